@@ -16,9 +16,12 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/spf13/cobra"
@@ -33,9 +36,21 @@ const defaultExecutablePath = "/opt/vertica/bin/vcluster"
 
 const CLIVersion = "1.2.0"
 const vclusterLogPathEnv = "VCLUSTER_LOG_PATH"
+const vclusterLogFormatEnv = "VCLUSTER_LOG_FORMAT"
+const vclusterLogMaxSizeEnv = "VCLUSTER_LOG_MAX_SIZE"
+const vclusterLogMaxFilesEnv = "VCLUSTER_LOG_MAX_FILES"
+const vclusterLogCompressEnv = "VCLUSTER_LOG_COMPRESS"
+const vclusterAuditLogEnv = "VCLUSTER_AUDIT_LOG"
+const vclusterSystemLogEnv = "VCLUSTER_SYSTEM_LOG"
 const vclusterKeyFileEnv = "VCLUSTER_KEY_FILE"
 const vclusterCertFileEnv = "VCLUSTER_CERT_FILE"
 
+// the values of VCLUSTER_AUDIT_LOG that are not a file
+const (
+	auditLogSyslog = "syslog"
+	auditLogOff    = "off"
+)
+
 // *Flag is for the flag name, *Key is for viper key name
 // They are bound together
 const (
@@ -60,6 +75,7 @@ const (
 	configParamFlag             = "config-param"
 	configParamKey              = "configParam"
 	logPathFlag                 = "log-path"
+	logFormatFlag               = "log-format"
 	logPathKey                  = "logPath"
 	keyFileFlag                 = "key-file"
 	keyFileKey                  = "keyFile"
@@ -71,8 +87,12 @@ const (
 	passwordFileKey             = "passwordFile"
 	readPasswordFromPromptFlag  = "read-password-from-prompt"
 	readPasswordFromPromptKey   = "readPasswordFromPrompt"
+	passwordKeyFileFlag         = "password-key-file"
+	yesFlag                     = "yes"
+	forceConfirmFlag            = "force-confirm"
 	configFlag                  = "config"
 	configKey                   = "config"
+	dbKeyFlag                   = "db-key"
 	verboseFlag                 = "verbose"
 	verboseKey                  = "verbose"
 	outputFileFlag              = "output-file"
@@ -80,6 +100,23 @@ const (
 	subclusterFlag              = "subcluster"
 	addNodeFlag                 = "new-hosts"
 	sandboxFlag                 = "sandbox"
+	stopNodeFlag                = "node"
+	hostCatalogPathsFlag        = "host-catalog-paths"
+	hostDataPathsFlag           = "host-data-paths"
+	hostDepotPathsFlag          = "host-depot-paths"
+	outputFormatFlag            = "output-format"
+	commandTimeoutFlag          = "command-timeout"
+	timingFlag                  = "timing"
+	sessionPollingIntervalFlag  = "session-polling-interval"
+	waitForFlag                 = "wait-for"
+)
+
+// Flags of the server TLS configuration of create_db
+const (
+	serverTLSModeFlag       = "server-tls-mode"
+	serverTLSKeyFileFlag    = "server-tls-key-file"
+	serverTLSCertFileFlag   = "server-tls-cert-file"
+	serverTLSCACertFileFlag = "server-tls-ca-file"
 )
 
 // Flag and key for database replication
@@ -133,6 +170,10 @@ const (
 	manageConfigSubCmd      = "manage_config"
 	configRecoverSubCmd     = "recover"
 	configShowSubCmd        = "show"
+	configSetPasswordSubCmd = "set_password"
+	configRollbackSubCmd    = "rollback"
+	fetchConfigSubCmd       = "fetch_config"
+	validateConfigSubCmd    = "validate_config"
 	replicationSubCmd       = "replication"
 	startReplicationSubCmd  = "start"
 	listAllNodesSubCmd      = "list_allnodes"
@@ -143,13 +184,32 @@ const (
 	stopSCSubCmd            = "stop_subcluster"
 	addNodeSubCmd           = "db_add_node"
 	removeNodeSubCmd        = "db_remove_node"
+	replaceNodeSubCmd       = "replace_node"
 	restartNodeSubCmd       = "restart_node"
+	startNodeSubCmd         = "start_node"
+	stopNodeSubCmd          = "stop_node"
 	reIPSubCmd              = "re_ip"
 	sandboxSubCmd           = "sandbox_subcluster"
 	unsandboxSubCmd         = "unsandbox_subcluster"
 	scrutinizeSubCmd        = "scrutinize"
 	showRestorePointsSubCmd = "show_restore_points"
 	installPkgSubCmd        = "install_packages"
+	upgradeSubCmd           = "upgrade"
+	rotateCertsSubCmd       = "rotate_certs"
+	clusterHealthSubCmd     = "status"
+	diagnosticsSubCmd       = "diagnostics"
+	installLicenseSubCmd    = "install_license"
+	licenseStatusSubCmd     = "license_status"
+	checkCatalogSubCmd      = "check_catalog"
+	checkHostsSubCmd        = "check_hosts"
+	alterDepotSubCmd        = "alter_depot"
+	clearDepotCacheSubCmd   = "clear_depot_cache"
+	recoverCatalogSubCmd    = "recover_catalog"
+	tailLogSubCmd           = "tail_log"
+	regenerateSpreadSubCmd  = "regenerate_spread"
+	dataCollectorSubCmd     = "data_collector"
+	shellSubCmd             = "shell"
+	serveSubCmd             = "serve"
 )
 
 // cmdGlobals holds global variables shared by multiple
@@ -159,11 +219,19 @@ type cmdGlobals struct {
 	file     *os.File
 	keyFile  string
 	certFile string
+	// text, json or yaml
+	outputFormat string
+	// the seconds a command can run before it is canceled, 0 means no limit
+	commandTimeout int
+	// the format of the debug logs, text or json
+	logFormat string
+	// whether the time spent in each instruction is printed at the end of the command
+	showTiming bool
 }
 
 var (
 	dbOptions = vclusterops.DatabaseOptionsFactory()
-	globals   = cmdGlobals{}
+	globals   = cmdGlobals{outputFormat: outputFormatText, logFormat: vlog.LogFormatText}
 	rootCmd   = &cobra.Command{
 		Use:   "vcluster",
 		Short: "Administer a Vertica cluster",
@@ -184,7 +252,30 @@ perform the following administrator operations:
 - Sandbox/Unsandbox a subcluster
 - Scrutinize a database
 - View the state of a database
-- Install packages on a database`,
+- Install packages on a database
+
+The log file is rotated when it reaches 100 MB, and the 5 latest rotated files
+are kept compressed. Set VCLUSTER_LOG_MAX_SIZE to the size in MB to rotate at,
+or 0 to never rotate, VCLUSTER_LOG_MAX_FILES to the number of rotated files to
+keep, and VCLUSTER_LOG_COMPRESS=false to keep them uncompressed.
+
+To also send the log entries to the system log of the host, set
+VCLUSTER_SYSTEM_LOG to "syslog" or "journald". The entries have the priority
+of their level and the identifier vcluster.
+
+The administrative commands are recorded in vcluster_audit.log, next to the log
+file, with the user, the options with the secrets masked, the hosts, the start
+and end times, and the result. Set VCLUSTER_AUDIT_LOG to another file, to
+"syslog" to send the records to syslog, or to "off".
+
+To be notified when a command ends, set VCLUSTER_NOTIFY_URL to a webhook, such
+as a Slack incoming webhook. The result of the command is posted to it as JSON.
+Set VCLUSTER_NOTIFY_ON to "failure" to only be notified of the failures.
+
+To trace the commands, set the VCLUSTER_TRACE_FILE environment variable to a
+file. The span of each instruction, and of each request that it sends to a
+host, are appended to the file as lines of JSON.
+` + exitCodesHelp,
 		Version: CLIVersion,
 	}
 )
@@ -200,26 +291,34 @@ type cmdInterface interface {
 	SetParser(parser *pflag.FlagSet)
 	setCommonFlags(cmd *cobra.Command, flags []string)
 	initCmdOutputFile() (*os.File, error)
+	getResult() any
 }
 
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		fmt.Printf("Error during execution: %s\n", err)
-		os.Exit(1)
+		// with a structured output format, stdout only has the command result
+		if isStructuredOutput() {
+			fmt.Fprintf(os.Stderr, "Error during execution: %s\n", err)
+		} else {
+			fmt.Printf("Error during execution: %s\n", err)
+		}
+		os.Exit(getExitCode(err))
 	}
 }
 
 // initVcc will initialize a vclusterops.VClusterCommands which contains a logger
 func initVcc(cmd *cobra.Command) vclusterops.VClusterCommands {
 	// setup logs
-	logger := vlog.Printer{ForCli: true}
+	logger := vlog.Printer{ForCli: true, LogFormat: globals.logFormat, LogRotation: getLogRotation(),
+		SystemLog: os.Getenv(vclusterSystemLogEnv)}
 	logger.SetupOrDie(dbOptions.LogPath)
 
 	vcc := vclusterops.VClusterCommands{
 		VClusterCommandsLogger: vclusterops.VClusterCommandsLogger{
 			Log: logger.WithName(cmd.CalledAs()),
 		},
+		AuditLogger: getAuditLogger(dbOptions.LogPath),
 	}
 	vcc.LogInfo("New VCluster command initialization")
 
@@ -309,7 +408,10 @@ func configViper(cmd *cobra.Command, flagsInConfig []string) error {
 	if cmd.CalledAs() != createDBSubCmd &&
 		cmd.CalledAs() != reviveDBSubCmd &&
 		cmd.CalledAs() != configRecoverSubCmd &&
-		cmd.CalledAs() != configShowSubCmd {
+		cmd.CalledAs() != configShowSubCmd &&
+		cmd.CalledAs() != configSetPasswordSubCmd &&
+		cmd.CalledAs() != configRollbackSubCmd &&
+		cmd.CalledAs() != validateConfigSubCmd {
 		err = loadConfigToViper()
 		if err != nil {
 			return err
@@ -366,6 +468,10 @@ func makeBasicCobraCmd(i cmdInterface, use, short, long string, commonFlags []st
 			return configViper(cmd, flagsInConfig)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// the logger is set up before the other options are validated
+			if err := validateLogFormat(globals.logFormat); err != nil {
+				return &usageError{err: err}
+			}
 			vcc := initVcc(cmd)
 			i.SetParser(cmd.Flags())
 			f, err := i.initCmdOutputFile()
@@ -378,18 +484,32 @@ func makeBasicCobraCmd(i cmdInterface, use, short, long string, commonFlags []st
 			// parseError and runError will be printed by the command invoker.
 			// we silence them in cobra for not printing duplicate error messages.
 			cmd.SilenceErrors = true
+			err = validateOutputFormat()
+			if err != nil {
+				return err
+			}
+			err = validateCommandTimeout()
+			if err != nil {
+				return err
+			}
 			parseError := i.Parse(os.Args[2:], vcc.GetLog())
 			if parseError != nil {
 				vcc.LogError(parseError, "fail to parse command")
-				return parseError
+				return writeResultIfStructured(cmd, i, nil, &usageError{err: parseError})
 			}
-			runError := i.Run(vcc)
+			startTime := time.Now()
+			workload := vclusterops.NewWorkloadSummary()
+			runError := runWithCmdContext(cmd.Name(), workload, func(ctx context.Context) error {
+				vcc.Ctx = ctx
+				return i.Run(vcc)
+			})
+			notifyCmdCompletion(cmd.CalledAs(), startTime, runError)
 			if runError != nil {
 				cmd.SilenceUsage = true // don't show usage when vcluster fails and operation has started
 				vcc.LogError(runError, "fail to run command")
 			}
 
-			return runError
+			return writeResultIfStructured(cmd, i, workload, runError)
 		},
 		PostRunE: func(cmd *cobra.Command, args []string) error {
 			if globals.verbose {
@@ -403,6 +523,20 @@ func makeBasicCobraCmd(i cmdInterface, use, short, long string, commonFlags []st
 	return cmd
 }
 
+// writeResultIfStructured writes the structured result of the command when the
+// output format is json or yaml, with the workload of the command if it ran.
+// The command error is returned as is.
+func writeResultIfStructured(cmd *cobra.Command, i cmdInterface, workload *vclusterops.WorkloadSummary, cmdErr error) error {
+	if !isStructuredOutput() {
+		return cmdErr
+	}
+	err := writeCmdResult(globals.file, cmd.CalledAs(), i.getResult(), workload, cmdErr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+	}
+	return cmdErr
+}
+
 // makeSimpleCobraCmd can make a simple cobra command for some vcluster commands
 // such as replication and manage_config
 func makeSimpleCobraCmd(use, short, long string) *cobra.Command {
@@ -422,26 +556,47 @@ func constructCmds() []*cobra.Command {
 		makeCmdCreateDB(),
 		makeCmdStopDB(),
 		makeListAllNodes(),
+		makeCmdClusterHealth(),
+		makeCmdDiagnostics(),
+		makeCmdCheckCatalog(),
+		makeCmdCheckHosts(),
 		makeCmdStartDB(),
 		makeCmdDropDB(),
 		makeCmdReviveDB(),
 		makeCmdReIP(),
+		makeCmdRecoverCatalog(),
+		makeCmdRegenerateSpread(),
 		makeCmdShowRestorePoints(),
 		makeCmdInstallPackages(),
+		makeCmdInstallLicense(),
+		makeCmdLicenseStatus(),
+		makeCmdUpgrade(),
+		makeCmdRotateCerts(),
 		// sc-scope cmds
 		makeCmdAddSubcluster(),
 		makeCmdRemoveSubcluster(),
 		makeCmdStopSubcluster(),
 		makeCmdSandboxSubcluster(),
 		makeCmdUnsandboxSubcluster(),
+		makeCmdAlterDepot(),
+		makeCmdClearDepotCache(),
 		// node-scope cmds
 		makeCmdRestartNodes(),
+		makeCmdStartNodes(),
+		makeCmdStopNode(),
 		makeCmdAddNode(),
 		makeCmdRemoveNode(),
+		makeCmdReplaceNode(),
 		// others
 		makeCmdScrutinize(),
+		makeCmdDataCollector(),
+		makeCmdTailLog(),
 		makeCmdManageConfig(),
+		makeCmdFetchConfig(),
+		makeCmdValidateConfig(),
 		makeCmdReplication(),
+		makeCmdShell(),
+		makeCmdServe(),
 	}
 }
 
@@ -506,6 +661,63 @@ func (realOperatingSystem) MkdirAll(path string, perm os.FileMode) error {
 	return os.MkdirAll(path, perm)
 }
 
+// getDefaultLogFormat returns the log format given by the environment, or text
+func getDefaultLogFormat() string {
+	if logFormat := os.Getenv(vclusterLogFormatEnv); logFormat != "" {
+		return logFormat
+	}
+	return vlog.LogFormatText
+}
+
+// getLogRotation returns the rotation of the log file, with the limits given
+// by the environment. The values that are not valid are ignored.
+func getLogRotation() vlog.LogRotation {
+	rotation := vlog.DefaultLogRotation
+	if maxSize, err := strconv.Atoi(os.Getenv(vclusterLogMaxSizeEnv)); err == nil && maxSize >= 0 {
+		rotation.MaxSizeMB = maxSize
+	}
+	if maxFiles, err := strconv.Atoi(os.Getenv(vclusterLogMaxFilesEnv)); err == nil && maxFiles >= 0 {
+		rotation.MaxFiles = maxFiles
+	}
+	if compress, err := strconv.ParseBool(os.Getenv(vclusterLogCompressEnv)); err == nil {
+		rotation.Compress = compress
+	}
+	return rotation
+}
+
+// getAuditLogger returns the audit logger given by the environment, or the
+// audit file in the directory of the log file
+func getAuditLogger(logFile string) vclusterops.AuditLogger {
+	auditLog := os.Getenv(vclusterAuditLogEnv)
+	switch auditLog {
+	case auditLogOff:
+		return nil
+	case auditLogSyslog:
+		return vclusterops.NewAuditSyslogLogger("vcluster")
+	case "":
+		if logFile == "" {
+			return nil
+		}
+		auditLog = filepath.Join(filepath.Dir(logFile), "vcluster_audit.log")
+	}
+	return vclusterops.NewAuditFileLogger(auditLog)
+}
+
+func validateLogFormat(logFormat string) error {
+	switch logFormat {
+	case vlog.LogFormatText, vlog.LogFormatJSON:
+	default:
+		return fmt.Errorf("invalid log format %q, must be %s or %s", logFormat, vlog.LogFormatText, vlog.LogFormatJSON)
+	}
+	switch systemLog := os.Getenv(vclusterSystemLogEnv); systemLog {
+	case "", vlog.SystemLogSyslog, vlog.SystemLogJournal:
+	default:
+		return fmt.Errorf("invalid value %q of %s, must be %s or %s", systemLog, vclusterSystemLogEnv,
+			vlog.SystemLogSyslog, vlog.SystemLogJournal)
+	}
+	return nil
+}
+
 func setLogPath() {
 	logPath = setLogPathImpl(realOperatingSystem{})
 }
@@ -16,11 +16,20 @@
 package commands
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/rfc7807"
+	"github.com/vertica/vcluster/vclusterops"
 )
 
 func TestConfigPathDefaults(t *testing.T) {
@@ -112,3 +121,242 @@ func TestSetLogPathImpl(t *testing.T) {
 	expectedLogPath = defaultHomeConfigDirLogPath
 	assert.Equal(t, expectedLogPath, logPath)
 }
+
+func TestCmdResult(t *testing.T) {
+	res := cmdResult{Command: "stop_subcluster", Success: true,
+		Result: map[string]string{"dbName": "test_db", "subcluster": "sc1"}}
+	jsonBytes, err := marshalCmdResult(&res, outputFormatJSON)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"command": "stop_subcluster", "success": true,
+		"result": {"dbName": "test_db", "subcluster": "sc1"}}`, string(jsonBytes))
+	// the yaml output has the same keys as the json output
+	yamlBytes, err := marshalCmdResult(&res, outputFormatYAML)
+	assert.NoError(t, err)
+	assert.Equal(t, "command: stop_subcluster\nresult:\n    dbName: test_db\n    subcluster: sc1\nsuccess: true\n",
+		string(yamlBytes))
+
+	// the problems reported by the hosts are collected from the error
+	hostErr := rfc7807.New(rfc7807.GenericBootstrapCatalogFailure).WithHost("192.168.1.101")
+	cmdErr := fmt.Errorf("fail to stop subcluster: %w", errors.Join(hostErr, errors.New("could not find a host")))
+	assert.Equal(t, map[string]string{"192.168.1.101": hostErr.Error()}, getHostErrors(cmdErr))
+	assert.Nil(t, getHostErrors(errors.New("fail to stop subcluster")))
+}
+
+func TestRunWithCmdContext(t *testing.T) {
+	defer func() { globals.commandTimeout = 0 }()
+
+	// the command fails without being canceled
+	err := runWithCmdContext("test", nil, func(_ context.Context) error { return errors.New("failed") })
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, exitCodeFailure, getExitCode(err))
+
+	// the command reaches its deadline while the op engine waits
+	globals.commandTimeout = 1
+	err = runWithCmdContext("test", nil, func(ctx context.Context) error {
+		<-ctx.Done()
+		return &vclusterops.OpEngineCanceledError{Instruction: "NMAHealthOp", InFlight: true,
+			Err: context.DeadlineExceeded}
+	})
+	assert.ErrorContains(t, err, "the command timed out after 1 seconds")
+	assert.ErrorContains(t, err, "while running instruction NMAHealthOp")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, exitCodeTimeout, getExitCode(err))
+
+	// an interrupted command has its own exit code
+	err = &cmdCanceledError{err: errors.New("failed"), cause: context.Canceled}
+	assert.EqualError(t, err, "the command was interrupted: failed")
+	assert.Equal(t, exitCodeInterrupted, getExitCode(err))
+
+	globals.commandTimeout = -1
+	assert.ErrorContains(t, validateCommandTimeout(), "must not be negative")
+}
+
+func TestFlagCompletion(t *testing.T) {
+	nodes := []completionNode{
+		{name: "v_test_db_node0001", subcluster: "sc1"},
+		{name: "v_test_db_node0002", subcluster: "sc1"},
+		{name: "v_test_db_node0003", subcluster: "sc2", sandbox: "sand"},
+	}
+	assert.Equal(t, []string{"sc1", "sc2"}, getCompletionNames(nodes, completeSubcluster))
+	assert.Equal(t, []string{"sand"}, getCompletionNames(nodes, completeSandbox))
+
+	nodeNames := getCompletionNames(nodes, completeNode)
+	assert.Len(t, nodeNames, 3)
+	assert.Equal(t, []string{"v_test_db_node0001", "v_test_db_node0002", "v_test_db_node0003"},
+		completeFlagValue(nodeNames, "v_test"))
+	// a comma-separated list is completed after the last comma, without the typed names
+	assert.Equal(t, []string{"v_test_db_node0001,v_test_db_node0003"},
+		completeFlagValue(nodeNames, "v_test_db_node0001,v_test_db_node0003"))
+	assert.Equal(t, []string{"v_test_db_node0002,v_test_db_node0001", "v_test_db_node0002,v_test_db_node0003"},
+		completeFlagValue(nodeNames, "v_test_db_node0002,"))
+}
+
+func TestRunWatch(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "watch")
+	assert.NoError(t, err)
+	defer f.Close()
+	globals.file = f
+	defer func() { globals.file = nil }()
+
+	// the watch stops once the command context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cmdContext = ctx
+	defer func() { cmdContext = context.Background() }()
+
+	c := CmdBase{watch: true, watchInterval: 1}
+	polls := 0
+	err = c.runWatch(func() (statusTable, error) {
+		polls++
+		if polls == 2 {
+			cancel()
+		}
+		return makeNodeStateTable([]vclusterops.NodeInfo{
+			{Name: "v_test_db_node0001", Address: "192.168.1.101", State: "UP", Subcluster: "sc1", IsPrimary: true},
+		}), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, polls)
+
+	content, err := os.ReadFile(f.Name())
+	assert.NoError(t, err)
+	// the table is written once, as the state does not change
+	assert.Equal(t, 1, strings.Count(string(content), "NAME"))
+	assert.Contains(t, string(content), "v_test_db_node0001   192.168.1.101   UP")
+
+	globals.outputFormat = outputFormatJSON
+	defer func() { globals.outputFormat = outputFormatText }()
+	assert.ErrorContains(t, c.validateWatchFlags(), "cannot be used with --output-format json")
+}
+
+func TestGetExitCode(t *testing.T) {
+	assert.Equal(t, exitCodeSuccess, getExitCode(nil))
+	assert.Equal(t, exitCodeFailure, getExitCode(errors.New("failed")))
+	assert.Equal(t, exitCodeUsage, getExitCode(&usageError{err: errors.New("unknown flag")}))
+
+	// the typed errors are found when they are wrapped or joined
+	authErr := &vclusterops.AuthFailureError{Host: "192.168.1.101", Detail: "wrong password"}
+	assert.Equal(t, exitCodeAuthFailure, getExitCode(fmt.Errorf("fail to stop db: %w", authErr)))
+	quorumErr := &vclusterops.QuorumError{Detail: "no quorum"}
+	assert.Equal(t, exitCodeQuorumFailure, getExitCode(errors.Join(errors.New("failed"), quorumErr)))
+	assert.Equal(t, exitCodePreconditionFailure, getExitCode(&vclusterops.DBIsRunningError{Detail: "running"}))
+	assert.Equal(t, exitCodePreconditionFailure,
+		getExitCode(fmt.Errorf("prepare failed: %w", &vclusterops.PreconditionError{Detail: "no up nodes detected"})))
+	assert.Equal(t, exitCodePartialSuccess,
+		getExitCode(&vclusterops.PartialSuccessError{Detail: "fail to install packages", Failed: []string{"pkg"}}))
+
+	// a canceled command has the exit code of the cancellation
+	canceledErr := &cmdCanceledError{err: authErr, cause: context.DeadlineExceeded}
+	assert.Equal(t, exitCodeTimeout, getExitCode(canceledErr))
+}
+
+func TestServeHandler(t *testing.T) {
+	handler := makeServeHandler(vclusterops.VClusterCommands{}, "secret")
+	post := func(path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// the requests without the token are rejected
+	assert.Equal(t, http.StatusUnauthorized, post("/v1/status", "", "{}").Code)
+	assert.Equal(t, http.StatusUnauthorized, post("/v1/status", "wrong", "{}").Code)
+
+	// unknown endpoints and methods
+	assert.Equal(t, http.StatusNotFound, post("/v1/revive_db", "secret", "{}").Code)
+	req := httptest.NewRequest(http.MethodGet, "/v1/status", http.NoBody)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	// the options that are not valid are a bad request, with the error in the result
+	rec = post("/v1/stop_db", "secret", `{"NoSuchOption": true}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var res cmdResult
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, stopDBSubCmd, res.Command)
+	assert.False(t, res.Success)
+	assert.Contains(t, res.Error, "NoSuchOption")
+	// the response has the correlation ID of the requests to the hosts
+	assert.NotEmpty(t, rec.Header().Get(vclusterops.CorrelationIDHeader))
+
+	// status codes by the class of the error
+	assert.Equal(t, http.StatusForbidden, getServeStatusCode(&vclusterops.AuthFailureError{}))
+	assert.Equal(t, http.StatusConflict, getServeStatusCode(&vclusterops.QuorumError{}))
+	assert.Equal(t, http.StatusMultiStatus, getServeStatusCode(&vclusterops.PartialSuccessError{}))
+	assert.Equal(t, http.StatusInternalServerError, getServeStatusCode(errors.New("failure")))
+}
+
+func TestServeListen(t *testing.T) {
+	c := CmdServe{listen: defaultListenAddress}
+	assert.NoError(t, c.validateListen())
+	c.listen = "localhost:8089"
+	assert.NoError(t, c.validateListen())
+	c.listen = "[::1]:8089"
+	assert.NoError(t, c.validateListen())
+
+	// plain HTTP is refused on the addresses that other hosts can reach
+	c.listen = ":8089"
+	err := c.validateListen()
+	assert.ErrorContains(t, err, "must serve HTTPS with --tls-cert-file and --tls-key-file to listen on :8089")
+	assert.Equal(t, exitCodeUsage, getExitCode(err))
+	c.listen = "10.20.30.40:8089"
+	assert.Error(t, c.validateListen())
+
+	c.tlsCertFile = "/home/dbadmin/server.crt"
+	assert.NoError(t, c.validateListen())
+	c.tlsCertFile = ""
+	c.insecure = true
+	assert.NoError(t, c.validateListen())
+
+	c.listen = "8089"
+	assert.ErrorContains(t, c.validateListen(), "invalid --listen address")
+
+	// the log format of the environment is validated before the server starts
+	t.Setenv(vclusterLogFormatEnv, "xml")
+	c = CmdServe{listen: defaultListenAddress}
+	err = c.run()
+	assert.ErrorContains(t, err, `invalid log format "xml"`)
+	assert.Equal(t, exitCodeUsage, getExitCode(err))
+}
+
+func TestNotifyCmdCompletion(t *testing.T) {
+	var notifications []cmdNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var notification cmdNotification
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&notification))
+		notifications = append(notifications, notification)
+	}))
+	defer server.Close()
+	t.Setenv(vclusterNotifyURLEnv, server.URL)
+	savedOptions := dbOptions
+	defer func() { dbOptions = savedOptions }()
+	dbOptions.DBName = "test_db"
+	dbOptions.RawHosts = []string{"192.168.1.101"}
+
+	notifyCmdCompletion(stopDBSubCmd, time.Now(), nil)
+	notifyCmdCompletion(stopDBSubCmd, time.Now(), &vclusterops.QuorumError{Detail: "no quorum"})
+	// only the failures are notified
+	t.Setenv(vclusterNotifyOnEnv, notifyOnFailure)
+	notifyCmdCompletion(stopDBSubCmd, time.Now(), nil)
+
+	assert.Len(t, notifications, 2)
+	assert.Equal(t, notifyResultSuccess, notifications[0].Result)
+	assert.Contains(t, notifications[0].Text, "vcluster stop_db of database test_db succeeded")
+	assert.Equal(t, []string{"192.168.1.101"}, notifications[0].Hosts)
+	assert.Equal(t, notifyResultFailure, notifications[1].Result)
+	assert.Equal(t, exitCodeQuorumFailure, notifications[1].ExitCode)
+	assert.Contains(t, notifications[1].Text, "failed on")
+	assert.Contains(t, notifications[1].Text, "no quorum")
+
+	// the status of a webhook that rejects the notification is returned
+	assert.ErrorContains(t, postCmdNotification(server.URL+"/missing", &notifications[0]), "404")
+}
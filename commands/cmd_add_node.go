@@ -16,8 +16,10 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -61,6 +63,20 @@ when using this option. This subcommand removes any surplus nodes from the
 catalog, provided they are down, before commencing the node addition process.
 Omitting the option will skip this node trimming process.
 
+The files of the UDx libraries are copied from an existing node to the new
+nodes before they start, and the packages are reinstalled once the new nodes
+are up, so that the new nodes load the same libraries. Use --skip-library-sync
+to skip these steps.
+
+In an Enterprise Mode database, --rebalance starts a rebalance of the cluster
+in the background once the new nodes are up. Use --wait-for-rebalance to wait
+until the rebalance completes, while its progress is printed.
+
+When more hosts than --node-batch-size are added, the hosts are added in
+batches: the nodes of a batch are created while the nodes of the previous
+batches start. If some batches fail, the other hosts are still added, and the
+hosts that failed are reported.
+
 Examples:
   # Add a single host to the existing database with config file
   vcluster db_add_node --db-name test_db --new-hosts 10.20.30.43 \
@@ -70,6 +86,10 @@ Examples:
   vcluster db_add_node --db-name test_db --new-hosts 10.20.30.43,10.20.30.44 \
     --data-path /data --hosts 10.20.30.40 \
     --node-names v_test_db_node0001,v_test_db_node0002
+
+  # Add a host to an Enterprise Mode database and wait for the rebalance
+  vcluster db_add_node --db-name test_db --new-hosts 10.20.30.43 \
+    --rebalance --wait-for-rebalance --config /opt/vertica/config/vertica_cluster.yaml
 `,
 		[]string{dbNameFlag, configFlag, hostsFlag, dataPathFlag, depotPathFlag,
 			passwordFlag},
@@ -77,6 +97,7 @@ Examples:
 
 	// local flags
 	newCmd.setLocalFlags(cmd)
+	newCmd.setHostPathFlags(cmd)
 
 	// require hosts to add
 	markFlagsRequired(cmd, []string{addNodeFlag})
@@ -115,7 +136,7 @@ func (c *CmdAddNode) setLocalFlags(cmd *cobra.Command) {
 		&c.addNodeOptions.DepotSize,
 		"depot-size",
 		"",
-		util.GetEonFlagMsg("Size of depot"),
+		util.GetEonFlagMsg("Size of depot, as a percentage of the disk, e.g., 50%, or as a size with a K, M, G or T unit, e.g., 10G"),
 	)
 	cmd.Flags().StringVar(
 		&c.nodeNameListStr,
@@ -123,6 +144,38 @@ func (c *CmdAddNode) setLocalFlags(cmd *cobra.Command) {
 		"",
 		"Comma-separated list of node names that exist in the cluster",
 	)
+	cmd.Flags().BoolVar(
+		&c.addNodeOptions.SkipLibrarySync,
+		"skip-library-sync",
+		false,
+		"Skip copying the UDx libraries to the new host(s) and reinstalling the packages",
+	)
+	cmd.Flags().BoolVar(
+		&c.addNodeOptions.RebalanceCluster,
+		"rebalance",
+		false,
+		"[Enterprise only] Start a rebalance of the cluster once the new host(s) are up",
+	)
+	cmd.Flags().BoolVar(
+		&c.addNodeOptions.WaitForRebalance,
+		"wait-for-rebalance",
+		false,
+		"[Enterprise only] Wait for the rebalance of the cluster to complete",
+	)
+	cmd.Flags().IntVar(
+		&c.addNodeOptions.RebalanceTimeout,
+		"rebalance-timeout",
+		util.DefaultRebalanceTimeoutSeconds,
+		"[Enterprise only] The timeout in seconds to wait for the rebalance, a negative value waits until it ends",
+	)
+	cmd.Flags().IntVar(
+		&c.addNodeOptions.NodeBatchSize,
+		"node-batch-size",
+		0,
+		"The number of hosts that are added together. The nodes of more hosts are created in batches, "+
+			"and are started once the last batch is created. The nodes of the batches that fail are trimmed "+
+			"from the catalog, and their hosts are reported. 0 adds all the hosts together",
+	)
 }
 
 func (c *CmdAddNode) Parse(inputArgv []string, logger vlog.Printer) error {
@@ -148,6 +201,7 @@ func (c *CmdAddNode) validateParse(logger vlog.Printer) error {
 	if err != nil {
 		return err
 	}
+	c.setHostPathPrefixes(&c.addNodeOptions.DatabaseOptions)
 
 	err = c.parseNodeNameList()
 	if err != nil {
@@ -192,9 +246,13 @@ func (c *CmdAddNode) Run(vcc vclusterops.ClusterCommands) error {
 	vcc.V(1).Info("Called method Run()")
 
 	options := c.addNodeOptions
+	if options.WaitForRebalance {
+		options.ProgressReporter = &rebalanceProgressPrinter{vcc: vcc}
+	}
 
 	vdb, addNodeError := vcc.VAddNode(options)
-	if addNodeError != nil {
+	var partialErr *vclusterops.PartialSuccessError
+	if addNodeError != nil && !errors.As(addNodeError, &partialErr) {
 		return addNodeError
 	}
 
@@ -204,10 +262,32 @@ func (c *CmdAddNode) Run(vcc vclusterops.ClusterCommands) error {
 		vcc.PrintWarning("fail to write config file, details: %s", err)
 	}
 
+	c.setResult(makeDBResult(&vdb))
+	if partialErr != nil {
+		// the config file has the nodes that are added
+		vcc.PrintWarning("Failed to add hosts %v to database %s", partialErr.Failed, options.DBName)
+		return partialErr
+	}
 	vcc.PrintInfo("Added nodes %v to database %s", c.addNodeOptions.NewHosts, options.DBName)
 	return nil
 }
 
+// rebalanceProgressPrinter prints the percent complete of the rebalance of the cluster
+type rebalanceProgressPrinter struct {
+	vcc vclusterops.ClusterCommands
+}
+
+func (printer *rebalanceProgressPrinter) ReportProgress(event vclusterops.ProgressEvent) {
+	msg := fmt.Sprintf("Cluster rebalance %s: %.1f%% complete", event.Stage, event.Percent)
+	if event.Total > 0 {
+		msg += fmt.Sprintf(", %d of %d projections", event.Done, event.Total)
+	}
+	if event.ETA > 0 {
+		msg += fmt.Sprintf(", about %s remaining", event.ETA.Round(time.Second))
+	}
+	printer.vcc.PrintInfo("%s", msg)
+}
+
 // SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdAddNode
 func (c *CmdAddNode) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
 	c.addNodeOptions.DatabaseOptions = *opt
@@ -16,8 +16,6 @@
 package commands
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/vertica/vcluster/vclusterops"
@@ -56,6 +54,10 @@ You must provide a subcluster name with the --subcluster option.
 By default, the new subcluster is secondary. To add a primary subcluster, use
 the --is-primary flag.
 
+To route client connections to the new subcluster, use the --load-balance-group
+option. The connection load balancing group is created with the subcluster, and
+the nodes added to the subcluster join the group.
+
 Examples:
   # Add a subcluster with config file
   vcluster db_add_subcluster --subcluster sc1 \
@@ -76,6 +78,12 @@ Examples:
   vcluster db_add_subcluster --subcluster sc1 --db-name test_db \
 	--hosts 10.20.30.40,10.20.30.41,10.20.30.42 \
 	--is-primary --control-set-size -1 --new-hosts 10.20.30.43
+
+  # Add a secondary subcluster with nodes and a load balancing group
+  vcluster db_add_subcluster --subcluster sc2 \
+    --config /opt/vertica/config/vertica_cluster.yaml \
+    --new-hosts 10.20.30.44,10.20.30.45 \
+    --load-balance-group sc2_group --load-balance-policy RANDOM
 `,
 		[]string{dbNameFlag, configFlag, hostsFlag, eonModeFlag, passwordFlag,
 			dataPathFlag, depotPathFlag},
@@ -117,6 +125,18 @@ func (c *CmdAddSubcluster) setLocalFlags(cmd *cobra.Command) {
 		vclusterops.ControlSetSizeDefaultValue,
 		"The number of nodes that will run spread within the subcluster",
 	)
+	cmd.Flags().StringVar(
+		&c.addSubclusterOptions.LoadBalanceGroup,
+		"load-balance-group",
+		"",
+		"The name of a connection load balancing group to create with the nodes of the new subcluster",
+	)
+	cmd.Flags().StringVar(
+		&c.addSubclusterOptions.LoadBalancePolicy,
+		"load-balance-policy",
+		vclusterops.LoadBalancePolicyRoundRobin,
+		"The policy of the load balancing group: ROUNDROBIN, RANDOM or NONE",
+	)
 	cmd.Flags().StringSliceVar(
 		&c.addSubclusterOptions.NewHosts,
 		addNodeFlag,
@@ -201,24 +221,15 @@ func (c *CmdAddSubcluster) Run(vcc vclusterops.ClusterCommands) error {
 
 	options := c.addSubclusterOptions
 
-	err := vcc.VAddSubcluster(options)
+	// the new hosts are added to the subcluster in the same call
+	options.SCRawHosts = options.NewHosts
+	vdb, err := vcc.VAddSubclusterWithVDB(options)
 	if err != nil {
 		vcc.LogError(err, "failed to add subcluster")
 		return err
 	}
 
 	if len(options.NewHosts) > 0 {
-		fmt.Printf("Adding hosts %v to subcluster %s\n",
-			options.NewHosts, options.SCName)
-
-		options.VAddNodeOptions.DatabaseOptions = c.addSubclusterOptions.DatabaseOptions
-		options.VAddNodeOptions.SCName = c.addSubclusterOptions.SCName
-
-		vdb, err := vcc.VAddNode(&options.VAddNodeOptions)
-		if err != nil {
-			vcc.LogError(err, "failed to add nodes into the new subcluster")
-			return err
-		}
 		// update db info in the config file
 		err = writeConfig(&vdb, vcc.GetLog())
 		if err != nil {
@@ -226,12 +237,16 @@ func (c *CmdAddSubcluster) Run(vcc vclusterops.ClusterCommands) error {
 		}
 	}
 
+	c.setResult(makeDBResult(&vdb))
 	if len(options.NewHosts) > 0 {
 		vcc.PrintInfo("Added subcluster %s with nodes %v to database %s",
 			options.SCName, options.NewHosts, options.DBName)
 	} else {
 		vcc.PrintInfo("Added subcluster %s to database %s", options.SCName, options.DBName)
 	}
+	if options.LoadBalanceGroup != "" {
+		vcc.PrintInfo("Created load balancing group %s for subcluster %s", options.LoadBalanceGroup, options.SCName)
+	}
 	return nil
 }
 
@@ -0,0 +1,141 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdAlterDepot
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdAlterDepot struct {
+	alterDepotOptions *vclusterops.VAlterDepotOptions
+
+	CmdBase
+}
+
+func makeCmdAlterDepot() *cobra.Command {
+	newCmd := &CmdAlterDepot{}
+
+	opt := vclusterops.VAlterDepotOptionsFactory()
+	newCmd.alterDepotOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		alterDepotSubCmd,
+		"Resize the depot of nodes",
+		`This subcommand resizes the depot storage location of the nodes of an Eon
+Mode database, such as before the disks of the depot are shrunk.
+
+The depot of the nodes of the subcluster given by --subcluster, or of the nodes
+given by --node-names, is resized. Without either option, the depot of all the
+nodes is resized. The nodes must be up.
+
+The new size is either a percentage of the disk, e.g., 40%, or a size in
+bytes with a K, M, G or T unit, e.g., 100G.
+
+Examples:
+  # Resize the depot of the nodes of a subcluster with config file
+  vcluster alter_depot --subcluster sc1 --size 40% \
+    --password testpassword --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Resize the depot of a node with user input
+  vcluster alter_depot --db-name test_db --hosts 10.20.30.40 --password testpassword \
+    --node-names v_test_db_node0001 --size 100G
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, passwordFlag, configFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	// require the new size
+	markFlagsRequired(cmd, []string{"size"})
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdAlterDepot) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&c.alterDepotOptions.SCName,
+		subclusterFlag,
+		"",
+		"The name of the subcluster whose nodes have their depot resized",
+	)
+	cmd.Flags().StringSliceVar(
+		&c.alterDepotOptions.NodeNames,
+		"node-names",
+		[]string{},
+		"Comma-separated list of the nodes whose depot is resized",
+	)
+	cmd.Flags().StringVar(
+		&c.alterDepotOptions.Size,
+		"size",
+		"",
+		"The new size of the depot, as a percentage of the disk, e.g., 40%, or in bytes, e.g., 100G",
+	)
+	cmd.MarkFlagsMutuallyExclusive(subclusterFlag, "node-names")
+}
+
+func (c *CmdAlterDepot) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.alterDepotOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdAlterDepot) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", alterDepotSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.alterDepotOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.alterDepotOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.alterDepotOptions.DatabaseOptions)
+}
+
+func (c *CmdAlterDepot) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	err := vcc.VAlterDepot(c.alterDepotOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to resize the depot")
+		return err
+	}
+
+	vcc.PrintInfo("Successfully resized the depot to %s", c.alterDepotOptions.Size)
+	c.setResult(map[string]string{"dbName": c.alterDepotOptions.DBName, "size": c.alterDepotOptions.Size})
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdAlterDepot
+func (c *CmdAlterDepot) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.alterDepotOptions.DatabaseOptions = *opt
+}
@@ -16,6 +16,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -25,10 +26,13 @@ import (
 	"github.com/vertica/vcluster/vclusterops"
 	"github.com/vertica/vcluster/vclusterops/util"
 	"github.com/vertica/vcluster/vclusterops/vlog"
+	"golang.org/x/term"
 )
 
 const (
 	outputFilePerm = 0600
+	// the environment variable of the client secret of an Azure service principal
+	azureClientSecretEnvVar = "AZURE_CLIENT_SECRET"
 )
 
 /* CmdBase
@@ -45,6 +49,24 @@ type CmdBase struct {
 	output                 string
 	passwordFile           string
 	readPasswordFromPrompt bool
+	// whether the password prompt asks to type the password twice, for the
+	// commands that set a new password
+	confirmPassword bool
+
+	// per-host path prefixes, for clusters whose hosts have different mount layouts
+	hostCatalogPaths map[string]string
+	hostDataPaths    map[string]string
+	hostDepotPaths   map[string]string
+
+	// the result of the command, which is written when the output format is json or yaml
+	result any
+
+	// whether a status command polls the state continuously, and the seconds between polls
+	watch         bool
+	watchInterval int
+
+	// whether a destructive command runs without asking to type the database name
+	skipConfirm bool
 }
 
 // ValidateParseBaseOptions will validate and parse the required base options in each command
@@ -83,6 +105,14 @@ func (c *CmdBase) setCommonFlags(cmd *cobra.Command, flags []string) {
 		"Path location used for the debug logs",
 	)
 	markFlagsFileName(cmd, map[string][]string{logPathFlag: {"log"}})
+	// log-format is a flag that all the subcommands need
+	cmd.Flags().StringVar(
+		&globals.logFormat,
+		logFormatFlag,
+		getDefaultLogFormat(),
+		"Format of the debug logs: text, or json for one JSON object per log entry. "+
+			"The format can also be given by the "+vclusterLogFormatEnv+" environment variable",
+	)
 
 	// verbose is a flag that all the subcommands need
 	cmd.Flags().BoolVar(
@@ -91,6 +121,29 @@ func (c *CmdBase) setCommonFlags(cmd *cobra.Command, flags []string) {
 		false,
 		"Show the details of VCluster run in the console",
 	)
+	// output-format is a flag that all the subcommands need
+	cmd.Flags().StringVar(
+		&globals.outputFormat,
+		outputFormatFlag,
+		outputFormatText,
+		"Format of the command output: text, json or yaml. With json or yaml, "+
+			"a structured result of the command is written instead of the text output",
+	)
+	// command-timeout is a flag that all the subcommands need
+	cmd.Flags().IntVar(
+		&globals.commandTimeout,
+		commandTimeoutFlag,
+		0,
+		"The seconds that the command can run before it is canceled. The default value 0 means no limit",
+	)
+	// timing is a flag that all the subcommands need
+	cmd.Flags().BoolVar(
+		&globals.showTiming,
+		timingFlag,
+		false,
+		"Print a summary of the time spent in each instruction, and of the slowest host of each instruction, "+
+			"to stderr at the end of the command",
+	)
 	// keyFile and certFile are flags that all subcommands require,
 	// except for manage_config and `manage_config show`
 	if cmd.Name() != configShowSubCmd {
@@ -147,8 +200,15 @@ func setConfigFlags(cmd *cobra.Command, flags []string) {
 			configFlag,
 			"c",
 			"",
-			"Path to the config file")
-		markFlagsFileName(cmd, map[string][]string{configFlag: {"yaml"}})
+			"Path to the config file, in yaml, json or toml format by its extension. "+
+				"It can also be the URL of a remote store: etcd://<host>:<port>/<key>, "+
+				"consul://<host>:<port>/<key> or configmap://<namespace>/<name>/<key>")
+		markFlagsFileName(cmd, map[string][]string{configFlag: {"yaml", "yml", "json", "toml"}})
+		cmd.Flags().StringVar(
+			&configDBKey,
+			dbKeyFlag,
+			"",
+			"Key of the database to use in a config file with multiple databases")
 	}
 	if util.StringInArray(hostsFlag, flags) {
 		cmd.Flags().StringSliceVar(
@@ -219,7 +279,8 @@ func (c *CmdBase) setPasswordFlags(cmd *cobra.Command) {
 		passwordFlag,
 		"p",
 		"",
-		"Database password",
+		"Database password. If - is passed, the password is read from stdin. "+
+			"The password can also be given by the "+vclusterPasswordEnv+" environment variable",
 	)
 	cmd.Flags().StringVar(
 		&c.passwordFile,
@@ -236,6 +297,251 @@ func (c *CmdBase) setPasswordFlags(cmd *cobra.Command) {
 	)
 	cmd.MarkFlagsMutuallyExclusive([]string{passwordFlag, passwordFileFlag,
 		readPasswordFromPromptFlag}...)
+	cmd.Flags().StringVar(
+		&passwordKeyFile,
+		passwordKeyFileFlag,
+		"",
+		"Path to the key file to decrypt the password stored in the config file. "+
+			"Defaults to $HOME/.config/vcluster/password.key",
+	)
+}
+
+// setWaitPolicyFlag sets the flag of what a start command waits for before
+// the nodes are considered started
+func setWaitPolicyFlag(cmd *cobra.Command, policy *vclusterops.WaitPolicy) {
+	cmd.Flags().StringVar(
+		(*string)(policy),
+		waitForFlag,
+		string(vclusterops.WaitForUp),
+		fmt.Sprintf("What to wait for before the nodes are considered started: %s (the vertica process is started), "+
+			"%s (the nodes are UP), %s (the nodes are UP and their shard subscriptions are ACTIVE, Eon only) "+
+			"or %s (the nodes are UP and accept client connections)",
+			vclusterops.WaitForProcess, vclusterops.WaitForUp, vclusterops.WaitForSubscriptions,
+			vclusterops.WaitForConnections),
+	)
+}
+
+// setAWSAuthFlags sets the flags of the AWS credentials of an S3 communal storage
+// besides the awsauth configuration parameter
+func setAWSAuthFlags(cmd *cobra.Command, opt *vclusterops.AWSAuthOptions) {
+	cmd.Flags().BoolVar(
+		&opt.GetAwsCredentialsFromEnv,
+		"get-aws-credentials-from-env-vars",
+		false,
+		util.GetEonFlagMsg("Read AWS credentials from environment variables, including AWS_SESSION_TOKEN"+
+			" for temporary credentials"),
+	)
+	cmd.Flags().StringVar(
+		&opt.AwsRoleARN,
+		"aws-role-arn",
+		"",
+		util.GetEonFlagMsg("ARN of an IAM role to assume with AWS STS to access the communal storage."+
+			" The role is assumed with the AWS key pair if it is given, or else with the instance profile"),
+	)
+	cmd.Flags().StringVar(
+		&opt.AwsRoleSessionName,
+		"aws-role-session-name",
+		"",
+		util.GetEonFlagMsg("Name of the session of the assumed AWS role"),
+	)
+	cmd.Flags().StringVar(
+		&opt.AwsExternalID,
+		"aws-external-id",
+		"",
+		util.GetEonFlagMsg("External ID that the trust policy of the AWS role requires"),
+	)
+	cmd.Flags().BoolVar(
+		&opt.AwsUseInstanceProfile,
+		"aws-use-instance-profile",
+		false,
+		util.GetEonFlagMsg("Use the credentials of the instance profile of each host instead of an AWS key pair"),
+	)
+}
+
+// setAzureAuthFlags sets the flags of the Azure credentials of an Azure communal
+// storage. The client secret of a service principal is not a flag, it is read
+// from the AZURE_CLIENT_SECRET environment variable so that it is not in the
+// arguments of the process.
+func setAzureAuthFlags(cmd *cobra.Command, opt *vclusterops.AzureAuthOptions) {
+	cmd.Flags().StringVar(
+		&opt.AzureAuthMethod,
+		"azure-auth-method",
+		"",
+		util.GetEonFlagMsg(fmt.Sprintf("How to authenticate to Azure Blob Storage: %s, or %s with the client secret in the "+
+			"%s environment variable", vclusterops.AzureAuthManagedIdentity, vclusterops.AzureAuthServicePrincipal,
+			azureClientSecretEnvVar)),
+	)
+	cmd.Flags().StringVar(
+		&opt.AzureAccountName,
+		"azure-account-name",
+		"",
+		util.GetEonFlagMsg("Name of the Azure storage account of the communal storage"),
+	)
+	cmd.Flags().StringVar(
+		&opt.AzureBlobEndpoint,
+		"azure-blob-endpoint",
+		"",
+		util.GetEonFlagMsg("Blob endpoint of the Azure storage account. Defaults to <account>.blob.core.windows.net"),
+	)
+	cmd.Flags().StringVar(
+		&opt.AzureClientID,
+		"azure-client-id",
+		"",
+		util.GetEonFlagMsg("Client ID of the user-assigned managed identity or of the service principal"),
+	)
+	cmd.Flags().StringVar(
+		&opt.AzureTenantID,
+		"azure-tenant-id",
+		"",
+		util.GetEonFlagMsg("Tenant ID of the Azure service principal"),
+	)
+}
+
+// setAzureClientSecret reads the client secret of an Azure service principal
+// from its environment variable
+func setAzureClientSecret(opt *vclusterops.AzureAuthOptions) {
+	if opt.AzureAuthMethod == vclusterops.AzureAuthServicePrincipal {
+		opt.AzureClientSecret = os.Getenv(azureClientSecretEnvVar)
+	}
+}
+
+// setGCSAuthFlags sets the flags of the GCS credentials of a GCS communal storage
+// besides the GCSAuth configuration parameter
+func setGCSAuthFlags(cmd *cobra.Command, opt *vclusterops.GCSAuthOptions) {
+	cmd.Flags().StringVar(
+		&opt.GCSAuthMethod,
+		"gcs-auth-method",
+		"",
+		util.GetEonFlagMsg(fmt.Sprintf("How to authenticate to Google Cloud Storage: %s with --gcs-service-account-key-file, "+
+			"or %s of the hosts. The credentials are checked on every host before the hosts are changed",
+			vclusterops.GCSAuthServiceAccountKey, vclusterops.GCSAuthWorkloadIdentity)),
+	)
+	cmd.Flags().StringVar(
+		&opt.GCSServiceAccountKeyFile,
+		"gcs-service-account-key-file",
+		"",
+		util.GetEonFlagMsg("Absolute path of the JSON key file of the GCS service account"),
+	)
+	markFlagsFileName(cmd, map[string][]string{"gcs-service-account-key-file": {"json"}})
+}
+
+// setConfirmFlags sets the flags that skip the confirmation of a destructive command
+func (c *CmdBase) setConfirmFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(
+		&c.skipConfirm,
+		yesFlag,
+		false,
+		"Run the command without asking to type the database name to confirm it",
+	)
+	// --force-confirm is the same as --yes, for the scripts that prefer an explicit name
+	cmd.Flags().BoolVar(
+		&c.skipConfirm,
+		forceConfirmFlag,
+		false,
+		"Same as --"+yesFlag,
+	)
+}
+
+// confirmDestructive asks the user to type the database name before a command
+// that cannot be undone. It fails if stdin is not a terminal, unless --yes is
+// given, so that automation has to opt in explicitly.
+func (c *CmdBase) confirmDestructive(action, dbName string, logger vlog.Printer) error {
+	if c.skipConfirm {
+		return nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return &usageError{err: fmt.Errorf("cannot confirm to %s because stdin is not a terminal, "+
+			"use --%s to run the command without confirmation", action, yesFlag)}
+	}
+	logger.PrintWarning("This command will %s.", action)
+	confirmation, err := readConfirmationFromPrompt(fmt.Sprintf("Type the database name (%s) to confirm: ", dbName))
+	if err != nil {
+		return err
+	}
+	if confirmation != dbName {
+		return fmt.Errorf("the typed name %q does not match the database name %q, the command is canceled",
+			confirmation, dbName)
+	}
+	return nil
+}
+
+// setHostPathFlags sets the flags of the per-host catalog, data and depot paths
+func (c *CmdBase) setHostPathFlags(cmd *cobra.Command) {
+	cmd.Flags().StringToStringVar(
+		&c.hostCatalogPaths,
+		hostCatalogPathsFlag,
+		map[string]string{},
+		"Comma-separated list of <host=path> pairs that override the catalog path of these hosts",
+	)
+	cmd.Flags().StringToStringVar(
+		&c.hostDataPaths,
+		hostDataPathsFlag,
+		map[string]string{},
+		"Comma-separated list of <host=path> pairs that override the data path of these hosts",
+	)
+	cmd.Flags().StringToStringVar(
+		&c.hostDepotPaths,
+		hostDepotPathsFlag,
+		map[string]string{},
+		util.GetEonFlagMsg("Comma-separated list of <host=path> pairs that override the depot path of these hosts"),
+	)
+}
+
+// setHostPathPrefixes adds the per-host paths from the cli to the
+// ones that are read from the config file
+func (c *CmdBase) setHostPathPrefixes(opt *vclusterops.DatabaseOptions) {
+	if len(c.hostCatalogPaths) == 0 && len(c.hostDataPaths) == 0 && len(c.hostDepotPaths) == 0 {
+		return
+	}
+	if opt.HostPathPrefixes == nil {
+		opt.HostPathPrefixes = make(map[string]vclusterops.HostPathPrefixes)
+	}
+	for host, path := range c.hostCatalogPaths {
+		prefixes := opt.HostPathPrefixes[host]
+		prefixes.CatalogPrefix = path
+		opt.HostPathPrefixes[host] = prefixes
+	}
+	for host, path := range c.hostDataPaths {
+		prefixes := opt.HostPathPrefixes[host]
+		prefixes.DataPrefix = path
+		opt.HostPathPrefixes[host] = prefixes
+	}
+	for host, path := range c.hostDepotPaths {
+		prefixes := opt.HostPathPrefixes[host]
+		prefixes.DepotPrefix = path
+		opt.HostPathPrefixes[host] = prefixes
+	}
+}
+
+// setSandboxHosts sets the hosts to the ones of a sandbox in the config file, or to the
+// ones of the main cluster if mainCluster is true, when the hosts are not given in the cli.
+// This lets a command reach a sandbox without querying the rest of the cluster first.
+func (c *CmdBase) setSandboxHosts(opt *vclusterops.DatabaseOptions, sandbox string, mainCluster bool) error {
+	if (sandbox == "" && !mainCluster) || c.parser.Changed(hostsFlag) {
+		return nil
+	}
+	config, err := readConfigFile(opt.ConfigPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	dbConfig, err := config.getDatabase(configDBKey, opt.DBName)
+	if err != nil {
+		return err
+	}
+	hosts := dbConfig.getSandboxHosts(sandbox)
+	if len(hosts) == 0 {
+		target := "the main cluster"
+		if sandbox != "" {
+			target = fmt.Sprintf("sandbox %s", sandbox)
+		}
+		return fmt.Errorf("cannot find any hosts of %s in the configuration file, use --%s to specify them",
+			target, hostsFlag)
+	}
+	opt.RawHosts = hosts
+	return nil
 }
 
 // ResetUserInputOptions reset password option to nil in each command
@@ -246,38 +552,49 @@ func (c *CmdBase) ResetUserInputOptions(opt *vclusterops.DatabaseOptions) {
 	}
 }
 
-// setDBPassword sets the password option if one of the password flags
-// is provided in the cli
+// setDBPassword sets the password option from the first password source
+// that is given, in this order:
+//   - --password, where - reads the password from stdin
+//   - --password-file, where - also reads the password from stdin
+//   - --read-password-from-prompt
+//   - the VCLUSTER_PASSWORD environment variable
+//   - the encrypted password in the config file
+//
+// The password option is reset to nil if none of them is given.
 func (c *CmdBase) setDBPassword(opt *vclusterops.DatabaseOptions) error {
-	if !c.usePassword() {
-		// reset password option to nil if password is not provided in cli
+	password, ok, err := c.readDBPassword(opt)
+	if err != nil {
+		return err
+	}
+	if !ok {
 		opt.Password = nil
 		return nil
 	}
+	opt.Password = &password
+	return nil
+}
 
-	if c.parser.Changed(passwordFlag) {
-		// no-op, password has been set elsewhere,
-		// through --password flag
-		return nil
-	}
-	if opt.Password == nil {
-		opt.Password = new(string)
-	}
-	if c.readPasswordFromPrompt {
-		password, err := readDBPasswordFromPrompt()
-		if err != nil {
-			return err
+func (c *CmdBase) readDBPassword(opt *vclusterops.DatabaseOptions) (password string, ok bool, err error) {
+	switch {
+	case c.parser.Changed(passwordFlag):
+		if opt.Password != nil && *opt.Password != stdinPasswordSource {
+			return *opt.Password, true, nil
 		}
-		*opt.Password = password
-		return nil
+		password, err = c.passwordFileHelper(stdinPasswordSource)
+	case c.parser.Changed(passwordFileFlag):
+		password, err = c.passwordFileHelper(c.passwordFile)
+	case c.readPasswordFromPrompt:
+		password, err = readDBPasswordFromPrompt(c.confirmPassword)
+	default:
+		if envPassword, found := os.LookupEnv(vclusterPasswordEnv); found {
+			return envPassword, true, nil
+		}
+		return readPasswordFromConfig()
 	}
-
-	password, err := c.passwordFileHelper(c.passwordFile)
 	if err != nil {
-		return err
+		return "", false, err
 	}
-	*opt.Password = password
-	return nil
+	return password, true, nil
 }
 
 func (c *CmdBase) passwordFileHelper(passwordFile string) (string, error) {
@@ -286,7 +603,7 @@ func (c *CmdBase) passwordFileHelper(passwordFile string) (string, error) {
 	}
 	// hyphen(`-`) is used to indicate that input should come
 	// from stdin rather than from a file
-	if passwordFile == "-" {
+	if passwordFile == stdinPasswordSource {
 		password, err := readFromStdin()
 		if err != nil {
 			return "", err
@@ -304,16 +621,32 @@ func (c *CmdBase) passwordFileHelper(passwordFile string) (string, error) {
 }
 
 // usePassword returns true if at least one of the password
-// flags is passed in the cli
+// flags is passed in the cli, or the password is in the environment
 func (c *CmdBase) usePassword() bool {
+	_, inEnv := os.LookupEnv(vclusterPasswordEnv)
 	return c.parser.Changed(passwordFlag) ||
 		c.parser.Changed(passwordFileFlag) ||
-		c.parser.Changed(readPasswordFromPromptFlag)
+		c.parser.Changed(readPasswordFromPromptFlag) ||
+		inEnv
+}
+
+// setResult sets the result of the command, which is written in json or yaml
+// when a structured output format is used
+func (c *CmdBase) setResult(result any) {
+	c.result = result
+}
+
+func (c *CmdBase) getResult() any {
+	return c.result
 }
 
 // writeCmdOutputToFile if output-file is set, writes the output of the command
-// to a file, otherwise to stdout
+// to a file, otherwise to stdout. With a structured output format, the output
+// is part of the command result instead.
 func (c *CmdBase) writeCmdOutputToFile(f *os.File, output []byte, logger vlog.Printer) {
+	if isStructuredOutput() {
+		return
+	}
 	_, err := f.Write(output)
 	if err != nil {
 		if f == os.Stdout {
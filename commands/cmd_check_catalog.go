@@ -0,0 +1,118 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdCheckCatalog
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdCheckCatalog struct {
+	checkCatalogOptions *vclusterops.VCheckCatalogConsistencyOptions
+
+	CmdBase
+}
+
+func makeCmdCheckCatalog() *cobra.Command {
+	newCmd := &CmdCheckCatalog{}
+
+	opt := vclusterops.VCheckCatalogConsistencyOptionsFactory()
+	newCmd.checkCatalogOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		checkCatalogSubCmd,
+		"Check that the catalogs of all nodes are consistent",
+		`This subcommand reads the catalog of every node through the node management
+agent (NMA) and compares the catalog versions and checksums. Each node is
+reported with one of the following states:
+  LATEST:      the node has the latest catalog
+  STALE:       the node has an older catalog version than other nodes
+  DIVERGENT:   the node has the latest catalog version, but the catalog
+               differs from the other nodes with that version
+  UNREACHABLE: the catalog of the node cannot be read
+
+The database can be up or down. The result is written in JSON to stdout, or to
+the file given by --output-file.
+
+Examples:
+  # Check the catalogs with config file
+  vcluster check_catalog --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Check the catalogs with user input
+  vcluster check_catalog --db-name test_db --hosts 10.20.30.40,10.20.30.41 \
+    --catalog-path /data
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, catalogPathFlag, configFlag, outputFileFlag},
+	)
+
+	return cmd
+}
+
+func (c *CmdCheckCatalog) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.checkCatalogOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdCheckCatalog) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", checkCatalogSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.checkCatalogOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	return c.ValidateParseBaseOptions(&c.checkCatalogOptions.DatabaseOptions)
+}
+
+func (c *CmdCheckCatalog) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	consistency, err := vcc.VCheckCatalogConsistency(c.checkCatalogOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to check catalog consistency")
+		return err
+	}
+
+	bytes, err := json.MarshalIndent(consistency, "", "  ")
+	if err != nil {
+		return fmt.Errorf("fail to marshal the catalog consistency, details %w", err)
+	}
+
+	c.setResult(consistency)
+	c.writeCmdOutputToFile(globals.file, bytes, vcc.GetLog())
+	vcc.LogInfo("Catalog consistency: ", "consistency", string(bytes))
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdCheckCatalog
+func (c *CmdCheckCatalog) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.checkCatalogOptions.DatabaseOptions = *opt
+}
@@ -0,0 +1,187 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdCheckHosts
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdCheckHosts struct {
+	checkHostsOptions *vclusterops.VCheckHostsOptions
+
+	CmdBase
+}
+
+func makeCmdCheckHosts() *cobra.Command {
+	newCmd := &CmdCheckHosts{}
+
+	opt := vclusterops.VCheckHostsOptionsFactory()
+	newCmd.checkHostsOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		checkHostsSubCmd,
+		"Check that hosts are ready for a database",
+		`This subcommand runs preflight checks on the hosts, before a database is
+created on them with create_db or they are added to a database with
+db_add_node. Through the node management agent (NMA) of each host, it checks
+that:
+  - the NMA can be reached
+  - the hosts have the same Vertica version, or the version given by
+    --vertica-version
+  - the ports of Vertica are not in use
+  - the catalog, data and depot paths are writable and have enough free space
+  - the filesystems of the paths have enough free inodes
+  - the clock of the host is close to the clock of this host
+  - the open files and user processes limits, vm.swappiness, transparent
+    hugepages and NTP synchronization meet the prerequisites of Vertica
+
+By default, the limits fail the command when they are too low, while the other
+OS settings only give a warning. --os-settings-policy sets whether each of
+these checks is a WARNING or FAILED when its setting is not met.
+
+The result of each check of each host is written in JSON to stdout, or to the
+file given by --output-file. The command fails if any check fails.
+
+Examples:
+  # Check the hosts of a new database
+  vcluster check_hosts --hosts 10.20.30.40,10.20.30.41,10.20.30.42 \
+    --catalog-path /data --data-path /data --depot-path /depot
+
+  # Check a host to add to a database with Vertica 24.2.0
+  vcluster check_hosts --hosts 10.20.30.43 --data-path /data \
+    --vertica-version "Vertica Analytic Database v24.2.0-0"
+
+  # Fail the check when NTP does not synchronize the clock of a host
+  vcluster check_hosts --hosts 10.20.30.40 --data-path /data \
+    --os-settings-policy ntp_sync=FAILED
+`,
+		[]string{hostsFlag, ipv6Flag, catalogPathFlag, dataPathFlag, depotPathFlag, outputFileFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdCheckHosts) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().IntSliceVar(
+		&c.checkHostsOptions.Ports,
+		"ports",
+		util.DefaultVerticaPorts,
+		"Comma-separated list of the ports that must not be in use on the hosts",
+	)
+	cmd.Flags().IntVar(
+		&c.checkHostsOptions.MinFreeSpaceMB,
+		"min-free-space",
+		util.DefaultMinFreeSpaceMB,
+		"The minimum free space in MB of the filesystem of each path",
+	)
+	cmd.Flags().IntVar(
+		&c.checkHostsOptions.MinFreeInodesPercent,
+		"min-free-inodes",
+		util.DefaultMinFreeInodesPercent,
+		"The minimum percentage of free inodes of the filesystem of each path",
+	)
+	cmd.Flags().IntVar(
+		&c.checkHostsOptions.MaxClockSkewSeconds,
+		"max-clock-skew",
+		util.DefaultMaxClockSkewSeconds,
+		"The maximum difference in seconds between the clock of a host and the clock of this host",
+	)
+	cmd.Flags().StringVar(
+		&c.checkHostsOptions.VerticaVersion,
+		"vertica-version",
+		"",
+		"The Vertica version that the hosts must have. Defaults to the version that most hosts have",
+	)
+	cmd.Flags().StringToStringVar(
+		&c.checkHostsOptions.OSSettingsPolicy,
+		"os-settings-policy",
+		map[string]string{},
+		"Comma-separated list of the status, WARNING or FAILED, of the OS settings checks when their setting is not met."+
+			" The checks are open_files_limit, max_user_processes, vm_swappiness, transparent_hugepages and ntp_sync",
+	)
+}
+
+func (c *CmdCheckHosts) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.checkHostsOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdCheckHosts) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", checkHostsSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.checkHostsOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	return c.ValidateParseBaseOptions(&c.checkHostsOptions.DatabaseOptions)
+}
+
+func (c *CmdCheckHosts) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	report, err := vcc.VCheckHosts(c.checkHostsOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to check hosts")
+		return err
+	}
+
+	bytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("fail to marshal the host check report, details %w", err)
+	}
+
+	c.setResult(report)
+	c.writeCmdOutputToFile(globals.file, bytes, vcc.GetLog())
+	vcc.LogInfo("Host check report: ", "report", string(bytes))
+
+	var failedHosts []string
+	for _, hostReport := range report.Hosts {
+		if hostReport.Status == vclusterops.HostCheckFailed {
+			failedHosts = append(failedHosts, hostReport.Host)
+		}
+	}
+	if len(failedHosts) > 0 {
+		return fmt.Errorf("preflight checks failed on hosts %v", failedHosts)
+	}
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdCheckHosts
+func (c *CmdCheckHosts) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.checkHostsOptions.DatabaseOptions = *opt
+}
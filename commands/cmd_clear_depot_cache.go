@@ -0,0 +1,129 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdClearDepotCache
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdClearDepotCache struct {
+	clearDepotCacheOptions *vclusterops.VClearDepotCacheOptions
+
+	CmdBase
+}
+
+func makeCmdClearDepotCache() *cobra.Command {
+	newCmd := &CmdClearDepotCache{}
+
+	opt := vclusterops.VClearDepotCacheOptionsFactory()
+	newCmd.clearDepotCacheOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		clearDepotCacheSubCmd,
+		"Evict the content of the depot of nodes",
+		`This subcommand evicts the content of the depot of the nodes of an Eon Mode
+database. The depot is then filled again from communal storage, such as after
+a configuration change.
+
+The depot of the nodes of the subcluster given by --subcluster, or of the nodes
+given by --node-names, is cleared. Without either option, the depot of all the
+nodes is cleared. The nodes must be up.
+
+Examples:
+  # Clear the depot of the nodes of a subcluster with config file
+  vcluster clear_depot_cache --subcluster sc1 \
+    --password testpassword --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Clear the depot of all the nodes with user input
+  vcluster clear_depot_cache --db-name test_db --hosts 10.20.30.40 --password testpassword
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, passwordFlag, configFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdClearDepotCache) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&c.clearDepotCacheOptions.SCName,
+		subclusterFlag,
+		"",
+		"The name of the subcluster whose nodes have their depot cleared",
+	)
+	cmd.Flags().StringSliceVar(
+		&c.clearDepotCacheOptions.NodeNames,
+		"node-names",
+		[]string{},
+		"Comma-separated list of the nodes whose depot is cleared",
+	)
+	cmd.MarkFlagsMutuallyExclusive(subclusterFlag, "node-names")
+}
+
+func (c *CmdClearDepotCache) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.clearDepotCacheOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdClearDepotCache) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", clearDepotCacheSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.clearDepotCacheOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.clearDepotCacheOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.clearDepotCacheOptions.DatabaseOptions)
+}
+
+func (c *CmdClearDepotCache) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	err := vcc.VClearDepotCache(c.clearDepotCacheOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to clear the depot cache")
+		return err
+	}
+
+	vcc.PrintInfo("Successfully cleared the depot cache")
+	c.setResult(map[string]string{"dbName": c.clearDepotCacheOptions.DBName})
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdClearDepotCache
+func (c *CmdClearDepotCache) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.clearDepotCacheOptions.DatabaseOptions = *opt
+}
@@ -0,0 +1,142 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdClusterHealth
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdClusterHealth struct {
+	clusterHealthOptions *vclusterops.VClusterHealthOptions
+
+	CmdBase
+}
+
+func makeCmdClusterHealth() *cobra.Command {
+	newCmd := &CmdClusterHealth{}
+
+	opt := vclusterops.VClusterHealthOptionsFactory()
+	newCmd.clusterHealthOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		clusterHealthSubCmd,
+		"Show the aggregated status of the database",
+		`This subcommand shows the status of a running database in a single result:
+the state, subcluster and sandbox of each node, whether the node management
+agent (NMA) is reachable on each host, and the catalog version on each host.
+The number of up nodes in each subcluster and whether the database has quorum
+are also reported.
+
+The result is written in JSON to stdout, or to the file given by --output-file.
+With --watch, the status is polled every --interval seconds and shown in a table
+that is updated in place, until the command is interrupted.
+
+Examples:
+  # Show the status of the database with config file
+  vcluster status --password testpassword \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Show the status of the database with user input
+  vcluster status --db-name test_db --hosts 10.20.30.40,10.20.30.41 \
+    --password testpassword --output-file /tmp/status.json
+
+  # Watch the status of the database
+  vcluster status --watch --config /opt/vertica/config/vertica_cluster.yaml
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, passwordFlag, configFlag, outputFileFlag},
+	)
+
+	// local flags
+	newCmd.setWatchFlags(cmd)
+
+	return cmd
+}
+
+func (c *CmdClusterHealth) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.clusterHealthOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdClusterHealth) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", clusterHealthSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.clusterHealthOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.clusterHealthOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	err = c.validateWatchFlags()
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.clusterHealthOptions.DatabaseOptions)
+}
+
+func (c *CmdClusterHealth) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	if c.watch {
+		watchVcc := quietVcc(vcc)
+		return c.runWatch(func() (statusTable, error) {
+			health, err := watchVcc.VClusterHealth(c.clusterHealthOptions)
+			if err != nil {
+				return statusTable{}, err
+			}
+			return makeClusterHealthTable(&health), nil
+		})
+	}
+
+	health, err := vcc.VClusterHealth(c.clusterHealthOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to get the database status")
+		return err
+	}
+
+	bytes, err := json.MarshalIndent(health, "", "  ")
+	if err != nil {
+		return fmt.Errorf("fail to marshal the database status, details %w", err)
+	}
+
+	c.setResult(health)
+	c.writeCmdOutputToFile(globals.file, bytes, vcc.GetLog())
+	vcc.LogInfo("Database status: ", "status", string(bytes))
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdClusterHealth
+func (c *CmdClusterHealth) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.clusterHealthOptions.DatabaseOptions = *opt
+}
@@ -116,6 +116,7 @@ func (c *CmdConfigRecover) Run(vcc vclusterops.ClusterCommands) error {
 	}
 	vcc.PrintInfo("Recovered config file for database %s at %s", vdb.Name,
 		c.recoverConfigOptions.ConfigPath)
+	c.setResult(makeDBResult(&vdb))
 
 	return nil
 }
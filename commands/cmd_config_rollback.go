@@ -0,0 +1,157 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+	"golang.org/x/exp/slices"
+)
+
+/* CmdConfigRollback
+ *
+ * A subcommand restoring a previous version
+ * of the config file from its backups.
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdConfigRollback struct {
+	sOptions vclusterops.DatabaseOptions
+	// the timestamp of the backup to restore, defaults to the newest backup
+	version string
+	// only list the backups
+	list bool
+	CmdBase
+}
+
+func makeCmdConfigRollback() *cobra.Command {
+	newCmd := &CmdConfigRollback{}
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		configRollbackSubCmd,
+		"Restore a previous version of the config file",
+		`This subcommand restores the config file from one of its backups.
+
+Each time vcluster updates the config file, the previous version is saved
+with a ".backup.<timestamp>" suffix. The 10 newest backups are kept, unless
+the config file sets another number in backupRetention.
+
+The current config file is backed up before it is restored, so a rollback
+can be undone by rolling back again.
+
+Examples:
+  # List the backups of the config file in the default location
+  vcluster manage_config rollback --list
+
+  # Restore the newest backup
+  vcluster manage_config rollback
+
+  # Restore a specific backup
+  vcluster manage_config rollback --version 20240301T101233.123456Z \
+    --config /opt/vertica/config/vertica_cluster.yaml
+`,
+		[]string{configFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdConfigRollback) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&c.version,
+		"version",
+		"",
+		"The timestamp of the backup to restore. Defaults to the newest backup",
+	)
+	cmd.Flags().BoolVar(
+		&c.list,
+		"list",
+		false,
+		"List the backups of the config file instead of restoring one",
+	)
+	cmd.MarkFlagsMutuallyExclusive("version", "list")
+}
+
+func (c *CmdConfigRollback) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	return nil
+}
+
+func (c *CmdConfigRollback) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	versions, err := listConfigBackups(dbOptions.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if c.list {
+		c.setResult(versions)
+		if isStructuredOutput() {
+			return nil
+		}
+		for _, version := range versions {
+			fmt.Println(version)
+		}
+		return nil
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("config file %s has no backups", dbOptions.ConfigPath)
+	}
+
+	version := c.version
+	if version == "" {
+		version = versions[len(versions)-1]
+	} else if !slices.Contains(versions, version) {
+		return fmt.Errorf("backup %s of config file %s is not found, use --list to show the backups",
+			version, dbOptions.ConfigPath)
+	}
+	configBytes, err := readConfigStore(getConfigBackupPrefix(dbOptions.ConfigPath) + version)
+	if err != nil {
+		return fmt.Errorf("fail to read backup %s of config file, details: %w", version, err)
+	}
+
+	err = backupConfigFile(dbOptions.ConfigPath, vcc.GetLog())
+	if err != nil {
+		return err
+	}
+	store, err := getConfigStore(dbOptions.ConfigPath)
+	if err != nil {
+		return err
+	}
+	err = store.Write(configBytes)
+	if err != nil {
+		return fmt.Errorf("fail to write config file, details: %w", err)
+	}
+	vcc.PrintInfo("Restored config file %s from backup %s", dbOptions.ConfigPath, version)
+	c.setResult(map[string]string{"configFile": dbOptions.ConfigPath, "version": version})
+
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance
+func (c *CmdConfigRollback) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.sOptions = *opt
+}
@@ -0,0 +1,127 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdConfigSetPassword
+ *
+ * A subcommand storing the database password
+ * encrypted in the config file.
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdConfigSetPassword struct {
+	sOptions vclusterops.DatabaseOptions
+	CmdBase
+}
+
+func makeCmdConfigSetPassword() *cobra.Command {
+	newCmd := &CmdConfigSetPassword{}
+	// the password prompt asks to type the new password twice
+	newCmd.confirmPassword = true
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		configSetPasswordSubCmd,
+		"Store the database password encrypted in the config file",
+		`This subcommand encrypts the database password with AES-256-GCM and stores
+it in the config file. Later commands that are not given a password read it
+from the config file and decrypt it, so the password does not need to be typed
+in or kept in a plain text file.
+
+The key is read from the file given by --password-key-file, the
+VCLUSTER_PASSWORD_KEY_FILE environment variable, or
+$HOME/.config/vcluster/password.key, in that order. If the key file does not
+exist, a new key is generated and saved in it. Keep the key file private, and
+do not store it next to the config file.
+
+Examples:
+  # Store the password read from a prompt in the default config file
+  vcluster manage_config set_password --read-password-from-prompt
+
+  # Store the password read from stdin with a specific key file
+  echo -n "testpassword" | vcluster manage_config set_password \
+    --password - --password-key-file /home/dbadmin/.vcluster.key \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Store the password given by the environment
+  VCLUSTER_PASSWORD="testpassword" vcluster manage_config set_password
+`,
+		[]string{configFlag, passwordFlag},
+	)
+
+	return cmd
+}
+
+func (c *CmdConfigSetPassword) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	return c.validateParse(logger)
+}
+
+// all validations of the arguments should go in here
+func (c *CmdConfigSetPassword) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", configSetPasswordSubCmd)
+	if !c.usePassword() {
+		return fmt.Errorf("must provide the password with --%s, --%s, --%s or the %s environment variable",
+			passwordFlag, passwordFileFlag, readPasswordFromPromptFlag, vclusterPasswordEnv)
+	}
+	return c.setDBPassword(&c.sOptions)
+}
+
+func (c *CmdConfigSetPassword) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	dbConfig, err := readConfig()
+	if err != nil {
+		return err
+	}
+	keyFilePath, err := getPasswordKeyFile()
+	if err != nil {
+		return err
+	}
+	key, err := readOrCreatePasswordKey(keyFilePath)
+	if err != nil {
+		return err
+	}
+	dbConfig.EncryptedPassword, err = encryptPassword(*c.sOptions.Password, key)
+	if err != nil {
+		return err
+	}
+
+	err = dbConfig.write(dbOptions.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("fail to write config file, details: %s", err)
+	}
+	vcc.PrintInfo("Stored the encrypted password of database %s in %s, using the key in %s",
+		dbConfig.Name, dbOptions.ConfigPath, keyFilePath)
+	c.setResult(map[string]string{"dbName": dbConfig.Name, "configFile": dbOptions.ConfigPath, "keyFile": keyFilePath})
+
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance
+func (c *CmdConfigSetPassword) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.sOptions = *opt
+}
@@ -16,8 +16,8 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/vertica/vcluster/vclusterops"
@@ -66,10 +66,22 @@ func (c *CmdConfigShow) Parse(inputArgv []string, logger vlog.Printer) error {
 }
 
 func (c *CmdConfigShow) Run(_ vclusterops.ClusterCommands) error {
-	fileBytes, err := os.ReadFile(dbOptions.ConfigPath)
+	fileBytes, err := readConfigStore(dbOptions.ConfigPath)
 	if err != nil {
 		return fmt.Errorf("fail to read config file, details: %w", err)
 	}
+	if isStructuredOutput() {
+		config, e := unmarshalConfig(fileBytes, getConfigFormat(dbOptions.ConfigPath))
+		if e != nil {
+			return fmt.Errorf("fail to unmarshal config file, details: %w", e)
+		}
+		configBytes, e := marshalConfig(config, configFormatJSON)
+		if e != nil {
+			return e
+		}
+		c.setResult(json.RawMessage(configBytes))
+		return nil
+	}
 	fmt.Printf("%s", string(fileBytes))
 
 	return nil
@@ -0,0 +1,133 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/tracing"
+)
+
+// the environment variable that gives the file that the spans of the commands are written to
+const vclusterTraceFileEnv = "VCLUSTER_TRACE_FILE"
+
+// cmdContext is canceled when the running command reaches its deadline or is interrupted
+var cmdContext = context.Background()
+
+// getCmdContext returns the context of the running command, so that a command
+// that runs until it is interrupted, such as a command in watch mode, can stop
+func getCmdContext() context.Context {
+	return cmdContext
+}
+
+// cmdCanceledError is returned when a command reaches its deadline or
+// is interrupted by a signal
+type cmdCanceledError struct {
+	err   error
+	cause error
+}
+
+func (e *cmdCanceledError) Error() string {
+	if errors.Is(e.cause, context.DeadlineExceeded) {
+		return fmt.Sprintf("the command timed out after %d seconds: %s", globals.commandTimeout, e.err)
+	}
+	return fmt.Sprintf("the command was interrupted: %s", e.err)
+}
+
+func (e *cmdCanceledError) Unwrap() []error {
+	return []error{e.err, e.cause}
+}
+
+func validateCommandTimeout() error {
+	if globals.commandTimeout < 0 {
+		return fmt.Errorf("the value of --%s must not be negative", commandTimeoutFlag)
+	}
+	return nil
+}
+
+// runWithCmdContext runs a command with a context that is canceled when the
+// command reaches the deadline set by --command-timeout, or when vcluster receives
+// SIGINT or SIGTERM. The context is given to run, to be the context of the
+// commands of vclusterops. The op engine checks it, so the command stops at the
+// instruction in flight instead of being killed in the middle of it.
+// The context also has the correlation ID of the command, which is sent to the
+// hosts with each request, the timing and workload summaries of the command, and
+// the span of the command when tracing is enabled.
+func runWithCmdContext(name string, workload *vclusterops.WorkloadSummary, run func(ctx context.Context) error) (err error) {
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// restore the default behavior so that a second signal kills vcluster
+		// if the command does not stop in time
+		<-sigCtx.Done()
+		stop()
+	}()
+
+	ctx := sigCtx
+	if globals.commandTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(sigCtx, time.Duration(globals.commandTimeout)*time.Second)
+		defer cancel()
+	}
+	ctx = vclusterops.ContextWithCorrelationID(ctx, vclusterops.NewCorrelationID())
+	timing := vclusterops.NewTimingSummary()
+	ctx = vclusterops.ContextWithTimingSummary(ctx, timing)
+	ctx = vclusterops.ContextWithWorkloadSummary(ctx, workload)
+	stopTracing := startCmdTracing()
+	defer stopTracing()
+	ctx, span := tracing.StartSpan(ctx, name)
+	defer func() { span.End(err) }()
+
+	cmdContext = ctx
+	defer func() { cmdContext = context.Background() }()
+
+	err = run(ctx)
+	if globals.showTiming {
+		fmt.Fprintln(os.Stderr)
+		_ = timing.WriteTable(os.Stderr)
+	}
+	if err != nil && ctx.Err() != nil {
+		return &cmdCanceledError{err: err, cause: ctx.Err()}
+	}
+	return err
+}
+
+// startCmdTracing enables tracing when the VCLUSTER_TRACE_FILE environment
+// variable is set. The spans of the command are appended to the file as lines
+// of JSON. It returns the function that disables tracing.
+func startCmdTracing() func() {
+	traceFile := os.Getenv(vclusterTraceFileEnv)
+	if traceFile == "" {
+		return func() {}
+	}
+	f, err := os.OpenFile(traceFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, outputFilePerm)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: fail to open the trace file %q, tracing is disabled: %s\n", traceFile, err)
+		return func() {}
+	}
+	tracing.SetExporter(tracing.NewJSONExporter(f))
+	return func() {
+		tracing.SetExporter(nil)
+		f.Close()
+	}
+}
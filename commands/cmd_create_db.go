@@ -16,6 +16,10 @@
 package commands
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/spf13/cobra"
 	"github.com/vertica/vcluster/vclusterops"
 	"github.com/vertica/vcluster/vclusterops/util"
@@ -32,11 +36,19 @@ import (
 
 type CmdCreateDB struct {
 	createDBOptions *vclusterops.VCreateDatabaseOptions
+	// the local files of the server TLS configuration
+	serverTLSKeyFile    string
+	serverTLSCertFile   string
+	serverTLSCACertFile string
+	// the local file of the spread encryption key
+	spreadEncryptionKeyFile string
 	CmdBase
 }
 
 func makeCmdCreateDB() *cobra.Command {
 	newCmd := &CmdCreateDB{}
+	// the password prompt asks to type the new password twice
+	newCmd.confirmPassword = true
 	opt := vclusterops.VCreateDatabaseOptionsFactory()
 	newCmd.createDBOptions = &opt
 
@@ -59,7 +71,9 @@ multiple configuration parameters when the database is created
 (see Example below).
 
 To remove the local directories like catalog, depot, and data, you can use the
---force-cleanup-on-failure or --force-removal-at-creation options.
+--force-cleanup-on-failure or --force-removal-at-creation options. To remove
+only the directories left by a failed attempt, which have no catalog, use
+--remove-partial-artifacts.
 The data deleted with these options is unrecoverable.
 
 The password for the dbadmin user of this new database can be provided in a few ways. 
@@ -103,6 +117,7 @@ Examples:
 	)
 	// local flags
 	newCmd.setLocalFlags(cmd)
+	newCmd.setHostPathFlags(cmd)
 
 	// check if hidden flags can be implemented/removed in VER-92259
 	// hidden flags
@@ -124,7 +139,14 @@ func (c *CmdCreateDB) setLocalFlags(cmd *cobra.Command) {
 		&c.createDBOptions.LicensePathOnNode,
 		"license",
 		"",
-		"Database license",
+		"Fully qualified path of the database license on the nodes",
+	)
+	cmd.Flags().StringVar(
+		&c.createDBOptions.LicenseFile,
+		"license-file",
+		"",
+		"Local database license file to upload to the bootstrap node. If --license is not given,\n"+
+			"the file is uploaded to "+util.DefaultDeployedLicenseKey,
 	)
 	cmd.Flags().StringVar(
 		&c.createDBOptions.Policy,
@@ -149,14 +171,11 @@ func (c *CmdCreateDB) setLocalFlags(cmd *cobra.Command) {
 		&c.createDBOptions.DepotSize,
 		"depot-size",
 		"",
-		util.GetEonFlagMsg("Size of depot"),
-	)
-	cmd.Flags().BoolVar(
-		&c.createDBOptions.GetAwsCredentialsFromEnv,
-		"get-aws-credentials-from-env-vars",
-		false,
-		util.GetEonFlagMsg("Read AWS credentials from environment variables"),
+		util.GetEonFlagMsg("Size of depot, as a percentage of the disk, e.g., 50%, or as a size with a K, M, G or T unit, e.g., 10G"),
 	)
+	setAWSAuthFlags(cmd, &c.createDBOptions.AWSAuthOptions)
+	setAzureAuthFlags(cmd, &c.createDBOptions.AzureAuthOptions)
+	setGCSAuthFlags(cmd, &c.createDBOptions.GCSAuthOptions)
 	cmd.Flags().BoolVar(
 		&c.createDBOptions.P2p,
 		"point-to-point",
@@ -175,6 +194,27 @@ func (c *CmdCreateDB) setLocalFlags(cmd *cobra.Command) {
 		-1,
 		"Enables a large cluster layout",
 	)
+	cmd.Flags().StringVar(
+		&c.createDBOptions.ControlNodePolicy,
+		"control-node-policy",
+		vclusterops.ControlNodePolicyAuto,
+		"The policy that chooses the control nodes of a large cluster: auto lets the server choose them, "+
+			"per-rack and per-fault-domain spread them across the groups of --host-control-groups, "+
+			"and explicit uses --control-nodes",
+	)
+	cmd.Flags().StringToStringVar(
+		&c.createDBOptions.HostControlGroups,
+		"host-control-groups",
+		map[string]string{},
+		"Comma-separated list of <host=group> pairs that give the rack or fault domain of each host",
+	)
+	cmd.Flags().StringSliceVar(
+		&c.createDBOptions.ControlNodes,
+		"control-nodes",
+		[]string{},
+		"Comma-separated list of the hosts of the control nodes, with the explicit control node policy. "+
+			"The first host must be a control node",
+	)
 	cmd.Flags().BoolVar(
 		&c.createDBOptions.SpreadLogging,
 		"spread-logging",
@@ -187,11 +227,25 @@ func (c *CmdCreateDB) setLocalFlags(cmd *cobra.Command) {
 		-1,
 		"Spread logging level",
 	)
+	cmd.Flags().BoolVar(
+		&c.createDBOptions.EnableSpreadEncryption,
+		"enable-spread-encryption",
+		false,
+		"Encrypt the spread traffic between the nodes from the creation of the database",
+	)
+	cmd.Flags().StringVar(
+		&c.spreadEncryptionKeyFile,
+		"spread-encryption-key-file",
+		"",
+		"Local file of the hex-encoded 32-byte key of the spread encryption, which enables the encryption. "+
+			"A key is generated if the encryption is enabled without this file",
+	)
 	cmd.Flags().BoolVar(
 		&c.createDBOptions.ForceCleanupOnFailure,
 		"force-cleanup-on-failure",
 		false,
-		"Force removal of existing directories on failure of command",
+		"Stop the started nodes and remove the directories of the database if the command fails, "+
+			"so that the command can run again",
 	)
 	cmd.Flags().BoolVar(
 		&c.createDBOptions.ForceRemovalAtCreation,
@@ -199,6 +253,13 @@ func (c *CmdCreateDB) setLocalFlags(cmd *cobra.Command) {
 		false,
 		"Force removal of existing directories before creating the database",
 	)
+	cmd.Flags().BoolVar(
+		&c.createDBOptions.RemovePartialArtifacts,
+		"remove-partial-artifacts",
+		false,
+		"If the directories of the database already exist and none of the hosts has a catalog in them, "+
+			"remove them and create the database again",
+	)
 	cmd.Flags().BoolVar(
 		&c.createDBOptions.SkipPackageInstall,
 		"skip-package-install",
@@ -211,6 +272,97 @@ func (c *CmdCreateDB) setLocalFlags(cmd *cobra.Command) {
 		util.DefaultTimeoutSeconds,
 		"The timeout to wait for the nodes to start",
 	)
+	cmd.Flags().BoolVar(
+		&c.createDBOptions.NoStart,
+		"no-start",
+		false,
+		"Create the catalog of the database on all the nodes and leave the database stopped, "+
+			"so that it can be started later with start_db",
+	)
+	cmd.Flags().StringVar(
+		&c.createDBOptions.ManifestFile,
+		"manifest-file",
+		"",
+		"Local file that the manifest of the created database is written to, in JSON. "+
+			"The manifest records the nodes, paths and parameters of the database, with the secrets masked",
+	)
+	markFlagsFileName(cmd, map[string][]string{"manifest-file": {"json"}})
+	c.setServerTLSFlags(cmd)
+}
+
+// setServerTLSFlags sets the flags of the server TLS configuration, which is
+// deployed before the first start of the database
+func (c *CmdCreateDB) setServerTLSFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&c.createDBOptions.ServerTLSMode,
+		serverTLSModeFlag,
+		"",
+		"Server TLS mode of the database: DISABLE, ENABLE, TRY_VERIFY or VERIFY_CA. "+
+			"The default is ENABLE if a server TLS key is given, and DISABLE otherwise",
+	)
+	cmd.Flags().StringVar(
+		&c.serverTLSKeyFile,
+		serverTLSKeyFileFlag,
+		"",
+		"Local file of the private key of the server, which is deployed to the catalog directory of each node",
+	)
+	cmd.Flags().StringVar(
+		&c.serverTLSCertFile,
+		serverTLSCertFileFlag,
+		"",
+		"Local file of the certificate of the server, which is deployed to the catalog directory of each node",
+	)
+	cmd.Flags().StringVar(
+		&c.serverTLSCACertFile,
+		serverTLSCACertFileFlag,
+		"",
+		"Local file of the CA certificate that verifies the client certificates, "+
+			"required with TRY_VERIFY and VERIFY_CA",
+	)
+	markFlagsFileName(cmd, map[string][]string{
+		serverTLSKeyFileFlag:    {"key", "pem"},
+		serverTLSCertFileFlag:   {"crt", "pem"},
+		serverTLSCACertFileFlag: {"crt", "pem"},
+	})
+	cmd.MarkFlagsRequiredTogether(serverTLSKeyFileFlag, serverTLSCertFileFlag)
+}
+
+// readServerTLSFiles reads the server TLS key and certificates from their files
+func (c *CmdCreateDB) readServerTLSFiles() error {
+	files := []struct {
+		path    string
+		content *string
+		desc    string
+	}{
+		{c.serverTLSKeyFile, &c.createDBOptions.ServerTLSKey, "server TLS key"},
+		{c.serverTLSCertFile, &c.createDBOptions.ServerTLSCert, "server TLS certificate"},
+		{c.serverTLSCACertFile, &c.createDBOptions.ServerTLSCACert, "server TLS CA certificate"},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(file.path)
+		if err != nil {
+			return fmt.Errorf("failed to read the %s file, details %w", file.desc, err)
+		}
+		*file.content = string(data)
+	}
+	return nil
+}
+
+// readSpreadEncryptionKeyFile reads the key of the spread encryption from its
+// file, so that the key is not given on the command line
+func (c *CmdCreateDB) readSpreadEncryptionKeyFile() error {
+	if c.spreadEncryptionKeyFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.spreadEncryptionKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the spread encryption key file, details %w", err)
+	}
+	c.createDBOptions.SpreadEncryptionKey = strings.TrimSpace(string(data))
+	return nil
 }
 
 // setHiddenFlags will set the hidden flags the command has.
@@ -256,6 +408,18 @@ func (c *CmdCreateDB) validateParse(logger vlog.Printer) error {
 	if err != nil {
 		return err
 	}
+	c.setHostPathPrefixes(&c.createDBOptions.DatabaseOptions)
+	setAzureClientSecret(&c.createDBOptions.AzureAuthOptions)
+
+	err = c.readServerTLSFiles()
+	if err != nil {
+		return err
+	}
+
+	err = c.readSpreadEncryptionKeyFile()
+	if err != nil {
+		return err
+	}
 
 	return c.setDBPassword(&c.createDBOptions.DatabaseOptions)
 }
@@ -272,7 +436,12 @@ func (c *CmdCreateDB) Run(vcc vclusterops.ClusterCommands) error {
 	if err != nil {
 		vcc.PrintWarning("fail to write config file, details: %s", err)
 	}
-	vcc.PrintInfo("Created a database with name [%s]", vdb.Name)
+	if c.createDBOptions.NoStart {
+		vcc.PrintInfo("Created a database with name [%s] without starting it", vdb.Name)
+	} else {
+		vcc.PrintInfo("Created a database with name [%s]", vdb.Name)
+	}
+	c.setResult(makeDBResult(&vdb))
 	return nil
 }
 
@@ -0,0 +1,142 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdDataCollector
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdDataCollector struct {
+	dataCollectorOptions *vclusterops.VGetDataCollectorOptions
+
+	CmdBase
+}
+
+func makeCmdDataCollector() *cobra.Command {
+	newCmd := &CmdDataCollector{}
+
+	opt := vclusterops.VGetDataCollectorOptionsFactory()
+	newCmd.dataCollectorOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		dataCollectorSubCmd,
+		"Download data collector tables from the nodes",
+		`This subcommand downloads the records of selected data collector (DC) tables
+from each node through the node management agent (NMA). It is a lightweight
+alternative to scrutinize for performance investigations.
+
+The DC tables are bundled together in a tarball and stored at the following
+directory: `+vclusterops.ScrutinizeOutputBasePath+`/VerticaDataCollector.<timestamp>.tar.
+
+Examples:
+  # Download the requests issued in a time range with config file
+  vcluster data_collector --tables dc_requests_issued,dc_requests_completed \
+    --start-time "2024-03-01 10:00:00" --end-time "2024-03-01 12:00:00" \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Download a DC table from some nodes with user input
+  vcluster data_collector --db-name test_db --hosts 10.20.30.40,10.20.30.41 \
+    --catalog-path /data --tables dc_requests_issued
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, catalogPathFlag, configFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	// require DC tables
+	markFlagsRequired(cmd, []string{"tables"})
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdDataCollector) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(
+		&c.dataCollectorOptions.Tables,
+		"tables",
+		[]string{},
+		"Comma-separated list of DC tables to download, e.g. dc_requests_issued",
+	)
+	cmd.Flags().StringVar(
+		&c.dataCollectorOptions.StartTime,
+		"start-time",
+		"",
+		"Only download the records after this time, formatted as "+vclusterops.DataCollectorHelpTimeFormatDesc,
+	)
+	cmd.Flags().StringVar(
+		&c.dataCollectorOptions.EndTime,
+		"end-time",
+		"",
+		"Only download the records before this time, formatted as "+vclusterops.DataCollectorHelpTimeFormatDesc,
+	)
+	cmd.Flags().StringVar(
+		&c.dataCollectorOptions.TarballName,
+		"tarball-name",
+		"",
+		"Name of the generated tarball. If empty an auto-generated "+
+			"name is used following the pattern VerticaDataCollector.<timestamp>",
+	)
+}
+
+func (c *CmdDataCollector) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.dataCollectorOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdDataCollector) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", dataCollectorSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.dataCollectorOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	return c.ValidateParseBaseOptions(&c.dataCollectorOptions.DatabaseOptions)
+}
+
+func (c *CmdDataCollector) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	err := vcc.VGetDataCollector(c.dataCollectorOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to download DC tables")
+		return err
+	}
+
+	vcc.PrintInfo("Successfully downloaded DC tables %v of the database %s",
+		c.dataCollectorOptions.Tables, c.dataCollectorOptions.DBName)
+	c.setResult(map[string]any{"dbName": c.dataCollectorOptions.DBName, "tables": c.dataCollectorOptions.Tables})
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdDataCollector
+func (c *CmdDataCollector) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.dataCollectorOptions.DatabaseOptions = *opt
+}
@@ -0,0 +1,136 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdDiagnostics
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdDiagnostics struct {
+	diagnosticsOptions *vclusterops.VGetDiagnosticsOptions
+
+	CmdBase
+}
+
+func makeCmdDiagnostics() *cobra.Command {
+	newCmd := &CmdDiagnostics{}
+
+	opt := vclusterops.VGetDiagnosticsOptionsFactory()
+	newCmd.diagnosticsOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		diagnosticsSubCmd,
+		"Show recent slow events and errors of the database",
+		`This subcommand collects the recent slow events and the summary of errors
+of a running database, grouped by node. It is a quick way to triage issues
+without running scrutinize.
+
+The result is written in JSON to stdout, or to the file given by --output-file.
+
+Examples:
+  # Show the slow events and errors of the last 30 minutes with config file
+  vcluster diagnostics --since-minutes 30 --password testpassword \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Show at most 20 slow events and error summaries with user input
+  vcluster diagnostics --db-name test_db --hosts 10.20.30.40 \
+    --password testpassword --limit 20
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, passwordFlag, configFlag, outputFileFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdDiagnostics) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(
+		&c.diagnosticsOptions.SinceMinutes,
+		"since-minutes",
+		c.diagnosticsOptions.SinceMinutes,
+		"Only collect the events and errors of the last given minutes",
+	)
+	cmd.Flags().IntVar(
+		&c.diagnosticsOptions.Limit,
+		"limit",
+		c.diagnosticsOptions.Limit,
+		"The maximum number of slow events and of error summaries to collect",
+	)
+}
+
+func (c *CmdDiagnostics) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.diagnosticsOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdDiagnostics) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", diagnosticsSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.diagnosticsOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.diagnosticsOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.diagnosticsOptions.DatabaseOptions)
+}
+
+func (c *CmdDiagnostics) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	nodesDiagnostics, err := vcc.VGetDiagnostics(c.diagnosticsOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to collect diagnostics")
+		return err
+	}
+
+	bytes, err := json.MarshalIndent(nodesDiagnostics, "", "  ")
+	if err != nil {
+		return fmt.Errorf("fail to marshal the diagnostics, details %w", err)
+	}
+
+	c.setResult(nodesDiagnostics)
+	c.writeCmdOutputToFile(globals.file, bytes, vcc.GetLog())
+	vcc.LogInfo("Diagnostics: ", "diagnostics", string(bytes))
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdDiagnostics
+func (c *CmdDiagnostics) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.diagnosticsOptions.DatabaseOptions = *opt
+}
@@ -16,6 +16,8 @@
 package commands
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 	"github.com/vertica/vcluster/vclusterops"
 	"github.com/vertica/vcluster/vclusterops/vlog"
@@ -54,10 +56,17 @@ is determined). When the command completes, the config file is removed.
 To remove the local directories like catalog, depot, and data, you can use the 
 --force-delete option. The data deleted with this option is unrecoverable.
 
+You are asked to type the database name to confirm the drop. Use --yes to
+drop the database without confirmation, for example in a script.
+
 Examples:
   # Drop a database with config file
   vcluster drop_db --db-name test_db \
     --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Drop a database in a script, without confirmation
+  vcluster drop_db --db-name test_db --yes \
+    --config /opt/vertica/config/vertica_cluster.yaml
 `,
 		[]string{dbNameFlag, configFlag, hostsFlag, catalogPathFlag, dataPathFlag, depotPathFlag},
 	)
@@ -79,6 +88,7 @@ func (c *CmdDropDB) setLocalFlags(cmd *cobra.Command) {
 		false,
 		"Delete local directories like catalog, depot, and data.",
 	)
+	c.setConfirmFlags(cmd)
 }
 
 func (c *CmdDropDB) Parse(inputArgv []string, logger vlog.Printer) error {
@@ -94,7 +104,12 @@ func (c *CmdDropDB) validateParse(logger vlog.Printer) error {
 	if err != nil {
 		return err
 	}
-	return c.ValidateParseBaseOptions(&c.dropDBOptions.DatabaseOptions)
+	err = c.ValidateParseBaseOptions(&c.dropDBOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.confirmDestructive(fmt.Sprintf("drop the database %s", c.dropDBOptions.DBName),
+		c.dropDBOptions.DBName, logger)
 }
 
 func (c *CmdDropDB) Run(vcc vclusterops.ClusterCommands) error {
@@ -107,6 +122,7 @@ func (c *CmdDropDB) Run(vcc vclusterops.ClusterCommands) error {
 	}
 
 	vcc.PrintInfo("Successfully dropped database %s", c.dropDBOptions.DBName)
+	c.setResult(map[string]string{"dbName": c.dropDBOptions.DBName})
 	// if the database is successfully dropped, the config file will be removed
 	// if failed to remove it, we will ask users to manually do it
 	err = removeConfig(vcc.GetLog())
@@ -0,0 +1,140 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdFetchConfig
+ *
+ * A subcommand refreshing the config file
+ * from a running database.
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdFetchConfig struct {
+	fetchConfigOptions *vclusterops.VFetchCoordinationDatabaseOptions
+	CmdBase
+}
+
+func makeCmdFetchConfig() *cobra.Command {
+	newCmd := &CmdFetchConfig{}
+	opt := vclusterops.VRecoverConfigOptionsFactory()
+	newCmd.fetchConfigOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		fetchConfigSubCmd,
+		"Refresh the config file from a running database",
+		`This subcommand refreshes the config file with the current nodes, subclusters
+and paths of a running database, which are read from the HTTPS service.
+
+Use it after the database is changed outside of vcluster, such as nodes added
+with admintools or SQL, to bring the config file back in sync. The old config
+file is backed up before it is rewritten.
+
+Only one of the hosts needs to be up. If the config file has other hosts that
+were removed from the database, they are dropped from the config file.
+
+Examples:
+  # Refresh the config file at the default location
+  vcluster fetch_config --db-name test_db --hosts 10.20.30.40 \
+    --password testpassword
+
+  # Refresh the config file using the hosts in it
+  vcluster fetch_config --password testpassword \
+    --config /opt/vertica/config/vertica_cluster.yaml
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, passwordFlag, configFlag},
+	)
+
+	return cmd
+}
+
+func (c *CmdFetchConfig) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.fetchConfigOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+// all validations of the arguments should go in here
+func (c *CmdFetchConfig) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", fetchConfigSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.fetchConfigOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.fetchConfigOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.fetchConfigOptions.DatabaseOptions)
+}
+
+func (c *CmdFetchConfig) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	vdb, err := vcc.VFetchRunningCoordinationDatabase(c.fetchConfigOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to fetch the database from the running database")
+		return err
+	}
+
+	// the config file may be missing or broken, in which case it is rebuilt from scratch
+	oldDBConfig, err := readConfig()
+	if err != nil {
+		vcc.PrintWarning("fail to read the existing config file, a new one will be written: %s", err)
+		oldDBConfig = &DatabaseConfig{}
+	}
+	newDBConfig, err := readVDBToDBConfig(&vdb)
+	if err != nil {
+		return err
+	}
+	added, removed := oldDBConfig.diffNodes(&newDBConfig)
+	if len(added) > 0 {
+		vcc.PrintInfo("Nodes added to the config file: %v", added)
+	}
+	if len(removed) > 0 {
+		vcc.PrintInfo("Nodes removed from the config file: %v", removed)
+	}
+
+	err = writeConfig(&vdb, vcc.GetLog())
+	if err != nil {
+		return fmt.Errorf("fail to write config file, details: %s", err)
+	}
+	vcc.PrintInfo("Refreshed config file for database %s at %s", vdb.Name,
+		c.fetchConfigOptions.ConfigPath)
+	c.setResult(map[string]any{"database": makeDBResult(&vdb), "addedNodes": added, "removedNodes": removed})
+
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdFetchConfig
+func (c *CmdFetchConfig) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.fetchConfigOptions.DatabaseOptions = *opt
+}
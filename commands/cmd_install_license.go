@@ -0,0 +1,131 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdInstallLicense
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdInstallLicense struct {
+	installLicenseOptions *vclusterops.VInstallLicenseOptions
+
+	CmdBase
+}
+
+func makeCmdInstallLicense() *cobra.Command {
+	newCmd := &CmdInstallLicense{}
+
+	opt := vclusterops.VInstallLicenseOptionsFactory()
+	newCmd.installLicenseOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		installLicenseSubCmd,
+		"Install a license in the database",
+		`This subcommand uploads a local license file to an up node of a running
+database and installs the license from that node.
+
+The license file is uploaded to `+util.DefaultDeployedLicenseKey+` on the node,
+unless another fully qualified path is given with --license-path-on-node.
+
+Examples:
+  # Install a license with config file
+  vcluster install_license --license-file /path/to/license.key \
+    --password testpassword --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Install a license with user input
+  vcluster install_license --db-name test_db --hosts 10.20.30.40 \
+    --password testpassword --license-file /path/to/license.key
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, passwordFlag, configFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	// require license file
+	markFlagsRequired(cmd, []string{"license-file"})
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdInstallLicense) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&c.installLicenseOptions.LicenseFile,
+		"license-file",
+		"",
+		"Local license file to install",
+	)
+	cmd.Flags().StringVar(
+		&c.installLicenseOptions.LicensePathOnNode,
+		"license-path-on-node",
+		c.installLicenseOptions.LicensePathOnNode,
+		"Fully qualified path the license file is uploaded to on the node",
+	)
+}
+
+func (c *CmdInstallLicense) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.installLicenseOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdInstallLicense) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", installLicenseSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.installLicenseOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.installLicenseOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.installLicenseOptions.DatabaseOptions)
+}
+
+func (c *CmdInstallLicense) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	err := vcc.VInstallLicense(c.installLicenseOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to install license")
+		return err
+	}
+
+	vcc.PrintInfo("Successfully installed license %s", c.installLicenseOptions.LicenseFile)
+	c.setResult(map[string]string{"dbName": c.installLicenseOptions.DBName, "licenseFile": c.installLicenseOptions.LicenseFile})
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdInstallLicense
+func (c *CmdInstallLicense) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.installLicenseOptions.DatabaseOptions = *opt
+}
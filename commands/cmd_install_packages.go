@@ -113,20 +113,28 @@ func (c *CmdInstallPackages) Run(vcc vclusterops.ClusterCommands) error {
 	options := c.installPkgOpts
 
 	status, err := vcc.VInstallPackages(options)
-	if err != nil {
+	if status == nil {
 		vcc.LogError(err, "failed to install the packages")
 		return err
 	}
 
-	var bytes []byte
-	bytes, err = json.MarshalIndent(status, "", "  ")
-	if err != nil {
-		return err
+	// the status is written out even if some packages failed to install
+	bytes, marshalErr := json.MarshalIndent(status, "", "  ")
+	if marshalErr != nil {
+		return marshalErr
 	}
 
+	c.setResult(status)
 	c.writeCmdOutputToFile(globals.file, bytes, vcc.GetLog())
 	vcc.LogInfo("Installed the packages: ", "packages", string(bytes))
+	for _, pkg := range status.Packages {
+		vcc.PrintInfo("Package %s: %s", pkg.PackageName, pkg.InstallStatus)
+	}
 
+	if err != nil {
+		vcc.LogError(err, "failed to install the packages")
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,116 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdLicenseStatus
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdLicenseStatus struct {
+	licenseStatusOptions *vclusterops.VGetLicenseStatusOptions
+
+	CmdBase
+}
+
+func makeCmdLicenseStatus() *cobra.Command {
+	newCmd := &CmdLicenseStatus{}
+
+	opt := vclusterops.VGetLicenseStatusOptionsFactory()
+	newCmd.licenseStatusOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		licenseStatusSubCmd,
+		"Show the licenses of the database",
+		`This subcommand shows the licenses installed in a running database, their
+limits, and whether the database complies with them as of the last audit.
+
+The result is written in JSON to stdout, or to the file given by --output-file.
+
+Examples:
+  # Show the licenses with config file
+  vcluster license_status --password testpassword \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Show the licenses with user input
+  vcluster license_status --db-name test_db --hosts 10.20.30.40 \
+    --password testpassword
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, passwordFlag, configFlag, outputFileFlag},
+	)
+
+	return cmd
+}
+
+func (c *CmdLicenseStatus) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.licenseStatusOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdLicenseStatus) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", licenseStatusSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.licenseStatusOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.licenseStatusOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.licenseStatusOptions.DatabaseOptions)
+}
+
+func (c *CmdLicenseStatus) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	licenses, err := vcc.VGetLicenseStatus(c.licenseStatusOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to get license status")
+		return err
+	}
+
+	bytes, err := json.MarshalIndent(licenses, "", "  ")
+	if err != nil {
+		return fmt.Errorf("fail to marshal the license status, details %w", err)
+	}
+
+	c.setResult(licenses)
+	c.writeCmdOutputToFile(globals.file, bytes, vcc.GetLog())
+	vcc.LogInfo("License status: ", "licenses", string(bytes))
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdLicenseStatus
+func (c *CmdLicenseStatus) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.licenseStatusOptions.DatabaseOptions = *opt
+}
@@ -56,15 +56,25 @@ obtain node information from a running database and the config file is not provi
 
 The only requirement for each host is that it is running the spread daemon.
 
+With --watch, the status of the nodes is polled every --interval seconds and
+shown in a table that is updated in place, until the command is interrupted.
+
 Examples:
   # List the status of nodes with config file where password authentication is
   # used to access the database
   vcluster list_allnodes --password testpassword \
     --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Watch the status of nodes, polling every 2 seconds
+  vcluster list_allnodes --watch --interval 2 \
+    --config /opt/vertica/config/vertica_cluster.yaml
 `,
 		[]string{dbNameFlag, hostsFlag, passwordFlag, catalogPathFlag, configFlag, outputFileFlag},
 	)
 
+	// local flags
+	newCmd.setWatchFlags(cmd)
+
 	return cmd
 }
 
@@ -91,12 +101,27 @@ func (c *CmdListAllNodes) validateParse(logger vlog.Printer) error {
 	if err != nil {
 		return err
 	}
+	err = c.validateWatchFlags()
+	if err != nil {
+		return err
+	}
 	return c.setDBPassword(&c.fetchNodeStateOptions.DatabaseOptions)
 }
 
 func (c *CmdListAllNodes) Run(vcc vclusterops.ClusterCommands) error {
 	vcc.V(1).Info("Called method Run()")
 
+	if c.watch {
+		watchVcc := quietVcc(vcc)
+		return c.runWatch(func() (statusTable, error) {
+			nodeStates, err := watchVcc.VFetchNodeState(c.fetchNodeStateOptions)
+			if err != nil && len(nodeStates) == 0 {
+				return statusTable{}, err
+			}
+			return makeNodeStateTable(nodeStates), nil
+		})
+	}
+
 	nodeStates, err := vcc.VFetchNodeState(c.fetchNodeStateOptions)
 	if err != nil {
 		// if all nodes are down, the nodeStates list is not empty
@@ -112,6 +137,7 @@ func (c *CmdListAllNodes) Run(vcc vclusterops.ClusterCommands) error {
 		return fmt.Errorf("fail to marshal the node state result, details %w", err)
 	}
 
+	c.setResult(nodeStates)
 	c.writeCmdOutputToFile(globals.file, bytes, vcc.GetLog())
 	vcc.LogInfo("Node states: ", "nodeStates", string(bytes))
 	return nil
@@ -30,11 +30,13 @@ import (
 func makeCmdManageConfig() *cobra.Command {
 	cmd := makeSimpleCobraCmd(
 		manageConfigSubCmd,
-		"Show or recover the content of the config file",
-		`This subcommand is used to print or recover the content of the config file.`)
+		"Show, recover, update or roll back the content of the config file",
+		`This subcommand is used to print, recover, update or roll back the content of the config file.`)
 
 	cmd.AddCommand(makeCmdConfigShow())
 	cmd.AddCommand(makeCmdConfigRecover())
+	cmd.AddCommand(makeCmdConfigSetPassword())
+	cmd.AddCommand(makeCmdConfigRollback())
 
 	return cmd
 }
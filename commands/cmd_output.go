@@ -0,0 +1,184 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/vertica/vcluster/rfc7807"
+	"github.com/vertica/vcluster/vclusterops"
+	"gopkg.in/yaml.v3"
+)
+
+// the formats of the command output
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+	outputFormatYAML = "yaml"
+)
+
+// cmdResult is the structured result that a command writes when the output
+// format is json or yaml, so that scripts do not need to parse the text messages
+type cmdResult struct {
+	Command string `json:"command"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	// the errors returned by each host, when the hosts report them
+	HostErrors map[string]string `json:"hostErrors,omitempty"`
+	// the command-specific result, such as the nodes of a created database
+	Result any `json:"result,omitempty"`
+	// the requests that the command sent to the hosts, and the bytes transferred
+	Workload *vclusterops.OpEngineWorkload `json:"workload,omitempty"`
+}
+
+// dbResult is the result of the commands that change the nodes of a database
+type dbResult struct {
+	DBName string       `json:"dbName"`
+	IsEon  bool         `json:"eonMode"`
+	Nodes  []nodeResult `json:"nodes"`
+	// the directories of each removed host that are left behind on purpose
+	KeptDirectories map[string][]string `json:"keptDirectories,omitempty"`
+}
+
+type nodeResult struct {
+	Name       string `json:"name"`
+	Address    string `json:"address"`
+	Subcluster string `json:"subcluster,omitempty"`
+	Sandbox    string `json:"sandbox,omitempty"`
+	IsPrimary  bool   `json:"isPrimary"`
+	State      string `json:"state,omitempty"`
+}
+
+// makeDBResult builds the result of a command from the database it returns
+func makeDBResult(vdb *vclusterops.VCoordinationDatabase) dbResult {
+	result := dbResult{DBName: vdb.Name, IsEon: vdb.IsEon, Nodes: []nodeResult{}}
+	// loop over HostList is needed as we want to preserve the order
+	for _, host := range vdb.HostList {
+		vnode, ok := vdb.HostNodeMap[host]
+		if !ok {
+			continue
+		}
+		result.Nodes = append(result.Nodes, nodeResult{
+			Name:       vnode.Name,
+			Address:    vnode.Address,
+			Subcluster: vnode.Subcluster,
+			Sandbox:    vnode.Sandbox,
+			IsPrimary:  vnode.IsPrimary,
+			State:      vnode.State,
+		})
+	}
+	return result
+}
+
+// printForceClosedSessions prints the client sessions that a stop command
+// closed because they were still connected when the drain ended
+func printForceClosedSessions(vcc vclusterops.ClusterCommands, sessions []vclusterops.SessionInfo) {
+	if len(sessions) == 0 {
+		return
+	}
+	vcc.PrintWarning("Closed %d sessions that were still connected when the drain ended:", len(sessions))
+	for _, session := range sessions {
+		vcc.PrintWarning("  %s on node %s from %s, logged in at %s", session.UserName, session.NodeName,
+			session.ClientHostname, session.LoginTimestamp)
+	}
+}
+
+// isStructuredOutput returns true if the command result is written in json or yaml
+func isStructuredOutput() bool {
+	return globals.outputFormat == outputFormatJSON || globals.outputFormat == outputFormatYAML
+}
+
+func validateOutputFormat() error {
+	switch globals.outputFormat {
+	case outputFormatText, outputFormatJSON, outputFormatYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q, must be one of %s, %s or %s", globals.outputFormat,
+		outputFormatText, outputFormatJSON, outputFormatYAML)
+}
+
+// writeCmdResult writes the structured result of a command to the output file, or stdout
+func writeCmdResult(f *os.File, command string, result any, workload *vclusterops.WorkloadSummary, cmdErr error) error {
+	res := cmdResult{Command: command, Success: cmdErr == nil, Result: result}
+	if workload != nil {
+		total := workload.Workload()
+		res.Workload = &total
+	}
+	if cmdErr != nil {
+		res.Error = cmdErr.Error()
+		res.HostErrors = getHostErrors(cmdErr)
+	}
+	bytes, err := marshalCmdResult(&res, globals.outputFormat)
+	if err != nil {
+		return fmt.Errorf("fail to marshal the result of command %s, details %w", command, err)
+	}
+	_, err = f.Write(bytes)
+	return err
+}
+
+func marshalCmdResult(res *cmdResult, format string) ([]byte, error) {
+	jsonBytes, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if format == outputFormatJSON {
+		return append(jsonBytes, '\n'), nil
+	}
+	// the result is converted through json so that the yaml output
+	// has the same keys as the json output
+	var generic any
+	err = json.Unmarshal(jsonBytes, &generic)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(generic)
+}
+
+// getHostErrors collects the problems that the hosts reported in an error
+func getHostErrors(err error) map[string]string {
+	hostErrors := make(map[string]string)
+	var collect func(e error)
+	collect = func(e error) {
+		if problem, ok := e.(*rfc7807.VProblem); ok && problem.Host != "" {
+			if _, found := hostErrors[problem.Host]; !found {
+				hostErrors[problem.Host] = problem.Error()
+			}
+		}
+		switch wrappedErr := e.(type) {
+		case interface{ Unwrap() []error }:
+			for _, childErr := range wrappedErr.Unwrap() {
+				collect(childErr)
+			}
+		case interface{ Unwrap() error }:
+			collect(wrappedErr.Unwrap())
+		}
+	}
+	collect(err)
+	if len(hostErrors) == 0 {
+		return nil
+	}
+	return hostErrors
+}
+
+// rawJSONOrString keeps a json string as is in the structured result
+func rawJSONOrString(s string) any {
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	return s
+}
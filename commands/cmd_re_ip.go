@@ -131,6 +131,7 @@ func (c *CmdReIP) Run(vcc vclusterops.ClusterCommands) error {
 	}
 
 	vcc.PrintInfo("Re-ip is successfully completed")
+	c.setResult(options.ReIPList)
 
 	// update config file after running re_ip
 	if canUpdateConfig {
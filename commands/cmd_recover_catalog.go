@@ -0,0 +1,154 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+const (
+	truncateCatalogFlag = "truncate-to-version"
+	dropNodeRecordFlag  = "drop-node"
+)
+
+/* CmdRecoverCatalog
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdRecoverCatalog struct {
+	recoverCatalogOptions *vclusterops.VRecoverCatalogOptions
+
+	CmdBase
+}
+
+func makeCmdRecoverCatalog() *cobra.Command {
+	newCmd := &CmdRecoverCatalog{}
+
+	opt := vclusterops.VRecoverCatalogOptionsFactory()
+	newCmd.recoverCatalogOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		recoverCatalogSubCmd,
+		"Run an expert catalog recovery action on a down database",
+		`This subcommand edits the catalog of each node of a down database through
+the node management agent (NMA), for recovery actions that support would
+otherwise run with server tools on each node:
+  - --truncate-to-version truncates the catalogs to a global catalog version,
+    dropping the changes committed after it
+  - --drop-node drops the record of a dead node from the catalogs of the
+    other nodes. The host of the dead node must not be in --hosts.
+
+These actions can lose committed changes, so they are refused without
+--expert. Only run them under the guidance of support.
+
+Examples:
+  # Truncate the catalogs to version 1024
+  vcluster recover_catalog --db-name test_db --hosts 10.20.30.40,10.20.30.41,10.20.30.42 \
+    --catalog-path /data --truncate-to-version 1024 --expert
+
+  # Drop a dead node from the catalogs of the other nodes
+  vcluster recover_catalog --db-name test_db --hosts 10.20.30.40,10.20.30.41 \
+    --catalog-path /data --drop-node v_test_db_node0003 --expert
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, catalogPathFlag, passwordFlag, configFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	// require exactly one recovery action
+	cmd.MarkFlagsOneRequired(truncateCatalogFlag, dropNodeRecordFlag)
+	cmd.MarkFlagsMutuallyExclusive(truncateCatalogFlag, dropNodeRecordFlag)
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdRecoverCatalog) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().Int64Var(
+		&c.recoverCatalogOptions.Version,
+		truncateCatalogFlag,
+		0,
+		"The global catalog version to truncate the catalogs to",
+	)
+	cmd.Flags().StringVar(
+		&c.recoverCatalogOptions.NodeName,
+		dropNodeRecordFlag,
+		"",
+		"The name of the dead node to drop from the catalogs",
+	)
+	cmd.Flags().BoolVar(
+		&c.recoverCatalogOptions.Expert,
+		"expert",
+		false,
+		"Confirm that the catalogs can be edited directly, which can lose committed changes",
+	)
+}
+
+func (c *CmdRecoverCatalog) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.recoverCatalogOptions.DatabaseOptions)
+
+	if c.parser.Changed(truncateCatalogFlag) {
+		c.recoverCatalogOptions.Action = vclusterops.CatalogRecoveryTruncate
+	} else {
+		c.recoverCatalogOptions.Action = vclusterops.CatalogRecoveryDropNode
+	}
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdRecoverCatalog) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", recoverCatalogSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.recoverCatalogOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.recoverCatalogOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.recoverCatalogOptions.DatabaseOptions)
+}
+
+func (c *CmdRecoverCatalog) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	err := vcc.VRecoverCatalog(c.recoverCatalogOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to recover catalog")
+		return err
+	}
+
+	vcc.PrintInfo("Successfully ran catalog recovery action %s on database %s",
+		c.recoverCatalogOptions.Action, c.recoverCatalogOptions.DBName)
+	c.setResult(map[string]string{"dbName": c.recoverCatalogOptions.DBName, "action": c.recoverCatalogOptions.Action})
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdRecoverCatalog
+func (c *CmdRecoverCatalog) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.recoverCatalogOptions.DatabaseOptions = *opt
+}
@@ -0,0 +1,145 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdRegenerateSpread
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdRegenerateSpread struct {
+	regenerateSpreadOptions *vclusterops.VRegenerateSpreadConfOptions
+
+	CmdBase
+}
+
+func makeCmdRegenerateSpread() *cobra.Command {
+	newCmd := &CmdRegenerateSpread{}
+
+	opt := vclusterops.VRegenerateSpreadConfOptionsFactory()
+	newCmd.regenerateSpreadOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		regenerateSpreadSubCmd,
+		"Regenerate spread.conf and restart the database to apply it",
+		`This subcommand writes a new spread.conf after the control nodes or the
+network addresses of the nodes change, instead of editing and copying it by
+hand:
+  - a primary up node writes spread.conf from its catalog
+  - the new spread.conf is sent to all the nodes of the main cluster
+  - the main cluster is stopped and started again
+
+The spread daemons of all the nodes must use the same configuration, and a
+change of the control nodes is only applied when all of them restart, so the
+whole database is restarted. The database is stopped after the user sessions
+are given --drain-seconds to disconnect. The down nodes use the new spread.conf
+when they are started. With --skip-restart, the database is not restarted and
+applies spread.conf the next time it is restarted with stop_db and start_db.
+
+Examples:
+  # Regenerate spread.conf and restart the database with config file
+  vcluster regenerate_spread --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Regenerate spread.conf without restarting the nodes
+  vcluster regenerate_spread --db-name test_db \
+    --hosts 10.20.30.40,10.20.30.41,10.20.30.42 --password testpassword --skip-restart
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, passwordFlag, configFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdRegenerateSpread) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(
+		&c.regenerateSpreadOptions.DrainSeconds,
+		"drain-seconds",
+		util.DefaultDrainSeconds,
+		"Seconds to wait for user connections to close before the database is stopped."+
+			" Default value is "+strconv.Itoa(util.DefaultDrainSeconds)+" seconds.",
+	)
+	cmd.Flags().IntVar(
+		&c.regenerateSpreadOptions.StatePollingTimeout,
+		"timeout",
+		util.DefaultStatePollingTimeout,
+		"The timeout (in seconds) to wait for the nodes to be up after the restart",
+	)
+	cmd.Flags().BoolVar(
+		&c.regenerateSpreadOptions.SkipRestart,
+		"skip-restart",
+		false,
+		"Only regenerate and send spread.conf, without restarting the database",
+	)
+}
+
+func (c *CmdRegenerateSpread) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.regenerateSpreadOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdRegenerateSpread) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", regenerateSpreadSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.regenerateSpreadOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.regenerateSpreadOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.regenerateSpreadOptions.DatabaseOptions)
+}
+
+func (c *CmdRegenerateSpread) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	err := vcc.VRegenerateSpreadConf(c.regenerateSpreadOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to regenerate spread.conf")
+		return err
+	}
+
+	vcc.PrintInfo("Successfully regenerated spread.conf of database %s", c.regenerateSpreadOptions.DBName)
+	c.setResult(map[string]any{"dbName": c.regenerateSpreadOptions.DBName,
+		"restarted": !c.regenerateSpreadOptions.SkipRestart})
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdRegenerateSpread
+func (c *CmdRegenerateSpread) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.regenerateSpreadOptions.DatabaseOptions = *opt
+}
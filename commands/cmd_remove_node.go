@@ -51,6 +51,11 @@ remove as a comma-separated list.
 
 You cannot remove nodes from a sandboxed subcluster in an Eon Mode database.
 
+In an Enterprise Mode database, the data is rebalanced away from the nodes
+before they are dropped. Use --poll-rebalance to run the rebalance in the
+background and print its progress until it completes, so that a long rebalance
+does not time out.
+
 Examples:
   # Remove multiple nodes from the existing database with config file
   vcluster db_remove_node --db-name test_db \
@@ -60,6 +65,12 @@ Examples:
   # Remove a single node from the existing database with user input
   vcluster db_remove_node --db-name test_db --remove 10.20.30.42 \
     --hosts 10.20.30.40 --data-path /data
+
+  # Remove a node from an Enterprise Mode database, polling the rebalance
+  # for at most two hours
+  vcluster db_remove_node --db-name test_db --remove 10.20.30.42 \
+    --poll-rebalance --rebalance-timeout 7200 \
+    --config /opt/vertica/config/vertica_cluster.yaml
 `,
 		[]string{dbNameFlag, configFlag, hostsFlag, catalogPathFlag, dataPathFlag, depotPathFlag, passwordFlag},
 	)
@@ -87,6 +98,18 @@ func (c *CmdRemoveNode) setLocalFlags(cmd *cobra.Command) {
 		true,
 		"Whether to force clean-up of existing directories if they are not empty",
 	)
+	cmd.Flags().BoolVar(
+		&c.removeNodeOptions.PollRebalance,
+		"poll-rebalance",
+		false,
+		"[Enterprise only] Run the rebalance of the cluster in the background and poll it until it completes",
+	)
+	cmd.Flags().IntVar(
+		&c.removeNodeOptions.RebalanceTimeout,
+		"rebalance-timeout",
+		util.DefaultRebalanceTimeoutSeconds,
+		"[Enterprise only] The timeout in seconds to poll the rebalance, a negative value polls until it ends",
+	)
 }
 
 func (c *CmdRemoveNode) Parse(inputArgv []string, logger vlog.Printer) error {
@@ -137,6 +160,9 @@ func (c *CmdRemoveNode) Run(vcc vclusterops.ClusterCommands) error {
 	vcc.LogInfo("Called method Run()")
 
 	options := c.removeNodeOptions
+	if options.PollRebalance {
+		options.ProgressReporter = &rebalanceProgressPrinter{vcc: vcc}
+	}
 
 	vdb, err := vcc.VRemoveNode(options)
 	if err != nil {
@@ -149,6 +175,7 @@ func (c *CmdRemoveNode) Run(vcc vclusterops.ClusterCommands) error {
 		vcc.PrintWarning("fail to write config file, details: %s", err)
 	}
 	vcc.PrintInfo("Successfully removed nodes %v from database %s", c.removeNodeOptions.HostsToRemove, options.DBName)
+	c.setResult(makeDBResult(&vdb))
 
 	return nil
 }
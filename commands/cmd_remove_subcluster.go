@@ -16,10 +16,16 @@
 package commands
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/util"
 	"github.com/vertica/vcluster/vclusterops/vlog"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 /* CmdRemoveSubcluster
@@ -49,6 +55,18 @@ You must provide the subcluster name with the --subcluster option.
 All hosts in the subcluster are removed. You cannot remove a sandboxed
 subcluster.
 
+You are asked to type the database name to confirm the removal. Use --yes to
+remove the subcluster without confirmation, for example in a script.
+
+The catalog, data and depot directories of the removed nodes are deleted. Use
+--keep-directories to leave them on the hosts, for example to inspect or reuse
+the storage. The directories that are kept are reported.
+
+Use --drain to remove a subcluster that serves clients. New client connections
+are redirected away from the subcluster, its client sessions are given
+--drain-seconds to disconnect, and the subcluster is stopped before its nodes
+are removed.
+
 Examples:
   # Remove a subcluster with config file
   vcluster db_remove_subcluster --subcluster sc1 \
@@ -58,6 +76,15 @@ Examples:
   vcluster db_remove_subcluster --db-name test_db \
     --hosts 10.20.30.40,10.20.30.41,10.20.30.42 --subcluster sc1 \
     --data-path /data --depot-path /data
+
+  # Remove a subcluster and keep the directories of its nodes
+  vcluster db_remove_subcluster --subcluster sc1 --keep-directories \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Drain a subcluster for up to 5 minutes, redirecting new connections to sc2,
+  # then remove it
+  vcluster db_remove_subcluster --subcluster sc1 --drain --drain-seconds 300 \
+    --redirect-to sc2 --config /opt/vertica/config/vertica_cluster.yaml
 `,
 		[]string{dbNameFlag, configFlag, hostsFlag, eonModeFlag, dataPathFlag, depotPathFlag, passwordFlag},
 	)
@@ -88,6 +115,33 @@ func (c *CmdRemoveSubcluster) setLocalFlags(cmd *cobra.Command) {
 		true,
 		"Whether force delete directories if they are not empty",
 	)
+	cmd.Flags().BoolVar(
+		&c.removeScOptions.KeepDirectories,
+		"keep-directories",
+		false,
+		"Keep the catalog, data and depot directories of the removed nodes",
+	)
+	cmd.MarkFlagsMutuallyExclusive("force-delete", "keep-directories")
+	cmd.Flags().BoolVar(
+		&c.removeScOptions.Drain,
+		"drain",
+		false,
+		"Redirect new client connections away from the subcluster and stop it once its sessions drain, before removing it",
+	)
+	cmd.Flags().IntVar(
+		&c.removeScOptions.DrainSeconds,
+		"drain-seconds",
+		util.DefaultDrainSeconds,
+		"Seconds to wait for the client sessions to disconnect with --drain."+
+			" If the value is negative, VCluster waits until all client sessions disconnect",
+	)
+	cmd.Flags().StringVar(
+		&c.removeScOptions.RedirectTargetSC,
+		"redirect-to",
+		"",
+		"The subcluster to redirect new client connections to with --drain. Defaults to any other subcluster",
+	)
+	c.setConfirmFlags(cmd)
 }
 
 func (c *CmdRemoveSubcluster) Parse(inputArgv []string, logger vlog.Printer) error {
@@ -116,6 +170,11 @@ func (c *CmdRemoveSubcluster) validateParse(logger vlog.Printer) error {
 	if err != nil {
 		return nil
 	}
+	err = c.confirmDestructive(fmt.Sprintf("remove the subcluster %s and its nodes from the database %s",
+		c.removeScOptions.SubclusterToRemove, c.removeScOptions.DBName), c.removeScOptions.DBName, logger)
+	if err != nil {
+		return err
+	}
 	return c.setDBPassword(&c.removeScOptions.DatabaseOptions)
 }
 
@@ -129,6 +188,7 @@ func (c *CmdRemoveSubcluster) Run(vcc vclusterops.ClusterCommands) error {
 	options := c.removeScOptions
 
 	vdb, err := vcc.VRemoveSubcluster(options)
+	printForceClosedSessions(vcc, options.ForceClosedSessions)
 	if err != nil {
 		return err
 	}
@@ -140,10 +200,23 @@ func (c *CmdRemoveSubcluster) Run(vcc vclusterops.ClusterCommands) error {
 	}
 	vcc.PrintInfo("Successfully removed subcluster %s from database %s",
 		options.SubclusterToRemove, options.DBName)
+	printKeptDirectories(vcc, options.KeptDirectories)
+	result := makeDBResult(&vdb)
+	result.KeptDirectories = options.KeptDirectories
+	c.setResult(result)
 
 	return nil
 }
 
+// printKeptDirectories prints the directories that are left on each removed host
+func printKeptDirectories(vcc vclusterops.ClusterCommands, keptDirectories map[string][]string) {
+	hosts := maps.Keys(keptDirectories)
+	slices.Sort(hosts)
+	for _, host := range hosts {
+		vcc.PrintInfo("Kept directories on host %s: %s", host, strings.Join(keptDirectories[host], ", "))
+	}
+}
+
 // SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdRemoveSubcluster
 func (c *CmdRemoveSubcluster) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
 	c.removeScOptions.DatabaseOptions = *opt
@@ -0,0 +1,165 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdReplaceNode
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdReplaceNode struct {
+	replaceNodeOptions *vclusterops.VReplaceNodeOptions
+	keepOldHost        bool
+
+	CmdBase
+}
+
+func makeCmdReplaceNode() *cobra.Command {
+	// CmdReplaceNode
+	newCmd := &CmdReplaceNode{}
+	opt := vclusterops.VReplaceNodeOptionsFactory()
+	newCmd.replaceNodeOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		replaceNodeSubCmd,
+		"Replace the host of a down node",
+		`This subcommand replaces the host of a down node with a new host, for example
+once the machine of the node has failed. The node keeps its name and its place
+in the database, so the other nodes stay up and no data is rebalanced.
+
+You need to provide the --old-host option with the host of the down node, and
+the --new-host option with the host that replaces it. The new host must not be
+in the database.
+
+The directories of the node are created on the new host, the node is re-IPed
+to the new host, and the node is started there. The directories of the node on
+the old host are then deleted, if the old host can be reached. Use
+--keep-old-host to leave them.
+
+Examples:
+  # Replace the host of a node with config file
+  vcluster replace_node --old-host 10.20.30.42 --new-host 10.20.30.43 \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Replace the host of a node with user input
+  vcluster replace_node --db-name test_db --hosts 10.20.30.40,10.20.30.41 \
+    --old-host 10.20.30.42 --new-host 10.20.30.43 --data-path /data
+`,
+		[]string{dbNameFlag, configFlag, hostsFlag, catalogPathFlag, dataPathFlag, depotPathFlag, passwordFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	// require the old and new hosts
+	markFlagsRequired(cmd, []string{"old-host", "new-host"})
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdReplaceNode) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&c.replaceNodeOptions.OldHost,
+		"old-host",
+		"",
+		"The host of the down node to replace",
+	)
+	cmd.Flags().StringVar(
+		&c.replaceNodeOptions.NewHost,
+		"new-host",
+		"",
+		"The host that replaces the old host",
+	)
+	cmd.Flags().BoolVar(
+		&c.replaceNodeOptions.ForceRemoval,
+		"force-removal",
+		false,
+		"Whether to force clean-up of existing directories on the new host",
+	)
+	cmd.Flags().BoolVar(
+		&c.keepOldHost,
+		"keep-old-host",
+		false,
+		"Keep the directories of the node on the old host",
+	)
+	cmd.Flags().IntVar(
+		&c.replaceNodeOptions.StatePollingTimeout,
+		"timeout",
+		util.DefaultStatePollingTimeout,
+		"The timeout (in seconds) to wait for the node to be up on the new host",
+	)
+}
+
+func (c *CmdReplaceNode) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogMaskedArgParse(c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.replaceNodeOptions.DatabaseOptions)
+	return c.validateParse(logger)
+}
+
+func (c *CmdReplaceNode) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()")
+
+	c.replaceNodeOptions.CleanupOldHost = !c.keepOldHost
+	err := c.getCertFilesFromCertPaths(&c.replaceNodeOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.replaceNodeOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.replaceNodeOptions.DatabaseOptions)
+}
+
+func (c *CmdReplaceNode) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.LogInfo("Called method Run()")
+
+	options := c.replaceNodeOptions
+
+	vdb, err := vcc.VReplaceNode(options)
+	if err != nil {
+		return err
+	}
+
+	// write db info to vcluster config file
+	err = writeConfig(&vdb, vcc.GetLog())
+	if err != nil {
+		vcc.PrintWarning("fail to write config file, details: %s", err)
+	}
+	vcc.PrintInfo("Successfully replaced host %s with host %s in database %s", options.OldHost, options.NewHost, options.DBName)
+	c.setResult(makeDBResult(&vdb))
+
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdReplaceNode
+func (c *CmdReplaceNode) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.replaceNodeOptions.DatabaseOptions = *opt
+}
@@ -52,6 +52,11 @@ to restart multiple nodes without a config file. If the IP_TO_RESTART value
 does not match the information stored in the catalog for NODE_NAME, Vertica
 updates the catalog with the IP_TO_RESTART value and restarts the node.
 
+The config files are synced from an up primary node to the nodes before
+they restart. The nodes whose catalog is behind the up nodes are reported,
+and they recover the latest catalog from the up nodes once they start. Use
+--retries to start again the nodes that fail to come up.
+
 Examples:
   # Restart a single node in the database with config file
   vcluster restart_node --db-name test_db \
@@ -96,6 +101,12 @@ func (c *CmdRestartNodes) setLocalFlags(cmd *cobra.Command) {
 		util.DefaultTimeoutSeconds,
 		"The timeout (in seconds) to wait for polling node state operation",
 	)
+	cmd.Flags().IntVar(
+		&c.restartNodesOptions.StartRetries,
+		"retries",
+		0,
+		"The number of times to start again the nodes that are still down when they fail to come up",
+	)
 }
 
 func (c *CmdRestartNodes) Parse(inputArgv []string, logger vlog.Printer) error {
@@ -145,6 +156,7 @@ func (c *CmdRestartNodes) Run(vcc vclusterops.ClusterCommands) error {
 		hostToRestart = append(hostToRestart, ip)
 	}
 	vcc.PrintInfo("Successfully restart hosts %s of the database %s", hostToRestart, options.DBName)
+	c.setResult(map[string]any{"dbName": options.DBName, "nodes": options.Nodes})
 
 	return nil
 }
@@ -30,7 +30,8 @@ import (
  */
 type CmdReviveDB struct {
 	CmdBase
-	reviveDBOptions *vclusterops.VReviveDatabaseOptions
+	reviveDBOptions     *vclusterops.VReviveDatabaseOptions
+	hostMappingFilePath string
 }
 
 func makeCmdReviveDB() *cobra.Command {
@@ -48,11 +49,20 @@ This could also restore an Eon Mode database to a given restore point.
 
 The communal storage path must be provided and it cannot be empty.
 If access to communal storage requires access keys, these can be provided
-through the --config-param option.
+through the --config-param option. An S3 communal storage can also be accessed
+with temporary credentials from environment variables, with an IAM role that
+is assumed with --aws-role-arn, or with the instance profile of each host
+with --aws-use-instance-profile. An Azure communal storage can be accessed
+with the managed identity of the hosts or with a service principal, chosen by
+--azure-auth-method. A GCS communal storage can be accessed with the key of a
+service account or with the workload identity of the hosts, chosen by
+--gcs-auth-method.
 
 You must also specify a set of hosts that matches the number of hosts when the
 database was running. You can omit the hosts only if --display-only
-is specified.
+is specified. The nodes are re-IPed to the new hosts as the database is
+revived: the hosts are given to the nodes in the order of the node names,
+or as mapped in the file of --host-mapping-file.
 
 The name of the database must be provided.
 
@@ -79,15 +89,30 @@ Examples:
     --config /opt/vertica/config/vertica_cluster.yaml --force-removal \
     --ignore-cluster-lease --restore-point-archive db --restore-point-index 1
 
+  # Revive a database on new hosts, giving each node the host in a mapping
+  # file
+  vcluster revive_db --db-name test_db \
+    --hosts 10.20.30.40,10.20.30.41,10.20.30.42 \
+    --communal-storage-location /communal \
+    --host-mapping-file /data/host_mapping.json
+
+  # Revive a database from S3 with the credentials of an assumed IAM role
+  vcluster revive_db --db-name test_db \
+    --hosts 10.20.30.40,10.20.30.41,10.20.30.42 \
+    --communal-storage-location s3://bucket/test_db \
+    --aws-role-arn arn:aws:iam::123456789012:role/vertica --aws-use-instance-profile
+
 `,
 		[]string{dbNameFlag, hostsFlag, communalStorageLocationFlag, configFlag, outputFileFlag, configParamFlag},
 	)
 
 	// local flags
 	newCmd.setLocalFlags(cmd)
+	newCmd.setHostPathFlags(cmd)
 
 	// require db-name and communal-storage-location
 	markFlagsRequired(cmd, []string{dbNameFlag, communalStorageLocationFlag})
+	markFlagsFileName(cmd, map[string][]string{"host-mapping-file": {"json"}})
 
 	return cmd
 }
@@ -139,6 +164,16 @@ func (c *CmdReviveDB) setLocalFlags(cmd *cobra.Command) {
 		"",
 		"The identifier of the restore point in the restore archive to restore from",
 	)
+	cmd.Flags().StringVar(
+		&c.hostMappingFilePath,
+		"host-mapping-file",
+		"",
+		"Absolute path of a JSON file that maps the address of each node in the catalog to its new host,"+
+			" in the format of the re-ip file. Without it, the hosts are given to the nodes in the order of the node names",
+	)
+	setAWSAuthFlags(cmd, &c.reviveDBOptions.AWSAuthOptions)
+	setAzureAuthFlags(cmd, &c.reviveDBOptions.AzureAuthOptions)
+	setGCSAuthFlags(cmd, &c.reviveDBOptions.GCSAuthOptions)
 	// only one of restore-point-index or restore-point-id" will be required
 	cmd.MarkFlagsMutuallyExclusive("restore-point-index", "restore-point-id")
 }
@@ -157,6 +192,15 @@ func (c *CmdReviveDB) validateParse(logger vlog.Printer) error {
 	if err != nil {
 		return err
 	}
+	c.setHostPathPrefixes(&c.reviveDBOptions.DatabaseOptions)
+	setAzureClientSecret(&c.reviveDBOptions.AzureAuthOptions)
+
+	if c.hostMappingFilePath != "" {
+		err = c.reviveDBOptions.ReadHostMappingFile(c.hostMappingFilePath)
+		if err != nil {
+			return err
+		}
+	}
 
 	// when --display-only is provided, we do not need to parse some base options like hostListStr
 	if c.reviveDBOptions.DisplayOnly {
@@ -175,6 +219,7 @@ func (c *CmdReviveDB) Run(vcc vclusterops.ClusterCommands) error {
 	}
 
 	if c.reviveDBOptions.DisplayOnly {
+		c.setResult(rawJSONOrString(dbInfo))
 		c.writeCmdOutputToFile(globals.file, []byte(dbInfo), vcc.GetLog())
 		vcc.LogInfo("database details: ", "db-info", dbInfo)
 		return nil
@@ -187,6 +232,7 @@ func (c *CmdReviveDB) Run(vcc vclusterops.ClusterCommands) error {
 	}
 
 	vcc.PrintInfo("Successfully revived database %s", c.reviveDBOptions.DBName)
+	c.setResult(makeDBResult(vdb))
 
 	return nil
 }
@@ -0,0 +1,204 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+const (
+	newKeyFileFlag    = "new-key-file"
+	newCertFileFlag   = "new-cert-file"
+	newCaCertFileFlag = "new-ca-cert-file"
+	skipHTTPSFlag     = "skip-https"
+)
+
+/* CmdRotateCerts
+ *
+ * Parses arguments to VRotateNMACerts and calls
+ * the high-level function for VRotateNMACerts.
+ *
+ * Implements ClusterCommand interface
+ */
+
+type CmdRotateCerts struct {
+	CmdBase
+	rotateCertsOptions *vclusterops.VRotateNMACertsOptions
+
+	newKeyFile    string
+	newCertFile   string
+	newCaCertFile string
+	skipHTTPS     bool
+}
+
+func makeCmdRotateCerts() *cobra.Command {
+	newCmd := &CmdRotateCerts{}
+	opt := vclusterops.VRotateNMACertsOptionsFactory()
+	newCmd.rotateCertsOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		rotateCertsSubCmd,
+		"Rotate the TLS certificates of the NMA and HTTPS services",
+		`This subcommand pushes new TLS certificates to the node management agent
+(NMA) on all hosts and to the HTTPS service on all up hosts.
+
+The services are rotated one host at a time. Each host restarts the service
+with the new certificates and is checked to accept connections with them
+before the next host is rotated. The previous certificates are discarded after
+all hosts are validated. If any host fails, all rotated hosts are rolled back
+to the previous certificates.
+
+The current certificates are given with --key-file and --cert-file.
+
+Examples:
+  # Rotate the certificates of the NMA and HTTPS services with config file
+  vcluster rotate_certs --key-file /path/to/key.pem --cert-file /path/to/cert.pem \
+    --new-key-file /path/to/new_key.pem --new-cert-file /path/to/new_cert.pem \
+    --new-ca-cert-file /path/to/new_ca.pem \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Rotate the certificates of the NMA only with user input
+  vcluster rotate_certs --db-name test_db --hosts 10.20.30.40,10.20.30.41,10.20.30.42 \
+    --password testpassword --skip-https \
+    --new-key-file /path/to/new_key.pem --new-cert-file /path/to/new_cert.pem
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, configFlag, passwordFlag, keyFileFlag, certFileFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	// require the new certs
+	markFlagsRequired(cmd, []string{newKeyFileFlag, newCertFileFlag})
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdRotateCerts) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&c.newKeyFile,
+		newKeyFileFlag,
+		"",
+		"Path to the new private key file",
+	)
+	cmd.Flags().StringVar(
+		&c.newCertFile,
+		newCertFileFlag,
+		"",
+		"Path to the new certificate file",
+	)
+	cmd.Flags().StringVar(
+		&c.newCaCertFile,
+		newCaCertFileFlag,
+		"",
+		"Path to the new CA certificate file",
+	)
+	cmd.Flags().BoolVar(
+		&c.skipHTTPS,
+		skipHTTPSFlag,
+		false,
+		"Only rotate the certificates of the NMA, not the HTTPS service",
+	)
+	cmd.Flags().IntVar(
+		&c.rotateCertsOptions.PollingTimeout,
+		"timeout",
+		util.DefaultTimeoutSeconds,
+		"The timeout (in seconds) to wait for a service to restart with the new certificates",
+	)
+}
+
+func (c *CmdRotateCerts) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogMaskedArgParse(c.argv)
+
+	// reset some options that are not included in user input
+	c.ResetUserInputOptions(&c.rotateCertsOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdRotateCerts) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()")
+	c.rotateCertsOptions.RotateHTTPSCerts = !c.skipHTTPS
+
+	err := c.readNewCertFiles()
+	if err != nil {
+		return err
+	}
+
+	err = c.getCertFilesFromCertPaths(&c.rotateCertsOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.rotateCertsOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.rotateCertsOptions.DatabaseOptions)
+}
+
+// readNewCertFiles reads the new certs from their files
+func (c *CmdRotateCerts) readNewCertFiles() error {
+	keyData, err := os.ReadFile(c.newKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read new private key file, details %w", err)
+	}
+	c.rotateCertsOptions.NewKey = string(keyData)
+
+	certData, err := os.ReadFile(c.newCertFile)
+	if err != nil {
+		return fmt.Errorf("failed to read new certificate file, details %w", err)
+	}
+	c.rotateCertsOptions.NewCert = string(certData)
+
+	if c.newCaCertFile != "" {
+		caCertData, err := os.ReadFile(c.newCaCertFile)
+		if err != nil {
+			return fmt.Errorf("failed to read new CA certificate file, details %w", err)
+		}
+		c.rotateCertsOptions.NewCaCert = string(caCertData)
+	}
+	return nil
+}
+
+func (c *CmdRotateCerts) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.LogInfo("Called method Run()")
+
+	options := c.rotateCertsOptions
+
+	err := vcc.VRotateNMACerts(options)
+	if err != nil {
+		vcc.LogError(err, "failed to rotate certificates")
+		return err
+	}
+	vcc.PrintInfo("Successfully rotated certificates of the database %s", options.DBName)
+	c.setResult(map[string]string{"dbName": options.DBName})
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdRotateCerts
+func (c *CmdRotateCerts) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.rotateCertsOptions.DatabaseOptions = *opt
+}
@@ -132,6 +132,9 @@ func (c *CmdSandboxSubcluster) Run(vcc vclusterops.ClusterCommands) error {
 
 	err := vcc.VSandbox(&options)
 	vcc.PrintInfo("Completed method Run() for command " + sandboxSubCmd)
+	if err == nil {
+		c.setResult(map[string]string{"dbName": options.DBName, "subcluster": options.SCName, "sandbox": options.SandboxName})
+	}
 	return err
 }
 
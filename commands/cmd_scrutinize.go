@@ -22,6 +22,8 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
 
@@ -74,6 +76,9 @@ type CmdScrutinize struct {
 	CmdBase
 	secretStoreRetriever secretRetriever
 	sOptions             vclusterops.VScrutinizeOptions
+	resumeID             string
+	// the sandbox to collect diagnostics from
+	sandbox string
 }
 
 func makeCmdScrutinize() *cobra.Command {
@@ -129,6 +134,20 @@ func (c *CmdScrutinize) setLocalFlags(cmd *cobra.Command) {
 		"Name of the generated tarball. If empty an auto-generated "+
 			"name is used following the pattern VerticaScrutinize.<timestamp>",
 	)
+	cmd.Flags().StringVar(
+		&c.resumeID,
+		"id",
+		"",
+		"ID of a previous scrutinize run to resume. The hosts and batches already "+
+			"collected by that run are skipped",
+	)
+	cmd.Flags().StringVar(
+		&c.sandbox,
+		sandboxFlag,
+		"",
+		"Name of the sandbox to collect diagnostics from. Without --hosts, "+
+			"the hosts of the sandbox are read from the config file",
+	)
 	cmd.Flags().StringVar(
 		&c.sOptions.LogAgeOldestTime,
 		"log-age-oldest-time",
@@ -150,8 +169,34 @@ func (c *CmdScrutinize) setLocalFlags(cmd *cobra.Command) {
 		"Maximum age of archived vertica log files to collect "+
 			"in hours, default "+fmt.Sprint(vclusterops.ScrutinizeLogMaxAgeHoursDefault),
 	)
+	// start-time and end-time are shorter names of log-age-oldest-time and log-age-newest-time
+	cmd.Flags().StringVar(
+		&c.sOptions.LogAgeOldestTime,
+		"start-time",
+		"",
+		"Only collect archived vertica log files newer than this timestamp, "+
+			"formatted as "+vclusterops.ScrutinizeHelpTimeFormatDesc,
+	)
+	cmd.Flags().StringVar(
+		&c.sOptions.LogAgeNewestTime,
+		"end-time",
+		"",
+		"Only collect archived vertica log files older than this timestamp, "+
+			"formatted as "+vclusterops.ScrutinizeHelpTimeFormatDesc,
+	)
+	cmd.Flags().Int64Var(
+		&c.sOptions.HostLogSizeLimitMB,
+		"host-log-size-limit",
+		0,
+		"Maximum total size in MB of vertica log files to collect from each host. "+
+			"The newest logs are collected first. No limit if 0",
+	)
 	cmd.MarkFlagsMutuallyExclusive("log-age-hours", "log-age-oldest-time")
 	cmd.MarkFlagsMutuallyExclusive("log-age-hours", "log-age-newest-time")
+	cmd.MarkFlagsMutuallyExclusive("log-age-hours", "start-time")
+	cmd.MarkFlagsMutuallyExclusive("log-age-hours", "end-time")
+	cmd.MarkFlagsMutuallyExclusive("start-time", "log-age-oldest-time")
+	cmd.MarkFlagsMutuallyExclusive("end-time", "log-age-newest-time")
 	cmd.Flags().BoolVar(
 		&c.sOptions.ExcludeContainers,
 		"exclude-containers",
@@ -184,6 +229,67 @@ func (c *CmdScrutinize) setLocalFlags(cmd *cobra.Command) {
 		"Include information describing all UDX functions, "+
 			"which can be expensive to gather on Eon",
 	)
+	cmd.Flags().StringSliceVar(
+		&c.sOptions.IncludeBatches,
+		"include-batches",
+		[]string{},
+		fmt.Sprintf("Comma-separated list of batches to collect, from %v. Defaults to %v",
+			vclusterops.ScrutinizeSelectableBatches, vclusterops.ScrutinizeDefaultBatches),
+	)
+	cmd.Flags().StringSliceVar(
+		&c.sOptions.ExcludeBatches,
+		"exclude-batches",
+		[]string{},
+		"Comma-separated list of batches not to collect",
+	)
+	cmd.Flags().BoolVar(
+		&c.sOptions.Redact,
+		"redact",
+		false,
+		"Scrub IP addresses, host names, user names and quoted query literals "+
+			"from the collected files before packaging them",
+	)
+	cmd.Flags().StringSliceVar(
+		&c.sOptions.RedactPatterns,
+		"redact-patterns",
+		[]string{},
+		"Comma-separated list of extra regular expressions of the data to scrub. Requires --redact",
+	)
+	cmd.Flags().IntVar(
+		&c.sOptions.DownloadConcurrency,
+		"download-concurrency",
+		0,
+		"Maximum number of host tarballs to download at the same time. No limit if 0",
+	)
+	cmd.Flags().Int64Var(
+		&c.sOptions.DownloadBandwidthLimitMB,
+		"download-bandwidth-limit",
+		0,
+		"Maximum bandwidth of each tarball download in MB per second. No limit if 0",
+	)
+	cmd.Flags().StringVar(
+		&c.sOptions.Compression,
+		"compression",
+		vclusterops.ScrutinizeCompressionGzip,
+		fmt.Sprintf("Compression of the tarball of each batch, one of %s, %s or %s",
+			vclusterops.ScrutinizeCompressionGzip, vclusterops.ScrutinizeCompressionZstd,
+			vclusterops.ScrutinizeCompressionNone),
+	)
+	cmd.Flags().StringVar(
+		&c.sOptions.ArchiveCompression,
+		"archive-compression",
+		vclusterops.ScrutinizeCompressionNone,
+		fmt.Sprintf("Compression of the final archive, one of %s, %s or %s",
+			vclusterops.ScrutinizeCompressionGzip, vclusterops.ScrutinizeCompressionZstd,
+			vclusterops.ScrutinizeCompressionNone),
+	)
+	cmd.Flags().StringVar(
+		&c.sOptions.SystemTableFormat,
+		"system-table-format",
+		"",
+		fmt.Sprintf("Format of the collected system tables, %s or %s",
+			vclusterops.ScrutinizeSystemTableFormatCSV, vclusterops.ScrutinizeSystemTableFormatJSON),
+	)
 }
 
 func (c *CmdScrutinize) Parse(inputArgv []string, logger vlog.Printer) error {
@@ -200,6 +306,9 @@ func (c *CmdScrutinize) Parse(inputArgv []string, logger vlog.Printer) error {
 	if c.parser.Changed("tarball-name") {
 		c.validateTarballName(logger)
 	}
+	if c.resumeID != "" {
+		c.sOptions.ID = c.resumeID
+	}
 	if c.sOptions.TarballName == "" {
 		// If the tarball name is empty, the final tarball
 		// name will be the auto-generated id
@@ -217,6 +326,11 @@ func (c *CmdScrutinize) validateParse(logger vlog.Printer) error {
 		return err
 	}
 
+	err = c.setSandboxHosts(&c.sOptions.DatabaseOptions, c.sandbox, false /*main cluster*/)
+	if err != nil {
+		return err
+	}
+
 	// parses host list and ipv6 - eon is irrelevant but handled
 	err = c.ValidateParseBaseOptions(&c.sOptions.DatabaseOptions)
 	if err != nil {
@@ -247,12 +361,14 @@ func (c *CmdScrutinize) Run(vcc vclusterops.ClusterCommands) error {
 		return err
 	}
 
+	c.sOptions.ProgressReporter = makeScrutinizeProgressPrinter(vcc)
 	err = vcc.VScrutinize(&c.sOptions)
 	if err != nil {
 		vcc.LogError(err, "scrutinize run failed")
 		return err
 	}
 	vcc.PrintInfo("Successfully completed scrutinize run for the database %s", c.sOptions.DBName)
+	c.setResult(map[string]string{"dbName": c.sOptions.DBName, "id": c.sOptions.ID, "tarballName": c.sOptions.TarballName})
 	return err
 }
 
@@ -461,6 +577,9 @@ func (c *CmdScrutinize) readOptionsFromK8sEnv(logger vlog.Printer) (allErrs erro
 
 // validateTarballName checks that the tarball name has the correct format
 func (c *CmdScrutinize) validateTarballName(logger vlog.Printer) {
+	if c.resumeID != "" {
+		c.sOptions.ID = c.resumeID
+	}
 	if c.sOptions.TarballName == "" {
 		logger.Info("The tarball name is empty. An auto-generated will be used")
 	}
@@ -536,3 +655,45 @@ func lookupAndCheckSecretEnvVars(nameEnv, namespaceEnv string) (*types.Namespace
 func (c *CmdScrutinize) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
 	c.sOptions.DatabaseOptions = *opt
 }
+
+// how often the download progress of a tarball is printed
+const scrutinizeDownloadPrintInterval = 5 * time.Second
+
+// scrutinizeProgressPrinter prints the progress of each batch tarball. Download
+// progress is printed at most every scrutinizeDownloadPrintInterval per tarball.
+type scrutinizeProgressPrinter struct {
+	vcc           vclusterops.ClusterCommands
+	lastPrintTime map[string]time.Time
+	mutex         sync.Mutex
+}
+
+func makeScrutinizeProgressPrinter(vcc vclusterops.ClusterCommands) *scrutinizeProgressPrinter {
+	return &scrutinizeProgressPrinter{
+		vcc:           vcc,
+		lastPrintTime: make(map[string]time.Time),
+	}
+}
+
+func (printer *scrutinizeProgressPrinter) ReportProgress(event vclusterops.ProgressEvent) {
+	if event.Stage == vclusterops.ProgressStageDownloading {
+		key := event.Host + "/" + event.Item
+		printer.mutex.Lock()
+		throttled := time.Since(printer.lastPrintTime[key]) < scrutinizeDownloadPrintInterval
+		if !throttled {
+			printer.lastPrintTime[key] = time.Now()
+		}
+		printer.mutex.Unlock()
+		if throttled {
+			return
+		}
+	}
+
+	msg := fmt.Sprintf("[%d/%d] batch %s on host %s: %s", event.Done, event.Total, event.Item, event.Host, event.Stage)
+	if event.Bytes > 0 {
+		msg += fmt.Sprintf(" (%d bytes)", event.Bytes)
+	}
+	if event.ETA > 0 {
+		msg += fmt.Sprintf(", about %s remaining", event.ETA.Round(time.Second))
+	}
+	printer.vcc.PrintInfo("%s", msg)
+}
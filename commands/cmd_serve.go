@@ -0,0 +1,418 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/metrics"
+	"github.com/vertica/vcluster/vclusterops/tracing"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+const (
+	listenFlag        = "listen"
+	tokenFileFlag     = "token-file"
+	tlsCertFileFlag   = "tls-cert-file"
+	tlsKeyFileFlag    = "tls-key-file"
+	insecureFlag      = "insecure"
+	metricsListenFlag = "metrics-listen"
+	// the environment variable that gives the token of the server
+	vclusterServeTokenEnv = "VCLUSTER_SERVE_TOKEN"
+
+	defaultListenAddress = "127.0.0.1:8089"
+	// the path prefix of the endpoints, which changes with incompatible changes
+	serveAPIPrefix = "/v1/"
+	// the path of the metrics of the op engine, in the Prometheus text format
+	serveMetricsPath = "/metrics"
+	// the limit of a request body, which only has the options of a command
+	maxServeRequestBytes = 1 << 20
+	serveHeaderTimeout   = 10 * time.Second
+	// a longer correlation ID of a caller is replaced, so that it does not bloat the logs
+	maxCorrelationIDLength = 128
+	serveShutdownTimeout   = 30 * time.Second
+)
+
+/* CmdServe
+ *
+ * Implements a REST server that runs the vclusterops API
+ */
+type CmdServe struct {
+	listen      string
+	tokenFile   string
+	tlsCertFile string
+	tlsKeyFile  string
+	// serve plain HTTP on an address that is not a loopback address
+	insecure bool
+	// the address of an unauthenticated server that only serves the metrics
+	metricsListen string
+}
+
+func makeCmdServe() *cobra.Command {
+	newCmd := &CmdServe{}
+
+	cmd := makeSimpleCobraCmd(
+		serveSubCmd,
+		"Serve the vcluster operations over a REST API",
+		`This subcommand starts a REST server that runs the vcluster operations, so
+that a central tool can administer clusters without running vcluster for each
+operation. The server runs one operation at a time.
+
+The endpoints take the options of the operation as a JSON object in a POST
+request, with the names of the fields of the vclusterops options, and return
+the same result as --output-format json:
+  POST /v1/create_db     VCreateDatabaseOptions
+  POST /v1/stop_db       VStopDatabaseOptions
+  POST /v1/status        VClusterHealthOptions
+  POST /v1/scrutinize    VScrutinizeOptions
+
+The metrics of the operations, such as their duration, the latency of the
+requests to each host, and the failures, are served in the Prometheus text
+format at GET /metrics. Use --metrics-listen to also serve them on another
+address without the token, for a scraper on the local host.
+
+Each request must have the header "Authorization: Bearer <token>", where the
+token is read from the file given by --token-file, or the VCLUSTER_SERVE_TOKEN
+environment variable. The server listens on localhost by default. As the
+requests have the token and the database password, the server must serve HTTPS
+with --tls-cert-file and --tls-key-file to listen on other addresses, unless
+--insecure is given, such as behind a proxy that terminates TLS.
+
+Examples:
+  # Serve on localhost
+  vcluster serve --token-file /home/dbadmin/.vcluster-token
+
+  # Serve HTTPS on all addresses
+  vcluster serve --listen :8089 --token-file /home/dbadmin/.vcluster-token \
+    --tls-cert-file /home/dbadmin/server.crt --tls-key-file /home/dbadmin/server.key
+
+  # Get the status of a database
+  curl -H "Authorization: Bearer $TOKEN" -X POST http://127.0.0.1:8089/v1/status \
+    -d '{"DBName": "test_db", "RawHosts": ["10.20.30.40"], "Password": "testpassword"}'
+`)
+
+	cmd.Flags().StringVar(&newCmd.listen, listenFlag, defaultListenAddress, "The address that the server listens on")
+	cmd.Flags().StringVar(&newCmd.tokenFile, tokenFileFlag, "",
+		"Path to the file of the token that the requests must have. "+
+			"The token can also be given by the "+vclusterServeTokenEnv+" environment variable")
+	cmd.Flags().StringVar(&newCmd.tlsCertFile, tlsCertFileFlag, "", "Path to the certificate file to serve HTTPS")
+	cmd.Flags().StringVar(&newCmd.tlsKeyFile, tlsKeyFileFlag, "", "Path to the key file to serve HTTPS")
+	cmd.MarkFlagsRequiredTogether(tlsCertFileFlag, tlsKeyFileFlag)
+	cmd.Flags().BoolVar(&newCmd.insecure, insecureFlag, false,
+		"Serve plain HTTP on an address that is not a loopback address, which sends the token and the passwords in cleartext")
+	cmd.Flags().StringVar(&newCmd.metricsListen, metricsListenFlag, "",
+		"The address of a server that serves the metrics without the token, e.g. 127.0.0.1:9090")
+
+	cmd.RunE = func(_ *cobra.Command, _ []string) error {
+		return newCmd.run()
+	}
+
+	return cmd
+}
+
+func (c *CmdServe) run() error {
+	// the server has no log format flag, so the format of the environment is validated
+	logFormat := getDefaultLogFormat()
+	err := validateLogFormat(logFormat)
+	if err != nil {
+		return &usageError{err: err}
+	}
+	err = c.validateListen()
+	if err != nil {
+		return err
+	}
+	token, err := c.readToken()
+	if err != nil {
+		return err
+	}
+
+	logger := vlog.Printer{LogFormat: logFormat, LogRotation: getLogRotation(),
+		SystemLog: os.Getenv(vclusterSystemLogEnv)}
+	logger.SetupOrDie(logPath)
+	stopTracing := startCmdTracing()
+	defer stopTracing()
+	handler := makeServeHandler(vclusterops.VClusterCommands{
+		VClusterCommandsLogger: vclusterops.VClusterCommandsLogger{Log: logger.WithName(serveSubCmd)},
+		AuditLogger:            getAuditLogger(logPath),
+	}, token)
+	server := &http.Server{
+		Addr:              c.listen,
+		Handler:           handler,
+		ReadHeaderTimeout: serveHeaderTimeout,
+	}
+
+	servers := []*http.Server{server}
+	if c.metricsListen != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle(serveMetricsPath, metrics.DefaultRegistry.Handler())
+		metricsServer := &http.Server{
+			Addr:              c.metricsListen,
+			Handler:           metricsMux,
+			ReadHeaderTimeout: serveHeaderTimeout,
+		}
+		servers = append(servers, metricsServer)
+		go func() {
+			if e := metricsServer.ListenAndServe(); !errors.Is(e, http.ErrServerClosed) {
+				logger.Error(e, "fail to serve the metrics")
+			}
+		}()
+		fmt.Printf("Serving the metrics on %s%s\n", c.metricsListen, serveMetricsPath)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		for _, s := range servers {
+			if e := s.Shutdown(shutdownCtx); e != nil {
+				logger.Error(e, "fail to shut down the server", "address", s.Addr)
+			}
+		}
+	}()
+
+	fmt.Printf("Serving the vcluster operations on %s\n", c.listen)
+	if c.tlsCertFile != "" {
+		err = server.ListenAndServeTLS(c.tlsCertFile, c.tlsKeyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// validateListen refuses to serve plain HTTP on an address that other hosts
+// can reach, unless it is explicitly asked for
+func (c *CmdServe) validateListen() error {
+	host, _, err := net.SplitHostPort(c.listen)
+	if err != nil {
+		return &usageError{err: fmt.Errorf("invalid --%s address %q: %w", listenFlag, c.listen, err)}
+	}
+	if c.tlsCertFile != "" || c.insecure || isLoopbackHost(host) {
+		return nil
+	}
+	return &usageError{err: fmt.Errorf("must serve HTTPS with --%s and --%s to listen on %s, "+
+		"as the requests have the token and the database password. Use --%s to serve plain HTTP anyway",
+		tlsCertFileFlag, tlsKeyFileFlag, c.listen, insecureFlag)}
+}
+
+// isLoopbackHost returns true if the host of a listen address is only reachable
+// from the local host. An empty host listens on all addresses.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (c *CmdServe) readToken() (string, error) {
+	token := os.Getenv(vclusterServeTokenEnv)
+	if c.tokenFile != "" {
+		tokenBytes, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("fail to read the token file %q: %w", c.tokenFile, err)
+		}
+		token = strings.TrimSpace(string(tokenBytes))
+	}
+	if token == "" {
+		return "", fmt.Errorf("must provide the token of the server with --%s or the %s environment variable",
+			tokenFileFlag, vclusterServeTokenEnv)
+	}
+	return token, nil
+}
+
+// serveHandler runs the operations of the requests one at a time, so that two
+// requests do not change the database at once. Each operation has the context
+// of its request.
+type serveHandler struct {
+	vcc   vclusterops.VClusterCommands
+	token string
+	mu    sync.Mutex
+	mux   *http.ServeMux
+}
+
+func makeServeHandler(vcc vclusterops.VClusterCommands, token string) *serveHandler {
+	h := &serveHandler{vcc: vcc, token: token, mux: http.NewServeMux()}
+	h.mux.Handle(serveMetricsPath, metrics.DefaultRegistry.Handler())
+	h.handleOp(createDBSubCmd, func(vcc vclusterops.VClusterCommands, body []byte) (any, error) {
+		options := vclusterops.VCreateDatabaseOptionsFactory()
+		if err := decodeServeOptions(body, &options); err != nil {
+			return nil, err
+		}
+		vdb, err := vcc.VCreateDatabase(&options)
+		if err != nil {
+			return nil, err
+		}
+		return makeDBResult(&vdb), nil
+	})
+	h.handleOp(stopDBSubCmd, func(vcc vclusterops.VClusterCommands, body []byte) (any, error) {
+		options := vclusterops.VStopDatabaseOptionsFactory()
+		if err := decodeServeOptions(body, &options); err != nil {
+			return nil, err
+		}
+		return map[string]string{"dbName": options.DBName}, vcc.VStopDatabase(&options)
+	})
+	h.handleOp(clusterHealthSubCmd, func(vcc vclusterops.VClusterCommands, body []byte) (any, error) {
+		options := vclusterops.VClusterHealthOptionsFactory()
+		if err := decodeServeOptions(body, &options); err != nil {
+			return nil, err
+		}
+		health, err := vcc.VClusterHealth(&options)
+		if err != nil {
+			return nil, err
+		}
+		return health, nil
+	})
+	h.handleOp(scrutinizeSubCmd, func(vcc vclusterops.VClusterCommands, body []byte) (any, error) {
+		options := vclusterops.VScrutinizeOptionsFactory()
+		if err := decodeServeOptions(body, &options); err != nil {
+			return nil, err
+		}
+		err := vcc.VScrutinize(&options)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"dbName": options.DBName, "id": options.ID, "tarballName": options.TarballName}, nil
+	})
+	return h
+}
+
+func (h *serveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	token, found := strings.CutPrefix(auth, "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// handleOp registers the endpoint of an operation. The request body has the
+// options of the operation, and the response has its structured result.
+func (h *serveHandler) handleOp(command string, run func(vcc vclusterops.VClusterCommands, body []byte) (any, error)) {
+	h.mux.HandleFunc(serveAPIPrefix+command, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxServeRequestBytes))
+		var result any
+		var workload *vclusterops.OpEngineWorkload
+		if err != nil {
+			err = &usageError{err: fmt.Errorf("fail to read the request body: %w", err)}
+		} else {
+			// the spans of the operation continue the trace of the caller, if any
+			ctx := tracing.ContextWithTraceparent(r.Context(), r.Header.Get(tracing.TraceparentHeader))
+			// the requests to the hosts have the correlation ID of the caller, if any
+			correlationID := r.Header.Get(vclusterops.CorrelationIDHeader)
+			if correlationID == "" || len(correlationID) > maxCorrelationIDLength {
+				correlationID = vclusterops.NewCorrelationID()
+			}
+			w.Header().Set(vclusterops.CorrelationIDHeader, correlationID)
+			ctx = vclusterops.ContextWithCorrelationID(ctx, correlationID)
+			summary := vclusterops.NewWorkloadSummary()
+			ctx = vclusterops.ContextWithWorkloadSummary(ctx, summary)
+			result, err = h.runOp(ctx, command, func(vcc vclusterops.VClusterCommands) (any, error) { return run(vcc, body) })
+			total := summary.Workload()
+			workload = &total
+		}
+
+		var buf bytes.Buffer
+		res := cmdResult{Command: command, Success: err == nil, Result: result, Workload: workload}
+		if err != nil {
+			res.Error = err.Error()
+			res.HostErrors = getHostErrors(err)
+		}
+		if e := json.NewEncoder(&buf).Encode(&res); e != nil {
+			http.Error(w, e.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(getServeStatusCode(err))
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+// runOp runs one operation at a time, and cancels it when the client goes away
+func (h *serveHandler) runOp(ctx context.Context, command string,
+	run func(vcc vclusterops.VClusterCommands) (any, error)) (result any, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ctx, span := tracing.StartSpan(ctx, command)
+	defer func() { span.End(err) }()
+	vcc := h.vcc
+	vcc.Ctx = ctx
+
+	h.vcc.LogInfo("Running the operation of a request", "command", command,
+		"correlationID", vclusterops.GetCorrelationID(ctx))
+	result, err = run(vcc)
+	if err != nil {
+		h.vcc.LogError(err, "fail to run the operation of a request", "command", command)
+	}
+	return result, err
+}
+
+func decodeServeOptions(body []byte, options any) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// reject the fields that are not options, so that a typo is not ignored
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(options); err != nil {
+		return &usageError{err: fmt.Errorf("fail to decode the options in the request body: %w", err)}
+	}
+	return nil
+}
+
+// getServeStatusCode returns the HTTP status of a response, by the class of the error
+func getServeStatusCode(err error) int {
+	switch getExitCode(err) {
+	case exitCodeSuccess:
+		return http.StatusOK
+	case exitCodeUsage:
+		return http.StatusBadRequest
+	case exitCodeAuthFailure:
+		return http.StatusForbidden
+	case exitCodeQuorumFailure, exitCodePreconditionFailure:
+		return http.StatusConflict
+	case exitCodePartialSuccess:
+		return http.StatusMultiStatus
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,315 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+const shellPrompt = "vcluster> "
+
+// the flags of the shell that are kept for the whole session
+var shellSessionFlags = []string{configFlag, dbNameFlag, hostsFlag, keyFileFlag, certFileFlag}
+
+/* CmdShell
+ *
+ * Implements an interactive shell that runs vcluster subcommands
+ */
+type CmdShell struct {
+	// the values of the session flags, added to each subcommand that has them
+	// and is not given them on its command line
+	session map[string]string
+
+	passwordFile           string
+	readPasswordFromPrompt bool
+
+	in  io.Reader
+	out io.Writer
+}
+
+func makeCmdShell() *cobra.Command {
+	newCmd := &CmdShell{session: make(map[string]string), in: os.Stdin, out: os.Stdout}
+
+	cmd := makeSimpleCobraCmd(
+		shellSubCmd,
+		"Run vcluster subcommands in an interactive shell",
+		`This subcommand starts an interactive shell that runs vcluster subcommands,
+one per line, without the "vcluster" prefix.
+
+The --config, --db-name, --hosts, --key-file and --cert-file options given to
+the shell are kept for the whole session, and added to each subcommand that
+accepts them, unless the subcommand line gives them. The password is read once
+when the shell starts, and the connections to the hosts are reused by the
+subcommands, so the TLS handshakes are not repeated.
+
+Besides the vcluster subcommands, the shell accepts:
+  set <option> <value>   keep an option for the session, e.g. set db-name test_db
+  unset <option>         remove an option from the session
+  session                show the options of the session
+  help                   show the subcommands
+  exit, quit             leave the shell, as does Ctrl-D
+
+Examples:
+  # Start a shell for the database in a config file, reading the password once
+  vcluster shell --config /opt/vertica/config/vertica_cluster.yaml \
+    --read-password-from-prompt
+
+  # In the shell
+  vcluster> status
+  vcluster> stop_subcluster --subcluster sc1
+  vcluster> start_node --start v_test_db_node0004,v_test_db_node0005
+`)
+
+	cmd.Flags().StringP(configFlag, "c", "", "Path to the config file of the session")
+	cmd.Flags().StringP(dbNameFlag, "d", "", "The name of the database of the session")
+	cmd.Flags().String(hostsFlag, "", "Comma-separated list of hosts of the session")
+	cmd.Flags().String(keyFileFlag, "", "Path to the key file of the session")
+	cmd.Flags().String(certFileFlag, "", "Path to the cert file of the session")
+	cmd.Flags().StringVar(
+		&newCmd.passwordFile,
+		passwordFileFlag,
+		"",
+		"Path to the file to read the password of the session from. "+
+			"If - is passed, the password is read from stdin",
+	)
+	cmd.Flags().BoolVar(
+		&newCmd.readPasswordFromPrompt,
+		readPasswordFromPromptFlag,
+		false,
+		"Prompt the user to enter the password of the session",
+	)
+	cmd.MarkFlagsMutuallyExclusive(passwordFileFlag, readPasswordFromPromptFlag)
+
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		for _, flag := range shellSessionFlags {
+			if cmd.Flags().Changed(flag) {
+				newCmd.session[flag] = cmd.Flags().Lookup(flag).Value.String()
+			}
+		}
+		return newCmd.run()
+	}
+
+	return cmd
+}
+
+func (c *CmdShell) run() error {
+	err := c.setSessionPassword()
+	if err != nil {
+		return err
+	}
+	vclusterops.SetReuseConnections(true)
+	defer vclusterops.SetReuseConnections(false)
+
+	scanner := bufio.NewScanner(c.in)
+	for {
+		fmt.Fprint(c.out, shellPrompt)
+		if !scanner.Scan() {
+			fmt.Fprintln(c.out)
+			return scanner.Err()
+		}
+		args, err := splitShellLine(scanner.Text())
+		if err != nil {
+			fmt.Fprintf(c.out, "Error: %s\n", err)
+			continue
+		}
+		if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+			continue
+		}
+		if args[0] == "exit" || args[0] == "quit" {
+			return nil
+		}
+		if c.runBuiltin(args) {
+			continue
+		}
+		err = runShellSubcommand(c.withSessionFlags(args))
+		if err != nil {
+			fmt.Fprintf(c.out, "Error during execution: %s (exit code %d)\n", err, getExitCode(err))
+		}
+	}
+}
+
+// setSessionPassword reads the password once, and gives it to the subcommands
+// through the environment, so that they do not ask for it again
+func (c *CmdShell) setSessionPassword() error {
+	var password string
+	var err error
+	switch {
+	case c.readPasswordFromPrompt:
+		password, err = readDBPasswordFromPrompt(false)
+	case c.passwordFile != "":
+		password, err = (&CmdBase{}).passwordFileHelper(c.passwordFile)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return os.Setenv(vclusterPasswordEnv, password)
+}
+
+// runBuiltin runs the commands of the shell itself, and returns false if the
+// line is a vcluster subcommand
+func (c *CmdShell) runBuiltin(args []string) bool {
+	switch args[0] {
+	case "help":
+		fmt.Fprintln(c.out, "Subcommands:")
+		for _, subCmd := range constructCmds() {
+			if subCmd.Name() != shellSubCmd && subCmd.Name() != serveSubCmd {
+				fmt.Fprintf(c.out, "  %-22s %s\n", subCmd.Name(), subCmd.Short)
+			}
+		}
+		fmt.Fprintln(c.out, `Run "<subcommand> --help" for the options of a subcommand.`)
+	case "set":
+		if len(args) != 3 {
+			fmt.Fprintln(c.out, "Usage: set <option> <value>")
+			return true
+		}
+		c.session[strings.TrimPrefix(args[1], "--")] = args[2]
+	case "unset":
+		if len(args) != 2 {
+			fmt.Fprintln(c.out, "Usage: unset <option>")
+			return true
+		}
+		delete(c.session, strings.TrimPrefix(args[1], "--"))
+	case "session":
+		flags := make([]string, 0, len(c.session))
+		for flag := range c.session {
+			flags = append(flags, flag)
+		}
+		sort.Strings(flags)
+		for _, flag := range flags {
+			fmt.Fprintf(c.out, "  --%s %s\n", flag, c.session[flag])
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// withSessionFlags adds the session options to the line of a subcommand, for
+// the options that the subcommand accepts and the line does not give
+func (c *CmdShell) withSessionFlags(args []string) []string {
+	subCmd, _, err := makeShellRootCmd().Find(args)
+	if err != nil {
+		return args
+	}
+	flags := make([]string, 0, len(c.session))
+	for flag := range c.session {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+
+	result := append([]string{}, args...)
+	for _, flag := range flags {
+		pflag := subCmd.Flags().Lookup(flag)
+		if pflag == nil || isFlagInArgs(pflag.Name, pflag.Shorthand, args) {
+			continue
+		}
+		result = append(result, "--"+flag, c.session[flag])
+	}
+	return result
+}
+
+func isFlagInArgs(name, shorthand string, args []string) bool {
+	for _, arg := range args {
+		if arg == "--"+name || strings.HasPrefix(arg, "--"+name+"=") {
+			return true
+		}
+		if shorthand != "" && (arg == "-"+shorthand || strings.HasPrefix(arg, "-"+shorthand+"=")) {
+			return true
+		}
+	}
+	return false
+}
+
+// makeShellRootCmd makes a new root command for each line of the shell. The
+// subcommands are made again, so that the options of a previous line are not kept.
+func makeShellRootCmd() *cobra.Command {
+	// the errors are printed by the shell
+	root := &cobra.Command{Use: rootCmd.Use, SilenceErrors: true}
+	root.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
+	for _, subCmd := range constructCmds() {
+		if subCmd.Name() != shellSubCmd && subCmd.Name() != serveSubCmd {
+			root.AddCommand(subCmd)
+		}
+	}
+	return root
+}
+
+// runShellSubcommand runs a subcommand as if it is run by vcluster
+func runShellSubcommand(args []string) error {
+	// the options are bound to the global options, so they are reset for each subcommand
+	dbOptions = vclusterops.DatabaseOptionsFactory()
+	dbOptions.Password = new(string)
+	globals = cmdGlobals{outputFormat: outputFormatText, logFormat: vlog.LogFormatText}
+
+	root := makeShellRootCmd()
+	savedArgs := os.Args
+	// the subcommands parse the options from os.Args
+	os.Args = append([]string{savedArgs[0]}, args...)
+	defer func() { os.Args = savedArgs }()
+	root.SetArgs(args)
+	return root.Execute()
+}
+
+// splitShellLine splits a line into arguments. The arguments can be quoted
+// in single or double quotes.
+func splitShellLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote %c", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -165,6 +165,7 @@ func (c *CmdShowRestorePoints) Run(vcc vclusterops.ClusterCommands) error {
 	}
 
 	vcc.PrintInfo("Successfully show restore points %v in database %s", restorePoints, options.DBName)
+	c.setResult(restorePoints)
 	return nil
 }
 
@@ -16,6 +16,8 @@
 package commands
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 	"github.com/vertica/vcluster/vclusterops"
 	"github.com/vertica/vcluster/vclusterops/util"
@@ -32,8 +34,6 @@ type CmdStartDB struct {
 
 	Force               bool // force cleanup to start the database
 	AllowFallbackKeygen bool // Generate spread encryption key from Vertica. Use under support guidance only
-	IgnoreClusterLease  bool // ignore the cluster lease in communal storage
-	Unsafe              bool // Start database unsafely, skipping recovery.
 	Fast                bool // Attempt fast startup database
 }
 
@@ -61,10 +61,34 @@ If you pass the --hosts command a subset of all nodes in the cluster, only the
 specified nodes are started. There must be a quorum of nodes for the database
 to start.
 
+For disaster recovery, when most primary nodes are lost, --force-without-quorum
+starts the database on the surviving nodes anyway. You are prompted to type the
+database name to confirm. The database starts from the catalog of the surviving
+nodes, so changes committed after they went down may be lost.
+
+If you pass --subclusters, only the nodes of those subclusters are started.
+The subclusters must include a quorum of the primary nodes.
+
 Examples:
   # Start a database with config file using password authentication
   vcluster start_db --password testpassword \
     --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Start only the primary subcluster and one secondary subcluster
+  vcluster start_db --password testpassword --subclusters sc_primary,sc_analytics \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Start a database on the surviving nodes after most primary nodes are lost
+  vcluster start_db --password testpassword --hosts 10.20.30.40 \
+    --force-without-quorum --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Start a database and wait until its nodes accept client connections
+  vcluster start_db --password testpassword --wait-for connections \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Start a copy of a database in read-only mode for a disaster recovery drill
+  vcluster start_db --password testpassword --read-only --ignore-cluster-lease \
+    --config /opt/vertica/config/vertica_cluster.yaml
 `,
 		[]string{dbNameFlag, hostsFlag, communalStorageLocationFlag,
 			configFlag, catalogPathFlag, passwordFlag, eonModeFlag, configParamFlag},
@@ -88,17 +112,66 @@ func (c *CmdStartDB) setLocalFlags(cmd *cobra.Command) {
 		util.DefaultTimeoutSeconds,
 		"The timeout (in seconds) to wait for polling node state operation",
 	)
+	cmd.Flags().BoolVar(
+		&c.startDBOptions.ForceWithoutQuorum,
+		"force-without-quorum",
+		false,
+		"Start the database even if the hosts do not include a quorum of the primary nodes.\n"+
+			"This implies --unsafe. Use for disaster recovery only, changes committed after the hosts went down may be lost",
+	)
+	cmd.Flags().StringVar(
+		&c.startDBOptions.Sandbox,
+		sandboxFlag,
+		"",
+		"Name of the sandbox to start. Without --hosts, the hosts of the sandbox are read from the config file",
+	)
+	cmd.Flags().IntVar(
+		&c.startDBOptions.StartWaveSize,
+		"start-wave-size",
+		0,
+		"The number of nodes that are started together. The waves of nodes are started one after another, "+
+			"which avoids spread reconfigurations and floods of the communal storage in large clusters. "+
+			"0 starts all the nodes together",
+	)
+	cmd.Flags().IntVar(
+		&c.startDBOptions.StartWaveDelaySeconds,
+		"start-wave-delay",
+		util.DefaultStartWaveDelaySeconds,
+		"The seconds to wait between the waves of nodes",
+	)
+	cmd.Flags().StringSliceVar(
+		&c.startDBOptions.Subclusters,
+		"subclusters",
+		[]string{},
+		"Comma-separated list of the subclusters to start. Only the nodes of these subclusters are started, "+
+			"and they must include a quorum of the primary nodes",
+	)
+	cmd.Flags().BoolVar(
+		&c.startDBOptions.ReadOnly,
+		"read-only",
+		false,
+		"Start the database in read-only mode, in which no changes are committed",
+	)
+	cmd.Flags().BoolVar(
+		&c.startDBOptions.Unsafe,
+		"unsafe",
+		false,
+		"Start the database without recovery, from the catalog and data on disk.\n"+
+			"Use for disaster recovery only, committed changes may be lost",
+	)
+	cmd.Flags().BoolVar(
+		&c.startDBOptions.IgnoreClusterLease,
+		"ignore-cluster-lease",
+		false,
+		util.GetEonFlagMsg("Start the database even if another cluster holds the lease of the communal storage,"+
+			" for example in a disaster recovery drill. Only one of the clusters can write to the communal storage"),
+	)
+	setWaitPolicyFlag(cmd, &c.startDBOptions.WaitPolicy)
 }
 
 // setHiddenFlags will set the hidden flags the command has.
 // These hidden flags will not be shown in help and usage of the command, and they will be used internally.
 func (c *CmdStartDB) setHiddenFlags(cmd *cobra.Command) {
-	cmd.Flags().BoolVar(
-		&c.Unsafe,
-		"unsafe",
-		false,
-		"",
-	)
 	cmd.Flags().BoolVar(
 		&c.Force,
 		"force",
@@ -112,7 +185,7 @@ func (c *CmdStartDB) setHiddenFlags(cmd *cobra.Command) {
 		"",
 	)
 	cmd.Flags().BoolVar(
-		&c.IgnoreClusterLease,
+		&c.startDBOptions.IgnoreClusterLease,
 		"ignore_cluster_lease",
 		false,
 		"",
@@ -129,7 +202,7 @@ func (c *CmdStartDB) setHiddenFlags(cmd *cobra.Command) {
 		false,
 		"",
 	)
-	hideLocalFlags(cmd, []string{"unsafe", "force", "allow_fallback_keygen", "ignore_cluster_lease", "fast", "trim-hosts"})
+	hideLocalFlags(cmd, []string{"force", "allow_fallback_keygen", "ignore_cluster_lease", "fast", "trim-hosts"})
 }
 
 func (c *CmdStartDB) Parse(inputArgv []string, logger vlog.Printer) error {
@@ -148,13 +221,44 @@ func (c *CmdStartDB) validateParse(logger vlog.Printer) error {
 		return err
 	}
 
+	err = c.setSandboxHosts(&c.startDBOptions.DatabaseOptions, c.startDBOptions.Sandbox, false /*main cluster*/)
+	if err != nil {
+		return err
+	}
+
 	err = c.ValidateParseBaseOptions(&c.startDBOptions.DatabaseOptions)
 	if err != nil {
 		return err
 	}
+
+	if c.startDBOptions.Unsafe || c.startDBOptions.ForceWithoutQuorum {
+		logger.PrintWarning("The database is started without recovery, changes that were committed " +
+			"after the last checkpoint on disk may be lost.")
+	}
+
+	if c.startDBOptions.ForceWithoutQuorum {
+		err = c.confirmForceWithoutQuorum(logger)
+		if err != nil {
+			return err
+		}
+	}
 	return c.setDBPassword(&c.startDBOptions.DatabaseOptions)
 }
 
+// confirmForceWithoutQuorum asks the user to type the database name to confirm
+// starting the database without quorum
+func (c *CmdStartDB) confirmForceWithoutQuorum(logger vlog.Printer) error {
+	logger.PrintWarning("Starting a database without quorum can lose changes that were committed " +
+		"after the given hosts went down. Only do this if most primary nodes are lost for good.")
+	confirmation, err := readConfirmationFromPrompt(
+		fmt.Sprintf("Type the database name (%s) to confirm: ", c.startDBOptions.DBName))
+	if err != nil {
+		return err
+	}
+	c.startDBOptions.ForceWithoutQuorumConfirmation = confirmation
+	return nil
+}
+
 func (c *CmdStartDB) Run(vcc vclusterops.ClusterCommands) error {
 	vcc.V(1).Info("Called method Run()")
 
@@ -167,6 +271,7 @@ func (c *CmdStartDB) Run(vcc vclusterops.ClusterCommands) error {
 	}
 
 	vcc.PrintInfo("Successfully start the database %s", options.DBName)
+	c.setResult(makeDBResult(vdb))
 
 	// for Eon database, update config file to fill nodes' subcluster information
 	if options.IsEon {
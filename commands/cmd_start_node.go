@@ -0,0 +1,166 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+const (
+	startNodesFlag     = "start"
+	startNodeHostsFlag = "start-hosts"
+)
+
+/* CmdStartNodes
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdStartNodes struct {
+	CmdBase
+	startNodesOptions *vclusterops.VStartNodesOptions
+
+	// names of the nodes to start
+	nodeNames []string
+	// hosts of the nodes to start
+	rawStartHosts []string
+}
+
+func makeCmdStartNodes() *cobra.Command {
+	// CmdStartNodes
+	newCmd := &CmdStartNodes{}
+	opt := vclusterops.VStartNodesOptionsFactory()
+	newCmd.startNodesOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		startNodeSubCmd,
+		"Start down nodes in the database",
+		`This subcommand starts a subset of down nodes in a running cluster.
+Unlike restart_node, the nodes keep the addresses stored in the catalog.
+
+You can pass --start a comma-separated list of node names, or --start-hosts
+a comma-separated list of hosts whose nodes need to be started. The cluster
+must not have lost quorum, otherwise use start_db. Nodes that are already
+up are skipped.
+
+Examples:
+  # Start a single node in the database with config file
+  vcluster start_node --db-name test_db \
+    --start v_test_db_node0004 --password testpassword \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Start multiple nodes by host in the database with config file
+  vcluster start_node --db-name test_db \
+    --start-hosts 10.20.30.42,10.20.30.43 --password testpassword \
+    --config /opt/vertica/config/vertica_cluster.yaml
+`,
+		[]string{dbNameFlag, hostsFlag, configFlag, passwordFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	// require nodes or hosts to start
+	cmd.MarkFlagsOneRequired(startNodesFlag, startNodeHostsFlag)
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdStartNodes) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(
+		&c.nodeNames,
+		startNodesFlag,
+		[]string{},
+		"Comma-separated list of names of the nodes that need to be started",
+	)
+	cmd.Flags().StringSliceVar(
+		&c.rawStartHosts,
+		startNodeHostsFlag,
+		[]string{},
+		"Comma-separated list of hosts of the nodes that need to be started",
+	)
+	cmd.Flags().IntVar(
+		&c.startNodesOptions.StatePollingTimeout,
+		"timeout",
+		util.DefaultTimeoutSeconds,
+		"The timeout (in seconds) to wait for polling node state operation",
+	)
+	setWaitPolicyFlag(cmd, &c.startNodesOptions.WaitPolicy)
+}
+
+func (c *CmdStartNodes) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.startNodesOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdStartNodes) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()")
+	c.startNodesOptions.ParseNodeNamesList(c.nodeNames)
+	if len(c.rawStartHosts) > 0 {
+		err := c.startNodesOptions.ParseHostToStartList(c.rawStartHosts)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.getCertFilesFromCertPaths(&c.startNodesOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.startNodesOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.startNodesOptions.DatabaseOptions)
+}
+
+func (c *CmdStartNodes) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	options := c.startNodesOptions
+
+	// this is the instruction that will be used by both CLI and operator
+	err := vcc.VStartNodes(options)
+	if err != nil {
+		return err
+	}
+
+	var nodesToStart []string
+	for nodeName := range options.Nodes {
+		nodesToStart = append(nodesToStart, nodeName)
+	}
+	vcc.PrintInfo("Successfully started nodes %s of the database %s", nodesToStart, options.DBName)
+	c.setResult(map[string]any{"dbName": options.DBName, "nodes": options.Nodes})
+
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdStartNodes
+func (c *CmdStartNodes) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.startNodesOptions.DatabaseOptions = *opt
+}
@@ -78,6 +78,10 @@ Examples:
   vcluster replication start --db-name test_db --db-user dbadmin --hosts 10.20.30.40 --target-db-name platform_db \
     --target-hosts 10.20.30.43 --password-file /path/to/password-file --target-db-user dbadmin \ 
     --target-password-file /path/to/password-file
+
+  # Start database replication and wait up to 10 minutes for it to complete
+  vcluster replication start --config /opt/vertica/config/vertica_cluster.yaml \
+    --target-conn /opt/vertica/config/target_connection.yaml --wait --timeout 600
 `,
 		// Temporarily, the Vcluster CLI doesn't support a config file for this subcommand.
 		// It will include all hosts from the config file.
@@ -123,6 +127,19 @@ func (c *CmdStartReplication) setLocalFlags(cmd *cobra.Command) {
 		"The TLS configuration to use when connecting to the target database "+
 			", must exist in the source database",
 	)
+	cmd.Flags().BoolVar(
+		&c.startRepOptions.PollCompletion,
+		"wait",
+		false,
+		"Wait for the replication to complete",
+	)
+	cmd.Flags().IntVar(
+		&c.startRepOptions.PollingTimeout,
+		"timeout",
+		util.DefaultTimeoutSeconds,
+		"The timeout (in seconds) to wait for the replication to complete when --wait is set."+
+			" A negative value means no timeout, zero is not allowed",
+	)
 	cmd.Flags().StringVar(
 		&c.targetConnPath,
 		targetConnFlag,
@@ -223,6 +240,7 @@ func (c *CmdStartReplication) Run(vcc vclusterops.ClusterCommands) error {
 		return err
 	}
 	vcc.PrintInfo("Successfully replicate to database %s", options.TargetDB)
+	c.setResult(map[string]string{"dbName": options.DBName, "targetDBName": options.TargetDB})
 	return nil
 }
 
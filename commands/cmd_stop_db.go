@@ -18,6 +18,7 @@ package commands
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/vertica/vcluster/vclusterops"
@@ -50,10 +51,26 @@ func makeCmdStopDB() *cobra.Command {
 		"Stop a database",
 		`This subcommand stops a database or sandbox.
 
+You are asked to type the database name to confirm the stop. Use --yes to
+stop the database without confirmation, for example in a script.
+
 Examples:
   # Stop a database with config file using password authentication
   vcluster stop_db --password testpassword \
     --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Stop a database after moving the data in memory out to disk, so that
+  # it starts faster
+  vcluster stop_db --moveout --password testpassword \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Stop the main cluster and all of its sandboxes
+  vcluster stop_db --include-sandboxes --password testpassword \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Stop a database in a script, without confirmation
+  vcluster stop_db --yes --password-file /home/dbadmin/password \
+    --config /opt/vertica/config/vertica_cluster.yaml
 `,
 		[]string{dbNameFlag, hostsFlag, ipv6Flag, eonModeFlag, configFlag, passwordFlag},
 	)
@@ -78,6 +95,13 @@ func (c *CmdStopDB) setLocalFlags(cmd *cobra.Command) {
 			" Default value is "+strconv.Itoa(util.DefaultDrainSeconds)+" seconds."+
 			" When the time expires, connections will be forcibly closed and the db will shut down"),
 	)
+	cmd.Flags().IntVar(
+		&c.stopDBOptions.SessionPollingSeconds,
+		sessionPollingIntervalFlag,
+		util.DefaultSessionPollingSeconds,
+		util.GetEonFlagMsg("seconds between the reports of the user sessions that are still connected while they drain."+
+			" If the value is 0, the sessions are not reported"),
+	)
 	cmd.Flags().StringVar(
 		&c.stopDBOptions.Sandbox,
 		sandboxFlag,
@@ -90,6 +114,21 @@ func (c *CmdStopDB) setLocalFlags(cmd *cobra.Command) {
 		false,
 		"Stop the database, but don't stop any of the sandboxes",
 	)
+	cmd.Flags().BoolVar(
+		&c.stopDBOptions.IncludeSandboxes,
+		"include-sandboxes",
+		false,
+		"Stop the main cluster and all of its sandboxes. This is also done without --sandbox and --main-cluster-only",
+	)
+	cmd.MarkFlagsMutuallyExclusive(sandboxFlag, "main-cluster-only", "include-sandboxes")
+	cmd.Flags().BoolVar(
+		&c.stopDBOptions.Moveout,
+		"moveout",
+		false,
+		"Move the data in memory out to disk before stopping the database, which reduces the recovery time"+
+			" of the next start",
+	)
+	c.setConfirmFlags(cmd)
 }
 
 // setHiddenFlags will set the hidden flags the command has.
@@ -133,13 +172,33 @@ func (c *CmdStopDB) validateParse(logger vlog.Printer) error {
 		return err
 	}
 
+	err = c.setSandboxHosts(&c.stopDBOptions.DatabaseOptions, c.stopDBOptions.Sandbox, c.stopDBOptions.MainCluster)
+	if err != nil {
+		return err
+	}
+
 	err = c.ValidateParseBaseOptions(&c.stopDBOptions.DatabaseOptions)
 	if err != nil {
 		return err
 	}
+	err = c.confirmDestructive(c.getStopAction(), c.stopDBOptions.DBName, logger)
+	if err != nil {
+		return err
+	}
 	return c.setDBPassword(&c.stopDBOptions.DatabaseOptions)
 }
 
+// getStopAction describes what the command stops, for the confirmation prompt
+func (c *CmdStopDB) getStopAction() string {
+	switch {
+	case c.stopDBOptions.Sandbox != "":
+		return fmt.Sprintf("stop the sandbox %s of the database %s", c.stopDBOptions.Sandbox, c.stopDBOptions.DBName)
+	case c.stopDBOptions.MainCluster:
+		return fmt.Sprintf("stop the main cluster of the database %s", c.stopDBOptions.DBName)
+	}
+	return fmt.Sprintf("stop the database %s and disconnect its users", c.stopDBOptions.DBName)
+}
+
 func (c *CmdStopDB) Run(vcc vclusterops.ClusterCommands) error {
 	vcc.LogInfo("Called method Run()")
 
@@ -150,6 +209,9 @@ func (c *CmdStopDB) Run(vcc vclusterops.ClusterCommands) error {
 		vcc.LogError(err, "failed to stop the database")
 		return err
 	}
+	c.setResult(map[string]any{"dbName": options.DBName, "sandbox": options.Sandbox, "mainClusterOnly": options.MainCluster,
+		"stoppedSandboxes": options.StoppedSandboxes, "forceClosedSessions": options.ForceClosedSessions})
+	printForceClosedSessions(vcc, options.ForceClosedSessions)
 	msg := fmt.Sprintf("Stopped a database with name %s", options.DBName)
 	if options.Sandbox != "" {
 		sandboxMsg := fmt.Sprintf(" on sandbox %s", options.Sandbox)
@@ -161,6 +223,9 @@ func (c *CmdStopDB) Run(vcc vclusterops.ClusterCommands) error {
 		vcc.PrintInfo(msg + stopMsg)
 		return nil
 	}
+	if len(options.StoppedSandboxes) > 0 {
+		msg += fmt.Sprintf(" on main cluster and sandboxes %s", strings.Join(options.StoppedSandboxes, ", "))
+	}
 	vcc.PrintInfo(msg)
 	return nil
 }
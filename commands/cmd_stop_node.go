@@ -0,0 +1,138 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdStopNode
+ *
+ * Parses arguments to StopNode and calls
+ * the high-level function for StopNode.
+ *
+ * Implements ClusterCommand interface
+ */
+
+type CmdStopNode struct {
+	CmdBase
+	stopNodeOptions *vclusterops.VStopNodeOptions
+}
+
+func makeCmdStopNode() *cobra.Command {
+	newCmd := &CmdStopNode{}
+	opt := vclusterops.VStopNodeOptionsFactory()
+	newCmd.stopNodeOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		stopNodeSubCmd,
+		"Stop a node in the database",
+		`This subcommand gracefully stops a single node in a running database.
+
+You must provide the node name with the --node option.
+
+User sessions on the node are given the drain period to disconnect before
+the node shuts down. A primary node is not stopped if the remaining primary
+nodes would lose quorum.
+
+Examples:
+  # Gracefully stop a node with config file
+  vcluster stop_node --node v_test_db_node0004 --drain-seconds 10 \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Gracefully stop a node with user input
+  vcluster stop_node --db-name test_db --node v_test_db_node0004 \
+    --hosts 10.20.30.40,10.20.30.41,10.20.30.42 --password testpassword
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, configFlag, passwordFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	// require name of the node to stop
+	markFlagsRequired(cmd, []string{stopNodeFlag})
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdStopNode) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&c.stopNodeOptions.NodeName,
+		stopNodeFlag,
+		"",
+		"The name of the node to stop",
+	)
+	cmd.Flags().IntVar(
+		&c.stopNodeOptions.DrainSeconds,
+		"drain-seconds",
+		util.DefaultDrainSeconds,
+		"Seconds to wait for user connections on the node to close."+
+			" Default value is "+strconv.Itoa(util.DefaultDrainSeconds)+" seconds."+
+			" When the time expires, connections will be forcibly closed and the node will shut down.",
+	)
+}
+
+func (c *CmdStopNode) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// reset some options that are not included in user input
+	c.ResetUserInputOptions(&c.stopNodeOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdStopNode) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()")
+	err := c.getCertFilesFromCertPaths(&c.stopNodeOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.stopNodeOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.stopNodeOptions.DatabaseOptions)
+}
+
+func (c *CmdStopNode) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.LogInfo("Called method Run()")
+
+	options := c.stopNodeOptions
+
+	err := vcc.VStopNode(options)
+	if err != nil {
+		vcc.LogError(err, "failed to stop the node", "Node", options.NodeName)
+		return err
+	}
+	vcc.PrintInfo("Successfully stopped node %s of the database %s", options.NodeName, options.DBName)
+	c.setResult(map[string]string{"dbName": options.DBName, "node": options.NodeName})
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdStopNode
+func (c *CmdStopNode) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.stopNodeOptions.DatabaseOptions = *opt
+}
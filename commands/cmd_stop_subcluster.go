@@ -54,6 +54,10 @@ You must provide the subcluster name with the --subcluster option.
 All hosts in the subcluster will be stopped. You cannot stop a sandboxed
 subcluster.
 
+With --redirect-connections, new client connections are redirected away from
+the subcluster before it is stopped, to the subcluster given by --redirect-to
+or to any other subcluster, while the existing sessions drain.
+
 Examples:
   # Gracefully stop a subcluster with config file
   vcluster stop_subcluster --subcluster sc1 --drain-seconds 10 \
@@ -67,6 +71,11 @@ Examples:
   vcluster stop_subcluster --db-name test_db --subcluster sc1 \
     --hosts 10.20.30.40,10.20.30.41,10.20.30.42 --drain-seconds 10
   
+  # Redirect new connections to subcluster sc2 and gracefully stop a subcluster
+  vcluster stop_subcluster --subcluster sc1 --drain-seconds 60 \
+    --redirect-connections --redirect-to sc2 \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
   # Forcibly stop a subcluster with user input
   vcluster stop_subcluster --db-name test_db --subcluster sc1 \
     --hosts 10.20.30.40,10.20.30.41,10.20.30.42 --force
@@ -98,6 +107,13 @@ func (c *CmdStopSubcluster) setLocalFlags(cmd *cobra.Command) {
 			" If the value is 0, VCluster closes all user connections immediately."+
 			" If the value is negative, VCluster waits indefinitely until all user sessions disconnect"),
 	)
+	cmd.Flags().IntVar(
+		&c.stopSCOptions.SessionPollingSeconds,
+		sessionPollingIntervalFlag,
+		util.DefaultSessionPollingSeconds,
+		util.GetEonFlagMsg("seconds between the reports of the user sessions that are still connected while they drain."+
+			" If the value is 0, the sessions are not reported"),
+	)
 	cmd.Flags().StringVar(
 		&c.stopSCOptions.SCName,
 		subclusterFlag,
@@ -110,6 +126,18 @@ func (c *CmdStopSubcluster) setLocalFlags(cmd *cobra.Command) {
 		false,
 		"Force the subcluster to shutdown immediately even if users are connected",
 	)
+	cmd.Flags().BoolVar(
+		&c.stopSCOptions.RedirectConnections,
+		"redirect-connections",
+		false,
+		"Redirect new client connections away from the subcluster before it is stopped",
+	)
+	cmd.Flags().StringVar(
+		&c.stopSCOptions.RedirectTargetSC,
+		"redirect-to",
+		"",
+		"The subcluster to redirect new client connections to. Defaults to any other subcluster",
+	)
 	cmd.MarkFlagsMutuallyExclusive("drain-seconds", "force")
 }
 
@@ -154,6 +182,9 @@ func (c *CmdStopSubcluster) Run(vcc vclusterops.ClusterCommands) error {
 		return err
 	}
 	vcc.PrintInfo("Successfully stopped subcluster %s", options.SCName)
+	c.setResult(map[string]any{"dbName": options.DBName, "subcluster": options.SCName,
+		"forceClosedSessions": options.ForceClosedSessions})
+	printForceClosedSessions(vcc, options.ForceClosedSessions)
 	return nil
 }
 
@@ -0,0 +1,140 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdTailLog
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdTailLog struct {
+	tailLogOptions *vclusterops.VTailLogOptions
+
+	CmdBase
+}
+
+func makeCmdTailLog() *cobra.Command {
+	newCmd := &CmdTailLog{}
+
+	opt := vclusterops.VTailLogOptionsFactory()
+	newCmd.tailLogOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		tailLogSubCmd,
+		"Show the last lines of the log of nodes",
+		`This subcommand shows the last lines of vertica.log or startup.log in the
+catalog directory of the nodes, through the node management agent (NMA) of
+each host. The database does not need to be up, so it can be used to debug
+nodes that fail to start.
+
+Each line is prefixed with the name of its node. With --follow, the lines
+written to the log are shown as they are written, until the command is
+interrupted or its --command-timeout is reached.
+
+Examples:
+  # Show the last 100 lines of vertica.log of all the nodes with config file
+  vcluster tail_log --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Follow startup.log of a node with user input
+  vcluster tail_log --db-name test_db --hosts 10.20.30.40 --catalog-path /data \
+    --node-names v_test_db_node0001 --log-file startup.log --lines 20 --follow
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, catalogPathFlag, configFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdTailLog) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(
+		&c.tailLogOptions.NodeNames,
+		"node-names",
+		[]string{},
+		"Comma-separated list of the nodes whose log is shown. Defaults to all the nodes",
+	)
+	cmd.Flags().StringVar(
+		&c.tailLogOptions.LogFile,
+		"log-file",
+		vclusterops.LogFileVertica,
+		fmt.Sprintf("The log file to show, %s or %s", vclusterops.LogFileVertica, vclusterops.LogFileStartup),
+	)
+	cmd.Flags().IntVar(
+		&c.tailLogOptions.Lines,
+		"lines",
+		util.DefaultTailLogLines,
+		"The number of lines to show from the end of the log",
+	)
+	cmd.Flags().BoolVar(
+		&c.tailLogOptions.Follow,
+		"follow",
+		false,
+		"Keep showing the lines written to the log until the command is interrupted",
+	)
+}
+
+func (c *CmdTailLog) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// for some options, we do not want to use their default values,
+	// if they are not provided in cli,
+	// reset the value of those options to nil
+	c.ResetUserInputOptions(&c.tailLogOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdTailLog) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()", "command", tailLogSubCmd)
+	err := c.getCertFilesFromCertPaths(&c.tailLogOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	return c.ValidateParseBaseOptions(&c.tailLogOptions.DatabaseOptions)
+}
+
+func (c *CmdTailLog) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	c.tailLogOptions.OnLine = func(line vclusterops.LogLine) {
+		fmt.Printf("[%s] %s\n", line.NodeName, line.Line)
+	}
+	err := vcc.VTailLog(c.tailLogOptions)
+	if err != nil {
+		vcc.LogError(err, "fail to tail log")
+		return err
+	}
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdTailLog
+func (c *CmdTailLog) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.tailLogOptions.DatabaseOptions = *opt
+}
@@ -132,6 +132,9 @@ func (c *CmdUnsandboxSubcluster) Run(vcc vclusterops.ClusterCommands) error {
 
 	err := vcc.VUnsandbox(&options)
 	vcc.PrintInfo("Completed method Run() for command " + unsandboxSubCmd)
+	if err == nil {
+		c.setResult(map[string]string{"dbName": options.DBName, "subcluster": options.SCName})
+	}
 	return err
 }
 
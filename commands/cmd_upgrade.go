@@ -0,0 +1,188 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+const (
+	newVersionFlag        = "new-version"
+	upgradeSubclusterFlag = "subclusters"
+	resumeAfterFlag       = "resume-after"
+	pauseFlag             = "pause"
+)
+
+/* CmdUpgrade
+ *
+ * Parses arguments to VUpgradeVertica and calls
+ * the high-level function for VUpgradeVertica.
+ *
+ * Implements ClusterCommand interface
+ */
+
+type CmdUpgrade struct {
+	CmdBase
+	upgradeOptions *vclusterops.VUpgradeVerticaOptions
+}
+
+func makeCmdUpgrade() *cobra.Command {
+	newCmd := &CmdUpgrade{}
+	opt := vclusterops.VUpgradeVerticaOptionsFactory()
+	newCmd.upgradeOptions = &opt
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		upgradeSubCmd,
+		"Upgrade the database one subcluster at a time",
+		`This subcommand performs a rolling upgrade of an Eon Mode database.
+
+The new Vertica binaries must be installed on all hosts before the upgrade.
+The Vertica version on the hosts of each subcluster is checked against
+--new-version, and the subcluster is stopped and restarted. The next subcluster
+is upgraded after all nodes of the current one are up. By default, secondary
+subclusters are upgraded before primary subclusters.
+
+A primary subcluster is only upgraded if the primary nodes of the other
+subclusters keep quorum while it is stopped. Otherwise, upgrade the secondary
+subclusters with --subclusters, and then restart the database with stop_db
+and start_db.
+
+With --pause, the command returns after each subcluster. Run it again with
+--resume-after set to the last upgraded subcluster to continue the upgrade.
+A subcluster that is already stopped by a failed upgrade is not stopped
+again when the upgrade is resumed.
+
+Examples:
+  # Upgrade all subclusters with config file
+  vcluster upgrade --new-version v24.3.0 \
+    --config /opt/vertica/config/vertica_cluster.yaml
+
+  # Upgrade one subcluster at a time, resuming after subcluster sc1
+  vcluster upgrade --db-name test_db --new-version v24.3.0 \
+    --hosts 10.20.30.40,10.20.30.41,10.20.30.42 --password testpassword \
+    --pause --resume-after sc1
+`,
+		[]string{dbNameFlag, hostsFlag, ipv6Flag, eonModeFlag, configFlag, passwordFlag},
+	)
+
+	// local flags
+	newCmd.setLocalFlags(cmd)
+
+	// hide eon mode flag since we expect it to come from config file, not from user input
+	hideLocalFlags(cmd, []string{eonModeFlag})
+
+	// require the new version
+	markFlagsRequired(cmd, []string{newVersionFlag})
+
+	return cmd
+}
+
+// setLocalFlags will set the local flags the command has
+func (c *CmdUpgrade) setLocalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&c.upgradeOptions.NewVersion,
+		newVersionFlag,
+		"",
+		"The Vertica version that the new binaries report, e.g., v24.3.0. "+
+			"The parts of the version that are not given match any value, e.g., 24.3 matches v24.3.0-2",
+	)
+	cmd.Flags().StringSliceVar(
+		&c.upgradeOptions.Subclusters,
+		upgradeSubclusterFlag,
+		[]string{},
+		"Comma-separated list of subclusters to upgrade in order. Defaults to all subclusters, secondaries first",
+	)
+	cmd.Flags().StringVar(
+		&c.upgradeOptions.ResumeAfter,
+		resumeAfterFlag,
+		"",
+		"Resume a paused upgrade with the subclusters after this one",
+	)
+	cmd.Flags().BoolVar(
+		&c.upgradeOptions.PauseAfterEachSubcluster,
+		pauseFlag,
+		false,
+		"Pause the upgrade after each subcluster",
+	)
+	cmd.Flags().IntVar(
+		&c.upgradeOptions.DrainSeconds,
+		"drain-seconds",
+		util.DefaultDrainSeconds,
+		"Seconds to wait for user connections to close before a subcluster is stopped."+
+			" Default value is "+strconv.Itoa(util.DefaultDrainSeconds)+" seconds.",
+	)
+	cmd.Flags().IntVar(
+		&c.upgradeOptions.StatePollingTimeout,
+		"timeout",
+		util.DefaultStatePollingTimeout,
+		"The timeout (in seconds) to wait for the nodes of a subcluster to be up",
+	)
+}
+
+func (c *CmdUpgrade) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	// reset some options that are not included in user input
+	c.ResetUserInputOptions(&c.upgradeOptions.DatabaseOptions)
+
+	return c.validateParse(logger)
+}
+
+func (c *CmdUpgrade) validateParse(logger vlog.Printer) error {
+	logger.Info("Called validateParse()")
+	err := c.getCertFilesFromCertPaths(&c.upgradeOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	err = c.ValidateParseBaseOptions(&c.upgradeOptions.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+	return c.setDBPassword(&c.upgradeOptions.DatabaseOptions)
+}
+
+func (c *CmdUpgrade) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.LogInfo("Called method Run()")
+
+	options := c.upgradeOptions
+
+	status, err := vcc.VUpgradeVertica(options)
+	c.setResult(status)
+	if err != nil {
+		vcc.LogError(err, "failed to upgrade the database", "upgraded subclusters", status.UpgradedSubclusters)
+		return err
+	}
+	if len(status.RemainingSubclusters) > 0 {
+		vcc.PrintInfo("Upgraded subclusters %v of the database %s, subclusters %v remain to be upgraded",
+			status.UpgradedSubclusters, options.DBName, status.RemainingSubclusters)
+		return nil
+	}
+	vcc.PrintInfo("Successfully upgraded the database %s to %s", options.DBName, options.NewVersion)
+	return nil
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance to the one in CmdUpgrade
+func (c *CmdUpgrade) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.upgradeOptions.DatabaseOptions = *opt
+}
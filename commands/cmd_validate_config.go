@@ -0,0 +1,100 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+/* CmdValidateConfig
+ *
+ * A subcommand checking the config file
+ * against its schema.
+ *
+ * Implements ClusterCommand interface
+ */
+type CmdValidateConfig struct {
+	sOptions vclusterops.DatabaseOptions
+	CmdBase
+}
+
+func makeCmdValidateConfig() *cobra.Command {
+	newCmd := &CmdValidateConfig{}
+
+	cmd := makeBasicCobraCmd(
+		newCmd,
+		validateConfigSubCmd,
+		"Validate the config file",
+		`This subcommand checks the config file for problems such as unknown keys,
+duplicate nodes, bad IP addresses and missing or relative paths. All the
+databases in the config file are checked.
+
+If any problems are found, they are written in JSON to stdout, or to the file
+given by --output-file, and the command fails.
+
+Examples:
+  # Validate the config file in the default location
+  vcluster validate_config
+
+  # Validate the config file at /tmp/vertica_cluster.yaml
+  vcluster validate_config --config /tmp/vertica_cluster.yaml
+`,
+		[]string{configFlag, outputFileFlag},
+	)
+
+	return cmd
+}
+
+func (c *CmdValidateConfig) Parse(inputArgv []string, logger vlog.Printer) error {
+	c.argv = inputArgv
+	logger.LogArgParse(&c.argv)
+
+	return nil
+}
+
+func (c *CmdValidateConfig) Run(vcc vclusterops.ClusterCommands) error {
+	vcc.V(1).Info("Called method Run()")
+
+	violations, err := validateConfigFile(dbOptions.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		vcc.PrintInfo("Config file %s is valid", dbOptions.ConfigPath)
+		return nil
+	}
+
+	bytes, err := json.MarshalIndent(violations, "", "  ")
+	if err != nil {
+		return fmt.Errorf("fail to marshal the config violations, details %w", err)
+	}
+	c.setResult(violations)
+	c.writeCmdOutputToFile(globals.file, bytes, vcc.GetLog())
+	for _, v := range violations {
+		vcc.LogInfo("Config violation", "violation", v.String())
+	}
+	return fmt.Errorf("config file %s has %d violations", dbOptions.ConfigPath, len(violations))
+}
+
+// SetDatabaseOptions will assign a vclusterops.DatabaseOptions instance
+func (c *CmdValidateConfig) SetDatabaseOptions(opt *vclusterops.DatabaseOptions) {
+	c.sOptions = *opt
+}
@@ -0,0 +1,167 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"golang.org/x/term"
+)
+
+const (
+	watchFlag         = "watch"
+	watchIntervalFlag = "interval"
+	// the default seconds between two polls in watch mode
+	defaultWatchInterval = 5
+	// moves the cursor to the top left corner and clears the terminal
+	clearScreen = "\033[H\033[2J"
+)
+
+// statusTable is the state of the nodes or subclusters rendered in watch mode
+type statusTable struct {
+	header []string
+	rows   [][]string
+}
+
+// setWatchFlags sets the flags of the status commands that can poll the state continuously
+func (c *CmdBase) setWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(
+		&c.watch,
+		watchFlag,
+		"w",
+		false,
+		"Poll the state continuously and show it in a live-updating table, until interrupted",
+	)
+	cmd.Flags().IntVar(
+		&c.watchInterval,
+		watchIntervalFlag,
+		defaultWatchInterval,
+		"The seconds between two polls in watch mode",
+	)
+}
+
+func (c *CmdBase) validateWatchFlags() error {
+	if !c.watch {
+		return nil
+	}
+	if c.watchInterval <= 0 {
+		return fmt.Errorf("the value of --%s must be positive", watchIntervalFlag)
+	}
+	if isStructuredOutput() {
+		return fmt.Errorf("--%s cannot be used with --%s %s", watchFlag, outputFormatFlag, globals.outputFormat)
+	}
+	return nil
+}
+
+// runWatch polls the state every interval and renders it as a table, until the
+// command is interrupted or reaches its deadline. On a terminal the table is
+// redrawn in place, otherwise a new table is written only when the state changes,
+// so that the output can be piped to a file.
+func (c *CmdBase) runWatch(poll func() (statusTable, error)) error {
+	out := io.Writer(os.Stdout)
+	if globals.file != nil {
+		out = globals.file
+	}
+	redraw := out == os.Stdout && term.IsTerminal(int(os.Stdout.Fd()))
+	ctx := getCmdContext()
+	interval := time.Duration(c.watchInterval) * time.Second
+
+	lastRendered := ""
+	for {
+		table, err := poll()
+		var rendered string
+		if err != nil {
+			// keep polling, as the database can come back while it is watched
+			rendered = fmt.Sprintf("Error: %s\n", err)
+		} else {
+			rendered = table.render()
+		}
+		now := time.Now().Format(time.DateTime)
+		if redraw {
+			fmt.Fprintf(out, "%sEvery %ds: vcluster %s\t%s\n\n%s", clearScreen, c.watchInterval,
+				strings.Join(os.Args[1:], " "), now, rendered)
+		} else if rendered != lastRendered {
+			fmt.Fprintf(out, "%s\n%s\n", now, rendered)
+		}
+		lastRendered = rendered
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (table *statusTable) render() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(table.header, "\t"))
+	for _, row := range table.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// quietVcc returns the commands that do not print the progress of each
+// instruction, so that the output of watch mode only has the table
+func quietVcc(vcc vclusterops.ClusterCommands) vclusterops.ClusterCommands {
+	log := vcc.GetLog()
+	log.ForCli = false
+	return vclusterops.VClusterCommands{VClusterCommandsLogger: vclusterops.VClusterCommandsLogger{Log: log},
+		Ctx: getCmdContext()}
+}
+
+func makeNodeStateTable(nodeStates []vclusterops.NodeInfo) statusTable {
+	table := statusTable{header: []string{"NAME", "ADDRESS", "STATE", "SUBCLUSTER", "PRIMARY", "VERSION"}}
+	for i := range nodeStates {
+		n := &nodeStates[i]
+		table.rows = append(table.rows, []string{n.Name, n.Address, n.State, n.Subcluster,
+			strconv.FormatBool(n.IsPrimary), n.Version})
+	}
+	return table
+}
+
+func makeClusterHealthTable(health *vclusterops.ClusterHealth) statusTable {
+	table := statusTable{header: []string{"NAME", "ADDRESS", "STATE", "SUBCLUSTER", "SANDBOX", "PRIMARY",
+		"NMA", "CATALOG VERSION"}}
+	for i := range health.Nodes {
+		n := &health.Nodes[i]
+		nmaState := "DOWN"
+		if n.NMAReachable {
+			nmaState = "UP"
+		}
+		table.rows = append(table.rows, []string{n.Name, n.Address, n.State, n.Subcluster, n.Sandbox,
+			strconv.FormatBool(n.IsPrimary), nmaState, strconv.FormatInt(n.CatalogVersion, 10)})
+	}
+	quorum := "no quorum"
+	if health.HasQuorum {
+		quorum = "quorum"
+	}
+	table.rows = append(table.rows, []string{}, []string{fmt.Sprintf("%d/%d nodes up, %s",
+		health.UpNodes, health.TotalNodes, quorum)})
+	return table
+}
@@ -0,0 +1,175 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// the kinds of database objects that a flag value can be completed with
+const (
+	completeSubcluster = iota
+	completeSandbox
+	completeNode
+)
+
+// the flags whose values are completed with database objects
+var flagCompletionKinds = map[string]int{
+	subclusterFlag:        completeSubcluster,
+	upgradeSubclusterFlag: completeSubcluster,
+	sandboxFlag:           completeSandbox,
+	stopNodeFlag:          completeNode,
+	startNodesFlag:        completeNode,
+}
+
+// the time to wait for the HTTPS service when completing a flag value,
+// so that the shell does not hang on a down database
+const liveCompletionTimeout = 5 * time.Second
+
+// completionNode is a node that the flag values are completed from
+type completionNode struct {
+	name       string
+	subcluster string
+	sandbox    string
+}
+
+// registerFlagCompletions registers the completions of subcluster, sandbox and
+// node names for the flags of a command and its subcommands
+func registerFlagCompletions(cmd *cobra.Command) {
+	for flag, kind := range flagCompletionKinds {
+		if cmd.Flags().Lookup(flag) == nil {
+			continue
+		}
+		err := cmd.RegisterFlagCompletionFunc(flag, makeFlagCompletionFunc(kind))
+		if err != nil {
+			cmd.PrintErrf("Warning: fail to register the completion of flag %q, details: %v\n", flag, err)
+		}
+	}
+	for _, subCmd := range cmd.Commands() {
+		registerFlagCompletions(subCmd)
+	}
+}
+
+func makeFlagCompletionFunc(kind int) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		nodes := getCompletionNodes(cmd)
+		return completeFlagValue(getCompletionNames(nodes, kind), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// getCompletionNodes reads the nodes from the database when --hosts is given,
+// otherwise from the config file
+func getCompletionNodes(cmd *cobra.Command) []completionNode {
+	if cmd.Flags().Changed(hostsFlag) {
+		nodes, err := fetchCompletionNodes()
+		if err == nil {
+			return nodes
+		}
+	}
+	initConfig()
+	dbConfig, err := readConfig()
+	if err != nil {
+		return nil
+	}
+	var nodes []completionNode
+	for _, n := range dbConfig.Nodes {
+		nodes = append(nodes, completionNode{name: n.Name, subcluster: n.Subcluster, sandbox: n.Sandbox})
+	}
+	return nodes
+}
+
+// fetchCompletionNodes gets the nodes from the HTTPS service of the hosts given by --hosts
+func fetchCompletionNodes() ([]completionNode, error) {
+	options := vclusterops.VFetchNodeStateOptionsFactory()
+	options.DBName = dbOptions.DBName
+	options.RawHosts = dbOptions.RawHosts
+	options.IPv6 = dbOptions.IPv6
+	if password, ok := os.LookupEnv(vclusterPasswordEnv); ok {
+		options.Password = &password
+	} else if password, ok, err := readPasswordFromConfig(); err == nil && ok {
+		options.Password = &password
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), liveCompletionTimeout)
+	defer cancel()
+
+	// the logger does not print anything, so that the shell only gets the completions
+	vcc := vclusterops.VClusterCommands{VClusterCommandsLogger: vclusterops.VClusterCommandsLogger{Log: vlog.Printer{}},
+		Ctx: ctx}
+	nodeStates, err := vcc.VFetchNodeState(&options)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []completionNode
+	for i := range nodeStates {
+		nodes = append(nodes, completionNode{name: nodeStates[i].Name, subcluster: nodeStates[i].Subcluster})
+	}
+	return nodes, nil
+}
+
+// getCompletionNames returns the sorted distinct names of a kind of objects
+func getCompletionNames(nodes []completionNode, kind int) []string {
+	nameSet := make(map[string]struct{})
+	for _, n := range nodes {
+		var name string
+		switch kind {
+		case completeSubcluster:
+			name = n.subcluster
+		case completeSandbox:
+			name = n.sandbox
+		case completeNode:
+			name = n.name
+		}
+		if name != "" {
+			nameSet[name] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(nameSet))
+	for name := range nameSet {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeFlagValue returns the names that complete the value being typed. The flags
+// that take a comma-separated list are completed after the last comma, and the
+// names already in the list are not suggested again.
+func completeFlagValue(names []string, toComplete string) []string {
+	prefix := ""
+	current := toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+		current = toComplete[i+1:]
+	}
+	typed := strings.Split(prefix, ",")
+	var completions []string
+	for _, name := range names {
+		if strings.HasPrefix(name, current) && !util.StringInArray(name, typed) {
+			completions = append(completions, prefix+name)
+		}
+	}
+	return completions
+}
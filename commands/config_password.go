@@ -0,0 +1,171 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	vclusterPasswordKeyFileEnv = "VCLUSTER_PASSWORD_KEY_FILE"
+	defPasswordKeyFileName     = "password.key"
+	// encrypted passwords are stored as <prefix><base64 of nonce and ciphertext>
+	encryptedPasswordPrefix = "aes256gcm:"
+	passwordKeySize         = 32
+	passwordKeyFilePerm     = 0600
+)
+
+// the key file to encrypt and decrypt the password stored in the config file
+var passwordKeyFile string
+
+// getPasswordKeyFile returns the path of the password key file. The order of
+// precedence is the --password-key-file option, the environment variable, and
+// $HOME/.config/vcluster/password.key.
+func getPasswordKeyFile() (string, error) {
+	if passwordKeyFile != "" {
+		return passwordKeyFile, nil
+	}
+	if val, ok := os.LookupEnv(vclusterPasswordKeyFileEnv); ok && val != "" {
+		return val, nil
+	}
+	cfgDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("fail to find the password key file, use --%s to provide one: %w",
+			passwordKeyFileFlag, err)
+	}
+	return filepath.Join(cfgDir, "vcluster", defPasswordKeyFileName), nil
+}
+
+// readPasswordKey reads the key from the password key file
+func readPasswordKey(keyFilePath string) ([]byte, error) {
+	keyBytes, err := os.ReadFile(keyFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read password key file %q: %w", keyFilePath, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(keyBytes)))
+	if err != nil || len(key) != passwordKeySize {
+		return nil, fmt.Errorf("password key file %q does not have a valid %d-byte key", keyFilePath, passwordKeySize)
+	}
+	return key, nil
+}
+
+// readOrCreatePasswordKey reads the key from the password key file. If the
+// file does not exist, a new random key is generated and saved in it.
+func readOrCreatePasswordKey(keyFilePath string) ([]byte, error) {
+	_, err := os.Stat(keyFilePath)
+	if err == nil {
+		return readPasswordKey(keyFilePath)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("fail to access password key file %q: %w", keyFilePath, err)
+	}
+
+	key := make([]byte, passwordKeySize)
+	if _, err = rand.Read(key); err != nil {
+		return nil, fmt.Errorf("fail to generate password key: %w", err)
+	}
+	const keyDirPerm = 0700
+	err = os.MkdirAll(filepath.Dir(keyFilePath), keyDirPerm)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create directory for password key file %q: %w", keyFilePath, err)
+	}
+	err = os.WriteFile(keyFilePath, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), passwordKeyFilePerm)
+	if err != nil {
+		return nil, fmt.Errorf("fail to write password key file %q: %w", keyFilePath, err)
+	}
+	return key, nil
+}
+
+// encryptPassword encrypts the password with AES-256-GCM
+func encryptPassword(password string, key []byte) (string, error) {
+	gcm, err := newPasswordCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("fail to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(password), nil)
+	return encryptedPasswordPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptPassword decrypts a password encrypted by encryptPassword
+func decryptPassword(encryptedPassword string, key []byte) (string, error) {
+	encoded, ok := strings.CutPrefix(encryptedPassword, encryptedPasswordPrefix)
+	if !ok {
+		return "", fmt.Errorf("unsupported format of the encrypted password")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("fail to decode the encrypted password: %w", err)
+	}
+	gcm, err := newPasswordCipher(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("the encrypted password is too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	password, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("fail to decrypt the password, the password key file may not match: %w", err)
+	}
+	return string(password), nil
+}
+
+func newPasswordCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create password cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// readPasswordFromConfig returns the password stored in the config file, and
+// false if the config file has no password for the database
+func readPasswordFromConfig() (string, bool, error) {
+	if dbOptions.ConfigPath == "" {
+		return "", false, nil
+	}
+	dbConfig, err := readConfig()
+	if err != nil || dbConfig.EncryptedPassword == "" {
+		// commands like create_db run without a config file
+		return "", false, nil
+	}
+	keyFilePath, err := getPasswordKeyFile()
+	if err != nil {
+		return "", false, err
+	}
+	key, err := readPasswordKey(keyFilePath)
+	if err != nil {
+		return "", false, err
+	}
+	password, err := decryptPassword(dbConfig.EncryptedPassword, key)
+	if err != nil {
+		return "", false, err
+	}
+	return password, true, nil
+}
@@ -0,0 +1,430 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// the schemes of the config path to keep the config file in a remote store.
+// Append +https to the etcd and consul schemes to connect with TLS.
+const (
+	configStoreEtcd      = "etcd"
+	configStoreConsul    = "consul"
+	configStoreConfigMap = "configmap"
+	configStoreHTTPS     = "+https"
+	consulHTTPTokenEnv   = "CONSUL_HTTP_TOKEN"
+	configStoreTimeout   = 30 * time.Second
+)
+
+// ConfigStore persists the content of the config file. The config file is a
+// local file by default. If the config path is a URL, it is kept in a remote
+// store instead, so multiple admin hosts can share one config file:
+//   - etcd://<host>:<port>/<key>
+//   - consul://<host>:<port>/<key>
+//   - configmap://<namespace>/<name>/<key>
+type ConfigStore interface {
+	// Read returns the content of the config file. It returns an error
+	// wrapping os.ErrNotExist if the config file does not exist.
+	Read() ([]byte, error)
+	Write(content []byte) error
+	Remove() error
+	// List returns the sorted config paths in the same store that start
+	// with the config path of this store
+	List() ([]string, error)
+}
+
+// isRemoteConfigPath returns true if the config path is the URL of a remote store
+func isRemoteConfigPath(configPath string) bool {
+	return strings.Contains(configPath, "://")
+}
+
+// getConfigStore returns the store of the config file at the given path
+func getConfigStore(configPath string) (ConfigStore, error) {
+	if !isRemoteConfigPath(configPath) {
+		return &fileConfigStore{path: configPath}, nil
+	}
+
+	storeURL, err := url.Parse(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config store URL %q: %w", configPath, err)
+	}
+	scheme, useHTTPS := strings.CutSuffix(storeURL.Scheme, configStoreHTTPS)
+	key := strings.TrimPrefix(storeURL.Path, "/")
+	if key == "" {
+		return nil, fmt.Errorf("config store URL %q must have a key", configPath)
+	}
+
+	// keep the original URL to build the paths of other keys in List()
+	storeURL.Path = ""
+	switch scheme {
+	case configStoreEtcd, configStoreConsul:
+		endpoint := url.URL{Scheme: "http", Host: storeURL.Host}
+		if useHTTPS {
+			endpoint.Scheme = "https"
+		}
+		client := &http.Client{Timeout: configStoreTimeout}
+		if scheme == configStoreEtcd {
+			return &etcdConfigStore{endpoint: endpoint.String(), key: key, client: client, baseURL: storeURL.String()}, nil
+		}
+		return &consulConfigStore{endpoint: endpoint.String(), key: key, client: client, baseURL: storeURL.String()}, nil
+	case configStoreConfigMap:
+		name, dataKey, found := strings.Cut(key, "/")
+		if storeURL.Host == "" || !found || name == "" || dataKey == "" {
+			return nil, fmt.Errorf("config store URL %q must be in the form %s://<namespace>/<name>/<key>",
+				configPath, configStoreConfigMap)
+		}
+		return &configMapConfigStore{namespace: storeURL.Host, name: name, key: dataKey}, nil
+	default:
+		return nil, fmt.Errorf("unsupported config store %q, must be one of %s, %s or %s",
+			storeURL.Scheme, configStoreEtcd, configStoreConsul, configStoreConfigMap)
+	}
+}
+
+// readConfigStore reads the content of the config file at the given path
+func readConfigStore(configPath string) ([]byte, error) {
+	store, err := getConfigStore(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return store.Read()
+}
+
+// fileConfigStore keeps the config file on the local file system
+type fileConfigStore struct {
+	path string
+}
+
+func (s *fileConfigStore) Read() ([]byte, error) {
+	return os.ReadFile(s.path)
+}
+
+func (s *fileConfigStore) Write(content []byte) error {
+	return os.WriteFile(s.path, content, configFilePerm)
+}
+
+func (s *fileConfigStore) Remove() error {
+	return os.Remove(s.path)
+}
+
+func (s *fileConfigStore) List() ([]string, error) {
+	paths, err := filepath.Glob(s.path + "*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// etcdConfigStore keeps the config file in etcd, through the JSON gateway of the v3 API
+type etcdConfigStore struct {
+	endpoint string
+	key      string
+	client   *http.Client
+	baseURL  string
+}
+
+type etcdKeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value,omitempty"`
+	RangeEnd string `json:"range_end,omitempty"`
+	KeysOnly bool   `json:"keys_only,omitempty"`
+}
+
+type etcdRangeResponse struct {
+	Kvs []etcdKeyValue `json:"kvs"`
+}
+
+func (s *etcdConfigStore) post(path string, body any) ([]byte, error) {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return sendConfigStoreRequest(s.client, http.MethodPost, s.endpoint+path, reqBody, nil)
+}
+
+func (s *etcdConfigStore) Read() ([]byte, error) {
+	respBody, err := s.post("/v3/kv/range", etcdKeyValue{Key: base64.StdEncoding.EncodeToString([]byte(s.key))})
+	if err != nil {
+		return nil, err
+	}
+	var resp etcdRangeResponse
+	err = json.Unmarshal(respBody, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse etcd response: %w", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, fmt.Errorf("key %q is not found in etcd: %w", s.key, os.ErrNotExist)
+	}
+	return base64.StdEncoding.DecodeString(resp.Kvs[0].Value)
+}
+
+func (s *etcdConfigStore) Write(content []byte) error {
+	_, err := s.post("/v3/kv/put", etcdKeyValue{
+		Key:   base64.StdEncoding.EncodeToString([]byte(s.key)),
+		Value: base64.StdEncoding.EncodeToString(content),
+	})
+	return err
+}
+
+func (s *etcdConfigStore) Remove() error {
+	_, err := s.post("/v3/kv/deleterange", etcdKeyValue{Key: base64.StdEncoding.EncodeToString([]byte(s.key))})
+	return err
+}
+
+func (s *etcdConfigStore) List() ([]string, error) {
+	// the range of keys with the prefix ends at the prefix with its last byte incremented
+	rangeEnd := []byte(s.key)
+	rangeEnd[len(rangeEnd)-1]++
+	respBody, err := s.post("/v3/kv/range", etcdKeyValue{
+		Key:      base64.StdEncoding.EncodeToString([]byte(s.key)),
+		RangeEnd: base64.StdEncoding.EncodeToString(rangeEnd),
+		KeysOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resp etcdRangeResponse
+	err = json.Unmarshal(respBody, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse etcd response: %w", err)
+	}
+	var paths []string
+	for _, kv := range resp.Kvs {
+		key, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil {
+			return nil, fmt.Errorf("fail to decode etcd key: %w", err)
+		}
+		paths = append(paths, s.baseURL+"/"+string(key))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// consulConfigStore keeps the config file in the consul key/value store. The
+// ACL token is read from the CONSUL_HTTP_TOKEN environment variable.
+type consulConfigStore struct {
+	endpoint string
+	key      string
+	client   *http.Client
+	baseURL  string
+}
+
+func (s *consulConfigStore) send(method, query string, body []byte) ([]byte, error) {
+	header := map[string]string{}
+	if token := os.Getenv(consulHTTPTokenEnv); token != "" {
+		header["X-Consul-Token"] = token
+	}
+	return sendConfigStoreRequest(s.client, method, s.endpoint+"/v1/kv/"+s.key+query, body, header)
+}
+
+func (s *consulConfigStore) Read() ([]byte, error) {
+	return s.send(http.MethodGet, "?raw", nil)
+}
+
+func (s *consulConfigStore) Write(content []byte) error {
+	_, err := s.send(http.MethodPut, "", content)
+	return err
+}
+
+func (s *consulConfigStore) Remove() error {
+	_, err := s.send(http.MethodDelete, "", nil)
+	return err
+}
+
+func (s *consulConfigStore) List() ([]string, error) {
+	respBody, err := s.send(http.MethodGet, "?keys", nil)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	err = json.Unmarshal(respBody, &keys)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse consul response: %w", err)
+	}
+	paths := make([]string, 0, len(keys))
+	for _, key := range keys {
+		paths = append(paths, s.baseURL+"/"+key)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// sendConfigStoreRequest sends a request to a remote config store and returns the
+// response body. A 404 response is returned as an error wrapping os.ErrNotExist.
+func sendConfigStoreRequest(client *http.Client, method, reqURL string, body []byte,
+	header map[string]string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), configStoreTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fail to connect to config store: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read response from config store: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s is not found in config store: %w", reqURL, os.ErrNotExist)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("config store returned %s: %s", resp.Status, string(respBody))
+	}
+	return respBody, nil
+}
+
+// configMapConfigStore keeps the config file in a key of a Kubernetes ConfigMap.
+// It uses the service account of the pod vcluster runs in.
+type configMapConfigStore struct {
+	namespace string
+	name      string
+	key       string
+}
+
+func (s *configMapConfigStore) getClient() (kubernetes.Interface, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("fail to get the in-cluster config of Kubernetes: %w", err)
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+// getConfigMap returns the ConfigMap, or nil if it does not exist
+func (s *configMapConfigStore) getConfigMap(ctx context.Context, client kubernetes.Interface) (*corev1.ConfigMap, error) {
+	configMap, err := client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fail to get ConfigMap %s/%s: %w", s.namespace, s.name, err)
+	}
+	return configMap, nil
+}
+
+func (s *configMapConfigStore) Read() ([]byte, error) {
+	client, err := s.getClient()
+	if err != nil {
+		return nil, err
+	}
+	configMap, err := s.getConfigMap(context.Background(), client)
+	if err != nil {
+		return nil, err
+	}
+	if configMap == nil {
+		return nil, fmt.Errorf("ConfigMap %s/%s is not found: %w", s.namespace, s.name, os.ErrNotExist)
+	}
+	content, ok := configMap.Data[s.key]
+	if !ok {
+		return nil, fmt.Errorf("key %q is not found in ConfigMap %s/%s: %w", s.key, s.namespace, s.name, os.ErrNotExist)
+	}
+	return []byte(content), nil
+}
+
+func (s *configMapConfigStore) Write(content []byte) error {
+	client, err := s.getClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	configMap, err := s.getConfigMap(ctx, client)
+	if err != nil {
+		return err
+	}
+	if configMap == nil {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: s.namespace, Name: s.name},
+			Data:       map[string]string{s.key: string(content)},
+		}
+		_, err = client.CoreV1().ConfigMaps(s.namespace).Create(ctx, configMap, metav1.CreateOptions{})
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[s.key] = string(content)
+	_, err = client.CoreV1().ConfigMaps(s.namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
+
+// Remove removes the key of the config file. The ConfigMap itself is kept
+// as it may hold other keys.
+func (s *configMapConfigStore) Remove() error {
+	client, err := s.getClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	configMap, err := s.getConfigMap(ctx, client)
+	if err != nil || configMap == nil {
+		return err
+	}
+	if _, ok := configMap.Data[s.key]; !ok {
+		return nil
+	}
+	delete(configMap.Data, s.key)
+	_, err = client.CoreV1().ConfigMaps(s.namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
+
+func (s *configMapConfigStore) List() ([]string, error) {
+	client, err := s.getClient()
+	if err != nil {
+		return nil, err
+	}
+	configMap, err := s.getConfigMap(context.Background(), client)
+	if err != nil || configMap == nil {
+		return nil, err
+	}
+	var paths []string
+	for key := range configMap.Data {
+		if strings.HasPrefix(key, s.key) {
+			paths = append(paths, fmt.Sprintf("%s://%s/%s/%s", configStoreConfigMap, s.namespace, s.name, key))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -0,0 +1,157 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestGetConfigStore(t *testing.T) {
+	store, err := getConfigStore("/opt/vertica/config/vertica_cluster.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, &fileConfigStore{path: "/opt/vertica/config/vertica_cluster.yaml"}, store)
+
+	store, err = getConfigStore("etcd+https://10.20.30.40:2379/vcluster/vertica_cluster.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://10.20.30.40:2379", store.(*etcdConfigStore).endpoint)
+	assert.Equal(t, "vcluster/vertica_cluster.yaml", store.(*etcdConfigStore).key)
+
+	store, err = getConfigStore("configmap://vertica/vcluster-config/vertica_cluster.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, &configMapConfigStore{namespace: "vertica", name: "vcluster-config", key: "vertica_cluster.yaml"}, store)
+
+	_, err = getConfigStore("configmap://vertica/vcluster-config")
+	assert.ErrorContains(t, err, "must be in the form")
+	_, err = getConfigStore("consul://10.20.30.40:8500")
+	assert.ErrorContains(t, err, "must have a key")
+	_, err = getConfigStore("zookeeper://10.20.30.40:2181/vcluster")
+	assert.ErrorContains(t, err, "unsupported config store")
+}
+
+// testConfigStore writes, reads and removes a config file in a store
+func testConfigStore(t *testing.T, configPath string) {
+	dbConfig := MakeDatabaseConfig()
+	dbConfig.Name = "test_db"
+	dbConfig.Nodes = []*NodeConfig{{Name: "v_test_db_node0001", Address: "192.168.1.101"}}
+
+	_, err := readConfigFile(configPath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.NoError(t, dbConfig.write(configPath))
+	config, err := readConfigFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, dbConfig, config.Database)
+
+	store, err := getConfigStore(configPath)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Remove())
+	_, err = store.Read()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestFileConfigStore(t *testing.T) {
+	testConfigStore(t, filepath.Join(t.TempDir(), "vertica_cluster.yaml"))
+}
+
+func TestEtcdConfigStore(t *testing.T) {
+	kv := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req etcdKeyValue
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		resp := etcdRangeResponse{}
+		switch r.URL.Path {
+		case "/v3/kv/range":
+			if value, ok := kv[req.Key]; ok {
+				resp.Kvs = []etcdKeyValue{{Key: req.Key, Value: value}}
+			}
+		case "/v3/kv/put":
+			kv[req.Key] = req.Value
+		case "/v3/kv/deleterange":
+			delete(kv, req.Key)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(&resp))
+	}))
+	defer server.Close()
+
+	testConfigStore(t, strings.Replace(server.URL, "http", configStoreEtcd, 1)+"/vcluster/vertica_cluster.yaml")
+}
+
+func TestConsulConfigStore(t *testing.T) {
+	kv := map[string][]byte{}
+	t.Setenv(consulHTTPTokenEnv, "test_token")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test_token", r.Header.Get("X-Consul-Token"))
+		key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+		switch r.Method {
+		case http.MethodGet:
+			value, ok := kv[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(value)
+		case http.MethodPut:
+			value, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			kv[key] = value
+		case http.MethodDelete:
+			delete(kv, key)
+		}
+	}))
+	defer server.Close()
+
+	testConfigStore(t, strings.Replace(server.URL, "http", configStoreConsul, 1)+"/vcluster/vertica_cluster.yaml")
+}
+
+func TestConfigBackups(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "vertica_cluster.yaml")
+	config := Config{BackupRetention: 2}
+	config.Database.Name = "test_db"
+	logger := vlog.Printer{}
+
+	// no backup is made before the config file exists
+	assert.NoError(t, backupConfigFile(configPath, logger))
+	versions, err := listConfigBackups(configPath)
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+
+	for i := 0; i < 3; i++ {
+		config.Database.CommunalStorageLocation = fmt.Sprintf("s3://bucket/%d", i)
+		assert.NoError(t, backupConfigFile(configPath, logger))
+		assert.NoError(t, config.write(configPath))
+	}
+
+	// only the two newest backups are kept
+	versions, err = listConfigBackups(configPath)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	backup, err := readConfigFile(getConfigBackupPrefix(configPath) + versions[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "s3://bucket/1", backup.Database.CommunalStorageLocation)
+}
@@ -0,0 +1,186 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigViolation is a problem found in the config file
+type ConfigViolation struct {
+	// the path of the field with the problem, such as databases.prod.nodes[1].address
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (v ConfigViolation) String() string {
+	if v.Field == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("%s: %s", v.Field, v.Message)
+}
+
+// ValidateClusterConfig checks the content of a config file and returns all the
+// violations found in it, such as duplicate nodes, bad IPs or missing paths.
+func ValidateClusterConfig(config *Config) []ConfigViolation {
+	var violations []ConfigViolation
+	if config.Version != currentConfigFileVersion {
+		violations = append(violations, ConfigViolation{Field: "configFileVersion",
+			Message: fmt.Sprintf("unsupported version %q, must be %q", config.Version, currentConfigFileVersion)})
+	}
+	if config.BackupRetention < 0 {
+		violations = append(violations, ConfigViolation{Field: "backupRetention",
+			Message: "backup retention must not be negative"})
+	}
+
+	if config.Database.Name != "" || len(config.Database.Nodes) > 0 || len(config.Databases) == 0 {
+		violations = append(violations, config.Database.validate("")...)
+	}
+	keys := maps.Keys(config.Databases)
+	sort.Strings(keys)
+	for _, key := range keys {
+		violations = append(violations, config.Databases[key].validate(fmt.Sprintf("databases.%s.", key))...)
+	}
+	return violations
+}
+
+// validate returns the violations in a database of the config file. The
+// prefix is added to the field of each violation.
+func (c *DatabaseConfig) validate(prefix string) []ConfigViolation {
+	var violations []ConfigViolation
+	addViolation := func(field, format string, args ...any) {
+		violations = append(violations, ConfigViolation{Field: prefix + field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Name == "" {
+		addViolation("dbName", "database name is missing")
+	} else if err := util.ValidateDBName(c.Name); err != nil {
+		addViolation("dbName", "%s", err)
+	}
+	if c.IsEon && c.CommunalStorageLocation == "" {
+		addViolation("communalStorageLocation", "communal storage location is missing for an Eon database")
+	}
+	if len(c.Nodes) == 0 {
+		addViolation("nodes", "database has no nodes")
+	}
+
+	nodeNames := make(map[string]int)
+	addresses := make(map[string]int)
+	// a subcluster is either in the main cluster or in a sandbox
+	subclusterNodes := make(map[string]int)
+	for i, node := range c.Nodes {
+		nodeField := fmt.Sprintf("nodes[%d].", i)
+		if node.Name == "" {
+			addViolation(nodeField+"name", "node name is missing")
+		} else if j, ok := nodeNames[node.Name]; ok {
+			addViolation(nodeField+"name", "node %s is a duplicate of nodes[%d]", node.Name, j)
+		} else {
+			nodeNames[node.Name] = i
+		}
+
+		switch {
+		case node.Address == "":
+			addViolation(nodeField+"address", "address is missing")
+		case c.Ipv6 && !util.IsIPv6(node.Address), !c.Ipv6 && !util.IsIPv4(node.Address):
+			ipVersion := "IPv4"
+			if c.Ipv6 {
+				ipVersion = "IPv6"
+			}
+			addViolation(nodeField+"address", "%s is not a valid %s address", node.Address, ipVersion)
+		default:
+			if j, ok := addresses[node.Address]; ok {
+				addViolation(nodeField+"address", "address %s is a duplicate of nodes[%d]", node.Address, j)
+			} else {
+				addresses[node.Address] = i
+			}
+		}
+
+		if j, ok := subclusterNodes[node.Subcluster]; !ok {
+			subclusterNodes[node.Subcluster] = i
+		} else if c.Nodes[j].Sandbox != node.Sandbox {
+			addViolation(nodeField+"sandbox", "node is in sandbox %q but nodes[%d] of the same subcluster %s is in sandbox %q",
+				node.Sandbox, j, node.Subcluster, c.Nodes[j].Sandbox)
+		}
+
+		paths := []struct {
+			field    string
+			path     string
+			required bool
+		}{
+			{"catalogPath", node.CatalogPath, true},
+			// the data path is not known in a recovered config file
+			{"dataPath", node.DataPath, false},
+			{"depotPath", node.DepotPath, c.IsEon},
+		}
+		for _, p := range paths {
+			if p.path == "" {
+				if p.required {
+					addViolation(nodeField+p.field, "path is missing")
+				}
+				continue
+			}
+			if err := util.AbsPathCheck(p.path); err != nil {
+				addViolation(nodeField+p.field, "%s is not an absolute path", p.path)
+			}
+		}
+	}
+	return violations
+}
+
+// checkConfigSchema decodes a config file and rejects the fields that are not in
+// the schema of the config file, such as misspelled keys
+func checkConfigSchema(configBytes []byte, format string) error {
+	switch format {
+	case configFormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(configBytes))
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(&flatConfig{})
+	case configFormatTOML:
+		decoder := toml.NewDecoder(bytes.NewReader(configBytes))
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(&flatConfig{})
+	default:
+		decoder := yaml.NewDecoder(bytes.NewReader(configBytes))
+		decoder.KnownFields(true)
+		return decoder.Decode(&Config{})
+	}
+}
+
+// validateConfigFile reads a config file and returns its violations. A config
+// file that cannot be parsed is reported as a single violation.
+func validateConfigFile(configPath string) ([]ConfigViolation, error) {
+	configBytes, err := readConfigStore(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read configuration file, details: %w", err)
+	}
+	format := getConfigFormat(configPath)
+	if err = checkConfigSchema(configBytes, format); err != nil {
+		return []ConfigViolation{{Message: fmt.Sprintf("invalid %s: %s", format, err)}}, nil
+	}
+	config, err := unmarshalConfig(configBytes, format)
+	if err != nil {
+		return []ConfigViolation{{Message: fmt.Sprintf("invalid %s: %s", format, err)}}, nil
+	}
+	return ValidateClusterConfig(config), nil
+}
@@ -0,0 +1,122 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vertica/vcluster/vclusterops"
+)
+
+// The exit codes of vcluster, by the class of the failure. They are part of
+// the interface of the CLI, so a code must never be reused for another class.
+const (
+	exitCodeSuccess = 0
+	// any failure that is not in the classes below
+	exitCodeFailure = 1
+	// the command line is not valid
+	exitCodeUsage = 2
+	// a host rejects the password or the certificate
+	exitCodeAuthFailure = 3
+	// the database does not have, or would lose, the quorum of its primary nodes
+	exitCodeQuorumFailure = 4
+	// the state of the database or the hosts does not allow the command
+	exitCodePreconditionFailure = 5
+	// the command succeeds on some of its targets, and fails on the others
+	exitCodePartialSuccess = 6
+	// same as the timeout utility, so that scripts can tell a timeout from a failure
+	exitCodeTimeout     = 124
+	exitCodeInterrupted = 130
+)
+
+// exitCodesHelp documents the exit codes in the help of vcluster
+const exitCodesHelp = `
+Exit codes:
+  0    the command succeeds
+  1    the command fails
+  2    the command line is not valid
+  3    a host rejects the password or the certificate
+  4    the database does not have, or would lose, the quorum of its primary nodes
+  5    the state of the database or the hosts does not allow the command
+  6    the command succeeds on some of its targets, and fails on the others
+  124  the command reaches the deadline given by --command-timeout
+  130  the command is interrupted by SIGINT or SIGTERM`
+
+// usageError is returned when the command line is not valid
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// getExitCode returns the exit code of vcluster for the error of a command. When
+// an error has several causes, the class of the first one found is used.
+func getExitCode(err error) int {
+	if err == nil {
+		return exitCodeSuccess
+	}
+
+	var canceledErr *cmdCanceledError
+	if errors.As(err, &canceledErr) {
+		if errors.Is(canceledErr.cause, context.DeadlineExceeded) {
+			return exitCodeTimeout
+		}
+		return exitCodeInterrupted
+	}
+
+	var usageErr *usageError
+	var authErr *vclusterops.AuthFailureError
+	var quorumErr *vclusterops.QuorumError
+	var reIPQuorumErr *vclusterops.ReIPNoClusterQuorumError
+	var partialErr *vclusterops.PartialSuccessError
+	switch {
+	case errors.As(err, &usageErr):
+		return exitCodeUsage
+	case errors.As(err, &authErr):
+		return exitCodeAuthFailure
+	case errors.As(err, &quorumErr), errors.As(err, &reIPQuorumErr):
+		return exitCodeQuorumFailure
+	case isPreconditionError(err):
+		return exitCodePreconditionFailure
+	case errors.As(err, &partialErr):
+		return exitCodePartialSuccess
+	}
+	return exitCodeFailure
+}
+
+// isPreconditionError returns true if the error is one of the errors that
+// vclusterops returns when the state of the database does not allow an operation
+func isPreconditionError(err error) bool {
+	var preconditionErr *vclusterops.PreconditionError
+	var dbRunningErr *vclusterops.DBIsRunningError
+	var leaseErr *vclusterops.ClusterLeaseNotExpiredError
+	var nodeCountErr *vclusterops.ReviveDBNodeCountMismatchError
+	var restorePointErr *vclusterops.ReviveDBRestorePointNotFoundError
+	var notSandboxedErr *vclusterops.SubclusterNotSandboxedError
+	return errors.As(err, &preconditionErr) ||
+		errors.As(err, &dbRunningErr) ||
+		errors.As(err, &leaseErr) ||
+		errors.As(err, &nodeCountErr) ||
+		errors.As(err, &restorePointErr) ||
+		errors.As(err, &notSandboxedErr)
+}
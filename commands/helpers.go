@@ -15,18 +15,49 @@ limitations under the License.
 package commands
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"golang.org/x/term"
 )
 
 const kubernetesPort = "KUBERNETES_PORT"
 
-func readDBPasswordFromPrompt() (string, error) {
-	// Prompt the user to enter the password
-	fmt.Print("Enter password: ")
+const (
+	// the environment variable that gives the database password
+	vclusterPasswordEnv = "VCLUSTER_PASSWORD"
+	// the password source that reads the password from stdin
+	stdinPasswordSource = "-"
+)
+
+// readDBPasswordFromPrompt reads the password without echoing it. With confirm,
+// the password is typed twice, so that a typo does not set a wrong password.
+func readDBPasswordFromPrompt(confirm bool) (string, error) {
+	password, err := readHiddenInput("Enter password: ")
+	if err != nil {
+		return "", err
+	}
+	if !confirm {
+		return password, nil
+	}
+	confirmation, err := readHiddenInput("Confirm password: ")
+	if err != nil {
+		return "", err
+	}
+	if password != confirmation {
+		return "", fmt.Errorf("the passwords do not match")
+	}
+	return password, nil
+}
+
+func readHiddenInput(prompt string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("cannot prompt for the password because stdin is not a terminal")
+	}
+	fmt.Print(prompt)
 
 	// Disable echoing
 	passwordBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
@@ -37,6 +68,22 @@ func readDBPasswordFromPrompt() (string, error) {
 	return string(passwordBytes), nil
 }
 
+// readConfirmationFromPrompt prints the prompt and reads one line of user input.
+// It fails if stdin is not a terminal, so that a confirmation is never read from
+// piped input by accident.
+func readConfirmationFromPrompt(prompt string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("cannot read the confirmation because stdin is not a terminal")
+	}
+	fmt.Print(prompt)
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("error reading confirmation: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
 func readFromStdin() (string, error) {
 	data, err := io.ReadAll(os.Stdin)
 	if err != nil {
@@ -14,13 +14,21 @@ limitations under the License.
 */
 package commands
 
+import "github.com/spf13/cobra"
+
 func init() {
 	dbOptions.Password = new(string)
 	// set the log path depending on executable path
 	setLogPath()
 
+	// the errors of the flags exit with the usage exit code
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
+
 	allCommands := constructCmds()
 	for _, c := range allCommands {
 		rootCmd.AddCommand(c)
+		registerFlagCompletions(c)
 	}
 }
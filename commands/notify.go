@@ -0,0 +1,118 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// the environment variables of the webhook that is notified when a command ends
+const (
+	vclusterNotifyURLEnv = "VCLUSTER_NOTIFY_URL"
+	vclusterNotifyOnEnv  = "VCLUSTER_NOTIFY_ON"
+)
+
+// the values of VCLUSTER_NOTIFY_ON
+const (
+	notifyOnAlways  = "always"
+	notifyOnFailure = "failure"
+)
+
+const (
+	notifyTimeout       = 10 * time.Second
+	notifyResultSuccess = "success"
+	notifyResultFailure = "failure"
+)
+
+// cmdNotification is the JSON payload that is posted to the webhook
+type cmdNotification struct {
+	// a summary of the notification, which Slack shows as the message
+	Text      string    `json:"text"`
+	Command   string    `json:"command"`
+	DBName    string    `json:"dbName,omitempty"`
+	Hosts     []string  `json:"hosts,omitempty"`
+	Hostname  string    `json:"hostname"`
+	Result    string    `json:"result"`
+	ExitCode  int       `json:"exitCode"`
+	Error     string    `json:"error,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+// notifyCmdCompletion posts the result of a command to the webhook given by
+// VCLUSTER_NOTIFY_URL. A failure to notify is printed as a warning, so it does
+// not change the result of the command.
+func notifyCmdCompletion(cmdName string, startTime time.Time, cmdErr error) {
+	url := os.Getenv(vclusterNotifyURLEnv)
+	if url == "" || (cmdErr == nil && os.Getenv(vclusterNotifyOnEnv) == notifyOnFailure) {
+		return
+	}
+	err := postCmdNotification(url, makeCmdNotification(cmdName, startTime, cmdErr))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: fail to notify the webhook of the end of %s: %s\n", cmdName, err)
+	}
+}
+
+func makeCmdNotification(cmdName string, startTime time.Time, cmdErr error) *cmdNotification {
+	notification := cmdNotification{
+		Command:   cmdName,
+		DBName:    dbOptions.DBName,
+		Hosts:     dbOptions.Hosts,
+		Result:    notifyResultSuccess,
+		ExitCode:  getExitCode(cmdErr),
+		StartTime: startTime,
+		EndTime:   time.Now(),
+	}
+	if len(notification.Hosts) == 0 {
+		notification.Hosts = dbOptions.RawHosts
+	}
+	notification.Hostname, _ = os.Hostname()
+	target := cmdName
+	if notification.DBName != "" {
+		target = fmt.Sprintf("%s of database %s", cmdName, notification.DBName)
+	}
+	if cmdErr != nil {
+		notification.Result = notifyResultFailure
+		notification.Error = cmdErr.Error()
+		notification.Text = fmt.Sprintf("vcluster %s failed on %s with exit code %d: %s",
+			target, notification.Hostname, notification.ExitCode, notification.Error)
+	} else {
+		notification.Text = fmt.Sprintf("vcluster %s succeeded on %s", target, notification.Hostname)
+	}
+	return &notification
+}
+
+func postCmdNotification(url string, notification *cmdNotification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	client := http.Client{Timeout: notifyTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("the webhook returned the status %s", resp.Status)
+	}
+	return nil
+}
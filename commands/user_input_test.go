@@ -22,7 +22,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops"
+	"github.com/vertica/vcluster/vclusterops/vlog"
 )
 
 var tempConfigFilePath = os.TempDir() + "/test_vertica_cluster.yaml"
@@ -90,3 +93,88 @@ func TestStartReplication(t *testing.T) {
 	err := simulateVClusterCli("vcluster replication start")
 	assert.ErrorContains(t, err, `required flag(s) "target-db-name", "target-hosts" not set`)
 }
+
+func TestReadDBPassword(t *testing.T) {
+	makeCmdBase := func(args ...string) (*CmdBase, *vclusterops.DatabaseOptions) {
+		c := &CmdBase{}
+		opt := vclusterops.DatabaseOptionsFactory()
+		opt.Password = new(string)
+		parser := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		parser.StringVar(opt.Password, passwordFlag, "", "")
+		parser.StringVar(&c.passwordFile, passwordFileFlag, "", "")
+		parser.BoolVar(&c.readPasswordFromPrompt, readPasswordFromPromptFlag, false, "")
+		assert.NoError(t, parser.Parse(args))
+		c.SetParser(parser)
+		return c, &opt
+	}
+	savedConfigPath := dbOptions.ConfigPath
+	dbOptions.ConfigPath = ""
+	defer func() { dbOptions.ConfigPath = savedConfigPath }()
+
+	// no password source is given
+	c, opt := makeCmdBase()
+	assert.NoError(t, c.setDBPassword(opt))
+	assert.Nil(t, opt.Password)
+
+	// the environment variable is used when no flag is given
+	t.Setenv(vclusterPasswordEnv, "env-password")
+	c, opt = makeCmdBase()
+	assert.True(t, c.usePassword())
+	assert.NoError(t, c.setDBPassword(opt))
+	assert.Equal(t, "env-password", *opt.Password)
+
+	// the flags take precedence over the environment variable
+	c, opt = makeCmdBase("--password", "flag-password")
+	assert.NoError(t, c.setDBPassword(opt))
+	assert.Equal(t, "flag-password", *opt.Password)
+
+	passwordFile := t.TempDir() + "/password"
+	assert.NoError(t, os.WriteFile(passwordFile, []byte("file-password\n"), 0600))
+	c, opt = makeCmdBase("--password-file", passwordFile)
+	assert.NoError(t, c.setDBPassword(opt))
+	assert.Equal(t, "file-password", *opt.Password)
+}
+
+func TestConfirmDestructive(t *testing.T) {
+	// the tests do not run in a terminal, so the confirmation cannot be prompted
+	c := &CmdBase{}
+	err := c.confirmDestructive("drop the database test_db", "test_db", vlog.Printer{})
+	assert.ErrorContains(t, err, "--yes")
+	assert.Equal(t, exitCodeUsage, getExitCode(err))
+
+	c.skipConfirm = true
+	assert.NoError(t, c.confirmDestructive("drop the database test_db", "test_db", vlog.Printer{}))
+
+	// both flags skip the confirmation
+	for _, flag := range []string{yesFlag, forceConfirmFlag} {
+		cmd := makeCmdStopDB()
+		assert.NoError(t, cmd.Flags().Parse([]string{"--" + flag}))
+		value, e := cmd.Flags().GetBool(flag)
+		assert.NoError(t, e)
+		assert.True(t, value)
+	}
+}
+
+func TestShell(t *testing.T) {
+	args, err := splitShellLine(`stop_subcluster --subcluster 'sc 1'  --db-name "test_db"`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"stop_subcluster", "--subcluster", "sc 1", "--db-name", "test_db"}, args)
+	_, err = splitShellLine(`status --db-name "test_db`)
+	assert.ErrorContains(t, err, "unterminated quote")
+
+	var out strings.Builder
+	c := &CmdShell{session: map[string]string{configFlag: "/opt/vertica/config/vertica_cluster.yaml"},
+		in: strings.NewReader("set --db-name test_db\nsession\nunset config\nsession\nexit\n"), out: &out}
+	assert.NoError(t, c.run())
+	assert.Contains(t, out.String(), "  --config /opt/vertica/config/vertica_cluster.yaml\n  --db-name test_db\n")
+	assert.Equal(t, map[string]string{dbNameFlag: "test_db"}, c.session)
+
+	// the session options are only added to the subcommands that accept them,
+	// when they are not on the line
+	c.session[hostsFlag] = "192.168.1.101"
+	assert.Equal(t, []string{"stop_subcluster", "--subcluster", "sc1", "--db-name", "test_db", "--hosts", "192.168.1.101"},
+		c.withSessionFlags([]string{"stop_subcluster", "--subcluster", "sc1"}))
+	assert.Equal(t, []string{"stop_subcluster", "-d", "db2", "--hosts", "192.168.1.101"},
+		c.withSessionFlags([]string{"stop_subcluster", "-d", "db2"}))
+	assert.Equal(t, []string{"manage_config", "show"}, c.withSessionFlags([]string{"manage_config", "show"}))
+}
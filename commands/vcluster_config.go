@@ -16,15 +16,23 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/pelletier/go-toml/v2"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/vertica/vcluster/vclusterops"
 	"github.com/vertica/vcluster/vclusterops/util"
 	"github.com/vertica/vcluster/vclusterops/vlog"
+	"golang.org/x/exp/maps"
 	"gopkg.in/yaml.v3"
 )
 
@@ -34,14 +42,51 @@ const (
 	// default file name that we'll use.
 	defConfigFileName        = "vertica_cluster.yaml"
 	currentConfigFileVersion = "1.0"
-	configBackupName         = "vertica_cluster.yaml.backup"
+	configBackupSuffix       = ".backup"
+	configBackupTimeFormat   = "20060102T150405.000000Z"
+	defConfigBackupRetention = 10
 	configFilePerm           = 0600
 )
 
+// the formats of the config file, detected by the file extension
+const (
+	configFormatYAML = "yaml"
+	configFormatJSON = "json"
+	configFormatTOML = "toml"
+)
+
 // Config is the struct of vertica_cluster.yaml
 type Config struct {
 	Version  string         `yaml:"configFileVersion"`
 	Database DatabaseConfig `yaml:",inline"`
+	// more databases in the same file, selected by their key with --db-key
+	Databases map[string]*DatabaseConfig `yaml:"databases,omitempty"`
+	// the number of backups kept when the config file is updated, defaults to 10
+	BackupRetention int `yaml:"backupRetention,omitempty"`
+}
+
+// flatConfig is the layout of Config in JSON and TOML config files, which
+// have the database fields at the top level like yaml config files
+type flatConfig struct {
+	Version                 string                         `json:"configFileVersion" toml:"configFileVersion"`
+	Name                    string                         `json:"dbName" toml:"dbName"`
+	Nodes                   []*NodeConfig                  `json:"nodes" toml:"nodes"`
+	IsEon                   bool                           `json:"eonMode" toml:"eonMode"`
+	CommunalStorageLocation string                         `json:"communalStorageLocation" toml:"communalStorageLocation"`
+	Ipv6                    bool                           `json:"ipv6" toml:"ipv6"`
+	EncryptedPassword       string                         `json:"encryptedPassword,omitempty" toml:"encryptedPassword,omitempty"`
+	Databases               map[string]*flatDatabaseConfig `json:"databases,omitempty" toml:"databases,omitempty"`
+	BackupRetention         int                            `json:"backupRetention,omitempty" toml:"backupRetention,omitempty"`
+}
+
+// flatDatabaseConfig is the layout of DatabaseConfig in JSON and TOML config files
+type flatDatabaseConfig struct {
+	Name                    string        `json:"dbName" toml:"dbName"`
+	Nodes                   []*NodeConfig `json:"nodes" toml:"nodes"`
+	IsEon                   bool          `json:"eonMode" toml:"eonMode"`
+	CommunalStorageLocation string        `json:"communalStorageLocation" toml:"communalStorageLocation"`
+	Ipv6                    bool          `json:"ipv6" toml:"ipv6"`
+	EncryptedPassword       string        `json:"encryptedPassword,omitempty" toml:"encryptedPassword,omitempty"`
 }
 
 // DatabaseConfig contains basic information for operating a database
@@ -51,18 +96,25 @@ type DatabaseConfig struct {
 	IsEon                   bool          `yaml:"eonMode" mapstructure:"eonMode"`
 	CommunalStorageLocation string        `yaml:"communalStorageLocation" mapstructure:"communalStorageLocation"`
 	Ipv6                    bool          `yaml:"ipv6" mapstructure:"ipv6"`
+	// the database password encrypted with the password key file
+	EncryptedPassword string `yaml:"encryptedPassword,omitempty" mapstructure:"encryptedPassword"`
 }
 
 // NodeConfig contains node information in the database
 type NodeConfig struct {
-	Name        string `yaml:"name" mapstructure:"name"`
-	Address     string `yaml:"address" mapstructure:"address"`
-	Subcluster  string `yaml:"subcluster" mapstructure:"subcluster"`
-	CatalogPath string `yaml:"catalogPath" mapstructure:"catalogPath"`
-	DataPath    string `yaml:"dataPath" mapstructure:"dataPath"`
-	DepotPath   string `yaml:"depotPath" mapstructure:"depotPath"`
+	Name        string `yaml:"name" json:"name" toml:"name" mapstructure:"name"`
+	Address     string `yaml:"address" json:"address" toml:"address" mapstructure:"address"`
+	Subcluster  string `yaml:"subcluster" json:"subcluster" toml:"subcluster" mapstructure:"subcluster"`
+	CatalogPath string `yaml:"catalogPath" json:"catalogPath" toml:"catalogPath" mapstructure:"catalogPath"`
+	DataPath    string `yaml:"dataPath" json:"dataPath" toml:"dataPath" mapstructure:"dataPath"`
+	DepotPath   string `yaml:"depotPath" json:"depotPath" toml:"depotPath" mapstructure:"depotPath"`
+	// empty if the node is in the main cluster
+	Sandbox string `yaml:"sandbox,omitempty" json:"sandbox,omitempty" toml:"sandbox,omitempty" mapstructure:"sandbox"`
 }
 
+// the key of the database to use in a config file with multiple databases
+var configDBKey string
+
 // MakeDatabaseConfig() can create an instance of DatabaseConfig
 func MakeDatabaseConfig() DatabaseConfig {
 	return DatabaseConfig{}
@@ -147,21 +199,40 @@ func initConfigImpl(vclusterExePath string, ensureOptVerticaConfigExists, ensure
 
 // loadConfigToViper can fill viper keys using vertica_cluster.yaml
 func loadConfigToViper() error {
-	// read config file
-	viper.SetConfigFile(dbOptions.ConfigPath)
-	err := viper.ReadInConfig()
+	// read config file, which can be in a remote store
+	configBytes, err := readConfigStore(dbOptions.ConfigPath)
+	if err == nil {
+		viper.SetConfigType(getConfigFormat(dbOptions.ConfigPath))
+		err = viper.ReadConfig(bytes.NewReader(configBytes))
+	}
 	if err != nil {
 		fmt.Printf("Warning: fail to read configuration file %q for viper: %v\n", dbOptions.ConfigPath, err)
 		return nil
 	}
 
-	// retrieve db info in viper
-	dbConfig := MakeDatabaseConfig()
-	err = viper.Unmarshal(&dbConfig)
+	// retrieve db info from the config file
+	config, err := unmarshalConfig(configBytes, getConfigFormat(dbOptions.ConfigPath))
 	if err != nil {
 		fmt.Printf("Warning: fail to unmarshal configuration file into DatabaseConfig: %v\n", err)
 		return nil
 	}
+	dbConfig, err := config.getDatabase(configDBKey, viper.GetString(dbNameKey))
+	if err != nil {
+		return err
+	}
+	// a database from the databases section replaces the top-level values
+	// of the config file, which still have lower priority than the flags
+	if dbConfig != &config.Database {
+		err = viper.MergeConfigMap(map[string]any{
+			dbNameKey:                  dbConfig.Name,
+			eonModeKey:                 dbConfig.IsEon,
+			communalStorageLocationKey: dbConfig.CommunalStorageLocation,
+			ipv6Key:                    dbConfig.Ipv6,
+		})
+		if err != nil {
+			return err
+		}
+	}
 
 	// if we can read config file, check if dbName in user input matches the one in config file
 	if viper.IsSet(dbNameKey) && dbConfig.Name != viper.GetString(dbNameKey) {
@@ -172,6 +243,9 @@ func loadConfigToViper() error {
 	// they are the values in each node so they need extra process.
 	if !viper.IsSet(hostsKey) {
 		viper.Set(hostsKey, dbConfig.getHosts())
+		// the nodes can have different paths, which only apply
+		// when the hosts are taken from the config file
+		dbOptions.HostPathPrefixes = dbConfig.getHostPathPrefixes()
 	}
 	catalogPrefix, dataPrefix, depotPrefix := dbConfig.getPathPrefixes()
 	if !viper.IsSet(catalogPathKey) {
@@ -226,8 +300,21 @@ func removeConfig(logger vlog.Printer) error {
 		return err
 	}
 
+	// keep the other databases in the config file
+	config, err := readConfigFile(dbOptions.ConfigPath)
+	if err == nil {
+		config.removeDatabase(configDBKey, dbOptions.DBName)
+		if !config.isEmpty() {
+			return config.write(dbOptions.ConfigPath)
+		}
+	}
+
 	// remove the old db config
-	return os.Remove(dbOptions.ConfigPath)
+	store, err := getConfigStore(dbOptions.ConfigPath)
+	if err != nil {
+		return err
+	}
+	return store.Remove()
 }
 
 // readVDBToDBConfig converts vdb to DatabaseConfig
@@ -243,21 +330,32 @@ func readVDBToDBConfig(vdb *vclusterops.VCoordinationDatabase) (DatabaseConfig,
 		nodeConfig.Name = vnode.Name
 		nodeConfig.Address = vnode.Address
 		nodeConfig.Subcluster = vnode.Subcluster
+		nodeConfig.Sandbox = vnode.Sandbox
 
 		// VER-91869 will replace the path prefixes with full paths
-		if vdb.CatalogPrefix == "" {
+		prefixes := vdb.HostPathPrefixes[host]
+		switch {
+		case prefixes.CatalogPrefix != "":
+			nodeConfig.CatalogPath = prefixes.CatalogPrefix
+		case vdb.CatalogPrefix == "":
 			nodeConfig.CatalogPath = util.GetPathPrefix(vnode.CatalogPath)
-		} else {
+		default:
 			nodeConfig.CatalogPath = vdb.CatalogPrefix
 		}
-		if vdb.DataPrefix == "" && len(vnode.StorageLocations) > 0 {
+		switch {
+		case prefixes.DataPrefix != "":
+			nodeConfig.DataPath = prefixes.DataPrefix
+		case vdb.DataPrefix == "" && len(vnode.StorageLocations) > 0:
 			nodeConfig.DataPath = util.GetPathPrefix(vnode.StorageLocations[0])
-		} else {
+		default:
 			nodeConfig.DataPath = vdb.DataPrefix
 		}
-		if vdb.IsEon && vdb.DepotPrefix == "" {
+		switch {
+		case prefixes.DepotPrefix != "":
+			nodeConfig.DepotPath = prefixes.DepotPrefix
+		case vdb.IsEon && vdb.DepotPrefix == "":
 			nodeConfig.DepotPath = util.GetPathPrefix(vnode.DepotPath)
-		} else {
+		default:
 			nodeConfig.DepotPath = vdb.DepotPrefix
 		}
 
@@ -272,59 +370,319 @@ func readVDBToDBConfig(vdb *vclusterops.VCoordinationDatabase) (DatabaseConfig,
 }
 
 // backupConfigFile backs up config file before we update it.
-// This function will add ".backup" suffix to previous config file.
+// This function will save the previous config file with a ".backup.<timestamp>"
+// suffix, and remove the oldest backups beyond the backup retention.
 func backupConfigFile(configFilePath string, logger vlog.Printer) error {
-	if util.CanReadAccessDir(configFilePath) == nil {
-		// copy file to vertica_cluster.yaml.backup
-		configDirPath := filepath.Dir(configFilePath)
-		configFileBackup := filepath.Join(configDirPath, configBackupName)
-		logger.Info("Configuration file exists and, creating a backup", "config file", configFilePath,
-			"backup file", configFileBackup)
-		err := util.CopyFile(configFilePath, configFileBackup, configFilePerm)
+	configBytes, err := readConfigStore(configFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// copy file to vertica_cluster.yaml.backup.<timestamp>
+	configFileBackup := getConfigBackupPrefix(configFilePath) + time.Now().UTC().Format(configBackupTimeFormat)
+	logger.Info("Configuration file exists and, creating a backup", "config file", configFilePath,
+		"backup file", configFileBackup)
+	backupStore, err := getConfigStore(configFileBackup)
+	if err != nil {
+		return err
+	}
+	err = backupStore.Write(configBytes)
+	if err != nil {
+		return err
+	}
+
+	retention := defConfigBackupRetention
+	config, err := unmarshalConfig(configBytes, getConfigFormat(configFilePath))
+	if err == nil && config.BackupRetention > 0 {
+		retention = config.BackupRetention
+	}
+	return pruneConfigBackups(configFilePath, retention, logger)
+}
+
+// getConfigBackupPrefix returns the path of the backups of a config file
+// without their timestamps
+func getConfigBackupPrefix(configFilePath string) string {
+	return configFilePath + configBackupSuffix + "."
+}
+
+// listConfigBackups returns the timestamps of the backups of a config file,
+// from the oldest to the newest
+func listConfigBackups(configFilePath string) ([]string, error) {
+	prefix := getConfigBackupPrefix(configFilePath)
+	prefixStore, err := getConfigStore(prefix)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := prefixStore.List()
+	if err != nil {
+		return nil, fmt.Errorf("fail to list the backups of configuration file, details: %w", err)
+	}
+	versions := make([]string, 0, len(paths))
+	for _, path := range paths {
+		versions = append(versions, strings.TrimPrefix(path, prefix))
+	}
+	return versions, nil
+}
+
+// pruneConfigBackups removes the oldest backups of a config file so that at
+// most retention backups are kept
+func pruneConfigBackups(configFilePath string, retention int, logger vlog.Printer) error {
+	versions, err := listConfigBackups(configFilePath)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(versions)-retention; i++ {
+		backupPath := getConfigBackupPrefix(configFilePath) + versions[i]
+		logger.Info("Removing old backup of configuration file", "backup file", backupPath)
+		backupStore, err := getConfigStore(backupPath)
 		if err != nil {
 			return err
 		}
+		err = backupStore.Remove()
+		if err != nil {
+			return fmt.Errorf("fail to remove old backup %s of configuration file, details: %w", backupPath, err)
+		}
 	}
-
 	return nil
 }
 
 // read reads information from configFilePath to a DatabaseConfig object.
-// It returns any read error encountered.
+// If the file has multiple databases, the one selected by --db-key or by the
+// database name is read. It returns any read error encountered.
 func readConfig() (dbConfig *DatabaseConfig, err error) {
 	configFilePath := dbOptions.ConfigPath
 
 	if configFilePath == "" {
 		return nil, fmt.Errorf("configuration file path is empty")
 	}
-	configBytes, err := os.ReadFile(configFilePath)
+	config, err := readConfigFile(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.getDatabase(configDBKey, dbOptions.DBName)
+}
+
+// readConfigFile reads all databases in a config file
+func readConfigFile(configFilePath string) (*Config, error) {
+	configBytes, err := readConfigStore(configFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("fail to read configuration file, details: %w", err)
 	}
 
-	var config Config
-	err = yaml.Unmarshal(configBytes, &config)
+	config, err := unmarshalConfig(configBytes, getConfigFormat(configFilePath))
 	if err != nil {
 		return nil, fmt.Errorf("fail to unmarshal configuration file, details: %w", err)
 	}
+	return config, nil
+}
+
+// getDatabase returns the database with the given key. Without a key, it returns
+// the database with the given name, or the only database in the config file.
+func (config *Config) getDatabase(key, dbName string) (*DatabaseConfig, error) {
+	if key != "" {
+		dbConfig, ok := config.Databases[key]
+		if !ok {
+			return nil, fmt.Errorf("database key %q is not found in the configuration file", key)
+		}
+		return dbConfig, nil
+	}
 
+	var dbConfigs []*DatabaseConfig
+	if config.Database.Name != "" {
+		dbConfigs = append(dbConfigs, &config.Database)
+	}
+	keys := maps.Keys(config.Databases)
+	sort.Strings(keys)
+	for _, k := range keys {
+		dbConfigs = append(dbConfigs, config.Databases[k])
+	}
+	for _, dbConfig := range dbConfigs {
+		if dbName != "" && dbConfig.Name == dbName {
+			return dbConfig, nil
+		}
+	}
+	if len(dbConfigs) > 1 {
+		return nil, fmt.Errorf("the configuration file has %d databases, use --%s to select one",
+			len(dbConfigs), dbKeyFlag)
+	}
+	if len(dbConfigs) == 1 {
+		return dbConfigs[0], nil
+	}
 	return &config.Database, nil
 }
 
-// write writes configuration information to configFilePath. It returns
-// any write error encountered. The viper in-built write function cannot
-// work well(the order of keys cannot be customized) so we used yaml.Marshal()
-// and os.WriteFile() to write the config file.
-func (c *DatabaseConfig) write(configFilePath string) error {
+// setDatabase adds or updates a database in the config file. With a key, the
+// database is saved under the key. Without a key, it replaces the database with
+// the same name in the databases section, or the top-level database.
+func (config *Config) setDatabase(key string, dbConfig *DatabaseConfig) {
+	if key == "" {
+		for k, existingDBConfig := range config.Databases {
+			if existingDBConfig.Name == dbConfig.Name {
+				config.Databases[k] = dbConfig
+				return
+			}
+		}
+		config.Database = *dbConfig
+		return
+	}
+	if config.Databases == nil {
+		config.Databases = make(map[string]*DatabaseConfig)
+	}
+	config.Databases[key] = dbConfig
+}
+
+// removeDatabase removes the database with the given key, or the database
+// with the given name if no key is given
+func (config *Config) removeDatabase(key, dbName string) {
+	if key != "" {
+		delete(config.Databases, key)
+		return
+	}
+	for k, dbConfig := range config.Databases {
+		if dbConfig.Name == dbName {
+			delete(config.Databases, k)
+			return
+		}
+	}
+	config.Database = DatabaseConfig{}
+}
+
+// isEmpty returns true if the config file has no databases
+func (config *Config) isEmpty() bool {
+	return config.Database.Name == "" && len(config.Databases) == 0
+}
+
+// getConfigFormat detects the format of a config file by its extension.
+// Files without a .json or .toml extension are read as yaml.
+func getConfigFormat(configFilePath string) string {
+	switch strings.ToLower(filepath.Ext(configFilePath)) {
+	case ".json":
+		return configFormatJSON
+	case ".toml":
+		return configFormatTOML
+	default:
+		return configFormatYAML
+	}
+}
+
+func unmarshalConfig(configBytes []byte, format string) (*Config, error) {
 	var config Config
+	var err error
+	switch format {
+	case configFormatJSON, configFormatTOML:
+		var flat flatConfig
+		if format == configFormatJSON {
+			err = json.Unmarshal(configBytes, &flat)
+		} else {
+			err = toml.Unmarshal(configBytes, &flat)
+		}
+		config.Version = flat.Version
+		config.BackupRetention = flat.BackupRetention
+		config.Database = DatabaseConfig{
+			Name:                    flat.Name,
+			Nodes:                   flat.Nodes,
+			IsEon:                   flat.IsEon,
+			CommunalStorageLocation: flat.CommunalStorageLocation,
+			Ipv6:                    flat.Ipv6,
+			EncryptedPassword:       flat.EncryptedPassword,
+		}
+		for key, flatDBConfig := range flat.Databases {
+			dbConfig := flatDBConfig.toDatabaseConfig()
+			config.setDatabase(key, &dbConfig)
+		}
+	default:
+		err = yaml.Unmarshal(configBytes, &config)
+	}
+	return &config, err
+}
+
+func marshalConfig(config *Config, format string) ([]byte, error) {
+	flat := flatConfig{
+		Version:                 config.Version,
+		BackupRetention:         config.BackupRetention,
+		Name:                    config.Database.Name,
+		Nodes:                   config.Database.Nodes,
+		IsEon:                   config.Database.IsEon,
+		CommunalStorageLocation: config.Database.CommunalStorageLocation,
+		Ipv6:                    config.Database.Ipv6,
+		EncryptedPassword:       config.Database.EncryptedPassword,
+	}
+	for key, dbConfig := range config.Databases {
+		if flat.Databases == nil {
+			flat.Databases = make(map[string]*flatDatabaseConfig)
+		}
+		flatDBConfig := dbConfig.toFlat()
+		flat.Databases[key] = &flatDBConfig
+	}
+	switch format {
+	case configFormatJSON:
+		return json.MarshalIndent(&flat, "", "  ")
+	case configFormatTOML:
+		return toml.Marshal(&flat)
+	default:
+		return yaml.Marshal(config)
+	}
+}
+
+func (c *DatabaseConfig) toFlat() flatDatabaseConfig {
+	return flatDatabaseConfig{
+		Name:                    c.Name,
+		Nodes:                   c.Nodes,
+		IsEon:                   c.IsEon,
+		CommunalStorageLocation: c.CommunalStorageLocation,
+		Ipv6:                    c.Ipv6,
+		EncryptedPassword:       c.EncryptedPassword,
+	}
+}
+
+func (c *flatDatabaseConfig) toDatabaseConfig() DatabaseConfig {
+	return DatabaseConfig{
+		Name:                    c.Name,
+		Nodes:                   c.Nodes,
+		IsEon:                   c.IsEon,
+		CommunalStorageLocation: c.CommunalStorageLocation,
+		Ipv6:                    c.Ipv6,
+		EncryptedPassword:       c.EncryptedPassword,
+	}
+}
+
+// write writes configuration information to configFilePath, in the format
+// given by its extension. The other databases in the config file are kept.
+// It returns any write error encountered.
+func (c *DatabaseConfig) write(configFilePath string) error {
+	config, err := readConfigFile(configFilePath)
+	if err != nil {
+		// start a new config file
+		config = &Config{}
+	}
+	// keep the stored password of the database, which is not part of the vdb
+	existingDBConfig, err := config.getDatabase(configDBKey, c.Name)
+	if err == nil && existingDBConfig.Name == c.Name && c.EncryptedPassword == "" {
+		c.EncryptedPassword = existingDBConfig.EncryptedPassword
+	}
+	config.setDatabase(configDBKey, c)
+	return config.write(configFilePath)
+}
+
+// write writes all databases to configFilePath. The viper in-built write
+// function cannot work well(the order of keys cannot be customized) so we
+// marshal the config ourselves and use os.WriteFile() to write the config file.
+func (config *Config) write(configFilePath string) error {
 	config.Version = currentConfigFileVersion
-	config.Database = *c
 
-	configBytes, err := yaml.Marshal(&config)
+	configBytes, err := marshalConfig(config, getConfigFormat(configFilePath))
 	if err != nil {
 		return fmt.Errorf("fail to marshal configuration data, details: %w", err)
 	}
-	err = os.WriteFile(configFilePath, configBytes, configFilePerm)
+	store, err := getConfigStore(configFilePath)
+	if err != nil {
+		return err
+	}
+	err = store.Write(configBytes)
 	if err != nil {
 		return fmt.Errorf("fail to write configuration file, details: %w", err)
 	}
@@ -332,6 +690,27 @@ func (c *DatabaseConfig) write(configFilePath string) error {
 	return nil
 }
 
+// diffNodes returns the names of the nodes in newConfig that are not in c,
+// and the names of the nodes in c that are not in newConfig
+func (c *DatabaseConfig) diffNodes(newConfig *DatabaseConfig) (added, removed []string) {
+	oldNodes := make(map[string]bool)
+	for _, n := range c.Nodes {
+		oldNodes[n.Name] = true
+	}
+	for _, n := range newConfig.Nodes {
+		if !oldNodes[n.Name] {
+			added = append(added, n.Name)
+		}
+		delete(oldNodes, n.Name)
+	}
+	for _, n := range c.Nodes {
+		if oldNodes[n.Name] {
+			removed = append(removed, n.Name)
+		}
+	}
+	return added, removed
+}
+
 // getHosts returns host addresses of all nodes in database
 func (c *DatabaseConfig) getHosts() []string {
 	var hostList []string
@@ -343,6 +722,18 @@ func (c *DatabaseConfig) getHosts() []string {
 	return hostList
 }
 
+// getSandboxHosts returns the hosts of a sandbox, or the hosts
+// of the main cluster if the sandbox is empty
+func (c *DatabaseConfig) getSandboxHosts(sandbox string) []string {
+	var hostList []string
+	for _, node := range c.Nodes {
+		if node.Sandbox == sandbox {
+			hostList = append(hostList, node.Address)
+		}
+	}
+	return hostList
+}
+
 // getPathPrefix returns catalog, data, and depot prefixes
 func (c *DatabaseConfig) getPathPrefixes() (catalogPrefix string,
 	dataPrefix string, depotPrefix string) {
@@ -352,3 +743,26 @@ func (c *DatabaseConfig) getPathPrefixes() (catalogPrefix string,
 
 	return c.Nodes[0].CatalogPath, c.Nodes[0].DataPath, c.Nodes[0].DepotPath
 }
+
+// getHostPathPrefixes returns the path prefixes of the nodes whose paths
+// differ from the ones of the first node
+func (c *DatabaseConfig) getHostPathPrefixes() map[string]vclusterops.HostPathPrefixes {
+	catalogPrefix, dataPrefix, depotPrefix := c.getPathPrefixes()
+	hostPathPrefixes := make(map[string]vclusterops.HostPathPrefixes)
+	for _, node := range c.Nodes {
+		prefixes := vclusterops.HostPathPrefixes{}
+		if node.CatalogPath != catalogPrefix {
+			prefixes.CatalogPrefix = node.CatalogPath
+		}
+		if node.DataPath != dataPrefix {
+			prefixes.DataPrefix = node.DataPath
+		}
+		if node.DepotPath != depotPrefix {
+			prefixes.DepotPrefix = node.DepotPath
+		}
+		if prefixes != (vclusterops.HostPathPrefixes{}) {
+			hostPathPrefixes[node.Address] = prefixes
+		}
+	}
+	return hostPathPrefixes
+}
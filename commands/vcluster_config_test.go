@@ -0,0 +1,222 @@
+/*
+ (c) Copyright [2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops"
+)
+
+func TestConfigFormats(t *testing.T) {
+	assert.Equal(t, configFormatYAML, getConfigFormat("/opt/vertica/config/vertica_cluster.yaml"))
+	assert.Equal(t, configFormatYAML, getConfigFormat("/opt/vertica/config/vertica_cluster"))
+	assert.Equal(t, configFormatJSON, getConfigFormat("/opt/vertica/config/vertica_cluster.JSON"))
+	assert.Equal(t, configFormatTOML, getConfigFormat("/opt/vertica/config/vertica_cluster.toml"))
+
+	dbConfig := MakeDatabaseConfig()
+	dbConfig.Name = "test_db"
+	dbConfig.IsEon = true
+	dbConfig.CommunalStorageLocation = "s3://bucket/test_db"
+	dbConfig.Nodes = []*NodeConfig{
+		{Name: "v_test_db_node0001", Address: "192.168.1.101", Subcluster: "default_subcluster",
+			CatalogPath: "/data", DataPath: "/data", DepotPath: "/depot"},
+	}
+
+	// the config is read back the same in each format
+	for _, ext := range []string{".yaml", ".json", ".toml"} {
+		configPath := filepath.Join(t.TempDir(), "vertica_cluster"+ext)
+		err := dbConfig.write(configPath)
+		assert.NoError(t, err)
+
+		dbOptions.ConfigPath = configPath
+		readDBConfig, err := readConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, &dbConfig, readDBConfig)
+	}
+	dbOptions.ConfigPath = ""
+}
+
+func TestConfigMultipleDatabases(t *testing.T) {
+	dbConfig1 := MakeDatabaseConfig()
+	dbConfig1.Name = "db1"
+	dbConfig1.Nodes = []*NodeConfig{{Name: "v_db1_node0001", Address: "192.168.1.101"}}
+	dbConfig2 := MakeDatabaseConfig()
+	dbConfig2.Name = "db2"
+	dbConfig2.IsEon = true
+	dbConfig2.Nodes = []*NodeConfig{{Name: "v_db2_node0001", Address: "192.168.1.102"}}
+
+	for _, ext := range []string{".yaml", ".json", ".toml"} {
+		configPath := filepath.Join(t.TempDir(), "vertica_cluster"+ext)
+		dbOptions.ConfigPath = configPath
+		// the first database is at the top level, the second one under its key
+		assert.NoError(t, dbConfig1.write(configPath))
+		configDBKey = "prod"
+		assert.NoError(t, dbConfig2.write(configPath))
+
+		readDBConfig, err := readConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, &dbConfig2, readDBConfig)
+
+		configDBKey = "dev"
+		_, err = readConfig()
+		assert.ErrorContains(t, err, `database key "dev" is not found`)
+
+		// without a key, the database is selected by name
+		configDBKey = ""
+		_, err = readConfig()
+		assert.ErrorContains(t, err, "the configuration file has 2 databases")
+		dbOptions.DBName = "db1"
+		readDBConfig, err = readConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, &dbConfig1, readDBConfig)
+
+		// removing a database keeps the others
+		config, err := readConfigFile(configPath)
+		assert.NoError(t, err)
+		config.removeDatabase("", "db2")
+		assert.False(t, config.isEmpty())
+		config.removeDatabase("", "db1")
+		assert.True(t, config.isEmpty())
+		dbOptions.DBName = ""
+	}
+	dbOptions.ConfigPath = ""
+}
+
+func TestConfigDiffNodes(t *testing.T) {
+	oldConfig := DatabaseConfig{Nodes: []*NodeConfig{{Name: "v_test_db_node0001"}, {Name: "v_test_db_node0002"}}}
+	newConfig := DatabaseConfig{Nodes: []*NodeConfig{{Name: "v_test_db_node0001"}, {Name: "v_test_db_node0003"}}}
+
+	added, removed := oldConfig.diffNodes(&newConfig)
+	assert.Equal(t, []string{"v_test_db_node0003"}, added)
+	assert.Equal(t, []string{"v_test_db_node0002"}, removed)
+
+	added, removed = newConfig.diffNodes(&newConfig)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
+
+func TestConfigHostPathPrefixes(t *testing.T) {
+	dbConfig := DatabaseConfig{Name: "test_db", Nodes: []*NodeConfig{
+		{Name: "v_test_db_node0001", Address: "192.168.1.101", CatalogPath: "/data", DataPath: "/data"},
+		{Name: "v_test_db_node0002", Address: "192.168.1.102", CatalogPath: "/data", DataPath: "/disk2"},
+	}}
+	assert.Equal(t, map[string]vclusterops.HostPathPrefixes{"192.168.1.102": {DataPrefix: "/disk2"}},
+		dbConfig.getHostPathPrefixes())
+
+	// the per-host paths are written back to the config file
+	vdb := vclusterops.VCoordinationDatabase{
+		Name:       "test_db",
+		HostList:   []string{"192.168.1.101", "192.168.1.102"},
+		DataPrefix: "/data",
+		HostNodeMap: map[string]*vclusterops.VCoordinationNode{
+			"192.168.1.101": {Name: "v_test_db_node0001", Address: "192.168.1.101"},
+			"192.168.1.102": {Name: "v_test_db_node0002", Address: "192.168.1.102"},
+		},
+		HostPathPrefixes: map[string]vclusterops.HostPathPrefixes{"192.168.1.102": {DataPrefix: "/disk2"}},
+	}
+	readConfig, err := readVDBToDBConfig(&vdb)
+	assert.NoError(t, err)
+	assert.Equal(t, "/data", readConfig.Nodes[0].DataPath)
+	assert.Equal(t, "/disk2", readConfig.Nodes[1].DataPath)
+}
+
+func TestConfigSandboxHosts(t *testing.T) {
+	dbConfig := DatabaseConfig{Name: "test_db", Nodes: []*NodeConfig{
+		{Name: "v_test_db_node0001", Address: "192.168.1.101", Subcluster: "sc1"},
+		{Name: "v_test_db_node0002", Address: "192.168.1.102", Subcluster: "sc2", Sandbox: "sand"},
+		{Name: "v_test_db_node0003", Address: "192.168.1.103", Subcluster: "sc2", Sandbox: "sand"},
+	}}
+	assert.Equal(t, []string{"192.168.1.102", "192.168.1.103"}, dbConfig.getSandboxHosts("sand"))
+	// the main cluster has no sandbox
+	assert.Equal(t, []string{"192.168.1.101"}, dbConfig.getSandboxHosts(""))
+	assert.Empty(t, dbConfig.getSandboxHosts("other"))
+}
+
+func TestConfigEncryptedPassword(t *testing.T) {
+	keyFilePath := filepath.Join(t.TempDir(), "vcluster", "password.key")
+	key, err := readOrCreatePasswordKey(keyFilePath)
+	assert.NoError(t, err)
+	// the key is read back from the key file
+	readKey, err := readOrCreatePasswordKey(keyFilePath)
+	assert.NoError(t, err)
+	assert.Equal(t, key, readKey)
+
+	encryptedPassword, err := encryptPassword("test_password", key)
+	assert.NoError(t, err)
+	assert.NotContains(t, encryptedPassword, "test_password")
+	password, err := decryptPassword(encryptedPassword, key)
+	assert.NoError(t, err)
+	assert.Equal(t, "test_password", password)
+
+	otherKey, err := readOrCreatePasswordKey(filepath.Join(t.TempDir(), "other.key"))
+	assert.NoError(t, err)
+	_, err = decryptPassword(encryptedPassword, otherKey)
+	assert.ErrorContains(t, err, "fail to decrypt the password")
+
+	// the stored password is read through the config file, and it is kept
+	// when the config file is rewritten
+	dbConfig := MakeDatabaseConfig()
+	dbConfig.Name = "test_db"
+	dbConfig.EncryptedPassword = encryptedPassword
+	configPath := filepath.Join(t.TempDir(), "vertica_cluster.yaml")
+	assert.NoError(t, dbConfig.write(configPath))
+	dbConfig.EncryptedPassword = ""
+	assert.NoError(t, dbConfig.write(configPath))
+
+	dbOptions.ConfigPath = configPath
+	passwordKeyFile = keyFilePath
+	password, ok, err := readPasswordFromConfig()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "test_password", password)
+	dbOptions.ConfigPath = ""
+	passwordKeyFile = ""
+}
+
+func TestValidateClusterConfig(t *testing.T) {
+	config := Config{Version: currentConfigFileVersion}
+	config.Database = DatabaseConfig{Name: "test_db", IsEon: true, Nodes: []*NodeConfig{
+		{Name: "v_test_db_node0001", Address: "192.168.1.101", Subcluster: "sc1", CatalogPath: "/data", DepotPath: "/depot"},
+		{Name: "v_test_db_node0001", Address: "192.168.1.101", Subcluster: "sc1", CatalogPath: "data", DepotPath: "/depot"},
+		{Name: "v_test_db_node0003", Address: "192.168.1.", Subcluster: "sc1", CatalogPath: "/data", Sandbox: "sand"},
+	}}
+	config.Databases = map[string]*DatabaseConfig{"prod": {Name: "prod_db"}}
+
+	violations := ValidateClusterConfig(&config)
+	assert.Equal(t, []ConfigViolation{
+		{Field: "communalStorageLocation", Message: "communal storage location is missing for an Eon database"},
+		{Field: "nodes[1].name", Message: "node v_test_db_node0001 is a duplicate of nodes[0]"},
+		{Field: "nodes[1].address", Message: "address 192.168.1.101 is a duplicate of nodes[0]"},
+		{Field: "nodes[1].catalogPath", Message: "data is not an absolute path"},
+		{Field: "nodes[2].address", Message: "192.168.1. is not a valid IPv4 address"},
+		{Field: "nodes[2].sandbox", Message: `node is in sandbox "sand" but nodes[0] of the same subcluster sc1 is in sandbox ""`},
+		{Field: "nodes[2].depotPath", Message: "path is missing"},
+		{Field: "databases.prod.nodes", Message: "database has no nodes"},
+	}, violations)
+
+	// unknown keys are reported
+	configPath := filepath.Join(t.TempDir(), "vertica_cluster.yaml")
+	err := os.WriteFile(configPath, []byte("configFileVersion: \"1.0\"\ndbName: test_db\nnodez: []\n"), configFilePerm)
+	assert.NoError(t, err)
+	violations, err = validateConfigFile(configPath)
+	assert.NoError(t, err)
+	assert.Len(t, violations, 1)
+	assert.Contains(t, violations[0].Message, "field nodez not found")
+}
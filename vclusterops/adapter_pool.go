@@ -22,6 +22,8 @@ import (
 	"time"
 
 	"github.com/theckman/yacspin"
+	"github.com/vertica/vcluster/vclusterops/metrics"
+	"github.com/vertica/vcluster/vclusterops/tracing"
 	"github.com/vertica/vcluster/vclusterops/vlog"
 )
 
@@ -60,9 +62,17 @@ func makeAdapterPool(logger vlog.Printer) adapterPool {
 type adapterToRequest struct {
 	adapter adapter
 	request hostHTTPRequest
+	host    string
 }
 
-func (pool *adapterPool) sendRequest(httpRequest *clusterHTTPRequest, spinner *yacspin.Spinner) error {
+func (pool *adapterPool) sendRequest(ctx context.Context, httpRequest *clusterHTTPRequest, spinner *yacspin.Spinner,
+	workload *opEngineWorkload) error {
+	// the instructions that poll the hosts send requests in a loop,
+	// so they stop here once the context is done
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("cannot send the requests of %s: %w", httpRequest.Name, err)
+	}
+
 	// build a collection of adapter to request
 	// we need this step as a host may not be in the pool
 	// in that case, we should not proceed
@@ -73,7 +83,7 @@ func (pool *adapterPool) sendRequest(httpRequest *clusterHTTPRequest, spinner *y
 		if !ok {
 			return fmt.Errorf("host %s is not found in the adapter pool", host)
 		}
-		ar := adapterToRequest{adapter: adpt, request: request}
+		ar := adapterToRequest{adapter: adpt, request: request, host: host}
 		adapterToRequestCollection = append(adapterToRequestCollection, ar)
 	}
 
@@ -91,22 +101,50 @@ func (pool *adapterPool) sendRequest(httpRequest *clusterHTTPRequest, spinner *y
 		defer cancelCtx()
 	}
 
+	start := time.Now()
+	spans := make(map[string]*tracing.Span, hostCount)
 	for i := 0; i < len(adapterToRequestCollection); i++ {
 		ar := adapterToRequestCollection[i]
 		// send request to the hosts
 		// each goroutine will handle one request for one host
 		request := ar.request
+		request.Traceparent = startRequestSpan(ctx, httpRequest.Name, ar.host, &request, spans)
+		request.CorrelationID = GetCorrelationID(ctx)
+		workload.recordRequest(ar.host, &request)
 		go ar.adapter.sendRequest(&request, resultChannel)
 	}
+	// the spans of the hosts that do not respond end with the error of the context
+	defer func() {
+		for _, span := range spans {
+			span.End(ctx.Err())
+		}
+	}()
 
 	// handle results
 	// we expect to receive the same number of results from the channel as the number of hosts
 	// before proceeding to the next steps
 	httpRequest.ResultCollection = make(map[string]hostHTTPResult)
 	for i := 0; i < hostCount; i++ {
-		result, ok := <-resultChannel
-		if ok {
-			httpRequest.ResultCollection[result.host] = result
+		select {
+		case result, ok := <-resultChannel:
+			if ok {
+				httpRequest.ResultCollection[result.host] = result
+				workload.recordResult(&result)
+				latency := time.Since(start)
+				metrics.ObserveRequest(httpRequest.Name, result.host, latency, !result.isPassing())
+				getTimingSummary(ctx).recordRequest(httpRequest.Name, result.host, latency)
+				pool.logger.Info("Request finished", "op", httpRequest.Name, "host", result.host,
+					"duration", latency, "statusCode", result.statusCode, "error", result.err,
+					correlationIDKey, GetCorrelationID(ctx))
+				if span, found := spans[result.host]; found {
+					span.SetAttribute(tracing.AttrHTTPStatusCode, result.statusCode)
+					span.End(result.err)
+				}
+			}
+		case <-ctx.Done():
+			// the result channel is not closed as the pending requests may
+			// still write to it. It is buffered so they will not block.
+			return fmt.Errorf("stop waiting for the responses of %s: %w", httpRequest.Name, ctx.Err())
 		}
 	}
 	close(resultChannel)
@@ -140,3 +178,18 @@ func progressCheck(ctx context.Context, name string, logger vlog.Printer, spinne
 		}
 	}
 }
+
+// startRequestSpan starts the span of the request to a host, and returns the
+// traceparent header that propagates it to the host
+func startRequestSpan(ctx context.Context, opName, host string, request *hostHTTPRequest,
+	spans map[string]*tracing.Span) string {
+	_, span := tracing.StartSpan(ctx, opName+" "+host)
+	if span == nil {
+		return ""
+	}
+	span.SetAttribute(tracing.AttrHost, host)
+	span.SetAttribute(tracing.AttrHTTPMethod, request.Method)
+	span.SetAttribute(tracing.AttrHTTPEndpoint, request.Endpoint)
+	spans[host] = span
+	return span.Traceparent()
+}
@@ -32,7 +32,8 @@ type VAddNodeOptions struct {
 	SCName string
 	// A primary up host that will be used to execute add_node operations
 	Initiator string
-	// Depot size, e.g., 10G
+	// Depot size of the new nodes, with two supported formats: % and KMGT, e.g., 50% or 10G.
+	// The default depot size of Vertica is used if it is empty.
 	DepotSize string
 	// Skip rebalance shards if true
 	SkipRebalanceShards *bool
@@ -46,6 +47,22 @@ type VAddNodeOptions struct {
 	// Names of the existing nodes in the cluster. This option can be
 	// used to remove partially added nodes from catalog.
 	ExpectedNodeNames []string
+	// Skip copying the UDx libraries of the database to the new nodes, and
+	// reinstalling the packages once the new nodes are up
+	SkipLibrarySync bool
+	// Start a rebalance of the cluster once the new nodes are up (Enterprise mode only)
+	RebalanceCluster bool
+	// Wait for the rebalance of the cluster to complete
+	WaitForRebalance bool
+	// Seconds to wait for the rebalance of the cluster, a negative value waits until it ends
+	RebalanceTimeout int
+	// Receives the percent complete of the rebalance while waiting for it, can be nil
+	ProgressReporter ProgressReporter
+	// The number of new nodes that are created together. If it is positive and there
+	// are more new nodes, the nodes are created in batches, and they are started once
+	// the last batch is created. The nodes of the batches that fail are trimmed from
+	// the catalog and reported by a PartialSuccessError. 0 adds all the nodes together.
+	NodeBatchSize int
 }
 
 func VAddNodeOptionsFactory() VAddNodeOptions {
@@ -60,16 +77,32 @@ func (o *VAddNodeOptions) setDefaultValues() {
 	o.DatabaseOptions.setDefaultValues()
 
 	o.SkipRebalanceShards = new(bool)
+	o.RebalanceTimeout = util.DefaultRebalanceTimeoutSeconds
 }
 
 func (o *VAddNodeOptions) validateEonOptions() error {
 	if o.DepotPrefix != "" {
-		return util.ValidateRequiredAbsPath(o.DepotPrefix, "depot path")
+		err := util.ValidateRequiredAbsPath(o.DepotPrefix, "depot path")
+		if err != nil {
+			return err
+		}
+	}
+	if o.DepotSize != "" {
+		validDepotSize, err := validateDepotSize(o.DepotSize)
+		if !validDepotSize {
+			return err
+		}
 	}
 	return nil
 }
 
 func (o *VAddNodeOptions) validateExtraOptions() error {
+	if o.WaitForRebalance && !o.RebalanceCluster {
+		return fmt.Errorf("cannot wait for the rebalance of the cluster without rebalancing it")
+	}
+	if o.NodeBatchSize < 0 {
+		return fmt.Errorf("node batch size cannot be negative")
+	}
 	// data prefix
 	if o.DataPrefix != "" {
 		return util.ValidateRequiredAbsPath(o.DataPrefix, "data path")
@@ -105,7 +138,11 @@ func (o *VAddNodeOptions) analyzeOptions() (err error) {
 		o.normalizePaths()
 	}
 
-	return nil
+	// the hosts in the config file can have path prefixes as well
+	allHosts := make([]string, 0, len(o.Hosts)+len(o.NewHosts))
+	allHosts = append(allHosts, o.Hosts...)
+	allHosts = append(allHosts, o.NewHosts...)
+	return o.analyzeHostPathPrefixes(allHosts)
 }
 
 func (o *VAddNodeOptions) validateAnalyzeOptions(logger vlog.Printer) error {
@@ -119,10 +156,12 @@ func (o *VAddNodeOptions) validateAnalyzeOptions(logger vlog.Printer) error {
 
 // VAddNode adds one or more nodes to an existing database.
 // It returns a VCoordinationDatabase that contains catalog information and any error encountered.
-func (vcc VClusterCommands) VAddNode(options *VAddNodeOptions) (VCoordinationDatabase, error) {
+func (vcc VClusterCommands) VAddNode(options *VAddNodeOptions) (_ VCoordinationDatabase, err error) {
+	defer vcc.startAudit("add_node", options)(&err)
+
 	vdb := makeVCoordinationDatabase()
 
-	err := options.validateAnalyzeOptions(vcc.Log)
+	err = options.validateAnalyzeOptions(vcc.Log)
 	if err != nil {
 		return vdb, err
 	}
@@ -143,6 +182,10 @@ func (vcc VClusterCommands) VAddNode(options *VAddNodeOptions) (VCoordinationDat
 		if e := options.validateEonOptions(); e != nil {
 			return vdb, e
 		}
+		if options.RebalanceCluster {
+			return vdb, fmt.Errorf("cannot rebalance the cluster of an Eon database, " +
+				"the shards of the subcluster are rebalanced instead")
+		}
 	}
 
 	err = options.setInitiator(vdb.PrimaryUpNodes)
@@ -169,6 +212,11 @@ func (vcc VClusterCommands) VAddNode(options *VAddNodeOptions) (VCoordinationDat
 		return vdb, err
 	}
 
+	if options.NodeBatchSize > 0 && len(options.NewHosts) > options.NodeBatchSize {
+		err = vcc.addNodesInBatches(&vdb, options)
+		return vdb, err
+	}
+
 	instructions, err := vcc.produceAddNodeInstructions(&vdb, options)
 	if err != nil {
 		return vdb, fmt.Errorf("fail to produce add node instructions, %w", err)
@@ -176,12 +224,137 @@ func (vcc VClusterCommands) VAddNode(options *VAddNodeOptions) (VCoordinationDat
 
 	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
-	if runError := clusterOpEngine.run(vcc.Log); runError != nil {
+	if runError := clusterOpEngine.run(vcc.getContext(), vcc.Log); runError != nil {
 		return vdb, fmt.Errorf("fail to complete add node operation, %w", runError)
 	}
 	return vdb, nil
 }
 
+// addNodesInBatches creates the new nodes in batches, and starts the nodes of the
+// batches that succeed once the last batch is created, so that they all start with
+// the spread.conf that has every new node. The nodes that the failed batches created
+// are trimmed from the catalog, and the failed hosts are removed from the vdb and
+// reported by a PartialSuccessError.
+func (vcc VClusterCommands) addNodesInBatches(vdb *VCoordinationDatabase, options *VAddNodeOptions) error {
+	instructions, err := vcc.produceAddNodePipelineInstructions(vdb, options)
+	if err != nil {
+		return fmt.Errorf("fail to produce add node instructions, %w", err)
+	}
+
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	// the failed hosts are only set once the pipeline has run
+	failedHosts := instructions[len(instructions)-1].(*pipelineOp).failedHosts
+	if len(failedHosts) > 0 {
+		err = vcc.trimFailedBatchNodes(vdb, options, failedHosts)
+		if err != nil {
+			return err
+		}
+	}
+	if runError != nil {
+		return fmt.Errorf("fail to complete add node operation, %w", runError)
+	}
+
+	startOptions := *options
+	startOptions.NewHosts = util.SliceDiff(options.NewHosts, failedHosts)
+	instructions, err = vcc.produceAddNodeStartInstructions(vdb, &startOptions,
+		false /*the libraries are synced by the batches*/)
+	if err != nil {
+		return fmt.Errorf("fail to produce add node instructions, %w", err)
+	}
+	clusterOpEngine = makeClusterOpEngine(instructions, &certs)
+	if runError := clusterOpEngine.run(vcc.getContext(), vcc.Log); runError != nil {
+		return fmt.Errorf("fail to complete add node operation, %w", runError)
+	}
+
+	if len(failedHosts) > 0 {
+		return &PartialSuccessError{
+			Detail: fmt.Sprintf("fail to add hosts %v, their nodes are trimmed from the catalog, the other hosts are added",
+				failedHosts),
+			Failed: failedHosts,
+		}
+	}
+	return nil
+}
+
+// trimFailedBatchNodes drops the nodes of the failed hosts from the catalog, as the
+// batch of a host may fail once its nodes are created, and removes the failed hosts
+// from the vdb
+func (vcc VClusterCommands) trimFailedBatchNodes(vdb *VCoordinationDatabase,
+	options *VAddNodeOptions, failedHosts []string) error {
+	catalogVDB := makeVCoordinationDatabase()
+	err := vcc.getVDBFromRunningDB(&catalogVDB, &options.DatabaseOptions)
+	if err != nil {
+		return fmt.Errorf("fail to find the nodes of the failed hosts %v in the catalog, %w", failedHosts, err)
+	}
+	nodesToTrim, err := getFailedBatchNodes(&catalogVDB, failedHosts)
+	if err != nil {
+		return err
+	}
+
+	if len(nodesToTrim) > 0 {
+		vcc.Log.PrintInfo("Trim nodes %+v of the failed hosts from catalog", nodesToTrim)
+		instructions, err := produceTrimFailedBatchNodesOps(vdb, options, nodesToTrim)
+		if err != nil {
+			return err
+		}
+		certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+		clusterOpEngine := makeClusterOpEngine(instructions, &certs)
+		err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+		if err != nil {
+			return fmt.Errorf("fail to trim nodes %v of the failed hosts %v from catalog, "+
+				"they can be trimmed by adding nodes with the expected node names, %w", nodesToTrim, failedHosts, err)
+		}
+	}
+
+	vdb.HostList = util.SliceDiff(vdb.HostList, failedHosts)
+	vdb.HostNodeMap = util.FilterMapByKey(vdb.HostNodeMap, vdb.HostList)
+	return nil
+}
+
+// getFailedBatchNodes returns the names of the nodes of the failed hosts that
+// are in the catalog. The hosts whose batch failed before their nodes were
+// created have no nodes.
+func getFailedBatchNodes(catalogVDB *VCoordinationDatabase, failedHosts []string) ([]string, error) {
+	var nodeNames []string
+	for _, host := range failedHosts {
+		vnode, ok := catalogVDB.HostNodeMap[host]
+		if !ok {
+			continue
+		}
+		// the nodes of the batches are started after the last batch
+		if vnode.State == util.NodeUpState {
+			return nil, fmt.Errorf("cannot trim the UP node %s (address %s)", vnode.Name, host)
+		}
+		nodeNames = append(nodeNames, vnode.Name)
+	}
+	return nodeNames, nil
+}
+
+// produceTrimFailedBatchNodesOps generates the instructions that drop the nodes of
+// the failed hosts from the catalog, and reload spread without them
+func produceTrimFailedBatchNodesOps(vdb *VCoordinationDatabase, options *VAddNodeOptions,
+	nodesToTrim []string) ([]clusterOp, error) {
+	var instructions []clusterOp
+	initiatorHost := []string{options.Initiator}
+	for _, nodeName := range nodesToTrim {
+		httpsDropNodeOp, err := makeHTTPSDropNodeOp(nodeName, initiatorHost,
+			options.usePassword, options.UserName, options.Password, vdb.IsEon)
+		if err != nil {
+			return instructions, err
+		}
+		instructions = append(instructions, &httpsDropNodeOp)
+	}
+	httpsReloadSpreadOp, err := makeHTTPSReloadSpreadOpWithInitiator(initiatorHost,
+		options.usePassword, options.UserName, options.Password)
+	if err != nil {
+		return instructions, err
+	}
+	instructions = append(instructions, &httpsReloadSpreadOp)
+	return instructions, nil
+}
+
 // checkAddNodeRequirements returns an error if at least one of the nodes
 // to add already exists in db.
 func checkAddNodeRequirements(vdb *VCoordinationDatabase, hostsToAdd []string) error {
@@ -198,15 +371,18 @@ func checkAddNodeRequirements(vdb *VCoordinationDatabase, hostsToAdd []string) e
 func (o *VAddNodeOptions) completeVDBSetting(vdb *VCoordinationDatabase) error {
 	vdb.DataPrefix = o.DataPrefix
 	vdb.DepotPrefix = o.DepotPrefix
+	// the depots of the new nodes are created with this size
+	vdb.DepotSize = o.DepotSize
+	vdb.HostPathPrefixes = o.HostPathPrefixes
 
 	hostNodeMap := makeVHostNodeMap()
 	// TODO: we set the depot and data path from /nodes rather than manually
 	// (VER-92725). This is useful for nmaDeleteDirectoriesOp.
 	for h, vnode := range vdb.HostNodeMap {
-		dataPath := vdb.genDataPath(vnode.Name)
+		dataPath := vdb.genDataPath(h, vnode.Name)
 		vnode.StorageLocations = append(vnode.StorageLocations, dataPath)
 		if vdb.DepotPrefix != "" {
-			vnode.DepotPath = vdb.genDepotPath(vnode.Name)
+			vnode.DepotPath = vdb.genDepotPath(h, vnode.Name)
 		}
 		hostNodeMap[h] = vnode
 	}
@@ -285,7 +461,7 @@ func (vcc VClusterCommands) trimNodesInCatalog(vdb *VCoordinationDatabase,
 
 	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
-	err := clusterOpEngine.run(vcc.Log)
+	err := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if err != nil {
 		vcc.Log.Error(err, "fail to trim nodes from catalog, %v")
 		return err
@@ -312,38 +488,27 @@ func (vcc VClusterCommands) trimNodesInCatalog(vdb *VCoordinationDatabase,
 //   - Create the new node
 //   - Reload spread
 //   - Transfer config files to the new node
+//   - Get the UDx libraries and copy their files to the new node
 //   - Start the new node
 //   - Poll node startup
 //   - Create depot on the new node (Eon mode only)
 //   - Sync catalog
 //   - Rebalance shards on subcluster (Eon mode only)
+//   - Reinstall the packages that have libraries, so that the new node loads them
+//   - Start a rebalance of the cluster, and wait for it (Enterprise mode only)
 func (vcc VClusterCommands) produceAddNodeInstructions(vdb *VCoordinationDatabase,
 	options *VAddNodeOptions) ([]clusterOp, error) {
-	var instructions []clusterOp
 	initiatorHost := []string{options.Initiator}
 	newHosts := options.NewHosts
-	allExistingHosts := util.SliceDiff(vdb.HostList, options.NewHosts)
 	username := options.UserName
 	usePassword := options.usePassword
 	password := options.Password
 
-	nmaHealthOp := makeNMAHealthOp(vdb.HostList)
-	instructions = append(instructions, &nmaHealthOp)
-
-	if vdb.IsEon {
-		httpsFindSubclusterOp, e := makeHTTPSFindSubclusterOp(
-			allExistingHosts, usePassword, username, password, options.SCName,
-			true /*ignore not found*/, AddNodeCmd)
-		if e != nil {
-			return instructions, e
-		}
-		instructions = append(instructions, &httpsFindSubclusterOp)
+	instructions, err := produceAddNodeCheckOps(vdb, options)
+	if err != nil {
+		return instructions, err
 	}
 
-	// require to have the same vertica version
-	nmaVerticaVersionOp := makeNMAVerticaVersionOpWithVDB(true /*hosts need to have the same Vertica version*/, vdb)
-	instructions = append(instructions, &nmaVerticaVersionOp)
-
 	// this is a copy of the original HostNodeMap that only
 	// contains the hosts to add.
 	newHostNodeMap := vdb.copyHostNodeMap(options.NewHosts)
@@ -353,26 +518,73 @@ func (vcc VClusterCommands) produceAddNodeInstructions(vdb *VCoordinationDatabas
 		return instructions, err
 	}
 	nmaNetworkProfileOp := makeNMANetworkProfileOp(vdb.HostList)
-	httpsCreateNodeOp, err := makeHTTPSCreateNodeOp(newHosts, initiatorHost,
-		usePassword, username, password, vdb, options.SCName)
-	if err != nil {
-		return instructions, err
+	instructions = append(instructions,
+		&nmaPrepareDirectoriesOp,
+		&nmaNetworkProfileOp,
+	)
+	// the nodes with different path prefixes are created separately
+	for _, newHostGroup := range vdb.groupHostsByPathPrefixes(newHosts) {
+		httpsCreateNodeOp, e := makeHTTPSCreateNodeOp(newHostGroup, initiatorHost,
+			usePassword, username, password, vdb, options.SCName)
+		if e != nil {
+			return instructions, e
+		}
+		instructions = append(instructions, &httpsCreateNodeOp)
 	}
 	httpsReloadSpreadOp, err := makeHTTPSReloadSpreadOpWithInitiator(initiatorHost, usePassword, username, password)
 	if err != nil {
 		return instructions, err
 	}
+	instructions = append(instructions, &httpsReloadSpreadOp)
+
+	startInstructions, err := vcc.produceAddNodeStartInstructions(vdb, options, !options.SkipLibrarySync)
+	instructions = append(instructions, startInstructions...)
+	return instructions, err
+}
+
+// produceAddNodeCheckOps generates the instructions that check the NMA connectivity,
+// the subcluster and the Vertica versions before the new nodes are created
+func produceAddNodeCheckOps(vdb *VCoordinationDatabase, options *VAddNodeOptions) ([]clusterOp, error) {
+	var instructions []clusterOp
+	allExistingHosts := util.SliceDiff(vdb.HostList, options.NewHosts)
+
+	nmaHealthOp := makeNMAHealthOp(vdb.HostList)
+	instructions = append(instructions, &nmaHealthOp)
+
+	if vdb.IsEon {
+		httpsFindSubclusterOp, e := makeHTTPSFindSubclusterOp(
+			allExistingHosts, options.usePassword, options.UserName, options.Password, options.SCName,
+			true /*ignore not found*/, AddNodeCmd)
+		if e != nil {
+			return instructions, e
+		}
+		instructions = append(instructions, &httpsFindSubclusterOp)
+	}
+
+	// require to have the same vertica version
+	nmaVerticaVersionOp := makeNMAVerticaVersionOpWithVDB(true /*hosts need to have the same Vertica version*/, vdb)
+	instructions = append(instructions, &nmaVerticaVersionOp)
+	return instructions, nil
+}
+
+// produceAddNodeStartInstructions generates the instructions that run once the new
+// nodes are created and spread is reloaded: transfer the config files to all the
+// nodes, copy the libraries to the new nodes if syncLibraries is set, start the
+// new nodes, create their depots, sync the catalog, and the post-start instructions
+func (vcc VClusterCommands) produceAddNodeStartInstructions(vdb *VCoordinationDatabase,
+	options *VAddNodeOptions, syncLibraries bool) ([]clusterOp, error) {
+	var instructions []clusterOp
+	initiatorHost := []string{options.Initiator}
+	newHosts := options.NewHosts
+	username := options.UserName
+	usePassword := options.usePassword
+	password := options.Password
+
 	httpsRestartUpCommandOp, err := makeHTTPSStartUpCommandOp(usePassword, username, password, vdb)
 	if err != nil {
 		return instructions, err
 	}
-	instructions = append(instructions,
-		&nmaPrepareDirectoriesOp,
-		&nmaNetworkProfileOp,
-		&httpsCreateNodeOp,
-		&httpsReloadSpreadOp,
-		&httpsRestartUpCommandOp,
-	)
+	instructions = append(instructions, &httpsRestartUpCommandOp)
 
 	// we will remove the nil parameters in VER-88401 by adding them in execContext
 	produceTransferConfigOps(&instructions,
@@ -380,6 +592,13 @@ func (vcc VClusterCommands) produceAddNodeInstructions(vdb *VCoordinationDatabas
 		vdb.HostList,
 		vdb /*db configurations retrieved from a running db*/)
 
+	if syncLibraries {
+		err = produceSyncLibrariesOps(&instructions, options, vdb)
+		if err != nil {
+			return instructions, err
+		}
+	}
+
 	nmaStartNewNodesOp := makeNMAStartNodeOpWithVDB(newHosts, options.StartUpConf, vdb)
 	httpsPollNodeStateOp, err := makeHTTPSPollNodeStateOp(newHosts, usePassword, username, password)
 	if err != nil {
@@ -390,8 +609,142 @@ func (vcc VClusterCommands) produceAddNodeInstructions(vdb *VCoordinationDatabas
 		&httpsPollNodeStateOp,
 	)
 
-	return vcc.prepareAdditionalEonInstructions(vdb, options, instructions,
+	instructions, err = vcc.prepareAdditionalEonInstructions(vdb, options, instructions,
 		username, usePassword, initiatorHost, newHosts)
+	if err != nil {
+		return instructions, err
+	}
+
+	err = produceAddNodePostStartOps(&instructions, options)
+	return instructions, err
+}
+
+// produceAddNodePipelineInstructions returns the instructions of add_node that create
+// the new nodes in batches. Each batch prepares the directories of its nodes, creates
+// them and reloads spread, and then copies the libraries to its nodes while the next
+// batches are created. The batches that fail do not fail the other batches. The pipeline
+// is the last instruction, and the nodes are started by the instructions that follow it.
+func (vcc VClusterCommands) produceAddNodePipelineInstructions(vdb *VCoordinationDatabase,
+	options *VAddNodeOptions) ([]clusterOp, error) {
+	instructions, err := produceAddNodeCheckOps(vdb, options)
+	if err != nil {
+		return instructions, err
+	}
+	nmaNetworkProfileOp := makeNMANetworkProfileOp(vdb.HostList)
+	instructions = append(instructions, &nmaNetworkProfileOp)
+
+	var batches []pipelineBatch
+	for _, newHostGroup := range vdb.groupHostsByPathPrefixes(options.NewHosts) {
+		for start := 0; start < len(newHostGroup); start += options.NodeBatchSize {
+			end := start + options.NodeBatchSize
+			if end > len(newHostGroup) {
+				end = len(newHostGroup)
+			}
+			batch, err := vcc.produceAddNodeBatch(vdb, options, newHostGroup[start:end])
+			if err != nil {
+				return instructions, err
+			}
+			batches = append(batches, batch)
+		}
+	}
+	pipelineOp := makePipelineOp("AddNodesPipelineOp",
+		fmt.Sprintf("Create %d nodes in %d batches", len(options.NewHosts), len(batches)), batches)
+	pipelineOp.allowPartialFailure = true
+	instructions = append(instructions, &pipelineOp)
+	return instructions, nil
+}
+
+// produceAddNodeBatch returns the instructions that prepare the directories of the
+// nodes of a batch, create the nodes from the initiator and reload spread, and then
+// copy the libraries to the nodes
+func (vcc VClusterCommands) produceAddNodeBatch(vdb *VCoordinationDatabase,
+	options *VAddNodeOptions, batchHosts []string) (pipelineBatch, error) {
+	batch := pipelineBatch{hosts: batchHosts}
+	initiatorHost := []string{options.Initiator}
+	usePassword := options.usePassword
+	username := options.UserName
+	password := options.Password
+
+	nmaPrepareDirectoriesOp, err := makeNMAPrepareDirectoriesOp(vdb.copyHostNodeMap(batchHosts),
+		options.ForceRemoval /*force cleanup*/, false /*for db revive*/)
+	if err != nil {
+		return batch, err
+	}
+	httpsCreateNodeOp, err := makeHTTPSCreateNodeOp(batchHosts, initiatorHost,
+		usePassword, username, password, vdb, options.SCName)
+	if err != nil {
+		return batch, err
+	}
+	httpsReloadSpreadOp, err := makeHTTPSReloadSpreadOpWithInitiator(initiatorHost, usePassword, username, password)
+	if err != nil {
+		return batch, err
+	}
+	batch.serial = append(batch.serial, &nmaPrepareDirectoriesOp, &httpsCreateNodeOp, &httpsReloadSpreadOp)
+
+	if !options.SkipLibrarySync {
+		batchOptions := *options
+		batchOptions.NewHosts = batchHosts
+		err = produceSyncLibrariesOps(&batch.concurrent, &batchOptions, vdb)
+		if err != nil {
+			return batch, err
+		}
+	}
+
+	return batch, nil
+}
+
+// produceAddNodePostStartOps generates the instructions that run once the new nodes
+// are up: reinstall the packages that have libraries, and rebalance the cluster
+func produceAddNodePostStartOps(instructions *[]clusterOp, options *VAddNodeOptions) error {
+	initiatorHost := []string{options.Initiator}
+	if !options.SkipLibrarySync {
+		// the packages are reinstalled with force, so that their libraries are loaded by the new nodes
+		httpsReinstallPackagesOp, err := makeHTTPSInstallPackagesOp(initiatorHost,
+			options.usePassword, options.UserName, options.Password,
+			true /*force reinstall*/, false /*verbose*/)
+		if err != nil {
+			return err
+		}
+		httpsReinstallPackagesOp.skipWithoutPackageLibraries = true
+		*instructions = append(*instructions, &httpsReinstallPackagesOp)
+	}
+
+	if !options.RebalanceCluster {
+		return nil
+	}
+	if options.WaitForRebalance {
+		return produceRebalanceClusterInBackgroundOps(instructions, initiatorHost,
+			options.usePassword, options.UserName, options.Password,
+			options.RebalanceTimeout, options.ProgressReporter)
+	}
+	httpsStartRebalanceClusterOp, err := makeHTTPSStartRebalanceClusterOp(initiatorHost,
+		options.usePassword, options.UserName, options.Password)
+	if err != nil {
+		return err
+	}
+	*instructions = append(*instructions, &httpsStartRebalanceClusterOp)
+	return nil
+}
+
+// produceSyncLibrariesOps generates the instructions that find the UDx libraries
+// from the initiator, and copy the library files to the catalog directories of
+// the new nodes before they start
+func produceSyncLibrariesOps(instructions *[]clusterOp, options *VAddNodeOptions,
+	vdb *VCoordinationDatabase) error {
+	httpsGetLibrariesOp, err := makeHTTPSGetLibrariesOp([]string{options.Initiator},
+		options.usePassword, options.UserName, options.Password)
+	if err != nil {
+		return err
+	}
+	nmaSyncLibrariesOp, err := makeNMASyncLibrariesOp(options.Initiator, options.NewHosts, vdb)
+	if err != nil {
+		return err
+	}
+	*instructions = append(*instructions,
+		&httpsGetLibrariesOp,
+		&nmaSyncLibrariesOp,
+	)
+	return nil
 }
 
 func (vcc VClusterCommands) prepareAdditionalEonInstructions(vdb *VCoordinationDatabase,
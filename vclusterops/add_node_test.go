@@ -0,0 +1,233 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestAddNodeDepotSize(t *testing.T) {
+	options := VAddNodeOptionsFactory()
+	options.DepotPrefix = "/depot"
+
+	options.DepotSize = "120%"
+	assert.ErrorContains(t, options.validateEonOptions(), "greater than 100%")
+	options.DepotSize = "10X"
+	assert.ErrorContains(t, options.validateEonOptions(), "not a well-formatted whole-number size")
+	options.DepotSize = "10G"
+	assert.NoError(t, options.validateEonOptions())
+
+	// the depots of the new nodes are created with the size of the options
+	vdb := makeVCoordinationDatabase()
+	vdb.DepotSize = "40%"
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.104"] = &VCoordinationNode{Name: "v_test_db_node0004"}
+	assert.NoError(t, options.completeVDBSetting(&vdb))
+	op, err := makeHTTPSCreateNodesDepotOp(&vdb, []string{"192.168.1.104"}, false, "", nil)
+	assert.NoError(t, err)
+	op.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, op.setupClusterHTTPRequest(op.hosts))
+	assert.Equal(t, "10G", op.clusterHTTPRequest.RequestCollection["192.168.1.104"].QueryParams["size"])
+}
+
+func TestAddNodeLibrarySync(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{CatalogPath: "/data/test_db/v_test_db_node0001_catalog/Catalog"}
+	vdb.HostNodeMap["192.168.1.104"] = &VCoordinationNode{CatalogPath: "/data/test_db/v_test_db_node0004_catalog"}
+	options := VAddNodeOptionsFactory()
+	options.Initiator = "192.168.1.101"
+	options.NewHosts = []string{"192.168.1.104"}
+
+	var instructions []clusterOp
+	assert.NoError(t, produceSyncLibrariesOps(&instructions, &options, &vdb))
+	assert.Len(t, instructions, 2)
+	syncOp := instructions[1].(*nmaSyncLibrariesOp)
+
+	// nothing is synced if the database has no libraries
+	execContext := makeOpEngineExecContext(vlog.Printer{})
+	assert.NoError(t, syncOp.prepare(&execContext))
+	assert.True(t, syncOp.isSkipExecute())
+
+	// the files are copied between the catalog directories of the nodes
+	execContext.userLibraries = []userLibrary{
+		{LibName: "MyFunctions", FilePath: "Libraries/0123/MyFunctions.so", MD5Sum: "9a4e"},
+	}
+	syncOp.skipExecute = false
+	syncOp.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, syncOp.prepare(&execContext))
+	assert.False(t, syncOp.isSkipExecute())
+	requestData := syncLibrariesRequestData{}
+	assert.NoError(t, json.Unmarshal([]byte(syncOp.clusterHTTPRequest.RequestCollection["192.168.1.104"].RequestData), &requestData))
+	assert.Equal(t, "192.168.1.101", requestData.SourceHost)
+	assert.Equal(t, "/data/test_db/v_test_db_node0001_catalog", requestData.SourceCatalogPath)
+	assert.Equal(t, "/data/test_db/v_test_db_node0004_catalog", requestData.CatalogPath)
+	assert.Equal(t, []syncLibraryFile{{FilePath: "Libraries/0123/MyFunctions.so", MD5Sum: "9a4e"}}, requestData.Libraries)
+
+	// a missing file fails the sync
+	syncOp.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.104": {status: SUCCESS, content: `{"synced_files": []}`},
+	}
+	assert.ErrorContains(t, syncOp.processResult(&execContext), "0 of 1 library files are synced")
+
+	// the packages are only reinstalled if some libraries are from packages
+	installOp, err := makeHTTPSInstallPackagesOp([]string{"192.168.1.101"}, false, "", nil, true, false)
+	assert.NoError(t, err)
+	installOp.skipWithoutPackageLibraries = true
+	assert.NoError(t, installOp.prepare(&execContext))
+	assert.True(t, installOp.isSkipExecute())
+	execContext.userLibraries[0].PackageName = "flextable"
+	installOp.skipExecute = false
+	installOp.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, installOp.prepare(&execContext))
+	assert.False(t, installOp.isSkipExecute())
+}
+
+func TestAddNodeRebalance(t *testing.T) {
+	options := VAddNodeOptionsFactory()
+	options.Initiator = "192.168.1.101"
+	options.WaitForRebalance = true
+	assert.ErrorContains(t, options.validateExtraOptions(), "without rebalancing it")
+	options.RebalanceCluster = true
+	assert.NoError(t, options.validateExtraOptions())
+
+	// the rebalance is started in the background, then polled
+	reporter := &mockProgressReporter{}
+	options.ProgressReporter = reporter
+	options.SkipLibrarySync = true
+	var instructions []clusterOp
+	assert.NoError(t, produceAddNodePostStartOps(&instructions, &options))
+	assert.Len(t, instructions, 2)
+	startOp := instructions[0].(*httpsRebalanceClusterOp)
+	assert.True(t, startOp.async)
+	startOp.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, startOp.setupClusterHTTPRequest(startOp.hosts))
+	assert.Equal(t, "true", startOp.clusterHTTPRequest.RequestCollection["192.168.1.101"].QueryParams["async"])
+	startOp.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, statusCode: SuccessCode, content: `{"detail": "REBALANCED"}`},
+	}
+	assert.ErrorContains(t, startOp.processResult(nil), "should be 'REBALANCE STARTED'")
+
+	// the percent complete is reported while the rebalance runs
+	pollOp := instructions[1].(*httpsPollRebalanceStatusOp)
+	done, err := pollOp.isRebalanceDone("192.168.1.101",
+		&rebalanceStatus{State: "running", PercentComplete: 25, ProjectionsDone: 10, ProjectionsTotal: 40}, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, ProgressEvent{Host: "192.168.1.101", Item: rebalanceProgressItem, Stage: ProgressStageRebalancing,
+		Percent: 25, Done: 10, Total: 40, ETA: 3 * time.Minute}, reporter.events[0])
+	done, err = pollOp.isRebalanceDone("192.168.1.101", &rebalanceStatus{State: "completed"}, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, ProgressStageDone, reporter.events[1].Stage)
+	assert.Equal(t, float64(100), reporter.events[1].Percent)
+	done, err = pollOp.isRebalanceDone("192.168.1.101", &rebalanceStatus{State: "failed", Detail: "out of disk space"}, time.Minute)
+	assert.ErrorContains(t, err, "out of disk space")
+	assert.True(t, done)
+
+	// without waiting, only the start of the rebalance is added
+	options.WaitForRebalance = false
+	instructions = nil
+	assert.NoError(t, produceAddNodePostStartOps(&instructions, &options))
+	assert.Len(t, instructions, 1)
+}
+
+func TestAddNodeBatches(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.Name = "test_db"
+	vdb.CatalogPrefix = "/data"
+	vdb.DataPrefix = "/data"
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostList = []string{"192.168.1.101"}
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Address: "192.168.1.101", Name: "v_test_db_node0001",
+		CatalogPath: "/data/test_db/v_test_db_node0001_catalog/Catalog", IsPrimary: true, State: util.NodeUpState}
+	options := VAddNodeOptionsFactory()
+	options.Initiator = "192.168.1.101"
+	options.UserName = "dbadmin"
+	options.NewHosts = []string{"192.168.1.102", "192.168.1.103", "192.168.1.104"}
+	assert.NoError(t, vdb.addHosts(options.NewHosts, ""))
+
+	// the hosts are added in one flow without a batch size
+	vcc := VClusterCommands{}
+	assert.NoError(t, options.validateExtraOptions())
+	instructions, err := vcc.produceAddNodeInstructions(&vdb, &options)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, findInstruction[*pipelineOp](instructions))
+
+	// the nodes are created in batches, and the pipeline is the last instruction
+	options.NodeBatchSize = 2
+	instructions, err = vcc.produceAddNodePipelineInstructions(&vdb, &options)
+	assert.NoError(t, err)
+	i := findInstruction[*pipelineOp](instructions)
+	assert.Equal(t, len(instructions)-1, i)
+	pipeline := instructions[i].(*pipelineOp)
+	assert.True(t, pipeline.allowPartialFailure)
+	assert.Len(t, pipeline.batches, 2)
+	assert.Equal(t, []string{"192.168.1.102", "192.168.1.103"}, pipeline.batches[0].hosts)
+	assert.Equal(t, []string{"192.168.1.104"}, pipeline.batches[1].hosts)
+	assert.Equal(t, 0, findInstruction[*nmaPrepareDirectoriesOp](pipeline.batches[0].serial))
+	assert.Equal(t, 2, findInstruction[*httpsReloadSpreadOp](pipeline.batches[0].serial))
+	assert.NotEqual(t, -1, findInstruction[*nmaSyncLibrariesOp](pipeline.batches[0].concurrent))
+	// the nodes are not started by the batches
+	assert.Equal(t, -1, findInstruction[*nmaStartNodeOp](pipeline.batches[0].concurrent))
+	assert.Equal(t, -1, findInstruction[*nmaStartNodeOp](instructions))
+
+	// the nodes of the batches that succeeded are started after the pipeline,
+	// and all the hosts get the config files
+	options.NewHosts = []string{"192.168.1.102", "192.168.1.103"}
+	instructions, err = vcc.produceAddNodeStartInstructions(&vdb, &options, false)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, findInstruction[*nmaSyncLibrariesOp](instructions))
+	i = findInstruction[*nmaStartNodeOp](instructions)
+	assert.NotEqual(t, -1, i)
+	assert.ElementsMatch(t, options.NewHosts, instructions[i].(*nmaStartNodeOp).hosts)
+	i = findInstruction[*nmaUploadConfigOp](instructions)
+	assert.NotEqual(t, -1, i)
+	assert.Equal(t, vdb.HostList, instructions[i].(*nmaUploadConfigOp).destHosts)
+
+	options.NodeBatchSize = -1
+	assert.ErrorContains(t, options.validateExtraOptions(), "node batch size cannot be negative")
+}
+
+func TestAddNodeFailedBatchNodes(t *testing.T) {
+	catalogVDB := makeVCoordinationDatabase()
+	catalogVDB.HostNodeMap = makeVHostNodeMap()
+	catalogVDB.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Name: "v_test_db_node0001", State: util.NodeUpState}
+	catalogVDB.HostNodeMap["192.168.1.104"] = &VCoordinationNode{Name: "v_test_db_node0004", State: util.NodeDownState}
+
+	// the hosts whose batch failed before their nodes were created are skipped
+	nodeNames, err := getFailedBatchNodes(&catalogVDB, []string{"192.168.1.104", "192.168.1.105"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v_test_db_node0004"}, nodeNames)
+
+	_, err = getFailedBatchNodes(&catalogVDB, []string{"192.168.1.101"})
+	assert.ErrorContains(t, err, "cannot trim the UP node")
+
+	options := VAddNodeOptionsFactory()
+	options.Initiator = "192.168.1.101"
+	options.UserName = "dbadmin"
+	instructions, err := produceTrimFailedBatchNodesOps(&catalogVDB, &options, nodeNames)
+	assert.NoError(t, err)
+	assert.Len(t, instructions, 2)
+	assert.Equal(t, 0, findInstruction[*httpsDropNodeOp](instructions))
+	assert.Equal(t, 1, findInstruction[*httpsReloadSpreadOp](instructions))
+}
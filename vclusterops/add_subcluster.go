@@ -16,6 +16,7 @@
 package vclusterops
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/vertica/vcluster/vclusterops/util"
@@ -32,12 +33,22 @@ type VAddSubclusterOptions struct {
 	// part 1: basic db info
 	DatabaseOptions
 	// part 2: subcluster info
-	SCName         string
-	SCHosts        []string
-	SCRawHosts     []string
-	IsPrimary      bool
+	SCName string
+	// Hosts to add to the new subcluster. When set, the nodes are added and
+	// the shards are rebalanced in the same call, and the subcluster is removed
+	// again if the nodes cannot be added.
+	SCHosts    []string
+	SCRawHosts []string
+	// whether the new subcluster is primary, it is secondary by default
+	IsPrimary bool
+	// the number of nodes of the subcluster that run spread, -1 for all of them
 	ControlSetSize int
 	CloneSC        string
+	// The connection load balancing group that is created with the nodes of
+	// the new subcluster, none if it is empty
+	LoadBalanceGroup string
+	// the policy to pick a node of the load balancing group, defaults to ROUNDROBIN
+	LoadBalancePolicy string
 	// part 3: add node info
 	VAddNodeOptions
 }
@@ -68,6 +79,7 @@ func (options *VAddSubclusterOptions) setDefaultValues() {
 	options.DatabaseOptions.setDefaultValues()
 
 	options.ControlSetSize = util.DefaultControlSetSize
+	options.LoadBalancePolicy = LoadBalancePolicyRoundRobin
 }
 
 func (options *VAddSubclusterOptions) validateRequiredOptions(logger vlog.Printer) error {
@@ -97,6 +109,15 @@ func (options *VAddSubclusterOptions) validateExtraOptions(logger vlog.Printer)
 			ControlSetSizeDefaultValue, ControlSetSizeLowerBound, ControlSetSizeUpperBound)
 	}
 
+	if options.LoadBalanceGroup != "" {
+		if err := util.ValidateName(options.LoadBalanceGroup, "load balancing group"); err != nil {
+			return err
+		}
+		if err := validateLoadBalancePolicy(options.LoadBalancePolicy); err != nil {
+			return err
+		}
+	}
+
 	if options.CloneSC != "" {
 		// TODO remove this log after we supported subcluster clone
 		logger.PrintWarning("option CloneSC is not implemented yet so it will be ignored")
@@ -117,9 +138,6 @@ func (options *VAddSubclusterOptions) validateExtraOptions(logger vlog.Printer)
 		if len(dupHosts) > 0 {
 			return fmt.Errorf("new subcluster has hosts %v which already exist in database %s", dupHosts, options.DBName)
 		}
-
-		// TODO remove this log after we supported adding subcluster with nodes
-		logger.PrintWarning("options SCRawHosts and SCHosts are not implemented yet so they will be ignored")
 	}
 
 	return nil
@@ -178,23 +196,38 @@ func (options *VAddSubclusterOptions) validateAnalyzeOptions(vcc VClusterCommand
 }
 
 // VAddSubcluster adds to a running database a new subcluster with provided options.
-// It returns any error encountered.
+// If SCHosts is set, the nodes are added to the new subcluster in the same call and
+// the subcluster is removed if any node cannot be added. If LoadBalanceGroup is set,
+// the load balancing group of the subcluster is created with it, so that the nodes
+// added to the subcluster later join the group.
 func (vcc VClusterCommands) VAddSubcluster(options *VAddSubclusterOptions) error {
+	_, err := vcc.VAddSubclusterWithVDB(options)
+	return err
+}
+
+// VAddSubclusterWithVDB adds a new subcluster like VAddSubcluster. It returns a
+// VCoordinationDatabase that contains catalog information, with the nodes added
+// to the subcluster if SCHosts is set, and any error encountered.
+func (vcc VClusterCommands) VAddSubclusterWithVDB(options *VAddSubclusterOptions) (_ VCoordinationDatabase, err error) {
+	defer vcc.startAudit("add_subcluster", options)(&err)
+
 	/*
 	 *   - Produce Instructions
 	 *   - Create a VClusterOpEngine
 	 *   - Give the instructions to the VClusterOpEngine to run
+	 *   - Add the nodes to the new subcluster, if any
 	 */
+	vdb := makeVCoordinationDatabase()
 
 	// validate and analyze all options
-	err := options.validateAnalyzeOptions(vcc)
+	err = options.validateAnalyzeOptions(vcc)
 	if err != nil {
-		return err
+		return vdb, err
 	}
 
-	instructions, err := vcc.produceAddSubclusterInstructions(options)
+	instructions, err := vcc.produceAddSubclusterInstructions(&vdb, options)
 	if err != nil {
-		return fmt.Errorf("fail to produce instructions, %w", err)
+		return vdb, fmt.Errorf("fail to produce instructions, %w", err)
 	}
 
 	// Create a VClusterOpEngine, and add certs to the engine
@@ -202,12 +235,64 @@ func (vcc VClusterCommands) VAddSubcluster(options *VAddSubclusterOptions) error
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// Give the instructions to the VClusterOpEngine to run
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if runError != nil {
-		return fmt.Errorf("fail to add subcluster %s, %w", options.SCName, runError)
+		return vdb, fmt.Errorf("fail to add subcluster %s, %w", options.SCName, runError)
 	}
 
-	return nil
+	if len(options.SCHosts) == 0 {
+		return vdb, nil
+	}
+
+	return addNodesToNewSubcluster(vcc, options)
+}
+
+// addNodesToNewSubcluster adds the SCHosts to the subcluster that was just created.
+// If the nodes cannot be added, it removes the subcluster with the nodes that were
+// partially added, so the database is left as it was before VAddSubcluster.
+func addNodesToNewSubcluster(vcc ClusterCommands, options *VAddSubclusterOptions) (VCoordinationDatabase, error) {
+	vcc.PrintInfo("Adding hosts %v to subcluster %s", options.SCHosts, options.SCName)
+
+	addNodeOpt := options.VAddNodeOptions
+	addNodeOpt.DatabaseOptions = options.DatabaseOptions
+	addNodeOpt.NewHosts = options.SCHosts
+	addNodeOpt.SCName = options.SCName
+
+	vdb, addNodeErr := vcc.VAddNode(&addNodeOpt)
+	if addNodeErr == nil {
+		return vdb, nil
+	}
+
+	vcc.PrintWarning("fail to add hosts to subcluster %s, removing the subcluster", options.SCName)
+	clearNewSubclusterLoadBalanceGroup(vcc, options)
+	removeScOpt := VRemoveScOptionsFactory()
+	removeScOpt.DatabaseOptions = options.DatabaseOptions
+	removeScOpt.SubclusterToRemove = options.SCName
+	removeScOpt.ForceDelete = true
+	vdb, removeScErr := vcc.VRemoveSubcluster(&removeScOpt)
+	if removeScErr != nil {
+		return vdb, errors.Join(addNodeErr,
+			fmt.Errorf("fail to roll back subcluster %s, please remove it with db_remove_subcluster, %w",
+				options.SCName, removeScErr))
+	}
+
+	return vdb, fmt.Errorf("fail to add hosts to subcluster %s, the subcluster has been removed, %w",
+		options.SCName, addNodeErr)
+}
+
+// clearNewSubclusterLoadBalanceGroup drops the load balancing group that was created
+// with a subcluster that is rolled back. The group is only logged if it cannot be
+// dropped, as it does not keep the subcluster from being removed.
+func clearNewSubclusterLoadBalanceGroup(vcc ClusterCommands, options *VAddSubclusterOptions) {
+	if options.LoadBalanceGroup == "" {
+		return
+	}
+	clearOpt := VClearLoadBalanceGroupOptionsFactory()
+	clearOpt.DatabaseOptions = options.DatabaseOptions
+	clearOpt.GroupName = options.LoadBalanceGroup
+	if err := vcc.VClearLoadBalanceGroup(&clearOpt); err != nil {
+		vcc.PrintWarning("fail to drop load balancing group %s, details: %s", options.LoadBalanceGroup, err)
+	}
 }
 
 // produceAddSubclusterInstructions will build a list of instructions to execute for
@@ -221,13 +306,13 @@ func (vcc VClusterCommands) VAddSubcluster(options *VAddSubclusterOptions) error
 //   - Add the subcluster catalog object through HTTPS call, and check the response to error out
 //     if the subcluster name already exists
 //   - Check if the new subcluster is created in database through HTTPS call
-//   - TODO: add new nodes to the subcluster
-func (vcc *VClusterCommands) produceAddSubclusterInstructions(options *VAddSubclusterOptions) ([]clusterOp, error) {
+//   - Create the load balancing group of the new subcluster, if any
+func (vcc *VClusterCommands) produceAddSubclusterInstructions(vdb *VCoordinationDatabase,
+	options *VAddSubclusterOptions) ([]clusterOp, error) {
 	var instructions []clusterOp
-	vdb := makeVCoordinationDatabase()
 
 	// get cluster info
-	err := vcc.getClusterInfoFromRunningDB(&vdb, &options.DatabaseOptions)
+	err := vcc.getClusterInfoFromRunningDB(vdb, &options.DatabaseOptions)
 	if err != nil {
 		return instructions, err
 	}
@@ -263,5 +348,22 @@ func (vcc *VClusterCommands) produceAddSubclusterInstructions(options *VAddSubcl
 		&httpsCheckSubclusterOp,
 	)
 
-	return instructions, nil
+	err = produceSubclusterLoadBalanceGroupOps(&instructions, options)
+	return instructions, err
+}
+
+// produceSubclusterLoadBalanceGroupOps creates the load balancing group of the new
+// subcluster after the subcluster is checked, so no follow-up call is needed to
+// route the connections to it
+func produceSubclusterLoadBalanceGroupOps(instructions *[]clusterOp, options *VAddSubclusterOptions) error {
+	if options.LoadBalanceGroup == "" {
+		return nil
+	}
+	httpsSetLoadBalanceGroupOp, err := makeHTTPSSetLoadBalanceGroupOp(options.usePassword, options.UserName,
+		options.Password, options.LoadBalanceGroup, options.SCName, options.LoadBalancePolicy)
+	if err != nil {
+		return err
+	}
+	*instructions = append(*instructions, &httpsSetLoadBalanceGroupOp)
+	return nil
 }
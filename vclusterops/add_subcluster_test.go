@@ -0,0 +1,122 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestAddSubclusterLoadBalanceGroup(t *testing.T) {
+	options := VAddSubclusterOptionsFactory()
+	options.SCName = "sc1"
+	options.ControlSetSize = 3
+	options.LoadBalanceGroup = "sc1_group"
+	assert.Equal(t, LoadBalancePolicyRoundRobin, options.LoadBalancePolicy)
+	assert.NoError(t, options.validateExtraOptions(vlog.Printer{}))
+
+	options.LoadBalancePolicy = "LEASTBUSY"
+	err := options.validateExtraOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, `invalid load balancing policy "LEASTBUSY"`)
+
+	// the policy is not checked without a group
+	options.LoadBalanceGroup = ""
+	assert.NoError(t, options.validateExtraOptions(vlog.Printer{}))
+	var instructions []clusterOp
+	assert.NoError(t, produceSubclusterLoadBalanceGroupOps(&instructions, &options))
+	assert.Empty(t, instructions)
+
+	// the group is created with the nodes of the new subcluster
+	options.LoadBalanceGroup = "sc1_group"
+	options.LoadBalancePolicy = LoadBalancePolicyRandom
+	assert.NoError(t, produceSubclusterLoadBalanceGroupOps(&instructions, &options))
+	i := findInstruction[*httpsLoadBalanceGroupOp](instructions)
+	assert.Equal(t, 0, i)
+	op := instructions[i].(*httpsLoadBalanceGroupOp)
+	assert.Equal(t, "sc1_group", op.groupName)
+	assert.Equal(t, PostMethod, op.method)
+	assert.Equal(t, map[string]string{"subcluster": "sc1", "policy": LoadBalancePolicyRandom}, op.requestParams)
+}
+
+// mockSubclusterCommands records the nodes added and the subclusters removed
+// by addNodesToNewSubcluster, and fails the calls that are set to fail
+type mockSubclusterCommands struct {
+	ClusterCommands
+	addNodeErr      error
+	removeScErr     error
+	addNodeOptions  *VAddNodeOptions
+	removeScOptions *VRemoveScOptions
+	clearedGroup    string
+}
+
+func (*mockSubclusterCommands) PrintInfo(string, ...any)    {}
+func (*mockSubclusterCommands) PrintWarning(string, ...any) {}
+
+func (m *mockSubclusterCommands) VAddNode(options *VAddNodeOptions) (VCoordinationDatabase, error) {
+	m.addNodeOptions = options
+	vdb := makeVCoordinationDatabase()
+	vdb.HostList = options.NewHosts
+	return vdb, m.addNodeErr
+}
+
+func (m *mockSubclusterCommands) VRemoveSubcluster(options *VRemoveScOptions) (VCoordinationDatabase, error) {
+	m.removeScOptions = options
+	return makeVCoordinationDatabase(), m.removeScErr
+}
+
+func (m *mockSubclusterCommands) VClearLoadBalanceGroup(options *VClearLoadBalanceGroupOptions) error {
+	m.clearedGroup = options.GroupName
+	return nil
+}
+
+func TestAddNodesToNewSubcluster(t *testing.T) {
+	options := VAddSubclusterOptionsFactory()
+	options.DBName = "test_db"
+	options.SCName = "sc1"
+	options.SCHosts = []string{"192.168.1.104", "192.168.1.105"}
+	options.LoadBalanceGroup = "sc1_group"
+
+	// the hosts are added to the new subcluster
+	vcc := &mockSubclusterCommands{}
+	vdb, err := addNodesToNewSubcluster(vcc, &options)
+	assert.NoError(t, err)
+	assert.Equal(t, options.SCHosts, vdb.HostList)
+	assert.Equal(t, options.SCHosts, vcc.addNodeOptions.NewHosts)
+	assert.Equal(t, "sc1", vcc.addNodeOptions.SCName)
+	assert.Equal(t, "test_db", vcc.addNodeOptions.DBName)
+	assert.Nil(t, vcc.removeScOptions)
+	assert.Empty(t, vcc.clearedGroup)
+
+	// the subcluster and its group are removed if the hosts cannot be added
+	vcc = &mockSubclusterCommands{addNodeErr: errors.New("fail to add node")}
+	_, err = addNodesToNewSubcluster(vcc, &options)
+	assert.ErrorContains(t, err, "fail to add hosts to subcluster sc1, the subcluster has been removed")
+	assert.ErrorContains(t, err, "fail to add node")
+	assert.Equal(t, "sc1", vcc.removeScOptions.SubclusterToRemove)
+	assert.True(t, vcc.removeScOptions.ForceDelete)
+	assert.Equal(t, "sc1_group", vcc.clearedGroup)
+
+	// the user is told to remove the subcluster if it cannot be removed
+	vcc = &mockSubclusterCommands{addNodeErr: errors.New("fail to add node"),
+		removeScErr: errors.New("fail to remove subcluster")}
+	_, err = addNodesToNewSubcluster(vcc, &options)
+	assert.ErrorContains(t, err, "fail to add node")
+	assert.ErrorContains(t, err, "fail to roll back subcluster sc1, please remove it with db_remove_subcluster")
+	assert.ErrorContains(t, err, "fail to remove subcluster")
+}
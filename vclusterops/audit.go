@@ -0,0 +1,237 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"os"
+	"os/user"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	auditResultSuccess = "success"
+	auditResultFailure = "failure"
+	auditMaskedValue   = "******"
+	auditFilePerm      = 0600
+)
+
+// the substrings of the option names whose values are masked in the audit records
+var auditSecretNames = []string{"password", "secret", "token", "auth", "credential", "key", "cert"}
+
+// AuditRecord is the record of an administrative command
+type AuditRecord struct {
+	Command   string    `json:"command"`
+	User      string    `json:"user"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	// success or failure
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+	// the hosts given to the command
+	Hosts []string `json:"hosts,omitempty"`
+	// the options of the command, with the secrets masked
+	Arguments map[string]any `json:"arguments"`
+}
+
+// AuditLogger receives the record of each administrative command that
+// VClusterCommands runs, once the command ends
+type AuditLogger interface {
+	WriteAuditRecord(record *AuditRecord) error
+}
+
+// AuditFileLogger appends the audit records to a file, one JSON object per line
+type AuditFileLogger struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewAuditFileLogger(path string) *AuditFileLogger {
+	return &AuditFileLogger{path: path}
+}
+
+// WriteAuditRecord opens the file for each record, so that the file is only
+// appended to and is not kept open between the commands
+func (l *AuditFileLogger) WriteAuditRecord(record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, auditFilePerm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// AuditSyslogLogger sends the audit records to the local syslog daemon
+type AuditSyslogLogger struct {
+	tag string
+}
+
+func NewAuditSyslogLogger(tag string) *AuditSyslogLogger {
+	return &AuditSyslogLogger{tag: tag}
+}
+
+func (l *AuditSyslogLogger) WriteAuditRecord(record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	writer, err := syslog.New(syslog.LOG_NOTICE|syslog.LOG_AUTHPRIV, l.tag)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+	return writer.Notice(string(data))
+}
+
+// startAudit starts the audit record of a command, and returns the function
+// that writes the record with the error of the command. It is deferred at the
+// start of each administrative command:
+//
+//	defer vcc.startAudit("stop_db", options)(&err)
+func (vcc VClusterCommands) startAudit(command string, options any) func(err *error) {
+	if vcc.AuditLogger == nil {
+		return func(*error) {}
+	}
+	record := AuditRecord{
+		Command:   command,
+		User:      getAuditUser(),
+		StartTime: time.Now(),
+	}
+	return func(err *error) {
+		record.EndTime = time.Now()
+		record.Result = auditResultSuccess
+		if err != nil && *err != nil {
+			record.Result = auditResultFailure
+			record.Error = (*err).Error()
+		}
+		// the options are read at the end, when the hosts have been resolved
+		record.Hosts = getAuditHosts(options)
+		if arguments, ok := getAuditArguments(reflect.ValueOf(options)).(map[string]any); ok {
+			record.Arguments = arguments
+		}
+		if e := vcc.AuditLogger.WriteAuditRecord(&record); e != nil {
+			vcc.Log.PrintWarning("fail to write the audit record of %s: %s", command, e)
+		}
+	}
+}
+
+func getAuditUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+func getAuditHosts(options any) []string {
+	opt, ok := options.(interface{ getDatabaseOptions() *DatabaseOptions })
+	if !ok {
+		return nil
+	}
+	if hosts := opt.getDatabaseOptions().Hosts; len(hosts) > 0 {
+		return hosts
+	}
+	return opt.getDatabaseOptions().RawHosts
+}
+
+// getDatabaseOptions lets the audit find the hosts of any options that embed DatabaseOptions
+func (opt *DatabaseOptions) getDatabaseOptions() *DatabaseOptions {
+	return opt
+}
+
+// getAuditArguments converts the options to the values of an audit record. The
+// values of the secrets are masked, and the fields that cannot be recorded,
+// such as functions, are skipped.
+func getAuditArguments(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return getAuditArguments(v.Elem())
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			return t
+		}
+		fields := make(map[string]any)
+		addAuditFields(v, fields)
+		return fields
+	case reflect.Map:
+		entries := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			entries[key] = maskAuditValue(key, getAuditArguments(iter.Value()))
+		}
+		return entries
+	case reflect.Slice, reflect.Array:
+		items := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, getAuditArguments(v.Index(i)))
+		}
+		return items
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Invalid:
+		return nil
+	}
+	return v.Interface()
+}
+
+// addAuditFields adds the exported fields of a struct, and the fields of the
+// structs that it embeds, to the fields of an audit record
+func addAuditFields(v reflect.Value, fields map[string]any) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		kind := field.Type.Kind()
+		if kind == reflect.Func || kind == reflect.Chan {
+			continue
+		}
+		if field.Anonymous && kind == reflect.Struct {
+			addAuditFields(v.Field(i), fields)
+			continue
+		}
+		fields[field.Name] = maskAuditValue(field.Name, getAuditArguments(v.Field(i)))
+	}
+}
+
+// maskAuditValue masks the value of a secret, found by its name
+func maskAuditValue(name string, value any) any {
+	if _, isBool := value.(bool); isBool || value == nil || value == "" {
+		return value
+	}
+	lowerName := strings.ToLower(name)
+	for _, secretName := range auditSecretNames {
+		if strings.Contains(lowerName, secretName) {
+			return auditMaskedValue
+		}
+	}
+	return value
+}
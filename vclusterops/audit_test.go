@@ -0,0 +1,80 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAuditLogger struct {
+	records []*AuditRecord
+}
+
+func (l *testAuditLogger) WriteAuditRecord(record *AuditRecord) error {
+	l.records = append(l.records, record)
+	return nil
+}
+
+func TestAudit(t *testing.T) {
+	auditLogger := &testAuditLogger{}
+	vcc := VClusterCommands{AuditLogger: auditLogger}
+
+	options := VStopDatabaseOptionsFactory()
+	options.RawHosts = []string{"192.168.1.101", "192.168.1.102"}
+	password := "secret"
+	options.Password = &password
+	options.Key = "/opt/vertica/config/https_certs/key.pem"
+	options.ConfigurationParameters = map[string]string{"awsauth": "id:secret", "awsregion": "us-east-1"}
+
+	// the command fails without a database name, and the failure is recorded
+	err := vcc.VStopDatabase(&options)
+	assert.Error(t, err)
+	assert.Len(t, auditLogger.records, 1)
+	record := auditLogger.records[0]
+	assert.Equal(t, "stop_db", record.Command)
+	assert.Equal(t, auditResultFailure, record.Result)
+	assert.Equal(t, err.Error(), record.Error)
+	assert.Equal(t, options.RawHosts, record.Hosts)
+	assert.False(t, record.EndTime.Before(record.StartTime))
+
+	// the secrets are masked, and the other options are kept
+	assert.Equal(t, auditMaskedValue, record.Arguments["Password"])
+	assert.Equal(t, auditMaskedValue, record.Arguments["Key"])
+	configParams := record.Arguments["ConfigurationParameters"].(map[string]any)
+	assert.Equal(t, auditMaskedValue, configParams["awsauth"])
+	assert.Equal(t, "us-east-1", configParams["awsregion"])
+	assert.Equal(t, false, record.Arguments["IsEon"])
+
+	// the file logger appends one line per record
+	auditFile := filepath.Join(t.TempDir(), "vcluster_audit.log")
+	fileLogger := NewAuditFileLogger(auditFile)
+	assert.NoError(t, fileLogger.WriteAuditRecord(record))
+	assert.NoError(t, fileLogger.WriteAuditRecord(record))
+	data, err := os.ReadFile(auditFile)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2)
+	var written AuditRecord
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &written))
+	assert.Equal(t, "stop_db", written.Command)
+	assert.NotContains(t, lines[1], password)
+}
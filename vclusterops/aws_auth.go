@@ -0,0 +1,133 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+// AWSAuthOptions are the ways to authenticate to an S3 communal storage,
+// besides the static key pair of the awsauth configuration parameter
+type AWSAuthOptions struct {
+	// whether get AWS credentials from environmental variables: the key pair from
+	// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, and the session token of
+	// temporary credentials from AWS_SESSION_TOKEN if it is set
+	GetAwsCredentialsFromEnv bool
+	// the ARN of an IAM role to assume with AWS STS. The NMA assumes the role
+	// with the key pair if it is given, or else with the instance profile,
+	// and gives the temporary credentials of the role to the server.
+	AwsRoleARN string
+	// the name of the session of the assumed role, generated by the NMA if empty
+	AwsRoleSessionName string
+	// the external ID that the trust policy of the role requires, if any
+	AwsExternalID string
+	// whether the NMA discovers the credentials of the instance profile of
+	// each host from the instance metadata, instead of a key pair
+	AwsUseInstanceProfile bool
+}
+
+// the ARN of an IAM role, e.g., arn:aws:iam::123456789012:role/vertica
+var awsRoleARNRegexp = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role/[\w+=,.@/-]+$`)
+
+func (opt *AWSAuthOptions) isSet() bool {
+	return opt.GetAwsCredentialsFromEnv || opt.AwsRoleARN != "" || opt.AwsUseInstanceProfile
+}
+
+// validate checks the AWS auth options against the communal storage location
+// and its configuration parameters
+func (opt *AWSAuthOptions) validate(location string, parameters map[string]string) error {
+	if (opt.AwsRoleSessionName != "" || opt.AwsExternalID != "") && opt.AwsRoleARN == "" {
+		return fmt.Errorf("the AWS role session name and external ID can only be given with an AWS role")
+	}
+	if !opt.isSet() {
+		return nil
+	}
+	if location == "" {
+		return fmt.Errorf("AWS credentials are only used in Eon mode")
+	}
+	if opt.AwsRoleARN != "" && !awsRoleARNRegexp.MatchString(opt.AwsRoleARN) {
+		return fmt.Errorf("invalid AWS role ARN %q, must be like arn:aws:iam::<account>:role/<name>", opt.AwsRoleARN)
+	}
+	if (opt.AwsRoleARN != "" || opt.AwsUseInstanceProfile) && !strings.HasPrefix(location, "s3://") {
+		return fmt.Errorf("an AWS role or instance profile can only be used with an S3 communal storage location, not %s",
+			location)
+	}
+	_, hasAWSAuth := getConfigurationParameter(parameters, util.AWSAuthKey)
+	if opt.GetAwsCredentialsFromEnv && hasAWSAuth {
+		return fmt.Errorf("the AWS credentials cannot be read from environment variables when the %s parameter is given",
+			util.AWSAuthKey)
+	}
+	if opt.AwsUseInstanceProfile && (opt.GetAwsCredentialsFromEnv || hasAWSAuth) {
+		return fmt.Errorf("the AWS instance profile cannot be used with an AWS key pair")
+	}
+	return nil
+}
+
+// setAWSAuth sets the AWS credentials that the NMA uses to access the communal
+// storage, reading them from environment variables if asked
+func (vdb *VCoordinationDatabase) setAWSAuth(opt *AWSAuthOptions) error {
+	if opt.GetAwsCredentialsFromEnv {
+		err := vdb.getAwsCredentialsFromEnv()
+		if err != nil {
+			return err
+		}
+	}
+	vdb.AwsRoleARN = opt.AwsRoleARN
+	vdb.AwsRoleSessionName = opt.AwsRoleSessionName
+	vdb.AwsExternalID = opt.AwsExternalID
+	vdb.AwsUseInstanceProfile = opt.AwsUseInstanceProfile
+	return nil
+}
+
+// set aws id key, aws secret key, and aws session token if it is set
+func (vdb *VCoordinationDatabase) getAwsCredentialsFromEnv() error {
+	awsIDKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	if awsIDKey == "" {
+		return fmt.Errorf("unable to get AWS ID key from environment variable")
+	}
+	awsSecretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if awsSecretKey == "" {
+		return fmt.Errorf("unable to get AWS Secret key from environment variable")
+	}
+	vdb.AwsIDKey = awsIDKey
+	vdb.AwsSecretKey = awsSecretKey
+	vdb.AwsSessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	return nil
+}
+
+// awsAuthRequestData are the fields of the NMA request bodies that tell the NMA
+// how to get AWS credentials other than a key pair. They are not secrets, the
+// key pair and the session token are sent with the sensitive fields.
+type awsAuthRequestData struct {
+	AWSRoleARN            string `json:"aws_role_arn,omitempty"`
+	AWSRoleSessionName    string `json:"aws_role_session_name,omitempty"`
+	AWSExternalID         string `json:"aws_external_id,omitempty"`
+	AWSUseInstanceProfile bool   `json:"aws_use_instance_profile,omitempty"`
+}
+
+func (vdb *VCoordinationDatabase) getAWSAuthRequestData() awsAuthRequestData {
+	return awsAuthRequestData{
+		AWSRoleARN:            vdb.AwsRoleARN,
+		AWSRoleSessionName:    vdb.AwsRoleSessionName,
+		AWSExternalID:         vdb.AwsExternalID,
+		AWSUseInstanceProfile: vdb.AwsUseInstanceProfile,
+	}
+}
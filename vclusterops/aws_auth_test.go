@@ -0,0 +1,73 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAWSAuthOptions(t *testing.T) {
+	const location = "s3://bucket/db"
+	opt := AWSAuthOptions{}
+	assert.NoError(t, opt.validate("", nil))
+
+	opt.AwsRoleARN = "arn:aws:iam::123456789012:role/vertica"
+	assert.NoError(t, opt.validate(location, nil))
+	assert.ErrorContains(t, opt.validate("", nil), "only used in Eon mode")
+	assert.ErrorContains(t, opt.validate("gs://bucket/db", nil), "S3 communal storage")
+	opt.AwsRoleARN = "arn:aws:iam::123:user/vertica"
+	assert.ErrorContains(t, opt.validate(location, nil), "invalid AWS role ARN")
+
+	// the session name and external ID go with a role
+	opt = AWSAuthOptions{AwsExternalID: "vertica"}
+	assert.ErrorContains(t, opt.validate(location, nil), "can only be given with an AWS role")
+
+	// the instance profile replaces the key pair
+	params := map[string]string{"AWSAuth": "id:secret"}
+	opt = AWSAuthOptions{AwsUseInstanceProfile: true}
+	assert.NoError(t, opt.validate(location, nil))
+	assert.ErrorContains(t, opt.validate(location, params), "cannot be used with an AWS key pair")
+	opt = AWSAuthOptions{GetAwsCredentialsFromEnv: true}
+	assert.ErrorContains(t, opt.validate(location, params), "environment variables")
+}
+
+func TestAWSAuthRequestData(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "token")
+	vdb := makeVCoordinationDatabase()
+	assert.NoError(t, vdb.setAWSAuth(&AWSAuthOptions{GetAwsCredentialsFromEnv: true,
+		AwsRoleARN: "arn:aws:iam::123456789012:role/vertica", AwsExternalID: "vertica"}))
+	assert.Equal(t, "token", vdb.AwsSessionToken)
+
+	op, err := makeNMACheckCommunalStorageOp([]string{"192.168.1.101"}, "s3://bucket/db", nil, &vdb, true)
+	assert.NoError(t, err)
+	requestData := map[string]any{}
+	assert.NoError(t, json.Unmarshal([]byte(op.hostRequestBody), &requestData))
+	assert.Equal(t, "id", requestData["aws_access_key_id"])
+	assert.Equal(t, "token", requestData["aws_session_token"])
+	assert.Equal(t, "arn:aws:iam::123456789012:role/vertica", requestData["aws_role_arn"])
+	assert.Equal(t, "vertica", requestData["aws_external_id"])
+	assert.NotContains(t, requestData, "aws_use_instance_profile")
+
+	// the session token is masked in the logs
+	masked := sensitiveFields{AWSSessionToken: vdb.AwsSessionToken}
+	masked.maskSensitiveInfo()
+	assert.NotEqual(t, "token", masked.AWSSessionToken)
+}
@@ -0,0 +1,191 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// The ways to authenticate to an Azure Blob Storage communal storage
+const (
+	// the managed identity of the hosts, system-assigned or user-assigned
+	AzureAuthManagedIdentity = "managed-identity"
+	// a service principal of Microsoft Entra ID with a client secret
+	AzureAuthServicePrincipal = "service-principal"
+)
+
+var azureAuthMethods = []string{AzureAuthManagedIdentity, AzureAuthServicePrincipal}
+
+const (
+	azureStorageCredentialsParam    = "AzureStorageCredentials"
+	azureStorageEndpointConfigParam = "AzureStorageEndpointConfig"
+	azureBlobEndpointSuffix         = ".blob.core.windows.net"
+)
+
+// the name of an Azure storage account: 3 to 24 lowercase letters and digits
+var azureAccountNameRegexp = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+
+// AzureAuthOptions are the ways to authenticate to an Azure Blob Storage
+// communal storage, instead of a raw AzureStorageCredentials parameter. They
+// are turned into the AzureStorageEndpointConfig and AzureStorageCredentials
+// parameters of the database.
+type AzureAuthOptions struct {
+	// AzureAuthManagedIdentity or AzureAuthServicePrincipal, empty if the
+	// Azure credentials are not given by these options
+	AzureAuthMethod string
+	// the name of the storage account of the communal storage
+	AzureAccountName string
+	// the blob endpoint of the storage account, <account>.blob.core.windows.net
+	// if it is empty
+	AzureBlobEndpoint string
+	// the client ID of a user-assigned managed identity, or of the service
+	// principal. It is empty for the system-assigned managed identity.
+	AzureClientID string
+	// the tenant of the service principal
+	AzureTenantID string
+	// the client secret of the service principal, never logged
+	AzureClientSecret string
+}
+
+// validate checks the Azure auth options against the communal storage location
+// and its configuration parameters
+func (opt *AzureAuthOptions) validate(location string, parameters map[string]string) error {
+	if opt.AzureAuthMethod == "" {
+		if opt.AzureAccountName != "" || opt.AzureClientID != "" || opt.AzureTenantID != "" || opt.AzureClientSecret != "" {
+			return fmt.Errorf("must specify the Azure auth method, %s or %s, with the Azure credentials",
+				AzureAuthManagedIdentity, AzureAuthServicePrincipal)
+		}
+		return nil
+	}
+	if !slices.Contains(azureAuthMethods, opt.AzureAuthMethod) {
+		return fmt.Errorf("invalid Azure auth method %q, must be one of %v", opt.AzureAuthMethod, azureAuthMethods)
+	}
+	if !strings.HasPrefix(location, "azb://") {
+		return fmt.Errorf("the Azure auth method can only be used with an Azure communal storage location, not %q", location)
+	}
+	if !azureAccountNameRegexp.MatchString(opt.AzureAccountName) {
+		return fmt.Errorf("invalid Azure storage account name %q, must be 3 to 24 lowercase letters and digits",
+			opt.AzureAccountName)
+	}
+	if opt.AzureAuthMethod == AzureAuthServicePrincipal {
+		if opt.AzureTenantID == "" || opt.AzureClientID == "" || opt.AzureClientSecret == "" {
+			return fmt.Errorf("must specify the tenant ID, client ID and client secret of the Azure service principal")
+		}
+	} else if opt.AzureTenantID != "" || opt.AzureClientSecret != "" {
+		return fmt.Errorf("the tenant ID and client secret can only be given with the Azure auth method %s",
+			AzureAuthServicePrincipal)
+	}
+	return opt.validateConfigurationParameters(parameters)
+}
+
+// validateConfigurationParameters checks that the Azure parameters that are
+// already given are the ones of the options, such as when the options are
+// analyzed again
+func (opt *AzureAuthOptions) validateConfigurationParameters(parameters map[string]string) error {
+	generatedParameters, err := opt.getConfigurationParameters()
+	if err != nil {
+		return err
+	}
+	for name, generatedValue := range generatedParameters {
+		if value, ok := getConfigurationParameter(parameters, strings.ToLower(name)); ok && value != generatedValue {
+			return fmt.Errorf("the %s parameter cannot be given with the Azure auth method", name)
+		}
+	}
+	if _, ok := generatedParameters[azureStorageCredentialsParam]; !ok {
+		if _, ok := getConfigurationParameter(parameters, strings.ToLower(azureStorageCredentialsParam)); ok {
+			return fmt.Errorf("the %s parameter cannot be given with the Azure auth method", azureStorageCredentialsParam)
+		}
+	}
+	return nil
+}
+
+// azureStorageCredential is an entry of the AzureStorageCredentials parameter
+type azureStorageCredential struct {
+	AccountName  string `json:"accountName"`
+	BlobEndpoint string `json:"blobEndpoint"`
+	TenantID     string `json:"tenantId,omitempty"`
+	ClientID     string `json:"clientId,omitempty"`
+	ClientSecret string `json:"clientSecret,omitempty"`
+}
+
+// azureStorageEndpointConfig is an entry of the AzureStorageEndpointConfig parameter
+type azureStorageEndpointConfig struct {
+	AccountName  string `json:"accountName"`
+	BlobEndpoint string `json:"blobEndpoint"`
+	Protocol     string `json:"protocol"`
+}
+
+func (opt *AzureAuthOptions) getBlobEndpoint() string {
+	if opt.AzureBlobEndpoint != "" {
+		return opt.AzureBlobEndpoint
+	}
+	return opt.AzureAccountName + azureBlobEndpointSuffix
+}
+
+// getConfigurationParameters returns the parameters that make the server
+// authenticate to Azure as the options say. The system-assigned managed
+// identity needs no credentials, the server uses it when there are none.
+func (opt *AzureAuthOptions) getConfigurationParameters() (map[string]string, error) {
+	parameters := make(map[string]string)
+	if opt.AzureAuthMethod == "" {
+		return parameters, nil
+	}
+
+	endpointConfig, err := json.Marshal([]azureStorageEndpointConfig{{AccountName: opt.AzureAccountName,
+		BlobEndpoint: opt.getBlobEndpoint(), Protocol: "https"}})
+	if err != nil {
+		return nil, fmt.Errorf("fail to marshal the Azure endpoint config, details: %w", err)
+	}
+	parameters[azureStorageEndpointConfigParam] = string(endpointConfig)
+
+	if opt.AzureClientID == "" {
+		return parameters, nil
+	}
+	credential := azureStorageCredential{AccountName: opt.AzureAccountName, BlobEndpoint: opt.getBlobEndpoint(),
+		ClientID: opt.AzureClientID}
+	if opt.AzureAuthMethod == AzureAuthServicePrincipal {
+		credential.TenantID = opt.AzureTenantID
+		credential.ClientSecret = opt.AzureClientSecret
+	}
+	credentials, err := json.Marshal([]azureStorageCredential{credential})
+	if err != nil {
+		return nil, fmt.Errorf("fail to marshal the Azure credentials, details: %w", err)
+	}
+	parameters[azureStorageCredentialsParam] = string(credentials)
+	return parameters, nil
+}
+
+// addConfigurationParameters adds the Azure parameters of the options to the
+// configuration parameters of a database. It should be called after the
+// options are validated.
+func (opt *AzureAuthOptions) addConfigurationParameters(parameters map[string]string) (map[string]string, error) {
+	azureParameters, err := opt.getConfigurationParameters()
+	if err != nil || len(azureParameters) == 0 {
+		return parameters, err
+	}
+	if parameters == nil {
+		parameters = make(map[string]string)
+	}
+	for name, value := range azureParameters {
+		parameters[name] = value
+	}
+	return parameters, nil
+}
@@ -0,0 +1,83 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAzureAuthOptions(t *testing.T) {
+	const location = "azb://account/container/db"
+	opt := AzureAuthOptions{}
+	assert.NoError(t, opt.validate("s3://bucket/db", nil))
+	opt.AzureAccountName = "account"
+	assert.ErrorContains(t, opt.validate(location, nil), "must specify the Azure auth method")
+
+	opt.AzureAuthMethod = AzureAuthManagedIdentity
+	assert.NoError(t, opt.validate(location, nil))
+	assert.ErrorContains(t, opt.validate("s3://bucket/db", nil), "Azure communal storage location")
+	opt.AzureAccountName = "Account_1"
+	assert.ErrorContains(t, opt.validate(location, nil), "invalid Azure storage account name")
+	opt.AzureAccountName = "account"
+	opt.AzureTenantID = "tenant"
+	assert.ErrorContains(t, opt.validate(location, nil), "can only be given with the Azure auth method")
+
+	opt.AzureAuthMethod = AzureAuthServicePrincipal
+	assert.ErrorContains(t, opt.validate(location, nil), "must specify the tenant ID, client ID and client secret")
+	opt.AzureClientID = "client"
+	opt.AzureClientSecret = "secret"
+	assert.NoError(t, opt.validate(location, nil))
+
+	// the raw parameters cannot be given with the options
+	params := map[string]string{"azurestoragecredentials": `[{"accountName": "account", "accountKey": "key"}]`}
+	assert.ErrorContains(t, opt.validate(location, params), "AzureStorageCredentials parameter cannot be given")
+	opt = AzureAuthOptions{AzureAuthMethod: AzureAuthManagedIdentity, AzureAccountName: "account"}
+	assert.ErrorContains(t, opt.validate(location, params), "AzureStorageCredentials parameter cannot be given")
+	opt.AzureAuthMethod = "account-key"
+	assert.ErrorContains(t, opt.validate(location, nil), "invalid Azure auth method")
+}
+
+func TestAzureAuthConfigurationParameters(t *testing.T) {
+	const location = "azb://account/container/db"
+
+	// the system-assigned managed identity only needs the endpoint
+	opt := AzureAuthOptions{AzureAuthMethod: AzureAuthManagedIdentity, AzureAccountName: "account"}
+	params, err := opt.addConfigurationParameters(nil)
+	assert.NoError(t, err)
+	assert.Len(t, params, 1)
+	assert.JSONEq(t, `[{"accountName": "account", "blobEndpoint": "account.blob.core.windows.net", "protocol": "https"}]`,
+		params[azureStorageEndpointConfigParam])
+
+	// a service principal has credentials, and the options can be validated
+	// again after their parameters are added
+	opt = AzureAuthOptions{AzureAuthMethod: AzureAuthServicePrincipal, AzureAccountName: "account",
+		AzureBlobEndpoint: "account.blob.core.usgovcloudapi.net", AzureTenantID: "tenant", AzureClientID: "client",
+		AzureClientSecret: "secret"}
+	params, err = opt.addConfigurationParameters(map[string]string{"DataSSLParams": "1"})
+	assert.NoError(t, err)
+	assert.Len(t, params, 3)
+	assert.JSONEq(t, `[{"accountName": "account", "blobEndpoint": "account.blob.core.usgovcloudapi.net",
+		"tenantId": "tenant", "clientId": "client", "clientSecret": "secret"}]`, params[azureStorageCredentialsParam])
+	assert.NoError(t, opt.validate(location, params))
+
+	// the credentials are masked in the logs and in the audit
+	masked := sensitiveFields{Parameters: params}
+	masked.maskSensitiveInfo()
+	assert.NotContains(t, masked.Parameters[azureStorageCredentialsParam], "secret")
+	assert.Equal(t, auditMaskedValue, maskAuditValue("AzureClientSecret", opt.AzureClientSecret))
+}
@@ -0,0 +1,239 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// catalog states of a node in the consistency check
+const (
+	CatalogLatest      = "LATEST"
+	CatalogStale       = "STALE"
+	CatalogDivergent   = "DIVERGENT"
+	CatalogUnreachable = "UNREACHABLE"
+)
+
+type VCheckCatalogConsistencyOptions struct {
+	DatabaseOptions
+}
+
+// CatalogConsistency is the result of comparing the catalogs of all nodes
+type CatalogConsistency struct {
+	LatestGlobalVersion int64 `json:"latest_global_version"`
+	// true if all nodes have the latest catalog and no catalog diverges
+	IsConsistent bool               `json:"is_consistent"`
+	Nodes        []NodeCatalogState `json:"nodes"`
+}
+
+// NodeCatalogState is the catalog version and checksum read from a node
+type NodeCatalogState struct {
+	NodeName      string `json:"node_name"`
+	Address       string `json:"address"`
+	GlobalVersion int64  `json:"global_version"`
+	// checksum of the nodes, subclusters and storage locations in the catalog
+	Checksum string `json:"checksum"`
+	// one of LATEST, STALE, DIVERGENT or UNREACHABLE
+	State string `json:"state"`
+}
+
+func VCheckCatalogConsistencyOptionsFactory() VCheckCatalogConsistencyOptions {
+	opt := VCheckCatalogConsistencyOptions{}
+	opt.setDefaultValues()
+	return opt
+}
+
+func (options *VCheckCatalogConsistencyOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandCheckCatalog, log); err != nil {
+		return err
+	}
+	if err := options.validateCatalogPath(); err != nil {
+		return err
+	}
+	return options.resolveHosts()
+}
+
+// VCheckCatalogConsistency reads the catalog of every node through the NMA and reports
+// which nodes have a stale catalog, and which nodes have the latest catalog version
+// but a catalog that differs from the other nodes with that version. The database
+// can be up or down.
+func (vcc VClusterCommands) VCheckCatalogConsistency(options *VCheckCatalogConsistencyOptions) (CatalogConsistency, error) {
+	consistency := CatalogConsistency{}
+	err := options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return consistency, err
+	}
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+
+	// the hosts with an unreachable NMA are reported as unreachable
+	nmaHealthOp := makeNMAHealthOp(options.Hosts)
+	clusterOpEngine := makeClusterOpEngine([]clusterOp{&nmaHealthOp}, &certs)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	if err != nil {
+		vcc.Log.PrintWarning("NMA is not reachable on some hosts, details: %v", err)
+	}
+	var nmaHosts []string
+	for host, result := range nmaHealthOp.clusterHTTPRequest.ResultCollection {
+		if result.isPassing() {
+			nmaHosts = append(nmaHosts, host)
+		}
+	}
+	if len(nmaHosts) == 0 {
+		return consistency, fmt.Errorf("NMA is not reachable on any of the hosts %v", options.Hosts)
+	}
+
+	vdb := makeVCoordinationDatabase()
+	nmaGetNodesInfoOp := makeNMAGetNodesInfoOp(nmaHosts, options.DBName, options.CatalogPrefix,
+		true /* ignore internal errors */, &vdb)
+	clusterOpEngine = makeClusterOpEngine([]clusterOp{&nmaGetNodesInfoOp}, &certs)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	if err != nil {
+		return consistency, fmt.Errorf("fail to get node info: %w", err)
+	}
+
+	nmaReadCatalogEditorOp, err := makeNMAReadCatalogEditorOp(&vdb)
+	if err != nil {
+		return consistency, err
+	}
+	clusterOpEngine = makeClusterOpEngine([]clusterOp{&nmaReadCatalogEditorOp}, &certs)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	if err != nil {
+		vcc.Log.PrintWarning("cannot read the catalog on some hosts, details: %v", err)
+	}
+
+	err = buildCatalogConsistency(&consistency, options.Hosts, &vdb, nmaReadCatalogEditorOp.hostCatalogs)
+	return consistency, err
+}
+
+// buildCatalogConsistency compares the catalogs read from the hosts. The catalogs with
+// the latest global version are compared against the checksum that most of them have,
+// so a single node with a different catalog is the one reported as divergent.
+func buildCatalogConsistency(consistency *CatalogConsistency, hosts []string, vdb *VCoordinationDatabase,
+	hostCatalogs map[string]nmaVDatabase) error {
+	checksumCounts := make(map[string]int)
+	for _, host := range hosts {
+		nodeState := NodeCatalogState{Address: host, State: CatalogUnreachable}
+		if vnode, ok := vdb.HostNodeMap[host]; ok {
+			nodeState.NodeName = vnode.Name
+		}
+
+		if nmaVDB, ok := hostCatalogs[host]; ok {
+			globalVersion, err := nmaVDB.Versions.Global.Int64()
+			if err != nil {
+				return fmt.Errorf("fail to convert the global catalog version of host %s to integer: %w", host, err)
+			}
+			// the state is decided after all catalogs are compared
+			nodeState.State = CatalogLatest
+			nodeState.GlobalVersion = globalVersion
+			nodeState.Checksum, err = catalogChecksum(&nmaVDB)
+			if err != nil {
+				return err
+			}
+			if globalVersion > consistency.LatestGlobalVersion {
+				consistency.LatestGlobalVersion = globalVersion
+				checksumCounts = make(map[string]int)
+			}
+			if globalVersion == consistency.LatestGlobalVersion {
+				checksumCounts[nodeState.Checksum]++
+			}
+		}
+		consistency.Nodes = append(consistency.Nodes, nodeState)
+	}
+
+	// ties are broken by the checksum itself, so the result does not depend on map order
+	var referenceChecksum string
+	for checksum, count := range checksumCounts {
+		if count > checksumCounts[referenceChecksum] ||
+			(count == checksumCounts[referenceChecksum] && checksum < referenceChecksum) {
+			referenceChecksum = checksum
+		}
+	}
+
+	consistency.IsConsistent = true
+	for i := range consistency.Nodes {
+		nodeState := &consistency.Nodes[i]
+		if nodeState.State == CatalogUnreachable {
+			consistency.IsConsistent = false
+			continue
+		}
+		switch {
+		case nodeState.GlobalVersion < consistency.LatestGlobalVersion:
+			nodeState.State = CatalogStale
+		case nodeState.Checksum != referenceChecksum:
+			nodeState.State = CatalogDivergent
+		default:
+			nodeState.State = CatalogLatest
+		}
+		if nodeState.State != CatalogLatest {
+			consistency.IsConsistent = false
+		}
+	}
+
+	sort.Slice(consistency.Nodes, func(i, j int) bool {
+		return consistency.Nodes[i].Address < consistency.Nodes[j].Address
+	})
+	return nil
+}
+
+// catalogChecksum returns a checksum of the parts of a catalog that must be the same on
+// all nodes with the same global version: the nodes, their subclusters and storage locations
+func catalogChecksum(nmaVDB *nmaVDatabase) (string, error) {
+	type nodeSummary struct {
+		Name             string   `json:"name"`
+		Address          string   `json:"address"`
+		CatalogPath      string   `json:"catalog_path"`
+		IsPrimary        bool     `json:"is_primary"`
+		Subcluster       string   `json:"subcluster"`
+		IsSandbox        bool     `json:"sandbox"`
+		StorageLocations []string `json:"storage_locations"`
+	}
+	catalogSummary := struct {
+		Name                    string        `json:"name"`
+		CommunalStorageLocation string        `json:"communal_storage_location"`
+		Nodes                   []nodeSummary `json:"nodes"`
+	}{
+		Name:                    nmaVDB.Name,
+		CommunalStorageLocation: nmaVDB.CommunalStorageLocation,
+	}
+	for i := range nmaVDB.Nodes {
+		n := &nmaVDB.Nodes[i]
+		catalogSummary.Nodes = append(catalogSummary.Nodes, nodeSummary{
+			Name:             n.Name,
+			Address:          n.Address,
+			CatalogPath:      n.CatalogPath,
+			IsPrimary:        n.IsPrimary,
+			Subcluster:       n.Subcluster.Name,
+			IsSandbox:        n.Subcluster.IsSandbox,
+			StorageLocations: n.StorageLocations,
+		})
+	}
+	sort.Slice(catalogSummary.Nodes, func(i, j int) bool {
+		return catalogSummary.Nodes[i].Name < catalogSummary.Nodes[j].Name
+	})
+
+	data, err := json.Marshal(catalogSummary)
+	if err != nil {
+		return "", fmt.Errorf("fail to marshal the catalog summary: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,70 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTestCatalog(globalVersion string, nodeNames ...string) nmaVDatabase {
+	nmaVDB := nmaVDatabase{Name: "test_db"}
+	nmaVDB.Versions.Global = json.Number(globalVersion)
+	for _, name := range nodeNames {
+		nmaVDB.Nodes = append(nmaVDB.Nodes, nmaVNode{Name: name, IsPrimary: true})
+	}
+	return nmaVDB
+}
+
+func TestBuildCatalogConsistency(t *testing.T) {
+	hosts := []string{"192.168.1.101", "192.168.1.102", "192.168.1.103", "192.168.1.104"}
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Name: "v_test_db_node0001"}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{Name: "v_test_db_node0002"}
+	vdb.HostNodeMap["192.168.1.103"] = &VCoordinationNode{Name: "v_test_db_node0003"}
+
+	// all catalogs are the same
+	hostCatalogs := map[string]nmaVDatabase{
+		"192.168.1.101": makeTestCatalog("10", "v_test_db_node0001", "v_test_db_node0002"),
+		"192.168.1.102": makeTestCatalog("10", "v_test_db_node0001", "v_test_db_node0002"),
+		"192.168.1.103": makeTestCatalog("10", "v_test_db_node0001", "v_test_db_node0002"),
+	}
+	consistency := CatalogConsistency{}
+	err := buildCatalogConsistency(&consistency, hosts[:3], &vdb, hostCatalogs)
+	assert.NoError(t, err)
+	assert.True(t, consistency.IsConsistent)
+	assert.Equal(t, int64(10), consistency.LatestGlobalVersion)
+
+	// one node is stale, one diverges, one is unreachable
+	hostCatalogs["192.168.1.102"] = makeTestCatalog("9", "v_test_db_node0001", "v_test_db_node0002")
+	hostCatalogs["192.168.1.103"] = makeTestCatalog("10", "v_test_db_node0001")
+	hostCatalogs["192.168.1.105"] = makeTestCatalog("10", "v_test_db_node0001", "v_test_db_node0002")
+	hosts = append(hosts, "192.168.1.105")
+	consistency = CatalogConsistency{}
+	err = buildCatalogConsistency(&consistency, hosts, &vdb, hostCatalogs)
+	assert.NoError(t, err)
+	assert.False(t, consistency.IsConsistent)
+	var states []string
+	for _, node := range consistency.Nodes {
+		states = append(states, node.State)
+	}
+	assert.Equal(t, []string{CatalogLatest, CatalogStale, CatalogDivergent, CatalogUnreachable, CatalogLatest}, states)
+	assert.Equal(t, "v_test_db_node0001", consistency.Nodes[0].NodeName)
+	assert.Equal(t, int64(9), consistency.Nodes[1].GlobalVersion)
+}
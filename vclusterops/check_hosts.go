@@ -0,0 +1,404 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"golang.org/x/exp/maps"
+)
+
+// statuses of a host check, from the best to the worst
+const (
+	HostCheckPassed  = "PASSED"
+	HostCheckWarning = "WARNING"
+	HostCheckFailed  = "FAILED"
+)
+
+// names of the host checks
+const (
+	HostCheckNMA            = "nma"
+	HostCheckVerticaVersion = "vertica_version"
+	HostCheckPort           = "port"
+	HostCheckPathWritable   = "path_writable"
+	HostCheckDiskSpace      = "disk_space"
+	HostCheckInodes         = "inodes"
+	HostCheckClockSkew      = "clock_skew"
+	// the checks of the OS settings, whose status when they are not met
+	// is set by the OS settings policy
+	HostCheckOSSettings          = "os_settings"
+	HostCheckOpenFilesLimit      = "open_files_limit"
+	HostCheckMaxUserProcesses    = "max_user_processes"
+	HostCheckSwappiness          = "vm_swappiness"
+	HostCheckTransparentHugepage = "transparent_hugepages"
+	HostCheckNTPSync             = "ntp_sync"
+)
+
+// the OS settings that Vertica needs
+const (
+	minOpenFilesLimit   = 65536
+	minMaxUserProcesses = 4096
+	maxSwappiness       = 1
+	// the transparent hugepages mode that Vertica needs on recent kernels
+	transparentHugepagesMode = "always"
+)
+
+const (
+	bytesPerMB = 1024 * 1024
+	percent    = 100
+)
+
+// VCheckHostsOptions represents the available options for VCheckHosts. The
+// hosts to check are the Hosts of the DatabaseOptions, and the paths to check
+// are its catalog, data and depot paths.
+type VCheckHostsOptions struct {
+	DatabaseOptions
+	// the ports that must not be in use on the hosts, defaults to the ports of Vertica
+	Ports []int
+	// the minimum free space in MB of the filesystem of each path
+	MinFreeSpaceMB int
+	// the minimum percentage of free inodes of the filesystem of each path
+	MinFreeInodesPercent int
+	// the maximum difference in seconds between the clock of a host and the clock
+	// of the host that runs vcluster
+	MaxClockSkewSeconds int
+	// The status of each OS settings check when its setting is not met, either
+	// WARNING or FAILED. The checks that are not in the policy use the default
+	// policy.
+	OSSettingsPolicy map[string]string
+	// The version of Vertica that the hosts must have, such as the version of the
+	// database that the hosts are added to. If it is empty, the hosts must have the
+	// version that most of them have.
+	VerticaVersion string
+}
+
+// HostsCheckReport is the result of the preflight checks of the hosts
+type HostsCheckReport struct {
+	// true if no check of any host failed
+	Passed bool              `json:"passed"`
+	Hosts  []HostCheckReport `json:"hosts"`
+}
+
+// HostCheckReport is the result of the preflight checks of a host
+type HostCheckReport struct {
+	Host           string `json:"host"`
+	VerticaVersion string `json:"vertica_version,omitempty"`
+	// the worst status of the checks of the host
+	Status string      `json:"status"`
+	Checks []HostCheck `json:"checks"`
+}
+
+// HostCheck is the result of a check of a host
+type HostCheck struct {
+	Name string `json:"name"`
+	// one of PASSED, WARNING or FAILED
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// getDefaultOSSettingsPolicy returns the status of each OS settings check when its
+// setting is not met. The limits make Vertica fail under load, while the other
+// settings only degrade it.
+func getDefaultOSSettingsPolicy() map[string]string {
+	return map[string]string{
+		HostCheckOpenFilesLimit:      HostCheckFailed,
+		HostCheckMaxUserProcesses:    HostCheckFailed,
+		HostCheckSwappiness:          HostCheckWarning,
+		HostCheckTransparentHugepage: HostCheckWarning,
+		HostCheckNTPSync:             HostCheckWarning,
+	}
+}
+
+func VCheckHostsOptionsFactory() VCheckHostsOptions {
+	opt := VCheckHostsOptions{}
+	// set default values to the params
+	opt.setDefaultValues()
+
+	return opt
+}
+
+func (options *VCheckHostsOptions) setDefaultValues() {
+	options.DatabaseOptions.setDefaultValues()
+
+	options.Ports = util.DefaultVerticaPorts
+	options.MinFreeSpaceMB = util.DefaultMinFreeSpaceMB
+	options.MinFreeInodesPercent = util.DefaultMinFreeInodesPercent
+	options.MaxClockSkewSeconds = util.DefaultMaxClockSkewSeconds
+}
+
+func (options *VCheckHostsOptions) validateAnalyzeOptions() error {
+	if len(options.RawHosts) == 0 && len(options.Hosts) == 0 {
+		return fmt.Errorf("must specify a host or host list")
+	}
+	for _, path := range options.getPaths() {
+		if err := util.ValidateAbsPath(path, "path to check"); err != nil {
+			return err
+		}
+	}
+	if options.MinFreeSpaceMB < 0 {
+		return fmt.Errorf("the minimum free space cannot be negative")
+	}
+	if options.MinFreeInodesPercent < 0 || options.MinFreeInodesPercent > percent {
+		return fmt.Errorf("the minimum percentage of free inodes must be between 0 and %d", percent)
+	}
+	if options.MaxClockSkewSeconds < 0 {
+		return fmt.Errorf("the maximum clock skew cannot be negative")
+	}
+	defaultPolicy := getDefaultOSSettingsPolicy()
+	for check, status := range options.OSSettingsPolicy {
+		if _, ok := defaultPolicy[check]; !ok {
+			return fmt.Errorf("invalid OS settings check %q in the OS settings policy", check)
+		}
+		if status != HostCheckWarning && status != HostCheckFailed {
+			return fmt.Errorf("invalid status %q of OS settings check %s, must be %s or %s", status, check,
+				HostCheckWarning, HostCheckFailed)
+		}
+	}
+	return options.resolveHosts()
+}
+
+// getPaths returns the catalog, data and depot paths to check, without duplicates
+func (options *VCheckHostsOptions) getPaths() []string {
+	var paths []string
+	for _, path := range []string{options.CatalogPrefix, options.DataPrefix, options.DepotPrefix} {
+		if path != "" && !util.StringInArray(path, paths) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// VCheckHosts runs preflight checks on prospective hosts, before a database is
+// created on them or they are added to a database. Through the NMA of each host,
+// it checks that:
+//   - the NMA can be reached
+//   - the hosts have the same Vertica version, or VerticaVersion if it is set
+//   - the ports of Vertica are not in use
+//   - the catalog, data and depot paths are writable and have enough free space
+//   - the filesystems of the paths have enough free inodes
+//   - the clock of the host is close to the clock of the host that runs vcluster
+//   - the kernel and OS settings meet the prerequisites of Vertica: the open
+//     files and user processes limits, vm.swappiness, transparent hugepages
+//     and NTP synchronization. A setting that is not met is a warning or a
+//     failure, as the OSSettingsPolicy sets.
+//
+// A failed check does not return an error: it is reported in the check report
+// of its host.
+func (vcc VClusterCommands) VCheckHosts(options *VCheckHostsOptions) (HostsCheckReport, error) {
+	report := HostsCheckReport{}
+	err := options.validateAnalyzeOptions()
+	if err != nil {
+		return report, err
+	}
+
+	nmaCheckHostOp := makeNMACheckHostOp(options.Hosts, options.getPaths(), options.Ports)
+	err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, []clusterOp{&nmaCheckHostOp})
+	if err != nil {
+		return report, fmt.Errorf("fail to check hosts %v, %w", options.Hosts, err)
+	}
+
+	// the disk usage and the OS settings are only asked to the hosts whose NMA is reachable
+	hostDiskUsage := make(map[string][]PathDiskUsage)
+	hostOSSettings := make(map[string]*hostOSSettings)
+	if reachableHosts := maps.Keys(nmaCheckHostOp.hostPreflights); len(reachableHosts) > 0 {
+		sort.Strings(reachableHosts)
+		nmaGetDiskUsageOp := makeNMAGetPathsDiskUsageOp(reachableHosts, options.getPaths(), hostDiskUsage)
+		nmaGetOSSettingsOp := makeNMAGetOSSettingsOp(reachableHosts, hostOSSettings)
+		err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, []clusterOp{&nmaGetDiskUsageOp, &nmaGetOSSettingsOp})
+		if err != nil {
+			vcc.Log.PrintWarning("fail to get the disk usage and OS settings of hosts %v, details: %v", reachableHosts, err)
+		}
+	}
+
+	buildHostsCheckReport(&report, options, &nmaCheckHostOp, hostDiskUsage, hostOSSettings)
+	return report, nil
+}
+
+// buildHostsCheckReport checks the preflight result of each host against the options
+func buildHostsCheckReport(report *HostsCheckReport, options *VCheckHostsOptions, op *nmaCheckHostOp,
+	hostDiskUsage map[string][]PathDiskUsage, hostOSSettings map[string]*hostOSSettings) {
+	expectedVersion := options.VerticaVersion
+	if expectedVersion == "" {
+		var hostVersions []string
+		for _, preflight := range op.hostPreflights {
+			hostVersions = append(hostVersions, preflight.VerticaVersion)
+		}
+		expectedVersion = getMostCommonVersion(hostVersions)
+	}
+
+	report.Passed = true
+	for _, host := range options.Hosts {
+		hostReport := HostCheckReport{Host: host}
+		preflight, ok := op.hostPreflights[host]
+		if ok {
+			hostReport.VerticaVersion = preflight.VerticaVersion
+			hostReport.addCheck(HostCheckNMA, HostCheckPassed, "NMA is reachable")
+			hostReport.checkPreflight(preflight, options, expectedVersion, op.getClockSkew(preflight.CurrentTime))
+			hostReport.checkInodes(hostDiskUsage[host], options.MinFreeInodesPercent)
+			hostReport.checkOSSettings(hostOSSettings[host], options.OSSettingsPolicy)
+		} else {
+			detail := "NMA is not reachable"
+			if result, found := op.clusterHTTPRequest.ResultCollection[host]; found && result.err != nil {
+				detail = fmt.Sprintf("NMA is not reachable: %v", result.err)
+			}
+			hostReport.addCheck(HostCheckNMA, HostCheckFailed, detail)
+		}
+		if hostReport.Status == HostCheckFailed {
+			report.Passed = false
+		}
+		report.Hosts = append(report.Hosts, hostReport)
+	}
+}
+
+func (hostReport *HostCheckReport) checkPreflight(preflight *hostPreflight, options *VCheckHostsOptions,
+	expectedVersion string, clockSkew time.Duration) {
+	if preflight.VerticaVersion == expectedVersion {
+		hostReport.addCheck(HostCheckVerticaVersion, HostCheckPassed, preflight.VerticaVersion)
+	} else {
+		hostReport.addCheck(HostCheckVerticaVersion, HostCheckFailed,
+			fmt.Sprintf("version %q does not match %q", preflight.VerticaVersion, expectedVersion))
+	}
+
+	for _, port := range preflight.Ports {
+		if port.InUse {
+			hostReport.addCheck(HostCheckPort, HostCheckFailed, fmt.Sprintf("port %d is in use", port.Port))
+		} else {
+			hostReport.addCheck(HostCheckPort, HostCheckPassed, fmt.Sprintf("port %d is free", port.Port))
+		}
+	}
+
+	minFreeBytes := uint64(options.MinFreeSpaceMB) * bytesPerMB
+	for _, path := range preflight.Paths {
+		if path.Writable {
+			hostReport.addCheck(HostCheckPathWritable, HostCheckPassed, fmt.Sprintf("%s is writable", path.Path))
+		} else {
+			hostReport.addCheck(HostCheckPathWritable, HostCheckFailed, fmt.Sprintf("%s is not writable", path.Path))
+		}
+		detail := fmt.Sprintf("%s has %d MB free", path.Path, path.FreeBytes/bytesPerMB)
+		if path.FreeBytes < minFreeBytes {
+			hostReport.addCheck(HostCheckDiskSpace, HostCheckFailed,
+				fmt.Sprintf("%s, less than %d MB", detail, options.MinFreeSpaceMB))
+		} else {
+			hostReport.addCheck(HostCheckDiskSpace, HostCheckPassed, detail)
+		}
+	}
+
+	detail := fmt.Sprintf("clock is %s off", clockSkew.Round(time.Millisecond))
+	if clockSkew > time.Duration(options.MaxClockSkewSeconds)*time.Second {
+		hostReport.addCheck(HostCheckClockSkew, HostCheckFailed,
+			fmt.Sprintf("%s, more than %d seconds", detail, options.MaxClockSkewSeconds))
+	} else {
+		hostReport.addCheck(HostCheckClockSkew, HostCheckPassed, detail)
+	}
+}
+
+// checkInodes checks the free inodes of the filesystem of each path. A host
+// without disk usage gets a warning, as its inodes could not be checked.
+func (hostReport *HostCheckReport) checkInodes(diskUsage []PathDiskUsage, minFreeInodesPercent int) {
+	if len(diskUsage) == 0 {
+		hostReport.addCheck(HostCheckInodes, HostCheckWarning, "could not get the inode usage")
+		return
+	}
+	for _, usage := range diskUsage {
+		if usage.TotalInodes == 0 {
+			hostReport.addCheck(HostCheckInodes, HostCheckPassed,
+				fmt.Sprintf("%s is on %s, which has no fixed number of inodes", usage.Path, usage.MountPoint))
+			continue
+		}
+		freePercent := float64(usage.FreeInodes) * percent / float64(usage.TotalInodes)
+		detail := fmt.Sprintf("%s is on %s, which has %.1f%% of its inodes free", usage.Path, usage.MountPoint, freePercent)
+		if freePercent < float64(minFreeInodesPercent) {
+			hostReport.addCheck(HostCheckInodes, HostCheckFailed, fmt.Sprintf("%s, less than %d%%", detail, minFreeInodesPercent))
+		} else {
+			hostReport.addCheck(HostCheckInodes, HostCheckPassed, detail)
+		}
+	}
+}
+
+// checkOSSettings checks the OS settings of a host. A setting that is not met gets
+// the status of its check in the policy, or else in the default policy.
+func (hostReport *HostCheckReport) checkOSSettings(settings *hostOSSettings, policy map[string]string) {
+	if settings == nil {
+		hostReport.addCheck(HostCheckOSSettings, HostCheckWarning, "could not get the OS settings")
+		return
+	}
+	defaultPolicy := getDefaultOSSettingsPolicy()
+	addSettingCheck := func(name string, met bool, detail string) {
+		if met {
+			hostReport.addCheck(name, HostCheckPassed, detail)
+		} else if status, ok := policy[name]; ok {
+			hostReport.addCheck(name, status, detail)
+		} else {
+			hostReport.addCheck(name, defaultPolicy[name], detail)
+		}
+	}
+
+	addSettingCheck(HostCheckOpenFilesLimit, settings.OpenFilesLimit >= minOpenFilesLimit,
+		fmt.Sprintf("open files limit is %d, must be at least %d", settings.OpenFilesLimit, minOpenFilesLimit))
+	addSettingCheck(HostCheckMaxUserProcesses, settings.MaxUserProcesses >= minMaxUserProcesses,
+		fmt.Sprintf("max user processes is %d, must be at least %d", settings.MaxUserProcesses, minMaxUserProcesses))
+	addSettingCheck(HostCheckSwappiness, settings.Swappiness <= maxSwappiness,
+		fmt.Sprintf("vm.swappiness is %d, must be at most %d", settings.Swappiness, maxSwappiness))
+	addSettingCheck(HostCheckTransparentHugepage, settings.TransparentHugepages == transparentHugepagesMode,
+		fmt.Sprintf("transparent hugepages is %q, must be %q", settings.TransparentHugepages, transparentHugepagesMode))
+	detail := "clock is synchronized by NTP"
+	if !settings.NTPSynchronized {
+		detail = "clock is not synchronized by NTP"
+	}
+	addSettingCheck(HostCheckNTPSync, settings.NTPSynchronized, detail)
+}
+
+// addCheck adds a check to the report of a host, and keeps the worst status of its checks
+func (hostReport *HostCheckReport) addCheck(name, status, detail string) {
+	hostReport.Checks = append(hostReport.Checks, HostCheck{Name: name, Status: status, Detail: detail})
+	if hostReport.Status == "" || hostCheckSeverity(status) > hostCheckSeverity(hostReport.Status) {
+		hostReport.Status = status
+	}
+}
+
+func hostCheckSeverity(status string) int {
+	switch status {
+	case HostCheckFailed:
+		return 2
+	case HostCheckWarning:
+		return 1
+	}
+	return 0
+}
+
+// getMostCommonVersion returns the version that most hosts have. If versions
+// are as common, the first one in sort order is returned.
+func getMostCommonVersion(hostVersions []string) string {
+	versionCounts := make(map[string]int)
+	var versions []string
+	for _, version := range hostVersions {
+		if versionCounts[version] == 0 {
+			versions = append(versions, version)
+		}
+		versionCounts[version]++
+	}
+	sort.Strings(versions)
+	mostCommon, mostCount := "", 0
+	for _, version := range versions {
+		if versionCounts[version] > mostCount {
+			mostCommon, mostCount = version, versionCounts[version]
+		}
+	}
+	return mostCommon
+}
@@ -0,0 +1,170 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestCheckHostsReport(t *testing.T) {
+	options := VCheckHostsOptionsFactory()
+	options.Hosts = []string{"192.168.1.101", "192.168.1.102", "192.168.1.103", "192.168.1.104"}
+	options.DataPrefix = "/data"
+	options.DepotPrefix = "/data"
+	options.Ports = []int{5433}
+	assert.NoError(t, options.validateAnalyzeOptions())
+	assert.Equal(t, []string{"/data"}, options.getPaths())
+
+	op := makeNMACheckHostOp(options.Hosts, options.getPaths(), options.Ports)
+	now := time.Now()
+	op.requestStart = now
+	op.requestEnd = now.Add(time.Second)
+	const version = "Vertica Analytic Database v24.2.0-0"
+	const preflightFmt = `{"vertica_version": %q, "current_time": %q,
+		"ports": [{"port": 5433, "in_use": %t}], "paths": [{"path": "/data", "writable": %t, "free_bytes": %d}]}`
+	for host, preflight := range map[string]string{
+		// all the checks pass
+		"192.168.1.101": fmt.Sprintf(preflightFmt, version, now.Format(time.RFC3339Nano), false, true, 4096*bytesPerMB),
+		// another version, the port is in use and the clock is a minute ahead
+		"192.168.1.102": fmt.Sprintf(preflightFmt, "Vertica Analytic Database v24.1.0-0",
+			now.Add(time.Minute).Format(time.RFC3339Nano), true, true, 4096*bytesPerMB),
+		// the path is not writable and has too little free space
+		"192.168.1.103": fmt.Sprintf(preflightFmt, version, now.Format(time.RFC3339Nano), false, false, 1024*bytesPerMB),
+	} {
+		op.hostPreflights[host] = &hostPreflight{}
+		assert.NoError(t, json.Unmarshal([]byte(preflight), op.hostPreflights[host]))
+	}
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.104": {status: FAILURE, err: errors.New("connection refused")},
+	}
+
+	// the filesystem of host 3 is short of inodes, and the disk usage of host 2 is unknown
+	diskUsage := func(freeInodes uint64) []PathDiskUsage {
+		return []PathDiskUsage{{Path: "/data", MountPoint: "/", TotalInodes: 1000, FreeInodes: freeInodes}}
+	}
+	hostDiskUsage := map[string][]PathDiskUsage{
+		"192.168.1.101": diskUsage(500),
+		"192.168.1.103": diskUsage(10),
+	}
+
+	// host 3 has a low open files limit and transparent hugepages disabled,
+	// and the OS settings of host 2 are unknown
+	goodSettings := hostOSSettings{OpenFilesLimit: 65536, MaxUserProcesses: 8192, Swappiness: 1,
+		TransparentHugepages: "always", NTPSynchronized: true}
+	badSettings := goodSettings
+	badSettings.OpenFilesLimit = 1024
+	badSettings.TransparentHugepages = "never"
+	hostOSSettingsMap := map[string]*hostOSSettings{
+		"192.168.1.101": &goodSettings,
+		"192.168.1.103": &badSettings,
+	}
+
+	report := HostsCheckReport{}
+	buildHostsCheckReport(&report, &options, &op, hostDiskUsage, hostOSSettingsMap)
+	assert.False(t, report.Passed)
+	assert.Len(t, report.Hosts, 4)
+
+	getFailedChecks := func(hostReport HostCheckReport) (failedChecks []string) {
+		for _, check := range hostReport.Checks {
+			if check.Status == HostCheckFailed {
+				failedChecks = append(failedChecks, check.Name)
+			}
+		}
+		return failedChecks
+	}
+	assert.Equal(t, HostCheckPassed, report.Hosts[0].Status)
+	assert.Empty(t, getFailedChecks(report.Hosts[0]))
+	assert.Equal(t, HostCheckFailed, report.Hosts[1].Status)
+	assert.Equal(t, []string{HostCheckVerticaVersion, HostCheckPort, HostCheckClockSkew}, getFailedChecks(report.Hosts[1]))
+	assert.Equal(t, []string{HostCheckPathWritable, HostCheckDiskSpace, HostCheckInodes, HostCheckOpenFilesLimit},
+		getFailedChecks(report.Hosts[2]))
+	assert.Contains(t, report.Hosts[2].Checks, HostCheck{Name: HostCheckTransparentHugepage, Status: HostCheckWarning,
+		Detail: `transparent hugepages is "never", must be "always"`})
+	assert.Contains(t, report.Hosts[1].Checks, HostCheck{Name: HostCheckInodes, Status: HostCheckWarning,
+		Detail: "could not get the inode usage"})
+	assert.Contains(t, report.Hosts[1].Checks, HostCheck{Name: HostCheckOSSettings, Status: HostCheckWarning,
+		Detail: "could not get the OS settings"})
+	assert.Equal(t, []string{HostCheckNMA}, getFailedChecks(report.Hosts[3]))
+	assert.Equal(t, "NMA is not reachable: connection refused", report.Hosts[3].Checks[0].Detail)
+
+	// the hosts must have the version that is given
+	options.VerticaVersion = "Vertica Analytic Database v24.1.0-0"
+	report = HostsCheckReport{}
+	buildHostsCheckReport(&report, &options, &op, hostDiskUsage, hostOSSettingsMap)
+	assert.Contains(t, getFailedChecks(report.Hosts[0]), HostCheckVerticaVersion)
+	assert.NotContains(t, getFailedChecks(report.Hosts[1]), HostCheckVerticaVersion)
+
+	// the policy can make a setting that is not met fail, or only warn
+	options.OSSettingsPolicy = map[string]string{
+		HostCheckOpenFilesLimit:      HostCheckWarning,
+		HostCheckTransparentHugepage: HostCheckFailed,
+	}
+	assert.NoError(t, options.validateAnalyzeOptions())
+	report = HostsCheckReport{}
+	buildHostsCheckReport(&report, &options, &op, hostDiskUsage, hostOSSettingsMap)
+	assert.Contains(t, getFailedChecks(report.Hosts[2]), HostCheckTransparentHugepage)
+	assert.NotContains(t, getFailedChecks(report.Hosts[2]), HostCheckOpenFilesLimit)
+
+	options.OSSettingsPolicy = map[string]string{HostCheckSwappiness: HostCheckPassed}
+	assert.ErrorContains(t, options.validateAnalyzeOptions(), "invalid status")
+	options.OSSettingsPolicy = map[string]string{"selinux": HostCheckFailed}
+	assert.ErrorContains(t, options.validateAnalyzeOptions(), "invalid OS settings check")
+}
+
+func TestGetOSSettings(t *testing.T) {
+	hosts := []string{"192.168.1.101", "192.168.1.102"}
+	hostOSSettingsMap := make(map[string]*hostOSSettings)
+	op := makeNMAGetOSSettingsOp(hosts, hostOSSettingsMap)
+
+	// a host whose NMA fails is left out instead of failing the op
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, content: `{"open_files_limit": 65536, "max_user_processes": 4096,
+			"vm_swappiness": 60, "transparent_hugepages": "madvise", "ntp_synchronized": true}`},
+		"192.168.1.102": {status: FAILURE, err: errors.New("connection refused")},
+	}
+	assert.NoError(t, op.processResult(nil))
+	assert.Len(t, hostOSSettingsMap, 1)
+	assert.Equal(t, 60, hostOSSettingsMap["192.168.1.101"].Swappiness)
+	assert.Equal(t, "madvise", hostOSSettingsMap["192.168.1.101"].TransparentHugepages)
+}
+
+func TestGetPathsDiskUsage(t *testing.T) {
+	hosts := []string{"192.168.1.101", "192.168.1.102"}
+	hostDiskUsage := make(map[string][]PathDiskUsage)
+	op := makeNMAGetPathsDiskUsageOp(hosts, []string{"/data", "/depot"}, hostDiskUsage)
+
+	execContext := makeOpEngineExecContext(vlog.Printer{})
+	op.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, op.prepare(&execContext))
+	assert.Equal(t, "/data,/depot", op.clusterHTTPRequest.RequestCollection[hosts[0]].QueryParams["paths"])
+
+	// a host whose NMA fails is left out instead of failing the op
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, content: `{"disk_usage": [
+			{"path": "/data", "mount_point": "/data", "free_bytes": 1024, "total_inodes": 1000, "free_inodes": 900}]}`},
+		"192.168.1.102": {status: FAILURE, err: errors.New("connection refused")},
+	}
+	assert.NoError(t, op.processResult(nil))
+	assert.Len(t, hostDiskUsage, 1)
+	assert.Equal(t, uint64(900), hostDiskUsage["192.168.1.101"][0].FreeInodes)
+}
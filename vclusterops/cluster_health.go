@@ -0,0 +1,198 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"sort"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+type VClusterHealthOptions struct {
+	DatabaseOptions
+}
+
+// ClusterHealth is the aggregated status of a database
+type ClusterHealth struct {
+	DBName      string             `json:"db_name"`
+	HasQuorum   bool               `json:"has_quorum"`
+	UpNodes     int                `json:"up_nodes"`
+	TotalNodes  int                `json:"total_nodes"`
+	Subclusters []SubclusterHealth `json:"subclusters"`
+	Nodes       []NodeHealth       `json:"nodes"`
+}
+
+// SubclusterHealth is the status of a subcluster
+type SubclusterHealth struct {
+	Name       string `json:"name"`
+	IsPrimary  bool   `json:"is_primary"`
+	Sandbox    string `json:"sandbox"`
+	UpNodes    int    `json:"up_nodes"`
+	TotalNodes int    `json:"total_nodes"`
+}
+
+// NodeHealth is the status of a node
+type NodeHealth struct {
+	Name         string `json:"name"`
+	Address      string `json:"address"`
+	State        string `json:"state"`
+	Subcluster   string `json:"subcluster"`
+	IsPrimary    bool   `json:"is_primary"`
+	Sandbox      string `json:"sandbox"`
+	NMAReachable bool   `json:"nma_reachable"`
+	// the global catalog version, 0 if the catalog cannot be read
+	CatalogVersion int64 `json:"catalog_version"`
+}
+
+func VClusterHealthOptionsFactory() VClusterHealthOptions {
+	opt := VClusterHealthOptions{}
+	// set default values to the params
+	opt.setDefaultValues()
+
+	return opt
+}
+
+// resolve hostnames to be IPs
+func (options *VClusterHealthOptions) analyzeOptions() (err error) {
+	if len(options.RawHosts) > 0 {
+		options.Hosts, err = util.ResolveRawHostsToAddresses(options.RawHosts, options.IPv6)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (options *VClusterHealthOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandClusterHealth, log); err != nil {
+		return err
+	}
+	return options.analyzeOptions()
+}
+
+// VClusterHealth gathers the state, subcluster and sandbox of each node from the running
+// database, the reachability of the NMA on each host, and the catalog version on each host
+// into a single ClusterHealth. Unreachable NMAs and unreadable catalogs are reported in the
+// result instead of failing the command.
+func (vcc VClusterCommands) VClusterHealth(options *VClusterHealthOptions) (ClusterHealth, error) {
+	health := ClusterHealth{DBName: options.DBName}
+
+	// validate and analyze options
+	err := options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return health, err
+	}
+
+	vdb := makeVCoordinationDatabase()
+	err = vcc.getVDBFromRunningDBIncludeSandbox(&vdb, &options.DatabaseOptions, AnySandbox)
+	if err != nil {
+		return health, err
+	}
+
+	hosts := util.CopySlice(vdb.HostList)
+	sort.Strings(hosts)
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+
+	// an unreachable NMA does not fail the command, it is reported in the result
+	nmaHealthOp := makeNMAHealthOp(hosts)
+	clusterOpEngine := makeClusterOpEngine([]clusterOp{&nmaHealthOp}, &certs)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	if err != nil {
+		vcc.Log.PrintWarning("NMA is not reachable on some hosts, details: %v", err)
+	}
+	var nmaHosts []string
+	for host, result := range nmaHealthOp.clusterHTTPRequest.ResultCollection {
+		if result.isPassing() {
+			nmaHosts = append(nmaHosts, host)
+		}
+	}
+
+	var catalogVersions map[string]int64
+	if len(nmaHosts) > 0 {
+		catalogVDB := getVDBForCatalogCheck(&vdb, nmaHosts)
+		// the catalog can only be read through a reachable NMA
+		for host := range catalogVDB.HostNodeMap {
+			if !util.StringInArray(host, nmaHosts) {
+				delete(catalogVDB.HostNodeMap, host)
+			}
+		}
+		nmaReadCatalogEditorOp, e := makeNMAReadCatalogEditorOp(&catalogVDB)
+		if e != nil {
+			return health, e
+		}
+		clusterOpEngine = makeClusterOpEngine([]clusterOp{&nmaReadCatalogEditorOp}, &certs)
+		err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+		if err != nil {
+			vcc.Log.PrintWarning("cannot read the catalog on some hosts, details: %v", err)
+		}
+		catalogVersions = nmaReadCatalogEditorOp.hostGlobalVersions
+	}
+
+	buildClusterHealth(&health, &vdb, hosts, nmaHosts, catalogVersions)
+	return health, nil
+}
+
+// buildClusterHealth fills in the node and subcluster status of the health
+func buildClusterHealth(health *ClusterHealth, vdb *VCoordinationDatabase, hosts, nmaHosts []string,
+	catalogVersions map[string]int64) {
+	scHealthMap := make(map[string]*SubclusterHealth)
+	var scNames []string
+	var upPrimaryCount, primaryCount uint
+	for _, host := range hosts {
+		vnode := vdb.HostNodeMap[host]
+		nodeHealth := NodeHealth{
+			Name:           vnode.Name,
+			Address:        vnode.Address,
+			State:          vnode.State,
+			Subcluster:     vnode.Subcluster,
+			IsPrimary:      vnode.IsPrimary,
+			Sandbox:        vnode.Sandbox,
+			NMAReachable:   util.StringInArray(host, nmaHosts),
+			CatalogVersion: catalogVersions[host],
+		}
+		health.Nodes = append(health.Nodes, nodeHealth)
+		isUp := vnode.State == util.NodeUpState
+
+		scHealth, ok := scHealthMap[vnode.Subcluster]
+		if !ok {
+			scHealth = &SubclusterHealth{Name: vnode.Subcluster, IsPrimary: vnode.IsPrimary, Sandbox: vnode.Sandbox}
+			scHealthMap[vnode.Subcluster] = scHealth
+			scNames = append(scNames, vnode.Subcluster)
+		}
+		scHealth.TotalNodes++
+		health.TotalNodes++
+		if isUp {
+			scHealth.UpNodes++
+			health.UpNodes++
+		}
+
+		// quorum is computed for the main cluster only
+		if vnode.IsPrimary && vnode.Sandbox == util.MainClusterSandbox {
+			primaryCount++
+			if isUp {
+				upPrimaryCount++
+			}
+		}
+	}
+
+	sort.Strings(scNames)
+	for _, scName := range scNames {
+		health.Subclusters = append(health.Subclusters, *scHealthMap[scName])
+	}
+	health.HasQuorum = primaryCount > 0 && hasNodeUpQuorum(upPrimaryCount, primaryCount)
+}
@@ -0,0 +1,52 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+func TestBuildClusterHealth(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Name: "v_test_db_node0001", Address: "192.168.1.101",
+		IsPrimary: true, State: util.NodeUpState, Subcluster: "default_subcluster"}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{Name: "v_test_db_node0002", Address: "192.168.1.102",
+		IsPrimary: true, State: util.NodeDownState, Subcluster: "default_subcluster"}
+	vdb.HostNodeMap["192.168.1.103"] = &VCoordinationNode{Name: "v_test_db_node0003", Address: "192.168.1.103",
+		IsPrimary: false, State: util.NodeUpState, Subcluster: "sc1", Sandbox: "sand"}
+	hosts := []string{"192.168.1.101", "192.168.1.102", "192.168.1.103"}
+
+	health := ClusterHealth{DBName: "test_db"}
+	buildClusterHealth(&health, &vdb, hosts, []string{"192.168.1.101", "192.168.1.103"},
+		map[string]int64{"192.168.1.101": 10, "192.168.1.103": 8})
+
+	// one of two primary nodes is up, which is not more than half
+	assert.False(t, health.HasQuorum)
+	assert.Equal(t, 2, health.UpNodes)
+	assert.Equal(t, 3, health.TotalNodes)
+	assert.Equal(t, []SubclusterHealth{
+		{Name: "default_subcluster", IsPrimary: true, UpNodes: 1, TotalNodes: 2},
+		{Name: "sc1", Sandbox: "sand", UpNodes: 1, TotalNodes: 1},
+	}, health.Subclusters)
+	assert.True(t, health.Nodes[0].NMAReachable)
+	assert.Equal(t, int64(10), health.Nodes[0].CatalogVersion)
+	assert.False(t, health.Nodes[1].NMAReachable)
+	assert.Equal(t, int64(0), health.Nodes[1].CatalogVersion)
+}
@@ -21,6 +21,7 @@
 package vclusterops
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -37,7 +38,7 @@ import (
  */
 
 // resultStatus is the data type for the status of
-// ClusterOpResult and hostHTTPResult
+// hostHTTPResult
 type resultStatus int
 
 var wrongCredentialErrMsg = []string{"Wrong password", "Wrong certificate"}
@@ -73,6 +74,7 @@ const (
 	SuccessCode        = 200
 	MultipleChoiceCode = 300
 	UnauthorizedCode   = 401
+	NotFoundCode       = 404
 	InternalErrorCode  = 500
 )
 
@@ -84,6 +86,9 @@ type hostHTTPResult struct {
 	host       string
 	content    string
 	err        error // This is set if the http response ends in a failure scenario
+	// the size of the response body, which is not in content when the body
+	// is downloaded to a file or streamed
+	bodyBytes int64
 }
 
 type httpsResponseStatus struct {
@@ -102,6 +107,12 @@ func (hostResult *hostHTTPResult) isUnauthorizedRequest() bool {
 	return hostResult.statusCode == UnauthorizedCode
 }
 
+// isNotFound returns true if the endpoint is not found, such as when
+// the NMA is older than the endpoint
+func (hostResult *hostHTTPResult) isNotFound() bool {
+	return hostResult.statusCode == NotFoundCode
+}
+
 // isSuccess returns true if status code is 200
 func (hostResult *hostHTTPResult) isSuccess() bool {
 	return hostResult.statusCode == SuccessCode
@@ -170,10 +181,14 @@ func (status resultStatus) getStatusString() string {
 
 // clusterOp interface requires that all ops implements
 // the following functions
-// log* implemented by embedding OpBase, but overrideable
+// log* implemented by embedding opBase, but overrideable.
+// New ops should embed opBase (and opHTTPSBase for HTTPS ops), be
+// built by an unexported makeXxxOp constructor and stay unexported.
+// External users drive ops only through the VClusterCommands methods.
 type clusterOp interface {
 	getName() string
 	setLogger(logger vlog.Printer)
+	setEventEmitter(events *eventEmitter)
 	setupSpinner()
 	startSpinner()
 	cleanupSpinner()
@@ -205,6 +220,8 @@ type opBase struct {
 	clusterHTTPRequest clusterHTTPRequest
 	skipExecute        bool // This can be set during prepare if we determine no work is needed
 	spinner            *yacspin.Spinner
+	// the emitter of the events of the command, nil if the caller does not handle them
+	events *eventEmitter
 }
 
 type opResponseMap map[string]string
@@ -214,7 +231,11 @@ func (op *opBase) getName() string {
 }
 
 func (op *opBase) setLogger(logger vlog.Printer) {
-	op.logger = logger.WithName(op.name)
+	op.logger = logger.WithOp(op.name)
+}
+
+func (op *opBase) setEventEmitter(events *eventEmitter) {
+	op.events = events
 }
 
 func (op *opBase) parseAndCheckResponse(host, responseContent string, responseObj any) error {
@@ -395,8 +416,7 @@ func (op *opBase) isSkipExecute() bool {
 // hasQuorum checks if we have enough working primary nodes to maintain data integrity
 // quorumCount = (1/2 * number of primary nodes) + 1
 func (op *opBase) hasQuorum(hostCount, primaryNodeCount uint) bool {
-	quorumCount := (primaryNodeCount + 1) / 2
-	if hostCount < quorumCount {
+	if !isQuorumSatisfied(hostCount, primaryNodeCount) {
 		op.logger.PrintError("[%s] Quorum check failed: "+
 			"number of hosts with latest catalog (%d) is not "+
 			"greater than or equal to 1/2 of number of the primary nodes (%d)\n",
@@ -407,6 +427,20 @@ func (op *opBase) hasQuorum(hostCount, primaryNodeCount uint) bool {
 	return true
 }
 
+// isQuorumSatisfied returns true if hostCount is at least half of primaryNodeCount.
+// It checks the number of hosts that have the latest catalog; use hasNodeUpQuorum
+// to check the number of primary nodes that are up.
+func isQuorumSatisfied(hostCount, primaryNodeCount uint) bool {
+	quorumCount := (primaryNodeCount + 1) / 2
+	return hostCount >= quorumCount
+}
+
+// hasNodeUpQuorum returns true if more than half of the primary nodes are up,
+// which the database needs to stay up
+func hasNodeUpQuorum(upPrimaryNodeCount, primaryNodeCount uint) bool {
+	return upPrimaryNodeCount > primaryNodeCount/2
+}
+
 // checkResponseStatusCode will verify if the status code in https response is a successful code
 func (op *opBase) checkResponseStatusCode(resp httpsResponseStatus, host string) (err error) {
 	if resp.StatusCode != respSuccStatusCode {
@@ -423,7 +457,10 @@ type sensitiveFields struct {
 	DBPassword         string            `json:"db_password"`
 	AWSAccessKeyID     string            `json:"aws_access_key_id"`
 	AWSSecretAccessKey string            `json:"aws_secret_access_key"`
+	AWSSessionToken    string            `json:"aws_session_token,omitempty"`
 	Parameters         map[string]string `json:"parameters"`
+
+	SpreadSecurityDetails string `json:"spread_security_details,omitempty"`
 }
 
 func (maskedData *sensitiveFields) maskSensitiveInfo() {
@@ -432,11 +469,18 @@ func (maskedData *sensitiveFields) maskSensitiveInfo() {
 		"awsauth":                 true,
 		"awssessiontoken":         true,
 		"gcsauth":                 true,
+		"gcsserviceaccountkey":    true,
 		"azurestoragecredentials": true,
 	}
 	maskedData.DBPassword = maskedValue
 	maskedData.AWSAccessKeyID = maskedValue
 	maskedData.AWSSecretAccessKey = maskedValue
+	if maskedData.AWSSessionToken != "" {
+		maskedData.AWSSessionToken = maskedValue
+	}
+	if maskedData.SpreadSecurityDetails != "" {
+		maskedData.SpreadSecurityDetails = maskedValue
+	}
 	for key := range maskedData.Parameters {
 		// Mask the value if the keys are credentials
 		keyLowerCase := strings.ToLower(key)
@@ -458,7 +502,7 @@ type opHTTPSBase struct {
 	userName        string
 }
 
-// we may add some common functions for OpHTTPSBase here
+// we may add some common functions for opHTTPSBase here
 
 func (opb *opHTTPSBase) validateAndSetUsernameAndPassword(opName string, useHTTPPassword bool,
 	userName string, httpsPassword *string) error {
@@ -486,23 +530,51 @@ type ClusterCommands interface {
 
 	VAddNode(options *VAddNodeOptions) (VCoordinationDatabase, error)
 	VAddSubcluster(options *VAddSubclusterOptions) error
+	VAddSubclusterWithVDB(options *VAddSubclusterOptions) (VCoordinationDatabase, error)
+	VClusterHealth(options *VClusterHealthOptions) (ClusterHealth, error)
+	VCheckCatalogConsistency(options *VCheckCatalogConsistencyOptions) (CatalogConsistency, error)
+	VCheckHosts(options *VCheckHostsOptions) (HostsCheckReport, error)
+	VClearLoadBalanceGroup(options *VClearLoadBalanceGroupOptions) error
 	VCreateDatabase(options *VCreateDatabaseOptions) (VCoordinationDatabase, error)
 	VDropDatabase(options *VDropDatabaseOptions) error
 	VFetchNodeState(options *VFetchNodeStateOptions) ([]NodeInfo, error)
+	VGetDataCollector(options *VGetDataCollectorOptions) error
+	VGetDiagnostics(options *VGetDiagnosticsOptions) ([]NodeDiagnostics, error)
+	VGetDrainingStatus(options *VGetDrainingStatusOptions) ([]SubclusterDrainingStatus, error)
+	VGetLicenseStatus(options *VGetLicenseStatusOptions) ([]LicenseStatus, error)
+	VGetNMAStatus(options *VGetNMAStatusOptions) ([]NMAStatus, error)
+	VDepositFiles(options *VDepositFilesOptions) ([]FileDepositResult, error)
+	VExecuteSQL(options *VExecuteSQLOptions) ([]SQLResult, error)
+	VAlterDepot(options *VAlterDepotOptions) error
+	VClearDepotCache(options *VClearDepotCacheOptions) error
+	VRecoverCatalog(options *VRecoverCatalogOptions) error
+	VTailLog(options *VTailLogOptions) error
+	VRegenerateSpreadConf(options *VRegenerateSpreadConfOptions) error
+	VInstallLicense(options *VInstallLicenseOptions) error
 	VInstallPackages(options *VInstallPackagesOptions) (*InstallPackageStatus, error)
+	VPollSubscriptionState(options *VPollSubscriptionStateOptions) error
+	VRedirectConnections(options *VRedirectConnectionsOptions) error
 	VReIP(options *VReIPOptions) error
 	VRemoveNode(options *VRemoveNodeOptions) (VCoordinationDatabase, error)
 	VRemoveSubcluster(removeScOpt *VRemoveScOptions) (VCoordinationDatabase, error)
+	VReplaceNode(options *VReplaceNodeOptions) (VCoordinationDatabase, error)
+	VRestartDatabase(options *VRestartDatabaseOptions) (vdbPtr *VCoordinationDatabase, err error)
+	VRotateNMACerts(options *VRotateNMACertsOptions) error
 	VReviveDatabase(options *VReviveDatabaseOptions) (dbInfo string, vdbPtr *VCoordinationDatabase, err error)
 	VSandbox(options *VSandboxOptions) error
+	VScaleSubcluster(options *VScaleSubclusterOptions) (VCoordinationDatabase, error)
 	VScrutinize(options *VScrutinizeOptions) error
+	VSetLoadBalanceGroup(options *VSetLoadBalanceGroupOptions) error
 	VShowRestorePoints(options *VShowRestorePointsOptions) (restorePoints []RestorePoint, err error)
 	VStartDatabase(options *VStartDatabaseOptions) (vdbPtr *VCoordinationDatabase, err error)
 	VStartNodes(options *VStartNodesOptions) error
 	VStopDatabase(options *VStopDatabaseOptions) error
+	VStopNode(options *VStopNodeOptions) error
 	VReplicateDatabase(options *VReplicationDatabaseOptions) error
 	VFetchCoordinationDatabase(options *VFetchCoordinationDatabaseOptions) (VCoordinationDatabase, error)
+	VFetchRunningCoordinationDatabase(options *VFetchCoordinationDatabaseOptions) (VCoordinationDatabase, error)
 	VUnsandbox(options *VUnsandboxOptions) error
+	VUpgradeVertica(options *VUpgradeVerticaOptions) (VUpgradeVerticaStatus, error)
 	VStopSubcluster(options *VStopSubclusterOptions) error
 	VFetchNodesDetails(options *VFetchNodesDetailsOptions) (NodesDetails, error)
 }
@@ -543,4 +615,18 @@ func (vcc VClusterCommandsLogger) PrintError(msg string, v ...any) {
 // (e.g. create db, add node, etc.).
 type VClusterCommands struct {
 	VClusterCommandsLogger
+	// if set, receives the record of each administrative command
+	AuditLogger AuditLogger
+	// if set, the commands stop once it is done, such as when the caller
+	// reaches a deadline or is interrupted. It also carries the correlation
+	// ID, the timing summary and the event handler of the commands.
+	Ctx context.Context
+}
+
+// getContext returns the context that the op engines of the commands run with
+func (vcc VClusterCommands) getContext() context.Context {
+	if vcc.Ctx == nil {
+		return context.Background()
+	}
+	return vcc.Ctx
 }
@@ -16,15 +16,42 @@
 package vclusterops
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"github.com/vertica/vcluster/vclusterops/metrics"
+	"github.com/vertica/vcluster/vclusterops/tracing"
 	"github.com/vertica/vcluster/vclusterops/vlog"
 )
 
+// OpEngineCanceledError is returned when the op engine is canceled, and
+// reports the instruction that was in flight or was about to run
+type OpEngineCanceledError struct {
+	Instruction string
+	// false if the engine was canceled between two instructions
+	InFlight bool
+	Err      error
+}
+
+func (e *OpEngineCanceledError) Error() string {
+	if e.InFlight {
+		return fmt.Sprintf("op engine canceled while running instruction %s: %s", e.Instruction, e.Err)
+	}
+	return fmt.Sprintf("op engine canceled before running instruction %s: %s", e.Instruction, e.Err)
+}
+
+func (e *OpEngineCanceledError) Unwrap() error {
+	return e.Err
+}
+
 type VClusterOpEngine struct {
 	instructions []clusterOp
 	certs        *httpsCerts
 	execContext  *opEngineExecContext
+	// the number of instructions that succeeded, so that a command can find
+	// the instruction that failed
+	numSucceeded int
 }
 
 func makeClusterOpEngine(instructions []clusterOp, certs *httpsCerts) VClusterOpEngine {
@@ -38,21 +65,64 @@ func (opEngine *VClusterOpEngine) shouldGetCertsFromOptions() bool {
 	return (opEngine.certs.key != "" && opEngine.certs.cert != "")
 }
 
-func (opEngine *VClusterOpEngine) run(logger vlog.Printer) error {
+// run runs the instructions until one of them fails, or until ctx is done.
+// The engine checks ctx before each instruction and while waiting for the
+// responses of the hosts, and returns an *OpEngineCanceledError once it is done.
+func (opEngine *VClusterOpEngine) run(ctx context.Context, logger vlog.Printer) error {
 	execContext := makeOpEngineExecContext(logger)
 	opEngine.execContext = &execContext
 
-	return opEngine.runWithExecContext(logger, &execContext)
+	return opEngine.runWithExecContext(ctx, logger, &execContext)
 }
 
-func (opEngine *VClusterOpEngine) runWithExecContext(logger vlog.Printer, execContext *opEngineExecContext) error {
+func (opEngine *VClusterOpEngine) runWithExecContext(ctx context.Context, logger vlog.Printer,
+	execContext *opEngineExecContext) error {
 	findCertsInOptions := opEngine.shouldGetCertsFromOptions()
+	// log the requests issued by the instructions, whether they succeed or not,
+	// so that the administrative load of each command can be measured
+	defer opEngine.recordWorkload(ctx, logger, execContext)
+
+	// the op engines of a library user that does not set a correlation ID
+	// have their own ID
+	correlationID := GetCorrelationID(ctx)
+	if correlationID == "" {
+		correlationID = NewCorrelationID()
+		ctx = ContextWithCorrelationID(ctx, correlationID)
+	}
+	logger.Log = logger.Log.WithValues(correlationIDKey, correlationID)
+	return opEngine.runInstructions(ctx, logger, execContext, findCertsInOptions)
+}
 
+// runInstructions runs the instructions of the engine with a context that
+// already has its correlation ID
+func (opEngine *VClusterOpEngine) runInstructions(ctx context.Context, logger vlog.Printer,
+	execContext *opEngineExecContext, findCertsInOptions bool) error {
+	execContext.dispatcher.ctx = ctx
+	events := getEventEmitter(ctx)
 	for _, op := range opEngine.instructions {
+		if ctx.Err() != nil {
+			return opEngine.canceledError(logger, op.getName(), false, ctx.Err())
+		}
+		// the requests of the instruction are child spans of its span
+		opCtx, span := tracing.StartSpan(ctx, op.getName())
+		execContext.dispatcher.ctx = opCtx
+		op.setEventEmitter(events)
+		events.instructionStarted(op.getName())
+		start := time.Now()
 		err := opEngine.runInstruction(logger, execContext, op, findCertsInOptions)
+		events.instructionFinished(op.getName(), time.Since(start), err)
+		metrics.ObserveOp(op.getName(), start, err)
+		getTimingSummary(ctx).recordInstruction(op.getName(), time.Since(start), err)
+		span.End(err)
+		opEngine.logInstruction(logger, op.getName(), time.Since(start), err)
 		if err != nil {
+			// the instruction fails when the context is done while it waits for the hosts
+			if ctx.Err() != nil {
+				return opEngine.canceledError(logger, op.getName(), true, ctx.Err())
+			}
 			return err
 		}
+		opEngine.numSucceeded++
 	}
 
 	return nil
@@ -105,3 +175,39 @@ func (opEngine *VClusterOpEngine) runInstruction(
 
 	return nil
 }
+
+func (opEngine *VClusterOpEngine) canceledError(logger vlog.Printer, instruction string, inFlight bool, err error) error {
+	canceledErr := &OpEngineCanceledError{Instruction: instruction, InFlight: inFlight, Err: err}
+	logger.PrintWarning("%s", canceledErr)
+	return canceledErr
+}
+
+// recordWorkload adds the hosts touched, requests issued and bytes transferred
+// by the instructions of this engine to the workload summary of ctx, and logs them
+func (opEngine *VClusterOpEngine) recordWorkload(ctx context.Context, logger vlog.Printer, execContext *opEngineExecContext) {
+	if execContext.dispatcher.workload == nil {
+		return
+	}
+	getWorkloadSummary(ctx).add(execContext.dispatcher.workload)
+	workload := execContext.dispatcher.workload.summary()
+	var instructionNames []string
+	for _, op := range opEngine.instructions {
+		instructionNames = append(instructionNames, op.getName())
+	}
+	logger.Info("Op engine workload summary",
+		"instructions", instructionNames,
+		"hostsTouched", workload.HostsTouched,
+		"requestsIssued", workload.RequestsIssued,
+		"bytesSent", workload.BytesSent,
+		"bytesReceived", workload.BytesReceived)
+}
+
+// logInstruction logs the duration and the result of an instruction, as one
+// entry that a log collector can aggregate
+func (opEngine *VClusterOpEngine) logInstruction(logger vlog.Printer, opName string, duration time.Duration, err error) {
+	if err != nil {
+		logger.Error(err, "Instruction failed", "op", opName, "duration", duration)
+		return
+	}
+	logger.Info("Instruction finished", "op", opName, "duration", duration)
+}
@@ -36,6 +36,7 @@ type opEngineExecContext struct {
 	dbInfo                        string              // store the db info that retrieved from communal storage
 	restorePoints                 []RestorePoint      // store list existing restore points that queried from an archive
 	systemTableList               systemTableListInfo // used for staging system tables
+	userLibraries                 []userLibrary       // the UDx libraries of the database, used for syncing them to new nodes
 }
 
 func makeOpEngineExecContext(logger vlog.Printer) opEngineExecContext {
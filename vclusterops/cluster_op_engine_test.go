@@ -16,8 +16,13 @@
 package vclusterops
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vertica/vcluster/vclusterops/vlog"
@@ -75,7 +80,7 @@ func TestSkipExecuteOp(t *testing.T) {
 	instructions := []clusterOp{&opWithSkipDisabled, &opWithSkipEnabled}
 	certs := httpsCerts{key: "key", cert: "cert", caCert: "ca-cert"}
 	opEngn := makeClusterOpEngine(instructions, &certs)
-	err := opEngn.run(vlog.Printer{})
+	err := opEngn.run(context.Background(), vlog.Printer{})
 	assert.Equal(t, nil, err)
 	assert.True(t, opWithSkipDisabled.calledPrepare)
 	assert.True(t, opWithSkipDisabled.calledExecute)
@@ -84,3 +89,193 @@ func TestSkipExecuteOp(t *testing.T) {
 	assert.False(t, opWithSkipEnabled.calledExecute)
 	assert.True(t, opWithSkipEnabled.calledFinalize)
 }
+
+func TestOpEngineWorkload(t *testing.T) {
+	workload := makeOpEngineWorkload()
+	requests := map[string]hostHTTPRequest{
+		"host1": {RequestData: `{"key":"value"}`},
+		"host2": {},
+	}
+	// a failed request is counted, and so is a downloaded file, which is not in the content
+	results := []hostHTTPResult{
+		{host: "host1", status: SUCCESS, content: "ok", bodyBytes: 2},
+		{host: "host2", status: EXCEPTION, err: errors.New("connection refused")},
+		{host: "host2", status: SUCCESS, bodyBytes: 1024},
+	}
+	for i := 0; i < 2; i++ {
+		for host, request := range requests {
+			request := request
+			workload.recordRequest(host, &request)
+		}
+	}
+	for i := range results {
+		workload.recordResult(&results[i])
+	}
+
+	summary := workload.summary()
+	assert.Equal(t, 2, summary.HostsTouched)
+	assert.Equal(t, 4, summary.RequestsIssued)
+	assert.Equal(t, int64(30), summary.BytesSent)
+	assert.Equal(t, int64(1026), summary.BytesReceived)
+
+	// the workload of several engines is summed up in the summary of their
+	// context, a host touched by several engines is counted once
+	other := makeOpEngineWorkload()
+	other.recordRequest("host2", &hostHTTPRequest{})
+	other.recordRequest("host3", &hostHTTPRequest{})
+	workloadSummary := NewWorkloadSummary()
+	ctx := ContextWithWorkloadSummary(context.Background(), workloadSummary)
+	getWorkloadSummary(ctx).add(workload)
+	getWorkloadSummary(ctx).add(other)
+	total := workloadSummary.Workload()
+	assert.Equal(t, 3, total.HostsTouched)
+	assert.Equal(t, 6, total.RequestsIssued)
+
+	// a context without a summary records nothing
+	getWorkloadSummary(context.Background()).add(workload)
+}
+
+// cancelingOp cancels the op engine context while it is executed
+type cancelingOp struct {
+	mockOp
+	cancel context.CancelFunc
+}
+
+func (m *cancelingOp) execute(_ *opEngineExecContext) error {
+	m.cancel()
+	return context.Canceled
+}
+
+func TestOpEngineCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	firstOp := makeMockOp(false)
+	secondOp := cancelingOp{mockOp: makeMockOp(false), cancel: cancel}
+	secondOp.name = "canceling-op"
+	lastOp := makeMockOp(false)
+	certs := httpsCerts{}
+	opEngn := makeClusterOpEngine([]clusterOp{&firstOp, &secondOp, &lastOp}, &certs)
+	err := opEngn.run(ctx, vlog.Printer{})
+
+	// the error reports the instruction in flight and the cause
+	var canceledErr *OpEngineCanceledError
+	assert.True(t, errors.As(err, &canceledErr))
+	assert.Equal(t, "canceling-op", canceledErr.Instruction)
+	assert.True(t, canceledErr.InFlight)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, firstOp.calledFinalize)
+	assert.False(t, lastOp.calledPrepare)
+
+	// an engine does not start any instruction with a done context
+	opEngn = makeClusterOpEngine([]clusterOp{&lastOp}, &certs)
+	err = opEngn.run(ctx, vlog.Printer{})
+	assert.ErrorContains(t, err, "canceled before running instruction skip-enabled-false")
+	assert.False(t, lastOp.calledPrepare)
+
+	// but the engines of the other commands are not canceled
+	vcc := VClusterCommands{}
+	opEngn = makeClusterOpEngine([]clusterOp{&lastOp}, &certs)
+	assert.NoError(t, opEngn.run(vcc.getContext(), vlog.Printer{}))
+	assert.True(t, lastOp.calledPrepare)
+}
+
+// correlationOp records the correlation ID of the requests of its instruction
+type correlationOp struct {
+	mockOp
+	correlationID string
+}
+
+func (m *correlationOp) execute(execContext *opEngineExecContext) error {
+	m.correlationID = GetCorrelationID(execContext.dispatcher.ctx)
+	return nil
+}
+
+func TestOpEngineCorrelationID(t *testing.T) {
+	certs := httpsCerts{}
+
+	// the engine has the correlation ID of the command
+	ctx := ContextWithCorrelationID(context.Background(), "test-correlation-id")
+	op := correlationOp{mockOp: makeMockOp(false)}
+	opEngn := makeClusterOpEngine([]clusterOp{&op}, &certs)
+	assert.NoError(t, opEngn.run(ctx, vlog.Printer{}))
+	assert.Equal(t, "test-correlation-id", op.correlationID)
+
+	// otherwise it has its own ID
+	op = correlationOp{mockOp: makeMockOp(false)}
+	opEngn = makeClusterOpEngine([]clusterOp{&op}, &certs)
+	assert.NoError(t, opEngn.run(context.Background(), vlog.Printer{}))
+	assert.Len(t, op.correlationID, 2*correlationIDSize)
+}
+
+func TestTimingSummary(t *testing.T) {
+	summary := NewTimingSummary()
+	ctx := ContextWithTimingSummary(context.Background(), summary)
+
+	firstOp := makeMockOp(false)
+	secondOp := makeMockOp(true)
+	opEngn := makeClusterOpEngine([]clusterOp{&firstOp, &secondOp, &firstOp}, &httpsCerts{})
+	assert.NoError(t, opEngn.run(ctx, vlog.Printer{}))
+	summary.recordInstruction("HTTPSPollNodeStateOp", time.Minute, errors.New("timeout"))
+	summary.recordRequest("HTTPSPollNodeStateOp", "192.168.1.101", time.Second)
+	summary.recordRequest("HTTPSPollNodeStateOp", "192.168.1.102", 3*time.Second)
+	summary.recordRequest("HTTPSPollNodeStateOp", "192.168.1.102", 2*time.Second)
+
+	// the instruction that dominates the runtime is first
+	instructions := summary.Instructions()
+	assert.Len(t, instructions, 3)
+	assert.Equal(t, "HTTPSPollNodeStateOp", instructions[0].Name)
+	assert.Equal(t, 1, instructions[0].Failures)
+	host, latency := instructions[0].SlowestHost()
+	assert.Equal(t, "192.168.1.102", host)
+	assert.Equal(t, 3*time.Second, latency)
+	// the mock ops take about no time, so their order is not checked
+	for _, instruction := range instructions[1:] {
+		if instruction.Name == "skip-enabled-false" {
+			assert.Equal(t, 2, instruction.Runs)
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, summary.WriteTable(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 5)
+	assert.Regexp(t, `^HTTPSPollNodeStateOp\s+1\s+1\s+1m0s\s+\d+\.\d%\s+192\.168\.1\.102\s+3s$`, lines[1])
+	assert.True(t, strings.HasPrefix(lines[4], "TOTAL"))
+
+	// the engines without a summary do not record their timing
+	var noSummary *TimingSummary
+	noSummary.recordInstruction("HTTPSPollNodeStateOp", time.Second, nil)
+}
+
+func TestOpEngineEvents(t *testing.T) {
+	var events []*Event
+	ctx := ContextWithEventHandler(context.Background(), func(event *Event) { events = append(events, event) })
+
+	firstOp := makeMockOp(false)
+	secondOp := cancelingOp{mockOp: makeMockOp(false), cancel: func() {}}
+	secondOp.name = "failing-op"
+	opEngn := makeClusterOpEngine([]clusterOp{&firstOp, &secondOp}, &httpsCerts{})
+	assert.Error(t, opEngn.run(ctx, vlog.Printer{}))
+
+	// each instruction has a start event, then a success or failure event
+	var types []EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []EventType{EventInstructionStarted, EventInstructionSucceeded,
+		EventInstructionStarted, EventInstructionFailed}, types)
+	assert.Equal(t, "failing-op", events[3].Instruction)
+	assert.ErrorIs(t, events[3].Err, context.Canceled)
+
+	// only the transitions of the nodes are sent
+	events = nil
+	emitter := getEventEmitter(ctx)
+	emitter.nodeState("HTTPSPollNodeStateOp", "192.168.1.101", "v_test_db_node0001", "INITIALIZING")
+	emitter.nodeState("HTTPSPollNodeStateOp", "192.168.1.101", "v_test_db_node0001", "INITIALIZING")
+	emitter.nodeState("HTTPSPollNodeStateOp", "192.168.1.101", "v_test_db_node0001", "UP")
+	assert.Len(t, events, 2)
+	assert.Equal(t, EventNodeStateChanged, events[1].Type)
+	assert.Equal(t, "INITIALIZING", events[1].PreviousState)
+	assert.Equal(t, "UP", events[1].State)
+	assert.Equal(t, "v_test_db_node0001", events[1].NodeName)
+}
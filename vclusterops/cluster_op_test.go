@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
 )
 
 func TestHasQuorum(t *testing.T) {
@@ -54,3 +55,44 @@ func TestHasQuorum(t *testing.T) {
 	succeed = op.hasQuorum(hostCount, primaryNodeCount)
 	assert.Equal(t, succeed, false)
 }
+
+func TestHasNodeUpQuorum(t *testing.T) {
+	assert.True(t, hasNodeUpQuorum(1, 1))
+	assert.True(t, hasNodeUpQuorum(2, 3))
+	assert.True(t, hasNodeUpQuorum(3, 4))
+	// exactly half of the primary nodes is not a quorum
+	assert.False(t, hasNodeUpQuorum(1, 2))
+	assert.False(t, hasNodeUpQuorum(2, 4))
+	assert.False(t, hasNodeUpQuorum(0, 0))
+}
+
+func TestStopSingleNodeQuorum(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Name: "node1", Address: "192.168.1.101",
+		IsPrimary: true, State: util.NodeUpState}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{Name: "node2", Address: "192.168.1.102",
+		IsPrimary: true, State: util.NodeUpState}
+	vdb.HostNodeMap["192.168.1.103"] = &VCoordinationNode{Name: "node3", Address: "192.168.1.103",
+		IsPrimary: true, State: util.NodeDownState}
+
+	// stopping a second primary node would lose quorum
+	op, err := makeHTTPSStopSingleNodeOp(&vdb, "node1", false, "", nil, nil)
+	assert.NoError(t, err)
+	op.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	execContext := makeOpEngineExecContext(op.logger)
+	err = op.prepare(&execContext)
+	assert.ErrorContains(t, err, "the remaining 1 of 3 primary nodes would not have quorum")
+
+	// only the target node receives the shutdown request
+	vdb.HostNodeMap["192.168.1.103"].State = util.NodeUpState
+	err = op.prepare(&execContext)
+	assert.NoError(t, err)
+	assert.Len(t, op.clusterHTTPRequest.RequestCollection, 1)
+	assert.Equal(t, "v1/nodes/node1/shutdown", op.clusterHTTPRequest.RequestCollection["192.168.1.101"].Endpoint)
+
+	// unknown nodes are rejected
+	op.nodeName = "node4"
+	err = op.prepare(&execContext)
+	assert.ErrorContains(t, err, "node node4 is not found in the database")
+}
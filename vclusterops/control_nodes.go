@@ -0,0 +1,173 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"golang.org/x/exp/slices"
+)
+
+// The policies that choose the control nodes of a large cluster
+const (
+	// the server chooses the control nodes
+	ControlNodePolicyAuto = "auto"
+	// the control nodes are spread evenly across the racks of the hosts
+	ControlNodePolicyRack = "per-rack"
+	// the control nodes are spread evenly across the fault domains of the hosts
+	ControlNodePolicyFaultDomain = "per-fault-domain"
+	// the control nodes are the given hosts
+	ControlNodePolicyExplicit = "explicit"
+)
+
+var controlNodePolicies = []string{ControlNodePolicyAuto, ControlNodePolicyRack,
+	ControlNodePolicyFaultDomain, ControlNodePolicyExplicit}
+
+// analyzeControlNodes validates the control node policy, and chooses the
+// hosts of the control nodes. It should be called after Hosts are resolved.
+func (opt *VCreateDatabaseOptions) analyzeControlNodes() error {
+	if opt.ControlNodePolicy == "" {
+		opt.ControlNodePolicy = ControlNodePolicyAuto
+	}
+	if !slices.Contains(controlNodePolicies, opt.ControlNodePolicy) {
+		return fmt.Errorf("invalid control node policy %q, must be one of %v", opt.ControlNodePolicy, controlNodePolicies)
+	}
+	opt.controlNodeHosts = nil
+	if opt.ControlNodePolicy == ControlNodePolicyAuto {
+		if len(opt.ControlNodes) > 0 || len(opt.HostControlGroups) > 0 {
+			return fmt.Errorf("the control nodes and the groups of the hosts can only be given with the "+
+				"control node policy %s, %s or %s", ControlNodePolicyRack, ControlNodePolicyFaultDomain, ControlNodePolicyExplicit)
+		}
+		return nil
+	}
+	if len(opt.Hosts) == 0 {
+		return fmt.Errorf("must specify the hosts to choose the control nodes")
+	}
+	// the first host bootstraps the catalog, so its node is a control node
+	bootstrapHost := getInitiator(opt.Hosts)
+
+	if opt.ControlNodePolicy == ControlNodePolicyExplicit {
+		controlNodeHosts, err := opt.resolveControlNodeHosts(opt.ControlNodes)
+		if err != nil {
+			return err
+		}
+		if len(controlNodeHosts) == 0 || len(controlNodeHosts) > util.MaxLargeCluster {
+			return fmt.Errorf("must specify between 1 and %d control nodes", util.MaxLargeCluster)
+		}
+		if opt.LargeCluster != util.DefaultLargeCluster && opt.LargeCluster != len(controlNodeHosts) {
+			return fmt.Errorf("the large cluster value %d does not match the %d control nodes", opt.LargeCluster, len(controlNodeHosts))
+		}
+		if !slices.Contains(controlNodeHosts, bootstrapHost) {
+			return fmt.Errorf("the first host %s must be a control node, as it bootstraps the catalog", bootstrapHost)
+		}
+		opt.controlNodeHosts = controlNodeHosts
+		return nil
+	}
+
+	hostGroups, err := opt.resolveHostControlGroups()
+	if err != nil {
+		return err
+	}
+	count := opt.LargeCluster
+	if count == util.DefaultLargeCluster {
+		count = util.MaxLargeCluster
+	}
+	opt.controlNodeHosts = selectControlNodes(opt.Hosts, hostGroups, bootstrapHost, count)
+	return nil
+}
+
+// resolveControlNodeHosts resolves the given control nodes to be IPs, which
+// must be among the hosts of the database
+func (opt *VCreateDatabaseOptions) resolveControlNodeHosts(rawHosts []string) ([]string, error) {
+	var hosts []string
+	for _, rawHost := range rawHosts {
+		addresses, err := util.ResolveRawHostsToAddresses([]string{rawHost}, opt.IPv6)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(opt.Hosts, addresses[0]) {
+			return nil, fmt.Errorf("control node %s is not one of the hosts %v", rawHost, opt.Hosts)
+		}
+		if !slices.Contains(hosts, addresses[0]) {
+			hosts = append(hosts, addresses[0])
+		}
+	}
+	return hosts, nil
+}
+
+// resolveHostControlGroups resolves the hosts of the groups to be IPs. Each
+// host of the database must be in a group.
+func (opt *VCreateDatabaseOptions) resolveHostControlGroups() (map[string]string, error) {
+	hostGroups := make(map[string]string, len(opt.HostControlGroups))
+	for rawHost, group := range opt.HostControlGroups {
+		addresses, err := util.ResolveRawHostsToAddresses([]string{rawHost}, opt.IPv6)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(opt.Hosts, addresses[0]) {
+			return nil, fmt.Errorf("host %s has a group but is not one of the hosts %v", rawHost, opt.Hosts)
+		}
+		hostGroups[addresses[0]] = group
+	}
+	var hostsWithoutGroup []string
+	for _, host := range opt.Hosts {
+		if hostGroups[host] == "" {
+			hostsWithoutGroup = append(hostsWithoutGroup, host)
+		}
+	}
+	if len(hostsWithoutGroup) > 0 {
+		return nil, fmt.Errorf("must specify the group of the hosts %v with the control node policy %s",
+			hostsWithoutGroup, opt.ControlNodePolicy)
+	}
+	return hostGroups, nil
+}
+
+// selectControlNodes chooses count control nodes that are spread evenly
+// across the groups of the hosts, by taking a host of each group in turn.
+// The bootstrap host is chosen first. The groups are in the order of their
+// names, and the hosts of a group in their order in hosts.
+func selectControlNodes(hosts []string, hostGroups map[string]string, bootstrapHost string, count int) []string {
+	groupHosts := make(map[string][]string)
+	groupHosts[hostGroups[bootstrapHost]] = []string{bootstrapHost}
+	for _, host := range hosts {
+		if host != bootstrapHost {
+			groupHosts[hostGroups[host]] = append(groupHosts[hostGroups[host]], host)
+		}
+	}
+	var groups []string
+	for group := range groupHosts {
+		if group != hostGroups[bootstrapHost] {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	groups = append([]string{hostGroups[bootstrapHost]}, groups...)
+
+	if count > len(hosts) {
+		count = len(hosts)
+	}
+	var controlNodeHosts []string
+	for i := 0; len(controlNodeHosts) < count; i++ {
+		for _, group := range groups {
+			if i < len(groupHosts[group]) && len(controlNodeHosts) < count {
+				controlNodeHosts = append(controlNodeHosts, groupHosts[group][i])
+			}
+		}
+	}
+	return controlNodeHosts
+}
@@ -17,7 +17,6 @@ package vclusterops
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -35,7 +34,9 @@ type VCoordinationDatabase struct {
 	// processed path prefixes
 	CatalogPrefix string
 	DataPrefix    string
-	HostNodeMap   vHostNodeMap
+	// the path prefixes of the hosts with a different mount layout
+	HostPathPrefixes map[string]HostPathPrefixes
+	HostNodeMap      vHostNodeMap
 	// for convenience
 	HostList []string // expected to be resolved IP addresses
 
@@ -47,7 +48,15 @@ type VCoordinationDatabase struct {
 	DepotSize               string
 	AwsIDKey                string
 	AwsSecretKey            string
-	NumShards               int
+	// the session token of temporary AWS credentials
+	AwsSessionToken string
+	// the IAM role that the NMA assumes, or the instance profile it uses,
+	// to get AWS credentials
+	AwsRoleARN            string
+	AwsRoleSessionName    string
+	AwsExternalID         string
+	AwsUseInstanceProfile bool
+	NumShards             int
 
 	// authentication
 	LicensePathOnNode string
@@ -58,6 +67,15 @@ type VCoordinationDatabase struct {
 	PrimaryUpNodes []string
 }
 
+// HostPathPrefixes is the catalog, data and depot path prefixes of a host that
+// has a different mount layout from the rest of the database. An empty prefix
+// falls back to the one of the database.
+type HostPathPrefixes struct {
+	CatalogPrefix string
+	DataPrefix    string
+	DepotPrefix   string
+}
+
 type vHostNodeMap map[string]*VCoordinationNode
 
 func makeVHostNodeMap() vHostNodeMap {
@@ -75,6 +93,7 @@ func (vdb *VCoordinationDatabase) setFromBasicDBOptions(options *VCreateDatabase
 	vdb.CatalogPrefix = options.CatalogPrefix
 	vdb.DataPrefix = options.DataPrefix
 	vdb.DepotPrefix = options.DepotPrefix
+	vdb.HostPathPrefixes = options.HostPathPrefixes
 
 	vdb.IsEon = false
 	if options.CommunalStorageLocation != "" {
@@ -122,11 +141,9 @@ func (vdb *VCoordinationDatabase) setFromCreateDBOptions(options *VCreateDatabas
 	vdb.HostList = options.Hosts
 	vdb.LicensePathOnNode = options.LicensePathOnNode
 
-	if options.GetAwsCredentialsFromEnv {
-		err := vdb.getAwsCredentialsFromEnv()
-		if err != nil {
-			return err
-		}
+	err = vdb.setAWSAuth(&options.AWSAuthOptions)
+	if err != nil {
+		return err
 	}
 	vdb.NumShards = options.ShardCount
 
@@ -146,6 +163,18 @@ func (vdb *VCoordinationDatabase) addNode(vnode *VCoordinationNode) error {
 	return nil
 }
 
+// replaceHost replaces a host of the VDB's HostList and HostNodeMap with the
+// host of a given node, keeping the position of the host in the HostList
+func (vdb *VCoordinationDatabase) replaceHost(oldHost string, vnode *VCoordinationNode) {
+	delete(vdb.HostNodeMap, oldHost)
+	vdb.HostNodeMap[vnode.Address] = vnode
+	for i, host := range vdb.HostList {
+		if host == oldHost {
+			vdb.HostList[i] = vnode.Address
+		}
+	}
+}
+
 // addHosts adds a given list of hosts to the VDB's HostList
 // and HostNodeMap.
 func (vdb *VCoordinationDatabase) addHosts(hosts []string, scName string) error {
@@ -176,6 +205,7 @@ func (vdb *VCoordinationDatabase) copy(targetHosts []string) VCoordinationDataba
 		Name:                    vdb.Name,
 		CatalogPrefix:           vdb.CatalogPrefix,
 		DataPrefix:              vdb.DataPrefix,
+		HostPathPrefixes:        vdb.HostPathPrefixes,
 		IsEon:                   vdb.IsEon,
 		CommunalStorageLocation: vdb.CommunalStorageLocation,
 		UseDepot:                vdb.UseDepot,
@@ -183,6 +213,11 @@ func (vdb *VCoordinationDatabase) copy(targetHosts []string) VCoordinationDataba
 		DepotSize:               vdb.DepotSize,
 		AwsIDKey:                vdb.AwsIDKey,
 		AwsSecretKey:            vdb.AwsSecretKey,
+		AwsSessionToken:         vdb.AwsSessionToken,
+		AwsRoleARN:              vdb.AwsRoleARN,
+		AwsRoleSessionName:      vdb.AwsRoleSessionName,
+		AwsExternalID:           vdb.AwsExternalID,
+		AwsUseInstanceProfile:   vdb.AwsUseInstanceProfile,
 		NumShards:               vdb.NumShards,
 		LicensePathOnNode:       vdb.LicensePathOnNode,
 		Ipv6:                    vdb.Ipv6,
@@ -266,37 +301,74 @@ func (vdb *VCoordinationDatabase) hasAtLeastOneDownNode() bool {
 	return false
 }
 
+// getCatalogPrefix returns the catalog path prefix of a host
+func (vdb *VCoordinationDatabase) getCatalogPrefix(host string) string {
+	if prefixes, ok := vdb.HostPathPrefixes[host]; ok && prefixes.CatalogPrefix != "" {
+		return prefixes.CatalogPrefix
+	}
+	return vdb.CatalogPrefix
+}
+
+// getDataPrefix returns the data path prefix of a host
+func (vdb *VCoordinationDatabase) getDataPrefix(host string) string {
+	if prefixes, ok := vdb.HostPathPrefixes[host]; ok && prefixes.DataPrefix != "" {
+		return prefixes.DataPrefix
+	}
+	return vdb.DataPrefix
+}
+
+// getDepotPrefix returns the depot path prefix of a host
+func (vdb *VCoordinationDatabase) getDepotPrefix(host string) string {
+	if prefixes, ok := vdb.HostPathPrefixes[host]; ok && prefixes.DepotPrefix != "" {
+		return prefixes.DepotPrefix
+	}
+	return vdb.DepotPrefix
+}
+
+// hasHostDepotPrefixes returns true if any host has its own depot path prefix
+func (vdb *VCoordinationDatabase) hasHostDepotPrefixes() bool {
+	for _, prefixes := range vdb.HostPathPrefixes {
+		if prefixes.DepotPrefix != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// groupHostsByPathPrefixes splits the hosts into groups that share the same
+// catalog and data path prefixes, keeping the order of the hosts
+func (vdb *VCoordinationDatabase) groupHostsByPathPrefixes(hosts []string) [][]string {
+	var groups [][]string
+	groupIndex := make(map[[2]string]int)
+	for _, host := range hosts {
+		key := [2]string{vdb.getCatalogPrefix(host), vdb.getDataPrefix(host)}
+		i, ok := groupIndex[key]
+		if !ok {
+			i = len(groups)
+			groupIndex[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], host)
+	}
+	return groups
+}
+
 // genDataPath builds and returns the data path
-func (vdb *VCoordinationDatabase) genDataPath(nodeName string) string {
+func (vdb *VCoordinationDatabase) genDataPath(host, nodeName string) string {
 	dataSuffix := fmt.Sprintf("%s_data", nodeName)
-	return filepath.Join(vdb.DataPrefix, vdb.Name, dataSuffix)
+	return filepath.Join(vdb.getDataPrefix(host), vdb.Name, dataSuffix)
 }
 
 // genDepotPath builds and returns the depot path
-func (vdb *VCoordinationDatabase) genDepotPath(nodeName string) string {
+func (vdb *VCoordinationDatabase) genDepotPath(host, nodeName string) string {
 	depotSuffix := fmt.Sprintf("%s_depot", nodeName)
-	return filepath.Join(vdb.DepotPrefix, vdb.Name, depotSuffix)
+	return filepath.Join(vdb.getDepotPrefix(host), vdb.Name, depotSuffix)
 }
 
 // genCatalogPath builds and returns the catalog path
-func (vdb *VCoordinationDatabase) genCatalogPath(nodeName string) string {
+func (vdb *VCoordinationDatabase) genCatalogPath(host, nodeName string) string {
 	catalogSuffix := fmt.Sprintf("%s_catalog", nodeName)
-	return filepath.Join(vdb.CatalogPrefix, vdb.Name, catalogSuffix)
-}
-
-// set aws id key and aws secret key
-func (vdb *VCoordinationDatabase) getAwsCredentialsFromEnv() error {
-	awsIDKey := os.Getenv("AWS_ACCESS_KEY_ID")
-	if awsIDKey == "" {
-		return fmt.Errorf("unable to get AWS ID key from environment variable")
-	}
-	awsSecretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
-	if awsSecretKey == "" {
-		return fmt.Errorf("unable to get AWS Secret key from environment variable")
-	}
-	vdb.AwsIDKey = awsIDKey
-	vdb.AwsSecretKey = awsSecretKey
-	return nil
+	return filepath.Join(vdb.getCatalogPrefix(host), vdb.Name, catalogSuffix)
 }
 
 // filterPrimaryNodes will remove secondary nodes from vdb
@@ -354,14 +426,15 @@ func (vnode *VCoordinationNode) setFromBasicDBOptions(
 		vnode.Port = options.ClientPort
 		nodeNameSuffix := i + 1
 		vnode.Name = fmt.Sprintf("v_%s_node%04d", dbNameInNode, nodeNameSuffix)
+		prefixes := options.getHostPathPrefixes(host)
 		catalogSuffix := fmt.Sprintf("%s_catalog", vnode.Name)
-		vnode.CatalogPath = filepath.Join(options.CatalogPrefix, dbName, catalogSuffix)
+		vnode.CatalogPath = filepath.Join(prefixes.CatalogPrefix, dbName, catalogSuffix)
 		dataSuffix := fmt.Sprintf("%s_data", vnode.Name)
-		dataPath := filepath.Join(options.DataPrefix, dbName, dataSuffix)
+		dataPath := filepath.Join(prefixes.DataPrefix, dbName, dataSuffix)
 		vnode.StorageLocations = append(vnode.StorageLocations, dataPath)
 		if options.DepotPrefix != "" {
 			depotSuffix := fmt.Sprintf("%s_depot", vnode.Name)
-			vnode.DepotPath = filepath.Join(options.DepotPrefix, dbName, depotSuffix)
+			vnode.DepotPath = filepath.Join(prefixes.DepotPrefix, dbName, depotSuffix)
 		}
 		if options.IPv6 {
 			vnode.ControlAddressFamily = util.IPv6ControlAddressFamily
@@ -380,11 +453,11 @@ func (vnode *VCoordinationNode) setNode(vdb *VCoordinationDatabase, address, nam
 	vnode.Address = address
 	vnode.Name = name
 	vnode.Subcluster = scName
-	vnode.CatalogPath = vdb.genCatalogPath(vnode.Name)
-	dataPath := vdb.genDataPath(vnode.Name)
+	vnode.CatalogPath = vdb.genCatalogPath(address, vnode.Name)
+	dataPath := vdb.genDataPath(address, vnode.Name)
 	vnode.StorageLocations = append(vnode.StorageLocations, dataPath)
 	if vdb.DepotPrefix != "" {
-		vnode.DepotPath = vdb.genDepotPath(vnode.Name)
+		vnode.DepotPath = vdb.genDepotPath(address, vnode.Name)
 	}
 	if vdb.Ipv6 {
 		vnode.ControlAddressFamily = util.IPv6ControlAddressFamily
@@ -0,0 +1,54 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// CorrelationIDHeader is the header of the requests to the NMA and the HTTPS
+// service that has the correlation ID of the command, so that the logs of
+// vcluster can be joined with the logs of the hosts
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// the key of the correlation ID in the log entries
+const correlationIDKey = "correlationID"
+
+const correlationIDSize = 16
+
+type correlationIDContextKey struct{}
+
+// NewCorrelationID returns a random correlation ID
+func NewCorrelationID() string {
+	id := make([]byte, correlationIDSize)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// ContextWithCorrelationID returns a context with the correlation ID of a
+// command. When the context is the Ctx of VClusterCommands, the requests of
+// all of the op engines of the command have the same ID.
+func ContextWithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey{}, correlationID)
+}
+
+// GetCorrelationID returns the correlation ID of a context, or an empty string
+func GetCorrelationID(ctx context.Context) string {
+	correlationID, _ := ctx.Value(correlationIDContextKey{}).(string)
+	return correlationID
+}
@@ -16,7 +16,11 @@
 package vclusterops
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -34,17 +38,30 @@ type VCreateDatabaseOptions struct {
 	Policy            string // database restart policy
 	SQLFile           string // SQL file to run (as dbadmin) immediately on database creation
 	LicensePathOnNode string // required to be a fully qualified path
+	// local license file that is deposited to LicensePathOnNode on the bootstrap host
+	// before bootstrapping the catalog
+	LicenseFile string
 
 	/* part 2: eon db info */
 
-	ShardCount               int    // number of shards in the database"
-	DepotSize                string // depot size with two supported formats: % and KMGT, e.g., 50% or 10G
-	GetAwsCredentialsFromEnv bool   // whether get AWS credentials from environmental variables
+	ShardCount int    // number of shards in the database"
+	DepotSize  string // depot size with two supported formats: % and KMGT, e.g., 50% or 10G
+	// the AWS credentials of an S3 communal storage besides the awsauth parameter
+	AWSAuthOptions
+	// the Azure credentials of an Azure communal storage instead of the
+	// AzureStorageCredentials parameter
+	AzureAuthOptions
+	// the GCS credentials of a GCS communal storage besides the GCSAuth parameter
+	GCSAuthOptions
 	// part 3: optional info
 	ForceCleanupOnFailure     bool // whether force remove existing directories on failure
 	ForceRemovalAtCreation    bool // whether force remove existing directories before creating the database
 	SkipPackageInstall        bool // whether skip package installation
 	TimeoutNodeStartupSeconds int  // timeout in seconds for polling node start up state
+	// whether the directories left by a failed attempt to create the database are
+	// removed, and the database is created again, when the directories already exist
+	// and none of the hosts has a catalog in them
+	RemovePartialArtifacts bool
 
 	/* part 3: new params originally in installer generated admintools.conf, now in create db op */
 
@@ -55,22 +72,78 @@ type VCreateDatabaseOptions struct {
 	SpreadLogging      bool // whether enable spread logging
 	SpreadLoggingLevel int  // spread logging level
 
+	// the policy that chooses the control nodes of a large cluster: auto lets
+	// the server choose them, per-rack and per-fault-domain spread them across
+	// the groups in HostControlGroups, and explicit uses ControlNodes
+	ControlNodePolicy string
+	// the rack or fault domain of each host, with the per-rack or per-fault-domain policy
+	HostControlGroups map[string]string
+	// the hosts of the control nodes, with the explicit policy
+	ControlNodes []string
+
 	/* part 4: other params */
 
 	SkipStartupPolling bool // whether skip startup polling
 	GenerateHTTPCerts  bool // whether generate http certificates
+	// Whether the database is left stopped once its catalog is created on all
+	// the nodes. Only the bootstrap node is started to create the other nodes,
+	// and it is stopped at the end, so that the hosts can be captured in an
+	// image and the database started later with start_db.
+	NoStart bool
+	// The file that the manifest of the created database is written to, if
+	// it is set. The manifest records the nodes, paths, shard count, masked
+	// parameters and communal storage location of the database.
+	ManifestFile string
 	// If the path is set, the NMA will store the Vertica start command at the path
 	// instead of executing it. This is useful in containerized environments where
 	// you may not want to have both the NMA and Vertica server in the same container.
 	// This feature requires version 24.2.0+.
 	StartUpConf string
+	// The server TLS configuration of the database. The key and the certs are
+	// deposited in the catalog directory of each node before the first start,
+	// so that the database never runs with self-signed certs. The mode is
+	// ENABLE if a key is given, and DISABLE otherwise. TRY_VERIFY and VERIFY_CA
+	// also verify the client certs with the CA cert.
+	ServerTLSMode   string
+	ServerTLSKey    string // PEM-encoded private key of the server
+	ServerTLSCert   string // PEM-encoded certificate of the server
+	ServerTLSCACert string // PEM-encoded CA certificate of the clients
+	// Whether the spread traffic between the nodes is encrypted from the
+	// bootstrap. This sets the EncryptSpreadComm parameter.
+	EnableSpreadEncryption bool
+	// The hex-encoded 32-byte key of the spread encryption. A key is generated
+	// if it is empty. Giving a key enables the spread encryption.
+	SpreadEncryptionKey string
 
 	/* hidden options (which cache information only) */
 
 	// the host used for bootstrapping
 	bootstrapHost []string
+	// whether the directories left by a failed attempt are being removed
+	removingPartialArtifacts bool
+	// the hosts of the control nodes chosen by the control node policy
+	controlNodeHosts []string
+	// the key ID and the key of the spread encryption, never logged
+	spreadSecurityDetails string
 }
 
+// The modes of the server TLS configuration set by create_db
+const (
+	ServerTLSModeDisable   = "DISABLE"
+	ServerTLSModeEnable    = "ENABLE"
+	ServerTLSModeTryVerify = "TRY_VERIFY"
+	ServerTLSModeVerifyCA  = "VERIFY_CA"
+)
+
+// the files of the server TLS configuration in the catalog directory of each node
+const (
+	serverTLSKeyFileName    = "server.key"
+	serverTLSCertFileName   = "server.crt"
+	serverTLSCACertFileName = "root.crt"
+	enableSSLParam          = "EnableSSL"
+	encryptSpreadCommParam  = "EncryptSpreadComm"
+)
+
 func VCreateDatabaseOptionsFactory() VCreateDatabaseOptions {
 	opt := VCreateDatabaseOptions{}
 	// set default values to the params
@@ -113,7 +186,11 @@ func (opt *VCreateDatabaseOptions) validateRequiredOptions(logger vlog.Printer)
 	// localhost, where vcluster is run
 	//
 	// empty string ("") will be converted to the default license path (/opt/vertica/share/license.key)
-	// in the /bootstrap-catalog endpoint
+	// in the /bootstrap-catalog endpoint, unless a local license file is given, which
+	// is deposited to the default path of deployed licenses
+	if opt.LicenseFile != "" && opt.LicensePathOnNode == "" {
+		opt.LicensePathOnNode = util.DefaultDeployedLicenseKey
+	}
 	if opt.LicensePathOnNode != "" && !util.IsAbsPath(opt.LicensePathOnNode) {
 		return fmt.Errorf("must provide a fully qualified path for license file")
 	}
@@ -196,6 +273,10 @@ func (opt *VCreateDatabaseOptions) validateEonOptions() error {
 		if err != nil {
 			return err
 		}
+		err = validateCommunalStorageParameters(opt.CommunalStorageLocation, opt.ConfigurationParameters)
+		if err != nil {
+			return err
+		}
 		if opt.DepotPrefix == "" {
 			return fmt.Errorf("must specify a depot path with commual storage location")
 		}
@@ -206,8 +287,14 @@ func (opt *VCreateDatabaseOptions) validateEonOptions() error {
 	if opt.DepotPrefix != "" && opt.CommunalStorageLocation == "" {
 		return fmt.Errorf("when depot path is given, communal storage location cannot be empty")
 	}
-	if opt.GetAwsCredentialsFromEnv && opt.CommunalStorageLocation == "" {
-		return fmt.Errorf("AWS credentials are only used in Eon mode")
+	if err := opt.AWSAuthOptions.validate(opt.CommunalStorageLocation, opt.ConfigurationParameters); err != nil {
+		return err
+	}
+	if err := opt.AzureAuthOptions.validate(opt.CommunalStorageLocation, opt.ConfigurationParameters); err != nil {
+		return err
+	}
+	if err := opt.GCSAuthOptions.validate(opt.CommunalStorageLocation, opt.ConfigurationParameters); err != nil {
+		return err
 	}
 	if opt.DepotSize != "" {
 		if opt.DepotPrefix == "" {
@@ -229,9 +316,118 @@ func (opt *VCreateDatabaseOptions) validateExtraOptions() error {
 	if opt.LargeCluster != util.DefaultLargeCluster && (opt.LargeCluster < 1 || opt.LargeCluster > util.MaxLargeCluster) {
 		return fmt.Errorf("must specify a valid large cluster value in range [1, 120]")
 	}
+	if err := opt.validateSpreadEncryptionOptions(); err != nil {
+		return err
+	}
+	return opt.validateServerTLSOptions()
+}
+
+// validateSpreadEncryptionOptions checks the key of the spread encryption.
+// The key is never included in the errors.
+func (opt *VCreateDatabaseOptions) validateSpreadEncryptionOptions() error {
+	if opt.SpreadEncryptionKey == "" {
+		return nil
+	}
+	opt.EnableSpreadEncryption = true
+	if enabled, keyType := opt.isSpreadEncryptionEnabled(); enabled && !strings.EqualFold(keyType, spreadKeyTypeVertica) {
+		return fmt.Errorf("a spread encryption key can only be given with the spread key type %s, not %s",
+			spreadKeyTypeVertica, keyType)
+	}
+	key, err := hex.DecodeString(opt.SpreadEncryptionKey)
+	if err != nil || len(key) != spreadKeySize {
+		return fmt.Errorf("the spread encryption key must be %d hex-encoded bytes", spreadKeySize)
+	}
+	return nil
+}
+
+// analyzeSpreadEncryption sets the EncryptSpreadComm parameter, and the key
+// of the spread encryption that is given to the bootstrap
+func (opt *VCreateDatabaseOptions) analyzeSpreadEncryption() error {
+	if opt.EnableSpreadEncryption {
+		if enabled, _ := opt.isSpreadEncryptionEnabled(); !enabled {
+			if opt.ConfigurationParameters == nil {
+				opt.ConfigurationParameters = make(map[string]string)
+			}
+			opt.ConfigurationParameters[encryptSpreadCommParam] = spreadKeyTypeVertica
+		}
+	}
+	opt.spreadSecurityDetails = ""
+	if enabled, keyType := opt.isSpreadEncryptionEnabled(); !enabled || !strings.EqualFold(keyType, spreadKeyTypeVertica) {
+		return nil
+	}
+	securityDetails, err := makeVerticaSpreadSecurityDetails(opt.SpreadEncryptionKey)
+	if err != nil {
+		return err
+	}
+	opt.spreadSecurityDetails = securityDetails
 	return nil
 }
 
+// validateServerTLSOptions checks the server TLS configuration before any
+// host is changed, and sets the default mode
+func (opt *VCreateDatabaseOptions) validateServerTLSOptions() error {
+	if opt.ServerTLSMode == "" {
+		opt.ServerTLSMode = ServerTLSModeDisable
+		if opt.ServerTLSKey != "" {
+			opt.ServerTLSMode = ServerTLSModeEnable
+		}
+	}
+	opt.ServerTLSMode = strings.ToUpper(opt.ServerTLSMode)
+
+	switch opt.ServerTLSMode {
+	case ServerTLSModeDisable:
+		if opt.ServerTLSKey != "" || opt.ServerTLSCert != "" || opt.ServerTLSCACert != "" {
+			return fmt.Errorf("the server TLS key and certificates cannot be given with the TLS mode %s", ServerTLSModeDisable)
+		}
+		return nil
+	case ServerTLSModeEnable, ServerTLSModeTryVerify, ServerTLSModeVerifyCA:
+	default:
+		return fmt.Errorf("invalid server TLS mode %q, must be one of %s, %s, %s or %s", opt.ServerTLSMode,
+			ServerTLSModeDisable, ServerTLSModeEnable, ServerTLSModeTryVerify, ServerTLSModeVerifyCA)
+	}
+
+	if opt.ServerTLSKey == "" || opt.ServerTLSCert == "" {
+		return fmt.Errorf("must specify the server TLS key and certificate with the TLS mode %s", opt.ServerTLSMode)
+	}
+	_, err := tls.X509KeyPair([]byte(opt.ServerTLSCert), []byte(opt.ServerTLSKey))
+	if err != nil {
+		return fmt.Errorf("the server TLS key and certificate are not a valid pair, details: %w", err)
+	}
+	if opt.ServerTLSCACert == "" {
+		if opt.ServerTLSMode != ServerTLSModeEnable {
+			return fmt.Errorf("must specify the server TLS CA certificate with the TLS mode %s", opt.ServerTLSMode)
+		}
+		return nil
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM([]byte(opt.ServerTLSCACert)) {
+		return fmt.Errorf("the server TLS CA certificate is not a valid PEM certificate")
+	}
+	return nil
+}
+
+// makeServerTLSDepositOps makes the ops that deposit the server TLS key and
+// certificates in the catalog directory of each node
+func makeServerTLSDepositOps(vdb *VCoordinationDatabase, options *VCreateDatabaseOptions) []clusterOp {
+	files := []struct{ name, content string }{
+		{serverTLSKeyFileName, options.ServerTLSKey},
+		{serverTLSCertFileName, options.ServerTLSCert},
+		{serverTLSCACertFileName, options.ServerTLSCACert},
+	}
+	var instructions []clusterOp
+	for _, file := range files {
+		if file.content == "" {
+			continue
+		}
+		hostDestinations := make(map[string]string, len(vdb.HostList))
+		for _, host := range vdb.HostList {
+			hostDestinations[host] = filepath.Join(vdb.HostNodeMap[host].CatalogPath, file.name)
+		}
+		nmaDepositFileOp := makeNMADepositHostFilesOp(hostDestinations, file.content, file.name)
+		instructions = append(instructions, &nmaDepositFileOp)
+	}
+	return instructions
+}
+
 func (opt *VCreateDatabaseOptions) validateParseOptions(logger vlog.Printer) error {
 	// validate base options
 	err := opt.validateBaseOptions("create_db", logger)
@@ -268,12 +464,44 @@ func (opt *VCreateDatabaseOptions) analyzeOptions() error {
 		opt.Hosts = hostAddresses
 	}
 
+	// the database is bootstrapped with TLS enabled, and uses the files of the
+	// catalog directory at its first start
+	if opt.ServerTLSMode != "" && opt.ServerTLSMode != ServerTLSModeDisable {
+		if opt.ConfigurationParameters == nil {
+			opt.ConfigurationParameters = make(map[string]string)
+		}
+		opt.ConfigurationParameters[enableSSLParam] = "1"
+	}
+	parameters, err := opt.AzureAuthOptions.addConfigurationParameters(opt.ConfigurationParameters)
+	if err != nil {
+		return err
+	}
+	opt.ConfigurationParameters, err = opt.GCSAuthOptions.addConfigurationParameters(parameters)
+	if err != nil {
+		return err
+	}
+
 	// process correct catalog path, data path and depot path prefixes
 	opt.CatalogPrefix = util.GetCleanPath(opt.CatalogPrefix)
 	opt.DataPrefix = util.GetCleanPath(opt.DataPrefix)
 	opt.DepotPrefix = util.GetCleanPath(opt.DepotPrefix)
 
-	return nil
+	if opt.DepotPrefix == "" {
+		for host, prefixes := range opt.HostPathPrefixes {
+			if prefixes.DepotPrefix != "" {
+				return fmt.Errorf("host %s has a depot path but the database does not use a depot", host)
+			}
+		}
+	}
+	err = opt.analyzeSpreadEncryption()
+	if err != nil {
+		return err
+	}
+	err = opt.analyzeControlNodes()
+	if err != nil {
+		return err
+	}
+	return opt.analyzeHostPathPrefixes(opt.Hosts)
 }
 
 func (opt *VCreateDatabaseOptions) validateAnalyzeOptions(logger vlog.Printer) error {
@@ -283,7 +511,9 @@ func (opt *VCreateDatabaseOptions) validateAnalyzeOptions(logger vlog.Printer) e
 	return opt.analyzeOptions()
 }
 
-func (vcc VClusterCommands) VCreateDatabase(options *VCreateDatabaseOptions) (VCoordinationDatabase, error) {
+func (vcc VClusterCommands) VCreateDatabase(options *VCreateDatabaseOptions) (_ VCoordinationDatabase, err error) {
+	defer vcc.startAudit("create_db", options)(&err)
+
 	vcc.Log.Info("starting VCreateDatabase")
 
 	/*
@@ -293,7 +523,7 @@ func (vcc VClusterCommands) VCreateDatabase(options *VCreateDatabaseOptions) (VC
 	 */
 	// Analyze to produce vdb info, for later create db use and for cache db info
 	vdb := makeVCoordinationDatabase()
-	err := vdb.setFromCreateDBOptions(options, vcc.Log)
+	err = vdb.setFromCreateDBOptions(options, vcc.Log)
 	if err != nil {
 		return vdb, err
 	}
@@ -309,11 +539,41 @@ func (vcc VClusterCommands) VCreateDatabase(options *VCreateDatabaseOptions) (VC
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// Give the instructions to the VClusterOpEngine to run
-	err = clusterOpEngine.run(vcc.Log)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	if err != nil && vcc.hasPartialCreateDBArtifacts(&vdb, options, instructions, clusterOpEngine.numSucceeded) {
+		// the directories are left by a failed attempt, so they are removed
+		// and the database is created again
+		vcc.Log.PrintWarning("Removing the directories left by a failed attempt to create database %s", options.DBName)
+		options.removingPartialArtifacts = true
+		defer func() { options.removingPartialArtifacts = false }()
+		vdb = makeVCoordinationDatabase()
+		err = vdb.setFromCreateDBOptions(options, vcc.Log)
+		if err != nil {
+			return vdb, err
+		}
+		instructions, err = vcc.produceCreateDBInstructions(&vdb, options)
+		if err != nil {
+			vcc.Log.Error(err, "fail to produce create db instructions")
+			return vdb, err
+		}
+		clusterOpEngine = makeClusterOpEngine(instructions, &certs)
+		err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	}
 	if err != nil {
 		vcc.Log.Error(err, "fail to create database")
+		if options.ForceCleanupOnFailure {
+			vcc.cleanupFailedCreateDB(&vdb, options, instructions, clusterOpEngine.numSucceeded)
+		}
 		return vdb, err
 	}
+
+	// the database is created even if its manifest cannot be written
+	if options.ManifestFile != "" {
+		manifest := makeCreateDBManifest(&vdb, options)
+		if e := writeCreateDBManifest(options.ManifestFile, &manifest); e != nil {
+			vcc.Log.PrintWarning("%s", e)
+		}
+	}
 	return vdb, nil
 }
 
@@ -325,6 +585,7 @@ func (vcc VClusterCommands) VCreateDatabase(options *VCreateDatabaseOptions) (VC
 //   - Check NMA connectivity
 //   - Check to see if any dbs running
 //   - Check NMA versions
+//   - Validate configuration parameters
 //   - Prepare directories
 //   - Get network profiles
 //   - Bootstrap the database
@@ -340,6 +601,7 @@ func (vcc VClusterCommands) VCreateDatabase(options *VCreateDatabaseOptions) (VC
 //   - Mark design ksafe
 //   - Install packages
 //   - Sync catalog
+//   - Stop the bootstrap node (no-start only)
 func (vcc VClusterCommands) produceCreateDBInstructions(
 	vdb *VCoordinationDatabase,
 	options *VCreateDatabaseOptions) ([]clusterOp, error) {
@@ -361,6 +623,14 @@ func (vcc VClusterCommands) produceCreateDBInstructions(
 	instructions = append(instructions, workerNodesInstructions...)
 	instructions = append(instructions, additionalInstructions...)
 
+	if options.NoStart {
+		stopInstructions, e := vcc.produceCreateDBStopInstructions(vdb, options)
+		if e != nil {
+			return instructions, e
+		}
+		instructions = append(instructions, stopInstructions...)
+	}
+
 	return instructions, nil
 }
 
@@ -391,7 +661,7 @@ func (vcc VClusterCommands) produceCreateDBBootstrapInstructions(
 	}
 
 	nmaPrepareDirectoriesOp, err := makeNMAPrepareDirectoriesOp(vdb.HostNodeMap,
-		options.ForceRemovalAtCreation, false /*for db revive*/)
+		options.ForceRemovalAtCreation || options.removingPartialArtifacts, false /*for db revive*/)
 	if err != nil {
 		return instructions, err
 	}
@@ -416,15 +686,56 @@ func (vcc VClusterCommands) produceCreateDBBootstrapInstructions(
 		&nmaHealthOp,
 		&nmaVerticaVersionOp,
 		&checkDBRunningOp,
+	)
+
+	// the parameters are validated by the bootstrap host before the hosts are changed
+	if len(options.ConfigurationParameters) > 0 {
+		nmaValidateConfigParamsOp, e := makeNMAValidateConfigParamsOp(bootstrapHost, options.ConfigurationParameters, vdb.IsEon)
+		if e != nil {
+			return instructions, e
+		}
+		instructions = append(instructions, &nmaValidateConfigParamsOp)
+	}
+
+	// the communal storage is checked before the hosts are changed
+	if vdb.IsEon {
+		checkCommunalStorageOps, e := produceCommunalStorageCheckOps(hosts, vdb.CommunalStorageLocation,
+			options.ConfigurationParameters, vdb, options.GCSAuthMethod, false /*for revive*/)
+		if e != nil {
+			return instructions, e
+		}
+		instructions = append(instructions, checkCommunalStorageOps...)
+	}
+
+	instructions = append(instructions,
 		&nmaPrepareDirectoriesOp,
 		&nmaNetworkProfileOp,
+	)
+
+	// the license must be on the bootstrap host before bootstrapping the catalog
+	if options.LicenseFile != "" {
+		var licenseContent string
+		licenseContent, err = readLicenseFile(options.LicenseFile)
+		if err != nil {
+			return instructions, err
+		}
+		nmaDepositLicenseOp := makeNMADepositFileOp(bootstrapHost, licenseContent, options.LicensePathOnNode)
+		instructions = append(instructions, &nmaDepositLicenseOp)
+	}
+
+	instructions = append(instructions,
 		&nmaBootstrapCatalogOp,
 		&nmaReadCatalogEditorOp,
 	)
 
+	// the server TLS files must be in the catalog directories before the first start
+	if options.ServerTLSMode != "" && options.ServerTLSMode != ServerTLSModeDisable {
+		instructions = append(instructions, makeServerTLSDepositOps(vdb, options)...)
+	}
+
 	if enabled, keyType := options.isSpreadEncryptionEnabled(); enabled {
 		instructions = append(instructions,
-			vcc.addEnableSpreadEncryptionOp(keyType),
+			vcc.addEnableSpreadEncryptionOp(keyType, options.spreadSecurityDetails),
 		)
 	}
 
@@ -455,12 +766,15 @@ func (vcc VClusterCommands) produceCreateDBWorkerNodesInstructions(
 
 	newNodeHosts := util.SliceDiff(hosts, bootstrapHost)
 	if len(hosts) > 1 {
-		httpsCreateNodeOp, err := makeHTTPSCreateNodeOp(newNodeHosts, bootstrapHost,
-			true /* use password auth */, options.UserName, options.Password, vdb, "")
-		if err != nil {
-			return instructions, err
+		// the nodes with different path prefixes are created separately
+		for _, newNodeHostGroup := range vdb.groupHostsByPathPrefixes(newNodeHosts) {
+			httpsCreateNodeOp, err := makeHTTPSCreateNodeOp(newNodeHostGroup, bootstrapHost,
+				true /* use password auth */, options.UserName, options.Password, vdb, "")
+			if err != nil {
+				return instructions, err
+			}
+			instructions = append(instructions, &httpsCreateNodeOp)
 		}
-		instructions = append(instructions, &httpsCreateNodeOp)
 	}
 
 	httpsReloadSpreadOp, err := makeHTTPSReloadSpreadOpWithInitiator(bootstrapHost,
@@ -495,8 +809,10 @@ func (vcc VClusterCommands) produceCreateDBWorkerNodesInstructions(
 			bootstrapHost,
 			vdb.HostList,
 			vdb /*db configurations retrieved from a running db*/)
-		nmaStartNewNodesOp := makeNMAStartNodeOpWithVDB(newNodeHosts, options.StartUpConf, vdb)
-		instructions = append(instructions, &nmaStartNewNodesOp)
+		if !options.NoStart {
+			nmaStartNewNodesOp := makeNMAStartNodeOpWithVDB(newNodeHosts, options.StartUpConf, vdb)
+			instructions = append(instructions, &nmaStartNewNodesOp)
+		}
 	}
 
 	return instructions, nil
@@ -511,7 +827,7 @@ func (vcc VClusterCommands) produceAdditionalCreateDBInstructions(vdb *VCoordina
 	bootstrapHost := options.bootstrapHost
 	username := options.UserName
 
-	if !options.SkipStartupPolling {
+	if !options.SkipStartupPolling && !options.NoStart {
 		httpsPollNodeStateOp, err := makeHTTPSPollNodeStateOpWithTimeoutAndCommand(hosts, true, username, options.Password,
 			options.TimeoutNodeStartupSeconds, CreateDBCmd)
 		if err != nil {
@@ -520,7 +836,19 @@ func (vcc VClusterCommands) produceAdditionalCreateDBInstructions(vdb *VCoordina
 		instructions = append(instructions, &httpsPollNodeStateOp)
 	}
 
-	if vdb.UseDepot {
+	if vdb.UseDepot && options.NoStart {
+		// the depot is created on the up nodes, so it waits for the first start
+		vcc.Log.PrintWarning("The depot of database %s is not created as the database is not started. "+
+			"Create it once the database is started", options.DBName)
+	} else if vdb.UseDepot && vdb.hasHostDepotPrefixes() {
+		// a cluster depot has the same path on all the nodes,
+		// so the depots are created node by node
+		httpsCreateNodesDepotOp, err := makeHTTPSCreateNodesDepotOp(vdb, hosts, true, username, options.Password)
+		if err != nil {
+			return instructions, err
+		}
+		instructions = append(instructions, &httpsCreateNodesDepotOp)
+	} else if vdb.UseDepot {
 		httpsCreateDepotOp, err := makeHTTPSCreateClusterDepotOp(vdb, bootstrapHost, true, username, options.Password)
 		if err != nil {
 			return instructions, err
@@ -556,8 +884,37 @@ func (vcc VClusterCommands) produceAdditionalCreateDBInstructions(vdb *VCoordina
 	return instructions, nil
 }
 
-func (vcc VClusterCommands) addEnableSpreadEncryptionOp(keyType string) clusterOp {
+// produceCreateDBStopInstructions returns the instructions that stop the
+// bootstrap node of a database that is created without being started
+func (vcc VClusterCommands) produceCreateDBStopInstructions(vdb *VCoordinationDatabase,
+	options *VCreateDatabaseOptions) ([]clusterOp, error) {
+	var instructions []clusterOp
+
+	httpsGetUpNodesOp, err := makeHTTPSGetUpNodesOp(options.DBName, options.bootstrapHost,
+		true /* use password auth */, options.UserName, options.Password, StopDBCmd)
+	if err != nil {
+		return instructions, err
+	}
+	httpsStopDBOp, err := makeHTTPSStopDBOp(true /* use password auth */, options.UserName, options.Password,
+		nil /* timeout */, "" /* sandbox */, false /* main cluster */)
+	if err != nil {
+		return instructions, err
+	}
+	httpsCheckDBRunningOp, err := makeHTTPSCheckRunningDBOp(vdb.HostList, true, /* use password auth */
+		options.UserName, options.Password, StopDB)
+	if err != nil {
+		return instructions, err
+	}
+	instructions = append(instructions, &httpsGetUpNodesOp, &httpsStopDBOp, &httpsCheckDBRunningOp)
+
+	return instructions, nil
+}
+
+// addEnableSpreadEncryptionOp adds the op that sets the key of the spread
+// encryption. The key is generated by the op if securityDetails is empty.
+func (vcc VClusterCommands) addEnableSpreadEncryptionOp(keyType, securityDetails string) clusterOp {
 	vcc.Log.Info("adding instruction to set key for spread encryption")
 	op := makeNMASpreadSecurityOp(vcc.Log, keyType)
+	op.securityDetails = securityDetails
 	return &op
 }
@@ -0,0 +1,153 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vertica/vcluster/rfc7807"
+)
+
+// findInstruction returns the index of the first instruction of type T, or -1
+// if there is none
+func findInstruction[T clusterOp](instructions []clusterOp) int {
+	for i, op := range instructions {
+		if _, ok := op.(T); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasPartialCreateDBArtifacts checks whether create_db failed because its
+// directories already exist, and they are left by a failed attempt to create
+// the database. The directories are left by a failed attempt if none of the
+// hosts has a catalog in them, because a database that was created, even if
+// it is down, has a catalog. No database is running on the hosts, as that is
+// checked before the directories are prepared. Nothing is removed unless the
+// caller sets RemovePartialArtifacts.
+func (vcc VClusterCommands) hasPartialCreateDBArtifacts(vdb *VCoordinationDatabase,
+	options *VCreateDatabaseOptions, instructions []clusterOp, numSucceeded int) bool {
+	if !options.RemovePartialArtifacts || options.ForceRemovalAtCreation || options.removingPartialArtifacts {
+		return false
+	}
+	prepareIndex := findInstruction[*nmaPrepareDirectoriesOp](instructions)
+	if prepareIndex < 0 || prepareIndex != numSucceeded {
+		return false
+	}
+	// the directories of other failures, such as a permission error, are not
+	// left by create_db
+	if !instructions[prepareIndex].(*nmaPrepareDirectoriesOp).failedOnExistingDirs {
+		return false
+	}
+
+	nmaReadCatalogEditorOp, err := makeNMAReadCatalogEditorOp(vdb)
+	if err != nil {
+		return false
+	}
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+	clusterOpEngine := makeClusterOpEngine([]clusterOp{&nmaReadCatalogEditorOp}, &certs)
+	// the op fails as no host has a catalog, so only the result of each host is checked
+	_ = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	err = checkNoCatalog(vdb.HostList, nmaReadCatalogEditorOp.clusterHTTPRequest.ResultCollection)
+	if err != nil {
+		vcc.Log.PrintWarning("The directories of database %s will not be removed: %s", options.DBName, err)
+		return false
+	}
+	return true
+}
+
+// checkNoCatalog returns an error unless each host has answered that its catalog
+// directory is empty or does not exist. A host that has a catalog, or whose
+// catalog cannot be read, may have a database that must not be removed.
+func checkNoCatalog(hosts []string, results map[string]hostHTTPResult) error {
+	for _, host := range hosts {
+		result, ok := results[host]
+		if !ok {
+			return fmt.Errorf("cannot read the catalog on host %s", host)
+		}
+		if result.isPassing() {
+			return fmt.Errorf("found a catalog on host %s", host)
+		}
+		if !isCatalogNotFoundError(result.err) {
+			return fmt.Errorf("cannot read the catalog on host %s: %w", host, result.err)
+		}
+	}
+	return nil
+}
+
+// isCatalogNotFoundError returns true if the NMA did not find a catalog in the
+// catalog directory of a host
+func isCatalogNotFoundError(err error) bool {
+	problem := &rfc7807.VProblem{}
+	return errors.As(err, &problem) &&
+		(problem.ProblemID == rfc7807.CECatalogDirEmptyError || problem.ProblemID == rfc7807.CatalogPathNotExistError)
+}
+
+// cleanupFailedCreateDB removes the directories that a failed create_db has
+// prepared, so that create_db can run again. The nodes that were started are
+// stopped first. The cleanup is best effort: its errors are logged, and the
+// error of create_db is returned to the caller.
+func (vcc VClusterCommands) cleanupFailedCreateDB(vdb *VCoordinationDatabase,
+	options *VCreateDatabaseOptions, instructions []clusterOp, numSucceeded int) {
+	prepareIndex := findInstruction[*nmaPrepareDirectoriesOp](instructions)
+	if prepareIndex < 0 || prepareIndex >= numSucceeded {
+		// the directories were not prepared by this attempt
+		return
+	}
+	vcc.Log.PrintWarning("Cleaning up the directories of database %s after the failure", options.DBName)
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+
+	startIndex := findInstruction[*nmaStartNodeOp](instructions)
+	if startIndex >= 0 && startIndex <= numSucceeded {
+		if err := vcc.stopFailedCreateDB(vdb, options, &certs); err != nil {
+			vcc.Log.PrintWarning("Fail to stop the nodes of database %s: %s", options.DBName, err)
+		}
+	}
+
+	nmaDeleteDirectoriesOp, err := makeNMADeleteDirectoriesOp(vdb, true /* force delete */)
+	if err == nil {
+		clusterOpEngine := makeClusterOpEngine([]clusterOp{&nmaDeleteDirectoriesOp}, &certs)
+		err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	}
+	if err != nil {
+		vcc.Log.PrintWarning("Fail to remove the directories of database %s, they must be removed "+
+			"before creating the database again: %s", options.DBName, err)
+	}
+}
+
+// stopFailedCreateDB stops the nodes that a failed create_db has started
+func (vcc VClusterCommands) stopFailedCreateDB(vdb *VCoordinationDatabase,
+	options *VCreateDatabaseOptions, certs *httpsCerts) error {
+	httpsGetUpNodesOp, err := makeHTTPSGetUpNodesOp(options.DBName, vdb.HostList,
+		true /* use password auth */, options.UserName, options.Password, StopDBCmd)
+	if err != nil {
+		return err
+	}
+	httpsStopDBOp, err := makeHTTPSStopDBOp(true /* use password auth */, options.UserName, options.Password,
+		nil /* timeout */, "" /* sandbox */, false /* main cluster */)
+	if err != nil {
+		return err
+	}
+	httpsCheckDBRunningOp, err := makeHTTPSCheckRunningDBOp(vdb.HostList, true, /* use password auth */
+		options.UserName, options.Password, StopDB)
+	if err != nil {
+		return err
+	}
+	clusterOpEngine := makeClusterOpEngine([]clusterOp{&httpsGetUpNodesOp, &httpsStopDBOp, &httpsCheckDBRunningOp}, certs)
+	return clusterOpEngine.run(vcc.getContext(), vcc.Log)
+}
@@ -0,0 +1,130 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const manifestFilePerm = 0600
+
+// CreateDBManifest records what create_db created, so that it can be audited
+// and given to the tools that revive or create the database again. The
+// secrets, such as the credentials in the parameters, are masked.
+type CreateDBManifest struct {
+	DBName    string    `json:"db_name"`
+	CreatedAt time.Time `json:"created_at"`
+	IsEon     bool      `json:"is_eon"`
+	// Eon params, empty in an Enterprise database
+	CommunalStorageLocation string `json:"communal_storage_location,omitempty"`
+	NumShards               int    `json:"num_shards,omitempty"`
+	DepotSize               string `json:"depot_size,omitempty"`
+
+	CatalogPrefix string                 `json:"catalog_prefix"`
+	DataPrefix    string                 `json:"data_prefix"`
+	DepotPrefix   string                 `json:"depot_prefix,omitempty"`
+	Parameters    map[string]string      `json:"parameters,omitempty"`
+	ControlNodes  []string               `json:"control_nodes,omitempty"`
+	Nodes         []CreateDBManifestNode `json:"nodes"`
+	// whether the database was left stopped
+	NoStart bool `json:"no_start,omitempty"`
+}
+
+// CreateDBManifestNode is a node that create_db created
+type CreateDBManifestNode struct {
+	Name             string   `json:"name"`
+	Address          string   `json:"address"`
+	Port             int      `json:"port"`
+	IsPrimary        bool     `json:"is_primary"`
+	Subcluster       string   `json:"subcluster,omitempty"`
+	CatalogPath      string   `json:"catalog_path"`
+	StorageLocations []string `json:"storage_locations"`
+	DepotPath        string   `json:"depot_path,omitempty"`
+}
+
+// makeCreateDBManifest returns the manifest of a created database, with the
+// nodes in the order of the hosts
+func makeCreateDBManifest(vdb *VCoordinationDatabase, options *VCreateDatabaseOptions) CreateDBManifest {
+	manifest := CreateDBManifest{
+		DBName:                  vdb.Name,
+		CreatedAt:               time.Now().UTC(),
+		IsEon:                   vdb.IsEon,
+		CommunalStorageLocation: vdb.CommunalStorageLocation,
+		NumShards:               vdb.NumShards,
+		DepotSize:               vdb.DepotSize,
+		CatalogPrefix:           vdb.CatalogPrefix,
+		DataPrefix:              vdb.DataPrefix,
+		DepotPrefix:             vdb.DepotPrefix,
+		ControlNodes:            options.controlNodeHosts,
+		Nodes:                   []CreateDBManifestNode{},
+		NoStart:                 options.NoStart,
+	}
+	if len(options.ConfigurationParameters) > 0 {
+		// the parameters are copied as they are masked in place
+		maskedData := sensitiveFields{Parameters: make(map[string]string, len(options.ConfigurationParameters))}
+		for name, value := range options.ConfigurationParameters {
+			maskedData.Parameters[name] = value
+		}
+		maskedData.maskSensitiveInfo()
+		manifest.Parameters = maskedData.Parameters
+	}
+	for _, host := range vdb.HostList {
+		vnode, ok := vdb.HostNodeMap[host]
+		if !ok {
+			continue
+		}
+		manifest.Nodes = append(manifest.Nodes, CreateDBManifestNode{
+			Name:             vnode.Name,
+			Address:          vnode.Address,
+			Port:             vnode.Port,
+			IsPrimary:        vnode.IsPrimary,
+			Subcluster:       vnode.Subcluster,
+			CatalogPath:      vnode.CatalogPath,
+			StorageLocations: vnode.StorageLocations,
+			DepotPath:        vnode.DepotPath,
+		})
+	}
+	return manifest
+}
+
+// writeCreateDBManifest writes the manifest of a created database as JSON
+func writeCreateDBManifest(path string, manifest *CreateDBManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("fail to marshal the manifest of database %s, details: %w", manifest.DBName, err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), manifestFilePerm); err != nil {
+		return fmt.Errorf("fail to write the manifest of database %s to %s, details: %w", manifest.DBName, path, err)
+	}
+	return nil
+}
+
+// ReadCreateDBManifest reads the manifest that create_db wrote, such as to
+// revive the database or to create it again
+func ReadCreateDBManifest(path string) (*CreateDBManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read the manifest file %s, details: %w", path, err)
+	}
+	manifest := CreateDBManifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("fail to parse the manifest file %s, details: %w", path, err)
+	}
+	return &manifest, nil
+}
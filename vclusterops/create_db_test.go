@@ -16,9 +16,21 @@
 package vclusterops
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/rfc7807"
 )
 
 const defaultPath = "/data"
@@ -51,3 +63,328 @@ func TestValidateDepotSize(t *testing.T) {
 	assert.Equal(t, res, true)
 	assert.Nil(t, err)
 }
+
+func TestHostPathPrefixes(t *testing.T) {
+	options := VCreateDatabaseOptionsFactory()
+	options.DBName = "test_db"
+	options.Hosts = []string{"192.168.1.101", "192.168.1.102", "192.168.1.103"}
+	options.CatalogPrefix = defaultPath
+	options.DataPrefix = defaultPath
+	options.HostPathPrefixes = map[string]HostPathPrefixes{
+		"192.168.1.102": {DataPrefix: "/disk2/"},
+	}
+	err := options.analyzeHostPathPrefixes(options.Hosts)
+	assert.NoError(t, err)
+	assert.Equal(t, "/disk2", options.HostPathPrefixes["192.168.1.102"].DataPrefix)
+
+	vdb := makeVCoordinationDatabase()
+	err = vdb.setFromBasicDBOptions(&options)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/disk2/test_db/v_test_db_node0002_data"},
+		vdb.HostNodeMap["192.168.1.102"].StorageLocations)
+	// the catalog path of the host is not overridden
+	assert.Equal(t, "/data/test_db/v_test_db_node0002_catalog",
+		vdb.HostNodeMap["192.168.1.102"].CatalogPath)
+	assert.Equal(t, [][]string{{"192.168.1.101", "192.168.1.103"}, {"192.168.1.102"}},
+		vdb.groupHostsByPathPrefixes(options.Hosts))
+
+	// the hosts with path prefixes must be in the database
+	options.HostPathPrefixes["192.168.1.104"] = HostPathPrefixes{CatalogPrefix: "/disk2"}
+	err = options.analyzeHostPathPrefixes(options.Hosts)
+	assert.ErrorContains(t, err, "host 192.168.1.104 has path prefixes but is not one of the hosts")
+
+	// the path prefixes must be absolute paths
+	options.HostPathPrefixes = map[string]HostPathPrefixes{"192.168.1.101": {CatalogPrefix: "disk2"}}
+	err = options.analyzeHostPathPrefixes(options.Hosts)
+	assert.Error(t, err)
+}
+
+// makeTestServerTLSPair returns a self-signed key and certificate in PEM
+func makeTestServerTLSPair(t *testing.T) (key, cert string) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "vertica"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(privateKey)
+	assert.NoError(t, err)
+	key = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	cert = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}))
+	return key, cert
+}
+
+func TestServerTLSOptions(t *testing.T) {
+	key, cert := makeTestServerTLSPair(t)
+
+	// the mode is DISABLE by default, and ENABLE if a key is given
+	opt := VCreateDatabaseOptionsFactory()
+	assert.NoError(t, opt.validateServerTLSOptions())
+	assert.Equal(t, ServerTLSModeDisable, opt.ServerTLSMode)
+	opt.ServerTLSMode = ""
+	opt.ServerTLSKey = key
+	opt.ServerTLSCert = cert
+	assert.NoError(t, opt.validateServerTLSOptions())
+	assert.Equal(t, ServerTLSModeEnable, opt.ServerTLSMode)
+
+	// the mode is case insensitive
+	opt.ServerTLSMode = "enable"
+	assert.NoError(t, opt.validateServerTLSOptions())
+	assert.Equal(t, ServerTLSModeEnable, opt.ServerTLSMode)
+
+	opt.ServerTLSMode = "REQUIRE"
+	assert.ErrorContains(t, opt.validateServerTLSOptions(), "invalid server TLS mode")
+
+	// the verify modes need a CA certificate
+	opt.ServerTLSMode = ServerTLSModeVerifyCA
+	assert.ErrorContains(t, opt.validateServerTLSOptions(), "must specify the server TLS CA certificate")
+	opt.ServerTLSCACert = "not a certificate"
+	assert.Error(t, opt.validateServerTLSOptions())
+	opt.ServerTLSCACert = cert
+	assert.NoError(t, opt.validateServerTLSOptions())
+
+	// the key must match the certificate
+	otherKey, _ := makeTestServerTLSPair(t)
+	opt.ServerTLSKey = otherKey
+	assert.ErrorContains(t, opt.validateServerTLSOptions(), "not a valid pair")
+
+	opt.ServerTLSMode = ServerTLSModeDisable
+	assert.ErrorContains(t, opt.validateServerTLSOptions(), "cannot be given")
+
+	// the files are deposited to the catalog directory of each node
+	opt.ServerTLSKey = key
+	opt.ServerTLSCACert = ""
+	vdb := makeVCoordinationDatabase()
+	vdb.HostList = []string{"192.168.1.101", "192.168.1.102"}
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{CatalogPath: "/data/test_db/v_test_db_node0001_catalog"}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{CatalogPath: "/data/test_db/v_test_db_node0002_catalog"}
+	instructions := makeServerTLSDepositOps(&vdb, &opt)
+	assert.Len(t, instructions, 2)
+	keyOp, ok := instructions[0].(*nmaDepositFileOp)
+	assert.True(t, ok)
+	assert.Equal(t, vdb.HostList, keyOp.hosts)
+	assert.Equal(t, key, keyOp.fileContent)
+	assert.Equal(t, "/data/test_db/v_test_db_node0002_catalog/server.key", keyOp.hostDestinations["192.168.1.102"])
+}
+
+func TestSpreadEncryptionOptions(t *testing.T) {
+	// the encryption is disabled by default
+	opt := VCreateDatabaseOptionsFactory()
+	assert.NoError(t, opt.validateSpreadEncryptionOptions())
+	assert.NoError(t, opt.analyzeSpreadEncryption())
+	enabled, _ := opt.isSpreadEncryptionEnabled()
+	assert.False(t, enabled)
+	assert.Empty(t, opt.spreadSecurityDetails)
+
+	// the key must be 32 hex-encoded bytes
+	opt.SpreadEncryptionKey = "not a key"
+	assert.ErrorContains(t, opt.validateSpreadEncryptionOptions(), "32 hex-encoded bytes")
+	opt.SpreadEncryptionKey = "abcd"
+	assert.ErrorContains(t, opt.validateSpreadEncryptionOptions(), "32 hex-encoded bytes")
+
+	// a key enables the encryption, and is given to the bootstrap
+	key := strings.Repeat("0a", spreadKeySize)
+	opt.SpreadEncryptionKey = key
+	assert.NoError(t, opt.validateSpreadEncryptionOptions())
+	assert.True(t, opt.EnableSpreadEncryption)
+	assert.NoError(t, opt.analyzeSpreadEncryption())
+	assert.Equal(t, spreadKeyTypeVertica, opt.ConfigurationParameters[encryptSpreadCommParam])
+	assert.Regexp(t, `^\{\\"[0-9a-f]{4}\\":\\"`+key+`\\"\}$`, opt.spreadSecurityDetails)
+
+	// the key is masked in the logs
+	maskedData := sensitiveFields{SpreadSecurityDetails: opt.spreadSecurityDetails}
+	maskedData.maskSensitiveInfo()
+	assert.NotContains(t, maskedData.SpreadSecurityDetails, key)
+
+	// a key cannot be given with another key type
+	opt.ConfigurationParameters = map[string]string{"encryptSpreadComm": "aws-kms"}
+	assert.ErrorContains(t, opt.validateSpreadEncryptionOptions(), "spread key type vertica")
+
+	// a key is generated if none is given
+	opt = VCreateDatabaseOptionsFactory()
+	opt.EnableSpreadEncryption = true
+	assert.NoError(t, opt.validateSpreadEncryptionOptions())
+	assert.NoError(t, opt.analyzeSpreadEncryption())
+	assert.Regexp(t, `^\{\\"[0-9a-f]{4}\\":\\"[0-9a-f]{64}\\"\}$`, opt.spreadSecurityDetails)
+}
+
+func TestCreateDBPartialArtifacts(t *testing.T) {
+	vcc := VClusterCommands{}
+	opt := VCreateDatabaseOptionsFactory()
+	nmaHealthOp := makeNMAHealthOp([]string{"192.168.1.101"})
+	prepareDirectoriesOp := nmaPrepareDirectoriesOp{}
+	startNodeOp := makeNMAStartNodeOp([]string{"192.168.1.101"}, "")
+	instructions := []clusterOp{&nmaHealthOp, &prepareDirectoriesOp, &startNodeOp}
+
+	assert.Equal(t, 1, findInstruction[*nmaPrepareDirectoriesOp](instructions))
+	assert.Equal(t, 2, findInstruction[*nmaStartNodeOp](instructions))
+	assert.Equal(t, -1, findInstruction[*nmaDeleteDirectoriesOp](instructions))
+
+	// the directories are not removed unless the caller opts in
+	vdb := makeVCoordinationDatabase()
+	prepareDirectoriesOp.failedOnExistingDirs = true
+	assert.False(t, vcc.hasPartialCreateDBArtifacts(&vdb, &opt, instructions, 1))
+	opt.RemovePartialArtifacts = true
+	// the directories are not checked if create_db does not fail to prepare them
+	assert.False(t, vcc.hasPartialCreateDBArtifacts(&vdb, &opt, instructions, 0))
+	assert.False(t, vcc.hasPartialCreateDBArtifacts(&vdb, &opt, instructions, 2))
+	// or if it fails for another reason than existing directories
+	prepareDirectoriesOp.failedOnExistingDirs = false
+	assert.False(t, vcc.hasPartialCreateDBArtifacts(&vdb, &opt, instructions, 1))
+	// or if they are removed anyway
+	prepareDirectoriesOp.failedOnExistingDirs = true
+	opt.ForceRemovalAtCreation = true
+	assert.False(t, vcc.hasPartialCreateDBArtifacts(&vdb, &opt, instructions, 1))
+}
+
+func TestPrepareDirectoriesExistingDirs(t *testing.T) {
+	existErr := rfc7807.New(rfc7807.CreateDirectoryExistError).WithHost("192.168.1.101")
+	permissionErr := rfc7807.New(rfc7807.CreateDirectoryPermissionDenied).WithHost("192.168.1.102")
+
+	// only the directories that exist fail
+	op := nmaPrepareDirectoriesOp{}
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: FAILURE, err: existErr},
+		"192.168.1.102": {status: SUCCESS, content: `{"/data/test_db": "created"}`},
+	}
+	assert.Error(t, op.processResult(nil))
+	assert.True(t, op.failedOnExistingDirs)
+
+	// a permission error is not left by create_db
+	op.clusterHTTPRequest.ResultCollection["192.168.1.102"] = hostHTTPResult{status: FAILURE, err: permissionErr}
+	assert.Error(t, op.processResult(nil))
+	assert.False(t, op.failedOnExistingDirs)
+}
+
+func TestCheckNoCatalog(t *testing.T) {
+	hosts := []string{"192.168.1.101", "192.168.1.102"}
+	emptyErr := rfc7807.New(rfc7807.CECatalogDirEmptyError)
+	notExistErr := rfc7807.New(rfc7807.CatalogPathNotExistError)
+
+	// no host has a catalog
+	results := map[string]hostHTTPResult{
+		"192.168.1.101": {status: FAILURE, err: emptyErr},
+		"192.168.1.102": {status: FAILURE, err: notExistErr},
+	}
+	assert.NoError(t, checkNoCatalog(hosts, results))
+
+	// a host has a catalog
+	results["192.168.1.102"] = hostHTTPResult{status: SUCCESS, content: "{}"}
+	assert.ErrorContains(t, checkNoCatalog(hosts, results), "found a catalog on host 192.168.1.102")
+
+	// the catalog of a host cannot be read, so it may exist
+	results["192.168.1.102"] = hostHTTPResult{status: EXCEPTION, err: errors.New("connection refused")}
+	assert.ErrorContains(t, checkNoCatalog(hosts, results), "cannot read the catalog on host 192.168.1.102")
+
+	// a host did not answer
+	delete(results, "192.168.1.102")
+	assert.ErrorContains(t, checkNoCatalog(hosts, results), "cannot read the catalog on host 192.168.1.102")
+}
+
+func TestCreateDBNoStart(t *testing.T) {
+	vcc := VClusterCommands{}
+	options := VCreateDatabaseOptionsFactory()
+	options.DBName = "test_db"
+	options.UserName = "dbadmin"
+	options.Password = new(string)
+	options.Hosts = []string{"192.168.1.101", "192.168.1.102", "192.168.1.103"}
+	options.CatalogPrefix = defaultPath
+	options.DataPrefix = defaultPath
+	options.bootstrapHost = []string{"192.168.1.101"}
+	options.NoStart = true
+	vdb := makeVCoordinationDatabase()
+	assert.NoError(t, vdb.setFromBasicDBOptions(&options))
+
+	// the new nodes get their config files but are not started
+	instructions, err := vcc.produceCreateDBWorkerNodesInstructions(&vdb, &options)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, findInstruction[*nmaStartNodeOp](instructions))
+	assert.NotEqual(t, -1, findInstruction[*nmaUploadConfigOp](instructions))
+
+	instructions, err = vcc.produceAdditionalCreateDBInstructions(&vdb, &options)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, findInstruction[*httpsPollNodeStateOp](instructions))
+
+	// the bootstrap node is stopped at the end
+	instructions, err = vcc.produceCreateDBStopInstructions(&vdb, &options)
+	assert.NoError(t, err)
+	assert.NotEqual(t, -1, findInstruction[*httpsStopDBOp](instructions))
+	checkRunningOp, ok := instructions[len(instructions)-1].(*httpsCheckRunningDBOp)
+	assert.True(t, ok)
+	assert.Equal(t, vdb.HostList, checkRunningOp.hosts)
+}
+
+func TestCreateDBManifest(t *testing.T) {
+	options := VCreateDatabaseOptionsFactory()
+	options.DBName = "test_db"
+	options.Hosts = []string{"192.168.1.101", "192.168.1.102"}
+	options.CatalogPrefix = defaultPath
+	options.DataPrefix = defaultPath
+	options.ConfigurationParameters = map[string]string{"MaxClientSessions": "100", "awsauth": "id:secret"}
+	vdb := makeVCoordinationDatabase()
+	assert.NoError(t, vdb.setFromBasicDBOptions(&options))
+
+	manifest := makeCreateDBManifest(&vdb, &options)
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	assert.NoError(t, writeCreateDBManifest(path, &manifest))
+	read, err := ReadCreateDBManifest(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "test_db", read.DBName)
+	assert.Len(t, read.Nodes, 2)
+	assert.Equal(t, "192.168.1.102", read.Nodes[1].Address)
+	assert.Equal(t, vdb.HostNodeMap["192.168.1.102"].CatalogPath, read.Nodes[1].CatalogPath)
+
+	// the secrets are masked, but not in the options
+	assert.Equal(t, "100", read.Parameters["MaxClientSessions"])
+	assert.NotContains(t, read.Parameters["awsauth"], "secret")
+	assert.Equal(t, "id:secret", options.ConfigurationParameters["awsauth"])
+}
+
+func TestControlNodePolicy(t *testing.T) {
+	options := VCreateDatabaseOptionsFactory()
+	options.Hosts = []string{"192.168.1.101", "192.168.1.102", "192.168.1.103", "192.168.1.104", "192.168.1.105"}
+
+	// the server chooses the control nodes by default
+	assert.NoError(t, options.analyzeControlNodes())
+	assert.Equal(t, ControlNodePolicyAuto, options.ControlNodePolicy)
+	assert.Empty(t, options.controlNodeHosts)
+	options.ControlNodes = []string{"192.168.1.101"}
+	assert.ErrorContains(t, options.analyzeControlNodes(), "can only be given")
+
+	options.ControlNodePolicy = "random"
+	assert.ErrorContains(t, options.analyzeControlNodes(), "invalid control node policy")
+
+	// the control nodes are spread across the racks, starting with the bootstrap host
+	options.ControlNodes = nil
+	options.ControlNodePolicy = ControlNodePolicyRack
+	options.LargeCluster = 3
+	options.HostControlGroups = map[string]string{
+		"192.168.1.101": "rack2", "192.168.1.102": "rack2", "192.168.1.103": "rack1",
+		"192.168.1.104": "rack1",
+	}
+	assert.ErrorContains(t, options.analyzeControlNodes(), "must specify the group of the hosts [192.168.1.105]")
+	options.HostControlGroups["192.168.1.105"] = "rack1"
+	assert.NoError(t, options.analyzeControlNodes())
+	assert.Equal(t, []string{"192.168.1.101", "192.168.1.103", "192.168.1.102"}, options.controlNodeHosts)
+
+	// all the hosts are control nodes if there are fewer hosts than control nodes
+	options.LargeCluster = -1
+	options.ControlNodePolicy = ControlNodePolicyFaultDomain
+	assert.NoError(t, options.analyzeControlNodes())
+	assert.Len(t, options.controlNodeHosts, 5)
+
+	options.HostControlGroups = nil
+	options.ControlNodePolicy = ControlNodePolicyExplicit
+	options.ControlNodes = []string{"192.168.1.102", "192.168.1.104"}
+	assert.ErrorContains(t, options.analyzeControlNodes(), "the first host 192.168.1.101 must be a control node")
+	options.ControlNodes = []string{"192.168.1.101", "192.168.1.106"}
+	assert.ErrorContains(t, options.analyzeControlNodes(), "is not one of the hosts")
+	options.ControlNodes = []string{"192.168.1.101", "192.168.1.104"}
+	assert.NoError(t, options.analyzeControlNodes())
+	assert.Equal(t, options.ControlNodes, options.controlNodeHosts)
+}
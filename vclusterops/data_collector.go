@@ -0,0 +1,172 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// human description of the formats for the time range of DC records
+const DataCollectorHelpTimeFormatDesc = "'YYYY-MM-DD HH:MM:SS [+/-XX]', with UTC hour offset '+/-XX' optional"
+
+// DC table names, e.g. dc_requests_issued
+var dcTableNameRegex = regexp.MustCompile(`^dc_[a-z0-9_]+$`)
+
+type VGetDataCollectorOptions struct {
+	DatabaseOptions
+	ID          string // generated: "VerticaDataCollector.yyyymmddhhmmss"
+	TarballName string // final tarball name, defaults to the ID
+	// the DC tables to download, e.g. dc_requests_issued
+	Tables []string
+	// only the records in the time range are downloaded, an empty time leaves
+	// that end of the range open
+	StartTime string
+	EndTime   string
+
+	timeFormats []util.TimeFormat // generated by factory
+	// the time range converted to RFC 3339 in UTC, for the NMA
+	startTimeUTC string
+	endTimeUTC   string
+}
+
+func VGetDataCollectorOptionsFactory() VGetDataCollectorOptions {
+	opt := VGetDataCollectorOptions{}
+	opt.setDefaultValues()
+	return opt
+}
+
+func (options *VGetDataCollectorOptions) setDefaultValues() {
+	options.DatabaseOptions.setDefaultValues()
+
+	const timeFmt = "20060102150405" // using fixed reference time from pkg 'time'
+	options.ID = "VerticaDataCollector." + time.Now().Format(timeFmt)
+
+	// if these are changed, the help format string must also be changed
+	noTZFormat := util.TimeFormat{Layout: time.DateTime, UseLocalTZ: true}
+	tzFormat := util.TimeFormat{Layout: time.DateTime + " -07"}
+	options.timeFormats = []util.TimeFormat{noTZFormat, tzFormat}
+}
+
+func (options *VGetDataCollectorOptions) validateParseOptions(logger vlog.Printer) error {
+	err := options.validateBaseOptions(commandGetDataCollector, logger)
+	if err != nil {
+		return err
+	}
+	err = options.validateCatalogPath()
+	if err != nil {
+		return err
+	}
+
+	if len(options.Tables) == 0 {
+		return fmt.Errorf("must specify at least one DC table")
+	}
+	for _, table := range options.Tables {
+		if !dcTableNameRegex.MatchString(table) {
+			return fmt.Errorf("invalid DC table name %q, DC table names are in lowercase and start with dc_", table)
+		}
+	}
+	return nil
+}
+
+// analyzeOptions resolves the hosts and converts the time range for the NMA
+func (options *VGetDataCollectorOptions) analyzeOptions() error {
+	err := options.resolveHosts()
+	if err != nil {
+		return err
+	}
+
+	if options.TarballName == "" {
+		options.TarballName = options.ID
+	}
+
+	var startTime, endTime time.Time
+	options.startTimeUTC, options.endTimeUTC = "", ""
+	if options.StartTime != "" {
+		startTime, err = util.ParseTime(options.StartTime, options.timeFormats)
+		if err != nil {
+			return fmt.Errorf("unable to parse start time '%s' according to allowed format %s",
+				options.StartTime, DataCollectorHelpTimeFormatDesc)
+		}
+		options.startTimeUTC = startTime.UTC().Format(time.RFC3339)
+	}
+	if options.EndTime != "" {
+		endTime, err = util.ParseTime(options.EndTime, options.timeFormats)
+		if err != nil {
+			return fmt.Errorf("unable to parse end time '%s' according to allowed format %s",
+				options.EndTime, DataCollectorHelpTimeFormatDesc)
+		}
+		options.endTimeUTC = endTime.UTC().Format(time.RFC3339)
+	}
+	if options.StartTime != "" && options.EndTime != "" && !startTime.Before(endTime) {
+		return fmt.Errorf("invalid time range: the start time must be before the end time")
+	}
+
+	return nil
+}
+
+func (options *VGetDataCollectorOptions) validateAnalyzeOptions(logger vlog.Printer) error {
+	if err := options.validateParseOptions(logger); err != nil {
+		return err
+	}
+	return options.analyzeOptions()
+}
+
+// VGetDataCollector downloads the records of the given DC tables from all nodes with a
+// running NMA, and bundles them in a tarball under ScrutinizeOutputBasePath. It is a
+// lightweight alternative to scrutinize for performance investigations. The database
+// does not need to be up.
+func (vcc VClusterCommands) VGetDataCollector(options *VGetDataCollectorOptions) error {
+	err := options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	vdb := makeVCoordinationDatabase()
+	err = options.getVDBForScrutinize(vcc.getContext(), vcc.Log, &vdb)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve cluster info: %w", err)
+	}
+	// from now on, use hosts with healthy NMA
+	options.Hosts = vdb.HostList
+
+	hostNodeNameMap, hostCatPathMap, err := getNodeInfoForScrutinize(options.Hosts, &vdb)
+	if err != nil {
+		return fmt.Errorf("failed to process retrieved node info, details %w", err)
+	}
+
+	stageDCTablesOp, err := makeNMAStageDCTablesOpWithFilter(options.ID, options.Hosts,
+		hostNodeNameMap, hostCatPathMap, options.Tables, options.startTimeUTC, options.endTimeUTC)
+	if err != nil {
+		return err
+	}
+	getTarballOp, err := makeNMAGetScrutinizeTarOp(options.ID, scrutinizeBatchNormal,
+		options.Hosts, hostNodeNameMap)
+	if err != nil {
+		return err
+	}
+
+	err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, []clusterOp{&stageDCTablesOp, &getTarballOp})
+	if err != nil {
+		return fmt.Errorf("fail to download DC tables: %w", err)
+	}
+
+	return tarAndRemoveDirectory(options.TarballName, options.ID, ScrutinizeCompressionNone, vcc.Log)
+}
@@ -0,0 +1,56 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestGetDataCollectorValidateOptions(t *testing.T) {
+	options := VGetDataCollectorOptionsFactory()
+	options.DBName = "test_db"
+	options.RawHosts = []string{"192.168.1.101"}
+	options.CatalogPrefix = "/data"
+
+	err := options.validateAnalyzeOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "must specify at least one DC table")
+
+	options.Tables = []string{"dc_requests_issued", "requests_issued"}
+	err = options.validateAnalyzeOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, `invalid DC table name "requests_issued"`)
+
+	options.Tables = []string{"dc_requests_issued"}
+	options.StartTime = "2024-03-01 10:00"
+	err = options.validateAnalyzeOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "unable to parse start time")
+
+	options.StartTime = "2024-03-01 12:00:00 +00"
+	options.EndTime = "2024-03-01 10:00:00 +00"
+	err = options.validateAnalyzeOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "the start time must be before the end time")
+
+	// the time range is sent to the NMA in UTC
+	options.StartTime = "2024-03-01 10:00:00 -05"
+	options.EndTime = ""
+	err = options.validateAnalyzeOptions(vlog.Printer{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-03-01T15:00:00Z", options.startTimeUTC)
+	assert.Equal(t, "", options.endTimeUTC)
+	assert.Equal(t, options.ID, options.TarballName)
+}
@@ -0,0 +1,153 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"golang.org/x/exp/slices"
+)
+
+// DepositFile is a local file and the path it is deposited to on the hosts
+type DepositFile struct {
+	LocalPath   string
+	Destination string
+}
+
+// VDepositFilesOptions represents the available options for VDepositFiles. The
+// files are deposited on each host of the DatabaseOptions.
+type VDepositFilesOptions struct {
+	DatabaseOptions
+	Files []DepositFile
+}
+
+// FileDepositResult is the outcome of depositing a file on a host
+type FileDepositResult struct {
+	Host        string `json:"host"`
+	Destination string `json:"destination"`
+	// the sha256 checksum of the file content
+	Checksum  string `json:"checksum"`
+	Succeeded bool   `json:"succeeded"`
+	Error     string `json:"error,omitempty"`
+}
+
+func VDepositFilesOptionsFactory() VDepositFilesOptions {
+	opt := VDepositFilesOptions{}
+	opt.setDefaultValues()
+	return opt
+}
+
+func (options *VDepositFilesOptions) validateAnalyzeOptions() error {
+	if len(options.RawHosts) == 0 && len(options.Hosts) == 0 {
+		return fmt.Errorf("must specify a host or host list")
+	}
+	if len(options.Files) == 0 {
+		return fmt.Errorf("must specify at least one file to deposit")
+	}
+	destinations := make(map[string]bool, len(options.Files))
+	for _, file := range options.Files {
+		if file.LocalPath == "" {
+			return fmt.Errorf("must specify the local path of the file deposited to %s", file.Destination)
+		}
+		// the file is written by the NMA, so the destination cannot be
+		// resolved against the local working directory
+		if !util.IsAbsPath(file.Destination) {
+			return fmt.Errorf("must provide a fully qualified destination for the file %s", file.LocalPath)
+		}
+		if destinations[file.Destination] {
+			return fmt.Errorf("more than one file is deposited to %s", file.Destination)
+		}
+		destinations[file.Destination] = true
+	}
+	return options.resolveHosts()
+}
+
+// VDepositFiles uploads local files, such as licenses, odbc.ini or custom
+// certificates, to the given paths on the hosts through the NMA. The NMA checks
+// the checksum of each written file. A result is returned for each file and
+// host, in the order of the files and then of the hosts. When the files are
+// deposited on some of the hosts only, a PartialSuccessError with the failed
+// hosts is returned with the results.
+func (vcc VClusterCommands) VDepositFiles(options *VDepositFilesOptions) (results []FileDepositResult, err error) {
+	defer vcc.startAudit("deposit_files", options)(&err)
+
+	err = options.validateAnalyzeOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	var instructions []clusterOp
+	var depositOps []*nmaDepositFileOp
+	for _, file := range options.Files {
+		content, readErr := os.ReadFile(file.LocalPath)
+		if readErr != nil {
+			return nil, fmt.Errorf("fail to read file %s: %w", file.LocalPath, readErr)
+		}
+		op := makeNMADepositFileOp(options.Hosts, string(content), file.Destination)
+		op.verifyChecksum = true
+		op.hostResults = make(map[string]*FileDepositResult, len(options.Hosts))
+		depositOps = append(depositOps, &op)
+		instructions = append(instructions, &op)
+	}
+
+	err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, instructions)
+	if err != nil {
+		return nil, fmt.Errorf("fail to deposit files: %w", err)
+	}
+
+	results = buildFileDepositResults(options.Hosts, depositOps)
+	return results, getFileDepositError(results)
+}
+
+// buildFileDepositResults collects the result of each deposit op, in the order
+// of the ops and then of the hosts
+func buildFileDepositResults(hosts []string, depositOps []*nmaDepositFileOp) []FileDepositResult {
+	var results []FileDepositResult
+	for _, op := range depositOps {
+		for _, host := range hosts {
+			result, ok := op.hostResults[host]
+			if !ok {
+				result = &FileDepositResult{Host: host, Destination: op.getDestination(host),
+					Checksum: op.checksum, Error: "no response from the NMA"}
+			}
+			results = append(results, *result)
+		}
+	}
+	return results
+}
+
+// getFileDepositError returns an error if a file could not be deposited on a host
+func getFileDepositError(results []FileDepositResult) error {
+	var failedHosts []string
+	succeeded := false
+	for i := range results {
+		if results[i].Succeeded {
+			succeeded = true
+		} else if !slices.Contains(failedHosts, results[i].Host) {
+			failedHosts = append(failedHosts, results[i].Host)
+		}
+	}
+	if len(failedHosts) == 0 {
+		return nil
+	}
+	if !succeeded {
+		return fmt.Errorf("fail to deposit the files on any host")
+	}
+	return &PartialSuccessError{Detail: fmt.Sprintf("fail to deposit files on hosts %v", failedHosts),
+		Failed: failedHosts}
+}
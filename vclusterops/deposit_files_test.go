@@ -0,0 +1,86 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDepositFilesOptions(t *testing.T) {
+	opt := VDepositFilesOptionsFactory()
+	opt.RawHosts = []string{"192.168.1.101"}
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(), "at least one file")
+
+	opt.Files = []DepositFile{{LocalPath: "odbc.ini", Destination: "etc/odbc.ini"}}
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(), "fully qualified destination")
+
+	opt.Files = []DepositFile{
+		{LocalPath: "odbc.ini", Destination: "/etc/odbc.ini"},
+		{LocalPath: "other.ini", Destination: "/etc/odbc.ini"},
+	}
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(), "more than one file")
+
+	opt.Files = opt.Files[:1]
+	assert.NoError(t, opt.validateAnalyzeOptions())
+}
+
+func TestDepositFilesChecksum(t *testing.T) {
+	hosts := []string{"192.168.1.101", "192.168.1.102", "192.168.1.103"}
+	op := makeNMADepositFileOp(hosts, "[ODBC]\n", "/etc/odbc.ini")
+	op.verifyChecksum = true
+	op.hostResults = make(map[string]*FileDepositResult)
+	checksum := getContentChecksum("[ODBC]\n")
+	assert.Equal(t, checksum, op.checksum)
+
+	op.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, op.setupClusterHTTPRequest(hosts))
+	assert.Contains(t, op.clusterHTTPRequest.RequestCollection[hosts[0]].RequestData, checksum)
+
+	// the results are recorded per host instead of failing the op
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS,
+			content: fmt.Sprintf(`{"destination": "/etc/odbc.ini", "checksum": %q}`, checksum)},
+		"192.168.1.102": {status: SUCCESS, content: `{"destination": "/etc/odbc.ini", "checksum": "abc"}`},
+		"192.168.1.103": {status: FAILURE, err: errors.New("connection refused")},
+	}
+	assert.NoError(t, op.processResult(nil))
+
+	results := buildFileDepositResults(hosts, []*nmaDepositFileOp{&op})
+	assert.Len(t, results, 3)
+	assert.Equal(t, FileDepositResult{Host: "192.168.1.101", Destination: "/etc/odbc.ini",
+		Checksum: checksum, Succeeded: true}, results[0])
+	assert.Contains(t, results[1].Error, "checksum mismatch")
+	assert.Equal(t, "connection refused", results[2].Error)
+
+	err := getFileDepositError(results)
+	partialErr := &PartialSuccessError{}
+	assert.ErrorAs(t, err, &partialErr)
+	assert.Equal(t, hosts[1:], partialErr.Failed)
+
+	// without verification, the checksum is not sent nor checked
+	op = makeNMADepositFileOp(hosts[:1], "[ODBC]\n", "/etc/odbc.ini")
+	op.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, op.setupClusterHTTPRequest(hosts[:1]))
+	assert.NotContains(t, op.clusterHTTPRequest.RequestCollection[hosts[0]].RequestData, "checksum")
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, content: `{"destination": "/etc/odbc.ini"}`},
+	}
+	assert.NoError(t, op.processResult(nil))
+}
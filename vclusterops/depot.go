@@ -0,0 +1,205 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+type VAlterDepotOptions struct {
+	DatabaseOptions
+
+	// the subcluster whose nodes have their depot resized. If neither
+	// SCName nor NodeNames is set, all the nodes have their depot resized.
+	SCName    string
+	NodeNames []string
+	// the new size of the depot, either a percentage of the disk, e.g., 40%,
+	// or a size in bytes, e.g., 100G
+	Size string
+}
+
+type VClearDepotCacheOptions struct {
+	DatabaseOptions
+
+	// the subcluster whose nodes have their depot cleared. If neither
+	// SCName nor NodeNames is set, all the nodes have their depot cleared.
+	SCName    string
+	NodeNames []string
+}
+
+func VAlterDepotOptionsFactory() VAlterDepotOptions {
+	opt := VAlterDepotOptions{}
+	opt.setDefaultValues()
+	return opt
+}
+
+func VClearDepotCacheOptionsFactory() VClearDepotCacheOptions {
+	opt := VClearDepotCacheOptions{}
+	opt.setDefaultValues()
+	return opt
+}
+
+func (options *VAlterDepotOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandAlterDepot, log); err != nil {
+		return err
+	}
+	if options.Size == "" {
+		return fmt.Errorf("must specify the new size of the depot")
+	}
+	if _, err := validateDepotSize(options.Size); err != nil {
+		return err
+	}
+	if err := validateDepotTargets(options.SCName, options.NodeNames); err != nil {
+		return err
+	}
+	return options.resolveHosts()
+}
+
+func (options *VClearDepotCacheOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandClearDepotCache, log); err != nil {
+		return err
+	}
+	if err := validateDepotTargets(options.SCName, options.NodeNames); err != nil {
+		return err
+	}
+	return options.resolveHosts()
+}
+
+func validateDepotTargets(scName string, nodeNames []string) error {
+	if scName != "" && len(nodeNames) > 0 {
+		return fmt.Errorf("cannot specify both a subcluster and nodes")
+	}
+	if scName != "" {
+		return util.ValidateName(scName, "subcluster")
+	}
+	return nil
+}
+
+// VAlterDepot resizes the depot storage location of the nodes of a subcluster,
+// of some nodes, or of all the nodes of an Eon database, such as before the
+// disks of the depot are shrunk. The nodes must be up.
+func (vcc VClusterCommands) VAlterDepot(options *VAlterDepotOptions) (err error) {
+	defer vcc.startAudit("alter_depot", options)(&err)
+
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+	err = options.setUsePassword(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	hostNodeNames, err := vcc.getDepotHostNodeNames(&options.DatabaseOptions, options.SCName, options.NodeNames)
+	if err != nil {
+		return err
+	}
+
+	httpsAlterDepotOp, err := makeHTTPSAlterDepotOp(hostNodeNames, options.usePassword, options.UserName,
+		options.Password, options.Size)
+	if err != nil {
+		return err
+	}
+	err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, []clusterOp{&httpsAlterDepotOp})
+	if err != nil {
+		return fmt.Errorf("fail to resize the depot to %s: %w", options.Size, err)
+	}
+	return nil
+}
+
+// VClearDepotCache evicts the content of the depot of the nodes of a subcluster,
+// of some nodes, or of all the nodes of an Eon database, so that the depot is
+// filled again from communal storage, such as after a configuration change.
+// The nodes must be up.
+func (vcc VClusterCommands) VClearDepotCache(options *VClearDepotCacheOptions) (err error) {
+	defer vcc.startAudit("clear_depot_cache", options)(&err)
+
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+	err = options.setUsePassword(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	hostNodeNames, err := vcc.getDepotHostNodeNames(&options.DatabaseOptions, options.SCName, options.NodeNames)
+	if err != nil {
+		return err
+	}
+
+	httpsClearDepotCacheOp, err := makeHTTPSClearDepotCacheOp(hostNodeNames, options.usePassword, options.UserName,
+		options.Password)
+	if err != nil {
+		return err
+	}
+	err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, []clusterOp{&httpsClearDepotCacheOp})
+	if err != nil {
+		return fmt.Errorf("fail to clear the depot cache: %w", err)
+	}
+	return nil
+}
+
+// getDepotHostNodeNames returns the name of the node on each host whose depot
+// is managed, after checking that these nodes are up
+func (vcc VClusterCommands) getDepotHostNodeNames(options *DatabaseOptions, scName string,
+	nodeNames []string) (map[string]string, error) {
+	vdb := makeVCoordinationDatabase()
+	err := vcc.getVDBFromRunningDB(&vdb, options)
+	if err != nil {
+		return nil, err
+	}
+	return getDepotTargets(&vdb, scName, nodeNames)
+}
+
+func getDepotTargets(vdb *VCoordinationDatabase, scName string, nodeNames []string) (map[string]string, error) {
+	if !vdb.IsEon {
+		return nil, fmt.Errorf("the depot can only be managed in an Eon database")
+	}
+
+	hostNodeNames := make(map[string]string)
+	for host, vnode := range vdb.HostNodeMap {
+		if scName != "" && vnode.Subcluster != scName {
+			continue
+		}
+		if len(nodeNames) > 0 && !slices.Contains(nodeNames, vnode.Name) {
+			continue
+		}
+		if vnode.State != util.NodeUpState {
+			return nil, fmt.Errorf("node %s is %s, the depot can only be managed on up nodes", vnode.Name, vnode.State)
+		}
+		hostNodeNames[host] = vnode.Name
+	}
+
+	if scName != "" && len(hostNodeNames) == 0 {
+		return nil, fmt.Errorf("cannot find any node in subcluster %s", scName)
+	}
+	var missingNodes []string
+	for _, name := range nodeNames {
+		if !slices.Contains(maps.Values(hostNodeNames), name) {
+			missingNodes = append(missingNodes, name)
+		}
+	}
+	if len(missingNodes) > 0 {
+		return nil, fmt.Errorf("cannot find nodes %v in the database", missingNodes)
+	}
+	return hostNodeNames, nil
+}
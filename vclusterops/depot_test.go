@@ -0,0 +1,92 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestValidateDepotOptions(t *testing.T) {
+	opt := VAlterDepotOptionsFactory()
+	opt.DBName = dbName
+	opt.RawHosts = []string{"192.168.1.101"}
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(vlog.Printer{}), "must specify the new size")
+	opt.Size = "120%"
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(vlog.Printer{}), "greater than 100%")
+	opt.Size = "40%"
+	opt.SCName = "sc1"
+	opt.NodeNames = []string{"v_test_db_node0001"}
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(vlog.Printer{}), "both a subcluster and nodes")
+	opt.NodeNames = nil
+	assert.NoError(t, opt.validateAnalyzeOptions(vlog.Printer{}))
+
+	clearOpt := VClearDepotCacheOptionsFactory()
+	clearOpt.DBName = dbName
+	clearOpt.RawHosts = []string{"192.168.1.101"}
+	assert.NoError(t, clearOpt.validateAnalyzeOptions(vlog.Printer{}))
+}
+
+func TestGetDepotTargets(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Name: "v_test_db_node0001", Subcluster: "sc1",
+		State: util.NodeUpState}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{Name: "v_test_db_node0002", Subcluster: "sc2",
+		State: util.NodeUpState}
+	vdb.HostNodeMap["192.168.1.103"] = &VCoordinationNode{Name: "v_test_db_node0003", Subcluster: "sc2",
+		State: util.NodeDownState}
+
+	_, err := getDepotTargets(&vdb, "sc1", nil)
+	assert.ErrorContains(t, err, "Eon database")
+	vdb.IsEon = true
+
+	hostNodeNames, err := getDepotTargets(&vdb, "sc1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"192.168.1.101": "v_test_db_node0001"}, hostNodeNames)
+
+	// the nodes must be up
+	_, err = getDepotTargets(&vdb, "sc2", nil)
+	assert.ErrorContains(t, err, "node v_test_db_node0003 is DOWN")
+	_, err = getDepotTargets(&vdb, "sc3", nil)
+	assert.ErrorContains(t, err, "cannot find any node in subcluster sc3")
+
+	hostNodeNames, err = getDepotTargets(&vdb, "", []string{"v_test_db_node0002"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"192.168.1.102": "v_test_db_node0002"}, hostNodeNames)
+	_, err = getDepotTargets(&vdb, "", []string{"v_test_db_node0002", "v_test_db_node0009"})
+	assert.ErrorContains(t, err, "cannot find nodes [v_test_db_node0009]")
+
+	// the request of each node is sent to its own host
+	op, err := makeHTTPSAlterDepotOp(hostNodeNames, false, "", nil, "40%")
+	assert.NoError(t, err)
+	op.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, op.setupClusterHTTPRequest(op.hosts))
+	request := op.clusterHTTPRequest.RequestCollection["192.168.1.102"]
+	assert.Equal(t, PutMethod, request.Method)
+	assert.Contains(t, request.Endpoint, "nodes/v_test_db_node0002/depot")
+	assert.Equal(t, "40%", request.QueryParams["size"])
+
+	clearOp, err := makeHTTPSClearDepotCacheOp(hostNodeNames, false, "", nil)
+	assert.NoError(t, err)
+	clearOp.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, clearOp.setupClusterHTTPRequest(clearOp.hosts))
+	assert.Contains(t, clearOp.clusterHTTPRequest.RequestCollection["192.168.1.102"].Endpoint,
+		"nodes/v_test_db_node0002/depot/cache")
+}
@@ -0,0 +1,166 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+const (
+	defaultDiagnosticsSinceMinutes = 60
+	defaultDiagnosticsLimit        = 100
+)
+
+type VGetDiagnosticsOptions struct {
+	DatabaseOptions
+
+	// only the events and errors of the last SinceMinutes minutes are collected
+	SinceMinutes int
+	// the max number of slow events and of error summaries collected
+	Limit int
+}
+
+// NodeDiagnostics is the triage data of a node
+type NodeDiagnostics struct {
+	NodeName   string         `json:"node_name"`
+	SlowEvents []SlowEvent    `json:"slow_events"`
+	Errors     []ErrorSummary `json:"errors"`
+	// the number of occurrences of all errors
+	ErrorCount int `json:"error_count"`
+}
+
+func VGetDiagnosticsOptionsFactory() VGetDiagnosticsOptions {
+	opt := VGetDiagnosticsOptions{}
+	// set default values to the params
+	opt.setDefaultValues()
+
+	return opt
+}
+
+func (options *VGetDiagnosticsOptions) setDefaultValues() {
+	options.DatabaseOptions.setDefaultValues()
+	options.SinceMinutes = defaultDiagnosticsSinceMinutes
+	options.Limit = defaultDiagnosticsLimit
+}
+
+func (options *VGetDiagnosticsOptions) validateParseOptions(log vlog.Printer) error {
+	err := options.validateBaseOptions(commandGetDiagnostics, log)
+	if err != nil {
+		return err
+	}
+
+	if options.SinceMinutes <= 0 {
+		return fmt.Errorf("the time window in minutes must be positive")
+	}
+	if options.Limit <= 0 {
+		return fmt.Errorf("the limit of collected events must be positive")
+	}
+
+	return nil
+}
+
+// resolve hostnames to be IPs
+func (options *VGetDiagnosticsOptions) analyzeOptions() (err error) {
+	if len(options.RawHosts) > 0 {
+		options.Hosts, err = util.ResolveRawHostsToAddresses(options.RawHosts, options.IPv6)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (options *VGetDiagnosticsOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateParseOptions(log); err != nil {
+		return err
+	}
+	return options.analyzeOptions()
+}
+
+// VGetDiagnostics collects the recent slow events and the summary of errors of a running
+// database and groups them by node. It is a quick triage path that does not need a full
+// scrutinize run.
+func (vcc VClusterCommands) VGetDiagnostics(options *VGetDiagnosticsOptions) ([]NodeDiagnostics, error) {
+	err := options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	err = options.setUsePassword(vcc.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	httpsGetUpNodesOp, err := makeHTTPSGetUpNodesOp(options.DBName, options.Hosts,
+		options.usePassword, options.UserName, options.Password, DiagnosticsCmd)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnostics := diagnosticsResp{}
+	httpsGetDiagnosticsOp, err := makeHTTPSGetDiagnosticsOp(options.Hosts, options.usePassword,
+		options.UserName, options.Password, options.SinceMinutes, options.Limit, &diagnostics)
+	if err != nil {
+		return nil, err
+	}
+
+	instructions := []clusterOp{&httpsGetUpNodesOp, &httpsGetDiagnosticsOp}
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	if err != nil {
+		return nil, fmt.Errorf("fail to collect diagnostics: %w", err)
+	}
+
+	return groupDiagnosticsByNode(&diagnostics), nil
+}
+
+// groupDiagnosticsByNode groups the slow events and errors by node, sorted by node name
+func groupDiagnosticsByNode(diagnostics *diagnosticsResp) []NodeDiagnostics {
+	nodeDiagnosticsMap := make(map[string]*NodeDiagnostics)
+	getNodeDiagnostics := func(nodeName string) *NodeDiagnostics {
+		nodeDiagnostics, ok := nodeDiagnosticsMap[nodeName]
+		if !ok {
+			nodeDiagnostics = &NodeDiagnostics{NodeName: nodeName}
+			nodeDiagnosticsMap[nodeName] = nodeDiagnostics
+		}
+		return nodeDiagnostics
+	}
+
+	for _, event := range diagnostics.SlowEvents {
+		nodeDiagnostics := getNodeDiagnostics(event.NodeName)
+		nodeDiagnostics.SlowEvents = append(nodeDiagnostics.SlowEvents, event)
+	}
+	for _, errSummary := range diagnostics.Errors {
+		nodeDiagnostics := getNodeDiagnostics(errSummary.NodeName)
+		nodeDiagnostics.Errors = append(nodeDiagnostics.Errors, errSummary)
+		nodeDiagnostics.ErrorCount += errSummary.Count
+	}
+
+	nodesDiagnostics := make([]NodeDiagnostics, 0, len(nodeDiagnosticsMap))
+	for _, nodeDiagnostics := range nodeDiagnosticsMap {
+		nodesDiagnostics = append(nodesDiagnostics, *nodeDiagnostics)
+	}
+	sort.Slice(nodesDiagnostics, func(i, j int) bool {
+		return nodesDiagnostics[i].NodeName < nodesDiagnostics[j].NodeName
+	})
+	return nodesDiagnostics
+}
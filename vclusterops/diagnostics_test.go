@@ -0,0 +1,47 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupDiagnosticsByNode(t *testing.T) {
+	diagnostics := diagnosticsResp{
+		SlowEvents: []SlowEvent{
+			{NodeName: "v_test_db_node0002", EventDescription: "Lock Attempts"},
+			{NodeName: "v_test_db_node0001", EventDescription: "Memory Allocation"},
+		},
+		Errors: []ErrorSummary{
+			{NodeName: "v_test_db_node0002", ErrorCode: 4566, Count: 12},
+			{NodeName: "v_test_db_node0002", ErrorCode: 2005, Count: 3},
+			{NodeName: "v_test_db_node0003", ErrorCode: 4566, Count: 1},
+		},
+	}
+
+	nodesDiagnostics := groupDiagnosticsByNode(&diagnostics)
+	assert.Len(t, nodesDiagnostics, 3)
+	assert.Equal(t, "v_test_db_node0001", nodesDiagnostics[0].NodeName)
+	assert.Len(t, nodesDiagnostics[0].SlowEvents, 1)
+	assert.Zero(t, nodesDiagnostics[0].ErrorCount)
+	assert.Equal(t, "v_test_db_node0002", nodesDiagnostics[1].NodeName)
+	assert.Len(t, nodesDiagnostics[1].Errors, 2)
+	assert.Equal(t, 15, nodesDiagnostics[1].ErrorCount)
+	assert.Empty(t, nodesDiagnostics[2].SlowEvents)
+	assert.Equal(t, 1, nodesDiagnostics[2].ErrorCount)
+}
@@ -0,0 +1,154 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+type VGetDrainingStatusOptions struct {
+	DatabaseOptions
+
+	// the subcluster to get the draining status of.
+	// If it is empty, the draining status of all subclusters is returned
+	SCName string
+}
+
+// SubclusterDrainingStatus is the draining state and the client sessions of a subcluster
+type SubclusterDrainingStatus struct {
+	Name string `json:"name"`
+	// true if all nodes of the subcluster are draining
+	IsDraining bool `json:"is_draining"`
+	// the number of client sessions on all nodes of the subcluster
+	ClientSessionCount int                  `json:"count_client_user_sessions"`
+	Nodes              []NodeDrainingStatus `json:"nodes"`
+}
+
+func VGetDrainingStatusOptionsFactory() VGetDrainingStatusOptions {
+	opt := VGetDrainingStatusOptions{}
+	// set default values to the params
+	opt.setDefaultValues()
+
+	return opt
+}
+
+func (options *VGetDrainingStatusOptions) validateParseOptions(log vlog.Printer) error {
+	err := options.validateBaseOptions(commandGetDrainingStatus, log)
+	if err != nil {
+		return err
+	}
+
+	if !options.IsEon {
+		return fmt.Errorf("connection draining is only available in Eon mode")
+	}
+
+	return nil
+}
+
+// resolve hostnames to be IPs
+func (options *VGetDrainingStatusOptions) analyzeOptions() (err error) {
+	if len(options.RawHosts) > 0 {
+		options.Hosts, err = util.ResolveRawHostsToAddresses(options.RawHosts, options.IPv6)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (options *VGetDrainingStatusOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateParseOptions(log); err != nil {
+		return err
+	}
+	return options.analyzeOptions()
+}
+
+// VGetDrainingStatus returns the draining state and the number of client sessions of each
+// subcluster, or of the given subcluster. A subcluster with no client sessions left can
+// be stopped, or have its nodes removed, without interrupting users.
+func (vcc VClusterCommands) VGetDrainingStatus(options *VGetDrainingStatusOptions) ([]SubclusterDrainingStatus, error) {
+	err := options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	err = options.setUsePassword(vcc.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	httpsGetUpNodesOp, err := makeHTTPSGetUpNodesOp(options.DBName, options.Hosts,
+		options.usePassword, options.UserName, options.Password, DrainingStatusCmd)
+	if err != nil {
+		return nil, err
+	}
+
+	drainingStatus := drainingStatusResp{}
+	httpsGetDrainingStatusOp, err := makeHTTPSGetDrainingStatusOp(options.Hosts, options.usePassword,
+		options.UserName, options.Password, &drainingStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	instructions := []clusterOp{&httpsGetUpNodesOp, &httpsGetDrainingStatusOp}
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get draining status: %w", err)
+	}
+
+	scDrainingStatus := groupDrainingStatusBySubcluster(&drainingStatus)
+	if options.SCName == "" {
+		return scDrainingStatus, nil
+	}
+	for _, status := range scDrainingStatus {
+		if status.Name == options.SCName {
+			return []SubclusterDrainingStatus{status}, nil
+		}
+	}
+	return nil, fmt.Errorf("subcluster %s is not found in database %s", options.SCName, options.DBName)
+}
+
+// groupDrainingStatusBySubcluster groups the draining status of nodes by subcluster,
+// sorted by subcluster name
+func groupDrainingStatusBySubcluster(drainingStatus *drainingStatusResp) []SubclusterDrainingStatus {
+	scStatusMap := make(map[string]*SubclusterDrainingStatus)
+	for _, nodeStatus := range drainingStatus.DrainingStatusList {
+		scStatus, ok := scStatusMap[nodeStatus.SubclusterName]
+		if !ok {
+			scStatus = &SubclusterDrainingStatus{Name: nodeStatus.SubclusterName, IsDraining: true}
+			scStatusMap[nodeStatus.SubclusterName] = scStatus
+		}
+		scStatus.Nodes = append(scStatus.Nodes, nodeStatus)
+		scStatus.ClientSessionCount += nodeStatus.ClientSessionCount
+		scStatus.IsDraining = scStatus.IsDraining && nodeStatus.IsDraining
+	}
+
+	scDrainingStatus := make([]SubclusterDrainingStatus, 0, len(scStatusMap))
+	for _, scStatus := range scStatusMap {
+		scDrainingStatus = append(scDrainingStatus, *scStatus)
+	}
+	sort.Slice(scDrainingStatus, func(i, j int) bool {
+		return scDrainingStatus[i].Name < scDrainingStatus[j].Name
+	})
+	return scDrainingStatus
+}
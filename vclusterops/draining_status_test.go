@@ -0,0 +1,44 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupDrainingStatusBySubcluster(t *testing.T) {
+	drainingStatus := drainingStatusResp{
+		DrainingStatusList: []NodeDrainingStatus{
+			{NodeName: "v_test_db_node0003", SubclusterName: "sc1", IsDraining: true, ClientSessionCount: 2},
+			{NodeName: "v_test_db_node0004", SubclusterName: "sc1", IsDraining: true, ClientSessionCount: 1},
+			{NodeName: "v_test_db_node0001", SubclusterName: "default_subcluster", IsDraining: false, ClientSessionCount: 5},
+			{NodeName: "v_test_db_node0002", SubclusterName: "default_subcluster", IsDraining: true},
+		},
+	}
+
+	scDrainingStatus := groupDrainingStatusBySubcluster(&drainingStatus)
+	assert.Len(t, scDrainingStatus, 2)
+	assert.Equal(t, "default_subcluster", scDrainingStatus[0].Name)
+	// only some nodes of the subcluster are draining
+	assert.False(t, scDrainingStatus[0].IsDraining)
+	assert.Equal(t, 5, scDrainingStatus[0].ClientSessionCount)
+	assert.Equal(t, "sc1", scDrainingStatus[1].Name)
+	assert.True(t, scDrainingStatus[1].IsDraining)
+	assert.Equal(t, 3, scDrainingStatus[1].ClientSessionCount)
+	assert.Len(t, scDrainingStatus[1].Nodes, 2)
+}
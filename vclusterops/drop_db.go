@@ -56,7 +56,9 @@ func (options *VDropDatabaseOptions) validateAnalyzeOptions() error {
 	return options.analyzeOptions()
 }
 
-func (vcc VClusterCommands) VDropDatabase(options *VDropDatabaseOptions) error {
+func (vcc VClusterCommands) VDropDatabase(options *VDropDatabaseOptions) (err error) {
+	defer vcc.startAudit("drop_db", options)(&err)
+
 	/*
 	 *   - Produce Instructions
 	 *   - Create a VClusterOpEngine
@@ -66,7 +68,7 @@ func (vcc VClusterCommands) VDropDatabase(options *VDropDatabaseOptions) error {
 	// Analyze to produce vdb info for drop db use
 	vdb := makeVCoordinationDatabase()
 
-	err := options.validateAnalyzeOptions()
+	err = options.validateAnalyzeOptions()
 	if err != nil {
 		return err
 	}
@@ -87,7 +89,7 @@ func (vcc VClusterCommands) VDropDatabase(options *VDropDatabaseOptions) error {
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// give the instructions to the VClusterOpEngine to run
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if runError != nil {
 		return fmt.Errorf("fail to drop database: %w", runError)
 	}
@@ -0,0 +1,73 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import "fmt"
+
+// The errors below classify the failures of the vcluster operations, so that
+// callers can do type checking to react to a class of failures, for example
+// to ask for a new password on an AuthFailureError.
+
+// AuthFailureError is returned when a host rejects the password or the
+// certificate of a request
+type AuthFailureError struct {
+	Host   string
+	Detail string
+}
+
+func (e *AuthFailureError) Error() string {
+	return e.Detail
+}
+
+// makeAuthFailureError returns the error of an op whose request is rejected by a host
+func makeAuthFailureError(opName, host string) *AuthFailureError {
+	return &AuthFailureError{
+		Host:   host,
+		Detail: fmt.Sprintf("[%s] wrong password/certificate for https service on host %s", opName, host),
+	}
+}
+
+// QuorumError is returned when an operation cannot run because the database
+// does not have, or would lose, the quorum of its primary nodes
+type QuorumError struct {
+	Detail string
+}
+
+func (e *QuorumError) Error() string {
+	return e.Detail
+}
+
+// PreconditionError is returned when the state of the database or the hosts
+// does not allow an operation, such as a database that is still running
+type PreconditionError struct {
+	Detail string
+}
+
+func (e *PreconditionError) Error() string {
+	return e.Detail
+}
+
+// PartialSuccessError is returned when an operation succeeds on some of its
+// targets, and fails on the others
+type PartialSuccessError struct {
+	Detail string
+	// the targets that the operation failed on, such as hosts or packages
+	Failed []string
+}
+
+func (e *PartialSuccessError) Error() string {
+	return e.Detail
+}
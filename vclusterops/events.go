@@ -0,0 +1,119 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EventType is the type of an event of a command
+type EventType string
+
+const (
+	EventInstructionStarted   EventType = "InstructionStarted"
+	EventInstructionSucceeded EventType = "InstructionSucceeded"
+	EventInstructionFailed    EventType = "InstructionFailed"
+	// a node is seen in a state that is not the last state seen by the command
+	EventNodeStateChanged EventType = "NodeStateChanged"
+)
+
+// Event is an event of a command, which a caller such as the VerticaDB
+// operator can map onto the status conditions of its resources
+type Event struct {
+	Type EventType
+	Time time.Time
+	// the instruction that the event comes from
+	Instruction string
+	// the duration of a finished instruction
+	Duration time.Duration
+	// the error of a failed instruction
+	Err error
+	// the node of a NodeStateChanged event. The node name is empty if the node
+	// is seen as down because its host does not respond.
+	Host          string
+	NodeName      string
+	PreviousState string
+	State         string
+}
+
+// EventHandler receives the events of a command. It is called synchronously
+// by the op engine, so it must not block.
+type EventHandler func(event *Event)
+
+// eventEmitter sends the events of a command to its handler, and tracks the
+// last state of the nodes so that only the transitions are sent
+type eventEmitter struct {
+	handler    EventHandler
+	mu         sync.Mutex
+	nodeStates map[string]string
+}
+
+type eventEmitterContextKey struct{}
+
+// ContextWithEventHandler returns a context whose op engines send their events
+// to the handler. When the context is the Ctx of VClusterCommands, the node
+// transitions are tracked across all of the op engines of a command.
+func ContextWithEventHandler(ctx context.Context, handler EventHandler) context.Context {
+	emitter := &eventEmitter{handler: handler, nodeStates: make(map[string]string)}
+	return context.WithValue(ctx, eventEmitterContextKey{}, emitter)
+}
+
+// getEventEmitter returns the emitter of a context, or nil. The methods of
+// a nil emitter do nothing.
+func getEventEmitter(ctx context.Context) *eventEmitter {
+	emitter, _ := ctx.Value(eventEmitterContextKey{}).(*eventEmitter)
+	return emitter
+}
+
+func (emitter *eventEmitter) emit(event *Event) {
+	if emitter == nil || emitter.handler == nil {
+		return
+	}
+	event.Time = time.Now()
+	emitter.handler(event)
+}
+
+func (emitter *eventEmitter) instructionStarted(instruction string) {
+	emitter.emit(&Event{Type: EventInstructionStarted, Instruction: instruction})
+}
+
+func (emitter *eventEmitter) instructionFinished(instruction string, duration time.Duration, err error) {
+	event := Event{Type: EventInstructionSucceeded, Instruction: instruction, Duration: duration}
+	if err != nil {
+		event.Type = EventInstructionFailed
+		event.Err = err
+	}
+	emitter.emit(&event)
+}
+
+// nodeState sends a NodeStateChanged event if the state of the node on a host
+// is not the last state seen for the host
+func (emitter *eventEmitter) nodeState(instruction, host, nodeName, state string) {
+	if emitter == nil {
+		return
+	}
+	emitter.mu.Lock()
+	previousState, found := emitter.nodeStates[host]
+	emitter.nodeStates[host] = state
+	emitter.mu.Unlock()
+	if found && previousState == state {
+		return
+	}
+	emitter.emit(&Event{Type: EventNodeStateChanged, Instruction: instruction, Host: host,
+		NodeName: nodeName, PreviousState: previousState, State: state})
+}
@@ -0,0 +1,75 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+type VExecuteSQLOptions struct {
+	DatabaseOptions
+
+	// a SQL statement, or a script of statements separated by semicolons
+	SQL string
+}
+
+func VExecuteSQLOptionsFactory() VExecuteSQLOptions {
+	opt := VExecuteSQLOptions{}
+	opt.setDefaultValues()
+	return opt
+}
+
+func (options *VExecuteSQLOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandExecuteSQL, log); err != nil {
+		return err
+	}
+	if strings.TrimSpace(options.SQL) == "" {
+		return fmt.Errorf("must specify the SQL to execute")
+	}
+	return options.resolveHosts()
+}
+
+// VExecuteSQL runs a SQL statement or script on an up host of a running database
+// through the HTTPS service, so that commands can query and change the database
+// without vsql. A result is returned for each statement, in the order of the
+// statements.
+func (vcc VClusterCommands) VExecuteSQL(options *VExecuteSQLOptions) (results []SQLResult, err error) {
+	defer vcc.startAudit("execute_sql", options)(&err)
+
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return nil, err
+	}
+	err = options.setUsePassword(vcc.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	httpsExecuteSQLOp, err := makeHTTPSExecuteSQLOp(options.Hosts, options.usePassword,
+		options.UserName, options.Password, options.SQL, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	err = vcc.runOnUpHost(&options.DatabaseOptions, &httpsExecuteSQLOp)
+	if err != nil {
+		return nil, fmt.Errorf("fail to execute SQL: %w", err)
+	}
+	return results, nil
+}
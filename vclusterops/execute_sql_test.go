@@ -0,0 +1,65 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestValidateExecuteSQLOptions(t *testing.T) {
+	opt := VExecuteSQLOptionsFactory()
+	opt.DBName = dbName
+	opt.RawHosts = []string{"192.168.1.101"}
+	opt.SQL = "  "
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(vlog.Printer{}), "must specify the SQL")
+
+	opt.SQL = "SELECT node_name, node_state FROM nodes;"
+	assert.NoError(t, opt.validateAnalyzeOptions(vlog.Printer{}))
+}
+
+func TestExecuteSQLOp(t *testing.T) {
+	var results []SQLResult
+	password := "password"
+	op, err := makeHTTPSExecuteSQLOp([]string{"192.168.1.101", "192.168.1.102"}, true, "dbadmin",
+		&password, "SELECT node_name, node_state FROM nodes; COMMIT;", &results)
+	assert.NoError(t, err)
+
+	// the SQL runs on a single up host
+	execContext := makeOpEngineExecContext(vlog.Printer{})
+	execContext.upHosts = []string{"192.168.1.102"}
+	op.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, op.prepare(&execContext))
+	assert.Equal(t, []string{"192.168.1.102"}, op.hosts)
+	assert.Contains(t, op.clusterHTTPRequest.RequestCollection["192.168.1.102"].RequestData, "COMMIT;")
+
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.102": {status: SUCCESS, content: `{"results": [
+			{"columns": ["node_name", "node_state"],
+			 "rows": [["v_test_db_node0001", "UP"], ["v_test_db_node0002", null]], "rows_affected": 0},
+			{"columns": [], "rows": [], "rows_affected": 0}]}`},
+	}
+	assert.NoError(t, op.processResult(nil))
+	assert.Len(t, results, 2)
+	stateIndex := results[0].GetColumnIndex("node_state")
+	assert.Equal(t, 1, stateIndex)
+	assert.Equal(t, "UP", *results[0].Rows[0][stateIndex])
+	assert.Nil(t, results[0].Rows[1][stateIndex])
+	assert.Equal(t, -1, results[0].GetColumnIndex("catalog_path"))
+	assert.Empty(t, results[1].Rows)
+}
@@ -104,7 +104,7 @@ func (vcc VClusterCommands) VFetchCoordinationDatabase(options *VFetchCoordinati
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// Give the instructions to the VClusterOpEngine to run
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 
 	// nmaVDB is an object obtained from the read catalog editor result
 	// we use nmaVDB data to complete vdb
@@ -134,6 +134,31 @@ func (vcc VClusterCommands) VFetchCoordinationDatabase(options *VFetchCoordinati
 	return vdb, runError
 }
 
+// VFetchRunningCoordinationDatabase builds a VCoordinationDatabase from the https
+// service of a running database, with its current nodes, subclusters, sandboxes
+// and paths. Unlike VFetchCoordinationDatabase, it does not read the catalog, so
+// it also picks up the changes made to the database outside of vcluster.
+func (vcc VClusterCommands) VFetchRunningCoordinationDatabase(options *VFetchCoordinationDatabaseOptions) (VCoordinationDatabase, error) {
+	vdb := makeVCoordinationDatabase()
+
+	err := options.validateBaseOptions(commandFetchConfig, vcc.Log)
+	if err != nil {
+		return vdb, err
+	}
+	err = options.resolveHosts()
+	if err != nil {
+		return vdb, err
+	}
+
+	err = vcc.getVDBFromRunningDB(&vdb, &options.DatabaseOptions)
+	if err != nil {
+		return vdb, err
+	}
+	vdb.Ipv6 = options.IPv6
+
+	return vdb, nil
+}
+
 // produceRecoverConfigInstructions will build a list of instructions to execute for
 // the recover config operation.
 
@@ -17,6 +17,7 @@ package vclusterops
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/vertica/vcluster/vclusterops/util"
 	"github.com/vertica/vcluster/vclusterops/vlog"
@@ -40,6 +41,7 @@ type NodeState struct {
 	Version                  string   `json:"build_info"`
 	SandboxName              string   `json:"sandbox_name"`
 	NumberShardSubscriptions uint     `json:"number_shard_subscriptions"`
+	UpSince                  string   `json:"up_since"`
 }
 
 type StorageLocation struct {
@@ -62,6 +64,34 @@ type StorageLocations struct {
 type NodeDetails struct {
 	NodeState
 	StorageLocations
+	// the fields below are only set when disk usage is requested
+	DiskUsage []PathDiskUsage `json:"disk_usage,omitempty"`
+	// how full the depot is compared to its max size, 0 if the depot has no max size
+	DepotUsagePercent float64 `json:"depot_usage_percent,omitempty"`
+}
+
+const depotUsageType = "DEPOT"
+
+// setDepotUsagePercent computes how full the depot is from the disk usage of
+// the depot path and the max size of the depot storage location
+func (nodeDetails *NodeDetails) setDepotUsagePercent() {
+	var depotMaxSize uint64
+	for _, loc := range nodeDetails.StorageLocList {
+		if strings.Contains(loc.UsageType, depotUsageType) {
+			depotMaxSize = loc.MaxSize
+			break
+		}
+	}
+	if depotMaxSize == 0 || nodeDetails.DepotPath == "" {
+		return
+	}
+	for _, usage := range nodeDetails.DiskUsage {
+		if usage.Path == nodeDetails.DepotPath {
+			const percent = 100
+			nodeDetails.DepotUsagePercent = float64(usage.PathUsedBytes) * percent / float64(depotMaxSize)
+			return
+		}
+	}
 }
 
 type NodesDetails []NodeDetails
@@ -70,6 +100,8 @@ type hostNodeDetailsMap map[string]*NodeDetails
 
 type VFetchNodesDetailsOptions struct {
 	DatabaseOptions
+	// whether to get the disk usage of the node paths through the NMA
+	IncludeDiskUsage bool
 }
 
 func VFetchNodesDetailsOptionsFactory() VFetchNodesDetailsOptions {
@@ -112,7 +144,9 @@ func (options *VFetchNodesDetailsOptions) validateAnalyzeOptions(log vlog.Printe
 	return options.analyzeOptions()
 }
 
-// VFetchNodesDetails can return nodes' details including node state and storage locations for the provided hosts
+// VFetchNodesDetails can return nodes' details including node state and storage locations for the provided hosts.
+// With IncludeDiskUsage, the disk usage of the catalog, data and depot paths and the depot fill percentage
+// are also returned.
 func (vcc VClusterCommands) VFetchNodesDetails(options *VFetchNodesDetailsOptions) (nodesDetails NodesDetails, err error) {
 	/*
 	 *   - Validate Options
@@ -136,7 +170,7 @@ func (vcc VClusterCommands) VFetchNodesDetails(options *VFetchNodesDetailsOption
 	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
-	err = clusterOpEngine.run(vcc.Log)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if err != nil {
 		return nodesDetails, fmt.Errorf("failed to fetch node details on hosts %v: %w", options.Hosts, err)
 	}
@@ -154,6 +188,7 @@ func (vcc VClusterCommands) VFetchNodesDetails(options *VFetchNodesDetailsOption
 // The generated instructions will later perform the following operations:
 //   - Get nodes' state by calling /v1/node
 //   - Get nodes' storage locations by calling /v1/node/storage-locations
+//   - Optionally, get the disk usage of nodes' paths by calling the NMA /v1/filesystem/usage
 func (vcc *VClusterCommands) produceFetchNodesDetailsInstructions(options *VFetchNodesDetailsOptions,
 	hostsWithNodeDetails hostNodeDetailsMap) ([]clusterOp, error) {
 	var instructions []clusterOp
@@ -181,5 +216,10 @@ func (vcc *VClusterCommands) produceFetchNodesDetailsInstructions(options *VFetc
 		&httpsGetStorageLocationsOp,
 	)
 
+	if options.IncludeDiskUsage {
+		nmaGetDiskUsageOp := makeNMAGetDiskUsageOp(options.Hosts, hostsWithNodeDetails)
+		instructions = append(instructions, &nmaGetDiskUsageOp)
+	}
+
 	return instructions, nil
 }
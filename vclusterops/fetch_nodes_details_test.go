@@ -36,3 +36,24 @@ func TestRequiredOptions(t *testing.T) {
 	assert.Empty(t, nodesDetails)
 	assert.ErrorContains(t, err, `must specify a host or host list`)
 }
+
+func TestSetDepotUsagePercent(t *testing.T) {
+	nodeDetails := NodeDetails{}
+	nodeDetails.DepotPath = "/data/test_db/v_test_db_node0001_depot"
+	nodeDetails.StorageLocList = []StorageLocation{
+		{UsageType: "DATA,TEMP", Path: "/data/test_db/v_test_db_node0001_data"},
+		{UsageType: "DEPOT", Path: "/data/test_db/v_test_db_node0001_depot", MaxSize: 1000},
+	}
+	nodeDetails.DiskUsage = []PathDiskUsage{
+		{Path: "/data/test_db/v_test_db_node0001_data", PathUsedBytes: 500},
+		{Path: "/data/test_db/v_test_db_node0001_depot", PathUsedBytes: 250},
+	}
+	nodeDetails.setDepotUsagePercent()
+	assert.InDelta(t, 25.0, nodeDetails.DepotUsagePercent, 0.001)
+
+	// no depot max size
+	nodeDetails.DepotUsagePercent = 0
+	nodeDetails.StorageLocList[1].MaxSize = 0
+	nodeDetails.setDepotUsagePercent()
+	assert.Zero(t, nodeDetails.DepotUsagePercent)
+}
@@ -0,0 +1,156 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"golang.org/x/exp/slices"
+)
+
+// The ways to authenticate to a Google Cloud Storage communal storage, besides
+// the HMAC key of the GCSAuth parameter
+const (
+	// the JSON key file of a service account
+	GCSAuthServiceAccountKey = "service-account-key"
+	// the workload identity of the hosts, from their metadata server
+	GCSAuthWorkloadIdentity = "workload-identity"
+)
+
+var gcsAuthMethods = []string{GCSAuthServiceAccountKey, GCSAuthWorkloadIdentity}
+
+const (
+	gcsAuthParam              = "gcsauth"
+	gcsServiceAccountKeyParam = "GCSServiceAccountKey"
+)
+
+// GCSAuthOptions are the ways to authenticate to a Google Cloud Storage
+// communal storage, instead of the HMAC key of the GCSAuth parameter. The key
+// of a service account is given to the server in the GCSServiceAccountKey
+// parameter, the workload identity needs no parameter.
+type GCSAuthOptions struct {
+	// GCSAuthServiceAccountKey or GCSAuthWorkloadIdentity, empty if the GCS
+	// credentials are not given by these options
+	GCSAuthMethod string
+	// the local path of the JSON key file of the service account
+	GCSServiceAccountKeyFile string
+
+	// the content of the key file, read when the options are analyzed
+	serviceAccountKey string
+}
+
+// gcsServiceAccountKey holds the fields of a service account key file that
+// are checked before the key is given to the server
+type gcsServiceAccountKey struct {
+	Type        string `json:"type"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+}
+
+// validate checks the GCS auth options against the communal storage location
+// and its configuration parameters
+func (opt *GCSAuthOptions) validate(location string, parameters map[string]string) error {
+	if opt.GCSAuthMethod == "" {
+		if opt.GCSServiceAccountKeyFile != "" {
+			return fmt.Errorf("the GCS service account key file can only be given with the GCS auth method %s",
+				GCSAuthServiceAccountKey)
+		}
+		return nil
+	}
+	if !slices.Contains(gcsAuthMethods, opt.GCSAuthMethod) {
+		return fmt.Errorf("invalid GCS auth method %q, must be one of %v", opt.GCSAuthMethod, gcsAuthMethods)
+	}
+	if !strings.HasPrefix(location, "gs://") {
+		return fmt.Errorf("the GCS auth method can only be used with a GCS communal storage location, not %q", location)
+	}
+	if _, ok := getConfigurationParameter(parameters, gcsAuthParam); ok {
+		return fmt.Errorf("the GCSAuth parameter cannot be given with the GCS auth method")
+	}
+	if opt.GCSAuthMethod == GCSAuthWorkloadIdentity {
+		if opt.GCSServiceAccountKeyFile != "" {
+			return fmt.Errorf("the GCS service account key file cannot be given with the GCS auth method %s",
+				GCSAuthWorkloadIdentity)
+		}
+		if _, ok := getConfigurationParameter(parameters, strings.ToLower(gcsServiceAccountKeyParam)); ok {
+			return fmt.Errorf("the %s parameter cannot be given with the GCS auth method %s",
+				gcsServiceAccountKeyParam, GCSAuthWorkloadIdentity)
+		}
+		return nil
+	}
+
+	err := util.ValidateRequiredAbsPath(opt.GCSServiceAccountKeyFile, "GCS service account key file")
+	if err != nil {
+		return err
+	}
+	// the parameter that is already given must be the key of these options,
+	// such as when the options are analyzed again
+	if value, ok := getConfigurationParameter(parameters, strings.ToLower(gcsServiceAccountKeyParam)); ok &&
+		value != opt.serviceAccountKey {
+		return fmt.Errorf("the %s parameter cannot be given with the GCS service account key file", gcsServiceAccountKeyParam)
+	}
+	return nil
+}
+
+// readServiceAccountKey reads the key file of the service account, and checks
+// that it is the key of a service account
+func (opt *GCSAuthOptions) readServiceAccountKey() error {
+	content, err := os.ReadFile(opt.GCSServiceAccountKeyFile)
+	if err != nil {
+		return fmt.Errorf("fail to read the GCS service account key file %s, details: %w", opt.GCSServiceAccountKeyFile, err)
+	}
+	key := gcsServiceAccountKey{}
+	err = json.Unmarshal(content, &key)
+	if err != nil {
+		return fmt.Errorf("the GCS service account key file %s is not valid JSON, details: %w", opt.GCSServiceAccountKeyFile, err)
+	}
+	if key.Type != "service_account" || key.ClientEmail == "" || key.PrivateKey == "" {
+		return fmt.Errorf("the GCS service account key file %s must be the key of a service account, "+
+			"with its type, client_email and private_key", opt.GCSServiceAccountKeyFile)
+	}
+	// the key is given in a parameter, on one line
+	compactKey := bytes.Buffer{}
+	err = json.Compact(&compactKey, content)
+	if err != nil {
+		return err
+	}
+	opt.serviceAccountKey = compactKey.String()
+	return nil
+}
+
+// addConfigurationParameters reads the key of the service account, if it is
+// the auth method, and adds it to the configuration parameters of a database.
+// It should be called after the options are validated.
+func (opt *GCSAuthOptions) addConfigurationParameters(parameters map[string]string) (map[string]string, error) {
+	if opt.GCSAuthMethod != GCSAuthServiceAccountKey {
+		return parameters, nil
+	}
+	if opt.serviceAccountKey == "" {
+		err := opt.readServiceAccountKey()
+		if err != nil {
+			return parameters, err
+		}
+	}
+	if parameters == nil {
+		parameters = make(map[string]string)
+	}
+	parameters[gcsServiceAccountKeyParam] = opt.serviceAccountKey
+	return parameters, nil
+}
@@ -55,6 +55,28 @@ func produceTransferConfigOps(instructions *[]clusterOp, sourceConfigHost,
 	)
 }
 
+// produceRebalanceClusterInBackgroundOps generates instructions to start a rebalance of
+// the cluster in the background, and to poll its status until it completes, so that a
+// long rebalance does not reach the timeout of a single request
+func produceRebalanceClusterInBackgroundOps(instructions *[]clusterOp, initiatorHost []string,
+	usePassword bool, userName string, password *string, timeout int, reporter ProgressReporter) error {
+	httpsStartRebalanceClusterOp, err := makeHTTPSStartRebalanceClusterOp(initiatorHost,
+		usePassword, userName, password)
+	if err != nil {
+		return err
+	}
+	httpsPollRebalanceStatusOp, err := makeHTTPSPollRebalanceStatusOp(initiatorHost,
+		usePassword, userName, password, timeout, reporter)
+	if err != nil {
+		return err
+	}
+	*instructions = append(*instructions,
+		&httpsStartRebalanceClusterOp,
+		&httpsPollRebalanceStatusOp,
+	)
+	return nil
+}
+
 // Get catalog path after we have db information from /catalog/database endpoint
 func updateCatalogPathMapFromCatalogEditor(hosts []string, nmaVDB *nmaVDatabase, catalogPathMap map[string]string) error {
 	if len(hosts) == 0 {
@@ -189,7 +211,7 @@ func (vcc VClusterCommands) getVDBFromRunningDBImpl(vdb *VCoordinationDatabase,
 
 	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
-	err = clusterOpEngine.run(vcc.Log)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if err != nil {
 		return fmt.Errorf("fail to retrieve database configurations, %w", err)
 	}
@@ -215,7 +237,7 @@ func (vcc VClusterCommands) getClusterInfoFromRunningDB(vdb *VCoordinationDataba
 
 	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
-	err = clusterOpEngine.run(vcc.Log)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if err != nil {
 		return fmt.Errorf("fail to retrieve cluster configurations, %w", err)
 	}
@@ -16,18 +16,25 @@
 package vclusterops
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/vertica/vcluster/rfc7807"
+	"github.com/vertica/vcluster/vclusterops/tracing"
 	"github.com/vertica/vcluster/vclusterops/util"
 	"github.com/vertica/vcluster/vclusterops/vlog"
 )
@@ -38,6 +45,46 @@ type httpAdapter struct {
 	respBodyHandler responseBodyHandler
 }
 
+// the transports that are shared by the requests when connections are reused,
+// keyed by the TLS credentials of the requests
+var (
+	reuseConnections   bool
+	sharedTransports   = make(map[string]*http.Transport)
+	sharedTransportsMu sync.Mutex
+)
+
+// SetReuseConnections sets whether the requests to the hosts share their
+// transports, so that the TLS connections are kept open between the commands
+// of a long-lived process, such as the vcluster shell. Turning it off closes
+// the idle connections.
+func SetReuseConnections(reuse bool) {
+	sharedTransportsMu.Lock()
+	defer sharedTransportsMu.Unlock()
+	reuseConnections = reuse
+	if !reuse {
+		for key, transport := range sharedTransports {
+			transport.CloseIdleConnections()
+			delete(sharedTransports, key)
+		}
+	}
+}
+
+// getTransport returns the transport of a request. The key identifies the TLS
+// credentials, so that requests with other credentials do not share connections.
+func getTransport(key string, tlsConfig *tls.Config) *http.Transport {
+	sharedTransportsMu.Lock()
+	defer sharedTransportsMu.Unlock()
+	if !reuseConnections {
+		return &http.Transport{TLSClientConfig: tlsConfig}
+	}
+	transport, ok := sharedTransports[key]
+	if !ok {
+		transport = &http.Transport{TLSClientConfig: tlsConfig}
+		sharedTransports[key] = transport
+	}
+	return transport
+}
+
 func makeHTTPAdapter(logger vlog.Printer) httpAdapter {
 	newHTTPAdapter := httpAdapter{}
 	newHTTPAdapter.name = "HTTPAdapter"
@@ -50,15 +97,33 @@ func makeHTTPAdapter(logger vlog.Printer) httpAdapter {
 // download a response body to a file via streaming read and
 // buffered write, rather than copying the body to memory.
 func makeHTTPDownloadAdapter(logger vlog.Printer,
-	destFilePath string) httpAdapter {
+	destFilePath string, onProgress func(bytes int64), bytesPerSecond int64) httpAdapter {
 	newHTTPAdapter := makeHTTPAdapter(logger)
 	newHTTPAdapter.respBodyHandler = &responseBodyDownloader{
-		logger,
-		destFilePath,
+		logger:         logger,
+		destFilePath:   destFilePath,
+		onProgress:     onProgress,
+		bytesPerSecond: bytesPerSecond,
 	}
 	return newHTTPAdapter
 }
 
+// makeHTTPStreamAdapter creates an HTTP adapter which reads a response body
+// line by line as it is received, and passes each line to onLine, rather than
+// returning the body once it is complete
+func makeHTTPStreamAdapter(logger vlog.Printer, onLine func(line string)) httpAdapter {
+	newHTTPAdapter := makeHTTPAdapter(logger)
+	newHTTPAdapter.respBodyHandler = &responseBodyStreamer{onLine: onLine}
+	return newHTTPAdapter
+}
+
+// the header in which the NMA returns the SHA-256 checksum of a downloaded file
+const sha256ChecksumHeader = "X-Checksum-Sha256"
+
+// errChecksumMismatch is returned when a downloaded file does not match the
+// checksum returned with it, usually because the transfer was corrupted
+var errChecksumMismatch = errors.New("checksum mismatch")
+
 type responseBodyHandler interface {
 	processResponseBody(resp *http.Response) (string, error)
 }
@@ -70,6 +135,61 @@ type responseBodyReader struct{}
 type responseBodyDownloader struct {
 	logger       vlog.Printer
 	destFilePath string
+	// if set, called with the bytes downloaded so far every downloadProgressBytes
+	onProgress func(bytes int64)
+	// the bandwidth limit of the download, no limit if 0
+	bytesPerSecond int64
+}
+
+// for reading the lines of a chunked response body as they are received
+type responseBodyStreamer struct {
+	onLine func(line string)
+}
+
+// the longest line a streamed response body can have
+const maxStreamedLineBytes = 1024 * 1024
+
+// throttledReader limits the rate of reading from a reader by sleeping whenever
+// the bytes read so far are ahead of the limit
+type throttledReader struct {
+	reader         io.Reader
+	bytesPerSecond int64
+	bytesRead      int64
+	startTime      time.Time
+}
+
+func (reader *throttledReader) Read(p []byte) (int, error) {
+	// read at most one second worth of bytes at a time to keep the rate smooth
+	if int64(len(p)) > reader.bytesPerSecond {
+		p = p[:reader.bytesPerSecond]
+	}
+	n, err := reader.reader.Read(p)
+	reader.bytesRead += int64(n)
+	expectedElapsed := time.Duration(float64(reader.bytesRead) / float64(reader.bytesPerSecond) * float64(time.Second))
+	if wait := expectedElapsed - time.Since(reader.startTime); wait > 0 {
+		time.Sleep(wait)
+	}
+	return n, err
+}
+
+// how often the progress of a download is reported
+const downloadProgressBytes = 16 * 1024 * 1024
+
+// progressWriter counts the bytes written through it and reports the count
+// every downloadProgressBytes
+type progressWriter struct {
+	written      int64
+	lastReported int64
+	onProgress   func(bytes int64)
+}
+
+func (writer *progressWriter) Write(p []byte) (int, error) {
+	writer.written += int64(len(p))
+	if writer.written-writer.lastReported >= downloadProgressBytes {
+		writer.lastReported = writer.written
+		writer.onProgress(writer.written)
+	}
+	return len(p), nil
 }
 
 const (
@@ -102,7 +222,7 @@ func (adapter *httpAdapter) sendRequest(request *hostHTTPRequest, resultChannel
 		port,
 		request.Endpoint,
 		queryParams)
-	adapter.logger.Info("Request URL", "URL", requestURL)
+	adapter.logger.Info("Request URL", "URL", requestURL, "host", adapter.host)
 
 	// whether use password (for HTTPS endpoints only)
 	usePassword, err := whetherUsePassword(request)
@@ -140,6 +260,12 @@ func (adapter *httpAdapter) sendRequest(request *hostHTTPRequest, resultChannel
 	if usePassword {
 		req.SetBasicAuth(request.Username, *request.Password)
 	}
+	if request.Traceparent != "" {
+		req.Header.Set(tracing.TraceparentHeader, request.Traceparent)
+	}
+	if request.CorrelationID != "" {
+		req.Header.Set(CorrelationIDHeader, request.CorrelationID)
+	}
 
 	// send HTTP request
 	resp, err := client.Do(req)
@@ -155,7 +281,24 @@ func (adapter *httpAdapter) sendRequest(request *hostHTTPRequest, resultChannel
 	resultChannel <- adapter.generateResult(resp)
 }
 
-func (adapter *httpAdapter) generateResult(resp *http.Response) hostHTTPResult {
+// countingBody counts the bytes read from a response body, whether the body
+// is read into memory, downloaded to a file or streamed
+type countingBody struct {
+	io.ReadCloser
+	bytesRead int64
+}
+
+func (body *countingBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	body.bytesRead += int64(n)
+	return n, err
+}
+
+func (adapter *httpAdapter) generateResult(resp *http.Response) (result hostHTTPResult) {
+	body := &countingBody{ReadCloser: resp.Body}
+	resp.Body = body
+	defer func() { result.bodyBytes = body.bytesRead }()
+
 	bodyString, err := adapter.respBodyHandler.processResponseBody(resp)
 	if err != nil {
 		return adapter.makeExceptionResult(err)
@@ -184,14 +327,56 @@ func (downloader *responseBodyDownloader) processResponseBody(resp *http.Respons
 	return readResponseBody(resp)
 }
 
-// downloadFile uses buffered read/writes to download the http response body to a file
+func (streamer *responseBodyStreamer) processResponseBody(resp *http.Response) (bodyString string, err error) {
+	if !isSuccess(resp) {
+		// in case of error, we get an RFC7807 error, not a stream
+		return readResponseBody(resp)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxStreamedLineBytes)
+	for scanner.Scan() {
+		streamer.onLine(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("fail to stream the response body: %w", err)
+	}
+	return "", nil
+}
+
+// downloadFile uses buffered read/writes to download the http response body to a file.
+// If the response has a checksum header, the downloaded file is verified against it.
 func (downloader *responseBodyDownloader) downloadFile(resp *http.Response) (bytesWritten int64, err error) {
 	file, err := os.Create(downloader.destFilePath)
 	if err != nil {
 		return 0, err
 	}
 	defer file.Close()
-	return io.Copy(file, resp.Body)
+	hasher := sha256.New()
+	writers := []io.Writer{file, hasher}
+	if downloader.onProgress != nil {
+		writers = append(writers, &progressWriter{onProgress: downloader.onProgress})
+	}
+	var body io.Reader = resp.Body
+	if downloader.bytesPerSecond > 0 {
+		body = &throttledReader{reader: resp.Body, bytesPerSecond: downloader.bytesPerSecond, startTime: time.Now()}
+	}
+	bytesWritten, err = io.Copy(io.MultiWriter(writers...), body)
+	if err != nil {
+		return bytesWritten, err
+	}
+
+	expectedChecksum := resp.Header.Get(sha256ChecksumHeader)
+	if expectedChecksum == "" {
+		// older NMA versions do not return a checksum
+		downloader.logger.Info("No checksum returned, skipping verification", "File", downloader.destFilePath)
+		return bytesWritten, nil
+	}
+	actualChecksum := hex.EncodeToString(hasher.Sum(nil))
+	if !strings.EqualFold(expectedChecksum, actualChecksum) {
+		return bytesWritten, fmt.Errorf("%w: expected SHA-256 %s, got %s", errChecksumMismatch,
+			expectedChecksum, actualChecksum)
+	}
+	return bytesWritten, nil
 }
 
 // readResponseBody attempts to read the entire contents of the http response into bodyString
@@ -342,11 +527,9 @@ func (adapter *httpAdapter) setupHTTPClient(
 		//nolint:gosec
 		client = &http.Client{
 			Timeout: time.Second * requestTimeout,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true,
-				},
-			},
+			Transport: getTransport("password", &tls.Config{
+				InsecureSkipVerify: true,
+			}),
 		}
 	} else {
 		var cert tls.Certificate
@@ -360,6 +543,11 @@ func (adapter *httpAdapter) setupHTTPClient(
 		if err != nil {
 			return client, err
 		}
+		// the transports are shared by the requests with the same client certificate
+		certHash := sha256.New()
+		for _, certBytes := range cert.Certificate {
+			certHash.Write(certBytes)
+		}
 		// for both http and nma, we have to use `InsecureSkipVerify: true` here
 		// because the certs are self signed at this time
 		// TODO: update the InsecureSkipVerify once we start to use non-self-signed certs
@@ -367,13 +555,11 @@ func (adapter *httpAdapter) setupHTTPClient(
 		//nolint:gosec
 		client = &http.Client{
 			Timeout: time.Second * requestTimeout,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					Certificates:       []tls.Certificate{cert},
-					RootCAs:            caCertPool,
-					InsecureSkipVerify: true,
-				},
-			},
+			Transport: getTransport("cert-"+hex.EncodeToString(certHash.Sum(nil)), &tls.Config{
+				Certificates:       []tls.Certificate{cert},
+				RootCAs:            caCertPool,
+				InsecureSkipVerify: true,
+			}),
 		}
 	}
 	return client, nil
@@ -16,6 +16,9 @@
 package vclusterops
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"io"
@@ -24,6 +27,7 @@ import (
 	"path"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vertica/vcluster/rfc7807"
@@ -214,3 +218,82 @@ func TestHandleGenericErrorResponse(t *testing.T) {
 	assert.False(t, ok)
 	assert.Contains(t, result.err.Error(), errorMessage)
 }
+
+func TestHandleFileDownloadChecksum(t *testing.T) {
+	destFilePath := path.Join(t.TempDir(), "download.tgz")
+	adapter := httpAdapter{respBodyHandler: &responseBodyDownloader{destFilePath: destFilePath}}
+	// not the SHA-256 of the content
+	const badChecksum = "e4c6fd55a3a0d5fb1e1a1e2d0c3e2a9bd7bb49a5beb9b32b51cd89ca0c0d3bb0"
+	content := []byte("tarball content")
+	sum := sha256.Sum256(content)
+	goodChecksum := hex.EncodeToString(sum[:])
+
+	// a file matching the checksum is accepted
+	mockResp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       &MockReadCloser{body: content},
+	}
+	mockResp.Header.Add(sha256ChecksumHeader, goodChecksum)
+	result := adapter.generateResult(mockResp)
+	assert.Equal(t, SUCCESS, result.status)
+	// the downloaded bytes are counted though they are not in the content
+	assert.Empty(t, result.content)
+	assert.Equal(t, int64(len(content)), result.bodyBytes)
+
+	// a corrupted file is rejected
+	mockResp = &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       &MockReadCloser{body: content},
+	}
+	mockResp.Header.Add(sha256ChecksumHeader, badChecksum)
+	result = adapter.generateResult(mockResp)
+	assert.Equal(t, EXCEPTION, result.status)
+	assert.ErrorIs(t, result.err, errChecksumMismatch)
+
+	// no checksum, no verification
+	mockResp = &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       &MockReadCloser{body: content},
+	}
+	result = adapter.generateResult(mockResp)
+	assert.Equal(t, SUCCESS, result.status)
+}
+
+func TestHandleStreamedResponse(t *testing.T) {
+	var lines []string
+	adapter := httpAdapter{respBodyHandler: &responseBodyStreamer{onLine: func(line string) { lines = append(lines, line) }}}
+	mockResp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       &MockReadCloser{body: []byte("first line\nsecond line\nlast line")},
+	}
+	result := adapter.generateResult(mockResp)
+	assert.Equal(t, SUCCESS, result.status)
+	assert.Equal(t, []string{"first line", "second line", "last line"}, lines)
+
+	// an error is read as a whole instead of streamed
+	lines = nil
+	mockResp = &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{},
+		Body:       &MockReadCloser{body: []byte("no such log")},
+	}
+	result = adapter.generateResult(mockResp)
+	assert.Equal(t, FAILURE, result.status)
+	assert.Contains(t, result.err.Error(), "no such log")
+	assert.Empty(t, lines)
+}
+
+func TestThrottledReader(t *testing.T) {
+	const bytesPerSecond = 1000
+	content := bytes.Repeat([]byte("x"), 200)
+	reader := &throttledReader{reader: bytes.NewReader(content), bytesPerSecond: bytesPerSecond, startTime: time.Now()}
+	readContent, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, content, readContent)
+	// reading 200 bytes at 1000 bytes per second takes at least 200ms
+	assert.GreaterOrEqual(t, time.Since(reader.startTime), 200*time.Millisecond)
+}
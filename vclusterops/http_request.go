@@ -29,6 +29,11 @@ type hostHTTPRequest struct {
 	// optional, for calling NMA/Vertica HTTPS endpoints. If Username/Password is set, that takes precedence over this for HTTPS calls.
 	UseCertsInOptions bool
 	Certs             httpsCerts
+
+	// the W3C trace-context header of the span of the request, empty if tracing is disabled
+	Traceparent string
+	// the correlation ID of the command that sends the request
+	CorrelationID string
 }
 
 type httpsCerts struct {
@@ -16,19 +16,133 @@
 package vclusterops
 
 import (
+	"context"
+	"sync"
+
 	"github.com/theckman/yacspin"
 	"github.com/vertica/vcluster/vclusterops/vlog"
 )
 
 type requestDispatcher struct {
 	opBase
-	pool adapterPool
+	pool     adapterPool
+	workload *opEngineWorkload
+	// canceling this context stops waiting for the responses of the hosts
+	ctx context.Context
+}
+
+// OpEngineWorkload summarizes the administrative load that a command
+// puts on the management network
+type OpEngineWorkload struct {
+	HostsTouched   int   `json:"hostsTouched"`   // number of distinct hosts that received at least one request
+	RequestsIssued int   `json:"requestsIssued"` // number of requests sent to the NMA or Vertica HTTPS service
+	BytesSent      int64 `json:"bytesSent"`      // total size of the request bodies
+	BytesReceived  int64 `json:"bytesReceived"`  // total size of the response bodies, including downloaded files
+}
+
+// opEngineWorkload accumulates the workload of the requests sent by the dispatcher
+type opEngineWorkload struct {
+	hosts          map[string]struct{}
+	requestsIssued int
+	bytesSent      int64
+	bytesReceived  int64
+}
+
+func makeOpEngineWorkload() *opEngineWorkload {
+	return &opEngineWorkload{hosts: make(map[string]struct{})}
+}
+
+// recordRequest adds a request sent to a host to the workload.
+// The methods of a nil workload do nothing.
+func (workload *opEngineWorkload) recordRequest(host string, request *hostHTTPRequest) {
+	if workload == nil {
+		return
+	}
+	workload.hosts[host] = struct{}{}
+	workload.requestsIssued++
+	workload.bytesSent += int64(len(request.RequestData))
+}
+
+// recordResult adds the response of a host to the workload, including the
+// bytes of a response body that was downloaded to a file
+func (workload *opEngineWorkload) recordResult(result *hostHTTPResult) {
+	if workload == nil {
+		return
+	}
+	workload.bytesReceived += result.bodyBytes
+}
+
+// add adds another workload to the workload, a host touched by both is counted once
+func (workload *opEngineWorkload) add(other *opEngineWorkload) {
+	for host := range other.hosts {
+		workload.hosts[host] = struct{}{}
+	}
+	workload.requestsIssued += other.requestsIssued
+	workload.bytesSent += other.bytesSent
+	workload.bytesReceived += other.bytesReceived
+}
+
+// WorkloadSummary accumulates the workload of the op engines of a command.
+// A command can run several op engines, so the summary is given to the
+// engines in the context of the commands:
+//
+//	summary := NewWorkloadSummary()
+//	vcc.Ctx = ContextWithWorkloadSummary(ctx, summary)
+type WorkloadSummary struct {
+	mu       sync.Mutex
+	workload *opEngineWorkload
+}
+
+func NewWorkloadSummary() *WorkloadSummary {
+	return &WorkloadSummary{workload: makeOpEngineWorkload()}
+}
+
+type workloadSummaryContextKey struct{}
+
+// ContextWithWorkloadSummary returns a context whose op engines add their
+// workload to the summary
+func ContextWithWorkloadSummary(ctx context.Context, summary *WorkloadSummary) context.Context {
+	return context.WithValue(ctx, workloadSummaryContextKey{}, summary)
+}
+
+// getWorkloadSummary returns the summary of a context, or nil. The methods
+// of a nil summary do nothing.
+func getWorkloadSummary(ctx context.Context) *WorkloadSummary {
+	summary, _ := ctx.Value(workloadSummaryContextKey{}).(*WorkloadSummary)
+	return summary
+}
+
+func (summary *WorkloadSummary) add(workload *opEngineWorkload) {
+	if summary == nil {
+		return
+	}
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+	summary.workload.add(workload)
+}
+
+// Workload returns the workload of the op engines that have run so far
+func (summary *WorkloadSummary) Workload() OpEngineWorkload {
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+	return summary.workload.summary()
+}
+
+func (workload *opEngineWorkload) summary() OpEngineWorkload {
+	return OpEngineWorkload{
+		HostsTouched:   len(workload.hosts),
+		RequestsIssued: workload.requestsIssued,
+		BytesSent:      workload.bytesSent,
+		BytesReceived:  workload.bytesReceived,
+	}
 }
 
 func makeHTTPRequestDispatcher(logger vlog.Printer) requestDispatcher {
 	newHTTPRequestDispatcher := requestDispatcher{}
 	newHTTPRequestDispatcher.name = "HTTPRequestDispatcher"
 	newHTTPRequestDispatcher.logger = logger.WithName(newHTTPRequestDispatcher.name)
+	newHTTPRequestDispatcher.workload = makeOpEngineWorkload()
+	newHTTPRequestDispatcher.ctx = context.Background()
 
 	return newHTTPRequestDispatcher
 }
@@ -45,13 +159,40 @@ func (dispatcher *requestDispatcher) setup(hosts []string) {
 	}
 }
 
+// downloadOptions tune the downloads set up by setupForDownload
+type downloadOptions struct {
+	// if set, called with the bytes downloaded from a host so far
+	onProgress func(host string, bytes int64)
+	// the bandwidth limit of each download, no limit if 0
+	bytesPerSecond int64
+}
+
 // set up the pool connection for each host to download a file
 func (dispatcher *requestDispatcher) setupForDownload(hosts []string,
-	hostToFilePathsMap map[string]string) {
+	hostToFilePathsMap map[string]string, options downloadOptions) {
 	dispatcher.pool = getPoolInstance(dispatcher.logger)
 
 	for _, host := range hosts {
-		adapter := makeHTTPDownloadAdapter(dispatcher.logger, hostToFilePathsMap[host])
+		var hostOnProgress func(bytes int64)
+		if options.onProgress != nil {
+			host := host
+			hostOnProgress = func(bytes int64) { options.onProgress(host, bytes) }
+		}
+		adapter := makeHTTPDownloadAdapter(dispatcher.logger, hostToFilePathsMap[host], hostOnProgress,
+			options.bytesPerSecond)
+		adapter.host = host
+		dispatcher.pool.connections[host] = &adapter
+	}
+}
+
+// set up the pool connection for each host to stream the lines of a response
+func (dispatcher *requestDispatcher) setupForStream(hosts []string, onLine func(host, line string)) {
+	dispatcher.pool = getPoolInstance(dispatcher.logger)
+
+	dispatcher.pool.connections = make(map[string]adapter)
+	for _, host := range hosts {
+		host := host
+		adapter := makeHTTPStreamAdapter(dispatcher.logger, func(line string) { onLine(host, line) })
 		adapter.host = host
 		dispatcher.pool.connections[host] = &adapter
 	}
@@ -59,5 +200,6 @@ func (dispatcher *requestDispatcher) setupForDownload(hosts []string,
 
 func (dispatcher *requestDispatcher) sendRequest(httpRequest *clusterHTTPRequest, spinner *yacspin.Spinner) error {
 	dispatcher.logger.Info("HTTP request dispatcher's sendRequest is called")
-	return dispatcher.pool.sendRequest(httpRequest, spinner)
+	// the requests that are sent are recorded even if they fail
+	return dispatcher.pool.sendRequest(dispatcher.ctx, httpRequest, spinner, dispatcher.workload)
 }
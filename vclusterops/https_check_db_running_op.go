@@ -34,6 +34,7 @@ const (
 	StartDB
 	ReviveDB
 	StopSC
+	RecoverCatalog
 
 	checkDBRunningOpName = "HTTPSCheckDBRunningOp"
 	checkDBRunningOpDesc = "Verify database is running"
@@ -51,6 +52,8 @@ func (op opType) String() string {
 		return "Revive DB"
 	case StopSC:
 		return "Stop Subcluster"
+	case RecoverCatalog:
+		return "Recover Catalog"
 	}
 	return "unknown operation"
 }
@@ -216,7 +219,7 @@ func (op *httpsCheckRunningDBOp) isDBRunningOnHost(host string,
 		case CreateDB:
 			msg = fmt.Sprintf("[%s] Detected HTTPS service running on host %s, please stop the HTTPS service before creating a new database",
 				op.name, host)
-		case StopDB, StartDB, ReviveDB, StopSC:
+		case StopDB, StartDB, ReviveDB, StopSC, RecoverCatalog:
 			msg = fmt.Sprintf("[%s] Detected HTTPS service running on host %s", op.name, host)
 		}
 		// check whether the node is starting and hasn't pulled the latest catalog yet
@@ -352,6 +355,10 @@ func (op *httpsCheckRunningDBOp) handleDBRunning(allErrs error, msg string, upHo
 		const reviveDBMsg = "aborting database revival"
 		op.logger.PrintInfo(reviveDBMsg)
 		op.updateSpinnerMessage(reviveDBMsg)
+	case RecoverCatalog:
+		const recoverCatalogMsg = "aborting catalog recovery, the database must be down"
+		op.logger.PrintInfo(recoverCatalogMsg)
+		op.updateSpinnerMessage(recoverCatalogMsg)
 	}
 
 	// when db is running, append an error to allErrs for stopping VClusterOpEngine
@@ -401,7 +408,7 @@ func (op *httpsCheckRunningDBOp) checkProcessedResult(sandboxedHosts map[string]
 func (op *httpsCheckRunningDBOp) execute(execContext *opEngineExecContext) error {
 	op.logger.Info("Execute() called", "opType", op.opType)
 	switch op.opType {
-	case CreateDB, StartDB, ReviveDB:
+	case CreateDB, StartDB, ReviveDB, RecoverCatalog:
 		return op.checkDBConnection(execContext)
 	case StopDB, StopSC:
 		return op.pollForDBDown(execContext)
@@ -36,9 +36,14 @@ func makeHTTPSCreateNodeOp(newNodeHosts []string, bootstrapHost []string,
 	op.description = "Create node in catalog"
 	op.hosts = bootstrapHost
 	op.RequestParams = make(map[string]string)
-	// HTTPS create node endpoint requires passing everything before node name
-	op.RequestParams["catalog-prefix"] = vdb.CatalogPrefix + "/" + vdb.Name
-	op.RequestParams["data-prefix"] = vdb.DataPrefix + "/" + vdb.Name
+	// HTTPS create node endpoint requires passing everything before node name.
+	// The new nodes are expected to share the same path prefixes.
+	var newNodeHost string
+	if len(newNodeHosts) > 0 {
+		newNodeHost = newNodeHosts[0]
+	}
+	op.RequestParams["catalog-prefix"] = vdb.getCatalogPrefix(newNodeHost) + "/" + vdb.Name
+	op.RequestParams["data-prefix"] = vdb.getDataPrefix(newNodeHost) + "/" + vdb.Name
 	op.RequestParams["hosts"] = util.ArrayToString(newNodeHosts, ",")
 	if scName != "" {
 		op.RequestParams["subcluster"] = scName
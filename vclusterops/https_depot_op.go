@@ -0,0 +1,131 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"golang.org/x/exp/maps"
+)
+
+type httpsDepotOp struct {
+	opBase
+	opHTTPSBase
+	// the name of the node on each host. The request for a node is sent
+	// to its own host.
+	hostNodeNames map[string]string
+	method        string
+	// the path under nodes/<node name>/depot of the endpoint
+	endpointSuffix string
+	requestParams  map[string]string
+}
+
+// makeHTTPSAlterDepotOp makes an op that resizes the depot storage location of
+// each node. The size is either a percentage of the disk, e.g., 40%, or a size
+// in bytes, e.g., 100G.
+func makeHTTPSAlterDepotOp(hostNodeNames map[string]string, useHTTPPassword bool, userName string,
+	httpsPassword *string, size string) (httpsDepotOp, error) {
+	op, err := makeHTTPSDepotOp(hostNodeNames, useHTTPPassword, userName, httpsPassword)
+	op.name = "HTTPSAlterDepotOp"
+	op.description = "Resize depot"
+	op.method = PutMethod
+	op.requestParams = map[string]string{"size": size}
+	return op, err
+}
+
+// makeHTTPSClearDepotCacheOp makes an op that evicts the content of the depot of each node
+func makeHTTPSClearDepotCacheOp(hostNodeNames map[string]string, useHTTPPassword bool, userName string,
+	httpsPassword *string) (httpsDepotOp, error) {
+	op, err := makeHTTPSDepotOp(hostNodeNames, useHTTPPassword, userName, httpsPassword)
+	op.name = "HTTPSClearDepotCacheOp"
+	op.description = "Clear depot cache"
+	op.method = DeleteMethod
+	op.endpointSuffix = "/cache"
+	return op, err
+}
+
+func makeHTTPSDepotOp(hostNodeNames map[string]string, useHTTPPassword bool, userName string,
+	httpsPassword *string) (httpsDepotOp, error) {
+	op := httpsDepotOp{}
+	op.hosts = maps.Keys(hostNodeNames)
+	sort.Strings(op.hosts)
+	op.hostNodeNames = hostNodeNames
+	op.useHTTPPassword = useHTTPPassword
+
+	if useHTTPPassword {
+		err := util.ValidateUsernameAndPassword("HTTPSDepotOp", useHTTPPassword, userName)
+		if err != nil {
+			return op, err
+		}
+		op.userName = userName
+		op.httpsPassword = httpsPassword
+	}
+	return op, nil
+}
+
+func (op *httpsDepotOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = op.method
+		httpRequest.buildHTTPSEndpoint("nodes/" + op.hostNodeNames[host] + "/depot" + op.endpointSuffix)
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+		httpRequest.QueryParams = op.requestParams
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsDepotOp) prepare(execContext *opEngineExecContext) error {
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsDepotOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsDepotOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isUnauthorizedRequest() {
+			return makeAuthFailureError(op.name, host)
+		}
+		if !result.isPassing() {
+			// not break here because we want to log all the failed nodes
+			allErrs = errors.Join(allErrs, result.err)
+		}
+	}
+
+	return allErrs
+}
+
+func (op *httpsDepotOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
@@ -0,0 +1,170 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+type httpsExecuteSQLOp struct {
+	opBase
+	opHTTPSBase
+	sql string
+	// filled in with the response once the op completes
+	sqlResults *[]SQLResult
+}
+
+type executeSQLRequestData struct {
+	SQL string `json:"sql"`
+}
+
+// makeHTTPSExecuteSQLOp makes an op that runs a SQL statement, or a script of
+// statements separated by semicolons, on an up host among the given hosts
+func makeHTTPSExecuteSQLOp(hosts []string, useHTTPPassword bool, userName string,
+	httpsPassword *string, sql string, sqlResults *[]SQLResult) (httpsExecuteSQLOp, error) {
+	op := httpsExecuteSQLOp{}
+	op.name = "HTTPSExecuteSQLOp"
+	op.description = "Execute SQL"
+	op.hosts = hosts
+	op.useHTTPPassword = useHTTPPassword
+	op.sql = sql
+	op.sqlResults = sqlResults
+
+	if useHTTPPassword {
+		err := util.ValidateUsernameAndPassword(op.name, useHTTPPassword, userName)
+		if err != nil {
+			return op, err
+		}
+		op.userName = userName
+		op.httpsPassword = httpsPassword
+	}
+
+	return op, nil
+}
+
+func (op *httpsExecuteSQLOp) setupClusterHTTPRequest(hosts []string) error {
+	dataBytes, err := json.Marshal(executeSQLRequestData{SQL: op.sql})
+	if err != nil {
+		return fmt.Errorf("[%s] fail to marshal request data to JSON string, detail %w", op.name, err)
+	}
+
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildHTTPSEndpoint("sql")
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+		httpRequest.RequestData = string(dataBytes)
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsExecuteSQLOp) prepare(execContext *opEngineExecContext) error {
+	host := getInitiatorFromUpHosts(execContext.upHosts, op.hosts)
+	if host == "" {
+		return fmt.Errorf(`[%s] cannot find any up hosts among the provided hosts %v`, op.name, op.hosts)
+	}
+
+	op.hosts = []string{host}
+
+	execContext.dispatcher.setup(op.hosts)
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsExecuteSQLOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsExecuteSQLOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+// The response has a result for each statement, in the order of the statements.
+// Values are returned as text, and NULL values as null.
+/*
+	{
+	  "results": [
+		{
+		  "columns": ["node_name", "node_state"],
+		  "rows": [
+			["v_test_db_node0001", "UP"],
+			["v_test_db_node0002", null]
+		  ],
+		  "rows_affected": 0
+		},
+		...
+	  ]
+	}
+*/
+type executeSQLResp struct {
+	Results []SQLResult `json:"results"`
+}
+
+// SQLResult is the result of a SQL statement. The rows are empty for
+// statements that do not return rows, such as DDL and DML statements.
+type SQLResult struct {
+	Columns []string `json:"columns"`
+	// the values of each row, in the order of the columns. A NULL value is nil.
+	Rows         [][]*string `json:"rows"`
+	RowsAffected int64       `json:"rows_affected"`
+}
+
+// GetColumnIndex returns the index of a column in the rows, or -1 if the
+// result has no such column
+func (r *SQLResult) GetColumnIndex(column string) int {
+	for i, name := range r.Columns {
+		if name == column {
+			return i
+		}
+	}
+	return -1
+}
+
+func (op *httpsExecuteSQLOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isUnauthorizedRequest() {
+			return makeAuthFailureError(op.name, host)
+		}
+
+		if result.isPassing() {
+			resp := executeSQLResp{}
+			err := op.parseAndCheckResponse(host, result.content, &resp)
+			if err != nil {
+				allErrs = errors.Join(allErrs, err)
+				return appendHTTPSFailureError(allErrs)
+			}
+			*op.sqlResults = resp.Results
+			return nil
+		}
+		allErrs = errors.Join(allErrs, result.err)
+	}
+	return appendHTTPSFailureError(allErrs)
+}
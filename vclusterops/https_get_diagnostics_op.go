@@ -0,0 +1,181 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+type httpsGetDiagnosticsOp struct {
+	opBase
+	opHTTPSBase
+	sinceMinutes int
+	limit        int
+	// filled in with the response once the op completes
+	diagnostics *diagnosticsResp
+}
+
+// makeHTTPSGetDiagnosticsOp makes an op that pulls the recent slow events and the
+// summary of errors of all nodes from an up host. The events are read from
+// dc_slow_events and the errors from dc_errors.
+func makeHTTPSGetDiagnosticsOp(hosts []string, useHTTPPassword bool, userName string,
+	httpsPassword *string, sinceMinutes, limit int, diagnostics *diagnosticsResp) (httpsGetDiagnosticsOp, error) {
+	op := httpsGetDiagnosticsOp{}
+	op.name = "HTTPSGetDiagnosticsOp"
+	op.description = "Collect recent slow events and errors"
+	op.hosts = hosts
+	op.useHTTPPassword = useHTTPPassword
+	op.sinceMinutes = sinceMinutes
+	op.limit = limit
+	op.diagnostics = diagnostics
+
+	if useHTTPPassword {
+		err := util.ValidateUsernameAndPassword(op.name, useHTTPPassword, userName)
+		if err != nil {
+			return op, err
+		}
+		op.userName = userName
+		op.httpsPassword = httpsPassword
+	}
+
+	return op, nil
+}
+
+func (op *httpsGetDiagnosticsOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.buildHTTPSEndpoint("diagnostics")
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+		httpRequest.QueryParams = map[string]string{
+			"since-minutes": strconv.Itoa(op.sinceMinutes),
+			"limit":         strconv.Itoa(op.limit),
+		}
+
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsGetDiagnosticsOp) prepare(execContext *opEngineExecContext) error {
+	host := getInitiatorFromUpHosts(execContext.upHosts, op.hosts)
+	if host == "" {
+		return fmt.Errorf(`[%s] cannot find any up hosts among the provided hosts %v`, op.name, op.hosts)
+	}
+
+	// the data collector tables of all nodes can be queried from one up host
+	op.hosts = []string{host}
+
+	execContext.dispatcher.setup(op.hosts)
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsGetDiagnosticsOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsGetDiagnosticsOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+// The response should look like
+/*
+	{
+	  "slow_events": [
+		{
+		  "node_name": "v_test_db_node0001",
+		  "time": "2024-03-01 10:12:33.123456-05",
+		  "event_description": "Lock Attempts",
+		  "threshold_us": 1000000,
+		  "duration_us": 3412032,
+		  "transaction_id": 45035996273705083,
+		  "statement_id": 1
+		},
+		...
+	  ],
+	  "errors": [
+		{
+		  "node_name": "v_test_db_node0001",
+		  "error_level_name": "ERROR",
+		  "error_code": 4566,
+		  "message": "Relation \"t1\" does not exist",
+		  "count": 12,
+		  "last_seen": "2024-03-01 10:15:02.000000-05"
+		},
+		...
+	  ]
+	}
+*/
+type diagnosticsResp struct {
+	SlowEvents []SlowEvent    `json:"slow_events"`
+	Errors     []ErrorSummary `json:"errors"`
+}
+
+// SlowEvent is an event from dc_slow_events
+type SlowEvent struct {
+	NodeName         string `json:"node_name"`
+	Time             string `json:"time"`
+	EventDescription string `json:"event_description"`
+	ThresholdUs      int64  `json:"threshold_us"`
+	DurationUs       int64  `json:"duration_us"`
+	TransactionID    uint64 `json:"transaction_id"`
+	StatementID      uint64 `json:"statement_id"`
+}
+
+// ErrorSummary is the number of occurrences of an error on a node
+type ErrorSummary struct {
+	NodeName   string `json:"node_name"`
+	ErrorLevel string `json:"error_level_name"`
+	ErrorCode  int    `json:"error_code"`
+	Message    string `json:"message"`
+	Count      int    `json:"count"`
+	LastSeen   string `json:"last_seen"`
+}
+
+func (op *httpsGetDiagnosticsOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isUnauthorizedRequest() {
+			return makeAuthFailureError(op.name, host)
+		}
+
+		if result.isPassing() {
+			err := op.parseAndCheckResponse(host, result.content, op.diagnostics)
+			if err != nil {
+				allErrs = errors.Join(allErrs, err)
+				return appendHTTPSFailureError(allErrs)
+			}
+
+			return nil
+		}
+		allErrs = errors.Join(allErrs, result.err)
+	}
+	return appendHTTPSFailureError(allErrs)
+}
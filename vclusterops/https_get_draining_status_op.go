@@ -0,0 +1,146 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+type httpsGetDrainingStatusOp struct {
+	opBase
+	opHTTPSBase
+	// filled in with the response once the op completes
+	drainingStatus *drainingStatusResp
+}
+
+// makeHTTPSGetDrainingStatusOp makes an op that gets the draining state and the
+// number of client sessions of all nodes from an up host
+func makeHTTPSGetDrainingStatusOp(hosts []string, useHTTPPassword bool, userName string,
+	httpsPassword *string, drainingStatus *drainingStatusResp) (httpsGetDrainingStatusOp, error) {
+	op := httpsGetDrainingStatusOp{}
+	op.name = "HTTPSGetDrainingStatusOp"
+	op.description = "Get draining status of nodes"
+	op.hosts = hosts
+	op.useHTTPPassword = useHTTPPassword
+	op.drainingStatus = drainingStatus
+
+	if useHTTPPassword {
+		err := util.ValidateUsernameAndPassword(op.name, useHTTPPassword, userName)
+		if err != nil {
+			return op, err
+		}
+		op.userName = userName
+		op.httpsPassword = httpsPassword
+	}
+
+	return op, nil
+}
+
+func (op *httpsGetDrainingStatusOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.buildHTTPSEndpoint("subclusters/draining-status")
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsGetDrainingStatusOp) prepare(execContext *opEngineExecContext) error {
+	host := getInitiatorFromUpHosts(execContext.upHosts, op.hosts)
+	if host == "" {
+		return fmt.Errorf(`[%s] cannot find any up hosts among the provided hosts %v`, op.name, op.hosts)
+	}
+
+	op.hosts = []string{host}
+
+	execContext.dispatcher.setup(op.hosts)
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsGetDrainingStatusOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsGetDrainingStatusOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+// The response should look like
+/*
+	{
+	  "draining_status_list": [
+		{
+		  "node_name": "v_test_db_node0001",
+		  "subcluster_name": "default_subcluster",
+		  "is_draining": false,
+		  "count_client_user_sessions": 3,
+		  "oldest_session_user": "dbadmin",
+		  "oldest_session_login_time": "2024-03-01 10:12:33.123456-05"
+		},
+		...
+	  ]
+	}
+*/
+type drainingStatusResp struct {
+	DrainingStatusList []NodeDrainingStatus `json:"draining_status_list"`
+}
+
+// NodeDrainingStatus is the draining state and the client sessions of a node
+type NodeDrainingStatus struct {
+	NodeName               string `json:"node_name"`
+	SubclusterName         string `json:"subcluster_name"`
+	IsDraining             bool   `json:"is_draining"`
+	ClientSessionCount     int    `json:"count_client_user_sessions"`
+	OldestSessionUser      string `json:"oldest_session_user"`
+	OldestSessionLoginTime string `json:"oldest_session_login_time"`
+}
+
+func (op *httpsGetDrainingStatusOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isUnauthorizedRequest() {
+			return makeAuthFailureError(op.name, host)
+		}
+
+		if result.isPassing() {
+			err := op.parseAndCheckResponse(host, result.content, op.drainingStatus)
+			if err != nil {
+				allErrs = errors.Join(allErrs, err)
+				return appendHTTPSFailureError(allErrs)
+			}
+
+			return nil
+		}
+		allErrs = errors.Join(allErrs, result.err)
+	}
+	return appendHTTPSFailureError(allErrs)
+}
@@ -0,0 +1,157 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+type httpsGetLibrariesOp struct {
+	opBase
+	opHTTPSBase
+}
+
+// makeHTTPSGetLibrariesOp makes an op that finds the UDx libraries of the database,
+// including the libraries of the installed packages. If no hosts are given, the
+// libraries are found from the first up host found by a previous op.
+func makeHTTPSGetLibrariesOp(hosts []string, useHTTPPassword bool,
+	userName string, httpsPassword *string) (httpsGetLibrariesOp, error) {
+	op := httpsGetLibrariesOp{}
+	op.name = "HTTPSGetLibrariesOp"
+	op.description = "Get UDx libraries"
+	op.hosts = hosts
+
+	err := util.ValidateUsernameAndPassword(op.name, useHTTPPassword, userName)
+	if err != nil {
+		return op, err
+	}
+	op.useHTTPPassword = useHTTPPassword
+	op.userName = userName
+	op.httpsPassword = httpsPassword
+	return op, nil
+}
+
+func (op *httpsGetLibrariesOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.buildHTTPSEndpoint("libraries")
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsGetLibrariesOp) prepare(execContext *opEngineExecContext) error {
+	if len(op.hosts) == 0 {
+		if len(execContext.upHosts) == 0 {
+			return fmt.Errorf(`[%s] Cannot find any up hosts in OpEngineExecContext`, op.name)
+		}
+		op.hosts = []string{execContext.upHosts[0]}
+	}
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsGetLibrariesOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsGetLibrariesOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+/*
+The response from the libraries endpoint will look like this:
+
+{"libraries": [
+
+	{
+	  "schema_name": "public",
+	  "lib_name": "MyFunctions",
+	  "file_path": "Libraries/02fc2ad1e5a6b7bd0c5b4f6b3e2e0e1d00a000000001f43a/MyFunctions.so",
+	  "md5sum": "9a4e2ab15a4a6c4e0f8d0e4cf0b5c0ab",
+	  "package_name": ""
+	},
+	...
+	]
+}
+*/
+
+type userLibraryList struct {
+	Libraries []userLibrary `json:"libraries"`
+}
+
+type userLibrary struct {
+	SchemaName string `json:"schema_name"`
+	LibName    string `json:"lib_name"`
+	// the path of the library file, relative to the catalog directory of the node
+	FilePath string `json:"file_path"`
+	MD5Sum   string `json:"md5sum"`
+	// the package that installed the library, empty for the libraries created by users
+	PackageName string `json:"package_name"`
+}
+
+// hasPackageLibraries returns true if any of the libraries is installed by a package
+func hasPackageLibraries(libraries []userLibrary) bool {
+	for i := range libraries {
+		if libraries[i].PackageName != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (op *httpsGetLibrariesOp) processResult(execContext *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isUnauthorizedRequest() {
+			return makeAuthFailureError(op.name, host)
+		}
+
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+			continue
+		}
+
+		libraryList := userLibraryList{}
+		err := op.parseAndCheckResponse(host, result.content, &libraryList)
+		if err != nil {
+			allErrs = errors.Join(allErrs, err)
+			continue
+		}
+		execContext.userLibraries = libraryList.Libraries
+		op.logger.Info("found UDx libraries", "count", len(libraryList.Libraries))
+		return nil
+	}
+
+	return appendHTTPSFailureError(allErrs)
+}
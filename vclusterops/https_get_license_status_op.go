@@ -0,0 +1,154 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+type httpsGetLicenseStatusOp struct {
+	opBase
+	opHTTPSBase
+	// filled in with the response once the op completes
+	licenseStatus *licenseStatusResp
+}
+
+// makeHTTPSGetLicenseStatusOp makes an op that gets the installed licenses and the
+// compliance of the database with them from an up host
+func makeHTTPSGetLicenseStatusOp(hosts []string, useHTTPPassword bool, userName string,
+	httpsPassword *string, licenseStatus *licenseStatusResp) (httpsGetLicenseStatusOp, error) {
+	op := httpsGetLicenseStatusOp{}
+	op.name = "HTTPSGetLicenseStatusOp"
+	op.description = "Get license status"
+	op.hosts = hosts
+	op.useHTTPPassword = useHTTPPassword
+	op.licenseStatus = licenseStatus
+
+	if useHTTPPassword {
+		err := util.ValidateUsernameAndPassword(op.name, useHTTPPassword, userName)
+		if err != nil {
+			return op, err
+		}
+		op.userName = userName
+		op.httpsPassword = httpsPassword
+	}
+
+	return op, nil
+}
+
+func (op *httpsGetLicenseStatusOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.buildHTTPSEndpoint("license")
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsGetLicenseStatusOp) prepare(execContext *opEngineExecContext) error {
+	host := getInitiatorFromUpHosts(execContext.upHosts, op.hosts)
+	if host == "" {
+		return fmt.Errorf(`[%s] cannot find any up hosts among the provided hosts %v`, op.name, op.hosts)
+	}
+
+	op.hosts = []string{host}
+
+	execContext.dispatcher.setup(op.hosts)
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsGetLicenseStatusOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsGetLicenseStatusOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+// The response should look like
+/*
+	{
+	  "licenses": [
+		{
+		  "name": "Vertica Premium Edition",
+		  "licensee": "Open Text",
+		  "start_date": "2024-01-01",
+		  "end_date": "2025-01-01",
+		  "size_limit_bytes": 1099511627776,
+		  "node_limit": 0,
+		  "audited_size_bytes": 549755813888,
+		  "last_audit_time": "2024-03-01 10:12:33.123456-05",
+		  "is_compliant": true
+		},
+		...
+	  ]
+	}
+*/
+type licenseStatusResp struct {
+	Licenses []LicenseStatus `json:"licenses"`
+}
+
+// LicenseStatus is an installed license and the compliance of the database with it
+type LicenseStatus struct {
+	Name      string `json:"name"`
+	Licensee  string `json:"licensee"`
+	StartDate string `json:"start_date"`
+	// empty if the license does not expire
+	EndDate string `json:"end_date"`
+	// zero if the license has no size or node limit
+	SizeLimitBytes   int64  `json:"size_limit_bytes"`
+	NodeLimit        int    `json:"node_limit"`
+	AuditedSizeBytes int64  `json:"audited_size_bytes"`
+	LastAuditTime    string `json:"last_audit_time"`
+	IsCompliant      bool   `json:"is_compliant"`
+}
+
+func (op *httpsGetLicenseStatusOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isUnauthorizedRequest() {
+			return makeAuthFailureError(op.name, host)
+		}
+
+		if result.isPassing() {
+			err := op.parseAndCheckResponse(host, result.content, op.licenseStatus)
+			if err != nil {
+				allErrs = errors.Join(allErrs, err)
+				return appendHTTPSFailureError(allErrs)
+			}
+
+			return nil
+		}
+		allErrs = errors.Join(allErrs, result.err)
+	}
+	return appendHTTPSFailureError(allErrs)
+}
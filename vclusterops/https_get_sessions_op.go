@@ -0,0 +1,157 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+type httpsGetSessionsOp struct {
+	opBase
+	opHTTPSBase
+	// only the sessions on the nodes of the subcluster are kept, if it is set
+	scName string
+	// filled in with the sessions once the op completes
+	sessions *[]SessionInfo
+}
+
+// SessionInfo is a client session that is connected to a node
+type SessionInfo struct {
+	NodeName       string `json:"node_name"`
+	SubclusterName string `json:"subcluster_name"`
+	SessionID      string `json:"session_id"`
+	UserName       string `json:"user_name"`
+	ClientHostname string `json:"client_hostname"`
+	LoginTimestamp string `json:"login_timestamp"`
+}
+
+// The response should look like
+/*
+	{
+	  "session_list": [
+		{
+		  "node_name": "v_test_db_node0001",
+		  "subcluster_name": "default_subcluster",
+		  "session_id": "v_test_db_node0001-12345:0x1a2b",
+		  "user_name": "dbadmin",
+		  "client_hostname": "192.168.1.50:51234",
+		  "login_timestamp": "2024-03-01 10:12:33.123456-05"
+		},
+		...
+	  ]
+	}
+*/
+type sessionsResp struct {
+	SessionList []SessionInfo `json:"session_list"`
+}
+
+// makeHTTPSGetSessionsOp makes an op that gets the client sessions of the
+// database from an up host
+func makeHTTPSGetSessionsOp(hosts []string, useHTTPPassword bool, userName string,
+	httpsPassword *string, scName string, sessions *[]SessionInfo) (httpsGetSessionsOp, error) {
+	op := httpsGetSessionsOp{}
+	op.name = "HTTPSGetSessionsOp"
+	op.description = "Get client sessions"
+	op.hosts = hosts
+	op.useHTTPPassword = useHTTPPassword
+	op.scName = scName
+	op.sessions = sessions
+
+	if useHTTPPassword {
+		err := util.ValidateUsernameAndPassword(op.name, useHTTPPassword, userName)
+		if err != nil {
+			return op, err
+		}
+		op.userName = userName
+		op.httpsPassword = httpsPassword
+	}
+
+	return op, nil
+}
+
+func (op *httpsGetSessionsOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.buildHTTPSEndpoint("sessions")
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsGetSessionsOp) prepare(execContext *opEngineExecContext) error {
+	host := getInitiatorFromUpHosts(execContext.upHosts, op.hosts)
+	if host == "" {
+		return fmt.Errorf(`[%s] cannot find any up hosts among the provided hosts %v`, op.name, op.hosts)
+	}
+
+	op.hosts = []string{host}
+
+	execContext.dispatcher.setup(op.hosts)
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsGetSessionsOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsGetSessionsOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *httpsGetSessionsOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isUnauthorizedRequest() {
+			return makeAuthFailureError(op.name, host)
+		}
+
+		if result.isPassing() {
+			response := sessionsResp{}
+			err := op.parseAndCheckResponse(host, result.content, &response)
+			if err != nil {
+				allErrs = errors.Join(allErrs, err)
+				return appendHTTPSFailureError(allErrs)
+			}
+
+			sessions := []SessionInfo{}
+			for _, session := range response.SessionList {
+				if op.scName == "" || session.SubclusterName == op.scName {
+					sessions = append(sessions, session)
+				}
+			}
+			*op.sessions = sessions
+			return nil
+		}
+		allErrs = errors.Join(allErrs, result.err)
+	}
+	return appendHTTPSFailureError(allErrs)
+}
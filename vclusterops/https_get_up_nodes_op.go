@@ -33,6 +33,10 @@ const (
 	StopSubclusterCmd
 	InstallPackageCmd
 	UnsandboxCmd
+	DiagnosticsCmd
+	DrainingStatusCmd
+	LoadBalanceCmd
+	LicenseCmd
 )
 
 type CommandType int
@@ -213,7 +217,7 @@ func (op *httpsGetUpNodesOp) processResult(execContext *opEngineExecContext) err
 		return nil
 	}
 
-	return errors.Join(allErrs, fmt.Errorf("no up nodes detected"))
+	return errors.Join(allErrs, &PreconditionError{Detail: "no up nodes detected"})
 }
 
 // Return true if all the results need to be scanned to figure out UP hosts
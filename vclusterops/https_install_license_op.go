@@ -0,0 +1,116 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+type httpsInstallLicenseOp struct {
+	opBase
+	opHTTPSBase
+	licensePathOnNode string
+}
+
+type installLicenseRequestData struct {
+	LicenseFile string `json:"license_file"`
+}
+
+// makeHTTPSInstallLicenseOp makes an op that applies the license file, which must
+// already be on the node, to the database. The request is sent to the first up host
+// found by HTTPSGetUpNodesOp, which is the host the license file is deposited on.
+func makeHTTPSInstallLicenseOp(useHTTPPassword bool, userName string, httpsPassword *string,
+	licensePathOnNode string) (httpsInstallLicenseOp, error) {
+	op := httpsInstallLicenseOp{}
+	op.name = "HTTPSInstallLicenseOp"
+	op.description = "Install license"
+	op.licensePathOnNode = licensePathOnNode
+	op.useHTTPPassword = useHTTPPassword
+
+	if useHTTPPassword {
+		err := util.ValidateUsernameAndPassword(op.name, useHTTPPassword, userName)
+		if err != nil {
+			return op, err
+		}
+		op.userName = userName
+		op.httpsPassword = httpsPassword
+	}
+	return op, nil
+}
+
+func (op *httpsInstallLicenseOp) setupClusterHTTPRequest(hosts []string) error {
+	dataBytes, err := json.Marshal(installLicenseRequestData{LicenseFile: op.licensePathOnNode})
+	if err != nil {
+		return fmt.Errorf("[%s] fail to marshal request data to JSON string, detail %w", op.name, err)
+	}
+
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildHTTPSEndpoint("license")
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+		httpRequest.RequestData = string(dataBytes)
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsInstallLicenseOp) prepare(execContext *opEngineExecContext) error {
+	if len(execContext.upHosts) == 0 {
+		return fmt.Errorf(`[%s] Cannot find any up hosts in OpEngineExecContext`, op.name)
+	}
+	op.hosts = []string{execContext.upHosts[0]}
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsInstallLicenseOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsInstallLicenseOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isUnauthorizedRequest() {
+			return makeAuthFailureError(op.name, host)
+		}
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+		}
+	}
+
+	return allErrs
+}
+
+func (op *httpsInstallLicenseOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
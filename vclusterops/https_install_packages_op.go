@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/vertica/vcluster/vclusterops/util"
 )
@@ -29,6 +30,8 @@ type httpsInstallPackagesOp struct {
 	verbose        bool // Include verbose output about package install status
 	forceReinstall bool
 	status         InstallPackageStatus // Filled in once the op completes
+	// skip the install if a previous httpsGetLibrariesOp found no libraries of packages
+	skipWithoutPackageLibraries bool
 }
 
 func makeHTTPSInstallPackagesOp(hosts []string, useHTTPPassword bool,
@@ -70,6 +73,11 @@ func (op *httpsInstallPackagesOp) setupClusterHTTPRequest(hosts []string) error
 }
 
 func (op *httpsInstallPackagesOp) prepare(execContext *opEngineExecContext) error {
+	if op.skipWithoutPackageLibraries && !hasPackageLibraries(execContext.userLibraries) {
+		op.logger.Info("no libraries of packages are found, skipping the package install")
+		op.skipExecute = true
+		return nil
+	}
 	// If no hosts passed in, we will find the hosts from execute-context
 	if len(op.hosts) == 0 {
 		if len(execContext.upHosts) == 0 {
@@ -128,6 +136,19 @@ type PackageStatus struct {
 	InstallStatus string `json:"install_status"`
 }
 
+const packageInstallFailure = "failure"
+
+// FailedPackages returns the names of the packages that failed to install
+func (status *InstallPackageStatus) FailedPackages() []string {
+	var failedPackages []string
+	for _, pkg := range status.Packages {
+		if strings.EqualFold(pkg.InstallStatus, packageInstallFailure) {
+			failedPackages = append(failedPackages, pkg.PackageName)
+		}
+	}
+	return failedPackages
+}
+
 func (op *httpsInstallPackagesOp) processResult(_ *opEngineExecContext) error {
 	var allErrs error
 
@@ -0,0 +1,129 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+type httpsLoadBalanceGroupOp struct {
+	opBase
+	opHTTPSBase
+	groupName     string
+	method        string
+	requestParams map[string]string
+}
+
+// makeHTTPSSetLoadBalanceGroupOp makes an op that creates or updates a connection load
+// balancing group made of the nodes of a subcluster. The request is sent to an up host
+// found by HTTPSGetUpNodesOp.
+func makeHTTPSSetLoadBalanceGroupOp(useHTTPPassword bool, userName string, httpsPassword *string,
+	groupName, scName, policy string) (httpsLoadBalanceGroupOp, error) {
+	op, err := makeHTTPSLoadBalanceGroupOp(useHTTPPassword, userName, httpsPassword, groupName)
+	op.name = "HTTPSSetLoadBalanceGroupOp"
+	op.description = "Set connection load balancing group"
+	op.method = PostMethod
+	op.requestParams = map[string]string{
+		"subcluster": scName,
+		"policy":     policy,
+	}
+	return op, err
+}
+
+// makeHTTPSClearLoadBalanceGroupOp makes an op that drops a connection load balancing group
+func makeHTTPSClearLoadBalanceGroupOp(useHTTPPassword bool, userName string, httpsPassword *string,
+	groupName string) (httpsLoadBalanceGroupOp, error) {
+	op, err := makeHTTPSLoadBalanceGroupOp(useHTTPPassword, userName, httpsPassword, groupName)
+	op.name = "HTTPSClearLoadBalanceGroupOp"
+	op.description = "Clear connection load balancing group"
+	op.method = DeleteMethod
+	return op, err
+}
+
+func makeHTTPSLoadBalanceGroupOp(useHTTPPassword bool, userName string, httpsPassword *string,
+	groupName string) (httpsLoadBalanceGroupOp, error) {
+	op := httpsLoadBalanceGroupOp{}
+	op.groupName = groupName
+	op.useHTTPPassword = useHTTPPassword
+
+	if useHTTPPassword {
+		err := util.ValidateUsernameAndPassword("HTTPSLoadBalanceGroupOp", useHTTPPassword, userName)
+		if err != nil {
+			return op, err
+		}
+		op.userName = userName
+		op.httpsPassword = httpsPassword
+	}
+	return op, nil
+}
+
+func (op *httpsLoadBalanceGroupOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = op.method
+		httpRequest.buildHTTPSEndpoint("load-balance-groups/" + op.groupName)
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+		httpRequest.QueryParams = op.requestParams
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsLoadBalanceGroupOp) prepare(execContext *opEngineExecContext) error {
+	if len(execContext.upHosts) == 0 {
+		return fmt.Errorf(`[%s] Cannot find any up hosts in OpEngineExecContext`, op.name)
+	}
+	op.hosts = []string{execContext.upHosts[0]}
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsLoadBalanceGroupOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsLoadBalanceGroupOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isUnauthorizedRequest() {
+			return makeAuthFailureError(op.name, host)
+		}
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+		}
+	}
+
+	return allErrs
+}
+
+func (op *httpsLoadBalanceGroupOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+// httpsMoveoutOp moves the data in memory out to disk on all the up nodes,
+// so that the nodes have less to recover when the database starts again
+type httpsMoveoutOp struct {
+	opBase
+	opHTTPSBase
+}
+
+func makeHTTPSMoveoutOp(useHTTPPassword bool, userName string, httpsPassword *string) (httpsMoveoutOp, error) {
+	op := httpsMoveoutOp{}
+	op.name = "HTTPSMoveoutOp"
+	op.description = "Move out data to disk"
+	op.useHTTPPassword = useHTTPPassword
+
+	if useHTTPPassword {
+		err := util.ValidateUsernameAndPassword(op.name, useHTTPPassword, userName)
+		if err != nil {
+			return op, err
+		}
+		op.userName = userName
+		op.httpsPassword = httpsPassword
+	}
+
+	return op, nil
+}
+
+func (op *httpsMoveoutOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildHTTPSEndpoint("cluster/moveout")
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsMoveoutOp) prepare(execContext *opEngineExecContext) error {
+	if len(execContext.upHosts) == 0 {
+		return fmt.Errorf(`[%s] Cannot find any up hosts in OpEngineExecContext`, op.name)
+	}
+	// the moveout runs on all the up nodes from the first up host
+	op.hosts = []string{execContext.upHosts[0]}
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsMoveoutOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsMoveoutOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isUnauthorizedRequest() {
+			return makeAuthFailureError(op.name, host)
+		}
+
+		if result.isPassing() {
+			// decode the json-format response
+			// The response object will be a dictionary, an example:
+			// {"detail": "Moveout completed on 3 nodes"}
+			moveoutRsp, err := op.parseAndCheckMapResponse(host, result.content)
+			if err != nil {
+				allErrs = errors.Join(allErrs, err)
+				continue
+			}
+			op.logger.PrintInfo(`[%s] %s`, op.name, moveoutRsp["detail"])
+		} else {
+			allErrs = errors.Join(allErrs, result.err)
+		}
+	}
+
+	return allErrs
+}
+
+func (op *httpsMoveoutOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
@@ -31,6 +31,7 @@ const (
 	StartDBCmd CmdType = iota
 	StartNodeCmd
 	CreateDBCmd
+	RotateCertsCmd
 )
 
 type CmdType int
@@ -43,6 +44,8 @@ func (cmd CmdType) String() string {
 		return "restart_node"
 	case CreateDBCmd:
 		return "create_db"
+	case RotateCertsCmd:
+		return "rotate_certs"
 	}
 	return "unknown_operation"
 }
@@ -178,11 +181,12 @@ func (op *httpsPollNodeStateOp) shouldStopPolling() (bool, error) {
 			case StartDBCmd, StartNodeCmd:
 				op.logger.PrintError("[%s] The credentials are incorrect. 'Catalog Sync' will not be executed.",
 					op.name)
-				return false, fmt.Errorf("[%s] wrong password/certificate for https service on host %s, but the nodes' startup have been in progress."+
-					"Please use vsql to check the nodes' status and manually run sync_catalog vsql command 'select sync_catalog()'", op.name, host)
+				authErr := makeAuthFailureError(op.name, host)
+				authErr.Detail += ", but the nodes' startup have been in progress." +
+					"Please use vsql to check the nodes' status and manually run sync_catalog vsql command 'select sync_catalog()'"
+				return false, authErr
 			case CreateDBCmd:
-				return true, fmt.Errorf("[%s] wrong password/certificate for https service on host %s",
-					op.name, host)
+				return true, makeAuthFailureError(op.name, host)
 			}
 		}
 		if result.isPassing() {
@@ -199,6 +203,7 @@ func (op *httpsPollNodeStateOp) shouldStopPolling() (bool, error) {
 			// the node list should only have one node info
 			if len(nodesInformation.NodeList) == 1 {
 				nodeInfo := nodesInformation.NodeList[0]
+				op.events.nodeState(op.name, host, nodeInfo.Name, nodeInfo.State)
 				if nodeInfo.State == util.NodeUpState {
 					upNodeCount++
 				}
@@ -0,0 +1,188 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+const (
+	rebalanceStateCompleted = "completed"
+	rebalanceStateFailed    = "failed"
+	rebalanceProgressItem   = "rebalance"
+)
+
+type httpsPollRebalanceStatusOp struct {
+	opBase
+	opHTTPSBase
+	timeout   int
+	reporter  ProgressReporter
+	startTime time.Time
+}
+
+// makeHTTPSPollRebalanceStatusOp makes an op that polls the status of a rebalance
+// that runs in the background until it completes or fails. The percent complete of
+// the rebalance is passed to the reporter, which can be nil.
+func makeHTTPSPollRebalanceStatusOp(hosts []string, useHTTPPassword bool, userName string,
+	httpsPassword *string, timeout int, reporter ProgressReporter) (httpsPollRebalanceStatusOp, error) {
+	op := httpsPollRebalanceStatusOp{}
+	op.name = "HTTPSPollRebalanceStatusOp"
+	op.description = "Wait for cluster rebalance to complete"
+	op.hosts = hosts
+	op.timeout = timeout
+	op.reporter = reporter
+
+	op.useHTTPPassword = useHTTPPassword
+	err := util.ValidateUsernameAndPassword(op.name, useHTTPPassword, userName)
+	if err != nil {
+		return op, err
+	}
+	op.userName = userName
+	op.httpsPassword = httpsPassword
+	return op, nil
+}
+
+// getPollingTimeout returns the timeout of the rebalance, a negative value polls
+// until the rebalance ends
+func (op *httpsPollRebalanceStatusOp) getPollingTimeout() int {
+	return op.timeout
+}
+
+func (op *httpsPollRebalanceStatusOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.Timeout = defaultHTTPRequestTimeoutSeconds
+		httpRequest.buildHTTPSEndpoint("cluster/rebalance/status")
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+	return nil
+}
+
+func (op *httpsPollRebalanceStatusOp) prepare(execContext *opEngineExecContext) error {
+	execContext.dispatcher.setup(op.hosts)
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsPollRebalanceStatusOp) execute(execContext *opEngineExecContext) error {
+	op.startTime = time.Now()
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsPollRebalanceStatusOp) processResult(execContext *opEngineExecContext) error {
+	err := pollState(op, execContext)
+	if err != nil {
+		return fmt.Errorf("cluster rebalance did not complete, %w", err)
+	}
+
+	return nil
+}
+
+func (op *httpsPollRebalanceStatusOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+// The content of the response should look like
+/*
+	{
+	  "state": "running",
+	  "percent_complete": 42.5,
+	  "projections_done": 17,
+	  "projections_total": 40,
+	  "detail": ""
+	}
+*/
+type rebalanceStatus struct {
+	State            string  `json:"state"`
+	PercentComplete  float64 `json:"percent_complete"`
+	ProjectionsDone  int     `json:"projections_done"`
+	ProjectionsTotal int     `json:"projections_total"`
+	// the reason of a failed rebalance
+	Detail string `json:"detail"`
+}
+
+func (op *httpsPollRebalanceStatusOp) shouldStopPolling() (bool, error) {
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isPasswordAndCertificateError(op.logger) {
+			return true, makeAuthFailureError(op.name, host)
+		}
+		if !result.isPassing() {
+			return false, nil
+		}
+
+		status := rebalanceStatus{}
+		err := op.parseAndCheckResponse(host, result.content, &status)
+		if err != nil {
+			return true, fmt.Errorf("[%s] fail to parse result on host %s, details: %w", op.name, host, err)
+		}
+
+		return op.isRebalanceDone(host, &status, time.Since(op.startTime))
+	}
+
+	return false, nil
+}
+
+// isRebalanceDone reports the progress of the rebalance, and returns true when
+// the rebalance is completed, and an error when it has failed
+func (op *httpsPollRebalanceStatusOp) isRebalanceDone(host string, status *rebalanceStatus,
+	elapsed time.Duration) (bool, error) {
+	event := ProgressEvent{
+		Host:    host,
+		Item:    rebalanceProgressItem,
+		Stage:   ProgressStageRebalancing,
+		Percent: status.PercentComplete,
+		Done:    status.ProjectionsDone,
+		Total:   status.ProjectionsTotal,
+	}
+	// assume the rest of the rebalance runs as fast as the part that is done
+	if status.PercentComplete > 0 && status.PercentComplete < 100 {
+		event.ETA = time.Duration(float64(elapsed) / status.PercentComplete * (100 - status.PercentComplete))
+	}
+
+	done := false
+	var err error
+	switch status.State {
+	case rebalanceStateCompleted:
+		done = true
+		event.Stage = ProgressStageDone
+		event.Percent = 100
+		event.ETA = 0
+	case rebalanceStateFailed:
+		done = true
+		event.Stage = ProgressStageFailed
+		err = fmt.Errorf("[%s] cluster rebalance failed: %s", op.name, status.Detail)
+	default:
+		op.logger.Info("cluster rebalance in progress", "op name", op.name, "percent complete", status.PercentComplete)
+	}
+	if op.reporter != nil {
+		op.reporter.ReportProgress(event)
+	}
+
+	return done, err
+}
@@ -0,0 +1,201 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+const (
+	replicationStatusCompleted = "completed"
+	replicationStatusFailed    = "failed"
+)
+
+type httpsPollReplicationStatusOp struct {
+	opBase
+	opHTTPSBase
+	sourceDB string
+	timeout  int
+}
+
+// makeHTTPSPollReplicationStatusOp makes an op that polls the replication status
+// on an up host of the source database until the replication completes or fails
+func makeHTTPSPollReplicationStatusOp(dbName string, sourceHosts []string,
+	useHTTPPassword bool, userName string, httpsPassword *string, timeout int) (httpsPollReplicationStatusOp, error) {
+	op := httpsPollReplicationStatusOp{}
+	op.name = "HTTPSPollReplicationStatusOp"
+	op.description = "Wait for database replication to complete"
+	op.sourceDB = dbName
+	op.hosts = sourceHosts
+	op.useHTTPPassword = useHTTPPassword
+	op.timeout = timeout
+
+	if useHTTPPassword {
+		err := util.ValidateUsernameAndPassword(op.name, useHTTPPassword, userName)
+		if err != nil {
+			return op, err
+		}
+		op.userName = userName
+		op.httpsPassword = httpsPassword
+	}
+
+	return op, nil
+}
+
+func (op *httpsPollReplicationStatusOp) getPollingTimeout() int {
+	return op.timeout
+}
+
+func (op *httpsPollReplicationStatusOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.Timeout = defaultHTTPRequestTimeoutSeconds
+		httpRequest.buildHTTPSEndpoint("replicate/status")
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+	return nil
+}
+
+func (op *httpsPollReplicationStatusOp) prepare(execContext *opEngineExecContext) error {
+	if len(execContext.nodesInfo) == 0 {
+		return fmt.Errorf(`[%s] cannot find any hosts in OpEngineExecContext`, op.name)
+	}
+	// the status is polled on an up host of the source database
+	var sourceHosts []string
+	for _, node := range execContext.nodesInfo {
+		if node.State != util.NodeDownState {
+			sourceHosts = append(sourceHosts, node.Address)
+		}
+	}
+	sourceHosts = util.SliceCommon(op.hosts, sourceHosts)
+	if len(sourceHosts) == 0 {
+		return fmt.Errorf(`[%s] cannot find any up hosts from source database %s`, op.name, op.sourceDB)
+	}
+	op.hosts = []string{sourceHosts[0]}
+
+	execContext.dispatcher.setup(op.hosts)
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsPollReplicationStatusOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsPollReplicationStatusOp) processResult(execContext *opEngineExecContext) error {
+	err := pollState(op, execContext)
+	if err != nil {
+		return fmt.Errorf("replication of database %s did not complete, %w", op.sourceDB, err)
+	}
+
+	return nil
+}
+
+func (op *httpsPollReplicationStatusOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+// The content of the response should look like
+/* "replication_status": [
+	{
+	  "node_name": "v_test_db_node0001",
+	  "op_name": "load_snapshot_prep",
+	  "op_status": "completed",
+	  "sent_bytes": 0,
+	  "total_bytes": 0
+	},
+	{
+	  "node_name": "v_test_db_node0001",
+	  "op_name": "data_transfer",
+	  "op_status": "started",
+	  "sent_bytes": 1024,
+	  "total_bytes": 4096
+	},
+	...
+  ]
+*/
+type replicationStatusList struct {
+	StatusList []replicationStatus `json:"replication_status"`
+}
+
+type replicationStatus struct {
+	NodeName   string `json:"node_name"`
+	OpName     string `json:"op_name"`
+	OpStatus   string `json:"op_status"`
+	SentBytes  int64  `json:"sent_bytes"`
+	TotalBytes int64  `json:"total_bytes"`
+}
+
+func (op *httpsPollReplicationStatusOp) shouldStopPolling() (bool, error) {
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isPasswordAndCertificateError(op.logger) {
+			return true, makeAuthFailureError(op.name, host)
+		}
+		if !result.isPassing() {
+			return false, nil
+		}
+
+		statusList := replicationStatusList{}
+		err := op.parseAndCheckResponse(host, result.content, &statusList)
+		if err != nil {
+			return true, fmt.Errorf("[%s] fail to parse result on host %s, details: %w", op.name, host, err)
+		}
+
+		return op.isReplicationDone(statusList.StatusList)
+	}
+
+	return false, nil
+}
+
+// isReplicationDone returns true when all replication steps are completed,
+// and an error when any of them has failed
+func (op *httpsPollReplicationStatusOp) isReplicationDone(statusList []replicationStatus) (bool, error) {
+	if len(statusList) == 0 {
+		return false, nil
+	}
+
+	var sentBytes, totalBytes int64
+	done := true
+	for _, status := range statusList {
+		switch status.OpStatus {
+		case replicationStatusFailed:
+			return true, fmt.Errorf("[%s] replication step %s failed on node %s",
+				op.name, status.OpName, status.NodeName)
+		case replicationStatusCompleted:
+		default:
+			done = false
+		}
+		sentBytes += status.SentBytes
+		totalBytes += status.TotalBytes
+	}
+	if !done {
+		op.logger.PrintInfo("[%s] replication in progress, %d of %d bytes sent", op.name, sentBytes, totalBytes)
+	}
+
+	return done, nil
+}
@@ -0,0 +1,104 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestReplicationStatusParsing(t *testing.T) {
+	op, err := makeHTTPSPollReplicationStatusOp("test_db", []string{"192.168.1.101"}, false, "", nil, 10)
+	assert.NoError(t, err)
+	pollResult := func(content string) (bool, error) {
+		op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+			"192.168.1.101": {status: SUCCESS, content: content},
+		}
+		return op.shouldStopPolling()
+	}
+
+	// all steps are completed
+	done, err := pollResult(`{"replication_status": [
+		{"node_name": "v_test_db_node0001", "op_name": "load_snapshot_prep", "op_status": "completed"},
+		{"node_name": "v_test_db_node0001", "op_name": "data_transfer", "op_status": "completed",
+		 "sent_bytes": 4096, "total_bytes": 4096}]}`)
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	// a step has failed
+	done, err = pollResult(`{"replication_status": [
+		{"node_name": "v_test_db_node0001", "op_name": "load_snapshot_prep", "op_status": "completed"},
+		{"node_name": "v_test_db_node0002", "op_name": "data_transfer", "op_status": "failed"}]}`)
+	assert.True(t, done)
+	assert.ErrorContains(t, err, "replication step data_transfer failed on node v_test_db_node0002")
+
+	// a step is in progress
+	done, err = pollResult(`{"replication_status": [
+		{"node_name": "v_test_db_node0001", "op_name": "load_snapshot_prep", "op_status": "completed"},
+		{"node_name": "v_test_db_node0001", "op_name": "data_transfer", "op_status": "started",
+		 "sent_bytes": 1024, "total_bytes": 4096}]}`)
+	assert.NoError(t, err)
+	assert.False(t, done)
+
+	// the replication has not started yet
+	done, err = pollResult(`{"replication_status": []}`)
+	assert.NoError(t, err)
+	assert.False(t, done)
+
+	// a response that cannot be parsed stops the polling
+	done, err = pollResult(`not json`)
+	assert.True(t, done)
+	assert.ErrorContains(t, err, "fail to parse result on host 192.168.1.101")
+
+	// a wrong password stops the polling
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: FAILURE, statusCode: UnauthorizedCode, content: "Wrong password",
+			err: errors.New("Wrong password")},
+	}
+	done, err = op.shouldStopPolling()
+	assert.True(t, done)
+	var authErr *AuthFailureError
+	assert.ErrorAs(t, err, &authErr)
+}
+
+func TestReplicationPollingTimeout(t *testing.T) {
+	options := VReplicationDatabaseFactory()
+	options.IsEon = true
+	options.PollCompletion = true
+
+	// a zero timeout is rejected
+	options.PollingTimeout = 0
+	err := options.validateParseOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "polling timeout cannot be zero")
+
+	// the timeout is not used without polling
+	options.PollCompletion = false
+	err = options.validateParseOptions(vlog.Printer{})
+	assert.NotContains(t, err.Error(), "polling timeout")
+
+	// a negative timeout polls until the replication is done
+	op, err := makeHTTPSPollReplicationStatusOp("test_db", []string{"192.168.1.101"}, false, "", nil, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, op.getPollingTimeout())
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, content: `{"replication_status": [
+			{"node_name": "v_test_db_node0001", "op_name": "data_transfer", "op_status": "completed"}]}`},
+	}
+	assert.NoError(t, pollState(&op, nil))
+}
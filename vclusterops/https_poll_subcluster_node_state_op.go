@@ -183,8 +183,7 @@ func (op *httpsPollSubclusterNodeStateOp) shouldStopPolling() (bool, error) {
 		// If we find the wrong password for the HTTPS service on any hosts, we should fail immediately.
 		// We also need to let user know to wait until all nodes are up
 		if result.isPasswordAndCertificateError(op.logger) {
-			return true, fmt.Errorf("[%s] wrong password/certificate for https service on host %s",
-				op.name, host)
+			return true, makeAuthFailureError(op.name, host)
 		}
 		if result.isPassing() {
 			// parse the /nodes/{node} endpoint response
@@ -198,6 +197,7 @@ func (op *httpsPollSubclusterNodeStateOp) shouldStopPolling() (bool, error) {
 			// the node list should only have one node info
 			if len(nodesInformation.NodeList) == 1 {
 				nodeInfo := nodesInformation.NodeList[0]
+				op.events.nodeState(op.name, host, nodeInfo.Name, nodeInfo.State)
 				if nodeInfo.State == util.NodeUpState {
 					upNodeCount++
 				}
@@ -234,14 +234,14 @@ func (op *httpsPollSubclusterNodeStateOp) shouldStopPollingForDown() (bool, erro
 		// If we find the wrong password for the HTTPS service on any hosts, we should fail immediately.
 		// We also need to let user know to wait until all nodes are DOWN
 		if result.isPasswordAndCertificateError(op.logger) {
-			return true, fmt.Errorf("[%s] wrong password/certificate for https service on host %s",
-				op.name, host)
+			return true, makeAuthFailureError(op.name, host)
 		}
 		if !result.isPassing() {
 			allErrs = errors.Join(allErrs, result.err)
 		}
 		if result.isFailing() && !result.isHTTPRunning() {
 			downHosts[host] = true
+			op.events.nodeState(op.name, host, "", util.NodeDownState)
 			continue
 		} else if result.isException() {
 			exceptionHosts[host] = true
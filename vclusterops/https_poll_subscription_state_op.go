@@ -21,11 +21,15 @@ import (
 	"github.com/vertica/vcluster/vclusterops/util"
 )
 
+const subscriptionsProgressItem = "subscriptions"
+
 type httpsPollSubscriptionStateOp struct {
 	opBase
 	opHTTPSBase
 	timeout     int
 	nodesToPoll *[]string
+	// if set, receives the number of ACTIVE subscriptions at each poll
+	reporter ProgressReporter
 }
 
 func makeHTTPSPollSubscriptionStateOp(hosts []string,
@@ -52,6 +56,20 @@ func makeHTTPSPollSubscriptionStateOp(hosts []string,
 	return op, nil
 }
 
+// makeHTTPSPollSubscriptionStateOpWithTimeout makes an op that polls the subscriptions
+// of the given nodes until all of them are ACTIVE or the timeout is reached
+func makeHTTPSPollSubscriptionStateOpWithTimeout(hosts []string,
+	useHTTPPassword bool, userName string, httpsPassword *string, nodesToPoll *[]string,
+	timeout int) (httpsPollSubscriptionStateOp, error) {
+	op, err := makeHTTPSPollSubscriptionStateOp(hosts, useHTTPPassword, userName, httpsPassword, nodesToPoll)
+	if err != nil {
+		return op, err
+	}
+	op.description = "Wait for shard subscriptions to be active"
+	op.timeout = timeout
+	return op, nil
+}
+
 func (op *httpsPollSubscriptionStateOp) getPollingTimeout() int {
 	// a negative value indicates no timeout and should never be used for this op
 	return util.Max(op.timeout, 0)
@@ -136,8 +154,7 @@ func (op *httpsPollSubscriptionStateOp) shouldStopPolling() (bool, error) {
 		op.logResponse(host, result)
 
 		if result.isPasswordAndCertificateError(op.logger) {
-			return true, fmt.Errorf("[%s] wrong password/certificate for https service on host %s",
-				op.name, host)
+			return true, makeAuthFailureError(op.name, host)
 		}
 
 		if result.isPassing() {
@@ -148,11 +165,15 @@ func (op *httpsPollSubscriptionStateOp) shouldStopPolling() (bool, error) {
 				return true, err
 			}
 
+			activeCount, totalCount := countActiveSubs(&subscriptList, op.nodesToPoll)
 			if containsInactiveSub(&subscriptList, op.nodesToPoll) {
+				op.logger.PrintInfo("[%s] %d of %d subscriptions are ACTIVE", op.name, activeCount, totalCount)
+				op.reportProgress(host, ProgressStageSubscribing, activeCount, totalCount)
 				return false, nil
 			}
 
 			op.logger.PrintInfo("All subscriptions are ACTIVE")
+			op.reportProgress(host, ProgressStageDone, activeCount, totalCount)
 			return true, nil
 		}
 	}
@@ -162,6 +183,24 @@ func (op *httpsPollSubscriptionStateOp) shouldStopPolling() (bool, error) {
 	return false, nil
 }
 
+// reportProgress passes the number of ACTIVE subscriptions to the reporter, if any
+func (op *httpsPollSubscriptionStateOp) reportProgress(host, stage string, activeCount, totalCount int) {
+	if op.reporter == nil {
+		return
+	}
+	event := ProgressEvent{
+		Host:  host,
+		Item:  subscriptionsProgressItem,
+		Stage: stage,
+		Done:  activeCount,
+		Total: totalCount,
+	}
+	if totalCount > 0 {
+		event.Percent = 100 * float64(activeCount) / float64(totalCount)
+	}
+	op.reporter.ReportProgress(event)
+}
+
 func containsInactiveSub(subscriptList *subscriptionList, nodesToPoll *[]string) bool {
 	var allNodesWithInactiveSubs []string
 	for _, s := range subscriptList.SubscriptionList {
@@ -173,3 +212,18 @@ func containsInactiveSub(subscriptList *subscriptionList, nodesToPoll *[]string)
 	// all subs of all nodes in nodesToPoll are active
 	return len(*nodesToPoll) != len(nodesToPollWithActiveSubs)
 }
+
+// countActiveSubs returns the number of ACTIVE subscriptions and the number of all
+// subscriptions of the nodes in nodesToPoll
+func countActiveSubs(subscriptList *subscriptionList, nodesToPoll *[]string) (activeCount, totalCount int) {
+	for _, s := range subscriptList.SubscriptionList {
+		if !util.StringInArray(s.Nodename, *nodesToPoll) {
+			continue
+		}
+		totalCount++
+		if s.SubscriptionState == "ACTIVE" {
+			activeCount++
+		}
+	}
+	return activeCount, totalCount
+}
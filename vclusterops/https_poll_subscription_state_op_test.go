@@ -0,0 +1,102 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+func TestCountActiveSubs(t *testing.T) {
+	subscriptList := subscriptionList{
+		SubscriptionList: []subscriptionInfo{
+			{Nodename: "v_test_db_node0001", ShardName: "replica", SubscriptionState: "ACTIVE"},
+			{Nodename: "v_test_db_node0001", ShardName: "segment0001", SubscriptionState: "ACTIVE"},
+			{Nodename: "v_test_db_node0002", ShardName: "replica", SubscriptionState: "PENDING"},
+			{Nodename: "v_test_db_node0003", ShardName: "replica", SubscriptionState: "PENDING"},
+		},
+	}
+	nodesToPoll := []string{"v_test_db_node0001", "v_test_db_node0002"}
+
+	assert.True(t, containsInactiveSub(&subscriptList, &nodesToPoll))
+	activeCount, totalCount := countActiveSubs(&subscriptList, &nodesToPoll)
+	assert.Equal(t, 2, activeCount)
+	assert.Equal(t, 3, totalCount)
+
+	// subscriptions of nodes not in the list are ignored
+	nodesToPoll = []string{"v_test_db_node0001"}
+	assert.False(t, containsInactiveSub(&subscriptList, &nodesToPoll))
+}
+
+func TestPollSubscriptionStateProgress(t *testing.T) {
+	nodesToPoll := []string{"v_test_db_node0001", "v_test_db_node0002"}
+	op, err := makeHTTPSPollSubscriptionStateOpWithTimeout([]string{"192.168.1.101"}, false, "", nil,
+		&nodesToPoll, 10)
+	assert.NoError(t, err)
+	reporter := &mockProgressReporter{}
+	op.reporter = reporter
+
+	// 3 of the 4 subscriptions of the polled nodes are ACTIVE
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, content: `{"subscription_list": [
+			{"node_name": "v_test_db_node0001", "shard_name": "replica", "subscription_state": "ACTIVE"},
+			{"node_name": "v_test_db_node0001", "shard_name": "segment0001", "subscription_state": "ACTIVE"},
+			{"node_name": "v_test_db_node0002", "shard_name": "replica", "subscription_state": "ACTIVE"},
+			{"node_name": "v_test_db_node0002", "shard_name": "segment0001", "subscription_state": "PENDING"}]}`},
+	}
+	done, err := op.shouldStopPolling()
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, []ProgressEvent{{Host: "192.168.1.101", Item: subscriptionsProgressItem,
+		Stage: ProgressStageSubscribing, Percent: 75, Done: 3, Total: 4}}, reporter.events)
+
+	// all subscriptions are ACTIVE
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, content: `{"subscription_list": [
+			{"node_name": "v_test_db_node0001", "shard_name": "replica", "subscription_state": "ACTIVE"},
+			{"node_name": "v_test_db_node0002", "shard_name": "replica", "subscription_state": "ACTIVE"}]}`},
+	}
+	done, err = op.shouldStopPolling()
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, ProgressStageDone, reporter.events[1].Stage)
+	assert.Equal(t, float64(100), reporter.events[1].Percent)
+}
+
+func TestGetSubscriptionNodesToPoll(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Name: "v_test_db_node0001", Subcluster: "sc1",
+		State: util.NodeUpState}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{Name: "v_test_db_node0002", Subcluster: "sc1",
+		State: util.NodeDownState}
+	vdb.HostNodeMap["192.168.1.103"] = &VCoordinationNode{Name: "v_test_db_node0003", Subcluster: "sc2",
+		State: util.NodeUpState}
+	vdb.HostNodeMap["192.168.1.104"] = &VCoordinationNode{Name: "v_test_db_node0004", Subcluster: "sc2",
+		State: util.NodeUpState, Sandbox: "sand"}
+
+	// the DOWN nodes are skipped, and so are the nodes of the sandboxes
+	nodesToPoll, downNodes := getSubscriptionNodesToPoll(&vdb, "")
+	assert.Equal(t, []string{"v_test_db_node0001", "v_test_db_node0003"}, nodesToPoll)
+	assert.Equal(t, []string{"v_test_db_node0002"}, downNodes)
+
+	// only the nodes of the subcluster are polled
+	nodesToPoll, downNodes = getSubscriptionNodesToPoll(&vdb, "sc2")
+	assert.Equal(t, []string{"v_test_db_node0003"}, nodesToPoll)
+	assert.Empty(t, downNodes)
+}
@@ -24,10 +24,13 @@ import (
 
 const RebalanceClusterSuccMsg = "REBALANCED"
 const RebalanceShardsSuccMsg = "REBALANCED SHARDS"
+const RebalanceClusterStartedMsg = "REBALANCE STARTED"
 
 type httpsRebalanceClusterOp struct {
 	opBase
 	opHTTPSBase
+	// start the rebalance in the background instead of waiting for it to finish
+	async bool
 }
 
 // makeHTTPSRebalanceClusterOp will make an op that call vertica-http service to rebalance the cluster
@@ -48,6 +51,17 @@ func makeHTTPSRebalanceClusterOp(initiatorHost []string, useHTTPPassword bool, u
 	return op, nil
 }
 
+// makeHTTPSStartRebalanceClusterOp makes an op that starts a rebalance of the cluster
+// in the background. The progress can be polled by an httpsPollRebalanceStatusOp.
+func makeHTTPSStartRebalanceClusterOp(initiatorHost []string, useHTTPPassword bool, userName string,
+	httpsPassword *string) (httpsRebalanceClusterOp, error) {
+	op, err := makeHTTPSRebalanceClusterOp(initiatorHost, useHTTPPassword, userName, httpsPassword)
+	op.name = "HTTPSStartRebalanceClusterOp"
+	op.description = "Start cluster rebalance"
+	op.async = true
+	return op, err
+}
+
 func (op *httpsRebalanceClusterOp) setupClusterHTTPRequest(hosts []string) error {
 	for _, host := range hosts {
 		httpRequest := hostHTTPRequest{}
@@ -57,6 +71,9 @@ func (op *httpsRebalanceClusterOp) setupClusterHTTPRequest(hosts []string) error
 			httpRequest.Password = op.httpsPassword
 			httpRequest.Username = op.userName
 		}
+		if op.async {
+			httpRequest.QueryParams = map[string]string{"async": "true"}
+		}
 		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
 	}
 	return nil
@@ -101,7 +118,11 @@ func (op *httpsRebalanceClusterOp) processResult(_ *opEngineExecContext) error {
 			{
 			  "detail": "REBALANCED SHARDS"
 			}
-			if eon
+			if eon, or
+			{
+			  "detail": "REBALANCE STARTED"
+			}
+			if the rebalance is started in the background
 		*/
 		resp, err := op.parseAndCheckMapResponse(host, result.content)
 		if err != nil {
@@ -110,9 +131,13 @@ func (op *httpsRebalanceClusterOp) processResult(_ *opEngineExecContext) error {
 			return allErrs
 		}
 		// verify if the response's content is correct
-		if resp["detail"] != RebalanceClusterSuccMsg &&
-			resp["detail"] != RebalanceShardsSuccMsg {
-			err = fmt.Errorf(`[%s] response detail should be '%s' but got '%s'`, op.name, RebalanceClusterSuccMsg, resp["detail"])
+		expectedMsg := RebalanceClusterSuccMsg
+		if op.async {
+			expectedMsg = RebalanceClusterStartedMsg
+		}
+		if resp["detail"] != expectedMsg &&
+			(op.async || resp["detail"] != RebalanceShardsSuccMsg) {
+			err = fmt.Errorf(`[%s] response detail should be '%s' but got '%s'`, op.name, expectedMsg, resp["detail"])
 			allErrs = errors.Join(allErrs, err)
 			return allErrs
 		}
@@ -0,0 +1,119 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+type httpsRedirectConnectionsOp struct {
+	opBase
+	opHTTPSBase
+	scName        string
+	requestParams map[string]string
+}
+
+// makeHTTPSRedirectConnectionsOp makes an op that enables or disables the redirect of
+// new client connections away from a subcluster. When the redirect is enabled, new
+// connections to the subcluster are sent to the target subcluster, or to any other
+// subcluster if the target is empty. The request is sent to an up host found by
+// HTTPSGetUpNodesOp.
+func makeHTTPSRedirectConnectionsOp(useHTTPPassword bool, userName string, httpsPassword *string,
+	scName string, enable bool, targetSCName string) (httpsRedirectConnectionsOp, error) {
+	op := httpsRedirectConnectionsOp{}
+	op.name = "HTTPSRedirectConnectionsOp"
+	op.scName = scName
+	op.useHTTPPassword = useHTTPPassword
+	if enable {
+		op.description = "Redirect new connections away from subcluster"
+	} else {
+		op.description = "Stop redirecting new connections away from subcluster"
+	}
+
+	op.requestParams = map[string]string{"enable": strconv.FormatBool(enable)}
+	if enable && targetSCName != "" {
+		op.requestParams["target-subcluster"] = targetSCName
+	}
+
+	if useHTTPPassword {
+		err := util.ValidateUsernameAndPassword(op.name, useHTTPPassword, userName)
+		if err != nil {
+			return op, err
+		}
+		op.userName = userName
+		op.httpsPassword = httpsPassword
+	}
+	return op, nil
+}
+
+func (op *httpsRedirectConnectionsOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildHTTPSEndpoint("subclusters/" + op.scName + "/redirect")
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+		httpRequest.QueryParams = op.requestParams
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsRedirectConnectionsOp) prepare(execContext *opEngineExecContext) error {
+	if len(execContext.upHosts) == 0 {
+		return fmt.Errorf(`[%s] Cannot find any up hosts in OpEngineExecContext`, op.name)
+	}
+	op.hosts = []string{execContext.upHosts[0]}
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsRedirectConnectionsOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsRedirectConnectionsOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isUnauthorizedRequest() {
+			return makeAuthFailureError(op.name, host)
+		}
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+		}
+	}
+
+	return allErrs
+}
+
+func (op *httpsRedirectConnectionsOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+type httpsRegenerateSpreadOp struct {
+	opBase
+	opHTTPSBase
+}
+
+// makeHTTPSRegenerateSpreadOp makes an op that asks the server on the given
+// host to write a new spread.conf from the control nodes and the addresses
+// of the nodes in its catalog
+func makeHTTPSRegenerateSpreadOp(hosts []string, useHTTPPassword bool,
+	userName string, httpsPassword *string) (httpsRegenerateSpreadOp, error) {
+	op := httpsRegenerateSpreadOp{}
+	op.name = "HTTPSRegenerateSpreadOp"
+	op.description = "Regenerate spread.conf"
+	op.hosts = hosts
+	op.useHTTPPassword = useHTTPPassword
+
+	err := util.ValidateUsernameAndPassword(op.name, useHTTPPassword, userName)
+	if err != nil {
+		return op, err
+	}
+	op.userName = userName
+	op.httpsPassword = httpsPassword
+	return op, nil
+}
+
+func (op *httpsRegenerateSpreadOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildHTTPSEndpoint("config/spread/regenerate")
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsRegenerateSpreadOp) prepare(execContext *opEngineExecContext) error {
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *httpsRegenerateSpreadOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsRegenerateSpreadOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isUnauthorizedRequest() {
+			return makeAuthFailureError(op.name, host)
+		}
+
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+			continue
+		}
+
+		// decode the json-format response
+		// The successful response object will be a dictionary as below:
+		// {"detail": "Wrote a new spread.conf"}
+		regenerateSpreadRsp, err := op.parseAndCheckMapResponse(host, result.content)
+		if err != nil {
+			err = fmt.Errorf("[%s] fail to parse result on host %s, details: %w", op.name, host, err)
+			allErrs = errors.Join(allErrs, err)
+			continue
+		}
+
+		const regenerateSpreadOpSuccMsg = "Wrote a new spread.conf"
+		if regenerateSpreadRsp["detail"] != regenerateSpreadOpSuccMsg {
+			err = fmt.Errorf(`[%s] response detail should be '%s' but got '%s'`,
+				op.name, regenerateSpreadOpSuccMsg, regenerateSpreadRsp["detail"])
+			allErrs = errors.Join(allErrs, err)
+		}
+	}
+
+	return allErrs
+}
+
+func (op *httpsRegenerateSpreadOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+type httpsReplaceCertsOp struct {
+	opBase
+	opHTTPSBase
+	action   string
+	newCerts *httpsCerts
+}
+
+type httpsReplaceCertsPayload struct {
+	Key    string `json:"key"`
+	Cert   string `json:"cert"`
+	CaCert string `json:"ca_cert"`
+}
+
+// makeHTTPSReplaceCertsOp makes an op that installs new TLS certs in the HTTPS
+// service of the given hosts. The service keeps its previous certs and reloads
+// its listener with the new ones.
+func makeHTTPSReplaceCertsOp(hosts []string, newCerts *httpsCerts,
+	useHTTPPassword bool, userName string, httpsPassword *string) (httpsReplaceCertsOp, error) {
+	op, err := makeHTTPSCertsActionOp(hosts, certsActionReplace, useHTTPPassword, userName, httpsPassword)
+	op.name = "HTTPSReplaceCertsOp"
+	op.description = "Replace HTTPS service certificates"
+	op.newCerts = newCerts
+	return op, err
+}
+
+// makeHTTPSRollbackCertsOp makes an op that restores the previous TLS certs
+// in the HTTPS service of the given hosts
+func makeHTTPSRollbackCertsOp(hosts []string,
+	useHTTPPassword bool, userName string, httpsPassword *string) (httpsReplaceCertsOp, error) {
+	op, err := makeHTTPSCertsActionOp(hosts, certsActionRollback, useHTTPPassword, userName, httpsPassword)
+	op.name = "HTTPSRollbackCertsOp"
+	op.description = "Restore previous HTTPS service certificates"
+	return op, err
+}
+
+// makeHTTPSDiscardCertsOp makes an op that discards the previous TLS certs
+// in the HTTPS service of the given hosts
+func makeHTTPSDiscardCertsOp(hosts []string,
+	useHTTPPassword bool, userName string, httpsPassword *string) (httpsReplaceCertsOp, error) {
+	op, err := makeHTTPSCertsActionOp(hosts, certsActionDiscard, useHTTPPassword, userName, httpsPassword)
+	op.name = "HTTPSDiscardCertsOp"
+	op.description = "Discard previous HTTPS service certificates"
+	return op, err
+}
+
+func makeHTTPSCertsActionOp(hosts []string, action string,
+	useHTTPPassword bool, userName string, httpsPassword *string) (httpsReplaceCertsOp, error) {
+	op := httpsReplaceCertsOp{}
+	op.hosts = hosts
+	op.action = action
+	op.useHTTPPassword = useHTTPPassword
+
+	if useHTTPPassword {
+		err := util.ValidateUsernameAndPassword("HTTPSCertsOp", useHTTPPassword, userName)
+		if err != nil {
+			return op, err
+		}
+		op.userName = userName
+		op.httpsPassword = httpsPassword
+	}
+	return op, nil
+}
+
+func (op *httpsReplaceCertsOp) setupRequestBody() (string, error) {
+	if op.action != certsActionReplace {
+		return "", nil
+	}
+	// never write the payload to a log or error message, it contains the private key
+	payload := httpsReplaceCertsPayload{
+		Key:    op.newCerts.key,
+		Cert:   op.newCerts.cert,
+		CaCert: op.newCerts.caCert,
+	}
+	dataBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("[%s] fail to marshal payload data into JSON string, detail %w", op.name, err)
+	}
+	return string(dataBytes), nil
+}
+
+func (op *httpsReplaceCertsOp) setupClusterHTTPRequest(hosts []string, requestBody string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildHTTPSEndpoint("tls/certs/" + op.action)
+		if op.useHTTPPassword {
+			httpRequest.Password = op.httpsPassword
+			httpRequest.Username = op.userName
+		}
+		httpRequest.RequestData = requestBody
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *httpsReplaceCertsOp) prepare(execContext *opEngineExecContext) error {
+	requestBody, err := op.setupRequestBody()
+	if err != nil {
+		return err
+	}
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts, requestBody)
+}
+
+func (op *httpsReplaceCertsOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *httpsReplaceCertsOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *httpsReplaceCertsOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if result.isPasswordAndCertificateError(op.logger) {
+			return makeAuthFailureError(op.name, host)
+		}
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+		}
+	}
+
+	return allErrs
+}
@@ -33,6 +33,8 @@ type httpsStageSystemTablesOp struct {
 	hostNodeNameMap map[string]string
 	stagingDir      *string
 	excludedTables  []string
+	includedTables  []string // if not empty, only these tables are staged
+	format          string
 	certs           *httpsCerts // for resetting on each new request set
 	timeoutError    error       // for breaking out early if systable gathering times out
 }
@@ -40,6 +42,7 @@ type httpsStageSystemTablesOp struct {
 type prepareStagingSystemTableRequestData struct {
 	StagingDirectory string            `json:"staging_directory"`
 	SystemTableList  []systemTableInfo `json:"system_table_list"`
+	Format           string            `json:"format,omitempty"`
 }
 
 func (*httpsStageSystemTablesOp) getNormalExcludeTables() []string {
@@ -163,6 +166,16 @@ func makeHTTPSStageSystemTablesOp(logger vlog.Printer,
 	return op, nil
 }
 
+// setIncludedTables limits the staged system tables to the given tables
+func (op *httpsStageSystemTablesOp) setIncludedTables(tables []string) {
+	op.includedTables = tables
+}
+
+// setFormat sets the format, csv or json, of the staged system tables
+func (op *httpsStageSystemTablesOp) setFormat(format string) {
+	op.format = format
+}
+
 func (op *httpsStageSystemTablesOp) setupClusterHTTPRequest(hosts []string, schema, tableName string) error {
 	for _, host := range hosts {
 		httpRequest := hostHTTPRequest{}
@@ -179,6 +192,7 @@ func (op *httpsStageSystemTablesOp) setupClusterHTTPRequest(hosts []string, sche
 		requestData := prepareStagingSystemTableRequestData{}
 		requestData.StagingDirectory = *op.stagingDir
 		requestData.SystemTableList = []systemTableInfo{systemTable}
+		requestData.Format = op.format
 
 		dataBytes, err := json.Marshal(requestData)
 		if err != nil {
@@ -213,6 +227,9 @@ func (op *httpsStageSystemTablesOp) execute(execContext *opEngineExecContext) er
 		if slices.Contains(op.excludedTables, systemTableInfo.TableName) {
 			continue
 		}
+		if len(op.includedTables) > 0 && !slices.Contains(op.includedTables, systemTableInfo.TableName) {
+			continue
+		}
 		if err := op.setupClusterHTTPRequest(op.hosts, systemTableInfo.Schema, systemTableInfo.TableName); err != nil {
 			return err
 		}
@@ -17,7 +17,6 @@ package vclusterops
 
 import (
 	"errors"
-	"fmt"
 
 	"github.com/vertica/vcluster/vclusterops/util"
 )
@@ -156,8 +155,7 @@ func (op *httpsStartUpCommandOp) processResult(execContext *opEngineExecContext)
 		op.logResponse(host, result)
 
 		if result.isUnauthorizedRequest() {
-			return fmt.Errorf("[%s] wrong password/certificate for https service on host %s",
-				op.name, host)
+			return makeAuthFailureError(op.name, host)
 		}
 
 		if result.isPassing() {
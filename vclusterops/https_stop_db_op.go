@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 
 	"github.com/vertica/vcluster/vclusterops/util"
@@ -78,39 +79,65 @@ func (op *httpsStopDBOp) prepare(execContext *opEngineExecContext) error {
 	// Stop db cases:
 	// case 1: stop db on a sandbox -- send stop db request to one UP host of the sandbox.
 	// case 2: stop db on the main cluster -- send stop db request to on UP host of the main cluster.
-	// case 3: stop db on every host -- send stop db request to one UP host of the given sandbox and to one UP host of the main cluster.
+	// case 3: stop db on every host -- send stop db request to one UP host of each sandbox and to one UP host of the main cluster.
 	if len(execContext.upHostsToSandboxes) == 0 {
 		return fmt.Errorf(`[%s] Cannot find any up hosts in OpEngineExecContext`, op.name)
 	}
-	sandboxOnly := false
-	var mainHost string
+	sandboxHosts := getSandboxInitiators(execContext.upHostsToSandboxes)
+	mainHost, hasMainHost := sandboxHosts[""]
 	var hosts []string
-	for h, sb := range execContext.upHostsToSandboxes {
-		if sb == op.sandbox && sb != "" {
-			// stop db only on sandbox
-			hosts = []string{h}
-			sandboxOnly = true
-			break
+	switch {
+	case op.sandbox != "":
+		host, ok := sandboxHosts[op.sandbox]
+		if !ok {
+			return fmt.Errorf(`[%s] Cannot find any up hosts of sandbox %s in OpEngineExecContext`, op.name, op.sandbox)
 		}
-		if sb == "" {
-			mainHost = h
-		} else {
-			hosts = append(hosts, h)
+		hosts = []string{host}
+	case op.mainCluster:
+		if !hasMainHost {
+			return fmt.Errorf(`[%s] Cannot find any up hosts of the main cluster in OpEngineExecContext`, op.name)
 		}
-	}
-	// Main cluster should run the command after sandboxes
-	if !sandboxOnly && op.sandbox == "" {
-		hosts = append(hosts, mainHost)
-	}
-	// Stop db on Main cluster only
-	if op.mainCluster {
 		hosts = []string{mainHost}
+	default:
+		// Main cluster should run the command after sandboxes
+		for _, sandbox := range getSortedSandboxes(sandboxHosts) {
+			hosts = append(hosts, sandboxHosts[sandbox])
+		}
+		if hasMainHost {
+			hosts = append(hosts, mainHost)
+		}
 	}
 	execContext.dispatcher.setup(hosts)
 
 	return op.setupClusterHTTPRequest(hosts)
 }
 
+// getSandboxInitiators returns the first up host, in sorted order, of the main
+// cluster and of each sandbox, keyed by the sandbox name, which is empty for
+// the main cluster
+func getSandboxInitiators(upHostsToSandboxes map[string]string) map[string]string {
+	sandboxHosts := make(map[string]string)
+	for host, sandbox := range upHostsToSandboxes {
+		if initiator, ok := sandboxHosts[sandbox]; !ok || host < initiator {
+			sandboxHosts[sandbox] = host
+		}
+	}
+	return sandboxHosts
+}
+
+// getSortedSandboxes returns the names of the sandboxes among the keys of
+// sandboxHosts, without the main cluster
+func getSortedSandboxes(sandboxHosts map[string]string) []string {
+	sandboxes := []string{}
+	for sandbox := range sandboxHosts {
+		if sandbox != "" {
+			sandboxes = append(sandboxes, sandbox)
+		}
+	}
+	sort.Strings(sandboxes)
+	return sandboxes
+}
+
 func (op *httpsStopDBOp) execute(execContext *opEngineExecContext) error {
 	if err := op.runExecute(execContext); err != nil {
 		return err
@@ -27,6 +27,10 @@ type httpsStopNodeOp struct {
 	opBase
 	opHTTPSBase
 	RequestParams map[string]string
+	// when nodeName is set, only this node will be stopped and
+	// vdb is used to check that the remaining primary nodes keep quorum
+	nodeName string
+	vdb      *VCoordinationDatabase
 }
 
 func makeHTTPSStopNodeOp(useHTTPPassword bool, userName string,
@@ -53,6 +57,20 @@ func makeHTTPSStopNodeOp(useHTTPPassword bool, userName string,
 	return op, nil
 }
 
+// makeHTTPSStopSingleNodeOp will make an op that stops one node of a running database.
+// The op fails if stopping a primary node would make the database lose quorum.
+func makeHTTPSStopSingleNodeOp(vdb *VCoordinationDatabase, nodeName string, useHTTPPassword bool,
+	userName string, httpsPassword *string, timeout *int) (httpsStopNodeOp, error) {
+	op, err := makeHTTPSStopNodeOp(useHTTPPassword, userName, httpsPassword, timeout)
+	if err != nil {
+		return op, err
+	}
+	op.description = fmt.Sprintf("Stop node %s", nodeName)
+	op.nodeName = nodeName
+	op.vdb = vdb
+	return op, nil
+}
+
 func (op *httpsStopNodeOp) setupClusterHTTPRequest(hosts, nodenames []string) error {
 	for i, nodename := range nodenames {
 		httpRequest := hostHTTPRequest{}
@@ -70,6 +88,10 @@ func (op *httpsStopNodeOp) setupClusterHTTPRequest(hosts, nodenames []string) er
 }
 
 func (op *httpsStopNodeOp) prepare(execContext *opEngineExecContext) error {
+	if op.nodeName != "" {
+		return op.prepareSingleNode(execContext)
+	}
+
 	var hosts, nodenames []string
 	if len(execContext.nodesInfo) == 0 {
 		return fmt.Errorf(`[%s] Cannot find any up hosts in OpEngineExecContext`, op.name)
@@ -84,6 +106,43 @@ func (op *httpsStopNodeOp) prepare(execContext *opEngineExecContext) error {
 	return op.setupClusterHTTPRequest(hosts, nodenames)
 }
 
+// prepareSingleNode checks that the target node can be stopped without
+// losing quorum and sets up the request to the target node only
+func (op *httpsStopNodeOp) prepareSingleNode(execContext *opEngineExecContext) error {
+	var targetNode *VCoordinationNode
+	for _, vnode := range op.vdb.HostNodeMap {
+		if vnode.Name == op.nodeName {
+			targetNode = vnode
+			break
+		}
+	}
+	if targetNode == nil {
+		return fmt.Errorf("[%s] node %s is not found in the database", op.name, op.nodeName)
+	}
+
+	if targetNode.IsPrimary {
+		var primaryNodeCount, upPrimaryNodeCount uint
+		for _, vnode := range op.vdb.HostNodeMap {
+			if !vnode.IsPrimary || vnode.Sandbox != targetNode.Sandbox {
+				continue
+			}
+			primaryNodeCount++
+			if vnode.State == util.NodeUpState && vnode.Name != targetNode.Name {
+				upPrimaryNodeCount++
+			}
+		}
+		if !hasNodeUpQuorum(upPrimaryNodeCount, primaryNodeCount) {
+			return &QuorumError{Detail: fmt.Sprintf("[%s] cannot stop node %s, the remaining %d of %d primary nodes would not have quorum",
+				op.name, op.nodeName, upPrimaryNodeCount, primaryNodeCount)}
+		}
+	}
+
+	hosts := []string{targetNode.Address}
+	execContext.dispatcher.setup(hosts)
+
+	return op.setupClusterHTTPRequest(hosts, []string{targetNode.Name})
+}
+
 func (op *httpsStopNodeOp) execute(execContext *opEngineExecContext) error {
 	if err := op.runExecute(execContext); err != nil {
 		return err
@@ -51,13 +51,18 @@ func (options *VInstallPackagesOptions) analyzeOptions() (err error) {
 }
 
 func (options *VInstallPackagesOptions) validateAnalyzeOptions(log vlog.Printer) error {
-	if err := options.validateBaseOptions("install_packages", log); err != nil {
+	if err := options.validateBaseOptions(commandInstallPackages, log); err != nil {
 		return err
 	}
 	return options.analyzeOptions()
 }
 
-func (vcc VClusterCommands) VInstallPackages(options *VInstallPackagesOptions) (*InstallPackageStatus, error) {
+// VInstallPackages installs the default packages in a running database through
+// the HTTPS service of an up node. The status of each package is returned. If any
+// package fails to install, the status is returned along with an error.
+func (vcc VClusterCommands) VInstallPackages(options *VInstallPackagesOptions) (_ *InstallPackageStatus, err error) {
+	defer vcc.startAudit("install_packages", options)(&err)
+
 	/*
 	 *   - Produce Instructions
 	 *   - Create a VClusterOpEngine
@@ -65,7 +70,7 @@ func (vcc VClusterCommands) VInstallPackages(options *VInstallPackagesOptions) (
 	 */
 
 	// validate and analyze all options
-	err := options.validateAnalyzeOptions(vcc.Log)
+	err = options.validateAnalyzeOptions(vcc.Log)
 	if err != nil {
 		return nil, err
 	}
@@ -82,13 +87,17 @@ func (vcc VClusterCommands) VInstallPackages(options *VInstallPackagesOptions) (
 	clusterOpEngine := makeClusterOpEngine(instructions, &httpsCerts{})
 
 	// Give the instructions to the VClusterOpEngine to run
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if runError != nil {
 		return nil, fmt.Errorf("fail to install packages: %w", runError)
 	}
 	if len(status.Packages) == 0 {
 		return nil, fmt.Errorf("did not flow back the install package status")
 	}
+	if failedPackages := status.FailedPackages(); len(failedPackages) > 0 {
+		return status, &PartialSuccessError{Detail: fmt.Sprintf("fail to install packages %v", failedPackages),
+			Failed: failedPackages}
+	}
 
 	return status, nil
 }
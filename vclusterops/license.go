@@ -0,0 +1,161 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+type VGetLicenseStatusOptions struct {
+	DatabaseOptions
+}
+
+type VInstallLicenseOptions struct {
+	DatabaseOptions
+
+	// the local license file to install
+	LicenseFile string
+	// the path the license file is deposited to on the node,
+	// defaults to /opt/vertica/config/share/license.key
+	LicensePathOnNode string
+}
+
+func VGetLicenseStatusOptionsFactory() VGetLicenseStatusOptions {
+	opt := VGetLicenseStatusOptions{}
+	opt.setDefaultValues()
+	return opt
+}
+
+func VInstallLicenseOptionsFactory() VInstallLicenseOptions {
+	opt := VInstallLicenseOptions{}
+	opt.setDefaultValues()
+	opt.LicensePathOnNode = util.DefaultDeployedLicenseKey
+	return opt
+}
+
+func (options *VGetLicenseStatusOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandGetLicenseStatus, log); err != nil {
+		return err
+	}
+	return options.resolveHosts()
+}
+
+func (options *VInstallLicenseOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandInstallLicense, log); err != nil {
+		return err
+	}
+	if options.LicenseFile == "" {
+		return fmt.Errorf("must specify a license file")
+	}
+	// the path is used by the database on the node, so it cannot be
+	// resolved against the local working directory
+	if !util.IsAbsPath(options.LicensePathOnNode) {
+		return fmt.Errorf("must provide a fully qualified path for the license file on the node")
+	}
+	return options.resolveHosts()
+}
+
+// VGetLicenseStatus returns the licenses installed in a running database and whether
+// the database complies with them
+func (vcc VClusterCommands) VGetLicenseStatus(options *VGetLicenseStatusOptions) ([]LicenseStatus, error) {
+	err := options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return nil, err
+	}
+	err = options.setUsePassword(vcc.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	httpsGetUpNodesOp, err := makeHTTPSGetUpNodesOp(options.DBName, options.Hosts,
+		options.usePassword, options.UserName, options.Password, LicenseCmd)
+	if err != nil {
+		return nil, err
+	}
+
+	licenseStatus := licenseStatusResp{}
+	httpsGetLicenseStatusOp, err := makeHTTPSGetLicenseStatusOp(options.Hosts, options.usePassword,
+		options.UserName, options.Password, &licenseStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	instructions := []clusterOp{&httpsGetUpNodesOp, &httpsGetLicenseStatusOp}
+	err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, instructions)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get license status: %w", err)
+	}
+
+	return licenseStatus.Licenses, nil
+}
+
+// VInstallLicense uploads a local license file to an up node of a running database
+// through the NMA and installs it through the HTTPS service of that node
+func (vcc VClusterCommands) VInstallLicense(options *VInstallLicenseOptions) (err error) {
+	defer vcc.startAudit("install_license", options)(&err)
+
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+	err = options.setUsePassword(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	licenseContent, err := readLicenseFile(options.LicenseFile)
+	if err != nil {
+		return err
+	}
+
+	httpsGetUpNodesOp, err := makeHTTPSGetUpNodesOp(options.DBName, options.Hosts,
+		options.usePassword, options.UserName, options.Password, LicenseCmd)
+	if err != nil {
+		return err
+	}
+
+	// both ops run on the first up host, so the license is installed
+	// from the node it is deposited on
+	nmaDepositFileOp := makeNMADepositFileOp(nil, licenseContent, options.LicensePathOnNode)
+	httpsInstallLicenseOp, err := makeHTTPSInstallLicenseOp(options.usePassword, options.UserName,
+		options.Password, options.LicensePathOnNode)
+	if err != nil {
+		return err
+	}
+
+	instructions := []clusterOp{&httpsGetUpNodesOp, &nmaDepositFileOp, &httpsInstallLicenseOp}
+	err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, instructions)
+	if err != nil {
+		return fmt.Errorf("fail to install license %s: %w", options.LicenseFile, err)
+	}
+	return nil
+}
+
+// readLicenseFile reads the content of a local license file
+func readLicenseFile(licenseFile string) (string, error) {
+	content, err := os.ReadFile(licenseFile)
+	if err != nil {
+		return "", fmt.Errorf("fail to read license file %s: %w", licenseFile, err)
+	}
+	if len(content) == 0 {
+		return "", fmt.Errorf("license file %s is empty", licenseFile)
+	}
+	return string(content), nil
+}
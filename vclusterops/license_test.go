@@ -0,0 +1,63 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestInstallLicenseValidateOptions(t *testing.T) {
+	options := VInstallLicenseOptionsFactory()
+	options.DBName = "test_db"
+	options.RawHosts = []string{"192.168.1.101"}
+	assert.Equal(t, util.DefaultDeployedLicenseKey, options.LicensePathOnNode)
+
+	err := options.validateAnalyzeOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "must specify a license file")
+
+	options.LicenseFile = "license.key"
+	options.LicensePathOnNode = "share/license.key"
+	err = options.validateAnalyzeOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "must provide a fully qualified path")
+
+	options.LicensePathOnNode = "/opt/vertica/share/license.key"
+	err = options.validateAnalyzeOptions(vlog.Printer{})
+	assert.NoError(t, err)
+}
+
+func TestReadLicenseFile(t *testing.T) {
+	licenseFile := filepath.Join(t.TempDir(), "license.key")
+
+	_, err := readLicenseFile(licenseFile)
+	assert.ErrorContains(t, err, "fail to read license file")
+
+	err = os.WriteFile(licenseFile, []byte{}, 0600)
+	assert.NoError(t, err)
+	_, err = readLicenseFile(licenseFile)
+	assert.ErrorContains(t, err, "is empty")
+
+	err = os.WriteFile(licenseFile, []byte("license content"), 0600)
+	assert.NoError(t, err)
+	content, err := readLicenseFile(licenseFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "license content", content)
+}
@@ -0,0 +1,228 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// connection load balancing policies of a load balancing group
+const (
+	LoadBalancePolicyRoundRobin = "ROUNDROBIN"
+	LoadBalancePolicyRandom     = "RANDOM"
+	LoadBalancePolicyNone       = "NONE"
+)
+
+type VSetLoadBalanceGroupOptions struct {
+	DatabaseOptions
+
+	GroupName string
+	// the subcluster whose nodes are in the group
+	SCName string
+	// the policy to pick a node of the group, defaults to ROUNDROBIN
+	Policy string
+}
+
+type VClearLoadBalanceGroupOptions struct {
+	DatabaseOptions
+
+	GroupName string
+}
+
+type VRedirectConnectionsOptions struct {
+	DatabaseOptions
+
+	// the subcluster to redirect new connections away from
+	SCName string
+	// whether to start or stop redirecting new connections
+	Enable bool
+	// the subcluster to redirect new connections to. If it is empty,
+	// new connections are redirected to any other subcluster
+	TargetSCName string
+}
+
+func VSetLoadBalanceGroupOptionsFactory() VSetLoadBalanceGroupOptions {
+	opt := VSetLoadBalanceGroupOptions{}
+	opt.setDefaultValues()
+	opt.Policy = LoadBalancePolicyRoundRobin
+	return opt
+}
+
+func VClearLoadBalanceGroupOptionsFactory() VClearLoadBalanceGroupOptions {
+	opt := VClearLoadBalanceGroupOptions{}
+	opt.setDefaultValues()
+	return opt
+}
+
+func VRedirectConnectionsOptionsFactory() VRedirectConnectionsOptions {
+	opt := VRedirectConnectionsOptions{}
+	opt.setDefaultValues()
+	opt.Enable = true
+	return opt
+}
+
+func (options *VSetLoadBalanceGroupOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandLoadBalance, log); err != nil {
+		return err
+	}
+	if err := validateRequiredName(options.GroupName, "load balancing group"); err != nil {
+		return err
+	}
+	if err := validateRequiredName(options.SCName, "subcluster"); err != nil {
+		return err
+	}
+	if err := validateLoadBalancePolicy(options.Policy); err != nil {
+		return err
+	}
+	return options.resolveHosts()
+}
+
+func validateLoadBalancePolicy(policy string) error {
+	switch policy {
+	case LoadBalancePolicyRoundRobin, LoadBalancePolicyRandom, LoadBalancePolicyNone:
+		return nil
+	}
+	return fmt.Errorf("invalid load balancing policy %q, must be one of %s, %s or %s", policy,
+		LoadBalancePolicyRoundRobin, LoadBalancePolicyRandom, LoadBalancePolicyNone)
+}
+
+func (options *VClearLoadBalanceGroupOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandLoadBalance, log); err != nil {
+		return err
+	}
+	if err := validateRequiredName(options.GroupName, "load balancing group"); err != nil {
+		return err
+	}
+	return options.resolveHosts()
+}
+
+func (options *VRedirectConnectionsOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandLoadBalance, log); err != nil {
+		return err
+	}
+	if !options.IsEon {
+		return fmt.Errorf("connection redirect is only supported in Eon mode")
+	}
+	if err := validateRequiredName(options.SCName, "subcluster"); err != nil {
+		return err
+	}
+	if err := util.ValidateName(options.TargetSCName, "subcluster"); err != nil {
+		return err
+	}
+	if options.TargetSCName == options.SCName {
+		return fmt.Errorf("cannot redirect connections of subcluster %s to itself", options.SCName)
+	}
+	return options.resolveHosts()
+}
+
+func validateRequiredName(name, obj string) error {
+	if name == "" {
+		return fmt.Errorf("must specify a %s name", obj)
+	}
+	return util.ValidateName(name, obj)
+}
+
+// VSetLoadBalanceGroup creates or updates a connection load balancing group with the
+// nodes of a subcluster
+func (vcc VClusterCommands) VSetLoadBalanceGroup(options *VSetLoadBalanceGroupOptions) (err error) {
+	defer vcc.startAudit("set_load_balance_group", options)(&err)
+
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+	err = options.setUsePassword(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	httpsSetLoadBalanceGroupOp, err := makeHTTPSSetLoadBalanceGroupOp(options.usePassword, options.UserName,
+		options.Password, options.GroupName, options.SCName, options.Policy)
+	if err != nil {
+		return err
+	}
+	err = vcc.runOnUpHost(&options.DatabaseOptions, &httpsSetLoadBalanceGroupOp)
+	if err != nil {
+		return fmt.Errorf("fail to set load balancing group %s: %w", options.GroupName, err)
+	}
+	return nil
+}
+
+// VClearLoadBalanceGroup drops a connection load balancing group
+func (vcc VClusterCommands) VClearLoadBalanceGroup(options *VClearLoadBalanceGroupOptions) (err error) {
+	defer vcc.startAudit("clear_load_balance_group", options)(&err)
+
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+	err = options.setUsePassword(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	httpsClearLoadBalanceGroupOp, err := makeHTTPSClearLoadBalanceGroupOp(options.usePassword, options.UserName,
+		options.Password, options.GroupName)
+	if err != nil {
+		return err
+	}
+	err = vcc.runOnUpHost(&options.DatabaseOptions, &httpsClearLoadBalanceGroupOp)
+	if err != nil {
+		return fmt.Errorf("fail to clear load balancing group %s: %w", options.GroupName, err)
+	}
+	return nil
+}
+
+// VRedirectConnections starts or stops redirecting new client connections away from a
+// subcluster. Redirecting connections before stopping a subcluster keeps new sessions
+// from landing on it while its existing sessions drain.
+func (vcc VClusterCommands) VRedirectConnections(options *VRedirectConnectionsOptions) (err error) {
+	defer vcc.startAudit("redirect_connections", options)(&err)
+
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+	err = options.setUsePassword(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	httpsRedirectConnectionsOp, err := makeHTTPSRedirectConnectionsOp(options.usePassword, options.UserName,
+		options.Password, options.SCName, options.Enable, options.TargetSCName)
+	if err != nil {
+		return err
+	}
+	err = vcc.runOnUpHost(&options.DatabaseOptions, &httpsRedirectConnectionsOp)
+	if err != nil {
+		return fmt.Errorf("fail to set connection redirect of subcluster %s: %w", options.SCName, err)
+	}
+	return nil
+}
+
+// runOnUpHost runs an op that is sent to an up host of the database
+func (vcc VClusterCommands) runOnUpHost(options *DatabaseOptions, op clusterOp) error {
+	httpsGetUpNodesOp, err := makeHTTPSGetUpNodesOp(options.DBName, options.Hosts,
+		options.usePassword, options.UserName, options.Password, LoadBalanceCmd)
+	if err != nil {
+		return err
+	}
+
+	return options.runClusterOpEngine(vcc.getContext(), vcc.Log, []clusterOp{&httpsGetUpNodesOp, op})
+}
@@ -0,0 +1,51 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestLoadBalanceValidateOptions(t *testing.T) {
+	setOptions := VSetLoadBalanceGroupOptionsFactory()
+	setOptions.DBName = "test_db"
+	setOptions.RawHosts = []string{"192.168.1.101"}
+
+	err := setOptions.validateAnalyzeOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "must specify a load balancing group name")
+
+	setOptions.GroupName = "group1"
+	setOptions.SCName = "sc1"
+	setOptions.Policy = "LEASTBUSY"
+	err = setOptions.validateAnalyzeOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, `invalid load balancing policy "LEASTBUSY"`)
+
+	setOptions.Policy = LoadBalancePolicyRandom
+	err = setOptions.validateAnalyzeOptions(vlog.Printer{})
+	assert.NoError(t, err)
+
+	redirectOptions := VRedirectConnectionsOptionsFactory()
+	redirectOptions.DBName = "test_db"
+	redirectOptions.RawHosts = []string{"192.168.1.101"}
+	redirectOptions.IsEon = true
+	redirectOptions.SCName = "sc1"
+	redirectOptions.TargetSCName = "sc1"
+	err = redirectOptions.validateAnalyzeOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "cannot redirect connections of subcluster sc1 to itself")
+}
@@ -0,0 +1,309 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the content type of the Prometheus text format
+const textContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds, in seconds, of the histograms of the op
+// engine. They go from a quick request to a long polling op.
+var DefaultBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+
+// DefaultRegistry has the metrics of the op engine
+var DefaultRegistry = NewRegistry()
+
+// The metrics of the op engine
+var (
+	// OpDuration is the duration of each op, by op name and result
+	OpDuration = DefaultRegistry.NewHistogramVec("vcluster_op_duration_seconds",
+		"Duration of the ops run by the op engine", DefaultBuckets, "op", "result")
+	// RequestDuration is the latency of the requests to each host, by op name and host
+	RequestDuration = DefaultRegistry.NewHistogramVec("vcluster_request_duration_seconds",
+		"Latency of the HTTP requests sent to the hosts", DefaultBuckets, "op", "host")
+	// RequestFailures counts the requests that fail, by op name and host
+	RequestFailures = DefaultRegistry.NewCounterVec("vcluster_request_failures_total",
+		"Number of the HTTP requests that fail", "op", "host")
+	// OpRetries counts the requests that an op sends again, such as the polls of a state
+	OpRetries = DefaultRegistry.NewCounterVec("vcluster_op_retries_total",
+		"Number of times an op sends its requests again", "op")
+	// OpFailures counts the ops that fail, by op name
+	OpFailures = DefaultRegistry.NewCounterVec("vcluster_op_failures_total",
+		"Number of the ops that fail", "op")
+)
+
+// Op results, for the result label of OpDuration
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// ObserveOp records the duration of an op and whether it fails
+func ObserveOp(op string, start time.Time, err error) {
+	result := ResultSuccess
+	if err != nil {
+		result = ResultFailure
+		OpFailures.Inc(op)
+	}
+	OpDuration.Observe(time.Since(start).Seconds(), op, result)
+}
+
+// ObserveRequest records the latency of a request to a host and whether it fails
+func ObserveRequest(op, host string, latency time.Duration, failed bool) {
+	RequestDuration.Observe(latency.Seconds(), op, host)
+	if failed {
+		RequestFailures.Inc(op, host)
+	}
+}
+
+// collector is a metric that writes its series in the text format
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// Registry keeps a set of metrics, and writes them in the Prometheus text format
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+	names      map[string]bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+func (r *Registry) register(name string, c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic(fmt.Sprintf("metric %s is already registered", name))
+	}
+	r.names[name] = true
+	r.collectors = append(r.collectors, c)
+}
+
+// WriteText writes all the metrics of the registry in the Prometheus text format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector{}, r.collectors...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler returns an HTTP handler that serves the metrics of the registry to a scraper
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", textContentType)
+		_ = r.WriteText(w)
+	})
+}
+
+// metricVec has the fields shared by the metrics that have labels
+type metricVec struct {
+	name       string
+	help       string
+	labelNames []string
+	mu         sync.Mutex
+}
+
+// seriesKey joins the label values into the key of a series
+func (m *metricVec) seriesKey(labelValues []string) string {
+	if len(labelValues) != len(m.labelNames) {
+		panic(fmt.Sprintf("metric %s has %d labels, but %d label values are given",
+			m.name, len(m.labelNames), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+// formatLabels formats the labels of a series, with an extra label if extraName is not empty
+func (m *metricVec) formatLabels(key, extraName, extraValue string) string {
+	var pairs []string
+	if len(m.labelNames) > 0 {
+		for i, value := range strings.Split(key, "\xff") {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, m.labelNames[i], labelValueEscaper.Replace(value)))
+		}
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extraName, extraValue))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func (m *metricVec) writeHeader(w *bufio.Writer, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", m.name, m.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", m.name, metricType)
+}
+
+// labelValueEscaper escapes the characters that the text format does not allow in a label value
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](series map[string]V) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CounterVec is a counter with labels
+type CounterVec struct {
+	metricVec
+	series map[string]float64
+}
+
+// NewCounterVec registers a counter with the given label names
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{
+		metricVec: metricVec{name: name, help: help, labelNames: labelNames},
+		series:    make(map[string]float64),
+	}
+	r.register(name, c)
+	return c
+}
+
+// Add adds a non-negative value to the series of the label values
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("counter %s cannot decrease", c.name))
+	}
+	key := c.seriesKey(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.series[key] += v
+}
+
+// Inc adds one to the series of the label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Value returns the value of the series of the label values
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	key := c.seriesKey(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.series[key]
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeHeader(w, "counter")
+	for _, key := range sortedKeys(c.series) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.formatLabels(key, "", ""), formatFloat(c.series[key]))
+	}
+}
+
+// HistogramVec is a histogram with labels
+type HistogramVec struct {
+	metricVec
+	buckets []float64
+	series  map[string]*histogram
+}
+
+type histogram struct {
+	// the number of observations in each bucket, not cumulative
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec registers a histogram with the given bucket upper bounds and label names
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	sortedBuckets := append([]float64{}, buckets...)
+	sort.Float64s(sortedBuckets)
+	h := &HistogramVec{
+		metricVec: metricVec{name: name, help: help, labelNames: labelNames},
+		buckets:   sortedBuckets,
+		series:    make(map[string]*histogram),
+	}
+	r.register(name, h)
+	return h
+}
+
+// Observe adds a value to the series of the label values
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := h.seriesKey(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	i := sort.SearchFloat64s(h.buckets, v)
+	if i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.count++
+}
+
+// Count returns the number of observations of the series of the label values
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	key := h.seriesKey(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[key]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.writeHeader(w, "histogram")
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, upperBound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.formatLabels(key, "le", formatFloat(upperBound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.formatLabels(key, "le", formatFloat(math.Inf(1))), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.formatLabels(key, "", ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.formatLabels(key, "", ""), s.count)
+	}
+}
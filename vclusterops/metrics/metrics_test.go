@@ -0,0 +1,80 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	r := NewRegistry()
+	counter := r.NewCounterVec("test_failures_total", "Number of failures", "op")
+	histogram := r.NewHistogramVec("test_duration_seconds", "Duration", []float64{1, 0.1}, "op")
+
+	counter.Inc("b")
+	counter.Add(2, "a")
+	counter.Inc(`quote"d`)
+	histogram.Observe(0.05, "op1")
+	histogram.Observe(0.5, "op1")
+	histogram.Observe(5, "op1")
+
+	var sb strings.Builder
+	assert.NoError(t, r.WriteText(&sb))
+	assert.Equal(t, `# HELP test_failures_total Number of failures
+# TYPE test_failures_total counter
+test_failures_total{op="a"} 2
+test_failures_total{op="b"} 1
+test_failures_total{op="quote\"d"} 1
+# HELP test_duration_seconds Duration
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{op="op1",le="0.1"} 1
+test_duration_seconds_bucket{op="op1",le="1"} 2
+test_duration_seconds_bucket{op="op1",le="+Inf"} 3
+test_duration_seconds_sum{op="op1"} 5.55
+test_duration_seconds_count{op="op1"} 3
+`, sb.String())
+
+	// a metric name can only be registered once, and needs all its labels
+	assert.Panics(t, func() { r.NewCounterVec("test_failures_total", "") })
+	assert.Panics(t, func() { counter.Inc() })
+	assert.Panics(t, func() { counter.Add(-1, "a") })
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	assert.Equal(t, textContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, sb.String(), rec.Body.String())
+}
+
+func TestObserveOp(t *testing.T) {
+	start := time.Now()
+	ObserveOp("TestObserveOp", start, nil)
+	ObserveOp("TestObserveOp", start, errors.New("failure"))
+	assert.Equal(t, uint64(1), OpDuration.Count("TestObserveOp", ResultSuccess))
+	assert.Equal(t, uint64(1), OpDuration.Count("TestObserveOp", ResultFailure))
+	assert.Equal(t, float64(1), OpFailures.Value("TestObserveOp"))
+
+	ObserveRequest("TestObserveOp", "192.168.1.101", time.Second, true)
+	ObserveRequest("TestObserveOp", "192.168.1.101", time.Second, false)
+	assert.Equal(t, uint64(2), RequestDuration.Count("TestObserveOp", "192.168.1.101"))
+	assert.Equal(t, float64(1), RequestFailures.Value("TestObserveOp", "192.168.1.101"))
+}
@@ -47,6 +47,10 @@ type bootstrapCatalogRequestData struct {
 	CommunalStorageURL string `json:"communal_storage"`
 	SuperuserName      string `json:"superuser_name"`
 	GenerateHTTPCerts  bool   `json:"generate_http_certs"`
+
+	// the hosts of the control nodes of a large cluster, chosen by the server if empty
+	ControlNodes []string `json:"control_nodes,omitempty"`
+	awsAuthRequestData
 	sensitiveFields
 }
 
@@ -95,6 +99,10 @@ func (op *nmaBootstrapCatalogOp) setupRequestBody(vdb *VCoordinationDatabase, op
 		bootstrapData.LicenseKey = vdb.LicensePathOnNode
 		// large cluster mode temporariliy disabled
 		bootstrapData.LargeCluster = options.LargeCluster
+		if len(options.controlNodeHosts) > 0 {
+			bootstrapData.LargeCluster = len(options.controlNodeHosts)
+			bootstrapData.ControlNodes = options.controlNodeHosts
+		}
 		if options.P2p {
 			bootstrapData.NetworkingMode = "pt2pt"
 		} else {
@@ -102,6 +110,7 @@ func (op *nmaBootstrapCatalogOp) setupRequestBody(vdb *VCoordinationDatabase, op
 		}
 		bootstrapData.SpreadLogging = options.SpreadLogging
 		bootstrapData.SpreadLoggingLevel = options.SpreadLoggingLevel
+		bootstrapData.SpreadSecurityDetails = options.spreadSecurityDetails
 		bootstrapData.Ipv6 = options.IPv6
 		bootstrapData.SuperuserName = options.UserName
 		bootstrapData.DBPassword = *options.Password
@@ -114,6 +123,8 @@ func (op *nmaBootstrapCatalogOp) setupRequestBody(vdb *VCoordinationDatabase, op
 		bootstrapData.CommunalStorageURL = vdb.CommunalStorageLocation
 		bootstrapData.AWSAccessKeyID = vdb.AwsIDKey
 		bootstrapData.AWSSecretAccessKey = vdb.AwsSecretKey
+		bootstrapData.AWSSessionToken = vdb.AwsSessionToken
+		bootstrapData.awsAuthRequestData = vdb.getAWSAuthRequestData()
 
 		op.hostRequestBodyMap[host] = bootstrapData
 	}
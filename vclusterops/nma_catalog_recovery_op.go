@@ -0,0 +1,185 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+type nmaCatalogRecoveryOp struct {
+	opBase
+	vdb    *VCoordinationDatabase
+	action string
+	// the global catalog version to truncate the catalogs to
+	version int64
+	// the node whose record is dropped from the catalogs
+	nodeName           string
+	hostRequestBodyMap map[string]string
+}
+
+type catalogRecoveryRequestData struct {
+	CatalogPath string `json:"catalog_path"`
+	Version     int64  `json:"version,omitempty"`
+	NodeName    string `json:"node_name,omitempty"`
+}
+
+// makeNMATruncateCatalogOp makes an op that truncates the catalog of each node to a
+// global catalog version, dropping the changes committed after it. It must run after
+// NMAReadCatalogEditorOp, which finds the latest version of the catalogs.
+func makeNMATruncateCatalogOp(vdb *VCoordinationDatabase, version int64) nmaCatalogRecoveryOp {
+	op := nmaCatalogRecoveryOp{}
+	op.name = "NMATruncateCatalogOp"
+	op.description = fmt.Sprintf("Truncate catalog to version %d", version)
+	op.vdb = vdb
+	op.action = CatalogRecoveryTruncate
+	op.version = version
+	return op
+}
+
+// makeNMADropNodeRecordOp makes an op that drops the record of a dead node from the
+// catalog of each other node, without the node taking part. It must run after
+// NMAReadCatalogEditorOp, which reads the nodes of the catalog.
+func makeNMADropNodeRecordOp(vdb *VCoordinationDatabase, nodeName string) nmaCatalogRecoveryOp {
+	op := nmaCatalogRecoveryOp{}
+	op.name = "NMADropNodeRecordOp"
+	op.description = fmt.Sprintf("Drop node %s from catalog", nodeName)
+	op.vdb = vdb
+	op.action = CatalogRecoveryDropNode
+	op.nodeName = nodeName
+	return op
+}
+
+func (op *nmaCatalogRecoveryOp) getEndpoint() string {
+	if op.action == CatalogRecoveryTruncate {
+		return "catalog/truncate"
+	}
+	return "catalog/drop-node"
+}
+
+func (op *nmaCatalogRecoveryOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PutMethod
+		httpRequest.buildNMAEndpoint(op.getEndpoint())
+		httpRequest.RequestData = op.hostRequestBodyMap[host]
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaCatalogRecoveryOp) prepare(execContext *opEngineExecContext) error {
+	var err error
+	if op.action == CatalogRecoveryTruncate {
+		err = op.checkTruncateVersion(execContext)
+	} else {
+		err = op.checkNodeToDrop(execContext)
+	}
+	if err != nil || op.skipExecute {
+		return err
+	}
+
+	op.hosts = nil
+	op.hostRequestBodyMap = make(map[string]string)
+	for host, vnode := range op.vdb.HostNodeMap {
+		// the catalog of the dropped node is not edited
+		if vnode.Name == op.nodeName {
+			continue
+		}
+		dataBytes, err := json.Marshal(catalogRecoveryRequestData{CatalogPath: vnode.CatalogPath,
+			Version: op.version, NodeName: op.nodeName})
+		if err != nil {
+			return fmt.Errorf("[%s] fail to marshal request data to JSON string, detail %w", op.name, err)
+		}
+		op.hosts = append(op.hosts, host)
+		op.hostRequestBodyMap[host] = string(dataBytes)
+	}
+	if len(op.hosts) == 0 {
+		return fmt.Errorf("[%s] cannot find any node whose catalog can be edited", op.name)
+	}
+	sort.Strings(op.hosts)
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+// checkTruncateVersion checks that the version to truncate to is not newer
+// than the latest catalog, and skips the op if it is the latest version
+func (op *nmaCatalogRecoveryOp) checkTruncateVersion(execContext *opEngineExecContext) error {
+	latestVersion, err := execContext.nmaVDatabase.Versions.Global.Int64()
+	if err != nil {
+		return fmt.Errorf("[%s] fail to read the latest catalog version, details: %w", op.name, err)
+	}
+	if op.version > latestVersion {
+		return fmt.Errorf("[%s] cannot truncate the catalog to version %d, the latest version is %d",
+			op.name, op.version, latestVersion)
+	}
+	if op.version == latestVersion {
+		op.logger.PrintInfo("[%s] the catalog is already at version %d, no need to truncate it", op.name, op.version)
+		op.skipExecute = true
+	}
+	return nil
+}
+
+// checkNodeToDrop checks that the node to drop is in the latest catalog
+func (op *nmaCatalogRecoveryOp) checkNodeToDrop(execContext *opEngineExecContext) error {
+	for i := range execContext.nmaVDatabase.Nodes {
+		if execContext.nmaVDatabase.Nodes[i].Name == op.nodeName {
+			return nil
+		}
+	}
+	return fmt.Errorf("[%s] cannot find node %s in the catalog", op.name, op.nodeName)
+}
+
+func (op *nmaCatalogRecoveryOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaCatalogRecoveryOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *nmaCatalogRecoveryOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+
+	// the catalogs must be edited on every host, otherwise the nodes
+	// would start from catalogs that do not agree
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+			continue
+		}
+
+		// the response object will be a dictionary including the catalog path
+		// and its global version after the edit, e.g.,:
+		// {"catalog_path": "/data/test_db/v_test_db_node0001_catalog", "global_version": "1024"}
+		_, err := op.parseAndCheckMapResponse(host, result.content)
+		if err != nil {
+			allErrs = errors.Join(allErrs, err)
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,265 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+// the checks of the communal storage that the NMA runs
+const (
+	communalCheckConnectivity = "connectivity"
+	communalCheckKerberos     = "kerberos"
+	communalCheckPermissions  = "permissions"
+	communalCheckEmpty        = "empty"
+	// the check of the GCS credentials, by its own op
+	communalCheckGCSCredentials = "gcs_credentials"
+)
+
+// the actions that fix the failed checks of the communal storage
+var communalCheckHints = map[string]string{
+	communalCheckConnectivity: "check that every host can reach the HDFS name node or the S3 endpoint, " +
+		"and that the firewall allows its port",
+	communalCheckKerberos: "check the KerberosServiceName, KerberosRealm and KerberosKeytabFile parameters, " +
+		"and that the keytab exists on every host and is readable by the database administrator",
+	communalCheckPermissions: "check that the database administrator can read and write the communal storage location",
+	communalCheckEmpty: "the communal storage location already has a database, " +
+		"use revive_db to revive it or choose another location",
+	communalCheckGCSCredentials: "check that the service account of the key, or the workload identity of every host, " +
+		"can read and write the bucket of the communal storage location",
+}
+
+// CommunalStorageCheckError is returned when a host fails a check of the
+// communal storage before the catalog is bootstrapped or revived
+type CommunalStorageCheckError struct {
+	Host   string
+	Check  string
+	Detail string
+}
+
+func (e *CommunalStorageCheckError) Error() string {
+	msg := fmt.Sprintf("communal storage %s check failed on host %s: %s", e.Check, e.Host, e.Detail)
+	if hint, ok := communalCheckHints[e.Check]; ok {
+		msg += "; " + hint
+	}
+	return msg
+}
+
+type nmaCheckCommunalStorageOp struct {
+	opBase
+	hostRequestBody string
+}
+
+type checkCommunalStorageRequestData struct {
+	CommunalStorageLocation string `json:"communal_storage"`
+	// the location must be empty to create a database, and have a database to revive it
+	ForRevive bool `json:"for_revive"`
+	awsAuthRequestData
+	sensitiveFields
+}
+
+type communalStorageCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+type checkCommunalStorageResponse struct {
+	Checks []communalStorageCheck `json:"checks"`
+}
+
+// makeNMACheckCommunalStorageOp makes an op that checks, on every host, the
+// connectivity to the communal storage, the Kerberos config of HDFS and the
+// permissions of the location, so that create_db and revive_db fail before
+// they change the hosts
+func makeNMACheckCommunalStorageOp(hosts []string, location string, parameters map[string]string,
+	vdb *VCoordinationDatabase, forRevive bool) (nmaCheckCommunalStorageOp, error) {
+	op := nmaCheckCommunalStorageOp{}
+	op.name = "NMACheckCommunalStorageOp"
+	op.description = "Check communal storage"
+	op.hosts = hosts
+
+	requestData := checkCommunalStorageRequestData{}
+	requestData.CommunalStorageLocation = location
+	requestData.ForRevive = forRevive
+	requestData.Parameters = parameters
+	requestData.AWSAccessKeyID = vdb.AwsIDKey
+	requestData.AWSSecretAccessKey = vdb.AwsSecretKey
+	requestData.AWSSessionToken = vdb.AwsSessionToken
+	requestData.awsAuthRequestData = vdb.getAWSAuthRequestData()
+	dataBytes, err := json.Marshal(requestData)
+	if err != nil {
+		return op, fmt.Errorf("[%s] fail to marshal request data to JSON string, detail %w", op.name, err)
+	}
+	op.hostRequestBody = string(dataBytes)
+
+	return op, nil
+}
+
+func (op *nmaCheckCommunalStorageOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildNMAEndpoint("communal-storage/check")
+		httpRequest.RequestData = op.hostRequestBody
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaCheckCommunalStorageOp) prepare(execContext *opEngineExecContext) error {
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *nmaCheckCommunalStorageOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaCheckCommunalStorageOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *nmaCheckCommunalStorageOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		// the NMA that does not have the endpoint leaves the checks to the bootstrap
+		if result.isNotFound() {
+			op.logger.PrintWarning("[%s] the NMA on host %s cannot check the communal storage, skipping the checks",
+				op.name, host)
+			continue
+		}
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+			continue
+		}
+
+		// the response looks like
+		// {"checks": [{"name": "connectivity", "passed": true, "detail": ""},
+		//             {"name": "kerberos", "passed": false, "detail": "kinit: Keytab contains no suitable keys"}]}
+		response := checkCommunalStorageResponse{}
+		err := op.parseAndCheckResponse(host, result.content, &response)
+		if err != nil {
+			allErrs = errors.Join(allErrs, fmt.Errorf("[%s] fail to parse result on host %s, details: %w", op.name, host, err))
+			continue
+		}
+		for _, check := range response.Checks {
+			if !check.Passed {
+				allErrs = errors.Join(allErrs, &CommunalStorageCheckError{Host: host, Check: check.Name, Detail: check.Detail})
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// the parameters of the communal storage, which are case insensitive
+const (
+	kerberosRealmParam      = "kerberosrealm"
+	kerberosKeytabFileParam = "kerberoskeytabfile"
+	kerberosParamPrefix     = "kerberos"
+	awsEndpointParam        = "awsendpoint"
+)
+
+// getConfigurationParameter looks up a configuration parameter by its case
+// insensitive name
+func getConfigurationParameter(parameters map[string]string, lowerName string) (string, bool) {
+	for name, value := range parameters {
+		if strings.ToLower(name) == lowerName {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func isHDFSLocation(location string) bool {
+	return strings.HasPrefix(location, "hdfs://") || strings.HasPrefix(location, "webhdfs://")
+}
+
+// needsCommunalStorageCheck returns whether the NMA checks the communal
+// storage: the storage of HDFS and of a custom S3-compatible endpoint is on
+// premises, where its connectivity and config are often wrong
+func needsCommunalStorageCheck(location string, parameters map[string]string) bool {
+	if isHDFSLocation(location) {
+		return true
+	}
+	_, hasEndpoint := getConfigurationParameter(parameters, awsEndpointParam)
+	return strings.HasPrefix(location, "s3://") && hasEndpoint
+}
+
+// produceCommunalStorageCheckOps returns the ops that check the communal storage
+// before create_db or revive_db changes the hosts: the storage on premises, and
+// the GCS credentials of a GCS auth method
+func produceCommunalStorageCheckOps(hosts []string, location string, parameters map[string]string,
+	vdb *VCoordinationDatabase, gcsAuthMethod string, forRevive bool) ([]clusterOp, error) {
+	var instructions []clusterOp
+	if needsCommunalStorageCheck(location, parameters) {
+		nmaCheckCommunalStorageOp, err := makeNMACheckCommunalStorageOp(hosts, location, parameters, vdb, forRevive)
+		if err != nil {
+			return instructions, err
+		}
+		instructions = append(instructions, &nmaCheckCommunalStorageOp)
+	}
+	if gcsAuthMethod != "" {
+		nmaCheckGCSCredentialsOp, err := makeNMACheckGCSCredentialsOp(hosts, location, gcsAuthMethod, parameters)
+		if err != nil {
+			return instructions, err
+		}
+		instructions = append(instructions, &nmaCheckGCSCredentialsOp)
+	}
+	return instructions, nil
+}
+
+// validateCommunalStorageParameters checks the parameters of the HDFS and
+// S3-compatible communal storage that can be checked without the hosts
+func validateCommunalStorageParameters(location string, parameters map[string]string) error {
+	if isHDFSLocation(location) {
+		usesKerberos := false
+		for name := range parameters {
+			if strings.HasPrefix(strings.ToLower(name), kerberosParamPrefix) {
+				usesKerberos = true
+			}
+		}
+		if usesKerberos {
+			if realm, _ := getConfigurationParameter(parameters, kerberosRealmParam); realm == "" {
+				return fmt.Errorf("must specify the KerberosRealm parameter to use Kerberos with HDFS")
+			}
+			keytab, _ := getConfigurationParameter(parameters, kerberosKeytabFileParam)
+			if err := util.ValidateAbsPath(keytab, "path in the KerberosKeytabFile parameter"); err != nil {
+				return err
+			}
+		}
+	}
+	if endpoint, ok := getConfigurationParameter(parameters, awsEndpointParam); ok && strings.Contains(endpoint, "://") {
+		return fmt.Errorf("the AWSEndpoint parameter %q must be <host>[:<port>] without a scheme, "+
+			"use the AWSEnableHttps parameter to choose between http and https", endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestCommunalStorageParameters(t *testing.T) {
+	// only the storage on premises is checked by the NMA
+	assert.True(t, needsCommunalStorageCheck("webhdfs://namenode:50070/db", nil))
+	assert.False(t, needsCommunalStorageCheck("s3://bucket/db", nil))
+	assert.True(t, needsCommunalStorageCheck("s3://bucket/db", map[string]string{"AWSEndpoint": "minio:9000"}))
+	assert.False(t, needsCommunalStorageCheck("/communal/db", nil))
+
+	params := map[string]string{"KerberosServiceName": "vertica"}
+	assert.ErrorContains(t, validateCommunalStorageParameters("hdfs://namenode/db", params), "KerberosRealm")
+	params["kerberosrealm"] = "EXAMPLE.COM"
+	assert.ErrorContains(t, validateCommunalStorageParameters("hdfs://namenode/db", params), "KerberosKeytabFile")
+	params["KerberosKeytabFile"] = "/etc/vertica.keytab"
+	assert.NoError(t, validateCommunalStorageParameters("hdfs://namenode/db", params))
+
+	params = map[string]string{"AWSEndpoint": "https://minio:9000"}
+	assert.ErrorContains(t, validateCommunalStorageParameters("s3://bucket/db", params), "without a scheme")
+	params["AWSEndpoint"] = "minio:9000"
+	assert.NoError(t, validateCommunalStorageParameters("s3://bucket/db", params))
+}
+
+func TestCheckCommunalStorageResult(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	op, err := makeNMACheckCommunalStorageOp([]string{"192.168.1.101", "192.168.1.102"}, "webhdfs://namenode:50070/db",
+		nil, &vdb, false)
+	assert.NoError(t, err)
+	op.setLogger(vlog.Printer{})
+	op.setupBasicInfo()
+
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, statusCode: SuccessCode,
+			content: `{"checks": [{"name": "connectivity", "passed": true, "detail": ""}]}`},
+		"192.168.1.102": {status: SUCCESS, statusCode: SuccessCode,
+			content: `{"checks": [{"name": "kerberos", "passed": false, "detail": "kinit: Keytab contains no suitable keys"}]}`},
+	}
+	err = op.processResult(nil)
+	var checkErr *CommunalStorageCheckError
+	assert.True(t, errors.As(err, &checkErr))
+	assert.Equal(t, "192.168.1.102", checkErr.Host)
+	assert.Equal(t, communalCheckKerberos, checkErr.Check)
+	assert.ErrorContains(t, err, "KerberosKeytabFile")
+
+	// an NMA without the endpoint skips the checks
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: FAILURE, statusCode: NotFoundCode, err: errors.New("404 Not Found")},
+	}
+	assert.NoError(t, op.processResult(nil))
+}
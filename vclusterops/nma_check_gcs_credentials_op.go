@@ -0,0 +1,124 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type nmaCheckGCSCredentialsOp struct {
+	opBase
+	hostRequestBody string
+}
+
+type checkGCSCredentialsRequestData struct {
+	CommunalStorageLocation string `json:"communal_storage"`
+	AuthMethod              string `json:"auth_method"`
+	sensitiveFields
+}
+
+// makeNMACheckGCSCredentialsOp makes an op that checks, on every host, that the
+// GCS credentials of the auth method can access the communal storage: the key
+// of the service account, or the workload identity that the metadata server
+// of the host gives. It runs before the hosts are changed by create_db or
+// revive_db.
+func makeNMACheckGCSCredentialsOp(hosts []string, location, authMethod string,
+	parameters map[string]string) (nmaCheckGCSCredentialsOp, error) {
+	op := nmaCheckGCSCredentialsOp{}
+	op.name = "NMACheckGCSCredentialsOp"
+	op.description = "Check GCS credentials"
+	op.hosts = hosts
+
+	requestData := checkGCSCredentialsRequestData{}
+	requestData.CommunalStorageLocation = location
+	requestData.AuthMethod = authMethod
+	requestData.Parameters = parameters
+	dataBytes, err := json.Marshal(requestData)
+	if err != nil {
+		return op, fmt.Errorf("[%s] fail to marshal request data to JSON string, detail %w", op.name, err)
+	}
+	op.hostRequestBody = string(dataBytes)
+
+	return op, nil
+}
+
+func (op *nmaCheckGCSCredentialsOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildNMAEndpoint("communal-storage/gcs/check")
+		httpRequest.RequestData = op.hostRequestBody
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaCheckGCSCredentialsOp) prepare(execContext *opEngineExecContext) error {
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *nmaCheckGCSCredentialsOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaCheckGCSCredentialsOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *nmaCheckGCSCredentialsOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		// the NMA that does not have the endpoint leaves the check to the bootstrap
+		if result.isNotFound() {
+			op.logger.PrintWarning("[%s] the NMA on host %s cannot check the GCS credentials, skipping the check",
+				op.name, host)
+			continue
+		}
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+			continue
+		}
+
+		// the response looks like
+		// {"checks": [{"name": "gcs_credentials", "passed": false,
+		//              "detail": "the metadata server has no service account for this instance"}]}
+		response := checkCommunalStorageResponse{}
+		err := op.parseAndCheckResponse(host, result.content, &response)
+		if err != nil {
+			allErrs = errors.Join(allErrs, fmt.Errorf("[%s] fail to parse result on host %s, details: %w", op.name, host, err))
+			continue
+		}
+		for _, check := range response.Checks {
+			if !check.Passed {
+				allErrs = errors.Join(allErrs, &CommunalStorageCheckError{Host: host, Check: check.Name, Detail: check.Detail})
+			}
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,147 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type nmaCheckHostOp struct {
+	opBase
+	paths []string
+	ports []int
+	// the preflight result of each host whose NMA responds
+	hostPreflights map[string]*hostPreflight
+	// the time range in which the requests are sent and answered, to compare
+	// the clocks of the hosts with
+	requestStart time.Time
+	requestEnd   time.Time
+}
+
+type hostPreflightRequestData struct {
+	Paths []string `json:"paths"`
+	Ports []int    `json:"ports"`
+}
+
+// hostPreflight is what the NMA reports of a prospective host
+type hostPreflight struct {
+	VerticaVersion string    `json:"vertica_version"`
+	CurrentTime    time.Time `json:"current_time"`
+	Ports          []struct {
+		Port  int  `json:"port"`
+		InUse bool `json:"in_use"`
+	} `json:"ports"`
+	Paths []struct {
+		Path string `json:"path"`
+		// whether the path, or its closest existing parent, is writable by the NMA user
+		Writable  bool   `json:"writable"`
+		FreeBytes uint64 `json:"free_bytes"`
+	} `json:"paths"`
+}
+
+// makeNMACheckHostOp makes an op that asks the NMA of each host for the Vertica
+// version, the time, whether the ports are in use, and the free space and
+// writability of the paths. The hosts whose NMA does not respond are left out of
+// hostPreflights, and do not fail the op.
+func makeNMACheckHostOp(hosts, paths []string, ports []int) nmaCheckHostOp {
+	op := nmaCheckHostOp{}
+	op.name = "NMACheckHostOp"
+	op.description = "Check the prerequisites of hosts"
+	op.hosts = hosts
+	op.paths = paths
+	op.ports = ports
+	op.hostPreflights = make(map[string]*hostPreflight)
+	return op
+}
+
+func (op *nmaCheckHostOp) setupClusterHTTPRequest(hosts []string) error {
+	dataBytes, err := json.Marshal(hostPreflightRequestData{Paths: op.paths, Ports: op.ports})
+	if err != nil {
+		return fmt.Errorf("[%s] fail to marshal request data to JSON string, detail %w", op.name, err)
+	}
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildNMAEndpoint("host/preflight")
+		httpRequest.RequestData = string(dataBytes)
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaCheckHostOp) prepare(execContext *opEngineExecContext) error {
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *nmaCheckHostOp) execute(execContext *opEngineExecContext) error {
+	op.requestStart = time.Now()
+	err := op.runExecute(execContext)
+	op.requestEnd = time.Now()
+	if err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaCheckHostOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *nmaCheckHostOp) processResult(_ *opEngineExecContext) error {
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if !result.isPassing() {
+			continue
+		}
+
+		// the successful response looks like
+		/*
+			{
+			  "vertica_version": "Vertica Analytic Database v24.2.0-0",
+			  "current_time": "2024-03-11T14:12:45.123456Z",
+			  "ports": [{"port": 5433, "in_use": false}, ...],
+			  "paths": [{"path": "/data", "writable": true, "free_bytes": 8215897325568}, ...]
+			}
+		*/
+		preflight := hostPreflight{}
+		err := op.parseAndCheckResponse(host, result.content, &preflight)
+		if err != nil {
+			return fmt.Errorf(`[%s] failed to parse result on host %s, details: %w`, op.name, host, err)
+		}
+		op.hostPreflights[host] = &preflight
+	}
+
+	return nil
+}
+
+// getClockSkew returns how far the time of a host is from the time range in
+// which the requests were sent and answered
+func (op *nmaCheckHostOp) getClockSkew(hostTime time.Time) time.Duration {
+	if hostTime.Before(op.requestStart) {
+		return op.requestStart.Sub(hostTime)
+	}
+	if hostTime.After(op.requestEnd) {
+		return hostTime.Sub(op.requestEnd)
+	}
+	return 0
+}
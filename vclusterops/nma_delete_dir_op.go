@@ -62,16 +62,15 @@ func (op *nmaDeleteDirectoriesOp) buildRequestBody(
 		p := deleteDirParams{}
 
 		// directories
-		p.Directories = append(p.Directories, vnode.CatalogPath)
-		p.Directories = append(p.Directories, vnode.StorageLocations...)
+		p.Directories = getNodeDirectories(vdb, vnode)
 
 		if vdb.UseDepot {
-			dbDepotPath := filepath.Join(vdb.DepotPrefix, vdb.Name)
-			p.Directories = append(p.Directories, vnode.DepotPath, dbDepotPath)
+			dbDepotPath := filepath.Join(vdb.getDepotPrefix(h), vdb.Name)
+			p.Directories = append(p.Directories, dbDepotPath)
 		}
 
-		dbCatalogPath := filepath.Join(vdb.CatalogPrefix, vdb.Name)
-		dbDataPath := filepath.Join(vdb.DataPrefix, vdb.Name)
+		dbCatalogPath := filepath.Join(vdb.getCatalogPrefix(h), vdb.Name)
+		dbDataPath := filepath.Join(vdb.getDataPrefix(h), vdb.Name)
 		p.Directories = append(p.Directories, dbCatalogPath, dbDataPath)
 
 		// force-delete
@@ -90,6 +89,26 @@ func (op *nmaDeleteDirectoriesOp) buildRequestBody(
 	return nil
 }
 
+// getNodeDirectories returns the catalog, data and depot directories of a node
+func getNodeDirectories(vdb *VCoordinationDatabase, vnode *VCoordinationNode) []string {
+	directories := []string{vnode.CatalogPath}
+	directories = append(directories, vnode.StorageLocations...)
+	if vdb.UseDepot {
+		directories = append(directories, vnode.DepotPath)
+	}
+	return directories
+}
+
+// getKeptDirectories returns the directories of each node of a vdb, which are
+// left behind when the directories are not deleted
+func getKeptDirectories(vdb *VCoordinationDatabase) map[string][]string {
+	keptDirectories := make(map[string][]string)
+	for h, vnode := range vdb.HostNodeMap {
+		keptDirectories[h] = getNodeDirectories(vdb, vnode)
+	}
+	return keptDirectories
+}
+
 func (op *nmaDeleteDirectoriesOp) setupClusterHTTPRequest(hosts []string) error {
 	for _, host := range hosts {
 		httpRequest := hostHTTPRequest{}
@@ -0,0 +1,185 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"golang.org/x/exp/maps"
+)
+
+type nmaDepositFileOp struct {
+	opBase
+	destination string
+	// the destination of each host, when the hosts do not have the same destination
+	hostDestinations map[string]string
+	fileContent      string
+	// the sha256 checksum of the content, which the NMA echoes back after
+	// writing the file when verifyChecksum is set
+	checksum       string
+	verifyChecksum bool
+	// when set, the outcome of the deposit is recorded for each host instead
+	// of failing the op
+	hostResults map[string]*FileDepositResult
+}
+
+type depositFileRequestData struct {
+	Destination string `json:"destination"`
+	Content     string `json:"content"`
+	Checksum    string `json:"checksum,omitempty"`
+}
+
+// getContentChecksum returns the hex-encoded sha256 checksum of the content
+func getContentChecksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// makeNMADepositFileOp makes an op that writes the content of a local file to the
+// destination path on the hosts. If no hosts are given, the file is deposited on the
+// first up host found by a previous op.
+func makeNMADepositFileOp(hosts []string, fileContent, destination string) nmaDepositFileOp {
+	op := nmaDepositFileOp{}
+	op.name = "NMADepositFileOp"
+	op.description = fmt.Sprintf("Deposit file to %s", destination)
+	op.hosts = hosts
+	op.fileContent = fileContent
+	op.checksum = getContentChecksum(fileContent)
+	op.destination = destination
+	return op
+}
+
+// makeNMADepositHostFilesOp makes an op that writes the same content to a
+// destination path that depends on the host, such as a file in the catalog
+// directory of each node
+func makeNMADepositHostFilesOp(hostDestinations map[string]string, fileContent, fileName string) nmaDepositFileOp {
+	op := nmaDepositFileOp{}
+	op.name = "NMADepositFileOp"
+	op.description = fmt.Sprintf("Deposit %s to %d host(s)", fileName, len(hostDestinations))
+	op.hosts = maps.Keys(hostDestinations)
+	sort.Strings(op.hosts)
+	op.hostDestinations = hostDestinations
+	op.fileContent = fileContent
+	op.checksum = getContentChecksum(fileContent)
+	return op
+}
+
+func (op *nmaDepositFileOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		requestData := depositFileRequestData{
+			Destination: op.getDestination(host),
+			Content:     op.fileContent,
+		}
+		if op.verifyChecksum {
+			requestData.Checksum = op.checksum
+		}
+		dataBytes, err := json.Marshal(requestData)
+		if err != nil {
+			return fmt.Errorf("[%s] fail to marshal request data to JSON string, detail %w", op.name, err)
+		}
+
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildNMAEndpoint("files/deposit")
+		httpRequest.RequestData = string(dataBytes)
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaDepositFileOp) prepare(execContext *opEngineExecContext) error {
+	if len(op.hosts) == 0 {
+		if len(execContext.upHosts) == 0 {
+			return fmt.Errorf(`[%s] Cannot find any up hosts in OpEngineExecContext`, op.name)
+		}
+		op.hosts = []string{execContext.upHosts[0]}
+	}
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *nmaDepositFileOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaDepositFileOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *nmaDepositFileOp) getDestination(host string) string {
+	if hostDestination, ok := op.hostDestinations[host]; ok {
+		return hostDestination
+	}
+	return op.destination
+}
+
+func (op *nmaDepositFileOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		err := op.checkHostResult(host, result)
+		if op.hostResults != nil {
+			op.hostResults[host] = &FileDepositResult{
+				Host:        host,
+				Destination: op.getDestination(host),
+				Checksum:    op.checksum,
+				Succeeded:   err == nil,
+			}
+			if err != nil {
+				op.hostResults[host].Error = err.Error()
+			}
+			continue
+		}
+		allErrs = errors.Join(allErrs, err)
+	}
+
+	return allErrs
+}
+
+func (op *nmaDepositFileOp) checkHostResult(host string, result hostHTTPResult) error {
+	if !result.isPassing() {
+		return result.err
+	}
+
+	// the response object will be a dictionary including the destination of the file, e.g.,:
+	// {"destination":"/opt/vertica/config/share/license.key"}
+	// when a checksum was sent, the NMA also returns the checksum of the written file
+	responseObj, err := op.parseAndCheckMapResponse(host, result.content)
+	if err != nil {
+		return err
+	}
+	if _, ok := responseObj["destination"]; !ok {
+		return fmt.Errorf(`[%s] response does not contain field "destination"`, op.name)
+	}
+	if op.verifyChecksum && responseObj["checksum"] != op.checksum {
+		return fmt.Errorf("[%s] checksum mismatch on host %s, expected %s but the written file has %q",
+			op.name, host, op.checksum, responseObj["checksum"])
+	}
+	return nil
+}
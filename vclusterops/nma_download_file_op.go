@@ -59,7 +59,9 @@ type downloadFileRequestData struct {
 	CatalogPath         string            `json:"catalog_path,omitempty"`
 	AWSAccessKeyID      string            `json:"aws_access_key_id,omitempty"`
 	AWSSecretAccessKey  string            `json:"aws_secret_access_key,omitempty"`
+	AWSSessionToken     string            `json:"aws_session_token,omitempty"`
 	Parameters          map[string]string `json:"parameters,omitempty"`
+	awsAuthRequestData
 }
 
 // ClusterLeaseNotExpiredError is returned when you attempt to access a
@@ -108,6 +110,10 @@ func makeNMADownloadFileOp(newNodes []string, sourceFilePath, destinationFilePat
 		requestData.DestinationFilePath = destinationFilePath
 		requestData.CatalogPath = catalogPath
 		requestData.Parameters = configurationParameters
+		requestData.AWSAccessKeyID = vdb.AwsIDKey
+		requestData.AWSSecretAccessKey = vdb.AwsSecretKey
+		requestData.AWSSessionToken = vdb.AwsSessionToken
+		requestData.awsAuthRequestData = vdb.getAWSAuthRequestData()
 
 		dataBytes, err := json.Marshal(requestData)
 		if err != nil {
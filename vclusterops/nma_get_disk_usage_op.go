@@ -0,0 +1,177 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"strings"
+)
+
+type nmaGetDiskUsageOp struct {
+	opBase
+	hostsWithNodeDetails hostNodeDetailsMap
+	// the paths to get the disk usage of on every host, and the disk usage
+	// found on each host, when the op does not work on node details
+	paths         []string
+	hostDiskUsage map[string][]PathDiskUsage
+}
+
+// PathDiskUsage is the disk usage of a path and of the filesystem it is on
+type PathDiskUsage struct {
+	Path       string `json:"path"`
+	MountPoint string `json:"mount_point"`
+	// the size of the files under the path
+	PathUsedBytes uint64 `json:"path_used_bytes"`
+	// the size and usage of the filesystem the path is on
+	TotalBytes uint64 `json:"total_bytes"`
+	UsedBytes  uint64 `json:"used_bytes"`
+	FreeBytes  uint64 `json:"free_bytes"`
+	// the inodes of the filesystem the path is on. They are zero if the
+	// filesystem has no fixed number of inodes.
+	TotalInodes uint64 `json:"total_inodes"`
+	UsedInodes  uint64 `json:"used_inodes"`
+	FreeInodes  uint64 `json:"free_inodes"`
+}
+
+type diskUsageResp struct {
+	DiskUsage []PathDiskUsage `json:"disk_usage"`
+}
+
+// makeNMAGetDiskUsageOp makes an op that gets the disk usage of the catalog, data
+// and depot paths of each node from the NMA filesystem endpoint. The paths are
+// taken from the node details that are filled in by HTTPSGetLocalNodeStateOp.
+func makeNMAGetDiskUsageOp(hosts []string, hostsWithNodeDetails hostNodeDetailsMap) nmaGetDiskUsageOp {
+	op := nmaGetDiskUsageOp{}
+	op.name = "NMAGetDiskUsageOp"
+	op.description = "Get disk usage of node paths"
+	op.hosts = hosts
+	op.hostsWithNodeDetails = hostsWithNodeDetails
+	return op
+}
+
+// makeNMAGetPathsDiskUsageOp makes an op that gets the disk usage of the same
+// paths on each host, such as the paths of a database that is not created yet.
+// A path that does not exist is reported with the filesystem of its closest
+// existing parent. The disk usage of each host is filled in hostDiskUsage; a
+// host whose NMA fails is left out of it instead of failing the op.
+func makeNMAGetPathsDiskUsageOp(hosts, paths []string, hostDiskUsage map[string][]PathDiskUsage) nmaGetDiskUsageOp {
+	op := nmaGetDiskUsageOp{}
+	op.name = "NMAGetDiskUsageOp"
+	op.description = "Get disk usage of paths"
+	op.hosts = hosts
+	op.paths = paths
+	op.hostDiskUsage = hostDiskUsage
+	return op
+}
+
+func (op *nmaGetDiskUsageOp) setupClusterHTTPRequest(hostPathsMap map[string][]string) error {
+	for host, paths := range hostPathsMap {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.buildNMAEndpoint("filesystem/usage")
+		httpRequest.QueryParams = map[string]string{"paths": strings.Join(paths, ",")}
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaGetDiskUsageOp) prepare(execContext *opEngineExecContext) error {
+	hostPathsMap := make(map[string][]string, len(op.hosts))
+	for _, host := range op.hosts {
+		if op.hostsWithNodeDetails == nil {
+			hostPathsMap[host] = op.paths
+			continue
+		}
+		nodeDetails, ok := op.hostsWithNodeDetails[host]
+		if !ok {
+			// this is a programming error, the host should've been added to the map in HTTPSGetLocalNodeStateOp
+			return fmt.Errorf(`[%s] cannot find node details of host %s`, op.name, host)
+		}
+		paths := []string{nodeDetails.CatalogPath}
+		paths = append(paths, nodeDetails.DataPath...)
+		if nodeDetails.DepotPath != "" {
+			paths = append(paths, nodeDetails.DepotPath)
+		}
+		hostPathsMap[host] = paths
+	}
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(hostPathsMap)
+}
+
+func (op *nmaGetDiskUsageOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaGetDiskUsageOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *nmaGetDiskUsageOp) processResult(_ *opEngineExecContext) error {
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if !result.isPassing() {
+			if op.hostsWithNodeDetails == nil {
+				continue
+			}
+			return result.err
+		}
+
+		// the successful response looks like
+		/*
+			{
+			  "disk_usage": [
+				{
+				  "path": "/data/test_db/v_test_db_node0001_depot",
+				  "mount_point": "/data",
+				  "path_used_bytes": 4929538395340,
+				  "total_bytes": 13693162209280,
+				  "used_bytes": 5477264883712,
+				  "free_bytes": 8215897325568,
+				  "total_inodes": 851968000,
+				  "used_inodes": 1523004,
+				  "free_inodes": 850444996
+				},
+				...
+			  ]
+			}
+		*/
+		usage := diskUsageResp{}
+		err := op.parseAndCheckResponse(host, result.content, &usage)
+		if err != nil {
+			return fmt.Errorf(`[%s] failed to parse result on host %s, details: %w`, op.name, host, err)
+		}
+
+		if op.hostsWithNodeDetails == nil {
+			op.hostDiskUsage[host] = usage.DiskUsage
+			continue
+		}
+		nodeDetails, ok := op.hostsWithNodeDetails[host]
+		if !ok {
+			return fmt.Errorf(`[%s] found an unexpected host %s`, op.name, host)
+		}
+		nodeDetails.DiskUsage = usage.DiskUsage
+		nodeDetails.setDepotUsagePercent()
+	}
+
+	return nil
+}
@@ -15,10 +15,6 @@
 
 package vclusterops
 
-import (
-	"fmt"
-)
-
 // nodes being down is not unusual for the purpose of this op.
 // don't block 6 minutes because of one down node.
 const healthRequestTimeoutSeconds = 20
@@ -87,7 +83,7 @@ func (op *nmaGetHealthyNodesOp) processResult(_ *opEngineExecContext) error {
 		}
 	}
 	if len(op.vdb.HostList) == 0 {
-		return fmt.Errorf("NMA is down or unresponsive on all hosts")
+		return &PreconditionError{Detail: "NMA is down or unresponsive on all hosts"}
 	}
 
 	return nil
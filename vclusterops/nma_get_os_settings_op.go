@@ -0,0 +1,111 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+)
+
+type nmaGetOSSettingsOp struct {
+	opBase
+	// the OS settings of each host, filled in once the op completes. A host
+	// whose NMA fails is left out.
+	hostOSSettings map[string]*hostOSSettings
+}
+
+// hostOSSettings are the kernel and OS settings of a host that Vertica depends on
+type hostOSSettings struct {
+	// the soft limits of the user that runs the NMA
+	OpenFilesLimit   uint64 `json:"open_files_limit"`
+	MaxUserProcesses uint64 `json:"max_user_processes"`
+	// the value of vm.swappiness
+	Swappiness int `json:"vm_swappiness"`
+	// the mode of transparent hugepages: always, madvise or never
+	TransparentHugepages string `json:"transparent_hugepages"`
+	// whether the clock is synchronized by NTP or chrony
+	NTPSynchronized bool `json:"ntp_synchronized"`
+}
+
+// makeNMAGetOSSettingsOp makes an op that gets the kernel and OS settings of
+// each host. The op does not fail when the NMA of a host fails, so that the
+// other checks of the hosts are still reported.
+func makeNMAGetOSSettingsOp(hosts []string, hostOSSettings map[string]*hostOSSettings) nmaGetOSSettingsOp {
+	op := nmaGetOSSettingsOp{}
+	op.name = "NMAGetOSSettingsOp"
+	op.description = "Get OS settings"
+	op.hosts = hosts
+	op.hostOSSettings = hostOSSettings
+	return op
+}
+
+func (op *nmaGetOSSettingsOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.buildNMAEndpoint("host/os-settings")
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaGetOSSettingsOp) prepare(execContext *opEngineExecContext) error {
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *nmaGetOSSettingsOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaGetOSSettingsOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *nmaGetOSSettingsOp) processResult(_ *opEngineExecContext) error {
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if !result.isPassing() {
+			op.logger.Info("fail to get the OS settings of host", "host", host, "details", result.err)
+			continue
+		}
+
+		// the successful response looks like
+		/*
+			{
+			  "open_files_limit": 65536,
+			  "max_user_processes": 127812,
+			  "vm_swappiness": 1,
+			  "transparent_hugepages": "always",
+			  "ntp_synchronized": true
+			}
+		*/
+		settings := hostOSSettings{}
+		err := op.parseAndCheckResponse(host, result.content, &settings)
+		if err != nil {
+			return fmt.Errorf(`[%s] failed to parse result on host %s, details: %w`, op.name, host, err)
+		}
+		op.hostOSSettings[host] = &settings
+	}
+
+	return nil
+}
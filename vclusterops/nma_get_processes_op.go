@@ -0,0 +1,140 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"strings"
+)
+
+// names of the database processes that the NMA lists, along with the NMA itself
+const (
+	processNameVertica = "vertica"
+	processNameSpread  = "spread"
+)
+
+type nmaGetProcessesOp struct {
+	opBase
+	// the processes found on each host, filled in once the op completes. A host
+	// whose NMA fails is left out.
+	hostProcesses map[string][]processInfo
+}
+
+// processInfo is a vertica, spread or NMA process running on a host
+type processInfo struct {
+	Name      string `json:"name"`
+	PID       int    `json:"pid"`
+	StartTime string `json:"start_time"`
+	Command   string `json:"command"`
+}
+
+func (p *processInfo) String() string {
+	return fmt.Sprintf("%s (pid %d, started at %s)", p.Name, p.PID, p.StartTime)
+}
+
+type processesResp struct {
+	Processes []processInfo `json:"processes"`
+}
+
+// makeNMAGetProcessesOp makes an op that lists the vertica, spread and NMA processes
+// running on the hosts. The op does not fail when the NMA of a host fails, as the
+// processes are only used to warn about stray processes.
+func makeNMAGetProcessesOp(hosts []string, hostProcesses map[string][]processInfo) nmaGetProcessesOp {
+	op := nmaGetProcessesOp{}
+	op.name = "NMAGetProcessesOp"
+	op.description = "Get running processes"
+	op.hosts = hosts
+	op.hostProcesses = hostProcesses
+	return op
+}
+
+func (op *nmaGetProcessesOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.buildNMAEndpoint("processes")
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaGetProcessesOp) prepare(execContext *opEngineExecContext) error {
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *nmaGetProcessesOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaGetProcessesOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *nmaGetProcessesOp) processResult(_ *opEngineExecContext) error {
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if !result.isPassing() {
+			op.logger.Info("fail to get the processes of host", "host", host, "details", result.err)
+			continue
+		}
+
+		// the successful response looks like
+		/*
+			{
+			  "processes": [
+				{
+				  "name": "vertica",
+				  "pid": 12345,
+				  "start_time": "2024-03-01T10:12:33Z",
+				  "command": "/opt/vertica/bin/vertica -D /data/test_db/v_test_db_node0001_catalog ..."
+				},
+				...
+			  ]
+			}
+		*/
+		processes := processesResp{}
+		err := op.parseAndCheckResponse(host, result.content, &processes)
+		if err != nil {
+			return fmt.Errorf(`[%s] failed to parse result on host %s, details: %w`, op.name, host, err)
+		}
+		op.hostProcesses[host] = processes.Processes
+	}
+
+	return nil
+}
+
+// getStrayProcesses returns the vertica and spread processes running on each host,
+// which are stray when the database is not running
+func getStrayProcesses(hostProcesses map[string][]processInfo) map[string][]string {
+	strayProcesses := make(map[string][]string)
+	for host, processes := range hostProcesses {
+		for i := range processes {
+			name := strings.ToLower(processes[i].Name)
+			if name == processNameVertica || name == processNameSpread {
+				strayProcesses[host] = append(strayProcesses[host], processes[i].String())
+			}
+		}
+	}
+	return strayProcesses
+}
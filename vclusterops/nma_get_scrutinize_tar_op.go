@@ -19,13 +19,29 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 
+	"github.com/vertica/vcluster/vclusterops/metrics"
 	"github.com/vertica/vcluster/vclusterops/util"
+	"golang.org/x/exp/maps"
 )
 
+// the number of times to retry downloading a tarball that fails checksum verification
+const scrutinizeTarMaxRetries = 3
+
 type nmaGetScrutinizeTarOp struct {
 	scrutinizeOpBase
 	useInitiator bool
+	// if set, the downloaded tarballs are recorded so a rerun can skip them
+	progress *scrutinizeProgress
+	// the compression of the tarball, gzip by default
+	compression string
+	// host -> path of the downloaded tarball
+	hostFilePathMap map[string]string
+	// the max number of tarballs downloaded at the same time, no limit if 0
+	concurrency int
+	// the bandwidth limit of each download, no limit if 0
+	bytesPerSecond int64
 }
 
 func makeNMAGetScrutinizeTarOp(
@@ -43,6 +59,7 @@ func makeNMAGetScrutinizeTarOp(
 	op.batch = batch
 	op.hostNodeNameMap = hostNodeNameMap
 	op.httpMethod = GetMethod
+	op.compression = ScrutinizeCompressionGzip
 
 	// the caller is responsible for making sure hosts and maps match up exactly
 	err := validateHostMaps(hosts, hostNodeNameMap)
@@ -60,6 +77,23 @@ func (op *nmaGetScrutinizeTarOp) useSingleHost() {
 	op.useInitiator = true
 }
 
+// setProgress records the downloaded tarballs in the progress of the scrutinize run
+func (op *nmaGetScrutinizeTarOp) setProgress(progress *scrutinizeProgress) {
+	op.progress = progress
+}
+
+// setCompression sets the codec the NMA compresses the tarball with
+func (op *nmaGetScrutinizeTarOp) setCompression(compression string) {
+	op.compression = compression
+}
+
+// setDownloadLimits limits the number of tarballs downloaded at the same time
+// and the bandwidth of each download. 0 means no limit.
+func (op *nmaGetScrutinizeTarOp) setDownloadLimits(concurrency int, bytesPerSecond int64) {
+	op.concurrency = concurrency
+	op.bytesPerSecond = bytesPerSecond
+}
+
 // createOutputDir creates a subdirectory {id} under /tmp/scrutinize/remote, which
 // may also be created by this function.  the "remote" subdirectory is created to
 // separate local scrutinize data staged by the NMA (placed in /tmp/scrutinize/) from
@@ -101,25 +135,113 @@ func (op *nmaGetScrutinizeTarOp) prepare(execContext *opEngineExecContext) error
 
 	hostToFilePathsMap := map[string]string{}
 	for _, host := range op.hosts {
-		hostToFilePathsMap[host] = fmt.Sprintf("%s/%s/%s-%s.tgz",
+		hostToFilePathsMap[host] = fmt.Sprintf("%s/%s/%s-%s%s",
 			scrutinizeRemoteOutputPath,
 			op.id,
 			op.hostNodeNameMap[host],
-			op.batch)
+			op.batch,
+			scrutinizeTarballExtension(op.compression))
 	}
-	execContext.dispatcher.setupForDownload(op.hosts, hostToFilePathsMap)
+	op.hostFilePathMap = hostToFilePathsMap
+	options := downloadOptions{bytesPerSecond: op.bytesPerSecond}
+	if op.tracker != nil {
+		options.onProgress = func(host string, bytes int64) {
+			op.tracker.report(host, op.batch, ProgressStageDownloading, bytes)
+		}
+	}
+	execContext.dispatcher.setupForDownload(op.hosts, hostToFilePathsMap, options)
 
 	return op.setupClusterHTTPRequest(op.hosts)
 }
 
+func (op *nmaGetScrutinizeTarOp) setupClusterHTTPRequest(hosts []string) error {
+	err := op.scrutinizeOpBase.setupClusterHTTPRequest(hosts)
+	if err != nil {
+		return err
+	}
+	// gzip is the default of the NMA, so only ask for other codecs
+	if op.compression != ScrutinizeCompressionGzip {
+		for host, httpRequest := range op.clusterHTTPRequest.RequestCollection {
+			httpRequest.QueryParams = map[string]string{"compression": op.compression}
+			op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+		}
+	}
+	return nil
+}
+
 func (op *nmaGetScrutinizeTarOp) execute(execContext *opEngineExecContext) error {
-	if err := op.runExecute(execContext); err != nil {
+	// the NMA tars the batch before streaming it back
+	for host := range op.clusterHTTPRequest.RequestCollection {
+		op.tracker.report(host, op.batch, ProgressStageTarring, 0)
+	}
+	if err := op.runLimitedExecute(execContext); err != nil {
 		return err
 	}
 
+	// download the tarballs corrupted in transfer again
+	allRequests := op.clusterHTTPRequest.RequestCollection
+	allResults := op.clusterHTTPRequest.ResultCollection
+	for attempt := 1; attempt <= scrutinizeTarMaxRetries; attempt++ {
+		retryRequests := make(map[string]hostHTTPRequest)
+		for host, result := range allResults {
+			if errors.Is(result.err, errChecksumMismatch) {
+				retryRequests[host] = allRequests[host]
+			}
+		}
+		if len(retryRequests) == 0 {
+			break
+		}
+		metrics.OpRetries.Inc(op.name)
+		op.logger.PrintWarning("Tarball of batch %s from hosts %v failed checksum verification, retrying (%d/%d)",
+			op.batch, maps.Keys(retryRequests), attempt, scrutinizeTarMaxRetries)
+		op.clusterHTTPRequest.RequestCollection = retryRequests
+		if err := op.runLimitedExecute(execContext); err != nil {
+			return err
+		}
+		for host, result := range op.clusterHTTPRequest.ResultCollection {
+			allResults[host] = result
+		}
+	}
+	op.clusterHTTPRequest.RequestCollection = allRequests
+	op.clusterHTTPRequest.ResultCollection = allResults
+
 	return op.processResult(execContext)
 }
 
+// runLimitedExecute sends the requests in groups of at most op.concurrency hosts,
+// so that no more than that many tarballs are downloaded at the same time
+func (op *nmaGetScrutinizeTarOp) runLimitedExecute(execContext *opEngineExecContext) error {
+	allRequests := op.clusterHTTPRequest.RequestCollection
+	if op.concurrency <= 0 || len(allRequests) <= op.concurrency {
+		return op.runExecute(execContext)
+	}
+
+	hosts := maps.Keys(allRequests)
+	sort.Strings(hosts)
+	allResults := make(map[string]hostHTTPResult, len(allRequests))
+	for start := 0; start < len(hosts); start += op.concurrency {
+		end := start + op.concurrency
+		if end > len(hosts) {
+			end = len(hosts)
+		}
+		groupRequests := make(map[string]hostHTTPRequest, end-start)
+		for _, host := range hosts[start:end] {
+			groupRequests[host] = allRequests[host]
+		}
+		op.clusterHTTPRequest.RequestCollection = groupRequests
+		if err := op.runExecute(execContext); err != nil {
+			op.clusterHTTPRequest.RequestCollection = allRequests
+			return err
+		}
+		for host, result := range op.clusterHTTPRequest.ResultCollection {
+			allResults[host] = result
+		}
+	}
+	op.clusterHTTPRequest.RequestCollection = allRequests
+	op.clusterHTTPRequest.ResultCollection = allResults
+	return nil
+}
+
 func (op *nmaGetScrutinizeTarOp) finalize(_ *opEngineExecContext) error {
 	return nil
 }
@@ -135,7 +257,15 @@ func (op *nmaGetScrutinizeTarOp) processResult(_ *opEngineExecContext) error {
 				"Host", host,
 				"Node", op.hostNodeNameMap[host],
 				"Batch", op.batch)
+			op.tracker.report(host, op.batch, ProgressStageDone, op.downloadedBytes(host))
+			if op.progress != nil {
+				if err := op.progress.markDownloaded(op.batch, host); err != nil {
+					op.logger.PrintWarning("Failed to record the progress of batch %s on host %s: %s",
+						op.batch, host, err.Error())
+				}
+			}
 		} else {
+			op.tracker.report(host, op.batch, ProgressStageFailed, 0)
 			op.logger.Error(result.err, "Failed to retrieve tarball",
 				"Host", host,
 				"Node", op.hostNodeNameMap[host],
@@ -152,3 +282,12 @@ func (op *nmaGetScrutinizeTarOp) processResult(_ *opEngineExecContext) error {
 
 	return allErrs
 }
+
+// downloadedBytes returns the size of the tarball downloaded from a host
+func (op *nmaGetScrutinizeTarOp) downloadedBytes(host string) int64 {
+	info, err := os.Stat(op.hostFilePathMap[host])
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
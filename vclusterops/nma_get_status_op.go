@@ -0,0 +1,131 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+)
+
+const nmaVersionEndpoint = "version"
+
+// nmaGetStatusOp gets the health or the version of the NMA on each host. The
+// hosts whose NMA does not respond are recorded in the statuses, and do not
+// fail the op, so that the NMA of all hosts can be reported.
+type nmaGetStatusOp struct {
+	opBase
+	endpoint string
+	// the status of the NMA of each host, filled in by the op
+	hostStatuses map[string]*NMAStatus
+}
+
+// makeNMAGetHealthStatusOp makes an op that records whether the NMA of each host
+// is reachable and healthy
+func makeNMAGetHealthStatusOp(hosts []string, hostStatuses map[string]*NMAStatus) nmaGetStatusOp {
+	op := nmaGetStatusOp{}
+	op.name = "NMAGetHealthStatusOp"
+	op.description = "Get NMA health"
+	op.hosts = hosts
+	op.endpoint = "health"
+	op.hostStatuses = hostStatuses
+	return op
+}
+
+// makeNMAGetVersionStatusOp makes an op that records the version of the NMA of
+// each host. It is only sent to the hosts whose NMA is healthy.
+func makeNMAGetVersionStatusOp(hostStatuses map[string]*NMAStatus) nmaGetStatusOp {
+	op := nmaGetStatusOp{}
+	op.name = "NMAGetVersionStatusOp"
+	op.description = "Get NMA version"
+	op.endpoint = nmaVersionEndpoint
+	op.hostStatuses = hostStatuses
+	return op
+}
+
+func (op *nmaGetStatusOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.buildNMAEndpoint(op.endpoint)
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaGetStatusOp) prepare(execContext *opEngineExecContext) error {
+	// the version is only asked to the healthy NMAs
+	if op.endpoint == nmaVersionEndpoint {
+		op.hosts = nil
+		for host, status := range op.hostStatuses {
+			if status.Healthy {
+				op.hosts = append(op.hosts, host)
+			}
+		}
+		if len(op.hosts) == 0 {
+			op.skipExecute = true
+			return nil
+		}
+	}
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *nmaGetStatusOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaGetStatusOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *nmaGetStatusOp) processResult(_ *opEngineExecContext) error {
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		status, ok := op.hostStatuses[host]
+		if !ok {
+			status = &NMAStatus{Host: host}
+			op.hostStatuses[host] = status
+		}
+		if !result.isPassing() {
+			status.Error = result.err.Error()
+			continue
+		}
+
+		// the successful responses look like {"healthy": "true"} and {"nma_version": "v24.2.0"}
+		response, err := op.parseAndCheckMapResponse(host, result.content)
+		if err != nil {
+			status.Error = fmt.Sprintf("[%s] fail to parse result on host %s, details: %v", op.name, host, err)
+			continue
+		}
+		if op.endpoint == nmaVersionEndpoint {
+			status.Version = response["nma_version"]
+			continue
+		}
+		status.Reachable = true
+		status.Healthy = response["healthy"] == "true"
+		if !status.Healthy {
+			status.Error = "NMA is not healthy"
+		}
+	}
+
+	return nil
+}
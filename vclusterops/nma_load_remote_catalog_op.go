@@ -41,11 +41,13 @@ type loadRemoteCatalogRequestData struct {
 	NodeName            string              `json:"node_name"`
 	AWSAccessKeyID      string              `json:"aws_access_key_id,omitempty"`
 	AWSSecretAccessKey  string              `json:"aws_secret_access_key,omitempty"`
+	AWSSessionToken     string              `json:"aws_session_token,omitempty"`
 	NodeAddresses       map[string][]string `json:"node_addresses"`
 	Parameters          map[string]string   `json:"parameters,omitempty"`
 	RestorePointArchive string              `json:"restore_point_archive,omitempty"`
 	RestorePointIndex   int                 `json:"restore_point_index,omitempty"`
 	RestorePointID      string              `json:"restore_point_id,omitempty"`
+	awsAuthRequestData
 }
 
 func makeNMALoadRemoteCatalogOp(oldHosts []string, configurationParameters map[string]string,
@@ -101,6 +103,10 @@ func (op *nmaLoadRemoteCatalogOp) setupRequestBody(execContext *opEngineExecCont
 		requestData.StorageLocations = vNode.StorageLocations
 		requestData.NodeAddresses = nodeAddresses
 		requestData.Parameters = op.configurationParameters
+		requestData.AWSAccessKeyID = op.vdb.AwsIDKey
+		requestData.AWSSecretAccessKey = op.vdb.AwsSecretKey
+		requestData.AWSSessionToken = op.vdb.AwsSessionToken
+		requestData.awsAuthRequestData = op.vdb.getAWSAuthRequestData()
 		if op.restorePoint != nil {
 			requestData.RestorePointArchive = op.restorePoint.Archive
 			requestData.RestorePointIndex = op.restorePoint.Index
@@ -187,7 +193,8 @@ func (op *nmaLoadRemoteCatalogOp) processResult(_ *opEngineExecContext) error {
 
 	// quorum check
 	if !op.hasQuorum(successPrimaryNodeCount, op.primaryNodeCount) {
-		err := fmt.Errorf("[%s] fail to load catalog on enough primary nodes. Success count: %d", op.name, successPrimaryNodeCount)
+		err := &QuorumError{Detail: fmt.Sprintf("[%s] fail to load catalog on enough primary nodes. Success count: %d",
+			op.name, successPrimaryNodeCount)}
 		op.logger.Error(err, "fail to load catalog, detail")
 		allErrs = errors.Join(allErrs, err)
 		return allErrs
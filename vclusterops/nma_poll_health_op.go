@@ -0,0 +1,97 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+)
+
+type nmaPollHealthOp struct {
+	opBase
+	timeout int
+}
+
+// makeNMAPollHealthOp makes an op that polls the NMA health endpoint on the given
+// hosts until all of them respond. It is used to wait for an NMA to come back
+// after its certs are replaced.
+func makeNMAPollHealthOp(hosts []string, timeout int) nmaPollHealthOp {
+	op := nmaPollHealthOp{}
+	op.name = "NMAPollHealthOp"
+	op.description = "Wait for NMA service to be healthy"
+	op.hosts = hosts
+	op.timeout = timeout
+	return op
+}
+
+func (op *nmaPollHealthOp) getPollingTimeout() int {
+	return op.timeout
+}
+
+func (op *nmaPollHealthOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.Timeout = defaultHTTPRequestTimeoutSeconds
+		httpRequest.buildNMAEndpoint("health")
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaPollHealthOp) prepare(execContext *opEngineExecContext) error {
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *nmaPollHealthOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaPollHealthOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *nmaPollHealthOp) processResult(execContext *opEngineExecContext) error {
+	err := pollState(op, execContext)
+	if err != nil {
+		return fmt.Errorf("NMA service is not healthy on hosts %v, %w", op.hosts, err)
+	}
+
+	return nil
+}
+
+func (op *nmaPollHealthOp) shouldStopPolling() (bool, error) {
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		// the NMA may still be restarting, or still be serving the previous certs
+		if !result.isPassing() {
+			return false, nil
+		}
+		_, err := op.parseAndCheckMapResponse(host, result.content)
+		if err != nil {
+			return true, err
+		}
+	}
+
+	return len(op.clusterHTTPRequest.ResultCollection) == len(op.hosts), nil
+}
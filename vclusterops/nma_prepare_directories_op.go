@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/vertica/vcluster/rfc7807"
 	"golang.org/x/exp/maps"
 )
 
@@ -28,6 +29,8 @@ type nmaPrepareDirectoriesOp struct {
 	hostRequestBodyMap map[string]string
 	forceCleanup       bool
 	forRevive          bool
+	// whether the op failed only because the directories already exist
+	failedOnExistingDirs bool
 }
 
 type prepareDirectoriesRequestData struct {
@@ -114,6 +117,7 @@ func (op *nmaPrepareDirectoriesOp) finalize(_ *opEngineExecContext) error {
 
 func (op *nmaPrepareDirectoriesOp) processResult(_ *opEngineExecContext) error {
 	var allErrs error
+	existingDirs, otherFailures := false, false
 
 	for host, result := range op.clusterHTTPRequest.ResultCollection {
 		op.logResponse(host, result)
@@ -127,12 +131,26 @@ func (op *nmaPrepareDirectoriesOp) processResult(_ *opEngineExecContext) error {
 			//  '/opt/vertica/config/logrotate': 'created'}
 			_, err := op.parseAndCheckMapResponse(host, result.content)
 			if err != nil {
+				otherFailures = true
 				allErrs = errors.Join(allErrs, err)
 			}
 		} else {
+			if isDirectoryExistError(result.err) {
+				existingDirs = true
+			} else {
+				otherFailures = true
+			}
 			allErrs = errors.Join(allErrs, result.err)
 		}
 	}
+	op.failedOnExistingDirs = existingDirs && !otherFailures
 
 	return allErrs
 }
+
+// isDirectoryExistError returns true if the NMA did not prepare the directories
+// of a host because they already exist
+func isDirectoryExistError(err error) bool {
+	problem := &rfc7807.VProblem{}
+	return errors.As(err, &problem) && problem.ProblemID == rfc7807.CreateDirectoryExistError
+}
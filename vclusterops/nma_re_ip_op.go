@@ -218,7 +218,8 @@ func (op *nmaReIPOp) prepare(execContext *opEngineExecContext) error {
 
 	// quorum check
 	if !op.hasQuorum(uint(len(op.hosts)), op.primaryNodeCount) {
-		return fmt.Errorf("failed quorum check, not enough primaries exist with: %d", len(op.hosts))
+		return &QuorumError{Detail: fmt.Sprintf("[%s] failed quorum check, not enough primaries exist with: %d",
+			op.name, len(op.hosts))}
 	}
 
 	// update re-ip list
@@ -288,7 +289,8 @@ func (op *nmaReIPOp) processResult(_ *opEngineExecContext) error {
 	// quorum check
 	if !op.hasQuorum(successCount, op.primaryNodeCount) {
 		// VER-88054 rollback the commits
-		err := fmt.Errorf("failed quroum check for re-ip update. Success count: %d", successCount)
+		err := &QuorumError{Detail: fmt.Sprintf("[%s] failed quorum check for re-ip update. Success count: %d",
+			op.name, successCount)}
 		allErrs = errors.Join(allErrs, err)
 	}
 
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 
 	"golang.org/x/exp/maps"
 )
@@ -28,6 +29,15 @@ type nmaReadCatalogEditorOp struct {
 	initiator      []string // used when creating new nodes
 	vdb            *VCoordinationDatabase
 	catalogPathMap map[string]string
+	// the global catalog version read from each host
+	hostGlobalVersions map[string]int64
+	// the catalog read from each host
+	hostCatalogs map[string]nmaVDatabase
+	// only report the hosts with a stale catalog, skipping the hosts that
+	// fail, and keep the catalog of the exec context
+	reportStaleHosts bool
+	// the hosts whose catalog is older than the latest catalog read
+	staleHosts []string
 }
 
 // makeNMAReadCatalogEditorOpWithInitiator creates an op to read catalog editor info.
@@ -165,10 +175,17 @@ type nmaVDatabase struct {
 }
 
 func (op *nmaReadCatalogEditorOp) processResult(execContext *opEngineExecContext) error {
+	if op.reportStaleHosts {
+		op.processStaleHostsResult()
+		return nil
+	}
+
 	var allErrs error
 	var hostsWithLatestCatalog []string
 	var maxGlobalVersion int64
 	var latestNmaVDB nmaVDatabase
+	op.hostGlobalVersions = make(map[string]int64)
+	op.hostCatalogs = make(map[string]nmaVDatabase)
 	for host, result := range op.clusterHTTPRequest.ResultCollection {
 		op.logResponse(host, result)
 
@@ -203,6 +220,8 @@ func (op *nmaReadCatalogEditorOp) processResult(execContext *opEngineExecContext
 				allErrs = errors.Join(allErrs, err)
 				continue
 			}
+			op.hostGlobalVersions[host] = globalVersion
+			op.hostCatalogs[host] = nmaVDB
 			if globalVersion > maxGlobalVersion {
 				hostsWithLatestCatalog = []string{host}
 				maxGlobalVersion = globalVersion
@@ -229,3 +248,45 @@ func (op *nmaReadCatalogEditorOp) processResult(execContext *opEngineExecContext
 
 	return allErrs
 }
+
+// processStaleHostsResult finds the hosts whose catalog is older than the latest
+// catalog read. The hosts that fail to return their catalog are skipped.
+func (op *nmaReadCatalogEditorOp) processStaleHostsResult() {
+	var maxGlobalVersion int64
+	op.hostGlobalVersions = make(map[string]int64)
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if !result.isPassing() {
+			op.logger.PrintWarning("[%s] fail to read the catalog on host %s, details: %v", op.name, host, result.err)
+			continue
+		}
+		nmaVDB := nmaVDatabase{}
+		err := op.parseAndCheckResponse(host, result.content, &nmaVDB)
+		if err != nil {
+			op.logger.PrintWarning("[%s] fail to parse the catalog on host %s, details: %v", op.name, host, err)
+			continue
+		}
+		globalVersion, err := nmaVDB.Versions.Global.Int64()
+		if err != nil {
+			op.logger.PrintWarning("[%s] fail to convert the global version of host %s, details: %v", op.name, host, err)
+			continue
+		}
+		op.hostGlobalVersions[host] = globalVersion
+		if globalVersion > maxGlobalVersion {
+			maxGlobalVersion = globalVersion
+		}
+	}
+
+	op.staleHosts = nil
+	for host, globalVersion := range op.hostGlobalVersions {
+		if globalVersion < maxGlobalVersion {
+			op.staleHosts = append(op.staleHosts, host)
+		}
+	}
+	sort.Strings(op.staleHosts)
+	if len(op.staleHosts) > 0 {
+		op.logger.PrintInfo("[%s] hosts %v have a stale catalog, they recover the latest catalog from the up nodes once they start",
+			op.name, op.staleHosts)
+	}
+}
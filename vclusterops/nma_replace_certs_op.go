@@ -0,0 +1,143 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// the actions that can be taken on the certs of a service during rotation
+const (
+	// install the new certs and keep the previous ones
+	certsActionReplace = "replace"
+	// restore the previous certs
+	certsActionRollback = "rollback"
+	// discard the previous certs once the new ones are validated
+	certsActionDiscard = "discard"
+)
+
+type nmaReplaceCertsOp struct {
+	opBase
+	action   string
+	newCerts *httpsCerts
+}
+
+type nmaReplaceCertsPayload struct {
+	Key    string `json:"key"`
+	Cert   string `json:"cert"`
+	CaCert string `json:"ca_cert"`
+}
+
+// makeNMAReplaceCertsOp makes an op that installs new TLS certs on the NMA
+// of the given hosts. The NMA keeps its previous certs and restarts its
+// listener with the new ones.
+func makeNMAReplaceCertsOp(hosts []string, newCerts *httpsCerts) nmaReplaceCertsOp {
+	op := nmaReplaceCertsOp{}
+	op.name = "NMAReplaceCertsOp"
+	op.description = "Replace NMA certificates"
+	op.hosts = hosts
+	op.action = certsActionReplace
+	op.newCerts = newCerts
+	return op
+}
+
+// makeNMARollbackCertsOp makes an op that restores the previous TLS certs
+// on the NMA of the given hosts
+func makeNMARollbackCertsOp(hosts []string) nmaReplaceCertsOp {
+	op := nmaReplaceCertsOp{}
+	op.name = "NMARollbackCertsOp"
+	op.description = "Restore previous NMA certificates"
+	op.hosts = hosts
+	op.action = certsActionRollback
+	return op
+}
+
+// makeNMADiscardCertsOp makes an op that discards the previous TLS certs
+// on the NMA of the given hosts
+func makeNMADiscardCertsOp(hosts []string) nmaReplaceCertsOp {
+	op := nmaReplaceCertsOp{}
+	op.name = "NMADiscardCertsOp"
+	op.description = "Discard previous NMA certificates"
+	op.hosts = hosts
+	op.action = certsActionDiscard
+	return op
+}
+
+func (op *nmaReplaceCertsOp) setupRequestBody() (string, error) {
+	if op.action != certsActionReplace {
+		return "", nil
+	}
+	// never write the payload to a log or error message, it contains the private key
+	payload := nmaReplaceCertsPayload{
+		Key:    op.newCerts.key,
+		Cert:   op.newCerts.cert,
+		CaCert: op.newCerts.caCert,
+	}
+	dataBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("[%s] fail to marshal payload data into JSON string, detail %w", op.name, err)
+	}
+	return string(dataBytes), nil
+}
+
+func (op *nmaReplaceCertsOp) setupClusterHTTPRequest(hosts []string, requestBody string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildNMAEndpoint("certs/" + op.action)
+		httpRequest.RequestData = requestBody
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaReplaceCertsOp) prepare(execContext *opEngineExecContext) error {
+	requestBody, err := op.setupRequestBody()
+	if err != nil {
+		return err
+	}
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts, requestBody)
+}
+
+func (op *nmaReplaceCertsOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaReplaceCertsOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *nmaReplaceCertsOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+		}
+	}
+
+	return allErrs
+}
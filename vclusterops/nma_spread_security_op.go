@@ -29,6 +29,8 @@ type nmaSpreadSecurityOp struct {
 	opBase
 	catalogPathMap map[string]string
 	keyType        string
+	// the key ID and the key that are set, generated by the op if empty
+	securityDetails string
 }
 
 type nmaSpreadSecurityPayload struct {
@@ -36,7 +38,13 @@ type nmaSpreadSecurityPayload struct {
 	SpreadSecurityDetails string `json:"spread_security_details"`
 }
 
-const spreadKeyTypeVertica = "vertica"
+const (
+	spreadKeyTypeVertica = "vertica"
+	// the size in bytes of a key of the vertica type
+	spreadKeySize = 32
+	// the size in bytes of a key ID
+	spreadKeyIDSize = 2
+)
 
 // makeNMASpreadSecurityOp will create the op to set or rotate the key for
 // spread encryption.
@@ -167,32 +175,44 @@ func (op *nmaSpreadSecurityOp) setRuntimeParms(execContext *opEngineExecContext)
 }
 
 func (op *nmaSpreadSecurityOp) generateSecurityDetails() (string, error) {
-	keyID, err := op.generateKeyID()
-	if err != nil {
-		return "", err
+	// the details can be given, such as by create_db to use the key of the bootstrap
+	if op.securityDetails != "" {
+		return op.securityDetails, nil
 	}
-
-	var spreadKey string
 	switch op.keyType {
 	case spreadKeyTypeVertica:
-		spreadKey, err = op.generateVerticaSpreadKey()
-		if err != nil {
-			return "", err
-		}
+		return makeVerticaSpreadSecurityDetails("")
 	default:
 		// Note, there is another key type that we support in the server
 		// (aws-kms). But we haven't yet added support for that here.
 		// VER-89659 is opened to address that.
 		return "", fmt.Errorf("unsupported spread key type %s", op.keyType)
 	}
-	// Note, we log the key ID for info purposes and is safe because it isn't
-	// sensitive. NEVER log the spreadKey.
-	op.logger.Info("generating spread key", "keyID", keyID)
+}
+
+// makeVerticaSpreadSecurityDetails returns the spread security details of a
+// new key ID and the given key of the vertica type, or of a generated key if
+// the key is empty
+func makeVerticaSpreadSecurityDetails(spreadKey string) (string, error) {
+	keyID, err := generateSpreadKeyID()
+	if err != nil {
+		return "", err
+	}
+	if spreadKey == "" {
+		spreadKey, err = generateVerticaSpreadKey()
+		if err != nil {
+			return "", err
+		}
+	}
+	// NEVER log the spreadKey.
 	return fmt.Sprintf(`{\"%s\":\"%s\"}`, keyID, spreadKey), nil
 }
 
 func (op *nmaSpreadSecurityOp) generateVerticaSpreadKey() (string, error) {
-	const spreadKeySize = 32
+	return generateVerticaSpreadKey()
+}
+
+func generateVerticaSpreadKey() (string, error) {
 	bytes := make([]byte, spreadKeySize)
 	if _, err := crand.Read(bytes); err != nil {
 		return "", fmt.Errorf("failed to generate random bytes for spread: %w", err)
@@ -201,8 +221,11 @@ func (op *nmaSpreadSecurityOp) generateVerticaSpreadKey() (string, error) {
 }
 
 func (op *nmaSpreadSecurityOp) generateKeyID() (string, error) {
-	const keyLength = 2
-	bytes := make([]byte, keyLength)
+	return generateSpreadKeyID()
+}
+
+func generateSpreadKeyID() (string, error) {
+	bytes := make([]byte, spreadKeyIDSize)
 	if _, err := crand.Read(bytes); err != nil {
 		return "", fmt.Errorf("failed to generate random bytes for key ID: %w", err)
 	}
@@ -22,10 +22,18 @@ import (
 
 type nmaStageDCTablesOp struct {
 	scrutinizeOpBase
+	// if empty, all DC tables are staged
+	tables []string
+	// if set, only the records in the time range are staged
+	startTime string
+	endTime   string
 }
 
 type stageDCTablesRequestData struct {
-	CatalogPath string `json:"catalog_path"`
+	CatalogPath string   `json:"catalog_path"`
+	Tables      []string `json:"tables,omitempty"`
+	StartTime   string   `json:"start_time,omitempty"`
+	EndTime     string   `json:"end_time,omitempty"`
 }
 
 type stageDCTablesResponseData struct {
@@ -56,11 +64,32 @@ func makeNMAStageDCTablesOp(
 	return op, err
 }
 
+// makeNMAStageDCTablesOpWithFilter makes an op that only stages the given DC tables,
+// and only their records in the time range from startTime to endTime. The times are
+// in RFC 3339 format, and an empty time leaves that end of the range open.
+func makeNMAStageDCTablesOpWithFilter(
+	id string,
+	hosts []string,
+	hostNodeNameMap map[string]string,
+	hostCatPathMap map[string]string,
+	tables []string,
+	startTime, endTime string) (nmaStageDCTablesOp, error) {
+	op, err := makeNMAStageDCTablesOp(id, hosts, hostNodeNameMap, hostCatPathMap)
+	op.description = fmt.Sprintf("Stage %d DC table(s)", len(tables))
+	op.tables = tables
+	op.startTime = startTime
+	op.endTime = endTime
+	return op, err
+}
+
 func (op *nmaStageDCTablesOp) setupRequestBody(hosts []string) error {
 	op.hostRequestBodyMap = make(map[string]string, len(hosts))
 	for _, host := range hosts {
 		stageDCTablesData := stageDCTablesRequestData{}
 		stageDCTablesData.CatalogPath = op.hostCatPathMap[host]
+		stageDCTablesData.Tables = op.tables
+		stageDCTablesData.StartTime = op.startTime
+		stageDCTablesData.EndTime = op.endTime
 
 		dataBytes, err := json.Marshal(stageDCTablesData)
 		if err != nil {
@@ -64,6 +64,35 @@ func makeNMAStageFilesOp(
 	return op, err
 }
 
+// makeNMAStageNMALogsOp makes an op that stages the NMA logs of the hosts
+func makeNMAStageNMALogsOp(
+	id string,
+	hosts []string,
+	hostNodeNameMap map[string]string,
+	hostCatPathMap map[string]string,
+	logSizeLimitBytes int64) (nmaStageFilesOp, error) {
+	op, err := makeNMAStageFilesOp(id, scrutinizeBatchNormal, hosts, hostNodeNameMap, hostCatPathMap, logSizeLimitBytes)
+	op.name = "NMAStageNMALogsOp"
+	op.description = "Stage NMA logs"
+	op.urlSuffix = "/nma.log"
+	return op, err
+}
+
+// makeNMAStageCoreFilesOp makes an op that stages the core files that Vertica
+// left in the catalog directories of the hosts
+func makeNMAStageCoreFilesOp(
+	id string,
+	hosts []string,
+	hostNodeNameMap map[string]string,
+	hostCatPathMap map[string]string,
+	fileSizeLimitBytes int64) (nmaStageFilesOp, error) {
+	op, err := makeNMAStageFilesOp(id, scrutinizeBatchNormal, hosts, hostNodeNameMap, hostCatPathMap, fileSizeLimitBytes)
+	op.name = "NMAStageCoreFilesOp"
+	op.description = "Stage core files"
+	op.urlSuffix = "/core_files"
+	return op, err
+}
+
 func (op *nmaStageFilesOp) setupRequestBody(hosts []string) error {
 	op.hostRequestBodyMap = make(map[string]string, len(hosts))
 	for _, host := range hosts {
@@ -25,6 +25,8 @@ type nmaStageVerticaLogsOp struct {
 	logSizeLimitBytes int64
 	logAgeMaxHours    int // The maximum age of archived logs in hours to retrieve
 	logAgeMinHours    int // The minimum age of archived logs in hours to retrieve
+	// The limit of the total size of logs staged on each host, no limit if 0
+	hostSizeLimitBytes int64
 }
 
 type stageVerticaLogsRequestData struct {
@@ -32,6 +34,8 @@ type stageVerticaLogsRequestData struct {
 	LogSizeLimitBytes int64  `json:"log_size_limit_bytes"`
 	LogAgeMaxHours    int    `json:"log_max_age_hours,omitempty"`
 	LogAgeMinHours    int    `json:"log_min_age_hours,omitempty"`
+	// the NMA stages the newest logs first, and stops once the limit is reached
+	HostSizeLimitBytes int64 `json:"host_size_limit_bytes,omitempty"`
 }
 
 type stageVerticaLogsResponseData struct {
@@ -69,6 +73,11 @@ func makeNMAStageVerticaLogsOp(
 	return op, err
 }
 
+// setHostSizeLimit caps the total size of the logs staged on each host
+func (op *nmaStageVerticaLogsOp) setHostSizeLimit(hostSizeLimitBytes int64) {
+	op.hostSizeLimitBytes = hostSizeLimitBytes
+}
+
 func (op *nmaStageVerticaLogsOp) setupRequestBody(hosts []string) error {
 	op.hostRequestBodyMap = make(map[string]string, len(hosts))
 	for _, host := range hosts {
@@ -77,6 +86,7 @@ func (op *nmaStageVerticaLogsOp) setupRequestBody(hosts []string) error {
 		stageVerticaLogsData.LogSizeLimitBytes = op.logSizeLimitBytes
 		stageVerticaLogsData.LogAgeMaxHours = op.logAgeMaxHours
 		stageVerticaLogsData.LogAgeMinHours = op.logAgeMinHours
+		stageVerticaLogsData.HostSizeLimitBytes = op.hostSizeLimitBytes
 
 		dataBytes, err := json.Marshal(stageVerticaLogsData)
 		if err != nil {
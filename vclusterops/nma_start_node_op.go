@@ -27,6 +27,9 @@ type nmaStartNodeOp struct {
 	hostRequestBodyMap map[string]string
 	vdb                *VCoordinationDatabase
 	sandbox            bool
+	// the arguments that are added to the start command of each node, such
+	// as the arguments of the start modes of start_db
+	startArgs []string
 }
 
 type startNodeRequestData struct {
@@ -111,8 +114,12 @@ func (op *nmaStartNodeOp) updateRequestBody(execContext *opEngineExecContext) er
 }
 
 func (op *nmaStartNodeOp) updateHostRequestBodyMapFromNodeStartCommand(host string, hostStartCommand []string) error {
+	// the start command is copied, as it can be shared with the exec context
+	startCommand := make([]string, 0, len(hostStartCommand)+len(op.startArgs))
+	startCommand = append(startCommand, hostStartCommand...)
+	startCommand = append(startCommand, op.startArgs...)
 	startNodeData := startNodeRequestData{
-		StartCommand: hostStartCommand,
+		StartCommand: startCommand,
 		StartupConf:  op.startupConf,
 	}
 
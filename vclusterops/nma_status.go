@@ -0,0 +1,124 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+)
+
+// VGetNMAStatusOptions represents the available options for VGetNMAStatus. The
+// NMA of each host of the DatabaseOptions is checked.
+type VGetNMAStatusOptions struct {
+	DatabaseOptions
+	// the version that the NMA of each host must have. If it is empty, the NMAs
+	// must have the version that most of them have.
+	ExpectedVersion string
+}
+
+// NMAStatus is the health and the version of the NMA on a host
+type NMAStatus struct {
+	Host string `json:"host"`
+	// whether the NMA responds
+	Reachable bool `json:"reachable"`
+	// whether the NMA responds and reports that it is healthy
+	Healthy bool   `json:"healthy"`
+	Version string `json:"version,omitempty"`
+	// whether the version of the NMA differs from the expected version
+	VersionMismatch bool `json:"version_mismatch"`
+	// why the NMA is not healthy or has no version, if so
+	Error string `json:"error,omitempty"`
+}
+
+func VGetNMAStatusOptionsFactory() VGetNMAStatusOptions {
+	opt := VGetNMAStatusOptions{}
+	opt.setDefaultValues()
+	return opt
+}
+
+func (options *VGetNMAStatusOptions) validateAnalyzeOptions() error {
+	if len(options.RawHosts) == 0 && len(options.Hosts) == 0 {
+		return fmt.Errorf("must specify a host or host list")
+	}
+	return options.resolveHosts()
+}
+
+// VGetNMAStatus gets the health and the version of the NMA on each host, so that
+// the agents that are down or have another version can be found before a
+// command fails on them. The statuses are returned in the order of the hosts.
+// Use GetNMAStatusError to turn the statuses into an error.
+func (vcc VClusterCommands) VGetNMAStatus(options *VGetNMAStatusOptions) ([]NMAStatus, error) {
+	err := options.validateAnalyzeOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	hostStatuses := make(map[string]*NMAStatus, len(options.Hosts))
+	for _, host := range options.Hosts {
+		hostStatuses[host] = &NMAStatus{Host: host}
+	}
+	nmaGetHealthStatusOp := makeNMAGetHealthStatusOp(options.Hosts, hostStatuses)
+	nmaGetVersionStatusOp := makeNMAGetVersionStatusOp(hostStatuses)
+	err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, []clusterOp{&nmaGetHealthStatusOp, &nmaGetVersionStatusOp})
+	if err != nil {
+		return nil, fmt.Errorf("fail to get the NMA status of hosts %v, %w", options.Hosts, err)
+	}
+
+	return buildNMAStatuses(options.Hosts, hostStatuses, options.ExpectedVersion), nil
+}
+
+// buildNMAStatuses orders the statuses as the hosts, and flags the NMAs whose
+// version differs from the expected version, or from the most common version
+func buildNMAStatuses(hosts []string, hostStatuses map[string]*NMAStatus, expectedVersion string) []NMAStatus {
+	if expectedVersion == "" {
+		var versions []string
+		for _, status := range hostStatuses {
+			if status.Version != "" {
+				versions = append(versions, status.Version)
+			}
+		}
+		expectedVersion = getMostCommonVersion(versions)
+	}
+
+	statuses := make([]NMAStatus, 0, len(hosts))
+	for _, host := range hosts {
+		status := *hostStatuses[host]
+		status.VersionMismatch = status.Version != "" && status.Version != expectedVersion
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// GetNMAStatusError returns an error that lists the hosts whose NMA is not healthy
+// or has a mismatched version, or nil if all NMAs are healthy with the same version
+func GetNMAStatusError(statuses []NMAStatus) error {
+	var unhealthyHosts, mismatchedHosts []string
+	for i := range statuses {
+		if !statuses[i].Healthy {
+			unhealthyHosts = append(unhealthyHosts, statuses[i].Host)
+		} else if statuses[i].VersionMismatch {
+			mismatchedHosts = append(mismatchedHosts, fmt.Sprintf("%s (%s)", statuses[i].Host, statuses[i].Version))
+		}
+	}
+	var err error
+	if len(unhealthyHosts) > 0 {
+		err = fmt.Errorf("NMA is down or not healthy on hosts %v", unhealthyHosts)
+	}
+	if len(mismatchedHosts) > 0 {
+		err = errors.Join(err, fmt.Errorf("NMA version does not match on hosts %v", mismatchedHosts))
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestGetNMAStatus(t *testing.T) {
+	hosts := []string{"192.168.1.101", "192.168.1.102", "192.168.1.103", "192.168.1.104"}
+	hostStatuses := make(map[string]*NMAStatus)
+	for _, host := range hosts {
+		hostStatuses[host] = &NMAStatus{Host: host}
+	}
+
+	healthOp := makeNMAGetHealthStatusOp(hosts, hostStatuses)
+	healthOp.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, content: `{"healthy": "true"}`},
+		"192.168.1.102": {status: SUCCESS, content: `{"healthy": "true"}`},
+		"192.168.1.103": {status: SUCCESS, content: `{"healthy": "true"}`},
+		"192.168.1.104": {status: FAILURE, err: errors.New("connection refused")},
+	}
+	assert.NoError(t, healthOp.processResult(nil))
+
+	// the version is only asked to the healthy NMAs
+	versionOp := makeNMAGetVersionStatusOp(hostStatuses)
+	execContext := makeOpEngineExecContext(vlog.Printer{})
+	versionOp.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, versionOp.prepare(&execContext))
+	assert.ElementsMatch(t, hosts[:3], versionOp.hosts)
+	versionOp.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, content: `{"nma_version": "v24.2.0"}`},
+		"192.168.1.102": {status: SUCCESS, content: `{"nma_version": "v24.2.0"}`},
+		"192.168.1.103": {status: SUCCESS, content: `{"nma_version": "v24.1.0"}`},
+	}
+	assert.NoError(t, versionOp.processResult(nil))
+
+	statuses := buildNMAStatuses(hosts, hostStatuses, "")
+	assert.Equal(t, NMAStatus{Host: "192.168.1.101", Reachable: true, Healthy: true, Version: "v24.2.0"}, statuses[0])
+	assert.True(t, statuses[2].VersionMismatch)
+	assert.Equal(t, NMAStatus{Host: "192.168.1.104", Error: "connection refused"}, statuses[3])
+	err := GetNMAStatusError(statuses)
+	assert.ErrorContains(t, err, "NMA is down or not healthy on hosts [192.168.1.104]")
+	assert.ErrorContains(t, err, "NMA version does not match on hosts [192.168.1.103 (v24.1.0)]")
+
+	// the NMAs must have the version that is given
+	statuses = buildNMAStatuses(hosts[:3], hostStatuses, "v24.1.0")
+	assert.True(t, statuses[0].VersionMismatch)
+	assert.False(t, statuses[2].VersionMismatch)
+	assert.NoError(t, GetNMAStatusError(statuses[2:3]))
+}
@@ -0,0 +1,169 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type nmaSyncLibrariesOp struct {
+	opBase
+	// the existing host whose NMA provides the library files
+	sourceHost        string
+	sourceCatalogPath string
+	// the catalog path of each host that receives the library files
+	hostCatalogPaths   map[string]string
+	hostRequestBodyMap map[string]string
+}
+
+type syncLibraryFile struct {
+	FilePath string `json:"file_path"`
+	MD5Sum   string `json:"md5sum"`
+}
+
+type syncLibrariesRequestData struct {
+	SourceHost        string            `json:"source_host"`
+	SourceCatalogPath string            `json:"source_catalog_path"`
+	CatalogPath       string            `json:"catalog_path"`
+	Libraries         []syncLibraryFile `json:"libraries"`
+}
+
+// makeNMASyncLibrariesOp makes an op that copies the files of the UDx libraries,
+// found by a previous httpsGetLibrariesOp, from the catalog directory of the source
+// host to the catalog directory of the target hosts. The NMA of each target host
+// downloads the files from the NMA of the source host, and checks their md5sum.
+func makeNMASyncLibrariesOp(sourceHost string, targetHosts []string,
+	vdb *VCoordinationDatabase) (nmaSyncLibrariesOp, error) {
+	op := nmaSyncLibrariesOp{}
+	op.name = "NMASyncLibrariesOp"
+	op.description = "Sync UDx libraries to new nodes"
+	op.hosts = targetHosts
+	op.sourceHost = sourceHost
+
+	sourceNode, ok := vdb.HostNodeMap[sourceHost]
+	if !ok {
+		return op, fmt.Errorf("[%s] fail to get catalog path from host %s", op.name, sourceHost)
+	}
+	op.sourceCatalogPath = getCatalogPath(sourceNode.CatalogPath)
+	op.hostCatalogPaths = make(map[string]string)
+	for _, host := range targetHosts {
+		vnode, ok := vdb.HostNodeMap[host]
+		if !ok {
+			return op, fmt.Errorf("[%s] fail to get catalog path from host %s", op.name, host)
+		}
+		op.hostCatalogPaths[host] = getCatalogPath(vnode.CatalogPath)
+	}
+	return op, nil
+}
+
+func (op *nmaSyncLibrariesOp) setupRequestBody(libraries []userLibrary) error {
+	files := make([]syncLibraryFile, 0, len(libraries))
+	for i := range libraries {
+		files = append(files, syncLibraryFile{FilePath: libraries[i].FilePath, MD5Sum: libraries[i].MD5Sum})
+	}
+
+	op.hostRequestBodyMap = make(map[string]string)
+	for _, host := range op.hosts {
+		requestData := syncLibrariesRequestData{
+			SourceHost:        op.sourceHost,
+			SourceCatalogPath: op.sourceCatalogPath,
+			CatalogPath:       op.hostCatalogPaths[host],
+			Libraries:         files,
+		}
+		dataBytes, err := json.Marshal(requestData)
+		if err != nil {
+			return fmt.Errorf("[%s] fail to marshal request data to JSON string, detail %w", op.name, err)
+		}
+		op.hostRequestBodyMap[host] = string(dataBytes)
+	}
+
+	return nil
+}
+
+func (op *nmaSyncLibrariesOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildNMAEndpoint("libraries/sync")
+		httpRequest.RequestData = op.hostRequestBodyMap[host]
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaSyncLibrariesOp) prepare(execContext *opEngineExecContext) error {
+	if len(execContext.userLibraries) == 0 {
+		op.logger.Info("no UDx libraries to sync, skipping the operation")
+		op.skipExecute = true
+		return nil
+	}
+
+	err := op.setupRequestBody(execContext.userLibraries)
+	if err != nil {
+		return err
+	}
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *nmaSyncLibrariesOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaSyncLibrariesOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+type syncLibrariesResponse struct {
+	SyncedFiles []string `json:"synced_files"`
+}
+
+func (op *nmaSyncLibrariesOp) processResult(execContext *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+			continue
+		}
+
+		// the response will contain the library files that are copied, e.g.,
+		// {"synced_files": ["Libraries/02fc2ad1e5a6b7bd0c5b4f6b3e2e0e1d00a000000001f43a/MyFunctions.so"]}
+		response := syncLibrariesResponse{}
+		err := op.parseAndCheckResponse(host, result.content, &response)
+		if err != nil {
+			allErrs = errors.Join(allErrs, err)
+			continue
+		}
+		if len(response.SyncedFiles) != len(execContext.userLibraries) {
+			err = fmt.Errorf("[%s] %d of %d library files are synced to host %s", op.name,
+				len(response.SyncedFiles), len(execContext.userLibraries), host)
+			allErrs = errors.Join(allErrs, err)
+		}
+	}
+
+	return allErrs
+}
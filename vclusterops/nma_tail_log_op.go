@@ -0,0 +1,127 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+
+	"golang.org/x/exp/slices"
+)
+
+type nmaTailLogOp struct {
+	opBase
+	vdb       *VCoordinationDatabase
+	nodeNames []string
+	logFile   string
+	lines     int
+	follow    bool
+	// called with each line as it is received from a host
+	onLine func(host, line string)
+}
+
+// makeNMATailLogOp makes an op that streams the last lines of a log file in the
+// catalog directory of the nodes, and the lines written after them if follow is
+// set. The catalog paths are taken from vdb, which is filled in by NMAGetNodesInfoOp.
+// If nodeNames is empty, the log of every node is streamed.
+func makeNMATailLogOp(vdb *VCoordinationDatabase, nodeNames []string, logFile string, lines int,
+	follow bool, onLine func(host, line string)) nmaTailLogOp {
+	op := nmaTailLogOp{}
+	op.name = "NMATailLogOp"
+	op.description = fmt.Sprintf("Tail %s", logFile)
+	op.vdb = vdb
+	op.nodeNames = nodeNames
+	op.logFile = logFile
+	op.lines = lines
+	op.follow = follow
+	op.onLine = onLine
+	return op
+}
+
+func (op *nmaTailLogOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = GetMethod
+		httpRequest.buildNMAEndpoint("logs/tail")
+		httpRequest.QueryParams = map[string]string{
+			"path":   path.Join(op.vdb.HostNodeMap[host].CatalogPath, op.logFile),
+			"lines":  strconv.Itoa(op.lines),
+			"follow": strconv.FormatBool(op.follow),
+		}
+		// the log is followed until the command is canceled
+		if op.follow {
+			httpRequest.Timeout = -1
+		}
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaTailLogOp) prepare(execContext *opEngineExecContext) error {
+	op.hosts = nil
+	foundNodeNames := make([]string, 0, len(op.nodeNames))
+	for host, vnode := range op.vdb.HostNodeMap {
+		if len(op.nodeNames) > 0 && !slices.Contains(op.nodeNames, vnode.Name) {
+			continue
+		}
+		op.hosts = append(op.hosts, host)
+		foundNodeNames = append(foundNodeNames, vnode.Name)
+	}
+	for _, nodeName := range op.nodeNames {
+		if !slices.Contains(foundNodeNames, nodeName) {
+			return fmt.Errorf("[%s] cannot find node %s on the hosts", op.name, nodeName)
+		}
+	}
+	if len(op.hosts) == 0 {
+		return fmt.Errorf("[%s] cannot find any node on the hosts", op.name)
+	}
+	sort.Strings(op.hosts)
+	execContext.dispatcher.setupForStream(op.hosts, op.onLine)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *nmaTailLogOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaTailLogOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+// The lines of the log are streamed in a chunked response as they are read,
+// so a successful result has no content left to process.
+func (op *nmaTailLogOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,153 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ConfigurationParameterError is returned when a configuration parameter of
+// create_db is unknown to the Vertica version of the hosts, or its value is
+// not valid
+type ConfigurationParameterError struct {
+	Name   string
+	Detail string
+	// the known parameter with the closest name, if the name is unknown
+	Suggestion string
+}
+
+func (e *ConfigurationParameterError) Error() string {
+	msg := fmt.Sprintf("invalid configuration parameter %s: %s", e.Name, e.Detail)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %s?", e.Suggestion)
+	}
+	return msg
+}
+
+type nmaValidateConfigParamsOp struct {
+	opBase
+	hostRequestBody string
+}
+
+type validateConfigParamsRequestData struct {
+	// some parameters are only valid in an Eon database
+	IsEon bool `json:"is_eon"`
+	sensitiveFields
+}
+
+type configParamValidation struct {
+	Name       string `json:"name"`
+	Valid      bool   `json:"valid"`
+	Detail     string `json:"detail"`
+	Suggestion string `json:"suggestion"`
+}
+
+type validateConfigParamsResponse struct {
+	Parameters []configParamValidation `json:"parameters"`
+}
+
+// makeNMAValidateConfigParamsOp makes an op that checks the names and the
+// values of the configuration parameters against the Vertica version that is
+// installed on the host, so that a typo fails create_db before any directory
+// is created. The versions of the hosts are checked to be the same before,
+// so the parameters are only checked on one host.
+func makeNMAValidateConfigParamsOp(hosts []string, parameters map[string]string, isEon bool) (nmaValidateConfigParamsOp, error) {
+	op := nmaValidateConfigParamsOp{}
+	op.name = "NMAValidateConfigParamsOp"
+	op.description = "Validate configuration parameters"
+	op.hosts = hosts
+
+	requestData := validateConfigParamsRequestData{}
+	requestData.IsEon = isEon
+	requestData.Parameters = parameters
+	dataBytes, err := json.Marshal(requestData)
+	if err != nil {
+		return op, fmt.Errorf("[%s] fail to marshal request data to JSON string, detail %w", op.name, err)
+	}
+	op.hostRequestBody = string(dataBytes)
+
+	return op, nil
+}
+
+func (op *nmaValidateConfigParamsOp) setupClusterHTTPRequest(hosts []string) error {
+	for _, host := range hosts {
+		httpRequest := hostHTTPRequest{}
+		httpRequest.Method = PostMethod
+		httpRequest.buildNMAEndpoint("configuration-parameters/validate")
+		httpRequest.RequestData = op.hostRequestBody
+		op.clusterHTTPRequest.RequestCollection[host] = httpRequest
+	}
+
+	return nil
+}
+
+func (op *nmaValidateConfigParamsOp) prepare(execContext *opEngineExecContext) error {
+	execContext.dispatcher.setup(op.hosts)
+
+	return op.setupClusterHTTPRequest(op.hosts)
+}
+
+func (op *nmaValidateConfigParamsOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *nmaValidateConfigParamsOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *nmaValidateConfigParamsOp) processResult(_ *opEngineExecContext) error {
+	var allErrs error
+
+	for host, result := range op.clusterHTTPRequest.ResultCollection {
+		op.logResponse(host, result)
+
+		// the NMA that does not have the endpoint leaves the parameters to the bootstrap
+		if result.isNotFound() {
+			op.logger.PrintWarning("[%s] the NMA on host %s cannot validate the configuration parameters, "+
+				"skipping the validation", op.name, host)
+			continue
+		}
+		if !result.isPassing() {
+			allErrs = errors.Join(allErrs, result.err)
+			continue
+		}
+
+		// the response looks like
+		// {"parameters": [{"name": "MaxClientSessions", "valid": true, "detail": "", "suggestion": ""},
+		//                 {"name": "MaxClientSessionss", "valid": false, "detail": "unknown parameter",
+		//                  "suggestion": "MaxClientSessions"}]}
+		response := validateConfigParamsResponse{}
+		err := op.parseAndCheckResponse(host, result.content, &response)
+		if err != nil {
+			allErrs = errors.Join(allErrs, fmt.Errorf("[%s] fail to parse result on host %s, details: %w", op.name, host, err))
+			continue
+		}
+		for _, param := range response.Parameters {
+			if !param.Valid {
+				allErrs = errors.Join(allErrs, &ConfigurationParameterError{Name: param.Name, Detail: param.Detail,
+					Suggestion: param.Suggestion})
+			}
+		}
+	}
+
+	return allErrs
+}
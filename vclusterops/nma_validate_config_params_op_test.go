@@ -0,0 +1,50 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestValidateConfigParamsResult(t *testing.T) {
+	op, err := makeNMAValidateConfigParamsOp([]string{"192.168.1.101"},
+		map[string]string{"MaxClientSessions": "100", "MaxClientSessionss": "100"}, false)
+	assert.NoError(t, err)
+	assert.Contains(t, op.hostRequestBody, `"MaxClientSessionss":"100"`)
+	op.setLogger(vlog.Printer{})
+	op.setupBasicInfo()
+
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, statusCode: SuccessCode,
+			content: `{"parameters": [{"name": "MaxClientSessions", "valid": true, "detail": "", "suggestion": ""},
+				{"name": "MaxClientSessionss", "valid": false, "detail": "unknown parameter", "suggestion": "MaxClientSessions"}]}`},
+	}
+	err = op.processResult(nil)
+	var paramErr *ConfigurationParameterError
+	assert.True(t, errors.As(err, &paramErr))
+	assert.Equal(t, "MaxClientSessionss", paramErr.Name)
+	assert.ErrorContains(t, err, "did you mean MaxClientSessions?")
+
+	// an NMA without the endpoint skips the validation
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: FAILURE, statusCode: NotFoundCode, err: errors.New("404 Not Found")},
+	}
+	assert.NoError(t, op.processResult(nil))
+}
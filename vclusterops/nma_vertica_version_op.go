@@ -18,6 +18,8 @@ package vclusterops
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
 
 	"github.com/vertica/vcluster/vclusterops/util"
 )
@@ -27,6 +29,10 @@ const (
 	DefaultSC = "default_subcluster"
 )
 
+// verticaVersionRegexp matches a Vertica version such as v24.1.0-2, in which
+// the patch and the hotfix are optional
+var verticaVersionRegexp = regexp.MustCompile(`v?(\d+)\.(\d+)(?:\.(\d+))?(?:-(\d+))?`)
+
 type hostVersionMap map[string]string
 
 type nmaVerticaVersionOp struct {
@@ -38,6 +44,8 @@ type nmaVerticaVersionOp struct {
 	vdb                *VCoordinationDatabase
 	sandbox            bool
 	scName             string
+	// when set, the version of every host must contain this string
+	expectedVersion string
 }
 
 func makeHostVersionMap() hostVersionMap {
@@ -83,6 +91,39 @@ func makeNMAVerticaVersionOpWithVDB(sameVersion bool, vdb *VCoordinationDatabase
 	return op
 }
 
+// makeNMAVerticaVersionOpWithExpectedVersion is used to verify that the hosts
+// have the expected Vertica binaries installed, e.g., before restarting them after an upgrade
+func makeNMAVerticaVersionOpWithExpectedVersion(hosts []string, expectedVersion string) nmaVerticaVersionOp {
+	op := makeNMAVerticaVersionOp(hosts, true /*sameVersion*/, false /*isEon*/)
+	op.description = "Check new Vertica version"
+	op.expectedVersion = expectedVersion
+	return op
+}
+
+// matchesExpectedVersion returns true if the version reported by a host has the
+// same components as the expected version. The components that are not given
+// in the expected version match any value, so 24.1 matches v24.1.0-2 but not
+// v24.10.0.
+func matchesExpectedVersion(version, expectedVersion string) bool {
+	expected := verticaVersionRegexp.FindStringSubmatch(expectedVersion)
+	actual := verticaVersionRegexp.FindStringSubmatch(version)
+	if expected == nil || actual == nil {
+		return false
+	}
+	for i := 1; i < len(expected); i++ {
+		if expected[i] == "" {
+			continue
+		}
+		// the components are digits, so they are always parsed
+		expectedComponent, _ := strconv.Atoi(expected[i])
+		actualComponent, _ := strconv.Atoi(actual[i])
+		if expectedComponent != actualComponent {
+			return false
+		}
+	}
+	return true
+}
+
 func (op *nmaVerticaVersionOp) setupClusterHTTPRequest(hosts []string) error {
 	for _, host := range hosts {
 		httpRequest := hostHTTPRequest{}
@@ -266,6 +307,9 @@ func (op *nmaVerticaVersionOp) logCheckVersionMatch() error {
 					return fmt.Errorf("[%s] No version collected for host [%s] in subcluster [%s]", op.name, host, sc)
 				}
 				return fmt.Errorf("[%s] No version collected for host [%s]", op.name, host)
+			} else if op.expectedVersion != "" && !matchesExpectedVersion(version, op.expectedVersion) {
+				return fmt.Errorf("[%s] Found version [%s] on host [%s], expected version [%s]",
+					op.name, version, host, op.expectedVersion)
 			} else if versionStr == NoVersion {
 				// first time seeing a valid version, set it as the versionStr
 				versionStr = version
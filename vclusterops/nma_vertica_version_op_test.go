@@ -123,3 +123,14 @@ func TestLogCheckVersionMatch(t *testing.T) {
 	err = op.logCheckVersionMatch()
 	assert.ErrorContains(t, err, "No version collected for all hosts in subcluster [sc1]")
 }
+
+func TestMatchesExpectedVersion(t *testing.T) {
+	assert.True(t, matchesExpectedVersion("Vertica Analytic Database v24.1.0", "v24.1.0"))
+	assert.True(t, matchesExpectedVersion("Vertica Analytic Database v24.1.0-2", "24.1"))
+	assert.True(t, matchesExpectedVersion("Vertica Analytic Database v24.1.0-2", "v24.1.0-2"))
+	// a version is not matched by its prefix
+	assert.False(t, matchesExpectedVersion("Vertica Analytic Database v24.10.0", "24.1"))
+	assert.False(t, matchesExpectedVersion("Vertica Analytic Database v24.1.1", "v24.1.0"))
+	assert.False(t, matchesExpectedVersion("Vertica Analytic Database v24.1.0-1", "v24.1.0-2"))
+	assert.False(t, matchesExpectedVersion("Vertica Analytic Database", "v24.1.0"))
+}
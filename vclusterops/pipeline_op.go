@@ -0,0 +1,128 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"sync"
+)
+
+// pipelineBatch is the instructions of a batch of hosts in a pipeline
+type pipelineBatch struct {
+	// the hosts of the batch, which are reported if the batch fails
+	hosts []string
+	// the instructions that run after the serial instructions of the previous batch
+	serial []clusterOp
+	// the instructions that run once the serial instructions of the batch
+	// succeed, concurrently with the instructions of the next batches
+	concurrent []clusterOp
+}
+
+// pipelineOp runs the instructions of several batches of hosts as a pipeline.
+// The serial instructions of the batches run one batch after another, such as
+// the instructions that change the catalog, and the concurrent instructions of
+// a batch overlap with the next batches. The instructions of a batch share an
+// exec context, which is separate from the other batches.
+type pipelineOp struct {
+	opBase
+	batches []pipelineBatch
+	certs   *httpsCerts
+	// whether the op succeeds when some of the batches succeed
+	allowPartialFailure bool
+	// the hosts of the batches that failed or were not started, filled in once the op completes
+	failedHosts []string
+}
+
+func makePipelineOp(name, description string, batches []pipelineBatch) pipelineOp {
+	op := pipelineOp{}
+	op.name = name
+	op.description = description
+	op.batches = batches
+	return op
+}
+
+func (op *pipelineOp) prepare(_ *opEngineExecContext) error {
+	return nil
+}
+
+// loadCertsIfNeeded keeps the certs, which the instructions of the batches load
+func (op *pipelineOp) loadCertsIfNeeded(certs *httpsCerts, _ bool) error {
+	op.certs = certs
+	return nil
+}
+
+func (op *pipelineOp) execute(execContext *opEngineExecContext) error {
+	certs := op.certs
+	if certs == nil {
+		certs = &httpsCerts{}
+	}
+	// the instructions of the batches have no progress spinner, as they run concurrently
+	logger := op.logger
+	logger.ForCli = false
+	ctx := execContext.dispatcher.ctx
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(op.batches))
+	startedBatches := 0
+	for i := range op.batches {
+		batch := op.batches[i]
+		batchContext := makeOpEngineExecContext(logger)
+		// the network profiles are found before the pipeline, and only read by the batches
+		batchContext.networkProfiles = execContext.networkProfiles
+		serialEngine := makeClusterOpEngine(batch.serial, certs)
+		err := serialEngine.runInstructions(ctx, logger, &batchContext, serialEngine.shouldGetCertsFromOptions())
+		if err != nil {
+			// the next batches are not started, the running batches are waited for
+			serialEngine.recordWorkload(ctx, logger, &batchContext)
+			errs[i] = err
+			break
+		}
+		startedBatches++
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			concurrentEngine := makeClusterOpEngine(batch.concurrent, certs)
+			defer concurrentEngine.recordWorkload(ctx, logger, &batchContext)
+			errs[i] = concurrentEngine.runInstructions(ctx, logger, &batchContext, concurrentEngine.shouldGetCertsFromOptions())
+		}(i)
+	}
+	wg.Wait()
+
+	op.failedHosts = nil
+	succeededBatches := 0
+	for i := range op.batches {
+		if i >= startedBatches || errs[i] != nil {
+			op.failedHosts = append(op.failedHosts, op.batches[i].hosts...)
+		} else {
+			succeededBatches++
+		}
+	}
+	err := errors.Join(errs...)
+	if err != nil && op.allowPartialFailure && succeededBatches > 0 {
+		op.logger.PrintWarning("[%s] %d of %d batches succeeded, failed hosts: %v, details: %v",
+			op.name, succeededBatches, len(op.batches), op.failedHosts, err)
+		return nil
+	}
+	return err
+}
+
+func (op *pipelineOp) processResult(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *pipelineOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
@@ -0,0 +1,171 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// funcOp is an op that runs a function instead of sending requests
+type funcOp struct {
+	opBase
+	run func(execContext *opEngineExecContext) error
+}
+
+func makeFuncOp(name string, run func() error) *funcOp {
+	return makeContextOp(name, func(*opEngineExecContext) error { return run() })
+}
+
+// makeContextOp makes a funcOp whose function reads the exec context
+func makeContextOp(name string, run func(execContext *opEngineExecContext) error) *funcOp {
+	op := &funcOp{run: run}
+	op.name = name
+	return op
+}
+
+func (op *funcOp) prepare(execContext *opEngineExecContext) error {
+	op.skipExecute = true
+	return op.run(execContext)
+}
+
+func (op *funcOp) execute(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *funcOp) processResult(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *funcOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
+
+func TestPipelineOp(t *testing.T) {
+	var mu sync.Mutex
+	var steps []string
+	step := func(name string) func() error {
+		return func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			steps = append(steps, name)
+			return nil
+		}
+	}
+
+	// the first batch starts its nodes only once the second batch is created,
+	// which would never happen if the batches did not overlap
+	batch2Created := make(chan struct{})
+	batches := []pipelineBatch{
+		{
+			serial: []clusterOp{makeFuncOp("create1", step("create1"))},
+			concurrent: []clusterOp{makeFuncOp("start1", func() error {
+				<-batch2Created
+				return step("start1")()
+			})},
+		},
+		{
+			serial: []clusterOp{makeFuncOp("create2", func() error {
+				defer close(batch2Created)
+				return step("create2")()
+			})},
+			concurrent: []clusterOp{makeFuncOp("start2", step("start2"))},
+		},
+	}
+	pipelineOp := makePipelineOp("PipelineOp", "", batches)
+	engine := makeClusterOpEngine([]clusterOp{&pipelineOp}, &httpsCerts{})
+	assert.NoError(t, engine.run(context.Background(), vlog.Printer{}))
+	assert.Equal(t, "create1", steps[0])
+	assert.Equal(t, "create2", steps[1])
+	assert.ElementsMatch(t, []string{"start1", "start2"}, steps[2:])
+
+	// a batch that fails to be created stops the pipeline
+	steps = nil
+	batches = []pipelineBatch{
+		{
+			serial:     []clusterOp{makeFuncOp("create1", step("create1"))},
+			concurrent: []clusterOp{makeFuncOp("start1", func() error { return errors.New("start1 failed") })},
+		},
+		{
+			serial:     []clusterOp{makeFuncOp("create2", func() error { return errors.New("create2 failed") })},
+			concurrent: []clusterOp{makeFuncOp("start2", step("start2"))},
+		},
+		{
+			serial: []clusterOp{makeFuncOp("create3", step("create3"))},
+		},
+	}
+	pipelineOp = makePipelineOp("PipelineOp", "", batches)
+	engine = makeClusterOpEngine([]clusterOp{&pipelineOp}, &httpsCerts{})
+	err := engine.run(context.Background(), vlog.Printer{})
+	assert.ErrorContains(t, err, "start1 failed")
+	assert.ErrorContains(t, err, "create2 failed")
+	assert.Equal(t, []string{"create1"}, steps)
+}
+
+func TestPipelineOpPartialFailure(t *testing.T) {
+	var profiles []map[string]networkProfile
+	var mu sync.Mutex
+	getProfiles := func(execContext *opEngineExecContext) error {
+		mu.Lock()
+		defer mu.Unlock()
+		profiles = append(profiles, execContext.networkProfiles)
+		return nil
+	}
+	batches := []pipelineBatch{
+		{
+			hosts:      []string{"192.168.1.101", "192.168.1.102"},
+			serial:     []clusterOp{makeContextOp("create1", getProfiles)},
+			concurrent: []clusterOp{makeFuncOp("start1", func() error { return nil })},
+		},
+		{
+			hosts:      []string{"192.168.1.103"},
+			serial:     []clusterOp{makeFuncOp("create2", func() error { return nil })},
+			concurrent: []clusterOp{makeFuncOp("start2", func() error { return errors.New("start2 failed") })},
+		},
+		{
+			hosts:  []string{"192.168.1.104"},
+			serial: []clusterOp{makeFuncOp("create3", func() error { return errors.New("create3 failed") })},
+		},
+		{
+			hosts:  []string{"192.168.1.105"},
+			serial: []clusterOp{makeContextOp("create4", getProfiles)},
+		},
+	}
+
+	// the failed batches and the batches that are not started are reported
+	pipelineOp := makePipelineOp("PipelineOp", "", batches)
+	pipelineOp.allowPartialFailure = true
+	execContext := makeOpEngineExecContext(vlog.Printer{})
+	execContext.networkProfiles = map[string]networkProfile{"192.168.1.101": {Broadcast: "192.168.1.255"}}
+	engine := makeClusterOpEngine([]clusterOp{&pipelineOp}, &httpsCerts{})
+	assert.NoError(t, engine.runInstructions(context.Background(), vlog.Printer{}, &execContext, false))
+	assert.Equal(t, []string{"192.168.1.103", "192.168.1.104", "192.168.1.105"}, pipelineOp.failedHosts)
+
+	// the batches read the network profiles of the pipeline
+	assert.Equal(t, []map[string]networkProfile{execContext.networkProfiles}, profiles)
+
+	// the pipeline fails if no batch succeeds
+	pipelineOp = makePipelineOp("PipelineOp", "", batches[2:])
+	pipelineOp.allowPartialFailure = true
+	engine = makeClusterOpEngine([]clusterOp{&pipelineOp}, &httpsCerts{})
+	assert.ErrorContains(t, engine.run(context.Background(), vlog.Printer{}), "create3 failed")
+	assert.Equal(t, []string{"192.168.1.104", "192.168.1.105"}, pipelineOp.failedHosts)
+}
@@ -0,0 +1,116 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+// the timeout of each attempt to connect to the client port of a node
+const clientConnectionTimeout = 5 * time.Second
+
+// pollClientConnectionsOp polls the client port of the nodes until all of
+// them accept connections. It connects over TCP, so it sends no HTTP request.
+type pollClientConnectionsOp struct {
+	opBase
+	timeout int
+	// the client port of each host, read from the catalog when it is known
+	ports map[string]int
+	// the hosts that do not accept connections yet
+	pendingHosts []string
+	dial         func(network, address string, timeout time.Duration) (net.Conn, error)
+}
+
+func makePollClientConnectionsOp(hosts []string, timeout int) pollClientConnectionsOp {
+	op := pollClientConnectionsOp{}
+	op.name = "PollClientConnectionsOp"
+	op.description = fmt.Sprintf("Wait for %d node(s) to accept connections", len(hosts))
+	op.hosts = hosts
+	op.timeout = timeout
+	op.dial = net.DialTimeout
+	return op
+}
+
+func (op *pollClientConnectionsOp) getPollingTimeout() int {
+	return util.Max(op.timeout, 0)
+}
+
+func (op *pollClientConnectionsOp) prepare(execContext *opEngineExecContext) error {
+	op.ports = make(map[string]int)
+	for _, host := range op.hosts {
+		op.ports[host] = util.DefaultClientPort
+		if vnode, ok := execContext.nmaVDatabase.HostNodeMap[host]; ok {
+			if port, err := vnode.ClientPort.Int64(); err == nil && port > 0 {
+				op.ports[host] = int(port)
+			}
+		}
+	}
+	op.pendingHosts = op.hosts
+	return nil
+}
+
+// loadCertsIfNeeded does nothing, as the op has no HTTPS request
+func (op *pollClientConnectionsOp) loadCertsIfNeeded(_ *httpsCerts, _ bool) error {
+	return nil
+}
+
+// runExecute tries to connect to each host that does not accept connections yet
+func (op *pollClientConnectionsOp) runExecute(_ *opEngineExecContext) error {
+	var pendingHosts []string
+	for _, host := range op.pendingHosts {
+		address := net.JoinHostPort(host, strconv.Itoa(op.ports[host]))
+		conn, err := op.dial("tcp", address, clientConnectionTimeout)
+		if err != nil {
+			op.logger.Info("node does not accept connections yet", "address", address, "error", err)
+			pendingHosts = append(pendingHosts, host)
+			continue
+		}
+		conn.Close()
+	}
+	op.pendingHosts = pendingHosts
+	return nil
+}
+
+func (op *pollClientConnectionsOp) shouldStopPolling() (bool, error) {
+	acceptingCount := len(op.hosts) - len(op.pendingHosts)
+	op.updateSpinnerMessage("%d out of %d nodes accept connections", acceptingCount, len(op.hosts))
+	return len(op.pendingHosts) == 0, nil
+}
+
+func (op *pollClientConnectionsOp) execute(execContext *opEngineExecContext) error {
+	if err := op.runExecute(execContext); err != nil {
+		return err
+	}
+
+	return op.processResult(execContext)
+}
+
+func (op *pollClientConnectionsOp) processResult(execContext *opEngineExecContext) error {
+	err := pollState(op, execContext)
+	if err != nil {
+		return fmt.Errorf("hosts %v do not accept connections, %w", op.pendingHosts, err)
+	}
+	return nil
+}
+
+func (op *pollClientConnectionsOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
@@ -0,0 +1,150 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+type VPollSubscriptionStateOptions struct {
+	DatabaseOptions
+
+	// the subcluster whose nodes' subscriptions are polled.
+	// If it is empty, the subscriptions of all nodes in the main cluster are polled
+	SCName string
+	// timeout in seconds for polling the subscriptions to be ACTIVE
+	Timeout int
+	// if set, receives the number of ACTIVE subscriptions at each poll
+	ProgressReporter ProgressReporter
+}
+
+func VPollSubscriptionStateOptionsFactory() VPollSubscriptionStateOptions {
+	opt := VPollSubscriptionStateOptions{}
+	// set default values to the params
+	opt.setDefaultValues()
+
+	return opt
+}
+
+func (options *VPollSubscriptionStateOptions) setDefaultValues() {
+	options.DatabaseOptions.setDefaultValues()
+	options.Timeout = util.DefaultTimeoutSeconds
+}
+
+func (options *VPollSubscriptionStateOptions) validateParseOptions(log vlog.Printer) error {
+	err := options.validateBaseOptions(commandPollSubscriptions, log)
+	if err != nil {
+		return err
+	}
+
+	if !options.IsEon {
+		return fmt.Errorf("shard subscriptions are only available in Eon mode")
+	}
+	if options.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+
+	return nil
+}
+
+// resolve hostnames to be IPs
+func (options *VPollSubscriptionStateOptions) analyzeOptions() (err error) {
+	if len(options.RawHosts) > 0 {
+		options.Hosts, err = util.ResolveRawHostsToAddresses(options.RawHosts, options.IPv6)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (options *VPollSubscriptionStateOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateParseOptions(log); err != nil {
+		return err
+	}
+	return options.analyzeOptions()
+}
+
+// VPollSubscriptionState waits until all shard subscriptions of the UP nodes in a subcluster,
+// or of all UP nodes in the main cluster, are ACTIVE. It can be called after adding a subcluster
+// or rebalancing shards. The progress is passed to the ProgressReporter on every poll, and an
+// error is returned if the subscriptions are not all ACTIVE within the timeout.
+func (vcc VClusterCommands) VPollSubscriptionState(options *VPollSubscriptionStateOptions) error {
+	err := options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	vdb := makeVCoordinationDatabase()
+	err = vcc.getVDBFromRunningDB(&vdb, &options.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	nodesToPoll, downNodes := getSubscriptionNodesToPoll(&vdb, options.SCName)
+	if len(downNodes) > 0 {
+		// the subscriptions of a DOWN node do not become ACTIVE until it is started
+		vcc.Log.PrintWarning("Skipping the subscriptions of nodes %v, which are not UP", downNodes)
+	}
+	if len(nodesToPoll) == 0 {
+		return fmt.Errorf("cannot find any UP nodes of subcluster %s in database %s", options.SCName, options.DBName)
+	}
+	if len(vdb.PrimaryUpNodes) == 0 {
+		return fmt.Errorf("cannot find any primary up nodes in database %s", options.DBName)
+	}
+
+	httpsPollSubscriptionStateOp, err := makeHTTPSPollSubscriptionStateOpWithTimeout(vdb.PrimaryUpNodes,
+		options.usePassword, options.UserName, options.Password, &nodesToPoll, options.Timeout)
+	if err != nil {
+		return err
+	}
+	httpsPollSubscriptionStateOp.reporter = options.ProgressReporter
+
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+	clusterOpEngine := makeClusterOpEngine([]clusterOp{&httpsPollSubscriptionStateOp}, &certs)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	if err != nil {
+		return fmt.Errorf("fail to wait for shard subscriptions to be ACTIVE: %w", err)
+	}
+	return nil
+}
+
+// getSubscriptionNodesToPoll returns the names of the UP nodes of the main cluster
+// in a subcluster, or in all subclusters if scName is empty, and the names of the
+// nodes that are skipped because they are not UP
+func getSubscriptionNodesToPoll(vdb *VCoordinationDatabase, scName string) (nodesToPoll, downNodes []string) {
+	for _, vnode := range vdb.HostNodeMap {
+		if vnode.Sandbox != util.MainClusterSandbox {
+			continue
+		}
+		if scName != "" && vnode.Subcluster != scName {
+			continue
+		}
+		if vnode.State == util.NodeUpState {
+			nodesToPoll = append(nodesToPoll, vnode.Name)
+		} else {
+			downNodes = append(downNodes, vnode.Name)
+		}
+	}
+	sort.Strings(nodesToPoll)
+	sort.Strings(downNodes)
+	return nodesToPoll, downNodes
+}
@@ -0,0 +1,110 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"sync"
+	"time"
+)
+
+// the stages of an item reported to a ProgressReporter
+const (
+	ProgressStageStaged      = "staged"
+	ProgressStageTarring     = "tarring"
+	ProgressStageDownloading = "downloading"
+	ProgressStageDone        = "done"
+	ProgressStageFailed      = "failed"
+	ProgressStageDraining    = "draining"
+	ProgressStageRunning     = "running"
+	ProgressStageRebalancing = "rebalancing"
+	ProgressStageSubscribing = "subscribing"
+)
+
+// ProgressEvent describes the progress of one item of a long running operation,
+// e.g. the tarball of one batch on one host during scrutinize, or one node
+// while its sessions drain during stop_subcluster, or a phase of a restart, or
+// the rebalance of the cluster after add_node, or the shard subscriptions of
+// the nodes that are being polled
+type ProgressEvent struct {
+	Host  string
+	Item  string
+	Stage string
+	// the bytes of the item processed so far, 0 if unknown
+	Bytes int64
+	// the client sessions still connected to the node of a draining item
+	Sessions int
+	// the percent of the item that is complete, 0 if unknown
+	Percent float64
+	// the number of items done or failed, and the number of all items
+	Done  int
+	Total int
+	// the estimated time until all items are done, 0 if unknown
+	ETA time.Duration
+}
+
+// ProgressReporter receives the progress of a long running operation. Events can
+// be reported from multiple goroutines at the same time.
+type ProgressReporter interface {
+	ReportProgress(event ProgressEvent)
+}
+
+// progressTracker counts the finished items and estimates the remaining time
+// before passing events to a reporter. A nil tracker reports nothing.
+type progressTracker struct {
+	reporter  ProgressReporter
+	startTime time.Time
+	total     int
+	done      int
+	mutex     sync.Mutex
+}
+
+func makeProgressTracker(reporter ProgressReporter, total int) *progressTracker {
+	if reporter == nil {
+		return nil
+	}
+	return &progressTracker{
+		reporter:  reporter,
+		startTime: time.Now(),
+		total:     total,
+	}
+}
+
+func (tracker *progressTracker) report(host, item, stage string, bytes int64) {
+	if tracker == nil {
+		return
+	}
+
+	tracker.mutex.Lock()
+	if stage == ProgressStageDone || stage == ProgressStageFailed {
+		tracker.done++
+	}
+	event := ProgressEvent{
+		Host:  host,
+		Item:  item,
+		Stage: stage,
+		Bytes: bytes,
+		Done:  tracker.done,
+		Total: tracker.total,
+	}
+	// assume the remaining items take as long as the finished ones on average
+	if tracker.done > 0 && tracker.done < tracker.total {
+		elapsed := time.Since(tracker.startTime)
+		event.ETA = elapsed / time.Duration(tracker.done) * time.Duration(tracker.total-tracker.done)
+	}
+	tracker.mutex.Unlock()
+
+	tracker.reporter.ReportProgress(event)
+}
@@ -117,14 +117,16 @@ func (opt *VReIPOptions) validateAnalyzeOptions(logger vlog.Printer) error {
 
 // VReIP changes the node address, control address, and control broadcast for a node.
 // It returns any error encountered.
-func (vcc VClusterCommands) VReIP(options *VReIPOptions) error {
+func (vcc VClusterCommands) VReIP(options *VReIPOptions) (err error) {
+	defer vcc.startAudit("re_ip", options)(&err)
+
 	/*
 	 *   - Produce Instructions
 	 *   - Create a VClusterOpEngine
 	 *   - Give the instructions to the VClusterOpEngine to run
 	 */
 
-	err := options.validateAnalyzeOptions(vcc.Log)
+	err = options.validateAnalyzeOptions(vcc.Log)
 	if err != nil {
 		return err
 	}
@@ -162,7 +164,7 @@ func (vcc VClusterCommands) VReIP(options *VReIPOptions) error {
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// give the instructions to the VClusterOpEngine to run
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if runError != nil {
 		return fmt.Errorf("fail to re-ip: %w", runError)
 	}
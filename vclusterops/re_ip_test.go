@@ -16,6 +16,7 @@
 package vclusterops
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -108,3 +109,20 @@ func TestTrimReIPList(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, len(op.reIPList), 3)
 }
+
+func TestReIPQuorumError(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	op := makeNMAReIPOp(nil, &vdb, false)
+
+	// only 1 of 3 primary nodes has the re-ip update
+	op.primaryNodeCount = 3
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"host1": {status: SUCCESS, content: "[]"},
+		"host2": {status: FAILURE, err: errors.New("fail to update the catalog")},
+		"host3": {status: FAILURE, err: errors.New("fail to update the catalog")},
+	}
+	err := op.processResult(nil)
+	var quorumErr *QuorumError
+	assert.ErrorAs(t, err, &quorumErr)
+	assert.ErrorContains(t, err, "failed quorum check for re-ip update. Success count: 1")
+}
@@ -0,0 +1,150 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// catalog recovery actions
+const (
+	// truncate the catalog of each node to a global catalog version
+	CatalogRecoveryTruncate = "truncate"
+	// drop the record of a dead node from the catalog of each other node
+	CatalogRecoveryDropNode = "drop_node"
+)
+
+// VRecoverCatalogOptions represents the available options for VRecoverCatalog.
+// The catalogs are edited on the hosts of the DatabaseOptions, which must not
+// include the host of a dead node.
+type VRecoverCatalogOptions struct {
+	DatabaseOptions
+
+	// one of CatalogRecoveryTruncate or CatalogRecoveryDropNode
+	Action string
+	// the global catalog version to truncate the catalogs to
+	Version int64
+	// the dead node to drop from the catalogs
+	NodeName string
+	// The recovery actions edit the catalogs directly and can lose committed
+	// changes, so they are refused unless Expert is set.
+	Expert bool
+}
+
+func VRecoverCatalogOptionsFactory() VRecoverCatalogOptions {
+	opt := VRecoverCatalogOptions{}
+	opt.setDefaultValues()
+	return opt
+}
+
+func (options *VRecoverCatalogOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandRecoverCatalog, log); err != nil {
+		return err
+	}
+	if !options.Expert {
+		return fmt.Errorf("catalog recovery edits the catalogs directly and can lose committed changes, " +
+			"it must only be run in expert mode under the guidance of support")
+	}
+	if err := util.ValidateRequiredAbsPath(options.CatalogPrefix, "catalog path"); err != nil {
+		return err
+	}
+	switch options.Action {
+	case CatalogRecoveryTruncate:
+		if options.Version <= 0 {
+			return fmt.Errorf("must specify a positive catalog version to truncate the catalog to")
+		}
+	case CatalogRecoveryDropNode:
+		if options.NodeName == "" {
+			return fmt.Errorf("must specify the name of the node to drop from the catalog")
+		}
+	default:
+		return fmt.Errorf("invalid catalog recovery action %q, must be %s or %s", options.Action,
+			CatalogRecoveryTruncate, CatalogRecoveryDropNode)
+	}
+	return options.resolveHosts()
+}
+
+// VRecoverCatalog runs a catalog-level recovery action on the nodes of a down
+// database through the NMA, so that support-driven recovery does not require
+// running server tools by hand on each node. The actions are:
+//   - truncate the catalog of each node to a global catalog version
+//   - force-drop the record of a dead node from the catalog of each other node
+func (vcc VClusterCommands) VRecoverCatalog(options *VRecoverCatalogOptions) (err error) {
+	defer vcc.startAudit("recover_catalog", options)(&err)
+
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	instructions, err := vcc.produceRecoverCatalogInstructions(options)
+	if err != nil {
+		return fmt.Errorf("fail to produce instructions, %w", err)
+	}
+
+	err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, instructions)
+	if err != nil {
+		return fmt.Errorf("fail to recover catalog: %w", err)
+	}
+	return nil
+}
+
+// The generated instructions will later perform the following operations:
+//   - Check NMA connectivity
+//   - Check that the database is down
+//   - Get the catalog path of each node
+//   - Read the catalogs to find the latest one
+//   - Truncate the catalogs or drop the node from them
+func (vcc VClusterCommands) produceRecoverCatalogInstructions(options *VRecoverCatalogOptions) ([]clusterOp, error) {
+	// need username for https operations
+	err := options.setUsePassword(vcc.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	nmaHealthOp := makeNMAHealthOp(options.Hosts)
+	checkDBRunningOp, err := makeHTTPSCheckRunningDBOp(options.Hosts,
+		options.usePassword, options.UserName, options.Password, RecoverCatalog)
+	if err != nil {
+		return nil, err
+	}
+
+	vdb := makeVCoordinationDatabase()
+	nmaGetNodesInfoOp := makeNMAGetNodesInfoOp(options.Hosts, options.DBName, options.CatalogPrefix,
+		false /* report all errors */, &vdb)
+	nmaReadCatalogEditorOp, err := makeNMAReadCatalogEditorOp(&vdb)
+	if err != nil {
+		return nil, err
+	}
+
+	var nmaCatalogRecoveryOp nmaCatalogRecoveryOp
+	if options.Action == CatalogRecoveryTruncate {
+		nmaCatalogRecoveryOp = makeNMATruncateCatalogOp(&vdb, options.Version)
+	} else {
+		nmaCatalogRecoveryOp = makeNMADropNodeRecordOp(&vdb, options.NodeName)
+	}
+
+	return []clusterOp{
+		&nmaHealthOp,
+		&checkDBRunningOp,
+		&nmaGetNodesInfoOp,
+		&nmaReadCatalogEditorOp,
+		&nmaCatalogRecoveryOp,
+	}, nil
+}
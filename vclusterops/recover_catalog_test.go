@@ -0,0 +1,85 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestValidateRecoverCatalogOptions(t *testing.T) {
+	opt := VRecoverCatalogOptionsFactory()
+	opt.DBName = dbName
+	opt.RawHosts = []string{"192.168.1.101"}
+	opt.CatalogPrefix = "/data"
+	opt.Action = CatalogRecoveryTruncate
+	opt.Version = 1024
+
+	// the actions are refused without expert mode
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(vlog.Printer{}), "expert mode")
+	opt.Expert = true
+	assert.NoError(t, opt.validateAnalyzeOptions(vlog.Printer{}))
+
+	opt.Version = 0
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(vlog.Printer{}), "positive catalog version")
+	opt.Action = CatalogRecoveryDropNode
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(vlog.Printer{}), "name of the node to drop")
+	opt.NodeName = "v_test_db_node0003"
+	assert.NoError(t, opt.validateAnalyzeOptions(vlog.Printer{}))
+	opt.Action = "rewrite"
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(vlog.Printer{}), "invalid catalog recovery action")
+}
+
+func TestCatalogRecoveryOp(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Name: "v_test_db_node0001",
+		CatalogPath: "/data/test_db/v_test_db_node0001_catalog"}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{Name: "v_test_db_node0002",
+		CatalogPath: "/data/test_db/v_test_db_node0002_catalog"}
+	execContext := makeOpEngineExecContext(vlog.Printer{})
+	execContext.nmaVDatabase.Versions.Global = "1024"
+	execContext.nmaVDatabase.Nodes = []nmaVNode{{Name: "v_test_db_node0001"}, {Name: "v_test_db_node0002"},
+		{Name: "v_test_db_node0003"}}
+
+	// the catalogs cannot be truncated to a newer version
+	op := makeNMATruncateCatalogOp(&vdb, 2048)
+	assert.ErrorContains(t, op.prepare(&execContext), "the latest version is 1024")
+	op = makeNMATruncateCatalogOp(&vdb, 1024)
+	assert.NoError(t, op.prepare(&execContext))
+	assert.True(t, op.skipExecute)
+
+	op = makeNMATruncateCatalogOp(&vdb, 1000)
+	op.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, op.prepare(&execContext))
+	assert.Equal(t, []string{"192.168.1.101", "192.168.1.102"}, op.hosts)
+	request := op.clusterHTTPRequest.RequestCollection["192.168.1.102"]
+	assert.Contains(t, request.Endpoint, "catalog/truncate")
+	assert.JSONEq(t, `{"catalog_path": "/data/test_db/v_test_db_node0002_catalog", "version": 1000}`, request.RequestData)
+
+	// the node to drop must be in the catalog
+	op = makeNMADropNodeRecordOp(&vdb, "v_test_db_node0009")
+	assert.ErrorContains(t, op.prepare(&execContext), "cannot find node v_test_db_node0009")
+	op = makeNMADropNodeRecordOp(&vdb, "v_test_db_node0003")
+	op.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, op.prepare(&execContext))
+	request = op.clusterHTTPRequest.RequestCollection["192.168.1.101"]
+	assert.Contains(t, request.Endpoint, "catalog/drop-node")
+	assert.JSONEq(t, `{"catalog_path": "/data/test_db/v_test_db_node0001_catalog", "node_name": "v_test_db_node0003"}`,
+		request.RequestData)
+}
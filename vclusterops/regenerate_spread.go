@@ -0,0 +1,170 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// VRegenerateSpreadConfOptions represents the available options for
+// VRegenerateSpreadConf
+type VRegenerateSpreadConfOptions struct {
+	DatabaseOptions
+
+	// time in seconds to wait for user sessions to disconnect before the
+	// database is stopped
+	DrainSeconds int
+	// timeout in seconds for polling the nodes to be up after the restart
+	StatePollingTimeout int
+	// only regenerate and redistribute spread.conf, the database applies it
+	// the next time it is restarted
+	SkipRestart bool
+}
+
+func VRegenerateSpreadConfOptionsFactory() VRegenerateSpreadConfOptions {
+	opt := VRegenerateSpreadConfOptions{}
+	// set default values to the params
+	opt.setDefaultValues()
+
+	return opt
+}
+
+func (options *VRegenerateSpreadConfOptions) setDefaultValues() {
+	options.DatabaseOptions.setDefaultValues()
+	options.DrainSeconds = util.DefaultDrainSeconds
+	options.StatePollingTimeout = util.DefaultStatePollingTimeout
+}
+
+func (options *VRegenerateSpreadConfOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandRegenerateSpread, log); err != nil {
+		return err
+	}
+	if options.DrainSeconds < 0 {
+		return fmt.Errorf("the drain seconds cannot be negative")
+	}
+	return options.resolveHosts()
+}
+
+// VRegenerateSpreadConf writes a new spread.conf after the control nodes or
+// the addresses of the nodes change, and applies it without manual steps:
+//   - a primary up node of the main cluster writes spread.conf from its catalog
+//   - the new spread.conf is sent to all the nodes of the main cluster
+//   - the main cluster is stopped and started again with VRestartDatabase
+//
+// The spread daemons of all the nodes must use the same configuration, and a
+// change of the control nodes is only applied when all of them restart, so the
+// nodes are not restarted one at a time. The down nodes use the new spread.conf
+// when they are started.
+func (vcc VClusterCommands) VRegenerateSpreadConf(options *VRegenerateSpreadConfOptions) (err error) {
+	defer vcc.startAudit("regenerate_spread", options)(&err)
+
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+	err = options.setUsePassword(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	vdb := makeVCoordinationDatabase()
+	err = vcc.getVDBFromRunningDB(&vdb, &options.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	instructions, err := produceRegenerateSpreadInstructions(options, &vdb)
+	if err != nil {
+		return fmt.Errorf("fail to produce instructions, %w", err)
+	}
+	err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, instructions)
+	if err != nil {
+		return fmt.Errorf("fail to regenerate spread.conf: %w", err)
+	}
+
+	if options.SkipRestart {
+		vcc.Log.PrintInfo("spread.conf is regenerated, restart the database to apply it")
+		return nil
+	}
+	vcc.Log.PrintInfo("Restarting the database to apply spread.conf")
+	restartOptions := options.makeRestartOptions()
+	_, err = vcc.VRestartDatabase(&restartOptions)
+	if err != nil {
+		return fmt.Errorf("spread.conf is regenerated, but fail to restart the database to apply it: %w", err)
+	}
+	return nil
+}
+
+// makeRestartOptions returns the options of the restart that applies spread.conf
+func (options *VRegenerateSpreadConfOptions) makeRestartOptions() VRestartDatabaseOptions {
+	restartOptions := VRestartDatabaseOptionsFactory()
+	restartOptions.DatabaseOptions = options.DatabaseOptions
+	drainSeconds := options.DrainSeconds
+	restartOptions.DrainSeconds = &drainSeconds
+	restartOptions.StatePollingTimeout = options.StatePollingTimeout
+	return restartOptions
+}
+
+// produceRegenerateSpreadInstructions will build a list of instructions to execute
+// for regenerating spread.conf.
+//
+// The generated instructions will later perform the following operations:
+//   - Regenerate spread.conf on a primary up node
+//   - Download spread.conf from that node
+//   - Upload spread.conf to the other nodes of the main cluster
+func produceRegenerateSpreadInstructions(options *VRegenerateSpreadConfOptions,
+	vdb *VCoordinationDatabase) ([]clusterOp, error) {
+	sourceHost := ""
+	var mainClusterHosts []string
+	for _, host := range vdb.HostList {
+		vnode, ok := vdb.HostNodeMap[host]
+		if !ok || vnode.Sandbox != util.MainClusterSandbox {
+			continue
+		}
+		mainClusterHosts = append(mainClusterHosts, host)
+		if sourceHost == "" && vnode.IsPrimary && vnode.State == util.NodeUpState {
+			sourceHost = host
+		}
+	}
+	if sourceHost == "" {
+		return nil, fmt.Errorf("could not find any primary up node to regenerate spread.conf")
+	}
+
+	httpsRegenerateSpreadOp, err := makeHTTPSRegenerateSpreadOp([]string{sourceHost}, options.usePassword,
+		options.UserName, options.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// the download op reads spread.conf from the primary up node of the vdb it is given,
+	// so it is given only the node that regenerated spread.conf
+	sourceVDB := vdb.copy([]string{sourceHost})
+	var spreadConfContent string
+	nmaDownloadSpreadConfigOp := makeNMADownloadConfigOp(
+		"NMADownloadSpreadConfigOp", []string{sourceHost}, spreadConf, &spreadConfContent, &sourceVDB)
+	nmaUploadSpreadConfigOp := makeNMAUploadConfigOp(
+		"NMAUploadSpreadConfigOp", []string{sourceHost}, util.SliceDiff(mainClusterHosts, []string{sourceHost}),
+		spreadConf, &spreadConfContent, vdb)
+
+	return []clusterOp{
+		&httpsRegenerateSpreadOp,
+		&nmaDownloadSpreadConfigOp,
+		&nmaUploadSpreadConfigOp,
+	}, nil
+}
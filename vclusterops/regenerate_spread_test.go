@@ -0,0 +1,87 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"golang.org/x/exp/maps"
+)
+
+func TestRegenerateSpreadInstructions(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostList = []string{"192.168.1.101", "192.168.1.102", "192.168.1.103", "192.168.1.104", "192.168.1.105"}
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Name: "v_test_db_node0001", IsPrimary: true,
+		State: util.NodeDownState, Sandbox: util.MainClusterSandbox}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{Name: "v_test_db_node0002", IsPrimary: true,
+		State: util.NodeUpState, Sandbox: util.MainClusterSandbox}
+	vdb.HostNodeMap["192.168.1.103"] = &VCoordinationNode{Name: "v_test_db_node0003", IsPrimary: true,
+		State: util.NodeUpState, Sandbox: util.MainClusterSandbox}
+	vdb.HostNodeMap["192.168.1.104"] = &VCoordinationNode{Name: "v_test_db_node0004",
+		State: util.NodeUpState, Sandbox: util.MainClusterSandbox}
+	vdb.HostNodeMap["192.168.1.105"] = &VCoordinationNode{Name: "v_test_db_node0005",
+		State: util.NodeUpState, Sandbox: "sand"}
+
+	// the first primary up node regenerates spread.conf, which is sent to the
+	// other nodes of the main cluster, up or down
+	options := VRegenerateSpreadConfOptionsFactory()
+	instructions, err := produceRegenerateSpreadInstructions(&options, &vdb)
+	assert.NoError(t, err)
+	assert.Len(t, instructions, 3)
+	regenerateOp := instructions[0].(*httpsRegenerateSpreadOp)
+	assert.Equal(t, []string{"192.168.1.102"}, regenerateOp.hosts)
+	downloadOp := instructions[1].(*nmaDownloadConfigOp)
+	assert.Equal(t, []string{"192.168.1.102"}, maps.Keys(downloadOp.vdb.HostNodeMap))
+	uploadOp := instructions[2].(*nmaUploadConfigOp)
+	assert.Equal(t, []string{"192.168.1.101", "192.168.1.103", "192.168.1.104"}, uploadOp.destHosts)
+
+	vdb.HostNodeMap["192.168.1.102"].State = util.NodeDownState
+	vdb.HostNodeMap["192.168.1.103"].State = util.NodeDownState
+	_, err = produceRegenerateSpreadInstructions(&options, &vdb)
+	assert.ErrorContains(t, err, "could not find any primary up node")
+}
+
+func TestRegenerateSpreadRestartOptions(t *testing.T) {
+	options := VRegenerateSpreadConfOptionsFactory()
+	options.DBName = "test_db"
+	options.DrainSeconds = 30
+	options.StatePollingTimeout = 600
+
+	// the whole main cluster is restarted with the drain and timeout of the command
+	restartOptions := options.makeRestartOptions()
+	assert.Equal(t, "test_db", restartOptions.DBName)
+	assert.Equal(t, 30, *restartOptions.DrainSeconds)
+	assert.Equal(t, 600, restartOptions.StatePollingTimeout)
+	assert.True(t, restartOptions.makeStopOptions().MainCluster)
+}
+
+func TestRegenerateSpreadOp(t *testing.T) {
+	op, err := makeHTTPSRegenerateSpreadOp([]string{"192.168.1.101"}, false, "", nil)
+	assert.NoError(t, err)
+
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, content: `{"detail": "Wrote a new spread.conf"}`},
+	}
+	assert.NoError(t, op.processResult(nil))
+
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, content: `{"detail": "Reloaded"}`},
+	}
+	assert.ErrorContains(t, op.processResult(nil), "should be 'Wrote a new spread.conf'")
+}
@@ -30,6 +30,19 @@ type VRemoveNodeOptions struct {
 	HostsToRemove []string // Hosts to remove from database
 	Initiator     string   // A primary up host that will be used to execute remove_node operations.
 	ForceDelete   bool     // whether force delete directories
+	// Start the rebalance of an Enterprise database in the background, and poll it until
+	// the data is moved away from the nodes to remove, instead of waiting for a single
+	// request that can time out before a long rebalance ends
+	PollRebalance bool
+	// Seconds to poll the rebalance, a negative value polls until it ends
+	RebalanceTimeout int
+	// Receives the percent complete of the rebalance while it is polled, can be nil
+	ProgressReporter ProgressReporter
+	// Keep the catalog, data and depot directories of the removed nodes, so that
+	// the storage can be inspected or reused
+	KeepDirectories bool
+	// The directories of each removed host that are kept, filled in once the nodes are removed
+	KeptDirectories map[string][]string
 }
 
 func VRemoveNodeOptionsFactory() VRemoveNodeOptions {
@@ -44,6 +57,7 @@ func (o *VRemoveNodeOptions) setDefaultValues() {
 	o.DatabaseOptions.setDefaultValues()
 
 	o.ForceDelete = true
+	o.RebalanceTimeout = util.DefaultRebalanceTimeoutSeconds
 }
 
 func (o *VRemoveNodeOptions) validateRequiredOptions(log vlog.Printer) error {
@@ -101,11 +115,13 @@ func (o *VRemoveNodeOptions) validateAnalyzeOptions(log vlog.Printer) error {
 	return o.setUsePassword(log)
 }
 
-func (vcc VClusterCommands) VRemoveNode(options *VRemoveNodeOptions) (VCoordinationDatabase, error) {
+func (vcc VClusterCommands) VRemoveNode(options *VRemoveNodeOptions) (_ VCoordinationDatabase, err error) {
+	defer vcc.startAudit("remove_node", options)(&err)
+
 	vdb := makeVCoordinationDatabase()
 
 	// validate and analyze options
-	err := options.validateAnalyzeOptions(vcc.Log)
+	err = options.validateAnalyzeOptions(vcc.Log)
 	if err != nil {
 		return vdb, err
 	}
@@ -166,7 +182,7 @@ func (vcc VClusterCommands) removeNodesInCatalog(options *VRemoveNodeOptions, vd
 
 	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
-	if runError := clusterOpEngine.run(vcc.Log); runError != nil {
+	if runError := clusterOpEngine.run(vcc.getContext(), vcc.Log); runError != nil {
 		// If the machines of the to-be-removed nodes crashed or get killed,
 		// the run error may be ignored.
 		// Here we check whether the to-be-removed nodes are still in the catalog.
@@ -179,6 +195,10 @@ func (vcc VClusterCommands) removeNodesInCatalog(options *VRemoveNodeOptions, vd
 		vcc.Log.PrintWarning("Nodes have been successfully removed, but encountered the following problems: %v",
 			runError)
 	}
+	if options.KeepDirectories {
+		removedVDB := vdb.copy(options.HostsToRemove)
+		options.addKeptDirectories(&removedVDB)
+	}
 
 	// we return a vdb that contains only the remaining hosts
 	return vdb.copy(remainingHosts), nil
@@ -197,7 +217,7 @@ func (vcc VClusterCommands) handleRemoveNodeForHostsNotInCatalog(vdb *VCoordinat
 	instructions := []clusterOp{&nmaGetNodesInfoOp}
 	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
 	opEng := makeClusterOpEngine(instructions, &certs)
-	err := opEng.run(vcc.Log)
+	err := opEng.run(vcc.getContext(), vcc.Log)
 	if err != nil {
 		return *vdb, fmt.Errorf("failed to get node info for missing hosts: %w", err)
 	}
@@ -210,6 +230,12 @@ func (vcc VClusterCommands) handleRemoveNodeForHostsNotInCatalog(vdb *VCoordinat
 		return *vdb, err
 	}
 
+	if options.KeepDirectories {
+		options.addKeptDirectories(&vdbForDeleteDir)
+		remainingHosts := util.SliceDiff(vdb.HostList, missingHosts)
+		return vdb.copy(remainingHosts), nil
+	}
+
 	// Using the paths fetched earlier, we can now build the list of directories
 	// that the NMA should remove.
 	nmaDeleteDirectoriesOp, err := makeNMADeleteDirectoriesOp(&vdbForDeleteDir, options.ForceDelete)
@@ -218,7 +244,7 @@ func (vcc VClusterCommands) handleRemoveNodeForHostsNotInCatalog(vdb *VCoordinat
 	}
 	instructions = []clusterOp{&nmaDeleteDirectoriesOp}
 	opEng = makeClusterOpEngine(instructions, &certs)
-	err = opEng.run(vcc.Log)
+	err = opEng.run(vcc.getContext(), vcc.Log)
 	if err != nil {
 		return *vdb, fmt.Errorf("failed to delete directories for missing hosts: %w", err)
 	}
@@ -227,6 +253,16 @@ func (vcc VClusterCommands) handleRemoveNodeForHostsNotInCatalog(vdb *VCoordinat
 	return vdb.copy(remainingHosts), nil
 }
 
+// addKeptDirectories records the directories of the removed nodes that are not deleted
+func (o *VRemoveNodeOptions) addKeptDirectories(vdb *VCoordinationDatabase) {
+	if o.KeptDirectories == nil {
+		o.KeptDirectories = make(map[string][]string)
+	}
+	for host, directories := range getKeptDirectories(vdb) {
+		o.KeptDirectories[host] = directories
+	}
+}
+
 // checkRemoveNodeRequirements validates any remove_node requirements. It will
 // return an error if a requirement isn't met.
 func checkRemoveNodeRequirements(vdb *VCoordinationDatabase, options *VRemoveNodeOptions) error {
@@ -253,6 +289,7 @@ func checkRemoveNodeRequirements(vdb *VCoordinationDatabase, options *VRemoveNod
 // from the https endpoints. We set those fields from options.
 func (o *VRemoveNodeOptions) completeVDBSetting(vdb *VCoordinationDatabase) error {
 	vdb.DataPrefix = o.DataPrefix
+	vdb.HostPathPrefixes = o.HostPathPrefixes
 
 	if o.DepotPrefix == "" {
 		return nil
@@ -271,7 +308,7 @@ func (o *VRemoveNodeOptions) completeVDBSetting(vdb *VCoordinationDatabase) erro
 	// TODO: we set the depot path from /nodes rather than manually
 	// (VER-92725). This is useful for nmaDeleteDirectoriesOp.
 	for h, vnode := range vdb.HostNodeMap {
-		vnode.DepotPath = vdb.genDepotPath(vnode.Name)
+		vnode.DepotPath = vdb.genDepotPath(h, vnode.Name)
 		hostNodeMap[h] = vnode
 	}
 	vdb.HostNodeMap = hostNodeMap
@@ -295,12 +332,13 @@ func getMainClusterNodes(vdb *VCoordinationDatabase, options *VRemoveNodeOptions
 // for a successful remove_node:
 //   - Update ksafety if needed
 //   - Mark nodes to remove as ephemeral
-//   - Rebalance cluster for Enterprise mode, rebalance shards for Eon mode
+//   - Rebalance cluster for Enterprise mode, rebalance shards for Eon mode. The rebalance
+//     of the cluster can run in the background while its status is polled.
 //   - Poll subscription state, wait for all subscrptions ACTIVE for Eon mode
 //   - Remove secondary nodes from spread
 //   - Drop Nodes
 //   - Reload spread
-//   - Delete catalog and data directories
+//   - Delete catalog and data directories, unless they are kept
 //   - Sync catalog (eon only)
 func (vcc VClusterCommands) produceRemoveNodeInstructions(vdb *VCoordinationDatabase, options *VRemoveNodeOptions) ([]clusterOp, error) {
 	var instructions []clusterOp
@@ -350,6 +388,12 @@ func (vcc VClusterCommands) produceRemoveNodeInstructions(vdb *VCoordinationData
 			return instructions, e
 		}
 		instructions = append(instructions, &httpsPollSubscriptionStateOp)
+	} else if options.PollRebalance {
+		err = produceRebalanceClusterInBackgroundOps(&instructions, initiatorHost, usePassword, username,
+			password, options.RebalanceTimeout, options.ProgressReporter)
+		if err != nil {
+			return instructions, err
+		}
 	} else {
 		var httpsRBCOp httpsRebalanceClusterOp
 		httpsRBCOp, err = makeHTTPSRebalanceClusterOp(initiatorHost, usePassword, username,
@@ -380,11 +424,13 @@ func (vcc VClusterCommands) produceRemoveNodeInstructions(vdb *VCoordinationData
 	}
 	instructions = append(instructions, &httpsReloadSpreadOp)
 
-	nmaDeleteDirectoriesOp, err := makeNMADeleteDirectoriesOp(&v, options.ForceDelete)
-	if err != nil {
-		return instructions, err
+	if !options.KeepDirectories {
+		nmaDeleteDirectoriesOp, e := makeNMADeleteDirectoriesOp(&v, options.ForceDelete)
+		if e != nil {
+			return instructions, e
+		}
+		instructions = append(instructions, &nmaDeleteDirectoriesOp)
 	}
-	instructions = append(instructions, &nmaDeleteDirectoriesOp)
 
 	if vdb.IsEon {
 		httpsSyncCatalogOp, err := makeHTTPSSyncCatalogOp(initiatorHost, true, username, password, RemoveNodeSyncCat)
@@ -0,0 +1,92 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+func TestRemoveNodePollRebalance(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.Name = "test_db"
+	vdb.HostList = []string{"192.168.1.101", "192.168.1.102", "192.168.1.103", "192.168.1.104"}
+	vdb.HostNodeMap = makeVHostNodeMap()
+	for i, host := range vdb.HostList {
+		vdb.HostNodeMap[host] = &VCoordinationNode{Address: host, Name: fmt.Sprintf("v_test_db_node%04d", i+1),
+			CatalogPath: "/data/test_db/catalog", IsPrimary: true, State: util.NodeUpState}
+	}
+	vcc := VClusterCommands{}
+	options := VRemoveNodeOptionsFactory()
+	options.Initiator = "192.168.1.101"
+	options.HostsToRemove = []string{"192.168.1.104"}
+	options.UserName = "dbadmin"
+
+	// by default, the cluster is rebalanced by a single request
+	instructions, err := vcc.produceRemoveNodeInstructions(&vdb, &options)
+	assert.NoError(t, err)
+	assert.NotEqual(t, -1, findInstruction[*httpsRebalanceClusterOp](instructions))
+	assert.Equal(t, -1, findInstruction[*httpsPollRebalanceStatusOp](instructions))
+
+	// the rebalance is started in the background and polled before the node is dropped
+	options.PollRebalance = true
+	options.RebalanceTimeout = 7200
+	instructions, err = vcc.produceRemoveNodeInstructions(&vdb, &options)
+	assert.NoError(t, err)
+	startIndex := findInstruction[*httpsRebalanceClusterOp](instructions)
+	pollIndex := findInstruction[*httpsPollRebalanceStatusOp](instructions)
+	assert.True(t, instructions[startIndex].(*httpsRebalanceClusterOp).async)
+	assert.Equal(t, startIndex+1, pollIndex)
+	assert.Equal(t, 7200, instructions[pollIndex].(*httpsPollRebalanceStatusOp).getPollingTimeout())
+	assert.Less(t, pollIndex, findInstruction[*httpsDropNodeOp](instructions))
+}
+
+func TestRemoveNodeKeepDirectories(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.Name = "test_db"
+	vdb.IsEon = true
+	vdb.UseDepot = true
+	vdb.HostList = []string{"192.168.1.101", "192.168.1.102", "192.168.1.103", "192.168.1.104"}
+	vdb.HostNodeMap = makeVHostNodeMap()
+	for i, host := range vdb.HostList {
+		name := fmt.Sprintf("v_test_db_node%04d", i+1)
+		vdb.HostNodeMap[host] = &VCoordinationNode{Address: host, Name: name, Subcluster: "sc1",
+			CatalogPath: "/catalog/test_db/" + name + "_catalog", StorageLocations: []string{"/data/test_db/" + name + "_data"},
+			DepotPath: "/depot/test_db/" + name + "_depot", IsPrimary: true, State: util.NodeUpState}
+	}
+	vcc := VClusterCommands{}
+	options := VRemoveNodeOptionsFactory()
+	options.Initiator = "192.168.1.101"
+	options.HostsToRemove = []string{"192.168.1.104"}
+	options.UserName = "dbadmin"
+
+	instructions, err := vcc.produceRemoveNodeInstructions(&vdb, &options)
+	assert.NoError(t, err)
+	assert.NotEqual(t, -1, findInstruction[*nmaDeleteDirectoriesOp](instructions))
+
+	// the directories are not deleted, and are reported
+	options.KeepDirectories = true
+	instructions, err = vcc.produceRemoveNodeInstructions(&vdb, &options)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, findInstruction[*nmaDeleteDirectoriesOp](instructions))
+	removedVDB := vdb.copy(options.HostsToRemove)
+	options.addKeptDirectories(&removedVDB)
+	assert.Equal(t, map[string][]string{"192.168.1.104": {"/catalog/test_db/v_test_db_node0004_catalog",
+		"/data/test_db/v_test_db_node0004_data", "/depot/test_db/v_test_db_node0004_depot"}}, options.KeptDirectories)
+}
@@ -30,6 +30,22 @@ type VRemoveScOptions struct {
 	DatabaseOptions
 	SubclusterToRemove string // subcluster to remove from database
 	ForceDelete        bool   // whether force delete directories
+	// Keep the catalog, data and depot directories of the nodes of the subcluster,
+	// so that the storage can be inspected or reused
+	KeepDirectories bool
+	// The directories of each removed host that are kept, filled in once the subcluster is removed
+	KeptDirectories map[string][]string
+	// Redirect new client connections away from the subcluster, wait for its client
+	// sessions to drain, and stop the subcluster before its nodes are removed
+	Drain bool
+	// seconds to wait for the client sessions to drain, defaults to 60. If the value
+	// is negative, the sessions are waited for until they all disconnect.
+	DrainSeconds int
+	// the subcluster to redirect new connections to, any other subcluster if it is empty
+	RedirectTargetSC string
+	// set once the subcluster is drained: the client sessions that were still
+	// connected when the drain ended, which the shutdown closed
+	ForceClosedSessions []SessionInfo
 }
 
 func VRemoveScOptionsFactory() VRemoveScOptions {
@@ -42,6 +58,7 @@ func VRemoveScOptionsFactory() VRemoveScOptions {
 
 func (o *VRemoveScOptions) setDefaultValues() {
 	o.DatabaseOptions.setDefaultValues()
+	o.DrainSeconds = util.DefaultDrainSeconds
 }
 
 func (o *VRemoveScOptions) validateRequiredOptions(logger vlog.Printer) error {
@@ -88,6 +105,9 @@ func (o *VRemoveScOptions) validateEonOptions() error {
 		return fmt.Errorf(`cannot remove subcluster from an enterprise database '%s'`,
 			o.DBName)
 	}
+	if o.Drain && o.RedirectTargetSC == o.SubclusterToRemove {
+		return fmt.Errorf("cannot redirect connections of subcluster %s to itself", o.SubclusterToRemove)
+	}
 	return nil
 }
 
@@ -118,13 +138,17 @@ func (o *VRemoveScOptions) validateAnalyzeOptions(logger vlog.Printer) error {
 // VRemoveSubcluster removes a subcluster. It returns updated database catalog information and any error encountered.
 // VRemoveSubcluster has three major phases:
 //  1. Pre-check: check the subcluster name and get nodes for the subcluster.
-//  2. Removes nodes: Optional. If there are any nodes still associated with the subcluster, runs VRemoveNode.
-//  3. Drop the subcluster: Remove the subcluster name from the database catalog.
-func (vcc VClusterCommands) VRemoveSubcluster(removeScOpt *VRemoveScOptions) (VCoordinationDatabase, error) {
+//  2. Drain: Optional. If Drain is set and the subcluster is up, redirects new connections away
+//     from the subcluster, waits for its sessions to drain and stops it, with VStopSubcluster.
+//  3. Removes nodes: Optional. If there are any nodes still associated with the subcluster, runs VRemoveNode.
+//  4. Drop the subcluster: Remove the subcluster name from the database catalog.
+func (vcc VClusterCommands) VRemoveSubcluster(removeScOpt *VRemoveScOptions) (_ VCoordinationDatabase, err error) {
+	defer vcc.startAudit("remove_subcluster", removeScOpt)(&err)
+
 	vdb := makeVCoordinationDatabase()
 
 	// validate and analyze options
-	err := removeScOpt.validateAnalyzeOptions(vcc.Log)
+	err = removeScOpt.validateAnalyzeOptions(vcc.Log)
 	if err != nil {
 		return vdb, err
 	}
@@ -148,16 +172,25 @@ func (vcc VClusterCommands) VRemoveSubcluster(removeScOpt *VRemoveScOptions) (VC
 		needRemoveNodes = true
 	}
 
+	if needRemoveNodes && removeScOpt.Drain {
+		err = vcc.drainSubclusterToRemove(&vdb, removeScOpt, hostsToRemove)
+		if err != nil {
+			return vdb, err
+		}
+	}
+
 	if needRemoveNodes {
 		// Remove nodes from the target subcluster
 		removeNodeOpt := VRemoveNodeOptionsFactory()
 		removeNodeOpt.DatabaseOptions = removeScOpt.DatabaseOptions
 		removeNodeOpt.HostsToRemove = hostsToRemove
 		removeNodeOpt.ForceDelete = removeScOpt.ForceDelete
+		removeNodeOpt.KeepDirectories = removeScOpt.KeepDirectories
 
 		vcc.Log.PrintInfo("Removing nodes %q from subcluster %s",
 			hostsToRemove, removeScOpt.SubclusterToRemove)
 		vdb, err = vcc.VRemoveNode(&removeNodeOpt)
+		removeScOpt.KeptDirectories = removeNodeOpt.KeptDirectories
 		if err != nil {
 			return vdb, err
 		}
@@ -173,6 +206,39 @@ func (vcc VClusterCommands) VRemoveSubcluster(removeScOpt *VRemoveScOptions) (VC
 	return vdb, nil
 }
 
+// drainSubclusterToRemove diverts the new connections away from the subcluster,
+// waits for its client sessions to drain, and stops it. The subcluster is not
+// drained if none of its nodes is up.
+func (vcc VClusterCommands) drainSubclusterToRemove(vdb *VCoordinationDatabase, options *VRemoveScOptions,
+	hostsToRemove []string) error {
+	hasUpNode := false
+	for _, host := range hostsToRemove {
+		if vnode, ok := vdb.HostNodeMap[host]; ok && vnode.State == util.NodeUpState {
+			hasUpNode = true
+			break
+		}
+	}
+	if !hasUpNode {
+		vcc.Log.PrintInfo("Subcluster %s is down, skipping the drain", options.SubclusterToRemove)
+		return nil
+	}
+
+	stopScOpt := VStopSubclusterOptionsFactory()
+	stopScOpt.DatabaseOptions = options.DatabaseOptions
+	stopScOpt.SCName = options.SubclusterToRemove
+	stopScOpt.DrainSeconds = options.DrainSeconds
+	stopScOpt.RedirectConnections = true
+	stopScOpt.RedirectTargetSC = options.RedirectTargetSC
+
+	vcc.Log.PrintInfo("Draining subcluster %s before removing it", options.SubclusterToRemove)
+	err := vcc.VStopSubcluster(&stopScOpt)
+	options.ForceClosedSessions = stopScOpt.ForceClosedSessions
+	if err != nil {
+		return fmt.Errorf("fail to drain subcluster %s before removing it, %w", options.SubclusterToRemove, err)
+	}
+	return nil
+}
+
 type removeDefaultSubclusterError struct {
 	Name string
 }
@@ -228,7 +294,7 @@ func (vcc VClusterCommands) removeScPreCheck(vdb *VCoordinationDatabase, options
 
 	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
-	err = clusterOpEngine.run(vcc.Log)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if err != nil {
 		// VER-88585 will improve this rfc error flow
 		if strings.Contains(err.Error(), "does not exist in the database") {
@@ -259,12 +325,13 @@ func (vcc VClusterCommands) removeScPreCheck(vdb *VCoordinationDatabase, options
 func (o *VRemoveScOptions) completeVDBSetting(vdb *VCoordinationDatabase) error {
 	vdb.DataPrefix = o.DataPrefix
 	vdb.DepotPrefix = o.DepotPrefix
+	vdb.HostPathPrefixes = o.HostPathPrefixes
 
 	hostNodeMap := makeVHostNodeMap()
 	// TODO: we set the depot path from /nodes rather than manually
 	// (VER-92725). This is useful for nmaDeleteDirectoriesOp.
 	for h, vnode := range vdb.HostNodeMap {
-		vnode.DepotPath = vdb.genDepotPath(vnode.Name)
+		vnode.DepotPath = vdb.genDepotPath(h, vnode.Name)
 		hostNodeMap[h] = vnode
 	}
 	vdb.HostNodeMap = hostNodeMap
@@ -295,7 +362,7 @@ func (vcc VClusterCommands) dropSubcluster(vdb *VCoordinationDatabase, options *
 
 	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
-	err = clusterOpEngine.run(vcc.Log)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if err != nil {
 		vcc.Log.Error(err, "fail to drop subcluster, details: %v", dropScErrMsg)
 		return err
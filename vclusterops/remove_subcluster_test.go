@@ -56,4 +56,13 @@ func TestRemoveSubcluster(t *testing.T) {
 	options.DepotPrefix = defaultPath
 	err = options.validateParseOptions(vlog.Printer{})
 	assert.NoError(t, err)
+
+	// the connections cannot be redirected to the subcluster that is drained
+	options.Drain = true
+	options.RedirectTargetSC = "sc1"
+	err = options.validateParseOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "cannot redirect connections of subcluster sc1 to itself")
+	options.RedirectTargetSC = ""
+	err = options.validateParseOptions(vlog.Printer{})
+	assert.NoError(t, err)
 }
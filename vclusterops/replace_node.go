@@ -0,0 +1,204 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// VReplaceNodeOptions represents the available options for VReplaceNode.
+type VReplaceNodeOptions struct {
+	DatabaseOptions
+	// The host of the node to replace. The node must be down.
+	OldHost string
+	// The host that replaces the old host. It must not be in the database.
+	NewHost string
+	// Force the clean-up of the directories of the node that already exist on the new host
+	ForceRemoval bool
+	// Delete the directories of the node on the old host once the node is replaced.
+	// The old host is often unreachable after a failure, so the directories are only
+	// deleted if the NMA on the old host can be reached.
+	CleanupOldHost bool
+	// timeout in seconds for polling the node until it is up on the new host
+	StatePollingTimeout int
+}
+
+func VReplaceNodeOptionsFactory() VReplaceNodeOptions {
+	opt := VReplaceNodeOptions{}
+	// set default values to the params
+	opt.setDefaultValues()
+
+	return opt
+}
+
+func (options *VReplaceNodeOptions) setDefaultValues() {
+	options.DatabaseOptions.setDefaultValues()
+
+	options.CleanupOldHost = true
+	options.StatePollingTimeout = util.DefaultStatePollingTimeout
+}
+
+func (options *VReplaceNodeOptions) validateParseOptions(logger vlog.Printer) error {
+	err := options.validateBaseOptions("replace_node", logger)
+	if err != nil {
+		return err
+	}
+	if options.OldHost == "" {
+		return fmt.Errorf("must specify the host of the node to replace")
+	}
+	if options.NewHost == "" {
+		return fmt.Errorf("must specify the host that replaces %s", options.OldHost)
+	}
+	return nil
+}
+
+func (options *VReplaceNodeOptions) analyzeOptions() (err error) {
+	// we analyze host names when it is set in user input, otherwise we use hosts in yaml config
+	if len(options.RawHosts) > 0 {
+		// resolve RawHosts to be IP addresses
+		options.Hosts, err = util.ResolveRawHostsToAddresses(options.RawHosts, options.IPv6)
+		if err != nil {
+			return err
+		}
+		options.normalizePaths()
+	}
+	if options.OldHost, err = util.ResolveToOneIP(options.OldHost, options.IPv6); err != nil {
+		return err
+	}
+	if options.NewHost, err = util.ResolveToOneIP(options.NewHost, options.IPv6); err != nil {
+		return err
+	}
+	if options.OldHost == options.NewHost {
+		return fmt.Errorf("cannot replace host %s with itself", options.OldHost)
+	}
+	return nil
+}
+
+func (options *VReplaceNodeOptions) validateAnalyzeOptions(logger vlog.Printer) error {
+	if err := options.validateParseOptions(logger); err != nil {
+		return err
+	}
+	if err := options.analyzeOptions(); err != nil {
+		return err
+	}
+	return options.setUsePassword(logger)
+}
+
+// VReplaceNode replaces the host of a down node, for example once the machine of
+// the node has failed, without removing the node and adding a new one. It:
+//   - Stages the new host: checks its NMA, and creates the catalog, data and depot
+//     directories of the node on it
+//   - Re-IPs the node to the new host, syncs the config files to it and starts the
+//     node there, with VStartNodes
+//   - Deletes the directories of the node on the old host, if CleanupOldHost is set
+//
+// It returns the VCoordinationDatabase of the database with the new host, and any
+// error encountered.
+func (vcc VClusterCommands) VReplaceNode(options *VReplaceNodeOptions) (_ VCoordinationDatabase, err error) {
+	defer vcc.startAudit("replace_node", options)(&err)
+
+	vdb := makeVCoordinationDatabase()
+
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return vdb, err
+	}
+
+	err = vcc.getVDBFromRunningDB(&vdb, &options.DatabaseOptions)
+	if err != nil {
+		return vdb, err
+	}
+
+	oldNode, err := options.getNodeToReplace(&vdb)
+	if err != nil {
+		return vdb, err
+	}
+	newNode := *oldNode
+	newNode.Address = options.NewHost
+
+	vcc.Log.PrintInfo("Staging node %s on host %s", oldNode.Name, options.NewHost)
+	err = vcc.stageReplacementHost(options, &newNode)
+	if err != nil {
+		return vdb, fmt.Errorf("fail to stage node %s on host %s, %w", oldNode.Name, options.NewHost, err)
+	}
+
+	startNodeOpt := VStartNodesOptionsFactory()
+	startNodeOpt.DatabaseOptions = options.DatabaseOptions
+	startNodeOpt.Nodes = map[string]string{oldNode.Name: options.NewHost}
+	startNodeOpt.StatePollingTimeout = options.StatePollingTimeout
+	vcc.Log.PrintInfo("Starting node %s on host %s", oldNode.Name, options.NewHost)
+	err = vcc.VStartNodes(&startNodeOpt)
+	if err != nil {
+		return vdb, fmt.Errorf("fail to start node %s on host %s, %w", oldNode.Name, options.NewHost, err)
+	}
+
+	if options.CleanupOldHost {
+		vcc.cleanupReplacedHost(options, &vdb)
+	}
+
+	newNode.State = util.NodeUpState
+	vdb.replaceHost(options.OldHost, &newNode)
+	return vdb, nil
+}
+
+// getNodeToReplace returns the node of the old host, which must be down, once it
+// checks that the new host is not in the database
+func (options *VReplaceNodeOptions) getNodeToReplace(vdb *VCoordinationDatabase) (*VCoordinationNode, error) {
+	oldNode, ok := vdb.HostNodeMap[options.OldHost]
+	if !ok {
+		return nil, fmt.Errorf("host %s is not in database %s", options.OldHost, options.DBName)
+	}
+	if oldNode.State == util.NodeUpState {
+		return nil, fmt.Errorf("node %s on host %s is up, only a down node can be replaced", oldNode.Name, options.OldHost)
+	}
+	if oldNode.Sandbox != "" {
+		return nil, fmt.Errorf("node %s on host %s is sandboxed and cannot be replaced", oldNode.Name, options.OldHost)
+	}
+	if newNode, exists := vdb.HostNodeMap[options.NewHost]; exists {
+		return nil, fmt.Errorf("host %s is already in database %s as node %s", options.NewHost, options.DBName, newNode.Name)
+	}
+	return oldNode, nil
+}
+
+// stageReplacementHost checks the NMA of the new host, and creates the directories
+// of the node on it
+func (vcc VClusterCommands) stageReplacementHost(options *VReplaceNodeOptions, newNode *VCoordinationNode) error {
+	nmaHealthOp := makeNMAHealthOp([]string{options.NewHost})
+	nmaPrepareDirectoriesOp, err := makeNMAPrepareDirectoriesOp(vHostNodeMap{options.NewHost: newNode},
+		options.ForceRemoval, false /*for db revive*/)
+	if err != nil {
+		return err
+	}
+	return options.runClusterOpEngine(vcc.getContext(), vcc.Log, []clusterOp{&nmaHealthOp, &nmaPrepareDirectoriesOp})
+}
+
+// cleanupReplacedHost deletes the directories of the node on the old host. The
+// failures are only logged, as the node has already been replaced.
+func (vcc VClusterCommands) cleanupReplacedHost(options *VReplaceNodeOptions, vdb *VCoordinationDatabase) {
+	oldVDB := vdb.copy([]string{options.OldHost})
+	nmaHealthOp := makeNMAHealthOp([]string{options.OldHost})
+	nmaDeleteDirectoriesOp, err := makeNMADeleteDirectoriesOp(&oldVDB, true /*force delete*/)
+	if err == nil {
+		err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, []clusterOp{&nmaHealthOp, &nmaDeleteDirectoriesOp})
+	}
+	if err != nil {
+		vcc.Log.PrintWarning("Node has been replaced, but fail to delete its directories on host %s, details: %v",
+			options.OldHost, err)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestReplaceNode(t *testing.T) {
+	options := VReplaceNodeOptionsFactory()
+	options.DBName = dbName
+	options.RawHosts = []string{"192.168.1.101"}
+	options.OldHost = "192.168.1.102"
+	assert.True(t, options.CleanupOldHost)
+
+	err := options.validateAnalyzeOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "must specify the host that replaces 192.168.1.102")
+	options.NewHost = "192.168.1.102"
+	err = options.validateAnalyzeOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "cannot replace host 192.168.1.102 with itself")
+
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	nodes := []VCoordinationNode{
+		{Name: "v_test_db_node0001", Address: "192.168.1.101", State: util.NodeUpState},
+		{Name: "v_test_db_node0002", Address: "192.168.1.102", State: util.NodeUpState},
+		{Name: "v_test_db_node0003", Address: "192.168.1.103", State: util.NodeDownState},
+	}
+	for i := range nodes {
+		assert.NoError(t, vdb.addNode(&nodes[i]))
+	}
+
+	// only a down node can be replaced, by a host that is not in the database
+	_, err = options.getNodeToReplace(&vdb)
+	assert.ErrorContains(t, err, "node v_test_db_node0002 on host 192.168.1.102 is up")
+	options.OldHost = "192.168.1.103"
+	options.NewHost = "192.168.1.101"
+	_, err = options.getNodeToReplace(&vdb)
+	assert.ErrorContains(t, err, "host 192.168.1.101 is already in database test_db as node v_test_db_node0001")
+	options.NewHost = "192.168.1.104"
+	oldNode, err := options.getNodeToReplace(&vdb)
+	assert.NoError(t, err)
+	assert.Equal(t, "v_test_db_node0003", oldNode.Name)
+
+	// the node keeps its place in the host list
+	newNode := *oldNode
+	newNode.Address = options.NewHost
+	vdb.replaceHost(options.OldHost, &newNode)
+	assert.Equal(t, []string{"192.168.1.101", "192.168.1.102", "192.168.1.104"}, vdb.HostList)
+	assert.NotContains(t, vdb.HostNodeMap, "192.168.1.103")
+	assert.Equal(t, "v_test_db_node0003", vdb.HostNodeMap["192.168.1.104"].Name)
+}
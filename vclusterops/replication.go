@@ -33,12 +33,18 @@ type VReplicationDatabaseOptions struct {
 	TargetUserName  string
 	TargetPassword  *string
 	SourceTLSConfig string
+	// wait for the replication to complete
+	PollCompletion bool
+	// timeout in seconds for polling the replication status, a negative value means no timeout.
+	// It cannot be zero when PollCompletion is set.
+	PollingTimeout int
 }
 
 func VReplicationDatabaseFactory() VReplicationDatabaseOptions {
 	opt := VReplicationDatabaseOptions{}
 	// set default values to the params
 	opt.setDefaultValues()
+	opt.PollingTimeout = util.DefaultTimeoutSeconds
 	return opt
 }
 
@@ -54,6 +60,10 @@ func (opt *VReplicationDatabaseOptions) validateParseOptions(logger vlog.Printer
 	if err != nil {
 		return err
 	}
+	// a zero timeout would fail before the status is polled
+	if opt.PollCompletion && opt.PollingTimeout == 0 {
+		return fmt.Errorf("polling timeout cannot be zero, use a negative value for no timeout")
+	}
 	if len(opt.TargetHosts) == 0 {
 		return fmt.Errorf("must specify a target host or target host list")
 	}
@@ -111,7 +121,9 @@ func (opt *VReplicationDatabaseOptions) validateAnalyzeOptions(logger vlog.Print
 }
 
 // VReplicateDatabase can copy all table data and metadata from this cluster to another
-func (vcc VClusterCommands) VReplicateDatabase(options *VReplicationDatabaseOptions) error {
+func (vcc VClusterCommands) VReplicateDatabase(options *VReplicationDatabaseOptions) (err error) {
+	defer vcc.startAudit("replicate_database", options)(&err)
+
 	/*
 	 *   - Produce Instructions
 	 *   - Create a VClusterOpEngine
@@ -119,7 +131,7 @@ func (vcc VClusterCommands) VReplicateDatabase(options *VReplicationDatabaseOpti
 	 */
 
 	// validate and analyze options
-	err := options.validateAnalyzeOptions(vcc.Log)
+	err = options.validateAnalyzeOptions(vcc.Log)
 	if err != nil {
 		return err
 	}
@@ -135,7 +147,7 @@ func (vcc VClusterCommands) VReplicateDatabase(options *VReplicationDatabaseOpti
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// give the instructions to the VClusterOpEngine to run
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if runError != nil {
 		if strings.Contains(runError.Error(), "EnableConnectCredentialForwarding is false") {
 			runError = fmt.Errorf("target database authentication failed, need to do one of the following things: " +
@@ -154,6 +166,7 @@ func (vcc VClusterCommands) VReplicateDatabase(options *VReplicationDatabaseOpti
 //   - Check NMA connectivity
 //   - Check Vertica versions
 //   - Replicate database
+//   - Poll the replication status until it completes (optional)
 func (vcc VClusterCommands) produceDBReplicationInstructions(options *VReplicationDatabaseOptions) ([]clusterOp, error) {
 	var instructions []clusterOp
 
@@ -202,5 +215,14 @@ func (vcc VClusterCommands) produceDBReplicationInstructions(options *VReplicati
 		&nmaVerticaVersionOp,
 		&httpsStartReplicationOp,
 	)
+
+	if options.PollCompletion {
+		httpsPollReplicationStatusOp, err := makeHTTPSPollReplicationStatusOp(options.DBName, options.Hosts,
+			options.usePassword, options.UserName, options.Password, options.PollingTimeout)
+		if err != nil {
+			return instructions, err
+		}
+		instructions = append(instructions, &httpsPollReplicationStatusOp)
+	}
 	return instructions, nil
 }
@@ -0,0 +1,225 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// the phases of a restart reported to a ProgressReporter
+const (
+	RestartPhaseStop     = "stop"
+	RestartPhaseStart    = "start"
+	RestartPhaseRollback = "rollback"
+)
+
+// VRestartDatabaseOptions are the options of VRestartDatabase, which stops the
+// main cluster of a database and starts it again with the same options
+type VRestartDatabaseOptions struct {
+	DatabaseOptions
+
+	/* stop options */
+	DrainSeconds *int // time in seconds to wait for database users' disconnection, Eon only
+	// the seconds between the polls of the client sessions that are still
+	// connected while they drain, 0 disables the polls
+	SessionPollingSeconds int
+	// whether the data in memory is moved out to disk before the database
+	// is stopped, so that it starts faster
+	Moveout bool
+
+	/* start options */
+	// timeout for polling the states of all nodes in the database
+	StatePollingTimeout int
+	// what the start waits for before the database is considered restarted
+	WaitPolicy WaitPolicy
+	// If the path is set, the NMA will store the Vertica start command at the
+	// path instead of executing it
+	StartUpConf string
+
+	// receives the phases of the restart as they run, are done or fail, and
+	// the sessions of each node while they drain
+	ProgressReporter ProgressReporter
+}
+
+// RestartDBStopError is returned when the stop phase of a restart fails. The
+// nodes that were stopped are started again, the database is left running
+// unless the rollback fails too.
+type RestartDBStopError struct {
+	StopErr     error
+	RollbackErr error
+}
+
+func (e *RestartDBStopError) Error() string {
+	if e.RollbackErr != nil {
+		return fmt.Sprintf("fail to stop the database: %s, and fail to start it again: %s", e.StopErr, e.RollbackErr)
+	}
+	return fmt.Sprintf("fail to stop the database, it is still running: %s", e.StopErr)
+}
+
+func (e *RestartDBStopError) Unwrap() []error {
+	return []error{e.StopErr, e.RollbackErr}
+}
+
+func VRestartDatabaseOptionsFactory() VRestartDatabaseOptions {
+	opt := VRestartDatabaseOptions{}
+	// set default values to the params
+	opt.setDefaultValues()
+
+	return opt
+}
+
+func (options *VRestartDatabaseOptions) setDefaultValues() {
+	options.DatabaseOptions.setDefaultValues()
+	options.SessionPollingSeconds = util.DefaultSessionPollingSeconds
+	options.StatePollingTimeout = util.DefaultStatePollingTimeout
+	options.WaitPolicy = WaitForUp
+}
+
+func (options *VRestartDatabaseOptions) validateParseOptions(log vlog.Printer) error {
+	err := options.validateBaseOptions("restart_db", log)
+	if err != nil {
+		return err
+	}
+	return options.WaitPolicy.validate()
+}
+
+// makeStopOptions returns the options of the stop phase, which only stops the
+// main cluster, as the sandboxes are not started again
+func (options *VRestartDatabaseOptions) makeStopOptions() VStopDatabaseOptions {
+	stopOptions := VStopDatabaseOptionsFactory()
+	stopOptions.DatabaseOptions = options.DatabaseOptions
+	stopOptions.DrainSeconds = options.DrainSeconds
+	stopOptions.SessionPollingSeconds = options.SessionPollingSeconds
+	stopOptions.Moveout = options.Moveout
+	stopOptions.MainCluster = true
+	stopOptions.ProgressReporter = options.ProgressReporter
+	return stopOptions
+}
+
+func (options *VRestartDatabaseOptions) makeStartOptions() VStartDatabaseOptions {
+	startOptions := VStartDatabaseOptionsFactory()
+	startOptions.DatabaseOptions = options.DatabaseOptions
+	startOptions.StatePollingTimeout = options.StatePollingTimeout
+	startOptions.WaitPolicy = options.WaitPolicy
+	startOptions.StartUpConf = options.StartUpConf
+	return startOptions
+}
+
+// VRestartDatabase stops the main cluster of a database, draining the sessions
+// and syncing the catalog, and then starts it again. If the stop fails, the
+// nodes that were up before the stop are started again, so that the database
+// is left running, and a RestartDBStopError is returned.
+func (vcc VClusterCommands) VRestartDatabase(options *VRestartDatabaseOptions) (vdbPtr *VCoordinationDatabase, err error) {
+	defer vcc.startAudit("restart_db", options)(&err)
+
+	err = options.validateParseOptions(vcc.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	// the nodes that are up before the stop are the ones started again if the stop fails
+	vdb := makeVCoordinationDatabase()
+	err = vcc.getVDBFromRunningDB(&vdb, &options.DatabaseOptions)
+	if err != nil {
+		return nil, fmt.Errorf("fail to find the up nodes of the database before stopping it: %w", err)
+	}
+	upHosts := getMainClusterHostsInState(&vdb, util.NodeUpState)
+
+	tracker := makeProgressTracker(options.ProgressReporter, 2 /*stop and start*/)
+
+	// each phase validates and analyzes its own copy of the options
+	tracker.report("", RestartPhaseStop, ProgressStageRunning, 0)
+	stopOptions := options.makeStopOptions()
+	stopErr := vcc.VStopDatabase(&stopOptions)
+	if stopErr != nil {
+		tracker.report("", RestartPhaseStop, ProgressStageFailed, 0)
+		vcc.Log.PrintWarning("fail to stop the database, starting the stopped nodes again")
+		tracker.report("", RestartPhaseRollback, ProgressStageRunning, 0)
+		rollbackErr := vcc.startStoppedNodes(options, upHosts)
+		if rollbackErr != nil {
+			tracker.report("", RestartPhaseRollback, ProgressStageFailed, 0)
+		} else {
+			tracker.report("", RestartPhaseRollback, ProgressStageDone, 0)
+		}
+		return nil, &RestartDBStopError{StopErr: stopErr, RollbackErr: rollbackErr}
+	}
+	tracker.report("", RestartPhaseStop, ProgressStageDone, 0)
+
+	tracker.report("", RestartPhaseStart, ProgressStageRunning, 0)
+	startOptions := options.makeStartOptions()
+	vdbPtr, err = vcc.VStartDatabase(&startOptions)
+	if err != nil {
+		tracker.report("", RestartPhaseStart, ProgressStageFailed, 0)
+		return nil, fmt.Errorf("fail to start the database after it was stopped: %w", err)
+	}
+	tracker.report("", RestartPhaseStart, ProgressStageDone, 0)
+	return vdbPtr, nil
+}
+
+// startStoppedNodes brings the database back to running after the stop phase
+// of a restart fails: the hosts that were up before the stop and are down are
+// restarted if the database is still up, otherwise the database is started on
+// the hosts that were up
+func (vcc VClusterCommands) startStoppedNodes(options *VRestartDatabaseOptions, upHosts []string) error {
+	vdb := makeVCoordinationDatabase()
+	err := vcc.getVDBFromRunningDB(&vdb, &options.DatabaseOptions)
+	if err != nil {
+		vcc.Log.Info("cannot get the running database, starting it", "error", err, "hosts", upHosts)
+		startOptions := options.makeStartOptions()
+		startOptions.RawHosts = upHosts
+		startOptions.Hosts = upHosts
+		_, err = vcc.VStartDatabase(&startOptions)
+		return err
+	}
+
+	stoppedHosts := getStoppedHosts(&vdb, upHosts)
+	if len(stoppedHosts) == 0 {
+		return nil
+	}
+	startNodesOptions := VStartNodesOptionsFactory()
+	startNodesOptions.DatabaseOptions = options.DatabaseOptions
+	startNodesOptions.StartHosts = stoppedHosts
+	startNodesOptions.StatePollingTimeout = options.StatePollingTimeout
+	startNodesOptions.WaitPolicy = options.WaitPolicy
+	startNodesOptions.StartUpConf = options.StartUpConf
+	err = vcc.VStartNodes(&startNodesOptions)
+	if err != nil {
+		return errors.Join(fmt.Errorf("fail to restart the nodes on hosts %v", stoppedHosts), err)
+	}
+	return nil
+}
+
+// getStoppedHosts returns the hosts that were up before the stop, and whose
+// nodes are down now. The nodes that were already down stay down.
+func getStoppedHosts(vdb *VCoordinationDatabase, upHosts []string) []string {
+	return util.SliceCommon(upHosts, getMainClusterHostsInState(vdb, util.NodeDownState))
+}
+
+// getMainClusterHostsInState returns the hosts of the nodes of the main cluster in a state
+func getMainClusterHostsInState(vdb *VCoordinationDatabase, state string) []string {
+	var hosts []string
+	for _, host := range vdb.HostList {
+		vnode, ok := vdb.HostNodeMap[host]
+		if ok && vnode.Sandbox == util.MainClusterSandbox && vnode.State == state {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
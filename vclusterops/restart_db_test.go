@@ -0,0 +1,78 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+func TestRestartDBOptions(t *testing.T) {
+	options := VRestartDatabaseOptionsFactory()
+	options.DBName = "test_db"
+	options.RawHosts = []string{"192.168.1.101", "192.168.1.102"}
+	options.DrainSeconds = new(int)
+	*options.DrainSeconds = 30
+	options.Moveout = true
+	options.WaitPolicy = WaitForConnections
+	options.ProgressReporter = &mockProgressReporter{}
+
+	// the stop phase only stops the main cluster
+	stopOptions := options.makeStopOptions()
+	assert.Equal(t, options.RawHosts, stopOptions.RawHosts)
+	assert.Equal(t, 30, *stopOptions.DrainSeconds)
+	assert.True(t, stopOptions.Moveout)
+	assert.True(t, stopOptions.MainCluster)
+	assert.Equal(t, options.ProgressReporter, stopOptions.ProgressReporter)
+
+	startOptions := options.makeStartOptions()
+	assert.Equal(t, options.RawHosts, startOptions.RawHosts)
+	assert.Equal(t, WaitForConnections, startOptions.WaitPolicy)
+	assert.Equal(t, util.DefaultStatePollingTimeout, startOptions.StatePollingTimeout)
+
+	// the restart is not run with invalid options
+	options.WaitPolicy = "running"
+	_, err := VClusterCommands{}.VRestartDatabase(&options)
+	assert.ErrorContains(t, err, "invalid wait policy")
+}
+
+func TestRestartDBStopError(t *testing.T) {
+	stopErr := errors.New("drain timed out")
+	err := &RestartDBStopError{StopErr: stopErr}
+	assert.ErrorIs(t, err, stopErr)
+	assert.Contains(t, err.Error(), "it is still running")
+
+	rollbackErr := errors.New("node did not come up")
+	err = &RestartDBStopError{StopErr: stopErr, RollbackErr: rollbackErr}
+	assert.ErrorIs(t, err, rollbackErr)
+	assert.Contains(t, err.Error(), "fail to start it again")
+
+	// only the nodes of the main cluster that were up before the stop are started again
+	vdb := makeVCoordinationDatabase()
+	vdb.HostList = []string{"192.168.1.101", "192.168.1.102", "192.168.1.103", "192.168.1.104"}
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{State: util.NodeUpState}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{State: util.NodeDownState}
+	vdb.HostNodeMap["192.168.1.103"] = &VCoordinationNode{State: util.NodeDownState, Sandbox: "sb1"}
+	vdb.HostNodeMap["192.168.1.104"] = &VCoordinationNode{State: util.NodeDownState}
+	assert.Equal(t, []string{"192.168.1.101"}, getMainClusterHostsInState(&vdb, util.NodeUpState))
+	// the node on 192.168.1.104 was already down before the stop
+	upHosts := []string{"192.168.1.101", "192.168.1.102"}
+	assert.Equal(t, []string{"192.168.1.102"}, getStoppedHosts(&vdb, upHosts))
+}
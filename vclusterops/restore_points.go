@@ -166,7 +166,7 @@ func (vcc VClusterCommands) VShowRestorePoints(options *VShowRestorePointsOption
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// give the instructions to the VClusterOpEngine to run
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if runError != nil {
 		return restorePoints, fmt.Errorf("fail to show restore points: %w", runError)
 	}
@@ -16,9 +16,13 @@
 package vclusterops
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/vertica/vcluster/vclusterops/util"
 )
@@ -39,6 +43,17 @@ type VReviveDatabaseOptions struct {
 	IgnoreClusterLease bool
 	// the restore policy
 	RestorePoint RestorePointPolicy
+	// maps the address of each node in the catalog to its new host, which
+	// must be in the hosts. Without it, the new hosts are given to the nodes
+	// in the order of the node names.
+	HostMapping map[string]string
+	// the AWS credentials of an S3 communal storage besides the awsauth parameter
+	AWSAuthOptions
+	// the Azure credentials of an Azure communal storage instead of the
+	// AzureStorageCredentials parameter
+	AzureAuthOptions
+	// the GCS credentials of a GCS communal storage besides the GCSAuth parameter
+	GCSAuthOptions
 }
 
 type RestorePointPolicy struct {
@@ -141,7 +156,23 @@ func (options *VReviveDatabaseOptions) validateRequiredOptions() error {
 	}
 
 	// communal storage
-	return util.ValidateCommunalStorageLocation(options.CommunalStorageLocation)
+	err = util.ValidateCommunalStorageLocation(options.CommunalStorageLocation)
+	if err != nil {
+		return err
+	}
+	err = validateCommunalStorageParameters(options.CommunalStorageLocation, options.ConfigurationParameters)
+	if err != nil {
+		return err
+	}
+	err = options.AWSAuthOptions.validate(options.CommunalStorageLocation, options.ConfigurationParameters)
+	if err != nil {
+		return err
+	}
+	err = options.AzureAuthOptions.validate(options.CommunalStorageLocation, options.ConfigurationParameters)
+	if err != nil {
+		return err
+	}
+	return options.GCSAuthOptions.validate(options.CommunalStorageLocation, options.ConfigurationParameters)
 }
 
 func (options *VReviveDatabaseOptions) validateRestoreOptions() error {
@@ -178,7 +209,25 @@ func (options *VReviveDatabaseOptions) analyzeOptions() (err error) {
 		}
 	}
 
-	return nil
+	// resolve the new hosts of the host mapping to be IP addresses
+	for oldAddress, newHost := range options.HostMapping {
+		addresses, e := util.ResolveRawHostsToAddresses([]string{newHost}, options.IPv6)
+		if e != nil {
+			return e
+		}
+		options.HostMapping[oldAddress] = addresses[0]
+	}
+
+	options.ConfigurationParameters, err = options.AzureAuthOptions.addConfigurationParameters(options.ConfigurationParameters)
+	if err != nil {
+		return err
+	}
+	options.ConfigurationParameters, err = options.GCSAuthOptions.addConfigurationParameters(options.ConfigurationParameters)
+	if err != nil {
+		return err
+	}
+
+	return options.analyzeHostPathPrefixes(options.Hosts)
 }
 
 func (options *VReviveDatabaseOptions) validateAnalyzeOptions() error {
@@ -191,6 +240,8 @@ func (options *VReviveDatabaseOptions) validateAnalyzeOptions() error {
 // VReviveDatabase revives a database that was terminated but whose communal storage data still exists.
 // It returns the database information retrieved from communal storage and any error encountered.
 func (vcc VClusterCommands) VReviveDatabase(options *VReviveDatabaseOptions) (dbInfo string, vdbPtr *VCoordinationDatabase, err error) {
+	defer vcc.startAudit("revive_db", options)(&err)
+
 	/*
 	 *   - Validate options
 	 *   - Run VClusterOpEngine to get terminated database info
@@ -204,6 +255,10 @@ func (vcc VClusterCommands) VReviveDatabase(options *VReviveDatabaseOptions) (db
 	}
 
 	vdb := makeVCoordinationDatabase()
+	err = vdb.setAWSAuth(&options.AWSAuthOptions)
+	if err != nil {
+		return dbInfo, nil, err
+	}
 
 	// part 1: produce instructions for getting terminated database info, and save the info to vdb
 	preReviveDBInstructions, err := vcc.producePreReviveDBInstructions(options, &vdb)
@@ -215,7 +270,7 @@ func (vcc VClusterCommands) VReviveDatabase(options *VReviveDatabaseOptions) (db
 	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
 	// feed the pre-revive db instructions to the VClusterOpEngine
 	clusterOpEngine := makeClusterOpEngine(preReviveDBInstructions, &certs)
-	err = clusterOpEngine.run(vcc.GetLog())
+	err = clusterOpEngine.run(vcc.getContext(), vcc.GetLog())
 	if err != nil {
 		return dbInfo, nil, fmt.Errorf("fail to collect the information of database in revive_db %w", err)
 	}
@@ -233,7 +288,7 @@ func (vcc VClusterCommands) VReviveDatabase(options *VReviveDatabaseOptions) (db
 
 		// feed the restore db specific instructions to the VClusterOpEngine
 		clusterOpEngine = makeClusterOpEngine(restoreDBSpecificInstructions, &certs)
-		runErr := clusterOpEngine.run(vcc.GetLog())
+		runErr := clusterOpEngine.run(vcc.getContext(), vcc.GetLog())
 		if runErr != nil {
 			return dbInfo, &vdb, fmt.Errorf("fail to collect the restore-specific information of database in revive_db %w", runErr)
 		}
@@ -252,7 +307,7 @@ func (vcc VClusterCommands) VReviveDatabase(options *VReviveDatabaseOptions) (db
 
 	// feed revive db instructions to the VClusterOpEngine
 	clusterOpEngine = makeClusterOpEngine(reviveDBInstructions, &certs)
-	err = clusterOpEngine.run(vcc.GetLog())
+	err = clusterOpEngine.run(vcc.getContext(), vcc.GetLog())
 	if err != nil {
 		return dbInfo, &vdb, fmt.Errorf("fail to revive database %w", err)
 	}
@@ -294,6 +349,14 @@ func (vcc VClusterCommands) producePreReviveDBInstructions(options *VReviveDatab
 		&checkDBRunningOp,
 	)
 
+	// the communal storage is checked before the description file is downloaded
+	checkCommunalStorageOps, err := produceCommunalStorageCheckOps(options.Hosts, options.CommunalStorageLocation,
+		options.ConfigurationParameters, vdb, options.GCSAuthMethod, true /*for revive*/)
+	if err != nil {
+		return instructions, err
+	}
+	instructions = append(instructions, checkCommunalStorageOps...)
+
 	// use current description file path as source file path
 	currConfigFileSrcPath := options.getCurrConfigFilePath()
 
@@ -372,7 +435,8 @@ func (vcc VClusterCommands) produceRestoreDBSpecificInstructions(options *VReviv
 // The generated instructions will later perform the following operations
 //   - Prepare database directories for all the hosts
 //   - Get network profiles for all the hosts
-//   - Load remote catalog from communal storage on all the hosts
+//   - Load remote catalog from communal storage on all the hosts, re-IPing
+//     the nodes to their new hosts
 func (vcc VClusterCommands) produceReviveDBInstructions(options *VReviveDatabaseOptions, vdb *VCoordinationDatabase) ([]clusterOp, error) {
 	var instructions []clusterOp
 
@@ -380,6 +444,12 @@ func (vcc VClusterCommands) produceReviveDBInstructions(options *VReviveDatabase
 	if err != nil {
 		return instructions, err
 	}
+	// the nodes are re-IPed to their new hosts when the remote catalog is loaded
+	for index, newHost := range newVDB.HostList {
+		if oldHosts[index] != newHost {
+			vcc.Log.PrintInfo("Node %s is moved from %s to %s", newVDB.HostNodeMap[newHost].Name, oldHosts[index], newHost)
+		}
+	}
 
 	// create a new HostNodeMap to prepare directories
 	hostNodeMap := makeVHostNodeMap()
@@ -427,6 +497,10 @@ func (options *VReviveDatabaseOptions) generateReviveVDB(vdb *VCoordinationDatab
 	newVDB = makeVCoordinationDatabase()
 	newVDB.Name = options.DBName
 	newVDB.CommunalStorageLocation = options.CommunalStorageLocation
+	err = newVDB.setAWSAuth(&options.AWSAuthOptions)
+	if err != nil {
+		return newVDB, oldHosts, err
+	}
 	// use new cluster hosts
 	newVDB.HostList = options.Hosts
 
@@ -459,12 +533,111 @@ func (options *VReviveDatabaseOptions) generateReviveVDB(vdb *VCoordinationDatab
 	if len(newVDB.HostList) != len(vNodes) {
 		return newVDB, oldHosts, fmt.Errorf("the number of new hosts does not match the number of nodes in original database")
 	}
+	if len(options.HostMapping) > 0 {
+		vNodes, err = options.lineUpNodesWithHostMapping(vNodes)
+		if err != nil {
+			return newVDB, oldHosts, err
+		}
+	}
 	for index, newHost := range newVDB.HostList {
 		// recreate the old host list with new hosts' order
 		oldHosts = append(oldHosts, vNodes[index].Address)
 		vNodes[index].Address = newHost
+		options.applyHostPathPrefixes(vNodes[index])
 		newVDB.HostNodeMap[newHost] = vNodes[index]
 	}
 
 	return newVDB, oldHosts, nil
 }
+
+// lineUpNodesWithHostMapping orders the nodes like the new hosts that the
+// host mapping gives them
+func (options *VReviveDatabaseOptions) lineUpNodesWithHostMapping(vNodes []*VCoordinationNode) ([]*VCoordinationNode, error) {
+	hostIndex := make(map[string]int, len(options.Hosts))
+	for index, host := range options.Hosts {
+		hostIndex[host] = index
+	}
+	linedUpNodes := make([]*VCoordinationNode, len(options.Hosts))
+	for _, vnode := range vNodes {
+		newHost, ok := options.HostMapping[vnode.Address]
+		if !ok {
+			return nil, fmt.Errorf("the host mapping does not have the address %s of node %s", vnode.Address, vnode.Name)
+		}
+		index, ok := hostIndex[newHost]
+		if !ok {
+			return nil, fmt.Errorf("the new host %s of node %s in the host mapping is not in the hosts", newHost, vnode.Name)
+		}
+		if linedUpNodes[index] != nil {
+			return nil, fmt.Errorf("the new host %s is mapped to both node %s and node %s",
+				newHost, linedUpNodes[index].Name, vnode.Name)
+		}
+		linedUpNodes[index] = vnode
+	}
+	return linedUpNodes, nil
+}
+
+// ReadHostMappingFile reads the host mapping from a file in the format of the
+// re-ip file, in which from_address is the address of a node in the catalog and
+// to_address is its new host
+func (options *VReviveDatabaseOptions) ReadHostMappingFile(path string) error {
+	if err := util.AbsPathCheck(path); err != nil {
+		return fmt.Errorf("must specify an absolute path for the host mapping file")
+	}
+
+	var rows []reIPRow
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("fail to read the host mapping file %s, details: %w", path, err)
+	}
+	err = json.Unmarshal(fileBytes, &rows)
+	if err != nil {
+		return fmt.Errorf("fail to unmarshal the host mapping file, details: %w", err)
+	}
+
+	options.HostMapping = make(map[string]string, len(rows))
+	for _, row := range rows {
+		if row.CurrentAddress == "" || row.NewAddress == "" {
+			return fmt.Errorf("each row of the host mapping file must have a from_address and a to_address")
+		}
+		options.HostMapping[row.CurrentAddress] = row.NewAddress
+	}
+	return nil
+}
+
+// applyHostPathPrefixes moves the catalog, data and depot paths of a revived node
+// under the path prefixes given for its new host. User storage locations are kept.
+func (options *VReviveDatabaseOptions) applyHostPathPrefixes(vnode *VCoordinationNode) {
+	prefixes, ok := options.HostPathPrefixes[vnode.Address]
+	if !ok {
+		return
+	}
+	userLocations := make(map[string]struct{}, len(vnode.UserStorageLocations))
+	for _, location := range vnode.UserStorageLocations {
+		userLocations[location] = struct{}{}
+	}
+
+	vnode.CatalogPath = replaceDBPathPrefix(vnode.CatalogPath, options.DBName, prefixes.CatalogPrefix)
+	for i, location := range vnode.StorageLocations {
+		if _, isUserLocation := userLocations[location]; isUserLocation {
+			continue
+		}
+		vnode.StorageLocations[i] = replaceDBPathPrefix(location, options.DBName, prefixes.DataPrefix)
+	}
+	vnode.DepotPath = replaceDBPathPrefix(vnode.DepotPath, options.DBName, prefixes.DepotPrefix)
+}
+
+// replaceDBPathPrefix replaces the part of a path before the database directory, e.g.,
+// /data/test_db/v_test_db_node0001_data becomes /disk2/test_db/v_test_db_node0001_data
+// with the new prefix /disk2. The path is returned unchanged if the new prefix is empty
+// or the path is not under a database directory.
+func replaceDBPathPrefix(path, dbName, newPrefix string) string {
+	if newPrefix == "" {
+		return path
+	}
+	dbDir := "/" + dbName + "/"
+	index := strings.Index(path, dbDir)
+	if index < 0 {
+		return path
+	}
+	return filepath.Join(newPrefix, path[index+1:])
+}
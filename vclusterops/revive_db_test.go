@@ -2,6 +2,8 @@ package vclusterops
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -54,3 +56,69 @@ func TestFindSpecifiedRestorePoint(t *testing.T) {
 	expectedErr = &ReviveDBRestorePointNotFoundError{Archive: "archive3", InvalidID: "id3"}
 	assert.EqualError(t, err, expectedErr.Error())
 }
+
+func TestReviveHostPathPrefixes(t *testing.T) {
+	options := VReviveDBOptionsFactory()
+	options.DBName = "test_db"
+	options.HostPathPrefixes = map[string]HostPathPrefixes{
+		"10.1.10.2": {CatalogPrefix: "/catalog", DataPrefix: "/disk2"},
+	}
+	vnode := VCoordinationNode{
+		Address:     "10.1.10.2",
+		CatalogPath: "/data/test_db/v_test_db_node0001_catalog/Catalog",
+		StorageLocations: []string{"/data/test_db/v_test_db_node0001_data",
+			"/home/dbadmin/test_db/user_location"},
+		UserStorageLocations: []string{"/home/dbadmin/test_db/user_location"},
+		DepotPath:            "/depot/test_db/v_test_db_node0001_depot",
+	}
+	options.applyHostPathPrefixes(&vnode)
+	assert.Equal(t, "/catalog/test_db/v_test_db_node0001_catalog/Catalog", vnode.CatalogPath)
+	// user storage locations are not moved
+	assert.Equal(t, []string{"/disk2/test_db/v_test_db_node0001_data",
+		"/home/dbadmin/test_db/user_location"}, vnode.StorageLocations)
+	assert.Equal(t, "/depot/test_db/v_test_db_node0001_depot", vnode.DepotPath)
+}
+
+func TestReviveHostMapping(t *testing.T) {
+	options := VReviveDBOptionsFactory()
+	options.DBName = "test_db"
+	options.Hosts = []string{"10.1.10.1", "10.1.10.2"}
+	makeOldVDB := func() *VCoordinationDatabase {
+		vdb := makeVCoordinationDatabase()
+		vdb.HostNodeMap = makeVHostNodeMap()
+		vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Name: "v_test_db_node0001", Address: "192.168.1.101"}
+		vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{Name: "v_test_db_node0002", Address: "192.168.1.102"}
+		return &vdb
+	}
+
+	// the hosts are given in the order of the node names by default
+	newVDB, oldHosts, err := options.generateReviveVDB(makeOldVDB())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.101", "192.168.1.102"}, oldHosts)
+	assert.Equal(t, "v_test_db_node0001", newVDB.HostNodeMap["10.1.10.1"].Name)
+
+	// the host mapping gives each node its host
+	options.HostMapping = map[string]string{"192.168.1.101": "10.1.10.2", "192.168.1.102": "10.1.10.1"}
+	newVDB, oldHosts, err = options.generateReviveVDB(makeOldVDB())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.102", "192.168.1.101"}, oldHosts)
+	assert.Equal(t, "v_test_db_node0001", newVDB.HostNodeMap["10.1.10.2"].Name)
+
+	options.HostMapping = map[string]string{"192.168.1.101": "10.1.10.2", "192.168.1.102": "10.1.10.2"}
+	_, _, err = options.generateReviveVDB(makeOldVDB())
+	assert.ErrorContains(t, err, "is mapped to both node")
+
+	options.HostMapping = map[string]string{"192.168.1.101": "10.1.10.2", "192.168.1.102": "10.1.10.3"}
+	_, _, err = options.generateReviveVDB(makeOldVDB())
+	assert.ErrorContains(t, err, "is not in the hosts")
+
+	options.HostMapping = map[string]string{"192.168.1.101": "10.1.10.2"}
+	_, _, err = options.generateReviveVDB(makeOldVDB())
+	assert.ErrorContains(t, err, "does not have the address 192.168.1.102")
+
+	// the file has the format of the re-ip file
+	path := filepath.Join(t.TempDir(), "host_mapping.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`[{"from_address": "192.168.1.101", "to_address": "10.1.10.2"}]`), 0600))
+	assert.NoError(t, options.ReadHostMappingFile(path))
+	assert.Equal(t, map[string]string{"192.168.1.101": "10.1.10.2"}, options.HostMapping)
+}
@@ -0,0 +1,280 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+	"golang.org/x/exp/slices"
+)
+
+type VRotateNMACertsOptions struct {
+	/* part 1: basic db info */
+	// Key, Cert and CaCert in DatabaseOptions are the certs currently in use
+	DatabaseOptions
+
+	/* part 2: new certs info */
+	NewKey    string
+	NewCert   string
+	NewCaCert string
+	// whether the certs of the HTTPS service are also rotated, defaults to true
+	RotateHTTPSCerts bool
+	// timeout in seconds for polling a service to be back with the new certs
+	PollingTimeout int
+}
+
+func VRotateNMACertsOptionsFactory() VRotateNMACertsOptions {
+	opt := VRotateNMACertsOptions{}
+	// set default values to the params
+	opt.setDefaultValues()
+
+	return opt
+}
+
+func (options *VRotateNMACertsOptions) setDefaultValues() {
+	options.DatabaseOptions.setDefaultValues()
+	options.RotateHTTPSCerts = true
+	options.PollingTimeout = util.DefaultTimeoutSeconds
+}
+
+func (options *VRotateNMACertsOptions) validateParseOptions(log vlog.Printer) error {
+	err := options.validateBaseOptions(commandRotateCerts, log)
+	if err != nil {
+		return err
+	}
+
+	if options.NewKey == "" || options.NewCert == "" {
+		return fmt.Errorf("must specify the new key and certificate")
+	}
+	// check the new certs before pushing them to any host
+	_, err = tls.X509KeyPair([]byte(options.NewCert), []byte(options.NewKey))
+	if err != nil {
+		return fmt.Errorf("the new key and certificate are not a valid pair, details: %w", err)
+	}
+	if options.PollingTimeout < 0 {
+		return fmt.Errorf("polling timeout cannot be negative")
+	}
+
+	return nil
+}
+
+// resolve hostnames to be IPs
+func (options *VRotateNMACertsOptions) analyzeOptions() (err error) {
+	if len(options.RawHosts) > 0 {
+		options.Hosts, err = util.ResolveRawHostsToAddresses(options.RawHosts, options.IPv6)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (options *VRotateNMACertsOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateParseOptions(log); err != nil {
+		return err
+	}
+	return options.analyzeOptions()
+}
+
+// certsRotation tracks the hosts whose services have new certs
+// so that they can be rolled back on failure
+type certsRotation struct {
+	options    *VRotateNMACertsOptions
+	oldCerts   httpsCerts
+	newCerts   httpsCerts
+	nmaHosts   []string
+	httpsHosts []string
+	// runOps runs the instructions of one step of the rotation with the given certs
+	runOps func(instructions []clusterOp, certs *httpsCerts) error
+}
+
+func (vcc VClusterCommands) makeCertsRotation(options *VRotateNMACertsOptions) certsRotation {
+	return certsRotation{
+		options:  options,
+		oldCerts: httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert},
+		newCerts: httpsCerts{key: options.NewKey, cert: options.NewCert, caCert: options.NewCaCert},
+		runOps: func(instructions []clusterOp, certs *httpsCerts) error {
+			clusterOpEngine := makeClusterOpEngine(instructions, certs)
+			return clusterOpEngine.run(vcc.getContext(), vcc.Log)
+		},
+	}
+}
+
+// VRotateNMACerts pushes new TLS certs to the NMA on all hosts and, optionally, to the
+// HTTPS service on all up hosts. The services are rotated one host at a time: a host
+// gets the new certs, restarts the service, and is checked to accept connections with
+// the new certs before the next host is rotated. The previous certs are discarded only
+// after all hosts are validated. On failure, the hosts whose certs were replaced are
+// rolled back to the previous certs one host at a time.
+func (vcc VClusterCommands) VRotateNMACerts(options *VRotateNMACertsOptions) (err error) {
+	defer vcc.startAudit("rotate_nma_certs", options)(&err)
+
+	// validate and analyze all options
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	vdb := makeVCoordinationDatabase()
+	err = vcc.getVDBFromRunningDB(&vdb, &options.DatabaseOptions)
+	if err != nil {
+		return err
+	}
+
+	var allHosts, upHosts []string
+	for host, vnode := range vdb.HostNodeMap {
+		allHosts = append(allHosts, host)
+		if vnode.State == util.NodeUpState {
+			upHosts = append(upHosts, host)
+		}
+	}
+	sort.Strings(allHosts)
+	sort.Strings(upHosts)
+
+	rotation := vcc.makeCertsRotation(options)
+	return vcc.rotateCerts(&rotation, allHosts, upHosts)
+}
+
+// rotateCerts rotates the NMA certs on allHosts, then the HTTPS certs on upHosts
+func (vcc VClusterCommands) rotateCerts(rotation *certsRotation, allHosts, upHosts []string) error {
+	options := rotation.options
+	for _, host := range allHosts {
+		err := vcc.rotateNMACertsOnHost(rotation, host)
+		if err != nil {
+			return vcc.rollbackCerts(rotation, fmt.Errorf("fail to rotate NMA certs on host %s: %w", host, err))
+		}
+	}
+	if options.RotateHTTPSCerts {
+		for _, host := range upHosts {
+			err := vcc.rotateHTTPSCertsOnHost(rotation, host)
+			if err != nil {
+				return vcc.rollbackCerts(rotation, fmt.Errorf("fail to rotate HTTPS certs on host %s: %w", host, err))
+			}
+		}
+	}
+
+	return vcc.discardPreviousCerts(rotation)
+}
+
+// rotateNMACertsOnHost replaces the NMA certs on a host with the new certs,
+// then polls the NMA with the new certs
+func (vcc VClusterCommands) rotateNMACertsOnHost(rotation *certsRotation, host string) error {
+	vcc.Log.PrintInfo("Rotating NMA certs on host %s", host)
+	hosts := []string{host}
+	nmaReplaceCertsOp := makeNMAReplaceCertsOp(hosts, &rotation.newCerts)
+	err := rotation.runOps([]clusterOp{&nmaReplaceCertsOp}, &rotation.oldCerts)
+	if err != nil {
+		return err
+	}
+	// the host has the new certs from now on, so it is rolled back on failure
+	rotation.nmaHosts = append(rotation.nmaHosts, host)
+
+	nmaPollHealthOp := makeNMAPollHealthOp(hosts, rotation.options.PollingTimeout)
+	return rotation.runOps([]clusterOp{&nmaPollHealthOp}, &rotation.newCerts)
+}
+
+// rotateHTTPSCertsOnHost replaces the HTTPS service certs on a host,
+// then polls the node state with the new certs
+func (vcc VClusterCommands) rotateHTTPSCertsOnHost(rotation *certsRotation, host string) error {
+	vcc.Log.PrintInfo("Rotating HTTPS certs on host %s", host)
+	options := rotation.options
+	hosts := []string{host}
+	httpsReplaceCertsOp, err := makeHTTPSReplaceCertsOp(hosts, &rotation.newCerts,
+		options.usePassword, options.UserName, options.Password)
+	if err != nil {
+		return err
+	}
+	err = rotation.runOps([]clusterOp{&httpsReplaceCertsOp}, &rotation.oldCerts)
+	if err != nil {
+		return err
+	}
+	rotation.httpsHosts = append(rotation.httpsHosts, host)
+
+	httpsPollNodeStateOp, err := makeHTTPSPollNodeStateOpWithTimeoutAndCommand(hosts,
+		options.usePassword, options.UserName, options.Password, options.PollingTimeout, RotateCertsCmd)
+	if err != nil {
+		return err
+	}
+	return rotation.runOps([]clusterOp{&httpsPollNodeStateOp}, &rotation.newCerts)
+}
+
+// rollbackCerts restores the previous certs on the rotated hosts one host at a time,
+// so that a host failing to roll back does not stop the rollback of the others. It
+// returns the rotation error joined with the hosts that failed to roll back.
+func (vcc VClusterCommands) rollbackCerts(rotation *certsRotation, rotateErr error) error {
+	vcc.Log.PrintWarning("Rolling back certs on NMA hosts %v and HTTPS hosts %v", rotation.nmaHosts, rotation.httpsHosts)
+	options := rotation.options
+	var failedHosts []string
+	var rollbackErrs []error
+	// the services of the rotated hosts only accept the new certs
+	for _, host := range rotation.httpsHosts {
+		httpsRollbackCertsOp, err := makeHTTPSRollbackCertsOp([]string{host},
+			options.usePassword, options.UserName, options.Password)
+		if err == nil {
+			err = rotation.runOps([]clusterOp{&httpsRollbackCertsOp}, &rotation.newCerts)
+		}
+		if err != nil {
+			failedHosts = append(failedHosts, host)
+			rollbackErrs = append(rollbackErrs, fmt.Errorf("fail to roll back HTTPS certs on host %s: %w", host, err))
+		}
+	}
+	for _, host := range rotation.nmaHosts {
+		nmaRollbackCertsOp := makeNMARollbackCertsOp([]string{host})
+		err := rotation.runOps([]clusterOp{&nmaRollbackCertsOp}, &rotation.newCerts)
+		if err != nil {
+			failedHosts = append(failedHosts, host)
+			rollbackErrs = append(rollbackErrs, fmt.Errorf("fail to roll back NMA certs on host %s: %w", host, err))
+		}
+	}
+
+	if len(rollbackErrs) > 0 {
+		slices.Sort(failedHosts)
+		failedHosts = slices.Compact(failedHosts)
+		return errors.Join(rotateErr, fmt.Errorf("fail to roll back certs on hosts %v, their previous certs "+
+			"must be restored manually: %w", failedHosts, errors.Join(rollbackErrs...)))
+	}
+	return rotateErr
+}
+
+// discardPreviousCerts discards the previous certs on all hosts once the
+// new certs are validated
+func (vcc VClusterCommands) discardPreviousCerts(rotation *certsRotation) error {
+	options := rotation.options
+	var instructions []clusterOp
+	nmaDiscardCertsOp := makeNMADiscardCertsOp(rotation.nmaHosts)
+	instructions = append(instructions, &nmaDiscardCertsOp)
+	if len(rotation.httpsHosts) > 0 {
+		httpsDiscardCertsOp, err := makeHTTPSDiscardCertsOp(rotation.httpsHosts,
+			options.usePassword, options.UserName, options.Password)
+		if err != nil {
+			return err
+		}
+		instructions = append(instructions, &httpsDiscardCertsOp)
+	}
+
+	err := rotation.runOps(instructions, &rotation.newCerts)
+	if err != nil {
+		// the new certs are in use, so this does not fail the rotation
+		vcc.Log.PrintWarning("fail to discard previous certs, details: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestRotateNMACertsValidateOptions(t *testing.T) {
+	options := VRotateNMACertsOptionsFactory()
+	options.DBName = "test_db"
+	options.RawHosts = []string{"192.168.1.101"}
+
+	// the new key and cert are required
+	err := options.validateParseOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "must specify the new key and certificate")
+
+	// the new key and cert must be a valid pair
+	options.NewKey = "key"
+	options.NewCert = "cert"
+	err = options.validateParseOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "the new key and certificate are not a valid pair")
+}
+
+func TestRotateCertsPartialFailure(t *testing.T) {
+	vcc := VClusterCommands{}
+	options := VRotateNMACertsOptionsFactory()
+	allHosts := []string{"host1", "host2", "host3"}
+	upHosts := []string{"host1", "host2", "host3"}
+
+	// runs the steps of a rotation, failing the ones listed in failedSteps,
+	// and records the steps that were run
+	var steps []string
+	makeRotation := func(failedSteps ...string) certsRotation {
+		steps = nil
+		rotation := vcc.makeCertsRotation(&options)
+		rotation.runOps = func(instructions []clusterOp, _ *httpsCerts) error {
+			for _, instruction := range instructions {
+				var hosts []string
+				switch op := instruction.(type) {
+				case *nmaReplaceCertsOp:
+					hosts = op.hosts
+				case *httpsReplaceCertsOp:
+					hosts = op.hosts
+				case *nmaPollHealthOp:
+					hosts = op.hosts
+				case *httpsPollNodeStateOp:
+					hosts = op.hosts
+				}
+				step := fmt.Sprintf("%s %v", instruction.getName(), hosts)
+				steps = append(steps, step)
+				for _, failedStep := range failedSteps {
+					if step == failedStep {
+						return errors.New("request failed")
+					}
+				}
+			}
+			return nil
+		}
+		return rotation
+	}
+
+	// the host whose NMA certs failed to be replaced is not rolled back
+	rotation := makeRotation("NMAReplaceCertsOp [host2]")
+	err := vcc.rotateCerts(&rotation, allHosts, upHosts)
+	assert.ErrorContains(t, err, "fail to rotate NMA certs on host host2")
+	assert.Equal(t, []string{"host1"}, rotation.nmaHosts)
+	assert.Equal(t, []string{
+		"NMAReplaceCertsOp [host1]",
+		"NMAPollHealthOp [host1]",
+		"NMAReplaceCertsOp [host2]",
+		"NMARollbackCertsOp [host1]",
+	}, steps)
+
+	// a host whose certs were replaced but failed the poll is rolled back, and a host
+	// failing to roll back does not stop the rollback of the other hosts
+	rotation = makeRotation("HTTPSPollNodeStateOp [host2]", "NMARollbackCertsOp [host1]")
+	err = vcc.rotateCerts(&rotation, allHosts, upHosts)
+	assert.ErrorContains(t, err, "fail to rotate HTTPS certs on host host2")
+	assert.ErrorContains(t, err, "fail to roll back certs on hosts [host1]")
+	assert.Equal(t, []string{"host1", "host2"}, rotation.httpsHosts)
+	assert.Subset(t, steps, []string{
+		"HTTPSRollbackCertsOp [host1]",
+		"HTTPSRollbackCertsOp [host2]",
+		"NMARollbackCertsOp [host1]",
+		"NMARollbackCertsOp [host2]",
+		"NMARollbackCertsOp [host3]",
+	})
+	assert.NotContains(t, steps, "HTTPSReplaceCertsOp [host3]")
+}
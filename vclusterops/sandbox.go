@@ -150,7 +150,9 @@ func (vcc *VClusterCommands) produceSandboxSubclusterInstructions(options *VSand
 	return instructions, nil
 }
 
-func (vcc VClusterCommands) VSandbox(options *VSandboxOptions) error {
+func (vcc VClusterCommands) VSandbox(options *VSandboxOptions) (err error) {
+	defer vcc.startAudit("sandbox_subcluster", options)(&err)
+
 	vcc.Log.V(0).Info("VSandbox method called", "options", options)
 	return runSandboxCmd(vcc, options)
 }
@@ -175,7 +177,7 @@ func (options *VSandboxOptions) runCommand(vcc VClusterCommands) error {
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// run the engine
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if runError != nil {
 		return fmt.Errorf("fail to sandbox subcluster %s, %w", options.SCName, runError)
 	}
@@ -0,0 +1,298 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// VScaleSubclusterOptions represents the available options for VScaleSubcluster.
+type VScaleSubclusterOptions struct {
+	DatabaseOptions
+	// Name of the subcluster to scale
+	SCName string
+	// The number of nodes that the subcluster has once it is scaled
+	TargetSize int
+	// The hosts that can be added to the subcluster, in order of preference. The hosts
+	// that are already in the database or whose NMA cannot be reached are skipped.
+	CandidateHosts []string
+	// whether to force delete the directories of the removed nodes
+	ForceDelete bool
+	// Depot size of the added nodes, with two supported formats: % and KMGT, e.g., 50% or 10G.
+	// The default depot size of Vertica is used if it is empty.
+	DepotSize string
+	// Skip rebalance shards of the subcluster once the nodes are added
+	SkipRebalanceShards bool
+	// Skip copying the UDx libraries of the database to the added nodes
+	SkipLibrarySync bool
+	// If the path is set, the NMA will store the Vertica start command of the
+	// added nodes at the path instead of executing it
+	StartUpConf string
+	// The hosts that are added to and removed from the subcluster, filled in once
+	// the subcluster is scaled
+	AddedHosts   []string
+	RemovedHosts []string
+}
+
+func VScaleSubclusterOptionsFactory() VScaleSubclusterOptions {
+	opt := VScaleSubclusterOptions{}
+	// set default values to the params
+	opt.setDefaultValues()
+
+	return opt
+}
+
+func (options *VScaleSubclusterOptions) setDefaultValues() {
+	options.DatabaseOptions.setDefaultValues()
+
+	options.ForceDelete = true
+}
+
+func (options *VScaleSubclusterOptions) validateParseOptions(logger vlog.Printer) error {
+	err := options.validateBaseOptions("scale_subcluster", logger)
+	if err != nil {
+		return err
+	}
+	if options.SCName == "" {
+		return fmt.Errorf("must specify a subcluster name")
+	}
+	if options.TargetSize < 0 {
+		return fmt.Errorf("the target size of subcluster %s cannot be negative", options.SCName)
+	}
+	return nil
+}
+
+func (options *VScaleSubclusterOptions) analyzeOptions() (err error) {
+	// we analyze host names when it is set in user input, otherwise we use hosts in yaml config
+	if len(options.RawHosts) > 0 {
+		// resolve RawHosts to be IP addresses
+		options.Hosts, err = util.ResolveRawHostsToAddresses(options.RawHosts, options.IPv6)
+		if err != nil {
+			return err
+		}
+		options.normalizePaths()
+	}
+	options.CandidateHosts, err = util.ResolveRawHostsToAddresses(options.CandidateHosts, options.IPv6)
+	return err
+}
+
+func (options *VScaleSubclusterOptions) validateAnalyzeOptions(logger vlog.Printer) error {
+	if err := options.validateParseOptions(logger); err != nil {
+		return err
+	}
+	if err := options.analyzeOptions(); err != nil {
+		return err
+	}
+	return options.setUsePassword(logger)
+}
+
+// VScaleSubcluster scales a subcluster to a target number of nodes. It compares the
+// target size with the nodes of the subcluster in the running database, and adds
+// nodes on the candidate hosts or removes nodes from the subcluster accordingly:
+//   - When scaling up, the candidate hosts that are already in the database or whose
+//     NMA cannot be reached are skipped, and the first of the remaining hosts are added.
+//   - When scaling down, the DOWN nodes are removed first, then the nodes that were
+//     added last.
+//
+// It does nothing if the subcluster already has the target size, so that an autoscaler
+// can call it with the size it wants. It returns the VCoordinationDatabase of the
+// database once the subcluster is scaled, and any error encountered.
+func (vcc VClusterCommands) VScaleSubcluster(options *VScaleSubclusterOptions) (_ VCoordinationDatabase, err error) {
+	defer vcc.startAudit("scale_subcluster", options)(&err)
+
+	vdb := makeVCoordinationDatabase()
+
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return vdb, err
+	}
+
+	err = vcc.getVDBFromRunningDB(&vdb, &options.DatabaseOptions)
+	if err != nil {
+		return vdb, err
+	}
+
+	addCount, candidates, hostsToRemove, err := options.planScaling(&vdb)
+	if err != nil {
+		return vdb, err
+	}
+	switch {
+	case addCount > 0:
+		return vcc.scaleUpSubcluster(&vdb, options, addCount, candidates)
+	case len(hostsToRemove) > 0:
+		return vcc.scaleDownSubcluster(options, hostsToRemove)
+	}
+	vcc.Log.PrintInfo("Subcluster %s already has %d nodes", options.SCName, options.TargetSize)
+	return vdb, nil
+}
+
+// planScaling compares the target size with the nodes of the subcluster. It returns
+// the number of nodes to add with the candidate hosts that are not in the database,
+// or the hosts of the nodes to remove. It returns an error if the subcluster does
+// not exist, or if a primary subcluster would be emptied or lose the primary quorum.
+func (options *VScaleSubclusterOptions) planScaling(vdb *VCoordinationDatabase) (addCount int,
+	candidates, hostsToRemove []string, err error) {
+	var members []*VCoordinationNode
+	for _, vnode := range vdb.HostNodeMap {
+		if vnode.Subcluster == options.SCName {
+			members = append(members, vnode)
+		}
+	}
+	if len(members) == 0 {
+		return 0, nil, nil, fmt.Errorf("subcluster %s does not exist in the database", options.SCName)
+	}
+
+	if options.TargetSize > len(members) {
+		for _, host := range options.CandidateHosts {
+			if _, exist := vdb.HostNodeMap[host]; !exist && !util.StringInArray(host, candidates) {
+				candidates = append(candidates, host)
+			}
+		}
+		return options.TargetSize - len(members), candidates, nil, nil
+	}
+
+	// the DOWN nodes are removed first, then the nodes with the largest names,
+	// which are the nodes that were added last
+	sort.Slice(members, func(i, j int) bool {
+		iDown := members[i].State == util.NodeDownState
+		jDown := members[j].State == util.NodeDownState
+		if iDown != jDown {
+			return iDown
+		}
+		return members[i].Name > members[j].Name
+	})
+	nodesToRemove := members[:len(members)-options.TargetSize]
+	if members[0].IsPrimary {
+		err = checkPrimaryScaleDown(vdb, options.SCName, options.TargetSize, nodesToRemove)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+	}
+	for _, vnode := range nodesToRemove {
+		hostsToRemove = append(hostsToRemove, vnode.Address)
+	}
+	return 0, nil, hostsToRemove, nil
+}
+
+// checkPrimaryScaleDown returns an error if a primary subcluster would have no nodes
+// left, or if the primary nodes that remain would not have quorum
+func checkPrimaryScaleDown(vdb *VCoordinationDatabase, scName string, targetSize int,
+	nodesToRemove []*VCoordinationNode) error {
+	if targetSize == 0 {
+		return fmt.Errorf("cannot scale the primary subcluster %s to 0 nodes", scName)
+	}
+	if len(nodesToRemove) == 0 {
+		return nil
+	}
+
+	sandbox := nodesToRemove[0].Sandbox
+	var primaryNodeCount, upPrimaryNodeCount uint
+	for _, vnode := range vdb.HostNodeMap {
+		if !vnode.IsPrimary || vnode.Sandbox != sandbox {
+			continue
+		}
+		primaryNodeCount++
+		if vnode.State == util.NodeUpState {
+			upPrimaryNodeCount++
+		}
+	}
+	for _, vnode := range nodesToRemove {
+		primaryNodeCount--
+		if vnode.State == util.NodeUpState {
+			upPrimaryNodeCount--
+		}
+	}
+	if !hasNodeUpQuorum(upPrimaryNodeCount, primaryNodeCount) {
+		return &QuorumError{Detail: fmt.Sprintf("cannot scale the primary subcluster %s to %d nodes, "+
+			"the remaining %d of %d primary nodes would not have quorum",
+			scName, targetSize, upPrimaryNodeCount, primaryNodeCount)}
+	}
+	return nil
+}
+
+// scaleUpSubcluster adds nodes to the subcluster on the first candidate hosts that
+// are provisioned, i.e., whose NMA can be reached. The given vdb is returned if no
+// node is added.
+func (vcc VClusterCommands) scaleUpSubcluster(vdb *VCoordinationDatabase, options *VScaleSubclusterOptions,
+	addCount int, candidates []string) (VCoordinationDatabase, error) {
+	provisionedHosts := vcc.getProvisionedHosts(&options.DatabaseOptions, candidates)
+	if len(provisionedHosts) < addCount {
+		return *vdb, fmt.Errorf("fail to scale subcluster %s to %d nodes: %d more hosts are needed, "+
+			"but only %d candidate hosts are provisioned", options.SCName, options.TargetSize, addCount, len(provisionedHosts))
+	}
+
+	addNodeOpt := VAddNodeOptionsFactory()
+	addNodeOpt.DatabaseOptions = options.DatabaseOptions
+	addNodeOpt.NewHosts = provisionedHosts[:addCount]
+	addNodeOpt.SCName = options.SCName
+	addNodeOpt.DepotSize = options.DepotSize
+	*addNodeOpt.SkipRebalanceShards = options.SkipRebalanceShards
+	addNodeOpt.SkipLibrarySync = options.SkipLibrarySync
+	addNodeOpt.StartUpConf = options.StartUpConf
+
+	vcc.Log.PrintInfo("Adding hosts %v to subcluster %s", addNodeOpt.NewHosts, options.SCName)
+	addedVDB, err := vcc.VAddNode(&addNodeOpt)
+	if err != nil {
+		return *vdb, fmt.Errorf("fail to scale subcluster %s to %d nodes, %w", options.SCName, options.TargetSize, err)
+	}
+	options.AddedHosts = addNodeOpt.NewHosts
+	return addedVDB, nil
+}
+
+// scaleDownSubcluster removes the given nodes from the subcluster
+func (vcc VClusterCommands) scaleDownSubcluster(options *VScaleSubclusterOptions,
+	hostsToRemove []string) (VCoordinationDatabase, error) {
+	removeNodeOpt := VRemoveNodeOptionsFactory()
+	removeNodeOpt.DatabaseOptions = options.DatabaseOptions
+	removeNodeOpt.HostsToRemove = hostsToRemove
+	removeNodeOpt.ForceDelete = options.ForceDelete
+
+	vcc.Log.PrintInfo("Removing hosts %v from subcluster %s", hostsToRemove, options.SCName)
+	vdb, err := vcc.VRemoveNode(&removeNodeOpt)
+	if err != nil {
+		return vdb, fmt.Errorf("fail to scale subcluster %s to %d nodes, %w", options.SCName, options.TargetSize, err)
+	}
+	options.RemovedHosts = hostsToRemove
+	return vdb, nil
+}
+
+// getProvisionedHosts returns the hosts whose NMA can be reached, in their given order
+func (vcc VClusterCommands) getProvisionedHosts(options *DatabaseOptions, hosts []string) []string {
+	if len(hosts) == 0 {
+		return nil
+	}
+	nmaHealthOp := makeNMAHealthOp(hosts)
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+	clusterOpEngine := makeClusterOpEngine([]clusterOp{&nmaHealthOp}, &certs)
+	if err := clusterOpEngine.run(vcc.getContext(), vcc.Log); err == nil {
+		return hosts
+	}
+
+	var provisionedHosts []string
+	for _, host := range hosts {
+		result, ok := nmaHealthOp.clusterHTTPRequest.ResultCollection[host]
+		if ok && result.isPassing() {
+			provisionedHosts = append(provisionedHosts, host)
+		} else {
+			vcc.Log.PrintWarning("Skipping candidate host %s, the NMA on the host cannot be reached", host)
+		}
+	}
+	return provisionedHosts
+}
@@ -0,0 +1,111 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestScaleSubclusterPlan(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	nodes := []VCoordinationNode{
+		{Name: "v_test_db_node0001", Address: "192.168.1.101", Subcluster: "default_subcluster", State: util.NodeUpState},
+		{Name: "v_test_db_node0002", Address: "192.168.1.102", Subcluster: "sc1", State: util.NodeUpState},
+		{Name: "v_test_db_node0003", Address: "192.168.1.103", Subcluster: "sc1", State: util.NodeDownState},
+		{Name: "v_test_db_node0004", Address: "192.168.1.104", Subcluster: "sc1", State: util.NodeUpState},
+	}
+	for i := range nodes {
+		assert.NoError(t, vdb.addNode(&nodes[i]))
+	}
+
+	options := VScaleSubclusterOptionsFactory()
+	options.SCName = "sc1"
+	options.CandidateHosts = []string{"192.168.1.101", "192.168.1.105", "192.168.1.105", "192.168.1.106"}
+
+	// the hosts that are already in the database are not candidates
+	options.TargetSize = 4
+	addCount, candidates, hostsToRemove, err := options.planScaling(&vdb)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, addCount)
+	assert.Equal(t, []string{"192.168.1.105", "192.168.1.106"}, candidates)
+	assert.Empty(t, hostsToRemove)
+
+	// nothing to do at the current size
+	options.TargetSize = 3
+	addCount, _, hostsToRemove, err = options.planScaling(&vdb)
+	assert.NoError(t, err)
+	assert.Zero(t, addCount)
+	assert.Empty(t, hostsToRemove)
+
+	// the DOWN node is removed first, then the node added last
+	options.TargetSize = 1
+	addCount, _, hostsToRemove, err = options.planScaling(&vdb)
+	assert.NoError(t, err)
+	assert.Zero(t, addCount)
+	assert.Equal(t, []string{"192.168.1.103", "192.168.1.104"}, hostsToRemove)
+
+	// a subcluster without nodes does not exist
+	options.SCName = "sc2"
+	_, _, _, err = options.planScaling(&vdb)
+	assert.ErrorContains(t, err, "subcluster sc2 does not exist in the database")
+
+	options.SCName = "sc1"
+	options.TargetSize = -1
+	options.DBName = "test_db"
+	options.RawHosts = []string{"192.168.1.101"}
+	err = options.validateParseOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "the target size of subcluster sc1 cannot be negative")
+}
+
+func TestScaleSubclusterPrimaryQuorum(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	nodes := []VCoordinationNode{
+		{Name: "v_test_db_node0001", Address: "192.168.1.101", Subcluster: "sc1", IsPrimary: true, State: util.NodeUpState},
+		{Name: "v_test_db_node0002", Address: "192.168.1.102", Subcluster: "sc1", IsPrimary: true, State: util.NodeUpState},
+		{Name: "v_test_db_node0003", Address: "192.168.1.103", Subcluster: "sc1", IsPrimary: true, State: util.NodeUpState},
+		{Name: "v_test_db_node0004", Address: "192.168.1.104", Subcluster: "sc2", IsPrimary: true, State: util.NodeDownState},
+		{Name: "v_test_db_node0005", Address: "192.168.1.105", Subcluster: "sc2", IsPrimary: true, State: util.NodeDownState},
+	}
+	for i := range nodes {
+		assert.NoError(t, vdb.addNode(&nodes[i]))
+	}
+	options := VScaleSubclusterOptionsFactory()
+	options.SCName = "sc1"
+
+	// a primary subcluster cannot be emptied
+	options.TargetSize = 0
+	_, _, _, err := options.planScaling(&vdb)
+	assert.ErrorContains(t, err, "cannot scale the primary subcluster sc1 to 0 nodes")
+
+	// 2 of 4 primary nodes would be up
+	options.TargetSize = 2
+	_, _, _, err = options.planScaling(&vdb)
+	var quorumErr *QuorumError
+	assert.ErrorAs(t, err, &quorumErr)
+
+	// the DOWN primary nodes can be removed
+	options.SCName = "sc2"
+	options.TargetSize = 1
+	_, _, hostsToRemove, err := options.planScaling(&vdb)
+	assert.NoError(t, err)
+	assert.Len(t, hostsToRemove, 1)
+}
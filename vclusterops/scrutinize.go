@@ -16,10 +16,12 @@
 package vclusterops
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/vertica/vcluster/vclusterops/util"
@@ -38,6 +40,7 @@ const scrutinizeLogFileName = "vcluster.log"
 const ScrutinizeLogMaxAgeHoursDefault = 24              // copy archived logs produced in most recent 24 hours
 const scrutinizeLogLimitBytes = 10 * 1024 * 1024 * 1024 // 10GB in bytes is the limit for individual log size
 const scrutinizeFileLimitBytes = 100 * 1024 * 1024      // 100 MB in bytes is the limit for individual misc file size
+const scrutinizeBytesPerMB = 1024 * 1024
 
 // batches are fixed, top level folders for each node's data
 const scrutinizeBatchNormal = "normal"
@@ -45,9 +48,79 @@ const scrutinizeBatchContext = "context"
 const scrutinizeBatchSystemTables = "system_tables"
 const scrutinizeSuffixSystemTables = "systables"
 
+// the batches of data that users can choose to collect with IncludeBatches
+// and ExcludeBatches. The config files and the results of diagnostic commands
+// are always collected.
+const (
+	ScrutinizeBatchVerticaLogs  = "vertica_logs"
+	ScrutinizeBatchNMALogs      = "nma_logs"
+	ScrutinizeBatchSystemTables = "system_tables"
+	ScrutinizeBatchDCTables     = "dc_tables"
+	ScrutinizeBatchCoreFiles    = "core_files"
+	// a small set of system tables that support asks for first, see
+	// scrutinizeCuratedSystemTables. It is much faster to collect than
+	// all system tables.
+	ScrutinizeBatchCuratedSystemTables = "curated_system_tables"
+)
+
+// ScrutinizeSelectableBatches are all batches that can be included or excluded
+var ScrutinizeSelectableBatches = []string{ScrutinizeBatchVerticaLogs, ScrutinizeBatchNMALogs,
+	ScrutinizeBatchSystemTables, ScrutinizeBatchDCTables, ScrutinizeBatchCoreFiles,
+	ScrutinizeBatchCuratedSystemTables}
+
+// the formats of the staged system tables
+const (
+	ScrutinizeSystemTableFormatCSV  = "csv"
+	ScrutinizeSystemTableFormatJSON = "json"
+)
+
+// the compression codecs of the batch tarballs and of the final archive
+const (
+	ScrutinizeCompressionGzip = "gzip"
+	ScrutinizeCompressionZstd = "zstd"
+	ScrutinizeCompressionNone = "none"
+)
+
+// scrutinizeTarballExtension returns the file extension of a tarball compressed
+// with the given codec
+func scrutinizeTarballExtension(compression string) string {
+	switch compression {
+	case ScrutinizeCompressionGzip:
+		return ".tgz"
+	case ScrutinizeCompressionZstd:
+		return ".tar.zst"
+	default:
+		return ".tar"
+	}
+}
+
+func validateScrutinizeCompression(compression, name string) error {
+	switch compression {
+	case ScrutinizeCompressionGzip, ScrutinizeCompressionZstd, ScrutinizeCompressionNone:
+		return nil
+	}
+	return fmt.Errorf("invalid %s %q, must be one of %s, %s or %s", name, compression,
+		ScrutinizeCompressionGzip, ScrutinizeCompressionZstd, ScrutinizeCompressionNone)
+}
+
+// the system tables collected by the curated system tables batch
+var scrutinizeCuratedSystemTables = []string{
+	"nodes",
+	"resource_pools",
+	"resource_pool_status",
+	"sessions",
+	"error_messages",
+}
+
+// ScrutinizeDefaultBatches are the batches collected when IncludeBatches is empty.
+// Core files can be very large, and NMA logs are rarely needed, so they are only
+// collected on request.
+var ScrutinizeDefaultBatches = []string{ScrutinizeBatchVerticaLogs, ScrutinizeBatchSystemTables,
+	ScrutinizeBatchDCTables}
+
 type VScrutinizeOptions struct {
 	DatabaseOptions
-	ID                          string // generated: "VerticaScrutinize.yyyymmddhhmmss"
+	ID                          string // generated: "VerticaScrutinize.yyyymmddhhmmss", or a previous ID to resume
 	TarballName                 string // final tarball name
 	ExcludeContainers           bool
 	ExcludeActiveQueries        bool
@@ -57,10 +130,38 @@ type VScrutinizeOptions struct {
 	LogAgeOldestTime            string
 	LogAgeNewestTime            string
 	LogAgeHours                 int // max log age from input
+	// the limit of the total size of vertica logs collected from each host
+	// in MB, no limit if 0
+	HostLogSizeLimitMB int64
+	// the batches to collect, ScrutinizeDefaultBatches if empty
+	IncludeBatches []string
+	// the batches not to collect
+	ExcludeBatches []string
+	// the format of the staged system tables, csv or json. If empty, the
+	// NMA picks the format.
+	SystemTableFormat string
+	// whether to scrub IPs, host names, user names and query literals from the
+	// collected files before packaging them
+	Redact bool
+	// the extra regular expressions of the data to scrub when redacting
+	RedactPatterns []string
+	// if set, receives the progress of each batch tarball on each host
+	ProgressReporter ProgressReporter
+	// the max number of host tarballs downloaded at the same time, no limit if 0
+	DownloadConcurrency int
+	// the bandwidth limit of each tarball download in MB per second, no limit if 0
+	DownloadBandwidthLimitMB int64
+	// the compression of the tarball of each batch, gzip by default
+	Compression string
+	// the compression of the final archive, none by default as the batch
+	// tarballs in it are already compressed
+	ArchiveCompression string
 
 	timeFormats    []util.TimeFormat // generated by factory
 	logAgeMaxHours int               // calculated from exported log age options
 	logAgeMinHours int               // calculated from exported log age options
+	batches        map[string]bool   // calculated from exported batch options
+	redactionRules []redactionRule   // calculated from exported redaction options
 }
 
 func VScrutinizeOptionsFactory() VScrutinizeOptions {
@@ -76,6 +177,8 @@ func (options *VScrutinizeOptions) setDefaultValues() {
 	options.DatabaseOptions.setDefaultValues()
 
 	options.ID = generateScrutinizeID()
+	options.Compression = ScrutinizeCompressionGzip
+	options.ArchiveCompression = ScrutinizeCompressionNone
 
 	// if these are changed, the help format string must also be changed
 	noTZFormat := util.TimeFormat{Layout: "2006-01-02 15", UseLocalTZ: true}
@@ -148,6 +251,11 @@ func (options *VScrutinizeOptions) validateRequiredOptions(logger vlog.Printer)
 		return err
 	}
 
+	// the ID is used in the output paths, and a rerun with the same ID resumes the collection
+	if options.ID == "" || strings.ContainsAny(options.ID, "/ ") {
+		return fmt.Errorf("invalid scrutinize ID %q", options.ID)
+	}
+
 	// RawHosts is already required by the cmd parser, so no need to check here
 	// check if catalog prefix in user input is correct
 	return options.validateCatalogPath()
@@ -174,10 +282,90 @@ func (options *VScrutinizeOptions) analyzeOptions(logger vlog.Printer) (err erro
 		return err
 	}
 
+	if options.HostLogSizeLimitMB < 0 {
+		return fmt.Errorf("invalid host log size limit %d, must not be negative", options.HostLogSizeLimitMB)
+	}
+
+	err = options.setBatches(logger)
+	if err != nil {
+		return err
+	}
+
+	switch options.SystemTableFormat {
+	case "", ScrutinizeSystemTableFormatCSV, ScrutinizeSystemTableFormatJSON:
+	default:
+		return fmt.Errorf("invalid system table format %q, must be %s or %s", options.SystemTableFormat,
+			ScrutinizeSystemTableFormatCSV, ScrutinizeSystemTableFormatJSON)
+	}
+
+	if options.DownloadConcurrency < 0 {
+		return fmt.Errorf("invalid download concurrency %d, must not be negative", options.DownloadConcurrency)
+	}
+	if options.DownloadBandwidthLimitMB < 0 {
+		return fmt.Errorf("invalid download bandwidth limit %d, must not be negative", options.DownloadBandwidthLimitMB)
+	}
+
+	if err = validateScrutinizeCompression(options.Compression, "compression"); err != nil {
+		return err
+	}
+	if err = validateScrutinizeCompression(options.ArchiveCompression, "archive compression"); err != nil {
+		return err
+	}
+
+	if options.Redact {
+		// redaction rewrites the batch tarballs, which is only supported with gzip
+		if options.Compression == ScrutinizeCompressionZstd {
+			return fmt.Errorf("redaction is not supported with %s compression", options.Compression)
+		}
+		options.redactionRules, err = options.buildRedactionRules()
+		if err != nil {
+			return err
+		}
+	} else if len(options.RedactPatterns) > 0 {
+		return fmt.Errorf("redaction patterns can only be given when redacting")
+	}
+
 	err = options.setUsePassword(logger)
 	return err
 }
 
+// setBatches calculates the batches to collect from the included and excluded batches
+func (options *VScrutinizeOptions) setBatches(logger vlog.Printer) error {
+	for _, batch := range append(util.CopySlice(options.IncludeBatches), options.ExcludeBatches...) {
+		if !util.StringInArray(batch, ScrutinizeSelectableBatches) {
+			return fmt.Errorf("invalid scrutinize batch %q, must be one of %v", batch, ScrutinizeSelectableBatches)
+		}
+	}
+
+	includeBatches := options.IncludeBatches
+	if len(includeBatches) == 0 {
+		includeBatches = ScrutinizeDefaultBatches
+	}
+	options.batches = make(map[string]bool)
+	for _, batch := range includeBatches {
+		if util.StringInArray(batch, options.ExcludeBatches) {
+			if len(options.IncludeBatches) > 0 {
+				return fmt.Errorf("scrutinize batch %s cannot be both included and excluded", batch)
+			}
+			continue
+		}
+		options.batches[batch] = true
+	}
+
+	logger.Info("Scrutinize batches set", "Batches", options.batches)
+	return nil
+}
+
+// collects returns true if the batch is collected
+func (options *VScrutinizeOptions) collects(batch string) bool {
+	return options.batches[batch]
+}
+
+// collectsSystemTables returns true if any system tables are collected
+func (options *VScrutinizeOptions) collectsSystemTables() bool {
+	return options.collects(ScrutinizeBatchSystemTables) || options.collects(ScrutinizeBatchCuratedSystemTables)
+}
+
 func (options *VScrutinizeOptions) ValidateAnalyzeOptions(logger vlog.Printer) error {
 	if err := options.validateParseOptions(logger); err != nil {
 		return err
@@ -185,9 +373,11 @@ func (options *VScrutinizeOptions) ValidateAnalyzeOptions(logger vlog.Printer) e
 	return options.analyzeOptions(logger)
 }
 
-func (vcc VClusterCommands) VScrutinize(options *VScrutinizeOptions) error {
+func (vcc VClusterCommands) VScrutinize(options *VScrutinizeOptions) (err error) {
+	defer vcc.startAudit("scrutinize", options)(&err)
+
 	// check required options (including those that can come from cluster config)
-	err := options.ValidateAnalyzeOptions(vcc.Log)
+	err = options.ValidateAnalyzeOptions(vcc.Log)
 	if err != nil {
 		vcc.Log.Error(err, "validation of scrutinize arguments failed")
 		return err
@@ -197,7 +387,7 @@ func (vcc VClusterCommands) VScrutinize(options *VScrutinizeOptions) error {
 	// 1. slice of nodes with NMA running
 	// 2. host -> node info map
 	vdb := makeVCoordinationDatabase()
-	err = options.getVDBForScrutinize(vcc.Log, &vdb)
+	err = options.getVDBForScrutinize(vcc.getContext(), vcc.Log, &vdb)
 	if err != nil {
 		vcc.Log.Error(err, "failed to retrieve cluster info for scrutinize")
 		return err
@@ -205,13 +395,20 @@ func (vcc VClusterCommands) VScrutinize(options *VScrutinizeOptions) error {
 	// from now on, use hosts with healthy NMA
 	options.Hosts = vdb.HostList
 
+	// a previous run with the same ID may have downloaded some of the tarballs
+	progress, err := loadScrutinizeProgress(options.ID)
+	if err != nil {
+		vcc.Log.Error(err, "failed to load scrutinize progress")
+		return err
+	}
+
 	// prepare main instructions
-	instructions, err := vcc.produceScrutinizeInstructions(options, &vdb)
+	instructions, err := vcc.produceScrutinizeInstructions(options, &vdb, progress)
 	if err != nil {
 		vcc.Log.Error(err, "failed to produce instructions for scrutinize")
 		return err
 	}
-	err = options.runClusterOpEngine(vcc.Log, instructions)
+	err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, instructions)
 	if err != nil {
 		vcc.Log.Error(err, "failed to run scrutinize operations")
 		return err
@@ -220,8 +417,21 @@ func (vcc VClusterCommands) VScrutinize(options *VScrutinizeOptions) error {
 	// add vcluster log to output
 	options.stageVclusterLog(options.ID, vcc.Log)
 
+	// the progress is not needed once the collection completes
+	if err = progress.remove(); err != nil {
+		vcc.Log.PrintWarning("Failed to remove scrutinize progress file: %s", err.Error())
+	}
+
+	if options.Redact {
+		vcc.Log.PrintInfo("Redacting the collected files")
+		if err = redactScrutinizeOutput(options.ID, options.redactionRules, vcc.Log); err != nil {
+			vcc.Log.Error(err, "failed to redact scrutinize output")
+			return err
+		}
+	}
+
 	// tar all results
-	if err = tarAndRemoveDirectory(options.TarballName, options.ID, vcc.Log); err != nil {
+	if err = tarAndRemoveDirectory(options.TarballName, options.ID, options.ArchiveCompression, vcc.Log); err != nil {
 		vcc.Log.Error(err, "failed to create final scrutinize output tarball")
 		return err
 	}
@@ -252,10 +462,18 @@ func (options *VScrutinizeOptions) stageVclusterLog(id string, log vlog.Printer)
 	}
 }
 
-// tarAndRemoveDirectory packages the final scrutinize output.
-func tarAndRemoveDirectory(tarballName, id string, log vlog.Printer) (err error) {
-	tarballPath := ScrutinizeOutputBasePath + "/" + tarballName + ".tar"
-	cmd := exec.Command("tar", "cf", tarballPath, "-C", "/tmp/scrutinize/remote", id)
+// tarAndRemoveDirectory packages the final scrutinize output, compressed with the
+// given codec.
+func tarAndRemoveDirectory(tarballName, id, compression string, log vlog.Printer) (err error) {
+	tarballPath := ScrutinizeOutputBasePath + "/" + tarballName + scrutinizeTarballExtension(compression)
+	args := []string{"cf", tarballPath, "-C", "/tmp/scrutinize/remote", id}
+	switch compression {
+	case ScrutinizeCompressionGzip:
+		args = append([]string{"-z"}, args...)
+	case ScrutinizeCompressionZstd:
+		args = append([]string{"--zstd"}, args...)
+	}
+	cmd := exec.Command("tar", args...)
 	log.Info("running command %s with args %v", cmd.Path, cmd.Args)
 	if err = cmd.Run(); err != nil {
 		return
@@ -271,12 +489,13 @@ func tarAndRemoveDirectory(tarballName, id string, log vlog.Printer) (err error)
 }
 
 // getVDBForScrutinize populates an empty coordinator database with the minimum
-// required information for further scrutinize operations.
-func (options *VScrutinizeOptions) getVDBForScrutinize(logger vlog.Printer,
+// required information for further scrutinize operations. It is also used by
+// other commands that collect files from the nodes through the NMA.
+func (options *DatabaseOptions) getVDBForScrutinize(ctx context.Context, logger vlog.Printer,
 	vdb *VCoordinationDatabase) error {
 	// get nodes where NMA is running and only use those for NMA ops
 	getHealthyNodesOp := makeNMAGetHealthyNodesOp(options.Hosts, vdb)
-	err := options.runClusterOpEngine(logger, []clusterOp{&getHealthyNodesOp})
+	err := options.runClusterOpEngine(ctx, logger, []clusterOp{&getHealthyNodesOp})
 	if err != nil {
 		return err
 	}
@@ -284,7 +503,7 @@ func (options *VScrutinizeOptions) getVDBForScrutinize(logger vlog.Printer,
 	// get map of host to node name and fully qualified catalog path
 	getNodesInfoOp := makeNMAGetNodesInfoOp(vdb.HostList, options.DBName,
 		options.CatalogPrefix, true /* ignore internal errors */, vdb)
-	err = options.runClusterOpEngine(logger, []clusterOp{&getNodesInfoOp})
+	err = options.runClusterOpEngine(ctx, logger, []clusterOp{&getNodesInfoOp})
 	if err != nil {
 		return err
 	}
@@ -308,59 +527,138 @@ func (options *VScrutinizeOptions) getVDBForScrutinize(logger vlog.Printer,
 //
 // The generated instructions will later perform the following operations necessary
 // for a successful scrutinize:
-//   - Get up nodes through https call
-//   - Initiate system table staging on the first up node, if available
-//   - Stage vertica logs on all nodes
+//   - Get up nodes through https call (if system tables are collected)
+//   - Initiate system table staging on the first up node, if available and collected
+//   - Stage vertica logs on all nodes (if collected)
+//   - Stage NMA logs on all nodes (if collected)
+//   - Stage DC tables on all nodes (if collected)
+//   - Stage core files on all nodes (if collected)
 //   - Stage files on all nodes
-//   - Stage DC tables on all nodes
 //   - Tar and retrieve vertica logs and DC tables from all nodes (batch normal)
 //   - Tar and retrieve error report from all nodes (batch context)
 //   - (If applicable) Poll for system table staging completion on task node
 //   - (If applicable) Tar and retrieve system tables from task node (batch system_tables)
 func (vcc VClusterCommands) produceScrutinizeInstructions(options *VScrutinizeOptions,
-	vdb *VCoordinationDatabase) (instructions []clusterOp, err error) {
+	vdb *VCoordinationDatabase, progress *scrutinizeProgress) (instructions []clusterOp, err error) {
 	// extract needed info from vdb
 	hostNodeNameMap, hostCatPathMap, err := getNodeInfoForScrutinize(options.Hosts, vdb)
 	if err != nil {
 		return nil, fmt.Errorf("failed to process retrieved node info, details %w", err)
 	}
 
-	// Get up database nodes for the system table task
-	getUpNodesOp, err := makeHTTPSGetUpNodesOp(options.DBName, options.Hosts,
-		options.usePassword, options.UserName, options.Password, ScrutinizeCmd)
-	if err != nil {
-		return nil, err
+	collectSystemTables := options.collectsSystemTables() && !progress.batchDownloaded(scrutinizeBatchSystemTables)
+	if collectSystemTables {
+		// Get up database nodes for the system table task
+		getUpNodesOp, e := makeHTTPSGetUpNodesOp(options.DBName, options.Hosts,
+			options.usePassword, options.UserName, options.Password, ScrutinizeCmd)
+		if e != nil {
+			return nil, e
+		}
+		getUpNodesOp.allowNoUpHosts()
+		instructions = append(instructions, &getUpNodesOp)
+
+		stageSystemTablesInstructions, e := getStageSystemTablesInstructions(vcc.Log, options, hostNodeNameMap)
+		if e != nil {
+			return nil, e
+		}
+		instructions = append(instructions, stageSystemTablesInstructions...)
 	}
-	getUpNodesOp.allowNoUpHosts()
-	instructions = append(instructions, &getUpNodesOp)
 
-	stageSystemTablesInstructions, err := getStageSystemTablesInstructions(vcc.Log, options, hostNodeNameMap)
-	if err != nil {
-		return nil, err
+	// skip the hosts whose tarballs were all downloaded by a previous run with the same ID
+	hosts := progress.pendingHosts(options.Hosts, scrutinizeBatchNormal, scrutinizeBatchContext)
+	if len(hosts) < len(options.Hosts) {
+		vcc.Log.PrintInfo("Skipping hosts %v already collected by scrutinize %s",
+			util.SliceDiff(options.Hosts, hosts), options.ID)
+	}
+	if len(hosts) > 0 {
+		hostInstructions, e := options.produceScrutinizeHostInstructions(vcc.Log, hosts,
+			hostNodeNameMap, hostCatPathMap, progress)
+		if e != nil {
+			return nil, e
+		}
+		instructions = append(instructions, hostInstructions...)
 	}
-	instructions = append(instructions, stageSystemTablesInstructions...)
 
-	// stage Vertica logs
-	stageVerticaLogsOp, err := makeNMAStageVerticaLogsOp(options.ID, options.Hosts,
-		hostNodeNameMap, hostCatPathMap, scrutinizeLogLimitBytes, options.logAgeMaxHours, options.logAgeMinHours)
-	if err != nil {
-		// map invariant assertion failure -- should not occur
-		return nil, err
+	if collectSystemTables {
+		// get 'system_tables' batch tarball last, as staging systables can take a long time
+		getSystemTablesTarballOp, e := makeNMAGetScrutinizeTarOp(options.ID, scrutinizeBatchSystemTables,
+			options.Hosts, hostNodeNameMap)
+		if e != nil {
+			return nil, e
+		}
+		getSystemTablesTarballOp.useSingleHost()
+		getSystemTablesTarballOp.setProgress(progress)
+		getSystemTablesTarballOp.setCompression(options.Compression)
+		getSystemTablesTarballOp.setDownloadLimits(options.DownloadConcurrency,
+			options.DownloadBandwidthLimitMB*scrutinizeBytesPerMB)
+		instructions = append(instructions, &getSystemTablesTarballOp)
+	}
+
+	// the normal and context tarballs of each host, and the system table tarball
+	tarballCount := len(hosts) * 2
+	if collectSystemTables {
+		tarballCount++
+	}
+	tracker := makeProgressTracker(options.ProgressReporter, tarballCount)
+	for _, instruction := range instructions {
+		if op, ok := instruction.(interface{ setProgressTracker(*progressTracker) }); ok {
+			op.setProgressTracker(tracker)
+		}
 	}
-	instructions = append(instructions, &stageVerticaLogsOp)
 
-	// stage DC Tables
-	stageDCTablesOp, err := makeNMAStageDCTablesOp(options.ID, options.Hosts,
-		hostNodeNameMap, hostCatPathMap)
-	if err != nil {
-		// map invariant assertion failure -- should not occur
-		return nil, err
+	return instructions, nil
+}
+
+// produceScrutinizeHostInstructions builds the instructions that stage and download
+// the files of each host
+func (options *VScrutinizeOptions) produceScrutinizeHostInstructions(logger vlog.Printer, hosts []string,
+	hostNodeNameMap, hostCatPathMap map[string]string, progress *scrutinizeProgress) (instructions []clusterOp, err error) {
+	if options.collects(ScrutinizeBatchVerticaLogs) {
+		// stage Vertica logs
+		stageVerticaLogsOp, e := makeNMAStageVerticaLogsOp(options.ID, hosts,
+			hostNodeNameMap, hostCatPathMap, scrutinizeLogLimitBytes, options.logAgeMaxHours, options.logAgeMinHours)
+		if e != nil {
+			// map invariant assertion failure -- should not occur
+			return nil, e
+		}
+		stageVerticaLogsOp.setHostSizeLimit(options.HostLogSizeLimitMB * scrutinizeBytesPerMB)
+		instructions = append(instructions, &stageVerticaLogsOp)
+	}
+
+	if options.collects(ScrutinizeBatchNMALogs) {
+		// stage NMA logs
+		stageNMALogsOp, e := makeNMAStageNMALogsOp(options.ID, hosts,
+			hostNodeNameMap, hostCatPathMap, scrutinizeLogLimitBytes)
+		if e != nil {
+			return nil, e
+		}
+		instructions = append(instructions, &stageNMALogsOp)
+	}
+
+	if options.collects(ScrutinizeBatchDCTables) {
+		// stage DC Tables
+		stageDCTablesOp, e := makeNMAStageDCTablesOp(options.ID, hosts,
+			hostNodeNameMap, hostCatPathMap)
+		if e != nil {
+			// map invariant assertion failure -- should not occur
+			return nil, e
+		}
+		instructions = append(instructions, &stageDCTablesOp)
+	}
+
+	if options.collects(ScrutinizeBatchCoreFiles) {
+		// stage core files, which are not limited by the misc file size limit
+		stageCoreFilesOp, e := makeNMAStageCoreFilesOp(options.ID, hosts,
+			hostNodeNameMap, hostCatPathMap, scrutinizeLogLimitBytes)
+		if e != nil {
+			return nil, e
+		}
+		instructions = append(instructions, &stageCoreFilesOp)
 	}
-	instructions = append(instructions, &stageDCTablesOp)
 
 	// stage 'normal' batch files -- see NMA for what files are collected
 	stageVerticaNormalFilesOp, err := makeNMAStageFilesOp(options.ID, scrutinizeBatchNormal,
-		options.Hosts, hostNodeNameMap, hostCatPathMap, scrutinizeFileLimitBytes)
+		hosts, hostNodeNameMap, hostCatPathMap, scrutinizeFileLimitBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -368,15 +666,15 @@ func (vcc VClusterCommands) produceScrutinizeInstructions(options *VScrutinizeOp
 
 	// stage 'context' batch files -- see NMA for what files are collected
 	stageVerticaContextFilesOp, err := makeNMAStageFilesOp(options.ID, scrutinizeBatchContext,
-		options.Hosts, hostNodeNameMap, hostCatPathMap, scrutinizeFileLimitBytes)
+		hosts, hostNodeNameMap, hostCatPathMap, scrutinizeFileLimitBytes)
 	if err != nil {
 		return nil, err
 	}
 	instructions = append(instructions, &stageVerticaContextFilesOp)
 
 	// run and stage diagnostic command results -- see NMA for what commands are run
-	stageCommandsOp, err := makeNMAStageCommandsOp(vcc.Log, options.ID, scrutinizeBatchContext,
-		options.Hosts, hostNodeNameMap, hostCatPathMap)
+	stageCommandsOp, err := makeNMAStageCommandsOp(logger, options.ID, scrutinizeBatchContext,
+		hosts, hostNodeNameMap, hostCatPathMap)
 	if err != nil {
 		return nil, err
 	}
@@ -384,29 +682,28 @@ func (vcc VClusterCommands) produceScrutinizeInstructions(options *VScrutinizeOp
 
 	// get 'normal' batch tarball (inc. Vertica logs and 'normal' batch files)
 	getNormalTarballOp, err := makeNMAGetScrutinizeTarOp(options.ID, scrutinizeBatchNormal,
-		options.Hosts, hostNodeNameMap)
+		hosts, hostNodeNameMap)
 	if err != nil {
 		return nil, err
 	}
+	getNormalTarballOp.setProgress(progress)
+	getNormalTarballOp.setCompression(options.Compression)
+	getNormalTarballOp.setDownloadLimits(options.DownloadConcurrency,
+		options.DownloadBandwidthLimitMB*scrutinizeBytesPerMB)
 	instructions = append(instructions, &getNormalTarballOp)
 
 	// get 'context' batch tarball (inc. 'context' batch files)
 	getContextTarballOp, err := makeNMAGetScrutinizeTarOp(options.ID, scrutinizeBatchContext,
-		options.Hosts, hostNodeNameMap)
+		hosts, hostNodeNameMap)
 	if err != nil {
 		return nil, err
 	}
+	getContextTarballOp.setProgress(progress)
+	getContextTarballOp.setCompression(options.Compression)
+	getContextTarballOp.setDownloadLimits(options.DownloadConcurrency,
+		options.DownloadBandwidthLimitMB*scrutinizeBytesPerMB)
 	instructions = append(instructions, &getContextTarballOp)
 
-	// get 'system_tables' batch tarball last, as staging systables can take a long time
-	getSystemTablesTarballOp, err := makeNMAGetScrutinizeTarOp(options.ID, scrutinizeBatchSystemTables,
-		options.Hosts, hostNodeNameMap)
-	if err != nil {
-		return nil, err
-	}
-	getSystemTablesTarballOp.useSingleHost()
-	instructions = append(instructions, &getSystemTablesTarballOp)
-
 	return instructions, nil
 }
 
@@ -468,6 +765,12 @@ func getStageSystemTablesInstructions(logger vlog.Printer, options *VScrutinizeO
 	if err != nil {
 		return nil, err
 	}
+	// all system tables include the curated ones, so only limit the tables
+	// when the curated batch is collected alone
+	if !options.collects(ScrutinizeBatchSystemTables) {
+		stageSystemTablesOp.setIncludedTables(scrutinizeCuratedSystemTables)
+	}
+	stageSystemTablesOp.setFormat(options.SystemTableFormat)
 	instructions = append(instructions, &stageSystemTablesOp)
 
 	return instructions, nil
@@ -23,7 +23,7 @@ import (
 // top level handler for scrutinize operations
 const scrutinizeURLPrefix = "scrutinize/"
 
-// scrutinizeOpBase, in addition to embedding the standard OpBase, wraps some
+// scrutinizeOpBase, in addition to embedding the standard opBase, wraps some
 // common data and functionality for scrutinize-specific ops
 type scrutinizeOpBase struct {
 	opBase
@@ -34,6 +34,12 @@ type scrutinizeOpBase struct {
 	hostNodeNameMap    map[string]string // must correspond to host list exactly!
 	hostCatPathMap     map[string]string // must correspond to host list exactly, if non-nil
 	hostRequestBodyMap map[string]string // should be nil if not used
+	tracker            *progressTracker  // nil if the progress is not reported
+}
+
+// setProgressTracker reports the progress of the op to the tracker
+func (op *scrutinizeOpBase) setProgressTracker(tracker *progressTracker) {
+	op.tracker = tracker
 }
 
 func (op *scrutinizeOpBase) setupClusterHTTPRequest(hosts []string) error {
@@ -76,6 +82,7 @@ func processStagedItemsResult[T any](op *scrutinizeOpBase, itemList []T) error {
 			for _, entry := range itemList {
 				op.logger.Info("item staged on host", "Host", host, "Item", entry)
 			}
+			op.tracker.report(host, op.batch, ProgressStageStaged, 0)
 		} else {
 			allErrs = errors.Join(allErrs, result.err)
 		}
@@ -0,0 +1,108 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+)
+
+const scrutinizeProgressFileName = "scrutinize_progress.json"
+
+// scrutinizeProgress records the tarballs downloaded by a scrutinize run, so that
+// a rerun with the same ID skips them instead of restarting the whole collection.
+// It is saved under the output directory of the run, which is only removed once
+// the final tarball is created.
+type scrutinizeProgress struct {
+	// batch -> hosts whose tarball of the batch is downloaded
+	Downloaded map[string][]string `json:"downloaded"`
+
+	filePath string
+	mutex    sync.Mutex
+}
+
+// loadScrutinizeProgress reads the progress of the scrutinize run with the given
+// ID. The progress is empty if the run has not started yet.
+func loadScrutinizeProgress(id string) (*scrutinizeProgress, error) {
+	progress := &scrutinizeProgress{
+		Downloaded: make(map[string][]string),
+		filePath:   fmt.Sprintf("%s/%s/%s", scrutinizeRemoteOutputPath, id, scrutinizeProgressFileName),
+	}
+	content, err := os.ReadFile(progress.filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fail to read scrutinize progress file %s: %w", progress.filePath, err)
+	}
+	if err = json.Unmarshal(content, progress); err != nil {
+		return nil, fmt.Errorf("fail to parse scrutinize progress file %s: %w", progress.filePath, err)
+	}
+	return progress, nil
+}
+
+// markDownloaded records that the tarball of a batch is downloaded from a host
+func (progress *scrutinizeProgress) markDownloaded(batch, host string) error {
+	progress.mutex.Lock()
+	defer progress.mutex.Unlock()
+
+	if util.StringInArray(host, progress.Downloaded[batch]) {
+		return nil
+	}
+	progress.Downloaded[batch] = append(progress.Downloaded[batch], host)
+
+	content, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("fail to marshal scrutinize progress: %w", err)
+	}
+	const progressFilePerms = 0600
+	return os.WriteFile(progress.filePath, content, progressFilePerms)
+}
+
+// batchDownloaded returns true if the tarball of a batch is downloaded from any host
+func (progress *scrutinizeProgress) batchDownloaded(batch string) bool {
+	return len(progress.Downloaded[batch]) > 0
+}
+
+// pendingHosts returns the hosts whose tarballs of the given batches are not all
+// downloaded
+func (progress *scrutinizeProgress) pendingHosts(hosts []string, batches ...string) []string {
+	var pending []string
+	for _, host := range hosts {
+		for _, batch := range batches {
+			if !util.StringInArray(host, progress.Downloaded[batch]) {
+				pending = append(pending, host)
+				break
+			}
+		}
+	}
+	return pending
+}
+
+// remove deletes the progress file so that it is not included in the final tarball
+func (progress *scrutinizeProgress) remove() error {
+	err := os.Remove(progress.filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,280 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// the replacements of the data scrubbed by the redaction rules
+const (
+	redactedIP      = "<IP>"
+	redactedHost    = "<HOST>"
+	redactedUser    = "<USER>"
+	redactedLiteral = "'<LITERAL>'"
+	redactedCustom  = "<REDACTED>"
+)
+
+// files that have a NUL byte in the first redactionSniffBytes bytes are
+// treated as binary and are not redacted
+const redactionSniffBytes = 8000
+
+type redactionRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// buildRedactionRules builds the rules that scrub IPs, the host names and user
+// name given in the options, user names in key-value pairs, and quoted query
+// literals, followed by a rule for each of the custom patterns
+func (options *VScrutinizeOptions) buildRedactionRules() ([]redactionRule, error) {
+	rules := []redactionRule{
+		{regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), redactedIP},
+		// full and compressed IPv6 addresses, not matching times like 10:12:33
+		{regexp.MustCompile(`\b(?:[0-9a-fA-F]{1,4}:){7}[0-9a-fA-F]{1,4}\b`), redactedIP},
+		{regexp.MustCompile(`\b(?:[0-9a-fA-F]{1,4}:)+:(?:[0-9a-fA-F]{1,4}(?::[0-9a-fA-F]{1,4})*)?`), redactedIP},
+		{regexp.MustCompile(`(?i)\b(user(?:name)?\s*[=:]\s*)[^\s,;]+`), "${1}" + redactedUser},
+		{regexp.MustCompile(`'(?:[^'\n]|'')*'`), redactedLiteral},
+	}
+	for _, host := range options.RawHosts {
+		if net.ParseIP(host) != nil {
+			continue
+		}
+		rules = append(rules, redactionRule{regexp.MustCompile(`\b` + regexp.QuoteMeta(host) + `\b`), redactedHost})
+	}
+	if options.UserName != "" {
+		rules = append(rules, redactionRule{regexp.MustCompile(`\b` + regexp.QuoteMeta(options.UserName) + `\b`),
+			redactedUser})
+	}
+	for _, pattern := range options.RedactPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		rules = append(rules, redactionRule{re, redactedCustom})
+	}
+	return rules, nil
+}
+
+func redactLine(line []byte, rules []redactionRule) []byte {
+	for _, rule := range rules {
+		line = rule.pattern.ReplaceAll(line, []byte(rule.replacement))
+	}
+	return line
+}
+
+// redactStream copies src to dst line by line, applying the redaction rules to
+// each line. Binary content is copied as is.
+func redactStream(dst io.Writer, src io.Reader, rules []redactionRule) error {
+	reader := bufio.NewReader(src)
+	head, err := reader.Peek(redactionSniffBytes)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return err
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		_, err = io.Copy(dst, reader)
+		return err
+	}
+
+	writer := bufio.NewWriter(dst)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if _, err = writer.Write(redactLine(line, rules)); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	return writer.Flush()
+}
+
+// redactToTempFile writes the redacted content of src to a temp file, so the
+// size is known before it is added to a tarball. The caller removes the file.
+func redactToTempFile(src io.Reader, rules []redactionRule, gzipped bool) (*os.File, error) {
+	tmpFile, err := os.CreateTemp("", "scrutinize-redact-")
+	if err != nil {
+		return nil, err
+	}
+	if gzipped {
+		var gzReader *gzip.Reader
+		gzReader, err = gzip.NewReader(src)
+		if err == nil {
+			gzWriter := gzip.NewWriter(tmpFile)
+			err = redactStream(gzWriter, gzReader, rules)
+			if err == nil {
+				err = gzWriter.Close()
+			}
+		}
+	} else {
+		err = redactStream(tmpFile, src, rules)
+	}
+	if err == nil {
+		_, err = tmpFile.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return nil, err
+	}
+	return tmpFile, nil
+}
+
+// redactTarball rewrites a tarball, gzipped or not, with the redaction rules applied
+// to each regular file in it. Gzipped files in the tarball, such as archived logs,
+// are redacted as well.
+func redactTarball(tarballPath string, gzipped bool, rules []redactionRule) error {
+	src, err := os.Open(tarballPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	var tarSrc io.Reader = src
+	if gzipped {
+		gzReader, e := gzip.NewReader(src)
+		if e != nil {
+			return e
+		}
+		tarSrc = gzReader
+	}
+	tarReader := tar.NewReader(tarSrc)
+
+	redactedPath := tarballPath + ".redacted"
+	dst, err := os.Create(redactedPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(redactedPath)
+	defer dst.Close()
+	var tarDst io.Writer = dst
+	var gzWriter *gzip.Writer
+	if gzipped {
+		gzWriter = gzip.NewWriter(dst)
+		tarDst = gzWriter
+	}
+	tarWriter := tar.NewWriter(tarDst)
+
+	for {
+		header, e := tarReader.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return e
+		}
+		if header.Typeflag != tar.TypeReg {
+			if e = tarWriter.WriteHeader(header); e != nil {
+				return e
+			}
+			continue
+		}
+		if e = redactTarEntry(tarWriter, tarReader, header, rules); e != nil {
+			return fmt.Errorf("fail to redact %s in %s: %w", header.Name, tarballPath, e)
+		}
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+	if gzWriter != nil {
+		if err = gzWriter.Close(); err != nil {
+			return err
+		}
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Rename(redactedPath, tarballPath)
+}
+
+func redactTarEntry(tarWriter *tar.Writer, src io.Reader, header *tar.Header, rules []redactionRule) error {
+	tmpFile, err := redactToTempFile(src, rules, strings.HasSuffix(header.Name, ".gz"))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	info, err := tmpFile.Stat()
+	if err != nil {
+		return err
+	}
+	header.Size = info.Size()
+	if err = tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tarWriter, tmpFile)
+	return err
+}
+
+// redactFile rewrites a plain file with the redaction rules applied
+func redactFile(filePath string, rules []redactionRule) error {
+	src, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmpFile, err := redactToTempFile(src, rules, false)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	dst, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	_, err = io.Copy(dst, tmpFile)
+	return err
+}
+
+// redactScrutinizeOutput applies the redaction rules to all files collected by
+// the scrutinize run with the given ID before they are packaged
+func redactScrutinizeOutput(id string, rules []redactionRule, logger vlog.Printer) error {
+	outputDir := fmt.Sprintf("%s/%s", scrutinizeRemoteOutputPath, id)
+	return filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		logger.Info("Redacting scrutinize output", "File", path)
+		switch {
+		case strings.HasSuffix(path, scrutinizeTarballExtension(ScrutinizeCompressionGzip)):
+			return redactTarball(path, true, rules)
+		case strings.HasSuffix(path, scrutinizeTarballExtension(ScrutinizeCompressionNone)):
+			return redactTarball(path, false, rules)
+		}
+		return redactFile(path, rules)
+	})
+}
@@ -0,0 +1,124 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactStream(t *testing.T) {
+	options := VScrutinizeOptionsFactory()
+	options.RawHosts = []string{"vnode1.example.com", "192.168.1.102"}
+	options.UserName = "dbadmin"
+	options.RedactPatterns = []string{`acct-\d+`}
+	rules, err := options.buildRedactionRules()
+	assert.NoError(t, err)
+
+	input := "Connected to 192.168.1.101 and vnode1.example.com as dbadmin\n" +
+		"Session user=alice ran SELECT * FROM t WHERE name = 'Bob''s' AND id = 'acct-42'\n" +
+		"2024-03-01 10:12:33 Listening on fd00::1:2:3\n" +
+		"Customer acct-1234 loaded"
+	var output bytes.Buffer
+	err = redactStream(&output, strings.NewReader(input), rules)
+	assert.NoError(t, err)
+	assert.Equal(t, "Connected to <IP> and <HOST> as <USER>\n"+
+		"Session user=<USER> ran SELECT * FROM t WHERE name = '<LITERAL>' AND id = '<LITERAL>'\n"+
+		"2024-03-01 10:12:33 Listening on <IP>\n"+
+		"Customer <REDACTED> loaded", output.String())
+
+	// binary content is not redacted
+	binary := "core\x00dump 192.168.1.101"
+	output.Reset()
+	err = redactStream(&output, strings.NewReader(binary), rules)
+	assert.NoError(t, err)
+	assert.Equal(t, binary, output.String())
+
+	// invalid patterns are rejected
+	options.RedactPatterns = []string{"acct-("}
+	_, err = options.buildRedactionRules()
+	assert.ErrorContains(t, err, "invalid redaction pattern")
+}
+
+func TestRedactTarball(t *testing.T) {
+	options := VScrutinizeOptionsFactory()
+	rules, err := options.buildRedactionRules()
+	assert.NoError(t, err)
+
+	// a tarball with a plain log and a gzipped archived log
+	var archivedLog bytes.Buffer
+	gzWriter := gzip.NewWriter(&archivedLog)
+	_, err = gzWriter.Write([]byte("old entry from 10.0.0.1\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, gzWriter.Close())
+	files := map[string][]byte{
+		"vertica.log":      []byte("new entry from 10.0.0.2\n"),
+		"vertica.log.1.gz": archivedLog.Bytes(),
+	}
+	tarballPath := path.Join(t.TempDir(), "v_test_db_node0001-normal.tgz")
+	tarball, err := os.Create(tarballPath)
+	assert.NoError(t, err)
+	gzWriter = gzip.NewWriter(tarball)
+	tarWriter := tar.NewWriter(gzWriter)
+	for name, content := range files {
+		assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)),
+			Typeflag: tar.TypeReg}))
+		_, err = tarWriter.Write(content)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzWriter.Close())
+	assert.NoError(t, tarball.Close())
+
+	err = redactTarball(tarballPath, true, rules)
+	assert.NoError(t, err)
+
+	// both logs are redacted
+	tarball, err = os.Open(tarballPath)
+	assert.NoError(t, err)
+	defer tarball.Close()
+	gzReader, err := gzip.NewReader(tarball)
+	assert.NoError(t, err)
+	tarReader := tar.NewReader(gzReader)
+	redacted := make(map[string]string)
+	for {
+		header, e := tarReader.Next()
+		if e == io.EOF {
+			break
+		}
+		assert.NoError(t, e)
+		var content io.Reader = tarReader
+		if strings.HasSuffix(header.Name, ".gz") {
+			content, e = gzip.NewReader(tarReader)
+			assert.NoError(t, e)
+		}
+		contentBytes, e := io.ReadAll(content)
+		assert.NoError(t, e)
+		redacted[header.Name] = string(contentBytes)
+	}
+	assert.Equal(t, map[string]string{
+		"vertica.log":      "new entry from <IP>\n",
+		"vertica.log.1.gz": "old entry from <IP>\n",
+	}, redacted)
+}
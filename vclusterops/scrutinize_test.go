@@ -17,7 +17,10 @@ package vclusterops
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path"
 	"testing"
 	"time"
 
@@ -148,3 +151,168 @@ func TestSetLogAgeRange(t *testing.T) {
 	assert.ErrorContains(t, err, "invalid time range: max log age cannot be less than min log age")
 	assert.Contains(t, logBuf.String(), "invalid log age range")
 }
+
+func TestScrutinizeBatches(t *testing.T) {
+	logger := vlog.Printer{}
+	sOptions := VScrutinizeOptionsFactory()
+
+	// default batches
+	err := sOptions.setBatches(logger)
+	assert.NoError(t, err)
+	assert.True(t, sOptions.collects(ScrutinizeBatchVerticaLogs))
+	assert.True(t, sOptions.collects(ScrutinizeBatchSystemTables))
+	assert.True(t, sOptions.collects(ScrutinizeBatchDCTables))
+	assert.False(t, sOptions.collects(ScrutinizeBatchNMALogs))
+	assert.False(t, sOptions.collects(ScrutinizeBatchCoreFiles))
+
+	// exclude from default batches
+	sOptions.ExcludeBatches = []string{ScrutinizeBatchSystemTables}
+	err = sOptions.setBatches(logger)
+	assert.NoError(t, err)
+	assert.False(t, sOptions.collects(ScrutinizeBatchSystemTables))
+	assert.True(t, sOptions.collects(ScrutinizeBatchDCTables))
+
+	// only the included batches are collected
+	sOptions.ExcludeBatches = nil
+	sOptions.IncludeBatches = []string{ScrutinizeBatchCoreFiles}
+	err = sOptions.setBatches(logger)
+	assert.NoError(t, err)
+	assert.Len(t, sOptions.batches, 1)
+	assert.True(t, sOptions.collects(ScrutinizeBatchCoreFiles))
+
+	// a batch cannot be both included and excluded
+	sOptions.ExcludeBatches = []string{ScrutinizeBatchCoreFiles}
+	err = sOptions.setBatches(logger)
+	assert.ErrorContains(t, err, "cannot be both included and excluded")
+
+	// unknown batches are rejected
+	sOptions.ExcludeBatches = []string{"heap_dumps"}
+	err = sOptions.setBatches(logger)
+	assert.ErrorContains(t, err, "invalid scrutinize batch")
+}
+
+func TestStageVerticaLogsHostSizeLimit(t *testing.T) {
+	hosts := []string{"192.168.1.101"}
+	hostNodeNameMap := map[string]string{"192.168.1.101": "v_test_db_node0001"}
+	hostCatPathMap := map[string]string{"192.168.1.101": "/data/test_db/v_test_db_node0001_catalog"}
+	op, err := makeNMAStageVerticaLogsOp("VerticaScrutinize.20240101000000", hosts, hostNodeNameMap,
+		hostCatPathMap, scrutinizeLogLimitBytes, ScrutinizeLogMaxAgeHoursDefault, 0)
+	assert.NoError(t, err)
+
+	// no limit by default
+	err = op.setupRequestBody(hosts)
+	assert.NoError(t, err)
+	assert.NotContains(t, op.hostRequestBodyMap["192.168.1.101"], "host_size_limit_bytes")
+
+	const hostSizeLimitMB = 500
+	op.setHostSizeLimit(hostSizeLimitMB * scrutinizeBytesPerMB)
+	err = op.setupRequestBody(hosts)
+	assert.NoError(t, err)
+	assert.Contains(t, op.hostRequestBodyMap["192.168.1.101"], `"host_size_limit_bytes":524288000`)
+}
+
+func TestScrutinizeCuratedSystemTables(t *testing.T) {
+	logger := vlog.Printer{}
+	sOptions := VScrutinizeOptionsFactory()
+	sOptions.IncludeBatches = []string{ScrutinizeBatchCuratedSystemTables}
+	err := sOptions.setBatches(logger)
+	assert.NoError(t, err)
+	assert.True(t, sOptions.collectsSystemTables())
+	assert.False(t, sOptions.collects(ScrutinizeBatchSystemTables))
+
+	// only the curated tables are staged
+	stagingDir := "/tmp/scrutinize/remote/VerticaScrutinize.20240101000000/v_test_db_node0001/systables"
+	op, err := makeHTTPSStageSystemTablesOp(logger, false, "", nil, sOptions.ID,
+		map[string]string{"192.168.1.101": "v_test_db_node0001"}, &stagingDir, false, false, false, false, false)
+	assert.NoError(t, err)
+	op.setIncludedTables(scrutinizeCuratedSystemTables)
+	op.setFormat(ScrutinizeSystemTableFormatJSON)
+	op.setupBasicInfo()
+	err = op.setupClusterHTTPRequest([]string{"192.168.1.101"}, "v_catalog", "nodes")
+	assert.NoError(t, err)
+	assert.Contains(t, op.clusterHTTPRequest.RequestCollection["192.168.1.101"].RequestData, `"format":"json"`)
+}
+
+func TestScrutinizeProgress(t *testing.T) {
+	progress := &scrutinizeProgress{
+		Downloaded: make(map[string][]string),
+		filePath:   path.Join(t.TempDir(), scrutinizeProgressFileName),
+	}
+	hosts := []string{"192.168.1.101", "192.168.1.102"}
+	assert.Equal(t, hosts, progress.pendingHosts(hosts, scrutinizeBatchNormal, scrutinizeBatchContext))
+
+	// a host is only done once all of its batches are downloaded
+	assert.NoError(t, progress.markDownloaded(scrutinizeBatchNormal, "192.168.1.101"))
+	assert.Equal(t, hosts, progress.pendingHosts(hosts, scrutinizeBatchNormal, scrutinizeBatchContext))
+	assert.NoError(t, progress.markDownloaded(scrutinizeBatchContext, "192.168.1.101"))
+	assert.Equal(t, []string{"192.168.1.102"},
+		progress.pendingHosts(hosts, scrutinizeBatchNormal, scrutinizeBatchContext))
+
+	assert.False(t, progress.batchDownloaded(scrutinizeBatchSystemTables))
+	assert.NoError(t, progress.markDownloaded(scrutinizeBatchSystemTables, "192.168.1.102"))
+	assert.True(t, progress.batchDownloaded(scrutinizeBatchSystemTables))
+
+	// the progress is saved to the file
+	content, err := os.ReadFile(progress.filePath)
+	assert.NoError(t, err)
+	savedProgress := scrutinizeProgress{}
+	assert.NoError(t, json.Unmarshal(content, &savedProgress))
+	assert.Equal(t, progress.Downloaded, savedProgress.Downloaded)
+
+	assert.NoError(t, progress.remove())
+	assert.NoFileExists(t, progress.filePath)
+	// removing it again is not an error
+	assert.NoError(t, progress.remove())
+}
+
+func TestScrutinizeCompression(t *testing.T) {
+	assert.Equal(t, ".tgz", scrutinizeTarballExtension(ScrutinizeCompressionGzip))
+	assert.Equal(t, ".tar.zst", scrutinizeTarballExtension(ScrutinizeCompressionZstd))
+	assert.Equal(t, ".tar", scrutinizeTarballExtension(ScrutinizeCompressionNone))
+
+	assert.NoError(t, validateScrutinizeCompression(ScrutinizeCompressionZstd, "compression"))
+	assert.ErrorContains(t, validateScrutinizeCompression("bzip2", "compression"), `invalid compression "bzip2"`)
+
+	// the NMA is only asked for codecs other than its default
+	hosts := []string{"192.168.1.101"}
+	op, err := makeNMAGetScrutinizeTarOp("VerticaScrutinize.20240101000000", scrutinizeBatchNormal, hosts,
+		map[string]string{"192.168.1.101": "v_test_db_node0001"})
+	assert.NoError(t, err)
+	op.setupBasicInfo()
+	assert.NoError(t, op.setupClusterHTTPRequest(hosts))
+	assert.Empty(t, op.clusterHTTPRequest.RequestCollection["192.168.1.101"].QueryParams)
+	op.setCompression(ScrutinizeCompressionZstd)
+	assert.NoError(t, op.setupClusterHTTPRequest(hosts))
+	assert.Equal(t, map[string]string{"compression": ScrutinizeCompressionZstd},
+		op.clusterHTTPRequest.RequestCollection["192.168.1.101"].QueryParams)
+}
+
+type mockProgressReporter struct {
+	events []ProgressEvent
+}
+
+func (reporter *mockProgressReporter) ReportProgress(event ProgressEvent) {
+	reporter.events = append(reporter.events, event)
+}
+
+func TestScrutinizeProgressTracker(t *testing.T) {
+	// no reporter, no tracker
+	tracker := makeProgressTracker(nil, 2)
+	assert.Nil(t, tracker)
+	tracker.report("192.168.1.101", scrutinizeBatchNormal, ProgressStageDone, 0)
+
+	reporter := &mockProgressReporter{}
+	tracker = makeProgressTracker(reporter, 2)
+	tracker.report("192.168.1.101", scrutinizeBatchNormal, ProgressStageTarring, 0)
+	tracker.report("192.168.1.101", scrutinizeBatchNormal, ProgressStageDone, 1024)
+	tracker.report("192.168.1.101", scrutinizeBatchContext, ProgressStageFailed, 0)
+	assert.Len(t, reporter.events, 3)
+	assert.Equal(t, ProgressEvent{Host: "192.168.1.101", Item: scrutinizeBatchNormal, Stage: ProgressStageTarring,
+		Total: 2}, reporter.events[0])
+	// the remaining time is estimated once an item is done
+	assert.Equal(t, 1, reporter.events[1].Done)
+	assert.Equal(t, int64(1024), reporter.events[1].Bytes)
+	assert.Positive(t, reporter.events[1].ETA)
+	assert.Equal(t, 2, reporter.events[2].Done)
+	assert.Zero(t, reporter.events[2].ETA)
+}
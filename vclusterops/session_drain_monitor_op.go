@@ -0,0 +1,257 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sessionDrainMonitorOp runs the op that drains and stops the nodes, and
+// polls the client sessions that are still connected while the op waits for
+// them to disconnect. The sessions of the last poll are the sessions that the
+// shutdown closes when the drain ends.
+type sessionDrainMonitorOp struct {
+	opBase
+	opHTTPSBase
+	stopOp   clusterOp
+	scName   string
+	interval time.Duration
+	certs    *httpsCerts
+	// filled in with the sessions of the last poll once the op completes
+	closedSessions *[]SessionInfo
+	// receives the session count of each node at each poll, if it is set
+	reporter  ProgressReporter
+	drainTime time.Duration
+}
+
+func makeSessionDrainMonitorOp(stopOp clusterOp, useHTTPPassword bool, userName string, httpsPassword *string,
+	scName string, intervalSeconds int, closedSessions *[]SessionInfo) sessionDrainMonitorOp {
+	op := sessionDrainMonitorOp{}
+	op.name = "SessionDrainMonitorOp"
+	op.description = "Drain client sessions"
+	op.stopOp = stopOp
+	op.useHTTPPassword = useHTTPPassword
+	op.userName = userName
+	op.httpsPassword = httpsPassword
+	op.scName = scName
+	op.interval = time.Duration(intervalSeconds) * time.Second
+	op.closedSessions = closedSessions
+	return op
+}
+
+// setProgressReporter makes the op report the session count of each node at
+// each poll, with the drain time that is left as the ETA
+func (op *sessionDrainMonitorOp) setProgressReporter(reporter ProgressReporter, drainSeconds int) {
+	op.reporter = reporter
+	op.drainTime = time.Duration(drainSeconds) * time.Second
+}
+
+func (op *sessionDrainMonitorOp) prepare(_ *opEngineExecContext) error {
+	return nil
+}
+
+// loadCertsIfNeeded keeps the certs, which the stop op and the polls load
+func (op *sessionDrainMonitorOp) loadCertsIfNeeded(certs *httpsCerts, _ bool) error {
+	op.certs = certs
+	return nil
+}
+
+func (op *sessionDrainMonitorOp) execute(execContext *opEngineExecContext) error {
+	if op.certs == nil {
+		op.certs = &httpsCerts{}
+	}
+	// the nested instructions have no progress spinner, the op reports the sessions in its own
+	logger := op.logger
+	logger.ForCli = false
+
+	// the up hosts are copied, as the stop op runs with the exec context
+	ctx := execContext.dispatcher.ctx
+	upHosts := append([]string{}, execContext.upHosts...)
+	nodes := op.getDrainingNodes(execContext.nodesInfo)
+	startTime := time.Now()
+	poll := func() []SessionInfo {
+		sessions := op.pollSessions(ctx, upHosts)
+		if sessions != nil {
+			op.reportSessionCounts(sessions, nodes, time.Since(startTime))
+		}
+		return sessions
+	}
+	var mu sync.Mutex
+	lastSessions := poll()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	if op.interval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(op.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					sessions := poll()
+					mu.Lock()
+					if sessions != nil {
+						lastSessions = sessions
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	stopEngine := makeClusterOpEngine([]clusterOp{op.stopOp}, op.certs)
+	err := stopEngine.runInstructions(ctx, logger, execContext, stopEngine.shouldGetCertsFromOptions())
+	close(done)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+
+	if lastSessions == nil {
+		lastSessions = []SessionInfo{}
+	}
+	*op.closedSessions = lastSessions
+	if len(lastSessions) > 0 {
+		op.updateSpinnerStopMessage("closed %d sessions that were still connected: %s",
+			len(lastSessions), summarizeSessionUsers(lastSessions))
+	}
+	return nil
+}
+
+// pollSessions returns the client sessions that are still connected, or nil
+// if they cannot be read, such as when the polled node has just stopped
+func (op *sessionDrainMonitorOp) pollSessions(ctx context.Context, upHosts []string) []SessionInfo {
+	logger := op.logger
+	logger.ForCli = false
+	var sessions []SessionInfo
+	getSessionsOp, err := makeHTTPSGetSessionsOp(upHosts, op.useHTTPPassword, op.userName,
+		op.httpsPassword, op.scName, &sessions)
+	if err != nil {
+		logger.Info("fail to make the op to get the sessions", "error", err)
+		return nil
+	}
+	pollContext := makeOpEngineExecContext(logger)
+	pollContext.upHosts = upHosts
+	pollEngine := makeClusterOpEngine([]clusterOp{&getSessionsOp}, op.certs)
+	err = pollEngine.runInstructions(ctx, logger, &pollContext, pollEngine.shouldGetCertsFromOptions())
+	if err != nil {
+		logger.Info("fail to get the sessions that are still connected", "error", err)
+		return nil
+	}
+
+	logger.Info("sessions still connected", "count", len(sessions), "users", summarizeSessionUsers(sessions))
+	if len(sessions) == 0 {
+		op.updateSpinnerMessage("no sessions are connected")
+	} else {
+		op.updateSpinnerMessage("%d sessions are still connected: %s", len(sessions), summarizeSessionUsers(sessions))
+	}
+	return sessions
+}
+
+// getDrainingNodes returns the up nodes that drain, so that the nodes without
+// any sessions are reported too
+func (op *sessionDrainMonitorOp) getDrainingNodes(nodesInfo []NodeInfo) []NodeInfo {
+	var nodes []NodeInfo
+	for _, node := range nodesInfo {
+		if op.scName == "" || node.Subcluster == op.scName {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// reportSessionCounts reports the number of sessions still connected to each
+// node. A node is done draining once it has no sessions.
+func (op *sessionDrainMonitorOp) reportSessionCounts(sessions []SessionInfo, nodes []NodeInfo, elapsed time.Duration) {
+	if op.reporter == nil {
+		return
+	}
+	sessionCounts := make(map[string]int)
+	for _, session := range sessions {
+		sessionCounts[session.NodeName]++
+	}
+	nodeAddresses := make(map[string]string)
+	for _, node := range nodes {
+		nodeAddresses[node.Name] = node.Address
+	}
+	// the nodes of sessions are reported even if they are not known as up nodes
+	for nodeName := range sessionCounts {
+		if _, ok := nodeAddresses[nodeName]; !ok {
+			nodeAddresses[nodeName] = ""
+		}
+	}
+	nodeNames := make([]string, 0, len(nodeAddresses))
+	for nodeName := range nodeAddresses {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+
+	var eta time.Duration
+	if op.drainTime > elapsed {
+		eta = op.drainTime - elapsed
+	}
+	done := 0
+	for _, nodeName := range nodeNames {
+		if sessionCounts[nodeName] == 0 {
+			done++
+		}
+	}
+	for _, nodeName := range nodeNames {
+		op.reporter.ReportProgress(ProgressEvent{
+			Host:     nodeAddresses[nodeName],
+			Item:     nodeName,
+			Stage:    ProgressStageDraining,
+			Sessions: sessionCounts[nodeName],
+			Done:     done,
+			Total:    len(nodeNames),
+			ETA:      eta,
+		})
+	}
+}
+
+// summarizeSessionUsers returns the users of the sessions with their number
+// of sessions, such as "alice (2), bob (1)"
+func summarizeSessionUsers(sessions []SessionInfo) string {
+	userCounts := make(map[string]int)
+	for _, session := range sessions {
+		userCounts[session.UserName]++
+	}
+	users := make([]string, 0, len(userCounts))
+	for user := range userCounts {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	for i, user := range users {
+		users[i] = fmt.Sprintf("%s (%d)", user, userCounts[user])
+	}
+	return strings.Join(users, ", ")
+}
+
+func (op *sessionDrainMonitorOp) processResult(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *sessionDrainMonitorOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
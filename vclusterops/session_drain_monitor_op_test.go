@@ -0,0 +1,98 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestGetSessionsResult(t *testing.T) {
+	var sessions []SessionInfo
+	op, err := makeHTTPSGetSessionsOp([]string{"192.168.1.101"}, false, "", nil, "sc1", &sessions)
+	assert.NoError(t, err)
+	op.setLogger(vlog.Printer{})
+	op.setupBasicInfo()
+
+	// only the sessions of the subcluster are kept
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, statusCode: SuccessCode,
+			content: `{"session_list": [
+				{"node_name": "v_test_db_node0001", "subcluster_name": "sc1", "user_name": "alice"},
+				{"node_name": "v_test_db_node0002", "subcluster_name": "sc1", "user_name": "bob"},
+				{"node_name": "v_test_db_node0003", "subcluster_name": "sc2", "user_name": "alice"},
+				{"node_name": "v_test_db_node0001", "subcluster_name": "sc1", "user_name": "alice"}]}`},
+	}
+	assert.NoError(t, op.processResult(nil))
+	assert.Len(t, sessions, 3)
+	assert.Equal(t, "alice (2), bob (1)", summarizeSessionUsers(sessions))
+}
+
+func TestStopSCSessionDrainMonitor(t *testing.T) {
+	vcc := VClusterCommands{}
+	options := VStopSubclusterOptionsFactory()
+	options.SCName = "sc1"
+	options.Hosts = []string{"192.168.1.101"}
+
+	// the stop op runs in the monitor, which fills in the closed sessions
+	instructions, err := vcc.produceStopSCInstructions(&options)
+	assert.NoError(t, err)
+	monitorOp, ok := instructions[len(instructions)-2].(*sessionDrainMonitorOp)
+	assert.True(t, ok)
+	_, ok = monitorOp.stopOp.(*httpsStopSCOp)
+	assert.True(t, ok)
+	assert.Equal(t, &options.ForceClosedSessions, monitorOp.closedSessions)
+
+	options.SessionPollingSeconds = 0
+	instructions, err = vcc.produceStopSCInstructions(&options)
+	assert.NoError(t, err)
+	_, ok = instructions[len(instructions)-2].(*httpsStopSCOp)
+	assert.True(t, ok)
+}
+
+func TestReportSessionCounts(t *testing.T) {
+	reporter := &mockProgressReporter{}
+	op := makeSessionDrainMonitorOp(nil, false, "", nil, "sc1", 10, nil)
+	op.setProgressReporter(reporter, 60)
+
+	// the up nodes of the subcluster are reported, even without sessions
+	nodes := op.getDrainingNodes([]NodeInfo{
+		{Name: "v_test_db_node0001", Address: "192.168.1.101", Subcluster: "sc1"},
+		{Name: "v_test_db_node0002", Address: "192.168.1.102", Subcluster: "sc1"},
+		{Name: "v_test_db_node0003", Address: "192.168.1.103", Subcluster: "sc2"},
+	})
+	sessions := []SessionInfo{
+		{NodeName: "v_test_db_node0001", UserName: "alice"},
+		{NodeName: "v_test_db_node0001", UserName: "bob"},
+	}
+	op.reportSessionCounts(sessions, nodes, 20*time.Second)
+	assert.Equal(t, []ProgressEvent{
+		{Host: "192.168.1.101", Item: "v_test_db_node0001", Stage: ProgressStageDraining, Sessions: 2,
+			Done: 1, Total: 2, ETA: 40 * time.Second},
+		{Host: "192.168.1.102", Item: "v_test_db_node0002", Stage: ProgressStageDraining,
+			Done: 1, Total: 2, ETA: 40 * time.Second},
+	}, reporter.events)
+
+	// the drain time that is left is not negative
+	reporter.events = nil
+	op.reportSessionCounts(nil, nodes, 2*time.Minute)
+	assert.Len(t, reporter.events, 2)
+	assert.Equal(t, 2, reporter.events[0].Done)
+	assert.Zero(t, reporter.events[0].ETA)
+}
@@ -17,9 +17,13 @@ package vclusterops
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/vertica/vcluster/vclusterops/util"
 	"github.com/vertica/vcluster/vclusterops/vlog"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 // VStartDatabaseOptions represents the available options when you start a database
@@ -36,8 +40,55 @@ type VStartDatabaseOptions struct {
 	// you may not want to have both the NMA and Vertica server in the same container.
 	// This feature requires version 24.2.0+.
 	StartUpConf string
+	// Start the database even if the hosts to start do not include a quorum of the
+	// primary nodes in the catalog. This is for disaster recovery only, when most
+	// primary nodes are lost, and the database starts from the catalog of the
+	// surviving nodes, which may lose changes committed after they went down.
+	// A minority of the primary nodes can only form a cluster in an unsafe
+	// start, so this implies Unsafe.
+	ForceWithoutQuorum bool
+	// must be set to the database name when ForceWithoutQuorum is set, to confirm
+	// that the quorum check is skipped on purpose
+	ForceWithoutQuorumConfirmation string
+	// the sandbox to start, whose nodes must be the hosts. The catalogs
+	// of the nodes outside of the sandbox are not read.
+	Sandbox string
+	// the subclusters to start, found in the latest catalog. Only the hosts of
+	// their nodes are started, so they must include a quorum of the primary
+	// nodes.
+	Subclusters []string
+	// the number of nodes that are started together. The waves of nodes start
+	// one after another, in the order of the hosts, so that the spread and the
+	// communal storage are not flooded by a large cluster. 0 starts all the
+	// nodes together.
+	StartWaveSize int
+	// the seconds to wait between the waves of nodes
+	StartWaveDelaySeconds int
+	// start the database in read-only mode, in which no data or catalog
+	// changes are committed, for example to check a database in a DR drill
+	ReadOnly bool
+	// start the database without recovery, from the last catalog and data on
+	// disk. This is for disaster recovery only, and can lose committed data.
+	Unsafe bool
+	// start the database even if another cluster holds the lease of the
+	// communal storage, for example to start a copy of the database in a
+	// DR drill. Two clusters must never write to the same communal storage.
+	IgnoreClusterLease bool
+	// what start_db waits for before the database is considered started,
+	// WaitForUp by default
+	WaitPolicy WaitPolicy
+
+	// the names of the nodes to start, read from the latest catalog
+	startNodeNames []string
 }
 
+// the arguments of the vertica start command for the start modes
+const (
+	startArgReadOnly           = "--read-only"
+	startArgUnsafe             = "--unsafe"
+	startArgIgnoreClusterLease = "--ignore-cluster-lease"
+)
+
 func VStartDatabaseOptionsFactory() VStartDatabaseOptions {
 	opt := VStartDatabaseOptions{}
 
@@ -50,6 +101,8 @@ func VStartDatabaseOptionsFactory() VStartDatabaseOptions {
 func (options *VStartDatabaseOptions) setDefaultValues() {
 	options.DatabaseOptions.setDefaultValues()
 	options.StatePollingTimeout = util.DefaultStatePollingTimeout
+	options.StartWaveDelaySeconds = util.DefaultStartWaveDelaySeconds
+	options.WaitPolicy = WaitForUp
 }
 
 func (options *VStartDatabaseOptions) validateRequiredOptions(logger vlog.Printer) error {
@@ -58,6 +111,19 @@ func (options *VStartDatabaseOptions) validateRequiredOptions(logger vlog.Printe
 		return err
 	}
 
+	if options.ForceWithoutQuorum && options.ForceWithoutQuorumConfirmation != options.DBName {
+		return fmt.Errorf("to start the database without quorum, the confirmation must be the database name %s",
+			options.DBName)
+	}
+
+	if options.StartWaveSize < 0 || options.StartWaveDelaySeconds < 0 {
+		return fmt.Errorf("the start wave size and delay cannot be negative")
+	}
+
+	if err := options.WaitPolicy.validate(); err != nil {
+		return err
+	}
+
 	return options.validateCatalogPath()
 }
 
@@ -69,6 +135,22 @@ func (options *VStartDatabaseOptions) validateEonOptions() error {
 	return nil
 }
 
+// getStartArgs returns the arguments that are added to the start command of
+// each node for the start modes
+func (options *VStartDatabaseOptions) getStartArgs() []string {
+	var startArgs []string
+	if options.ReadOnly {
+		startArgs = append(startArgs, startArgReadOnly)
+	}
+	if options.Unsafe || options.ForceWithoutQuorum {
+		startArgs = append(startArgs, startArgUnsafe)
+	}
+	if options.IgnoreClusterLease {
+		startArgs = append(startArgs, startArgIgnoreClusterLease)
+	}
+	return startArgs
+}
+
 func (options *VStartDatabaseOptions) validateParseOptions(logger vlog.Printer) error {
 	// batch 1: validate required parameters
 	err := options.validateRequiredOptions(logger)
@@ -99,6 +181,8 @@ func (options *VStartDatabaseOptions) validateAnalyzeOptions(logger vlog.Printer
 }
 
 func (vcc VClusterCommands) VStartDatabase(options *VStartDatabaseOptions) (vdbPtr *VCoordinationDatabase, err error) {
+	defer vcc.startAudit("start_db", options)(&err)
+
 	/*
 	 *   - Produce Instructions
 	 *   - Create VClusterOpEngine
@@ -135,11 +219,19 @@ func (vcc VClusterCommands) VStartDatabase(options *VStartDatabaseOptions) (vdbP
 		}
 	}
 
+	// the nodes that are not started may be lost or in another sandbox,
+	// so we do not read their catalogs
+	if (options.ForceWithoutQuorum || options.Sandbox != "") && len(vdb.HostNodeMap) > 0 {
+		vdb.HostNodeMap = vdb.copyHostNodeMap(options.Hosts)
+		vdb.HostList = maps.Keys(vdb.HostNodeMap)
+	}
+
 	// start_db pre-checks and get basic info
 	err = vcc.runStartDBPrecheck(options, &vdb)
 	if err != nil {
 		return nil, err
 	}
+	vcc.warnStrayProcesses(options)
 
 	// produce start_db instructions
 	instructions, err := vcc.produceStartDBInstructions(options, &vdb)
@@ -152,7 +244,7 @@ func (vcc VClusterCommands) VStartDatabase(options *VStartDatabaseOptions) (vdbP
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// Give the instructions to the VClusterOpEngine to run
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if runError != nil {
 		return nil, fmt.Errorf("fail to start database: %w", runError)
 	}
@@ -169,7 +261,7 @@ func (vcc VClusterCommands) VStartDatabase(options *VStartDatabaseOptions) (vdbP
 
 func (vcc VClusterCommands) runStartDBPrecheck(options *VStartDatabaseOptions, vdb *VCoordinationDatabase) error {
 	// pre-instruction to perform basic checks and get basic information
-	preInstructions, err := vcc.produceStartDBPreCheck(options, vdb, options.TrimHostList)
+	preInstructions, err := vcc.produceStartDBPreCheck(options, vdb)
 	if err != nil {
 		return fmt.Errorf("fail to production instructions: %w", err)
 	}
@@ -177,7 +269,7 @@ func (vcc VClusterCommands) runStartDBPrecheck(options *VStartDatabaseOptions, v
 	// create a VClusterOpEngine for pre-check, and add certs to the engine
 	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
 	clusterOpEngine := makeClusterOpEngine(preInstructions, &certs)
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if runError != nil {
 		return fmt.Errorf("fail to start database pre-checks: %w", runError)
 	}
@@ -189,9 +281,101 @@ func (vcc VClusterCommands) runStartDBPrecheck(options *VStartDatabaseOptions, v
 		options.Hosts = vcc.removeHostsNotInCatalog(&clusterOpEngine.execContext.nmaVDatabase, options.Hosts)
 	}
 
+	if len(options.Subclusters) > 0 {
+		options.Hosts, err = vcc.filterHostsBySubclusters(&clusterOpEngine.execContext.nmaVDatabase,
+			options.Hosts, options.Subclusters)
+		if err != nil {
+			return err
+		}
+	}
+
+	options.startNodeNames = nil
+	for _, host := range options.Hosts {
+		if vnode, ok := clusterOpEngine.execContext.nmaVDatabase.HostNodeMap[host]; ok {
+			options.startNodeNames = append(options.startNodeNames, vnode.Name)
+		}
+	}
+
+	return vcc.checkStartDBQuorum(options, &clusterOpEngine.execContext.nmaVDatabase)
+}
+
+// warnStrayProcesses warns about the vertica and spread processes that still run
+// on the hosts to start. As the database is not running, they are likely zombie
+// processes, which can hold the catalog locks and make the nodes fail to start.
+func (vcc VClusterCommands) warnStrayProcesses(options *VStartDatabaseOptions) {
+	hostProcesses := make(map[string][]processInfo)
+	nmaGetProcessesOp := makeNMAGetProcessesOp(options.Hosts, hostProcesses)
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+	clusterOpEngine := makeClusterOpEngine([]clusterOp{&nmaGetProcessesOp}, &certs)
+	if err := clusterOpEngine.run(vcc.getContext(), vcc.Log); err != nil {
+		vcc.Log.Info("fail to get the processes of the hosts to start", "details", err)
+		return
+	}
+
+	strayProcesses := getStrayProcesses(hostProcesses)
+	for _, host := range options.Hosts {
+		if processes, ok := strayProcesses[host]; ok {
+			vcc.Log.PrintWarning("Host %s has stray processes %s, which can hold catalog locks and "+
+				"prevent the node from starting. Kill them if the node fails to start", host, strings.Join(processes, ", "))
+		}
+	}
+}
+
+// checkStartDBQuorum checks that the hosts to start include a quorum of the primary
+// nodes in the latest catalog. Without quorum, the started nodes cannot form a
+// cluster, so start_db is refused unless the check is explicitly overridden.
+func (vcc VClusterCommands) checkStartDBQuorum(options *VStartDatabaseOptions, vdb *nmaVDatabase) error {
+	var startPrimaryNodeCount uint
+	for _, host := range options.Hosts {
+		if vnode, ok := vdb.HostNodeMap[host]; ok && vnode.IsPrimary {
+			startPrimaryNodeCount++
+		}
+	}
+	if hasNodeUpQuorum(startPrimaryNodeCount, vdb.PrimaryNodeCount) {
+		return nil
+	}
+
+	if !options.ForceWithoutQuorum && len(options.Subclusters) > 0 {
+		return &QuorumError{Detail: fmt.Sprintf("the subclusters %v only have %d of %d primary nodes, which is not a quorum. "+
+			"Include the primary subclusters in the subclusters to start", options.Subclusters,
+			startPrimaryNodeCount, vdb.PrimaryNodeCount)}
+	}
+	if !options.ForceWithoutQuorum {
+		return &QuorumError{Detail: fmt.Sprintf("only %d of %d primary nodes are in the hosts to start, which is not a quorum. "+
+			"If the other primary nodes are lost, use the force-without-quorum option to start the database anyway",
+			startPrimaryNodeCount, vdb.PrimaryNodeCount)}
+	}
+	vcc.Log.PrintWarning("Starting the database with only %d of %d primary nodes, which is not a quorum. "+
+		"The database starts from the catalog of these nodes, and changes that were committed after "+
+		"they went down may be lost", startPrimaryNodeCount, vdb.PrimaryNodeCount)
 	return nil
 }
 
+// filterHostsBySubclusters returns the hosts of the nodes in the subclusters,
+// which must all be in the catalog
+func (vcc VClusterCommands) filterHostsBySubclusters(vdb *nmaVDatabase, hosts, subclusters []string) ([]string, error) {
+	foundSubclusters := make(map[string]bool)
+	var filteredHosts []string
+	for _, h := range hosts {
+		vnode, exist := vdb.HostNodeMap[h]
+		if !exist {
+			continue
+		}
+		if slices.Contains(subclusters, vnode.Subcluster.Name) {
+			foundSubclusters[vnode.Subcluster.Name] = true
+			filteredHosts = append(filteredHosts, h)
+		}
+	}
+
+	for _, sc := range subclusters {
+		if !foundSubclusters[sc] {
+			return nil, fmt.Errorf("subcluster %s has no nodes in the hosts of database %s", sc, vdb.Name)
+		}
+	}
+	vcc.Log.PrintInfo("Starting the hosts %v of the subclusters %v", filteredHosts, subclusters)
+	return filteredHosts, nil
+}
+
 func (vcc VClusterCommands) removeHostsNotInCatalog(vdb *nmaVDatabase, hosts []string) []string {
 	var trimmedHostList []string
 	var extraHosts []string
@@ -221,9 +405,8 @@ func (vcc VClusterCommands) removeHostsNotInCatalog(vdb *nmaVDatabase, hosts []s
 //   - Check NMA connectivity
 //   - Check to see if any dbs run
 //   - Get nodes' information by calling the NMA /nodes endpoint
-//   - Find latest catalog to use for removal of nodes not in the catalog
-func (vcc VClusterCommands) produceStartDBPreCheck(options *VStartDatabaseOptions, vdb *VCoordinationDatabase,
-	trimHostList bool) ([]clusterOp, error) {
+//   - Find latest catalog to use for removal of nodes not in the catalog and for the quorum check
+func (vcc VClusterCommands) produceStartDBPreCheck(options *VStartDatabaseOptions, vdb *VCoordinationDatabase) ([]clusterOp, error) {
 	var instructions []clusterOp
 
 	nmaHealthOp := makeNMAHealthOp(options.Hosts)
@@ -250,14 +433,12 @@ func (vcc VClusterCommands) produceStartDBPreCheck(options *VStartDatabaseOption
 		instructions = append(instructions, &nmaGetNodesInfoOp)
 	}
 
-	// find latest catalog to use for removal of nodes not in the catalog
-	if trimHostList {
-		nmaReadCatalogEditorOp, err := makeNMAReadCatalogEditorOp(vdb)
-		if err != nil {
-			return instructions, err
-		}
-		instructions = append(instructions, &nmaReadCatalogEditorOp)
+	// find latest catalog to use for removal of nodes not in the catalog and for the quorum check
+	nmaReadCatalogEditorOp, err := makeNMAReadCatalogEditorOp(vdb)
+	if err != nil {
+		return instructions, err
 	}
+	instructions = append(instructions, &nmaReadCatalogEditorOp)
 
 	return instructions, nil
 }
@@ -270,8 +451,8 @@ func (vcc VClusterCommands) produceStartDBPreCheck(options *VStartDatabaseOption
 //   - Use NMA /catalog/database to get the best source node for spread.conf and vertica.conf
 //   - Check Vertica versions
 //   - Sync the confs to the rest of nodes who have lower catalog version (results from the previous step)
-//   - Start all nodes of the database
-//   - Poll node startup
+//   - Start all nodes of the database, in waves if StartWaveSize is set
+//   - Poll node startup, as the wait policy asks
 //   - Sync catalog (Eon mode only)
 func (vcc VClusterCommands) produceStartDBInstructions(options *VStartDatabaseOptions, vdb *VCoordinationDatabase) ([]clusterOp, error) {
 	var instructions []clusterOp
@@ -304,19 +485,19 @@ func (vcc VClusterCommands) produceStartDBInstructions(options *VStartDatabaseOp
 		options.Hosts,
 		nil /*db configurations retrieved from a running db*/)
 
-	nmaStartNewNodesOp := makeNMAStartNodeOp(options.Hosts, options.StartUpConf)
-	httpsPollNodeStateOp, err := makeHTTPSPollNodeStateOpWithTimeoutAndCommand(options.Hosts,
-		options.usePassword, options.UserName, options.Password, options.StatePollingTimeout, StartDBCmd)
+	instructions = append(instructions, makeStartWaveOps(options)...)
+
+	waitPolicyOps, err := makeWaitPolicyOps(vcc.Log, options.WaitPolicy, options.Hosts, options.startNodeNames,
+		options.IsEon, &options.DatabaseOptions, options.StatePollingTimeout, StartDBCmd)
 	if err != nil {
 		return instructions, err
 	}
+	instructions = append(instructions, waitPolicyOps...)
 
-	instructions = append(instructions,
-		&nmaStartNewNodesOp,
-		&httpsPollNodeStateOp,
-	)
-
-	if options.IsEon {
+	// the catalog can only be synced once the nodes are UP
+	if options.IsEon && !options.WaitPolicy.waitsForUp() {
+		vcc.Log.PrintInfo("Skipping sync catalog, as the nodes are not polled until they are UP")
+	} else if options.IsEon {
 		httpsSyncCatalogOp, err := makeHTTPSSyncCatalogOp(options.Hosts, options.usePassword, options.UserName, options.Password, StartDBSyncCat)
 		if err != nil {
 			return instructions, err
@@ -327,6 +508,32 @@ func (vcc VClusterCommands) produceStartDBInstructions(options *VStartDatabaseOp
 	return instructions, nil
 }
 
+// makeStartWaveOps returns the instructions that start the hosts in waves of
+// StartWaveSize nodes, with a wait between the waves
+func makeStartWaveOps(options *VStartDatabaseOptions) []clusterOp {
+	if options.StartWaveSize == 0 || len(options.Hosts) <= options.StartWaveSize {
+		nmaStartNewNodesOp := makeNMAStartNodeOp(options.Hosts, options.StartUpConf)
+		nmaStartNewNodesOp.startArgs = options.getStartArgs()
+		return []clusterOp{&nmaStartNewNodesOp}
+	}
+
+	var instructions []clusterOp
+	for start := 0; start < len(options.Hosts); start += options.StartWaveSize {
+		end := start + options.StartWaveSize
+		if end > len(options.Hosts) {
+			end = len(options.Hosts)
+		}
+		if start > 0 && options.StartWaveDelaySeconds > 0 {
+			waitOp := makeWaitOp(time.Duration(options.StartWaveDelaySeconds) * time.Second)
+			instructions = append(instructions, &waitOp)
+		}
+		nmaStartNewNodesOp := makeNMAStartNodeOp(options.Hosts[start:end], options.StartUpConf)
+		nmaStartNewNodesOp.startArgs = options.getStartArgs()
+		instructions = append(instructions, &nmaStartNewNodesOp)
+	}
+	return instructions
+}
+
 func (vcc VClusterCommands) setOrRotateEncryptionKey(keyType string) clusterOp {
 	vcc.Log.Info("adding instruction to set or rotate the key for spread encryption")
 	op := makeNMASpreadSecurityOp(vcc.Log, keyType)
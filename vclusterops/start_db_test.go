@@ -0,0 +1,277 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestCheckStartDBQuorum(t *testing.T) {
+	vcc := VClusterCommands{}
+	vdb := nmaVDatabase{PrimaryNodeCount: 3}
+	vdb.HostNodeMap = map[string]*nmaVNode{
+		"192.168.1.101": {IsPrimary: true},
+		"192.168.1.102": {IsPrimary: true},
+		"192.168.1.103": {IsPrimary: true},
+		"192.168.1.104": {IsPrimary: false},
+	}
+	options := VStartDatabaseOptionsFactory()
+
+	// two of three primary nodes are started
+	options.Hosts = []string{"192.168.1.101", "192.168.1.102"}
+	err := vcc.checkStartDBQuorum(&options, &vdb)
+	assert.NoError(t, err)
+
+	// secondary nodes do not count for quorum
+	options.Hosts = []string{"192.168.1.101", "192.168.1.104"}
+	err = vcc.checkStartDBQuorum(&options, &vdb)
+	assert.ErrorContains(t, err, "only 1 of 3 primary nodes are in the hosts to start")
+
+	// half of the primary nodes is not a quorum
+	vdb.PrimaryNodeCount = 4
+	vdb.HostNodeMap["192.168.1.104"].IsPrimary = true
+	options.Hosts = []string{"192.168.1.101", "192.168.1.102"}
+	err = vcc.checkStartDBQuorum(&options, &vdb)
+	assert.ErrorContains(t, err, "only 2 of 4 primary nodes are in the hosts to start")
+
+	options.ForceWithoutQuorum = true
+	err = vcc.checkStartDBQuorum(&options, &vdb)
+	assert.NoError(t, err)
+}
+
+func TestForceWithoutQuorumConfirmation(t *testing.T) {
+	options := VStartDatabaseOptionsFactory()
+	options.DBName = "test_db"
+	options.RawHosts = []string{"192.168.1.101"}
+	options.CatalogPrefix = "/data"
+	options.ForceWithoutQuorum = true
+
+	err := options.validateRequiredOptions(vlog.Printer{})
+	assert.ErrorContains(t, err, "the confirmation must be the database name test_db")
+
+	options.ForceWithoutQuorumConfirmation = "test_db"
+	err = options.validateRequiredOptions(vlog.Printer{})
+	assert.NoError(t, err)
+}
+
+func TestStartDBSubclusters(t *testing.T) {
+	vcc := VClusterCommands{}
+	vdb := nmaVDatabase{Name: "test_db", PrimaryNodeCount: 2}
+	vdb.HostNodeMap = map[string]*nmaVNode{
+		"192.168.1.101": {IsPrimary: true},
+		"192.168.1.102": {IsPrimary: true},
+		"192.168.1.103": {IsPrimary: false},
+		"192.168.1.104": {IsPrimary: false},
+	}
+	vdb.HostNodeMap["192.168.1.101"].Subcluster.Name = "sc_primary"
+	vdb.HostNodeMap["192.168.1.102"].Subcluster.Name = "sc_primary"
+	vdb.HostNodeMap["192.168.1.103"].Subcluster.Name = "sc_secondary"
+	vdb.HostNodeMap["192.168.1.104"].Subcluster.Name = "sc_other"
+	options := VStartDatabaseOptionsFactory()
+	options.Hosts = []string{"192.168.1.101", "192.168.1.102", "192.168.1.103", "192.168.1.104"}
+
+	hosts, err := vcc.filterHostsBySubclusters(&vdb, options.Hosts, []string{"sc_primary", "sc_secondary"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.101", "192.168.1.102", "192.168.1.103"}, hosts)
+
+	_, err = vcc.filterHostsBySubclusters(&vdb, options.Hosts, []string{"sc_primary", "sc_missing"})
+	assert.ErrorContains(t, err, "subcluster sc_missing has no nodes")
+
+	// the subclusters to start must have a quorum of the primary nodes
+	options.Subclusters = []string{"sc_secondary"}
+	options.Hosts, err = vcc.filterHostsBySubclusters(&vdb, options.Hosts, options.Subclusters)
+	assert.NoError(t, err)
+	err = vcc.checkStartDBQuorum(&options, &vdb)
+	assert.ErrorContains(t, err, "Include the primary subclusters")
+}
+
+func TestStartWaves(t *testing.T) {
+	options := VStartDatabaseOptionsFactory()
+	options.Hosts = []string{"192.168.1.101", "192.168.1.102", "192.168.1.103", "192.168.1.104", "192.168.1.105"}
+
+	// all the nodes are started together by default
+	instructions := makeStartWaveOps(&options)
+	assert.Len(t, instructions, 1)
+
+	// the waves are separated by a wait
+	options.StartWaveSize = 2
+	instructions = makeStartWaveOps(&options)
+	assert.Len(t, instructions, 5)
+	lastWave, ok := instructions[4].(*nmaStartNodeOp)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"192.168.1.105"}, lastWave.hosts)
+	wait, ok := instructions[1].(*waitOp)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(util.DefaultStartWaveDelaySeconds)*time.Second, wait.duration)
+
+	options.StartWaveDelaySeconds = 0
+	instructions = makeStartWaveOps(&options)
+	assert.Len(t, instructions, 3)
+
+	// the wait ends when the command is canceled
+	execContext := makeOpEngineExecContext(vlog.Printer{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	execContext.dispatcher.ctx = ctx
+	wait = &waitOp{duration: time.Hour}
+	assert.ErrorIs(t, wait.execute(&execContext), context.Canceled)
+}
+
+func TestStartModes(t *testing.T) {
+	options := VStartDatabaseOptionsFactory()
+	options.Hosts = []string{"192.168.1.101"}
+
+	// the start commands are not changed by default
+	assert.Empty(t, options.getStartArgs())
+
+	options.ReadOnly = true
+	options.IgnoreClusterLease = true
+	instructions := makeStartWaveOps(&options)
+	op, ok := instructions[0].(*nmaStartNodeOp)
+	assert.True(t, ok)
+	assert.Equal(t, []string{startArgReadOnly, startArgIgnoreClusterLease}, op.startArgs)
+
+	// the arguments are added to a copy of the start command
+	startCmd := []string{"/opt/vertica/bin/vertica", "-D", "/data/practice_db/v_practice_db_node0001_catalog"}
+	op.hostRequestBodyMap = make(map[string]string)
+	assert.NoError(t, op.updateHostRequestBodyMapFromNodeStartCommand(options.Hosts[0], startCmd))
+	startNodeData := startNodeRequestData{}
+	assert.NoError(t, json.Unmarshal([]byte(op.hostRequestBodyMap[options.Hosts[0]]), &startNodeData))
+	assert.Equal(t, append(startCmd, startArgReadOnly, startArgIgnoreClusterLease), startNodeData.StartCommand)
+	assert.Len(t, startCmd, 3)
+}
+
+func TestForceWithoutQuorumStartArgs(t *testing.T) {
+	options := VStartDatabaseOptionsFactory()
+	options.Hosts = []string{"192.168.1.101"}
+
+	// a minority of the primary nodes is started with an unsafe start
+	options.ForceWithoutQuorum = true
+	instructions := makeStartWaveOps(&options)
+	op, ok := instructions[0].(*nmaStartNodeOp)
+	assert.True(t, ok)
+	assert.Equal(t, []string{startArgUnsafe}, op.startArgs)
+
+	startCmd := []string{"/opt/vertica/bin/vertica", "-D", "/data/practice_db/v_practice_db_node0001_catalog"}
+	op.hostRequestBodyMap = make(map[string]string)
+	assert.NoError(t, op.updateHostRequestBodyMapFromNodeStartCommand(options.Hosts[0], startCmd))
+	startNodeData := startNodeRequestData{}
+	assert.NoError(t, json.Unmarshal([]byte(op.hostRequestBodyMap[options.Hosts[0]]), &startNodeData))
+	assert.Equal(t, append(startCmd, startArgUnsafe), startNodeData.StartCommand)
+
+	// the argument is not repeated when the unsafe start is also requested
+	options.Unsafe = true
+	assert.Equal(t, []string{startArgUnsafe}, options.getStartArgs())
+}
+
+func TestWaitPolicy(t *testing.T) {
+	options := VStartDatabaseOptionsFactory()
+	options.DBName = "test_db"
+	options.Hosts = []string{"192.168.1.101", "192.168.1.102"}
+	nodeNames := []string{"v_test_db_node0001", "v_test_db_node0002"}
+	assert.NoError(t, WaitPolicy("").validate())
+	assert.ErrorContains(t, WaitPolicy("running").validate(), "invalid wait policy")
+
+	getWaitPolicyOps := func(policy WaitPolicy, isEon bool) []clusterOp {
+		instructions, err := makeWaitPolicyOps(vlog.Printer{}, policy, options.Hosts, nodeNames, isEon,
+			&options.DatabaseOptions, options.StatePollingTimeout, StartDBCmd)
+		assert.NoError(t, err)
+		return instructions
+	}
+
+	// the nodes are not polled once their process is started
+	assert.Empty(t, getWaitPolicyOps(WaitForProcess, true))
+	instructions := getWaitPolicyOps(WaitForUp, true)
+	assert.Len(t, instructions, 1)
+	assert.Equal(t, 0, findInstruction[*httpsPollNodeStateOp](instructions))
+
+	// the subscriptions are only polled in Eon mode
+	instructions = getWaitPolicyOps(WaitForSubscriptions, true)
+	assert.Len(t, instructions, 2)
+	subscriptionOp, ok := instructions[1].(*httpsPollSubscriptionStateOp)
+	assert.True(t, ok)
+	assert.Equal(t, nodeNames, *subscriptionOp.nodesToPoll)
+	assert.Len(t, getWaitPolicyOps(WaitForSubscriptions, false), 1)
+
+	instructions = getWaitPolicyOps(WaitForConnections, false)
+	assert.Equal(t, 1, findInstruction[*pollClientConnectionsOp](instructions))
+}
+
+func TestPollClientConnections(t *testing.T) {
+	op := makePollClientConnectionsOp([]string{"192.168.1.101", "192.168.1.102"}, 10)
+	execContext := makeOpEngineExecContext(vlog.Printer{})
+	execContext.nmaVDatabase.HostNodeMap = map[string]*nmaVNode{"192.168.1.102": {ClientPort: "5434"}}
+	assert.NoError(t, op.prepare(&execContext))
+
+	// the client port of the catalog is used when it is known
+	var dialedAddresses []string
+	op.dial = func(_, address string, _ time.Duration) (net.Conn, error) {
+		dialedAddresses = append(dialedAddresses, address)
+		if address == "192.168.1.101:5433" {
+			return nil, errors.New("connection refused")
+		}
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	}
+	assert.NoError(t, op.runExecute(&execContext))
+	assert.Equal(t, []string{"192.168.1.101:5433", "192.168.1.102:5434"}, dialedAddresses)
+	assert.Equal(t, []string{"192.168.1.101"}, op.pendingHosts)
+	stop, err := op.shouldStopPolling()
+	assert.NoError(t, err)
+	assert.False(t, stop)
+
+	// only the pending hosts are polled again
+	dialedAddresses = nil
+	op.ports["192.168.1.101"] = 5435
+	assert.NoError(t, op.runExecute(&execContext))
+	assert.Equal(t, []string{"192.168.1.101:5435"}, dialedAddresses)
+	stop, err = op.shouldStopPolling()
+	assert.NoError(t, err)
+	assert.True(t, stop)
+}
+
+func TestGetStrayProcesses(t *testing.T) {
+	hosts := []string{"192.168.1.101", "192.168.1.102", "192.168.1.103"}
+	hostProcesses := make(map[string][]processInfo)
+	op := makeNMAGetProcessesOp(hosts, hostProcesses)
+	op.clusterHTTPRequest.ResultCollection = map[string]hostHTTPResult{
+		"192.168.1.101": {status: SUCCESS, content: `{"processes": [
+			{"name": "nma", "pid": 100, "start_time": "2024-03-01T10:00:00Z"},
+			{"name": "vertica", "pid": 200, "start_time": "2024-03-01T10:12:33Z"},
+			{"name": "spread", "pid": 201, "start_time": "2024-03-01T10:12:32Z"}]}`},
+		"192.168.1.102": {status: SUCCESS, content: `{"processes": [{"name": "nma", "pid": 100}]}`},
+		// a host whose NMA fails does not fail the op
+		"192.168.1.103": {status: FAILURE, err: errors.New("connection refused")},
+	}
+	assert.NoError(t, op.processResult(nil))
+	assert.Len(t, hostProcesses, 2)
+
+	strayProcesses := getStrayProcesses(hostProcesses)
+	assert.Equal(t, map[string][]string{"192.168.1.101": {
+		"vertica (pid 200, started at 2024-03-01T10:12:33Z)",
+		"spread (pid 201, started at 2024-03-01T10:12:32Z)",
+	}}, strayProcesses)
+}
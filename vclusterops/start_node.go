@@ -28,8 +28,13 @@ import (
 type VStartNodesOptions struct {
 	// basic db info
 	DatabaseOptions
-	// A set of nodes(nodename - host) that we want to start in the database
+	// A set of nodes(nodename - host) that we want to start in the database.
+	// An empty host means that the node will be started with the address
+	// stored in the catalog.
 	Nodes map[string]string
+	// A list of hosts whose nodes we want to start in the database. This can be
+	// used instead of Nodes when the node names are not known.
+	StartHosts []string
 	// timeout for polling nodes that we want to start in httpsPollNodeStateOp
 	StatePollingTimeout int
 	// If the path is set, the NMA will store the Vertica start command at the path
@@ -37,6 +42,12 @@ type VStartNodesOptions struct {
 	// you may not want to have both the NMA and Vertica server in the same container.
 	// This feature requires version 24.2.0+.
 	StartUpConf string
+	// number of times to start again the nodes that are still down when the
+	// nodes fail to start or to come up, 0 means that the nodes are started only once
+	StartRetries int
+	// what restart_node waits for before the nodes are considered started,
+	// WaitForUp by default
+	WaitPolicy WaitPolicy
 }
 
 type VStartNodesInfo struct {
@@ -63,9 +74,16 @@ func VStartNodesOptionsFactory() VStartNodesOptions {
 
 func (options *VStartNodesOptions) setDefaultValues() {
 	options.DatabaseOptions.setDefaultValues()
+	options.WaitPolicy = WaitForUp
 }
 
 func (options *VStartNodesOptions) validateParseOptions(logger vlog.Printer) error {
+	if options.StartRetries < 0 {
+		return fmt.Errorf("the number of retries cannot be negative")
+	}
+	if err := options.WaitPolicy.validate(); err != nil {
+		return err
+	}
 	return options.validateBaseOptions("restart_node", logger)
 }
 
@@ -97,6 +115,40 @@ func (options *VStartNodesOptions) ParseNodesList(rawNodeMap map[string]string)
 	return nil
 }
 
+// ParseNodeNamesList builds a nodeName-host map from a list of node names. The
+// nodes will be started with the addresses stored in the catalog.
+func (options *VStartNodesOptions) ParseNodeNamesList(nodeNames []string) {
+	options.Nodes = make(map[string]string)
+	for _, nodeName := range nodeNames {
+		options.Nodes[nodeName] = ""
+	}
+}
+
+// ParseHostToStartList resolves the hosts whose nodes we want to start to IP addresses
+func (options *VStartNodesOptions) ParseHostToStartList(rawHosts []string) (err error) {
+	options.StartHosts, err = util.ResolveRawHostsToAddresses(rawHosts, options.IPv6)
+	return err
+}
+
+// addNodesFromStartHosts looks up the node names of StartHosts in the catalog
+// and adds them to the set of nodes to start
+func (options *VStartNodesOptions) addNodesFromStartHosts(vdb *VCoordinationDatabase) error {
+	if len(options.StartHosts) == 0 {
+		return nil
+	}
+	if options.Nodes == nil {
+		options.Nodes = make(map[string]string)
+	}
+	_, hostsNotInCatalog := vdb.containNodes(options.StartHosts)
+	if len(hostsNotInCatalog) > 0 {
+		return fmt.Errorf("hosts %v are not found in the catalog", hostsNotInCatalog)
+	}
+	for _, host := range options.StartHosts {
+		options.Nodes[vdb.HostNodeMap[host].Name] = host
+	}
+	return nil
+}
+
 func (options *VStartNodesOptions) validateAnalyzeOptions(logger vlog.Printer) error {
 	if err := options.validateParseOptions(logger); err != nil {
 		return err
@@ -130,12 +182,35 @@ func (vcc VClusterCommands) startNodePreCheck(vdb *VCoordinationDatabase, option
 	return nil
 }
 
+// startNodeQuorumCheck verifies that the main cluster or the sandbox of the nodes
+// to start has not lost quorum. Starting nodes requires a running cluster, so
+// the user needs to call start_db if the quorum is already lost.
+func (vcc VClusterCommands) startNodeQuorumCheck(vdb *VCoordinationDatabase, sandbox string) error {
+	var primaryNodeCount, upPrimaryNodeCount uint
+	for _, vnode := range vdb.HostNodeMap {
+		if vnode.Sandbox != sandbox || !vnode.IsPrimary {
+			continue
+		}
+		primaryNodeCount++
+		if vnode.State == util.NodeUpState {
+			upPrimaryNodeCount++
+		}
+	}
+	if !hasNodeUpQuorum(upPrimaryNodeCount, primaryNodeCount) {
+		return &QuorumError{Detail: fmt.Sprintf("quorum check failed: only %d of %d primary nodes are up, "+
+			"use start_db to start the database after quorum is lost", upPrimaryNodeCount, primaryNodeCount)}
+	}
+	return nil
+}
+
 // VStartNodes starts the given nodes for a cluster that has not yet lost
 // cluster quorum. Returns any error encountered. If necessary, it updates the
 // node's IP in the Vertica catalog. If cluster quorum is already lost, use
 // VStartDatabase. It will skip any nodes given that no longer exist in the
-// catalog.
-func (vcc VClusterCommands) VStartNodes(options *VStartNodesOptions) error {
+// catalog or that are already up.
+func (vcc VClusterCommands) VStartNodes(options *VStartNodesOptions) (err error) {
+	defer vcc.startAudit("start_node", options)(&err)
+
 	/*
 	 *   - Produce Instructions
 	 *   - Create a VClusterOpEngine
@@ -148,7 +223,7 @@ func (vcc VClusterCommands) VStartNodes(options *VStartNodesOptions) error {
 	}
 
 	// validate and analyze options
-	err := options.validateAnalyzeOptions(vcc.Log)
+	err = options.validateAnalyzeOptions(vcc.Log)
 	if err != nil {
 		return err
 	}
@@ -167,6 +242,12 @@ func (vcc VClusterCommands) VStartNodes(options *VStartNodesOptions) error {
 		hostNodeNameMap[vnode.Name] = vnode.Address
 	}
 
+	// find the nodes of the hosts to start in the catalog
+	err = options.addNodesFromStartHosts(&vdb)
+	if err != nil {
+		return err
+	}
+
 	// precheck to make sure the nodes to start are either all sandboxed nodes in one sandbox or all main cluster nodes
 	err = vcc.startNodePreCheck(&vdb, options, hostNodeNameMap, restartNodeInfo)
 	if err != nil {
@@ -182,6 +263,11 @@ func (vcc VClusterCommands) VStartNodes(options *VStartNodesOptions) error {
 		return errors.Join(err, fmt.Errorf("hint: make sure there is at least one UP node in the database"))
 	}
 
+	err = vcc.startNodeQuorumCheck(&vdb, restartNodeInfo.Sandbox)
+	if err != nil {
+		return err
+	}
+
 	for nodename, newIP := range options.Nodes {
 		oldIP, ok := hostNodeNameMap[nodename]
 		if !ok {
@@ -192,6 +278,15 @@ func (vcc VClusterCommands) VStartNodes(options *VStartNodesOptions) error {
 				"nodename", nodename, "newIP", newIP)
 			continue
 		}
+		// an empty IP means that the node keeps the address in the catalog
+		if newIP == "" {
+			newIP = oldIP
+		}
+		// the node is already running with the same address, so there is nothing to start
+		if vnode, exists := vdb.HostNodeMap[oldIP]; exists && oldIP == newIP && vnode.State == util.NodeUpState {
+			vcc.Log.PrintInfo("skipping start of node %s because it is already up", nodename)
+			continue
+		}
 		// if the IP that is given is different than the IP in the catalog, a re-ip is necessary
 		if oldIP != newIP {
 			restartNodeInfo.ReIPList = append(restartNodeInfo.ReIPList, newIP)
@@ -208,9 +303,10 @@ func (vcc VClusterCommands) VStartNodes(options *VStartNodesOptions) error {
 	restartNodeInfo.HostsToStart = append(restartNodeInfo.HostsToStart, hostsNoNeedToReIP...)
 
 	// If no nodes found to start. We can simply exit here. This can happen if
-	// given a list of nodes that aren't in the catalog any longer.
+	// given a list of nodes that aren't in the catalog any longer or that are
+	// already up.
 	if len(restartNodeInfo.HostsToStart) == 0 {
-		vcc.Log.Info("None of the nodes provided are in the catalog. There is nothing to start.")
+		vcc.Log.Info("None of the nodes provided are down in the catalog. There is nothing to start.")
 		return nil
 	}
 
@@ -225,13 +321,131 @@ func (vcc VClusterCommands) VStartNodes(options *VStartNodesOptions) error {
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// Give the instructions to the VClusterOpEngine to run
-	err = clusterOpEngine.run(vcc.Log)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	opEngine := &clusterOpEngine
+	for retry := 1; err != nil && retry <= options.StartRetries && canRetryStartNodes(opEngine, err); retry++ {
+		vcc.Log.PrintWarning("fail to restart node, retrying (%d/%d), details: %v", retry, options.StartRetries, err)
+		opEngine, err = vcc.retryStartNodes(restartNodeInfo, options, &vdb)
+	}
 	if err != nil {
 		return fmt.Errorf("fail to restart node, %w", err)
 	}
 	return nil
 }
 
+// canRetryStartNodes returns true if the op engine failed while starting the
+// nodes or polling them, so that starting the nodes again may succeed. The
+// failures of the other instructions, such as the re-IP or the version check,
+// are not retried.
+func canRetryStartNodes(opEngine *VClusterOpEngine, err error) bool {
+	var canceledErr *OpEngineCanceledError
+	if opEngine == nil || errors.As(err, &canceledErr) || opEngine.numSucceeded >= len(opEngine.instructions) {
+		return false
+	}
+	switch opEngine.instructions[opEngine.numSucceeded].(type) {
+	case *nmaStartNodeOp, *httpsPollNodeStateOp, *httpsPollSubscriptionStateOp, *pollClientConnectionsOp:
+		return true
+	}
+	return false
+}
+
+// retryStartNodes polls the states of the nodes again, starts the nodes that are
+// still down and waits for them to recover. The nodes that came up in the previous
+// attempt are not started again. The catalog and confs have already been synced by
+// the first attempt. It returns the op engine that started the nodes, which is nil
+// if the nodes were not started.
+func (vcc VClusterCommands) retryStartNodes(startNodeInfo *VStartNodesInfo, options *VStartNodesOptions,
+	vdb *VCoordinationDatabase) (*VClusterOpEngine, error) {
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+	httpsGetNodesInfoOp, err := makeHTTPSGetNodesInfoOp(options.DBName, options.Hosts,
+		options.usePassword, options.UserName, options.Password, vdb, true, startNodeInfo.Sandbox)
+	if err != nil {
+		return nil, err
+	}
+	getNodesInfoEngine := makeClusterOpEngine([]clusterOp{&httpsGetNodesInfoOp}, &certs)
+	err = getNodesInfoEngine.run(vcc.getContext(), vcc.Log)
+	if err != nil {
+		return nil, fmt.Errorf("fail to poll the node states before retrying, %w", err)
+	}
+
+	retryNodeInfo := *startNodeInfo
+	retryNodeInfo.HostsToStart = getDownHosts(vdb, startNodeInfo.HostsToStart)
+	if len(retryNodeInfo.HostsToStart) == 0 {
+		vcc.Log.PrintInfo("All the nodes to start are no longer down, skipping the retry")
+		return nil, nil
+	}
+
+	httpsRestartUpCommandOp, err := makeHTTPSStartUpCommandWithSandboxOp(options.usePassword, options.UserName, options.Password,
+		vdb, startNodeInfo.Sandbox)
+	if err != nil {
+		return nil, err
+	}
+	nmaRestartNewNodesOp := makeNMAStartNodeOpWithVDB(retryNodeInfo.HostsToStart, options.StartUpConf, vdb)
+	waitPolicyOps, err := vcc.makeStartNodesWaitPolicyOps(&retryNodeInfo, options, vdb)
+	if err != nil {
+		return nil, err
+	}
+
+	instructions := []clusterOp{
+		&httpsRestartUpCommandOp,
+		&nmaRestartNewNodesOp,
+	}
+	instructions = append(instructions, waitPolicyOps...)
+	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
+	return &clusterOpEngine, clusterOpEngine.run(vcc.getContext(), vcc.Log)
+}
+
+// getDownHosts returns the hosts whose nodes are down in the vdb
+func getDownHosts(vdb *VCoordinationDatabase, hosts []string) []string {
+	var downHosts []string
+	for _, host := range hosts {
+		if vnode, ok := vdb.HostNodeMap[host]; ok && vnode.State == util.NodeDownState {
+			downHosts = append(downHosts, host)
+		}
+	}
+	return downHosts
+}
+
+// makeStartNodesWaitPolicyOps returns the ops that wait for the nodes to start
+// as the wait policy asks
+func (vcc VClusterCommands) makeStartNodesWaitPolicyOps(startNodeInfo *VStartNodesInfo, options *VStartNodesOptions,
+	vdb *VCoordinationDatabase) ([]clusterOp, error) {
+	var nodeNames []string
+	for _, host := range startNodeInfo.HostsToStart {
+		if vnode, ok := vdb.HostNodeMap[host]; ok {
+			nodeNames = append(nodeNames, vnode.Name)
+		}
+	}
+	return makeWaitPolicyOps(vcc.Log, options.WaitPolicy, startNodeInfo.HostsToStart, nodeNames, vdb.IsEon,
+		&options.DatabaseOptions, options.StatePollingTimeout, StartNodeCmd)
+}
+
+// getVDBForCatalogCheck returns a vdb with the hosts to start and the up primary hosts.
+// Their catalog will be read to report which hosts to start have a stale catalog.
+func getVDBForCatalogCheck(vdb *VCoordinationDatabase, hostsToStart []string) VCoordinationDatabase {
+	hosts := util.CopySlice(hostsToStart)
+	for host, vnode := range vdb.HostNodeMap {
+		if vnode.IsPrimary && vnode.State == util.NodeUpState {
+			hosts = append(hosts, host)
+		}
+	}
+
+	catalogCheckVDB := vdb.copy(hosts)
+	// the catalog editor expects the catalog directory, not the Catalog subdirectory
+	// returned by the https service, so we do not modify the nodes of the input vdb
+	catalogCheckVDB.HostNodeMap = makeVHostNodeMap()
+	for _, host := range hosts {
+		vnode, ok := vdb.HostNodeMap[host]
+		if !ok {
+			continue
+		}
+		vnodeCopy := *vnode
+		vnodeCopy.CatalogPath = getCatalogPath(vnode.CatalogPath)
+		catalogCheckVDB.HostNodeMap[host] = &vnodeCopy
+	}
+	return catalogCheckVDB
+}
+
 // produceStartNodesInstructions will build a list of instructions to execute for
 // the restart_node command.
 //
@@ -245,11 +459,12 @@ func (vcc VClusterCommands) VStartNodes(options *VStartNodesOptions) error {
 //     3. Reload spread
 //     4. Call https /v1/nodes to update nodes' info
 //   - Check Vertica versions
+//   - If no re-ip is needed, use NMA /catalog/database to report the nodes to be restarted with a stale catalog
 //   - Use any UP primary nodes as source host for syncing spread.conf and vertica.conf
 //   - Sync the confs to the nodes to be restarted
 //   - Call https /v1/startup/command to get restart command of the nodes to be restarted
 //   - restart nodes
-//   - Poll node start up
+//   - Poll node start up, as the wait policy asks
 //   - sync catalog
 func (vcc VClusterCommands) produceStartNodesInstructions(startNodeInfo *VStartNodesInfo, options *VStartNodesOptions,
 	vdb *VCoordinationDatabase) ([]clusterOp, error) {
@@ -309,6 +524,16 @@ func (vcc VClusterCommands) produceStartNodesInstructions(startNodeInfo *VStartN
 	// we use information from v1/nodes endpoint to get all node information to update the sourceConfHost value
 	// after we find any UP primary nodes as source host for syncing spread.conf and vertica.conf
 	// we will remove the nil parameters in VER-88401 by adding them in execContext
+	if len(startNodeInfo.ReIPList) == 0 {
+		// report the nodes to start whose catalog is behind the up primary nodes
+		catalogCheckVDB := getVDBForCatalogCheck(vdb, startNodeInfo.HostsToStart)
+		nmaReadCatalogEditorOp, e := makeNMAReadCatalogEditorOp(&catalogCheckVDB)
+		if e != nil {
+			return instructions, e
+		}
+		nmaReadCatalogEditorOp.reportStaleHosts = true
+		instructions = append(instructions, &nmaReadCatalogEditorOp)
+	}
 	produceTransferConfigOps(
 		&instructions,
 		nil, /*source hosts for transferring configuration files*/
@@ -322,8 +547,7 @@ func (vcc VClusterCommands) produceStartNodesInstructions(startNodeInfo *VStartN
 	}
 
 	nmaRestartNewNodesOp := makeNMAStartNodeOpWithVDB(startNodeInfo.HostsToStart, options.StartUpConf, vdb)
-	httpsPollNodeStateOp, err := makeHTTPSPollNodeStateOpWithTimeoutAndCommand(startNodeInfo.HostsToStart,
-		options.usePassword, options.UserName, options.Password, options.StatePollingTimeout, StartNodeCmd)
+	waitPolicyOps, err := vcc.makeStartNodesWaitPolicyOps(startNodeInfo, options, vdb)
 	if err != nil {
 		return instructions, err
 	}
@@ -331,10 +555,13 @@ func (vcc VClusterCommands) produceStartNodesInstructions(startNodeInfo *VStartN
 	instructions = append(instructions,
 		&httpsRestartUpCommandOp,
 		&nmaRestartNewNodesOp,
-		&httpsPollNodeStateOp,
 	)
+	instructions = append(instructions, waitPolicyOps...)
 
-	if vdb.IsEon {
+	// the catalog can only be synced once the nodes are UP
+	if vdb.IsEon && !options.WaitPolicy.waitsForUp() {
+		vcc.Log.PrintInfo("Skipping sync catalog, as the nodes are not polled until they are UP")
+	} else if vdb.IsEon {
 		httpsSyncCatalogOp, err := makeHTTPSSyncCatalogOp(options.Hosts, options.usePassword, options.UserName,
 			options.Password, StartNodeSyncCat)
 		if err != nil {
@@ -0,0 +1,158 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestStartNodeQuorumCheck(t *testing.T) {
+	vcc := VClusterCommands{}
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{IsPrimary: true, State: util.NodeUpState}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{IsPrimary: true, State: util.NodeDownState}
+	vdb.HostNodeMap["192.168.1.103"] = &VCoordinationNode{IsPrimary: true, State: util.NodeDownState}
+	vdb.HostNodeMap["192.168.1.104"] = &VCoordinationNode{IsPrimary: false, State: util.NodeUpState}
+
+	// one of three primary nodes is up
+	err := vcc.startNodeQuorumCheck(&vdb, util.MainClusterSandbox)
+	assert.ErrorContains(t, err, "only 1 of 3 primary nodes are up")
+
+	// two of three primary nodes are up
+	vdb.HostNodeMap["192.168.1.102"].State = util.NodeUpState
+	err = vcc.startNodeQuorumCheck(&vdb, util.MainClusterSandbox)
+	assert.NoError(t, err)
+
+	// one of two primary nodes is not a quorum
+	delete(vdb.HostNodeMap, "192.168.1.103")
+	vdb.HostNodeMap["192.168.1.102"].State = util.NodeDownState
+	err = vcc.startNodeQuorumCheck(&vdb, util.MainClusterSandbox)
+	assert.ErrorContains(t, err, "only 1 of 2 primary nodes are up")
+
+	// nodes from other sandboxes are ignored
+	vdb.HostNodeMap["192.168.1.105"] = &VCoordinationNode{IsPrimary: true, State: util.NodeDownState, Sandbox: "sand"}
+	err = vcc.startNodeQuorumCheck(&vdb, "sand")
+	assert.ErrorContains(t, err, "only 0 of 1 primary nodes are up")
+}
+
+func TestAddNodesFromStartHosts(t *testing.T) {
+	options := VStartNodesOptionsFactory()
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Name: "v_test_db_node0001", Address: "192.168.1.101"}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{Name: "v_test_db_node0002", Address: "192.168.1.102"}
+
+	options.ParseNodeNamesList([]string{"v_test_db_node0001"})
+	options.StartHosts = []string{"192.168.1.102"}
+	err := options.addNodesFromStartHosts(&vdb)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"v_test_db_node0001": "", "v_test_db_node0002": "192.168.1.102"}, options.Nodes)
+
+	// hosts that are not in the catalog are rejected
+	options.StartHosts = []string{"192.168.1.103"}
+	err = options.addNodesFromStartHosts(&vdb)
+	assert.ErrorContains(t, err, "hosts [192.168.1.103] are not found in the catalog")
+}
+
+func TestGetVDBForCatalogCheck(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Address: "192.168.1.101", IsPrimary: true,
+		State: util.NodeUpState, CatalogPath: "/data/test_db/v_test_db_node0001_catalog/Catalog"}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{Address: "192.168.1.102", IsPrimary: false,
+		State: util.NodeUpState, CatalogPath: "/data/test_db/v_test_db_node0002_catalog/Catalog"}
+	vdb.HostNodeMap["192.168.1.103"] = &VCoordinationNode{Address: "192.168.1.103", IsPrimary: true,
+		State: util.NodeDownState, CatalogPath: "/data/test_db/v_test_db_node0003_catalog/Catalog"}
+
+	catalogCheckVDB := getVDBForCatalogCheck(&vdb, []string{"192.168.1.103"})
+	// the hosts to start and the up primary hosts are checked
+	assert.Len(t, catalogCheckVDB.HostNodeMap, 2)
+	assert.Equal(t, "/data/test_db/v_test_db_node0001_catalog",
+		catalogCheckVDB.HostNodeMap["192.168.1.101"].CatalogPath)
+	assert.Equal(t, "/data/test_db/v_test_db_node0003_catalog",
+		catalogCheckVDB.HostNodeMap["192.168.1.103"].CatalogPath)
+	// the input vdb is not modified
+	assert.Equal(t, "/data/test_db/v_test_db_node0001_catalog/Catalog",
+		vdb.HostNodeMap["192.168.1.101"].CatalogPath)
+}
+
+func TestReportStaleCatalogHosts(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	op, err := makeNMAReadCatalogEditorOp(&vdb)
+	assert.NoError(t, err)
+	op.reportStaleHosts = true
+	op.clusterHTTPRequest.ResultCollection = make(map[string]hostHTTPResult)
+	op.clusterHTTPRequest.ResultCollection["192.168.1.101"] = hostHTTPResult{status: SUCCESS,
+		content: `{"name": "test_db", "versions": {"global": 12}}`}
+	op.clusterHTTPRequest.ResultCollection["192.168.1.102"] = hostHTTPResult{status: SUCCESS,
+		content: `{"name": "test_db", "versions": {"global": 10}}`}
+	op.clusterHTTPRequest.ResultCollection["192.168.1.103"] = hostHTTPResult{status: FAILURE,
+		err: errors.New("connection refused")}
+
+	// the failed host is skipped, and the catalog of the exec context is kept
+	execContext := makeOpEngineExecContext(vlog.Printer{})
+	execContext.nmaVDatabase.Name = "kept"
+	assert.NoError(t, op.processResult(&execContext))
+	assert.Equal(t, []string{"192.168.1.102"}, op.staleHosts)
+	assert.Equal(t, "kept", execContext.nmaVDatabase.Name)
+	assert.Empty(t, execContext.hostsWithLatestCatalog)
+}
+
+func TestCanRetryStartNodes(t *testing.T) {
+	hosts := []string{"192.168.1.101"}
+	nmaHealthOp := makeNMAHealthOp(hosts)
+	nmaVerticaVersionOp := makeNMAVerticaVersionOp(hosts, true, false)
+	nmaStartNodeOp := makeNMAStartNodeOp(hosts, "")
+	httpsPollNodeStateOp, err := makeHTTPSPollNodeStateOp(hosts, false, "", nil)
+	assert.NoError(t, err)
+	opEngine := makeClusterOpEngine([]clusterOp{&nmaHealthOp, &nmaVerticaVersionOp, &nmaStartNodeOp,
+		&httpsPollNodeStateOp}, &httpsCerts{})
+	failure := errors.New("failed")
+
+	// the failures before the nodes are started are not retried
+	opEngine.numSucceeded = 1
+	assert.False(t, canRetryStartNodes(&opEngine, failure))
+
+	// the failures to start or poll the nodes are retried
+	opEngine.numSucceeded = 2
+	assert.True(t, canRetryStartNodes(&opEngine, failure))
+	opEngine.numSucceeded = 3
+	assert.True(t, canRetryStartNodes(&opEngine, failure))
+
+	// but not if the command is canceled
+	assert.False(t, canRetryStartNodes(&opEngine, &OpEngineCanceledError{Instruction: "HTTPSPollNodeStateOp",
+		InFlight: true, Err: context.Canceled}))
+	assert.False(t, canRetryStartNodes(nil, failure))
+}
+
+func TestGetDownHosts(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{State: util.NodeUpState}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{State: util.NodeDownState}
+	vdb.HostNodeMap["192.168.1.103"] = &VCoordinationNode{State: "RECOVERING"}
+
+	// the nodes that came up or are recovering are not started again
+	assert.Equal(t, []string{"192.168.1.102"},
+		getDownHosts(&vdb, []string{"192.168.1.101", "192.168.1.102", "192.168.1.103", "192.168.1.104"}))
+}
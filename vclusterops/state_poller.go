@@ -18,6 +18,8 @@ package vclusterops
 import (
 	"fmt"
 	"time"
+
+	"github.com/vertica/vcluster/vclusterops/metrics"
 )
 
 const (
@@ -30,6 +32,7 @@ const (
 )
 
 type statePoller interface {
+	getName() string
 	getPollingTimeout() int
 	shouldStopPolling() (bool, error)
 	runExecute(execContext *opEngineExecContext) error
@@ -54,6 +57,7 @@ func pollState(poller statePoller, execContext *opEngineExecContext) error {
 
 		if count > 0 {
 			time.Sleep(PollingInterval * time.Second)
+			metrics.OpRetries.Inc(poller.getName())
 		}
 
 		shouldStopPoll, err := poller.shouldStopPolling()
@@ -30,9 +30,27 @@ type VStopDatabaseOptions struct {
 	DrainSeconds *int   // time in seconds to wait for database users' disconnection
 	Sandbox      string // Stop db on given sandbox
 	MainCluster  bool   // Stop db on main cluster only
+	// stop the sandboxes with the main cluster. They are also stopped when
+	// neither Sandbox nor MainCluster is set, this makes it explicit.
+	IncludeSandboxes bool
+	// the seconds between the polls of the client sessions that are still
+	// connected while they drain, 0 disables the polls
+	SessionPollingSeconds int
+	// receives the number of client sessions still connected to each node at
+	// each poll while they drain, if it is set
+	ProgressReporter ProgressReporter
+	// whether the data in memory is moved out to disk before the database
+	// is stopped, so that the nodes have less to recover when it starts again
+	Moveout bool
 	/* part 3: hidden info */
 	CheckUserConn bool // whether check user connection
 	ForceKill     bool // whether force kill connections
+
+	// set once the database is stopped: the client sessions that were still
+	// connected when the drain ended, which the shutdown closed
+	ForceClosedSessions []SessionInfo
+	// set once the database is stopped: the sandboxes that were stopped
+	StoppedSandboxes []string
 }
 
 func VStopDatabaseOptionsFactory() VStopDatabaseOptions {
@@ -45,6 +63,7 @@ func VStopDatabaseOptionsFactory() VStopDatabaseOptions {
 
 func (options *VStopDatabaseOptions) setDefaultValues() {
 	options.DatabaseOptions.setDefaultValues()
+	options.SessionPollingSeconds = util.DefaultSessionPollingSeconds
 }
 
 func (options *VStopDatabaseOptions) validateRequiredOptions(log vlog.Printer) error {
@@ -60,6 +79,9 @@ func (options *VStopDatabaseOptions) validateEonOptions(log vlog.Printer) error
 	if options.Sandbox != "" && options.MainCluster {
 		return fmt.Errorf("Error: cannot use both --sandbox and --main-cluster-only options together ")
 	}
+	if options.IncludeSandboxes && (options.Sandbox != "" || options.MainCluster) {
+		return fmt.Errorf("cannot include the sandboxes when stopping a sandbox or the main cluster only")
+	}
 
 	// if db is enterprise db and we see --drain-seconds, we will ignore it
 	if !options.IsEon {
@@ -119,7 +141,9 @@ func (options *VStopDatabaseOptions) validateAnalyzeOptions(log vlog.Printer) er
 	return options.analyzeOptions()
 }
 
-func (vcc VClusterCommands) VStopDatabase(options *VStopDatabaseOptions) error {
+func (vcc VClusterCommands) VStopDatabase(options *VStopDatabaseOptions) (err error) {
+	defer vcc.startAudit("stop_db", options)(&err)
+
 	/*
 	 *   - Produce Instructions
 	 *   - Create a VClusterOpEngine
@@ -127,7 +151,7 @@ func (vcc VClusterCommands) VStopDatabase(options *VStopDatabaseOptions) error {
 	 */
 
 	// validate and analyze all options
-	err := options.validateAnalyzeOptions(vcc.Log)
+	err = options.validateAnalyzeOptions(vcc.Log)
 	if err != nil {
 		return err
 	}
@@ -155,11 +179,13 @@ func (vcc VClusterCommands) VStopDatabase(options *VStopDatabaseOptions) error {
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// Give the instructions to the VClusterOpEngine to run
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if runError != nil {
 		return fmt.Errorf("fail to stop database: %w", runError)
 	}
 
+	options.StoppedSandboxes = options.getStoppedSandboxes(clusterOpEngine.execContext.upHostsToSandboxes)
+
 	return nil
 }
 
@@ -169,8 +195,10 @@ func (vcc VClusterCommands) VStopDatabase(options *VStopDatabaseOptions) error {
 // The generated instructions will later perform the following operations necessary
 // for a successful stop_db:
 //   - Get up nodes through https call
+//   - Move out the data to disk, if it is asked
 //   - Sync catalog through the first up node
-//   - Stop db through the first up node
+//   - Stop db through the first up node, polling the sessions that are still
+//     connected while they drain
 //   - Check there is not any database running
 func (vcc *VClusterCommands) produceStopDBInstructions(options *VStopDatabaseOptions) ([]clusterOp, error) {
 	var instructions []clusterOp
@@ -192,6 +220,16 @@ func (vcc *VClusterCommands) produceStopDBInstructions(options *VStopDatabaseOpt
 	}
 	instructions = append(instructions, &httpsGetUpNodesOp)
 
+	// the moveout comes before the catalog sync, so that the synced catalog
+	// has the containers that it writes
+	if options.Moveout {
+		httpsMoveoutOp, e := makeHTTPSMoveoutOp(usePassword, options.UserName, options.Password)
+		if e != nil {
+			return instructions, e
+		}
+		instructions = append(instructions, &httpsMoveoutOp)
+	}
+
 	if options.IsEon {
 		httpsSyncCatalogOp, e := makeHTTPSSyncCatalogOpWithoutHosts(usePassword, options.UserName, options.Password, StopDBSyncCat)
 		if e != nil {
@@ -214,14 +252,32 @@ func (vcc *VClusterCommands) produceStopDBInstructions(options *VStopDatabaseOpt
 		return instructions, err
 	}
 
-	instructions = append(instructions,
-		&httpsStopDBOp,
-		&httpsCheckDBRunningOp,
-	)
+	// the sessions are reported while they drain
+	if options.DrainSeconds != nil && options.SessionPollingSeconds > 0 {
+		sessionDrainMonitorOp := makeSessionDrainMonitorOp(&httpsStopDBOp, usePassword, options.UserName,
+			options.Password, "" /*all subclusters*/, options.SessionPollingSeconds, &options.ForceClosedSessions)
+		sessionDrainMonitorOp.setProgressReporter(options.ProgressReporter, *options.DrainSeconds)
+		instructions = append(instructions, &sessionDrainMonitorOp)
+	} else {
+		instructions = append(instructions, &httpsStopDBOp)
+	}
+	instructions = append(instructions, &httpsCheckDBRunningOp)
 
 	return instructions, nil
 }
 
+// getStoppedSandboxes returns the sandboxes that the stop op stopped, among
+// the sandboxes of the up hosts
+func (options *VStopDatabaseOptions) getStoppedSandboxes(upHostsToSandboxes map[string]string) []string {
+	if options.Sandbox != "" {
+		return []string{options.Sandbox}
+	}
+	if options.MainCluster {
+		return []string{}
+	}
+	return getSortedSandboxes(getSandboxInitiators(upHostsToSandboxes))
+}
+
 // checkStopDBRequirements validates any stop_db requirements. It will
 // return an error if a requirement isn't met.
 func (options *VStopDatabaseOptions) checkStopDBRequirements(vdb *VCoordinationDatabase) error {
@@ -0,0 +1,91 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+	"golang.org/x/exp/maps"
+)
+
+func TestStopDBMoveout(t *testing.T) {
+	vcc := VClusterCommands{}
+	options := VStopDatabaseOptionsFactory()
+	options.DBName = "test_db"
+	options.Hosts = []string{"192.168.1.101", "192.168.1.102"}
+	options.IsEon = true
+
+	// no moveout by default
+	instructions, err := vcc.produceStopDBInstructions(&options)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, findInstruction[*httpsMoveoutOp](instructions))
+
+	// the data is moved out before the catalog is synced
+	options.Moveout = true
+	instructions, err = vcc.produceStopDBInstructions(&options)
+	assert.NoError(t, err)
+	moveoutIndex := findInstruction[*httpsMoveoutOp](instructions)
+	assert.NotEqual(t, -1, moveoutIndex)
+	assert.Less(t, moveoutIndex, findInstruction[*httpsSyncCatalogOp](instructions))
+
+	// the moveout is run from the first up host
+	execContext := makeOpEngineExecContext(vcc.Log)
+	execContext.upHosts = []string{"192.168.1.102", "192.168.1.101"}
+	moveoutOp := instructions[moveoutIndex].(*httpsMoveoutOp)
+	moveoutOp.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, moveoutOp.prepare(&execContext))
+	assert.Equal(t, []string{"192.168.1.102"}, moveoutOp.hosts)
+}
+
+func TestStopDBSandboxes(t *testing.T) {
+	execContext := makeOpEngineExecContext(vlog.Printer{})
+	execContext.upHostsToSandboxes = map[string]string{
+		"192.168.1.101": "", "192.168.1.102": "",
+		"192.168.1.103": "sb1", "192.168.1.104": "sb1",
+		"192.168.1.105": "sb2",
+	}
+	getStopHosts := func(sandbox string, mainCluster bool) ([]string, error) {
+		op, err := makeHTTPSStopDBOp(false, "", nil, nil, sandbox, mainCluster)
+		assert.NoError(t, err)
+		op.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+		err = op.prepare(&execContext)
+		return maps.Keys(op.clusterHTTPRequest.RequestCollection), err
+	}
+
+	// one host of each sandbox and one host of the main cluster
+	hosts, err := getStopHosts("", false)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"192.168.1.101", "192.168.1.103", "192.168.1.105"}, hosts)
+
+	hosts, err = getStopHosts("sb1", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.103"}, hosts)
+
+	hosts, err = getStopHosts("", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.101"}, hosts)
+
+	_, err = getStopHosts("sb3", false)
+	assert.ErrorContains(t, err, "sandbox sb3")
+
+	options := VStopDatabaseOptionsFactory()
+	options.IncludeSandboxes = true
+	assert.Equal(t, []string{"sb1", "sb2"}, options.getStoppedSandboxes(execContext.upHostsToSandboxes))
+	options.MainCluster = true
+	assert.ErrorContains(t, options.validateEonOptions(vlog.Printer{}), "cannot include the sandboxes")
+}
@@ -0,0 +1,161 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+type VStopNodeOptions struct {
+	/* part 1: basic db info */
+	DatabaseOptions
+
+	/* part 2: node info */
+	NodeName     string // name of the node to stop
+	DrainSeconds int    // time in seconds to wait for user sessions on the node to disconnect, its default value is 60
+}
+
+func VStopNodeOptionsFactory() VStopNodeOptions {
+	opt := VStopNodeOptions{}
+	// set default values to the params
+	opt.setDefaultValues()
+
+	return opt
+}
+
+func (options *VStopNodeOptions) setDefaultValues() {
+	options.DatabaseOptions.setDefaultValues()
+	options.DrainSeconds = util.DefaultDrainSeconds
+}
+
+func (options *VStopNodeOptions) validateRequiredOptions(log vlog.Printer) error {
+	err := options.validateBaseOptions(commandStopNode, log)
+	if err != nil {
+		return err
+	}
+
+	if options.NodeName == "" {
+		return fmt.Errorf("must specify the name of the node to stop")
+	}
+
+	return nil
+}
+
+func (options *VStopNodeOptions) validateParseOptions(log vlog.Printer) error {
+	return options.validateRequiredOptions(log)
+}
+
+// resolve hostnames to be IPs
+func (options *VStopNodeOptions) analyzeOptions() (err error) {
+	// resolve RawHosts to be IP addresses
+	if len(options.RawHosts) > 0 {
+		options.Hosts, err = util.ResolveRawHostsToAddresses(options.RawHosts, options.IPv6)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (options *VStopNodeOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateParseOptions(log); err != nil {
+		return err
+	}
+	return options.analyzeOptions()
+}
+
+// VStopNode gracefully stops one node of a running database. User sessions on the
+// node are given DrainSeconds to disconnect before the node shuts down. The node
+// is not stopped if the remaining primary nodes would lose quorum.
+func (vcc VClusterCommands) VStopNode(options *VStopNodeOptions) (err error) {
+	defer vcc.startAudit("stop_node", options)(&err)
+
+	/*
+	 *   - Validate Options
+	 *   - Get the database state from a running node
+	 *   - Produce Instructions
+	 *   - Create a VClusterOpEngine
+	 *   - Give the instructions to the VClusterOpEngine to run
+	 */
+
+	// validate and analyze all options
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	// retrieve the node states, including the nodes in sandboxes
+	vdb := makeVCoordinationDatabase()
+	err = vcc.getVDBFromRunningDBIncludeSandbox(&vdb, &options.DatabaseOptions, AnySandbox)
+	if err != nil {
+		return err
+	}
+
+	for _, vnode := range vdb.HostNodeMap {
+		if vnode.Name == options.NodeName && vnode.State == util.NodeDownState {
+			vcc.Log.PrintInfo("node %s is already down", options.NodeName)
+			return nil
+		}
+	}
+
+	instructions, err := vcc.produceStopNodeInstructions(options, &vdb)
+	if err != nil {
+		return fmt.Errorf("fail to production instructions: %w", err)
+	}
+
+	// Create a VClusterOpEngine, and add certs to the engine
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
+
+	// Give the instructions to the VClusterOpEngine to run
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	if runError != nil {
+		return fmt.Errorf("failed to stop node %s: %w", options.NodeName, runError)
+	}
+
+	return nil
+}
+
+// produceStopNodeInstructions will build a list of instructions to execute for
+// the stop node operation.
+//
+// The generated instructions will later perform the following operations necessary
+// for a successful stop_node:
+//   - Check that the remaining primary nodes keep quorum
+//   - Drain the sessions and stop the node through the HTTPS shutdown endpoint
+func (vcc *VClusterCommands) produceStopNodeInstructions(options *VStopNodeOptions,
+	vdb *VCoordinationDatabase) ([]clusterOp, error) {
+	var instructions []clusterOp
+
+	err := options.setUsePassword(vcc.Log)
+	if err != nil {
+		return instructions, err
+	}
+
+	httpsStopNodeOp, err := makeHTTPSStopSingleNodeOp(vdb, options.NodeName, options.usePassword,
+		options.UserName, options.Password, &options.DrainSeconds)
+	if err != nil {
+		return instructions, err
+	}
+
+	instructions = append(instructions, &httpsStopNodeOp)
+
+	return instructions, nil
+}
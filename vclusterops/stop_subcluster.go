@@ -30,6 +30,20 @@ type VStopSubclusterOptions struct {
 	DrainSeconds int    // time in seconds to wait for subcluster users' disconnection, its default value is 60
 	SCName       string // subcluster name
 	Force        bool   // force the subcluster to shutdown immediately even if users are connected
+	// redirect new connections away from the subcluster before it is stopped,
+	// to RedirectTargetSC if it is set, otherwise to any other subcluster
+	RedirectConnections bool
+	RedirectTargetSC    string
+	// the seconds between the polls of the client sessions that are still
+	// connected while they drain, 0 disables the polls
+	SessionPollingSeconds int
+	// receives the number of client sessions still connected to each node of
+	// the subcluster at each poll while they drain, if it is set
+	ProgressReporter ProgressReporter
+
+	// set once the subcluster is stopped: the client sessions that were still
+	// connected when the drain ended, which the shutdown closed
+	ForceClosedSessions []SessionInfo
 }
 
 func VStopSubclusterOptionsFactory() VStopSubclusterOptions {
@@ -43,6 +57,7 @@ func VStopSubclusterOptionsFactory() VStopSubclusterOptions {
 func (options *VStopSubclusterOptions) setDefaultValues() {
 	options.DatabaseOptions.setDefaultValues()
 	options.DrainSeconds = util.DefaultDrainSeconds
+	options.SessionPollingSeconds = util.DefaultSessionPollingSeconds
 }
 
 func (options *VStopSubclusterOptions) validateRequiredOptions(log vlog.Printer) error {
@@ -62,6 +77,9 @@ func (options *VStopSubclusterOptions) validateEonOptions(log vlog.Printer) erro
 		// this log is for vclusterops user since they probably set both DrainSeconds and Force
 		log.Info("The subcluster will be forcibly shutdown so provided drain seconds will be ignored")
 	}
+	if options.RedirectConnections && options.RedirectTargetSC == options.SCName {
+		return fmt.Errorf("cannot redirect connections of subcluster %s to itself", options.SCName)
+	}
 
 	return nil
 }
@@ -109,7 +127,9 @@ func (options *VStopSubclusterOptions) validateAnalyzeOptions(log vlog.Printer)
 	return options.analyzeOptions()
 }
 
-func (vcc VClusterCommands) VStopSubcluster(options *VStopSubclusterOptions) error {
+func (vcc VClusterCommands) VStopSubcluster(options *VStopSubclusterOptions) (err error) {
+	defer vcc.startAudit("stop_subcluster", options)(&err)
+
 	/*
 	 *   - Validate Options
 	 *   - Produce Instructions
@@ -118,7 +138,7 @@ func (vcc VClusterCommands) VStopSubcluster(options *VStopSubclusterOptions) err
 	 */
 
 	// validate and analyze all options
-	err := options.validateAnalyzeOptions(vcc.Log)
+	err = options.validateAnalyzeOptions(vcc.Log)
 	if err != nil {
 		return err
 	}
@@ -133,7 +153,7 @@ func (vcc VClusterCommands) VStopSubcluster(options *VStopSubclusterOptions) err
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// Give the instructions to the VClusterOpEngine to run
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if runError != nil {
 		return fmt.Errorf("failed to stop subcluster %s: %w", options.SCName, runError)
 	}
@@ -147,8 +167,10 @@ func (vcc VClusterCommands) VStopSubcluster(options *VStopSubclusterOptions) err
 // The generated instructions will later perform the following operations necessary
 // for a successful stop_subcluster:
 //   - Get up nodes in the target subcluster through https call
+//   - Optionally, redirect new connections away from the target subcluster
 //   - Sync catalog through the first up node in the target subcluster
-//   - Stop subcluster through the first up node in the target subcluster
+//   - Stop subcluster through the first up node in the target subcluster, polling
+//     the sessions that are still connected while they drain
 //   - Check if there are any running nodes in the target subcluster
 func (vcc *VClusterCommands) produceStopSCInstructions(options *VStopSubclusterOptions) ([]clusterOp, error) {
 	var instructions []clusterOp
@@ -185,12 +207,29 @@ func (vcc *VClusterCommands) produceStopSCInstructions(options *VStopSubclusterO
 		return instructions, err
 	}
 
-	instructions = append(instructions,
-		&httpsGetUpNodesOp,
-		&httpsSyncCatalogOp,
-		&httpsStopSCOp,
-		&httpsCheckDBRunningOp,
-	)
+	instructions = append(instructions, &httpsGetUpNodesOp)
+	if options.RedirectConnections {
+		httpsRedirectConnectionsOp, e := makeHTTPSRedirectConnectionsOp(usePassword, options.UserName, options.Password,
+			options.SCName, true /*enable*/, options.RedirectTargetSC)
+		if e != nil {
+			return instructions, e
+		}
+		instructions = append(instructions, &httpsRedirectConnectionsOp)
+	}
+
+	instructions = append(instructions, &httpsSyncCatalogOp)
+	// the sessions are reported while they drain
+	if options.SessionPollingSeconds > 0 {
+		sessionDrainMonitorOp := makeSessionDrainMonitorOp(&httpsStopSCOp, usePassword, options.UserName,
+			options.Password, options.SCName, options.SessionPollingSeconds, &options.ForceClosedSessions)
+		if !options.Force {
+			sessionDrainMonitorOp.setProgressReporter(options.ProgressReporter, options.DrainSeconds)
+		}
+		instructions = append(instructions, &sessionDrainMonitorOp)
+	} else {
+		instructions = append(instructions, &httpsStopSCOp)
+	}
+	instructions = append(instructions, &httpsCheckDBRunningOp)
 
 	return instructions, nil
 }
@@ -0,0 +1,115 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// the log files in the catalog directory of a node that can be tailed
+const (
+	LogFileVertica = "vertica.log"
+	LogFileStartup = "startup.log"
+)
+
+// VTailLogOptions represents the available options for VTailLog
+type VTailLogOptions struct {
+	DatabaseOptions
+
+	// the nodes whose log is tailed, all the nodes on the hosts if it is empty
+	NodeNames []string
+	// LogFileVertica or LogFileStartup, defaults to LogFileVertica
+	LogFile string
+	// the number of lines to get from the end of the log
+	Lines int
+	// whether to keep streaming the lines written to the log until the
+	// command is canceled
+	Follow bool
+	// called with each line as it is received. The calls are not concurrent.
+	OnLine func(line LogLine)
+}
+
+// LogLine is a line of the log of a node
+type LogLine struct {
+	Host     string `json:"host"`
+	NodeName string `json:"node_name"`
+	Line     string `json:"line"`
+}
+
+func VTailLogOptionsFactory() VTailLogOptions {
+	opt := VTailLogOptions{}
+	opt.setDefaultValues()
+	opt.LogFile = LogFileVertica
+	opt.Lines = util.DefaultTailLogLines
+	return opt
+}
+
+func (options *VTailLogOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateBaseOptions(commandTailLog, log); err != nil {
+		return err
+	}
+	if err := util.ValidateRequiredAbsPath(options.CatalogPrefix, "catalog path"); err != nil {
+		return err
+	}
+	if options.LogFile != LogFileVertica && options.LogFile != LogFileStartup {
+		return fmt.Errorf("invalid log file %q, must be %s or %s", options.LogFile, LogFileVertica, LogFileStartup)
+	}
+	if options.Lines < 0 {
+		return fmt.Errorf("the number of lines cannot be negative")
+	}
+	if options.OnLine == nil {
+		return fmt.Errorf("must specify a function to receive the lines of the log")
+	}
+	return options.resolveHosts()
+}
+
+// VTailLog streams the last lines of vertica.log or startup.log from the nodes
+// through the NMA, and keeps streaming the lines written to it if Follow is set,
+// such as to watch why nodes fail to start. The database does not need to be up.
+// With Follow, VTailLog returns once the context of the op engine is canceled.
+func (vcc VClusterCommands) VTailLog(options *VTailLogOptions) error {
+	err := options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return err
+	}
+
+	vdb := makeVCoordinationDatabase()
+	nmaGetNodesInfoOp := makeNMAGetNodesInfoOp(options.Hosts, options.DBName, options.CatalogPrefix,
+		true /* ignore internal errors */, &vdb)
+	// the hosts stream their lines concurrently
+	var mu sync.Mutex
+	onLine := func(host, line string) {
+		mu.Lock()
+		defer mu.Unlock()
+		logLine := LogLine{Host: host, Line: line}
+		if vnode, ok := vdb.HostNodeMap[host]; ok {
+			logLine.NodeName = vnode.Name
+		}
+		options.OnLine(logLine)
+	}
+	nmaTailLogOp := makeNMATailLogOp(&vdb, options.NodeNames, options.LogFile, options.Lines,
+		options.Follow, onLine)
+
+	err = options.runClusterOpEngine(vcc.getContext(), vcc.Log, []clusterOp{&nmaGetNodesInfoOp, &nmaTailLogOp})
+	if err != nil {
+		return fmt.Errorf("fail to tail %s: %w", options.LogFile, err)
+	}
+	return nil
+}
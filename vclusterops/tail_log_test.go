@@ -0,0 +1,69 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestValidateTailLogOptions(t *testing.T) {
+	opt := VTailLogOptionsFactory()
+	opt.DBName = dbName
+	opt.RawHosts = []string{"192.168.1.101"}
+	opt.CatalogPrefix = "/data"
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(vlog.Printer{}), "function to receive the lines")
+
+	opt.OnLine = func(LogLine) {}
+	assert.NoError(t, opt.validateAnalyzeOptions(vlog.Printer{}))
+	opt.LogFile = "dbLog"
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(vlog.Printer{}), "invalid log file")
+	opt.LogFile = LogFileStartup
+	opt.Lines = -1
+	assert.ErrorContains(t, opt.validateAnalyzeOptions(vlog.Printer{}), "cannot be negative")
+}
+
+func TestTailLogOp(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{Name: "v_test_db_node0001",
+		CatalogPath: "/data/test_db/v_test_db_node0001_catalog"}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{Name: "v_test_db_node0002",
+		CatalogPath: "/data/test_db/v_test_db_node0002_catalog"}
+	execContext := makeOpEngineExecContext(vlog.Printer{})
+
+	op := makeNMATailLogOp(&vdb, []string{"v_test_db_node0009"}, LogFileVertica, 100, false, nil)
+	assert.ErrorContains(t, op.prepare(&execContext), "cannot find node v_test_db_node0009")
+
+	op = makeNMATailLogOp(&vdb, []string{"v_test_db_node0002"}, LogFileStartup, 20, true, nil)
+	op.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, op.prepare(&execContext))
+	assert.Equal(t, []string{"192.168.1.102"}, op.hosts)
+	request := op.clusterHTTPRequest.RequestCollection["192.168.1.102"]
+	assert.Equal(t, map[string]string{"path": "/data/test_db/v_test_db_node0002_catalog/startup.log",
+		"lines": "20", "follow": "true"}, request.QueryParams)
+	// a followed log has no timeout
+	assert.Equal(t, -1, request.Timeout)
+
+	// without node names, the log of every node is tailed
+	op = makeNMATailLogOp(&vdb, nil, LogFileVertica, 100, false, nil)
+	op.clusterHTTPRequest.RequestCollection = make(map[string]hostHTTPRequest)
+	assert.NoError(t, op.prepare(&execContext))
+	assert.Equal(t, []string{"192.168.1.101", "192.168.1.102"}, op.hosts)
+	assert.Zero(t, op.clusterHTTPRequest.RequestCollection["192.168.1.101"].Timeout)
+}
@@ -0,0 +1,170 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// TimingSummary accumulates the wall time of the instructions of a command,
+// and the latency of the hosts that they send requests to. A command can run
+// several op engines, so the summary is given to the engines in the context of
+// the commands:
+//
+//	summary := NewTimingSummary()
+//	vcc.Ctx = ContextWithTimingSummary(ctx, summary)
+type TimingSummary struct {
+	mu           sync.Mutex
+	instructions map[string]*InstructionTiming
+}
+
+// InstructionTiming is the time spent in an instruction, over all of its runs
+type InstructionTiming struct {
+	Name     string
+	Runs     int
+	Failures int
+	// the total wall time of the runs
+	Duration time.Duration
+	// the latency of the slowest response of each host
+	HostLatencies map[string]time.Duration
+}
+
+// SlowestHost returns the host with the highest latency, and its latency
+func (timing *InstructionTiming) SlowestHost() (host string, latency time.Duration) {
+	for h, l := range timing.HostLatencies {
+		if l > latency || (l == latency && h < host) {
+			host, latency = h, l
+		}
+	}
+	return host, latency
+}
+
+func NewTimingSummary() *TimingSummary {
+	return &TimingSummary{instructions: make(map[string]*InstructionTiming)}
+}
+
+type timingSummaryContextKey struct{}
+
+// ContextWithTimingSummary returns a context whose op engines record their
+// timing in the summary
+func ContextWithTimingSummary(ctx context.Context, summary *TimingSummary) context.Context {
+	return context.WithValue(ctx, timingSummaryContextKey{}, summary)
+}
+
+// getTimingSummary returns the summary of a context, or nil. The methods of
+// a nil summary do nothing.
+func getTimingSummary(ctx context.Context) *TimingSummary {
+	summary, _ := ctx.Value(timingSummaryContextKey{}).(*TimingSummary)
+	return summary
+}
+
+func (summary *TimingSummary) getInstruction(name string) *InstructionTiming {
+	timing, found := summary.instructions[name]
+	if !found {
+		timing = &InstructionTiming{Name: name, HostLatencies: make(map[string]time.Duration)}
+		summary.instructions[name] = timing
+	}
+	return timing
+}
+
+func (summary *TimingSummary) recordInstruction(name string, duration time.Duration, err error) {
+	if summary == nil {
+		return
+	}
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+	timing := summary.getInstruction(name)
+	timing.Runs++
+	timing.Duration += duration
+	if err != nil {
+		timing.Failures++
+	}
+}
+
+func (summary *TimingSummary) recordRequest(name, host string, latency time.Duration) {
+	if summary == nil {
+		return
+	}
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+	timing := summary.getInstruction(name)
+	if latency > timing.HostLatencies[host] {
+		timing.HostLatencies[host] = latency
+	}
+}
+
+// Instructions returns the timing of the instructions that have run, from
+// the one that took the most time to the one that took the least
+func (summary *TimingSummary) Instructions() []InstructionTiming {
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+	var instructions []InstructionTiming
+	for _, timing := range summary.instructions {
+		if timing.Runs == 0 {
+			continue
+		}
+		instruction := *timing
+		instruction.HostLatencies = make(map[string]time.Duration, len(timing.HostLatencies))
+		for host, latency := range timing.HostLatencies {
+			instruction.HostLatencies[host] = latency
+		}
+		instructions = append(instructions, instruction)
+	}
+	sort.Slice(instructions, func(i, j int) bool {
+		if instructions[i].Duration != instructions[j].Duration {
+			return instructions[i].Duration > instructions[j].Duration
+		}
+		return instructions[i].Name < instructions[j].Name
+	})
+	return instructions
+}
+
+// WriteTable writes the summary as a table, with the share of the total time
+// of each instruction and its slowest host
+func (summary *TimingSummary) WriteTable(out io.Writer) error {
+	instructions := summary.Instructions()
+	var total time.Duration
+	for i := range instructions {
+		total += instructions[i].Duration
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "INSTRUCTION\tRUNS\tFAILURES\tTIME\tSHARE\tSLOWEST HOST\tLATENCY")
+	for i := range instructions {
+		timing := &instructions[i]
+		share := 0.0
+		if total > 0 {
+			share = 100 * float64(timing.Duration) / float64(total)
+		}
+		host, latency := timing.SlowestHost()
+		hostLatency := "-"
+		if host == "" {
+			host = "-"
+		} else {
+			hostLatency = latency.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%.1f%%\t%s\t%s\n", timing.Name, timing.Runs, timing.Failures,
+			timing.Duration.Round(time.Millisecond), share, host, hostLatency)
+	}
+	fmt.Fprintf(w, "TOTAL\t\t\t%s\t\t\t\n", total.Round(time.Millisecond))
+	return w.Flush()
+}
@@ -0,0 +1,218 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceparentHeader is the W3C trace-context header that propagates a span to the NMA
+const TraceparentHeader = "traceparent"
+
+// the version and the sampled flag of the traceparent header
+const (
+	traceparentVersion = "00"
+	sampledFlag        = "01"
+)
+
+// Attributes of the spans
+const (
+	AttrHost           = "net.peer.name"
+	AttrHTTPMethod     = "http.method"
+	AttrHTTPStatusCode = "http.status_code"
+	AttrHTTPEndpoint   = "http.target"
+)
+
+// Span is a timed operation of a trace, with the fields of the OpenTelemetry
+// data model. The spans of vcluster are an instruction of the op engine, or a
+// request to a host as a child span of its instruction.
+type Span struct {
+	TraceID      string            `json:"traceId"`
+	SpanID       string            `json:"spanId"`
+	ParentSpanID string            `json:"parentSpanId,omitempty"`
+	Name         string            `json:"name"`
+	StartTime    time.Time         `json:"startTime"`
+	EndTime      time.Time         `json:"endTime"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	// the error that fails the operation of the span, empty if it succeeds
+	Error string `json:"error,omitempty"`
+
+	mu    sync.Mutex
+	ended bool
+}
+
+// Exporter receives the spans once they end. An exporter can send them to an
+// OpenTelemetry collector, for example with the OpenTelemetry SDK.
+type Exporter interface {
+	ExportSpan(span *Span)
+}
+
+var (
+	exporter      Exporter
+	exporterMutex sync.Mutex
+)
+
+// SetExporter sets the exporter of the spans. Tracing is disabled while the
+// exporter is nil, which is the default.
+func SetExporter(e Exporter) {
+	exporterMutex.Lock()
+	defer exporterMutex.Unlock()
+	exporter = e
+}
+
+func getExporter() Exporter {
+	exporterMutex.Lock()
+	defer exporterMutex.Unlock()
+	return exporter
+}
+
+type spanContextKey struct{}
+
+// spanContext identifies the parent of a new span, which can be a span of
+// another process that is given by a traceparent header
+type spanContext struct {
+	traceID string
+	spanID  string
+}
+
+// StartSpan starts a span as a child of the span in the context, or as the
+// root of a new trace. It returns a nil span if tracing is disabled, and the
+// methods of a nil span do nothing.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	if getExporter() == nil {
+		return ctx, nil
+	}
+	span := &Span{
+		SpanID:     newID(8),
+		Name:       name,
+		StartTime:  time.Now(),
+		Attributes: make(map[string]string),
+	}
+	if parent, ok := ctx.Value(spanContextKey{}).(spanContext); ok {
+		span.TraceID = parent.traceID
+		span.ParentSpanID = parent.spanID
+	} else {
+		span.TraceID = newID(16)
+	}
+	return context.WithValue(ctx, spanContextKey{}, spanContext{traceID: span.TraceID, spanID: span.SpanID}), span
+}
+
+// ContextWithTraceparent returns a context whose spans are children of the
+// span in a traceparent header, so that the traces of a caller continue in vcluster.
+// The context is returned as is if the header is not valid.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || parts[0] != traceparentVersion || !isID(parts[1], 16) || !isID(parts[2], 8) {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, spanContext{traceID: parts[1], spanID: parts[2]})
+}
+
+// SetAttribute sets an attribute of the span
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attributes[key] = fmt.Sprint(value)
+}
+
+// Traceparent returns the traceparent header that makes the span the parent
+// of the spans of the receiver of a request
+func (s *Span) Traceparent() string {
+	if s == nil {
+		return ""
+	}
+	return strings.Join([]string{traceparentVersion, s.TraceID, s.SpanID, sampledFlag}, "-")
+}
+
+// End ends the span with the error of its operation, and exports it. Only the
+// first call ends the span.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	if err != nil {
+		s.Error = err.Error()
+	}
+	s.mu.Unlock()
+
+	if e := getExporter(); e != nil {
+		e.ExportSpan(s)
+	}
+}
+
+func newID(size int) string {
+	id := make([]byte, size)
+	// an ID of all zeros is not valid
+	for isZero(id) {
+		_, _ = rand.Read(id)
+	}
+	return hex.EncodeToString(id)
+}
+
+func isZero(id []byte) bool {
+	for _, b := range id {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func isID(s string, size int) bool {
+	id, err := hex.DecodeString(s)
+	return err == nil && len(id) == size && !isZero(id) && s == strings.ToLower(s)
+}
+
+// JSONExporter writes each span as a line of JSON, which can be loaded by a
+// tool or sent to a collector later
+type JSONExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewJSONExporter(w io.Writer) *JSONExporter {
+	return &JSONExporter{w: w}
+}
+
+func (e *JSONExporter) ExportSpan(span *Span) {
+	span.mu.Lock()
+	data, err := json.Marshal(span)
+	span.mu.Unlock()
+	if err != nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, _ = e.w.Write(append(data, '\n'))
+}
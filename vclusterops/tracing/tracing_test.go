@@ -0,0 +1,80 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartSpan(t *testing.T) {
+	// tracing is disabled without an exporter
+	ctx, span := StartSpan(context.Background(), "disabled")
+	assert.Nil(t, span)
+	assert.Equal(t, context.Background(), ctx)
+	span.SetAttribute(AttrHost, "192.168.1.101")
+	span.End(nil)
+	assert.Empty(t, span.Traceparent())
+
+	var buf bytes.Buffer
+	SetExporter(NewJSONExporter(&buf))
+	defer SetExporter(nil)
+
+	ctx, parent := StartSpan(context.Background(), "NMAHealthOp")
+	_, child := StartSpan(ctx, "NMAHealthOp 192.168.1.101")
+	assert.Equal(t, parent.TraceID, child.TraceID)
+	assert.Equal(t, parent.SpanID, child.ParentSpanID)
+	assert.Empty(t, parent.ParentSpanID)
+
+	child.SetAttribute(AttrHTTPStatusCode, 500)
+	child.End(errors.New("internal error"))
+	child.End(nil)
+	parent.End(nil)
+
+	// the spans are exported once, in the order they end
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	var exported Span
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &exported))
+	assert.Equal(t, child.SpanID, exported.SpanID)
+	assert.Equal(t, "500", exported.Attributes[AttrHTTPStatusCode])
+	assert.Equal(t, "internal error", exported.Error)
+}
+
+func TestTraceparent(t *testing.T) {
+	SetExporter(NewJSONExporter(&bytes.Buffer{}))
+	defer SetExporter(nil)
+
+	// a span continues the trace of a traceparent header
+	const header = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := ContextWithTraceparent(context.Background(), header)
+	_, span := StartSpan(ctx, "HTTPSCheckRunningDBOp")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", span.ParentSpanID)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+span.SpanID+"-01", span.Traceparent())
+
+	// the headers that are not valid are ignored
+	for _, invalid := range []string{"", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01", "00-4bf92f3577b34da6a3ce929d0e0e4736-00F067AA0BA902B7-01"} {
+		assert.Equal(t, context.Background(), ContextWithTraceparent(context.Background(), invalid))
+	}
+}
@@ -258,7 +258,9 @@ func (vcc *VClusterCommands) produceUnsandboxSCInstructions(options *VUnsandboxO
 	return instructions, nil
 }
 
-func (vcc VClusterCommands) VUnsandbox(options *VUnsandboxOptions) error {
+func (vcc VClusterCommands) VUnsandbox(options *VUnsandboxOptions) (err error) {
+	defer vcc.startAudit("unsandbox_subcluster", options)(&err)
+
 	vcc.Log.V(0).Info("VUnsandbox method called", "options", options)
 	return runSandboxCmd(vcc, options)
 }
@@ -281,7 +283,7 @@ func (options *VUnsandboxOptions) runCommand(vcc VClusterCommands) error {
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// run the engine
-	runError := clusterOpEngine.run(vcc.Log)
+	runError := clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if runError != nil {
 		return fmt.Errorf("fail to unsandbox subcluster %s, %w", options.SCName, runError)
 	}
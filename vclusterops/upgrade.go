@@ -0,0 +1,288 @@
+/*
+ (c) Copyright [2023-2024] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+type VUpgradeVerticaOptions struct {
+	/* part 1: basic db info */
+	DatabaseOptions
+
+	/* part 2: upgrade info */
+	// the version that the new Vertica binaries must report, e.g., v24.3.0. The
+	// components that are not given match any value, so 24.3 matches v24.3.0-2.
+	NewVersion string
+	// the subclusters to upgrade in order. If it is empty, all subclusters
+	// are upgraded, secondary subclusters first
+	Subclusters []string
+	// resume a paused upgrade with the subclusters after this one
+	ResumeAfter string
+	// stop the upgrade after each subcluster so that it can be checked
+	// before the upgrade is resumed with ResumeAfter
+	PauseAfterEachSubcluster bool
+	// time in seconds to wait for user sessions to disconnect before stopping a subcluster
+	DrainSeconds int
+	// timeout in seconds for polling the restarted nodes to be up
+	StatePollingTimeout int
+}
+
+// VUpgradeVerticaStatus records the progress of an upgrade. It can be used
+// to resume a paused upgrade.
+type VUpgradeVerticaStatus struct {
+	UpgradedSubclusters  []string
+	RemainingSubclusters []string
+}
+
+func VUpgradeVerticaOptionsFactory() VUpgradeVerticaOptions {
+	opt := VUpgradeVerticaOptions{}
+	// set default values to the params
+	opt.setDefaultValues()
+
+	return opt
+}
+
+func (options *VUpgradeVerticaOptions) setDefaultValues() {
+	options.DatabaseOptions.setDefaultValues()
+	options.DrainSeconds = util.DefaultDrainSeconds
+	options.StatePollingTimeout = util.DefaultStatePollingTimeout
+}
+
+func (options *VUpgradeVerticaOptions) validateParseOptions(log vlog.Printer) error {
+	err := options.validateBaseOptions(commandUpgrade, log)
+	if err != nil {
+		return err
+	}
+
+	if !options.IsEon {
+		return fmt.Errorf("online upgrade is only supported in Eon mode")
+	}
+	if options.NewVersion == "" {
+		return fmt.Errorf("must specify the new Vertica version")
+	}
+	if verticaVersionRegexp.FindString(options.NewVersion) != options.NewVersion {
+		return fmt.Errorf("invalid Vertica version %s, the version must be like v24.3.0-1, v24.3.0 or 24.3",
+			options.NewVersion)
+	}
+
+	return nil
+}
+
+// resolve hostnames to be IPs
+func (options *VUpgradeVerticaOptions) analyzeOptions() (err error) {
+	if len(options.RawHosts) > 0 {
+		options.Hosts, err = util.ResolveRawHostsToAddresses(options.RawHosts, options.IPv6)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (options *VUpgradeVerticaOptions) validateAnalyzeOptions(log vlog.Printer) error {
+	if err := options.validateParseOptions(log); err != nil {
+		return err
+	}
+	return options.analyzeOptions()
+}
+
+// VUpgradeVertica performs a rolling upgrade of an Eon database, one subcluster at a time.
+// The new Vertica binaries must already be installed on all hosts. The hosts of each
+// subcluster are checked to have the new version, and the subcluster is stopped, unless
+// it is already stopped by an upgrade that failed, and it is restarted and polled until
+// it is up before the next subcluster is upgraded. A primary subcluster
+// can only be upgraded if the primary nodes of the other subclusters keep quorum
+// while it is stopped; otherwise the database must be upgraded with stop_db and start_db.
+// It returns the subclusters that are upgraded and the ones that remain, and any error encountered.
+func (vcc VClusterCommands) VUpgradeVertica(options *VUpgradeVerticaOptions) (_ VUpgradeVerticaStatus, err error) {
+	defer vcc.startAudit("upgrade_vertica", options)(&err)
+
+	status := VUpgradeVerticaStatus{}
+
+	// validate and analyze all options
+	err = options.validateAnalyzeOptions(vcc.Log)
+	if err != nil {
+		return status, err
+	}
+
+	vdb := makeVCoordinationDatabase()
+	err = vcc.getVDBFromRunningDB(&vdb, &options.DatabaseOptions)
+	if err != nil {
+		return status, err
+	}
+
+	status.RemainingSubclusters, err = options.getSubclustersToUpgrade(&vdb)
+	if err != nil {
+		return status, err
+	}
+	// refuse the upgrade before any subcluster is stopped
+	err = checkUpgradeQuorum(&vdb, status.RemainingSubclusters)
+	if err != nil {
+		return status, err
+	}
+
+	for len(status.RemainingSubclusters) > 0 {
+		scName := status.RemainingSubclusters[0]
+		err = vcc.upgradeSubcluster(options, &vdb, scName)
+		if err != nil {
+			return status, fmt.Errorf("fail to upgrade subcluster %s: %w", scName, err)
+		}
+		status.UpgradedSubclusters = append(status.UpgradedSubclusters, scName)
+		status.RemainingSubclusters = status.RemainingSubclusters[1:]
+
+		if options.PauseAfterEachSubcluster && len(status.RemainingSubclusters) > 0 {
+			vcc.Log.PrintInfo("Upgrade paused after subcluster %s, resume it after this subcluster to upgrade %v",
+				scName, status.RemainingSubclusters)
+			break
+		}
+	}
+
+	return status, nil
+}
+
+// getSubclustersToUpgrade returns the ordered subclusters to upgrade, starting
+// after ResumeAfter if it is set
+func (options *VUpgradeVerticaOptions) getSubclustersToUpgrade(vdb *VCoordinationDatabase) ([]string, error) {
+	scIsPrimary := make(map[string]bool)
+	for _, vnode := range vdb.HostNodeMap {
+		scIsPrimary[vnode.Subcluster] = vnode.IsPrimary
+	}
+
+	subclusters := util.CopySlice(options.Subclusters)
+	if len(subclusters) == 0 {
+		for sc := range scIsPrimary {
+			subclusters = append(subclusters, sc)
+		}
+		// upgrade secondary subclusters first so the primary subclusters keep the database up longer
+		sort.Slice(subclusters, func(i, j int) bool {
+			if scIsPrimary[subclusters[i]] != scIsPrimary[subclusters[j]] {
+				return !scIsPrimary[subclusters[i]]
+			}
+			return subclusters[i] < subclusters[j]
+		})
+	}
+
+	for _, sc := range subclusters {
+		if _, ok := scIsPrimary[sc]; !ok {
+			return nil, fmt.Errorf("subcluster %s is not found in database %s", sc, vdb.Name)
+		}
+	}
+
+	if options.ResumeAfter == "" {
+		return subclusters, nil
+	}
+	for i, sc := range subclusters {
+		if sc == options.ResumeAfter {
+			return subclusters[i+1:], nil
+		}
+	}
+	return nil, fmt.Errorf("subcluster %s to resume after is not in the subclusters to upgrade %v",
+		options.ResumeAfter, subclusters)
+}
+
+// checkUpgradeQuorum checks that each primary subcluster to upgrade can be
+// stopped while the database is up. Starting the nodes of a stopped subcluster
+// requires a running database, so the up primary nodes of the other subclusters
+// must keep quorum.
+func checkUpgradeQuorum(vdb *VCoordinationDatabase, subclusters []string) error {
+	for _, scName := range subclusters {
+		var isPrimary bool
+		var primaryNodeCount, upPrimaryNodeCount uint
+		for _, vnode := range vdb.HostNodeMap {
+			if !vnode.IsPrimary || vnode.Sandbox != util.MainClusterSandbox {
+				continue
+			}
+			primaryNodeCount++
+			if vnode.Subcluster == scName {
+				isPrimary = true
+			} else if vnode.State == util.NodeUpState {
+				upPrimaryNodeCount++
+			}
+		}
+		if isPrimary && !hasNodeUpQuorum(upPrimaryNodeCount, primaryNodeCount) {
+			return &QuorumError{Detail: fmt.Sprintf("cannot upgrade primary subcluster %s online, only %d of %d primary nodes "+
+				"would be up while it is stopped. Upgrade the secondary subclusters only, then upgrade the database "+
+				"with stop_db and start_db", scName, upPrimaryNodeCount, primaryNodeCount)}
+		}
+	}
+	return nil
+}
+
+// upgradeSubcluster checks the version of the new binaries on the hosts of a
+// subcluster, stops the subcluster if it is up, and restarts it
+func (vcc VClusterCommands) upgradeSubcluster(options *VUpgradeVerticaOptions,
+	vdb *VCoordinationDatabase, scName string) error {
+	scHosts, nodes, isStopped := getUpgradeSubclusterNodes(vdb, scName)
+
+	// the NMA reports the version of the installed binaries, so the subcluster
+	// is not stopped if they are not the new ones
+	vcc.Log.PrintInfo("Checking Vertica version on hosts %v", scHosts)
+	nmaHealthOp := makeNMAHealthOp(scHosts)
+	nmaVerticaVersionOp := makeNMAVerticaVersionOpWithExpectedVersion(scHosts, options.NewVersion)
+	instructions := []clusterOp{&nmaHealthOp, &nmaVerticaVersionOp}
+	certs := httpsCerts{key: options.Key, cert: options.Cert, caCert: options.CaCert}
+	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
+	err := clusterOpEngine.run(vcc.getContext(), vcc.Log)
+	if err != nil {
+		return fmt.Errorf("the new Vertica binaries are not installed on subcluster %s, "+
+			"install them and resume the upgrade: %w", scName, err)
+	}
+
+	// a subcluster whose upgrade failed once it was stopped is already stopped
+	// when the upgrade is resumed
+	if isStopped {
+		vcc.Log.PrintInfo("Subcluster %s is already stopped, skipping the stop", scName)
+	} else {
+		vcc.Log.PrintInfo("Stopping subcluster %s for upgrade", scName)
+		stopSCOptions := VStopSubclusterOptionsFactory()
+		stopSCOptions.DatabaseOptions = options.DatabaseOptions
+		stopSCOptions.SCName = scName
+		stopSCOptions.DrainSeconds = options.DrainSeconds
+		err = vcc.VStopSubcluster(&stopSCOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	vcc.Log.PrintInfo("Restarting subcluster %s", scName)
+	startNodesOptions := VStartNodesOptionsFactory()
+	startNodesOptions.DatabaseOptions = options.DatabaseOptions
+	startNodesOptions.Nodes = nodes
+	startNodesOptions.StatePollingTimeout = options.StatePollingTimeout
+	return vcc.VStartNodes(&startNodesOptions)
+}
+
+// getUpgradeSubclusterNodes returns the hosts and the nodes of a subcluster, and
+// whether all its nodes are down
+func getUpgradeSubclusterNodes(vdb *VCoordinationDatabase, scName string) (scHosts []string,
+	nodes map[string]string, isStopped bool) {
+	nodes = make(map[string]string)
+	isStopped = true
+	for host, vnode := range vdb.HostNodeMap {
+		if vnode.Subcluster == scName {
+			scHosts = append(scHosts, host)
+			nodes[vnode.Name] = ""
+			isStopped = isStopped && vnode.State == util.NodeDownState
+		}
+	}
+	return scHosts, nodes, isStopped
+}
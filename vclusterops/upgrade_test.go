@@ -0,0 +1,117 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vertica/vcluster/vclusterops/util"
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+func TestGetSubclustersToUpgrade(t *testing.T) {
+	options := VUpgradeVerticaOptionsFactory()
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{IsPrimary: true, Subcluster: "default_subcluster"}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{IsPrimary: false, Subcluster: "sc2"}
+	vdb.HostNodeMap["192.168.1.103"] = &VCoordinationNode{IsPrimary: false, Subcluster: "sc1"}
+
+	// secondary subclusters are upgraded first
+	subclusters, err := options.getSubclustersToUpgrade(&vdb)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sc1", "sc2", "default_subcluster"}, subclusters)
+
+	// resume after a subcluster
+	options.ResumeAfter = "sc2"
+	subclusters, err = options.getSubclustersToUpgrade(&vdb)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default_subcluster"}, subclusters)
+
+	// unknown subclusters are rejected
+	options.Subclusters = []string{"sc3"}
+	_, err = options.getSubclustersToUpgrade(&vdb)
+	assert.ErrorContains(t, err, "subcluster sc3 is not found")
+}
+
+func TestCheckUpgradeQuorum(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	vdb.HostNodeMap["192.168.1.101"] = &VCoordinationNode{IsPrimary: true, Subcluster: "sc_primary1", State: util.NodeUpState}
+	vdb.HostNodeMap["192.168.1.102"] = &VCoordinationNode{IsPrimary: true, Subcluster: "sc_primary1", State: util.NodeUpState}
+	vdb.HostNodeMap["192.168.1.103"] = &VCoordinationNode{IsPrimary: true, Subcluster: "sc_primary2", State: util.NodeUpState}
+	vdb.HostNodeMap["192.168.1.104"] = &VCoordinationNode{IsPrimary: false, Subcluster: "sc_secondary", State: util.NodeUpState}
+
+	// secondary subclusters can always be stopped
+	assert.NoError(t, checkUpgradeQuorum(&vdb, []string{"sc_secondary"}))
+
+	// one of three primary nodes is not a quorum
+	err := checkUpgradeQuorum(&vdb, []string{"sc_secondary", "sc_primary1"})
+	var quorumErr *QuorumError
+	assert.True(t, errors.As(err, &quorumErr))
+	assert.ErrorContains(t, err, "cannot upgrade primary subcluster sc_primary1 online, only 1 of 3 primary nodes")
+
+	// two of three primary nodes keep quorum
+	assert.NoError(t, checkUpgradeQuorum(&vdb, []string{"sc_primary2"}))
+
+	// unless one of them is down
+	vdb.HostNodeMap["192.168.1.102"].State = util.NodeDownState
+	assert.ErrorContains(t, checkUpgradeQuorum(&vdb, []string{"sc_primary2"}), "only 1 of 3 primary nodes")
+
+	// a single primary subcluster cannot be upgraded online
+	delete(vdb.HostNodeMap, "192.168.1.103")
+	assert.ErrorContains(t, checkUpgradeQuorum(&vdb, []string{"sc_primary1"}), "only 0 of 2 primary nodes")
+}
+
+func TestValidateUpgradeVersion(t *testing.T) {
+	options := VUpgradeVerticaOptionsFactory()
+	options.DBName = "test_db"
+	options.RawHosts = []string{"192.168.1.101"}
+	options.IsEon = true
+
+	options.NewVersion = "v24.3.0"
+	assert.NoError(t, options.validateParseOptions(vlog.Printer{}))
+	options.NewVersion = "24.3"
+	assert.NoError(t, options.validateParseOptions(vlog.Printer{}))
+	options.NewVersion = "latest"
+	assert.ErrorContains(t, options.validateParseOptions(vlog.Printer{}),
+		"invalid Vertica version latest, the version must be like v24.3.0-1, v24.3.0 or 24.3")
+}
+
+func TestUpgradeStoppedSubcluster(t *testing.T) {
+	vdb := makeVCoordinationDatabase()
+	vdb.HostNodeMap = makeVHostNodeMap()
+	nodes := []VCoordinationNode{
+		{Name: "v_test_db_node0001", Address: "192.168.1.101", Subcluster: "sc1", State: util.NodeUpState},
+		{Name: "v_test_db_node0002", Address: "192.168.1.102", Subcluster: "sc1", State: util.NodeDownState},
+		{Name: "v_test_db_node0003", Address: "192.168.1.103", Subcluster: "sc2", State: util.NodeDownState},
+	}
+	for i := range nodes {
+		assert.NoError(t, vdb.addNode(&nodes[i]))
+	}
+
+	// a subcluster with an up node is stopped
+	scHosts, scNodes, isStopped := getUpgradeSubclusterNodes(&vdb, "sc1")
+	assert.ElementsMatch(t, []string{"192.168.1.101", "192.168.1.102"}, scHosts)
+	assert.Len(t, scNodes, 2)
+	assert.False(t, isStopped)
+
+	// the stop is skipped when the upgrade is resumed with a stopped subcluster
+	_, _, isStopped = getUpgradeSubclusterNodes(&vdb, "sc2")
+	assert.True(t, isStopped)
+}
@@ -27,6 +27,7 @@ const (
 	DefaultShareDir                  = DefaultDBDir + "/share"
 	DefaultLicenseKey                = DefaultShareDir + "/license.key"
 	DefaultConfigDir                 = DefaultDBDir + "/config"
+	DefaultDeployedLicenseKey        = DefaultConfigDir + "/share/license.key"
 	DefaultRetryCount                = 3
 	DefaultTimeoutSeconds            = 300
 	DefaultLoadCatalogTimeoutSeconds = 3600
@@ -38,7 +39,14 @@ const (
 	MinDepotSize                     = 0
 	MaxDepotSize                     = 100
 	DefaultDrainSeconds              = 60
+	DefaultSessionPollingSeconds     = 10
 	DefaultControlSetSize            = -1
+	DefaultStartWaveDelaySeconds     = 10
+	DefaultRebalanceTimeoutSeconds   = -1
+	DefaultMinFreeSpaceMB            = 2048
+	DefaultMaxClockSkewSeconds       = 5
+	DefaultMinFreeInodesPercent      = 5
+	DefaultTailLogLines              = 100
 	NodeUpState                      = "UP"
 	NodeDownState                    = "DOWN"
 	SuppressHelp                     = "SUPPRESS_HELP"
@@ -46,3 +54,7 @@ const (
 )
 
 var RestartPolicyList = []string{"never", DefaultRestartPolicy, "always"}
+
+// DefaultVerticaPorts are the ports that a Vertica node listens on: the client,
+// internal, spread, spread monitor and HTTPS ports
+var DefaultVerticaPorts = []int{DefaultClientPort, DefaultClientPort + 1, 4803, 4804, 6543, DefaultHTTPPort}
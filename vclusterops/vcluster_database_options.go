@@ -16,6 +16,7 @@
 package vclusterops
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -40,6 +41,9 @@ type DatabaseOptions struct {
 	CatalogPrefix string
 	// path of data directory
 	DataPrefix string
+	// catalog, data and depot path prefixes of the hosts whose paths are
+	// different from the ones above, keyed by host
+	HostPathPrefixes map[string]HostPathPrefixes
 	// File path to YAML config file
 	ConfigPath string
 
@@ -99,8 +103,27 @@ const (
 	commandShowRestorePoints = "show_restore_points"
 	commandInstallPackages   = "install_packages"
 	commandConfigRecover     = "manage_config_recover"
+	commandFetchConfig       = "fetch_config"
 	commandReplicationStart  = "replication_start"
 	commandFetchNodesDetails = "fetch_nodes_details"
+	commandStopNode          = "stop_node"
+	commandUpgrade           = "upgrade"
+	commandRotateCerts       = "rotate_certs"
+	commandClusterHealth     = "status"
+	commandPollSubscriptions = "poll_subscription_state"
+	commandGetDiagnostics    = "diagnostics"
+	commandGetDrainingStatus = "draining_status"
+	commandLoadBalance       = "load_balance"
+	commandGetLicenseStatus  = "license_status"
+	commandInstallLicense    = "install_license"
+	commandCheckCatalog      = "check_catalog"
+	commandGetDataCollector  = "data_collector"
+	commandExecuteSQL        = "execute_sql"
+	commandAlterDepot        = "alter_depot"
+	commandClearDepotCache   = "clear_depot_cache"
+	commandRecoverCatalog    = "recover_catalog"
+	commandTailLog           = "tail_log"
+	commandRegenerateSpread  = "regenerate_spread"
 )
 
 func DatabaseOptionsFactory() DatabaseOptions {
@@ -225,7 +248,8 @@ func (opt *DatabaseOptions) validateConfigDir(commandName string) error {
 		return nil
 	}
 
-	if opt.ConfigPath == "" {
+	// the config file can also be in a remote store given by a URL
+	if opt.ConfigPath == "" || strings.Contains(opt.ConfigPath, "://") {
 		return nil
 	}
 
@@ -302,7 +326,7 @@ func (opt *DatabaseOptions) getVDBWhenDBIsDown(vcc VClusterCommands) (vdb VCoord
 
 	certs := httpsCerts{key: opt.Key, cert: opt.Cert, caCert: opt.CaCert}
 	clusterOpEngine := makeClusterOpEngine(instructions1, &certs)
-	err = clusterOpEngine.run(vcc.Log)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if err != nil {
 		vcc.Log.PrintError("fail to retrieve node names from NMA /nodes: %v", err)
 		return vdb, err
@@ -320,7 +344,7 @@ func (opt *DatabaseOptions) getVDBWhenDBIsDown(vcc VClusterCommands) (vdb VCoord
 	instructions2 = append(instructions2, &nmaDownLoadFileOp)
 
 	clusterOpEngine = makeClusterOpEngine(instructions2, &certs)
-	err = clusterOpEngine.run(vcc.Log)
+	err = clusterOpEngine.run(vcc.getContext(), vcc.Log)
 	if err != nil {
 		vcc.Log.PrintError("fail to retrieve node details from %s: %v", descriptionFileName, err)
 		return vdb, err
@@ -393,11 +417,77 @@ func (opt *DatabaseOptions) isSpreadEncryptionEnabled() (enabled bool, encryptio
 	return false, ""
 }
 
-func (opt *DatabaseOptions) runClusterOpEngine(log vlog.Printer, instructions []clusterOp) error {
+// getHostPathPrefixes returns the catalog, data and depot path prefixes of a host
+func (opt *DatabaseOptions) getHostPathPrefixes(host string) HostPathPrefixes {
+	prefixes := opt.HostPathPrefixes[host]
+	if prefixes.CatalogPrefix == "" {
+		prefixes.CatalogPrefix = opt.CatalogPrefix
+	}
+	if prefixes.DataPrefix == "" {
+		prefixes.DataPrefix = opt.DataPrefix
+	}
+	if prefixes.DepotPrefix == "" {
+		prefixes.DepotPrefix = opt.DepotPrefix
+	}
+	return prefixes
+}
+
+// analyzeHostPathPrefixes validates the per-host path prefixes of the given hosts, resolves their
+// hosts to be IPs and cleans their paths. It should be called after Hosts are resolved.
+func (opt *DatabaseOptions) analyzeHostPathPrefixes(hosts []string) error {
+	if len(opt.HostPathPrefixes) == 0 {
+		return nil
+	}
+	resolvedPrefixes := make(map[string]HostPathPrefixes, len(opt.HostPathPrefixes))
+	for rawHost, prefixes := range opt.HostPathPrefixes {
+		addresses, err := util.ResolveRawHostsToAddresses([]string{rawHost}, opt.IPv6)
+		if err != nil {
+			return err
+		}
+		host := addresses[0]
+		if !slices.Contains(hosts, host) {
+			return fmt.Errorf("host %s has path prefixes but is not one of the hosts %v", rawHost, hosts)
+		}
+		paths := []struct {
+			path *string
+			name string
+		}{
+			{&prefixes.CatalogPrefix, "catalog path"},
+			{&prefixes.DataPrefix, "data path"},
+			{&prefixes.DepotPrefix, "depot path"},
+		}
+		for _, p := range paths {
+			if *p.path == "" {
+				continue
+			}
+			err = util.ValidateAbsPath(*p.path, fmt.Sprintf("%s of host %s", p.name, rawHost))
+			if err != nil {
+				return err
+			}
+			*p.path = util.GetCleanPath(*p.path)
+		}
+		resolvedPrefixes[host] = prefixes
+	}
+	opt.HostPathPrefixes = resolvedPrefixes
+	return nil
+}
+
+// resolveHosts resolves the hostnames in RawHosts to be IPs
+func (opt *DatabaseOptions) resolveHosts() (err error) {
+	if len(opt.RawHosts) > 0 {
+		opt.Hosts, err = util.ResolveRawHostsToAddresses(opt.RawHosts, opt.IPv6)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (opt *DatabaseOptions) runClusterOpEngine(ctx context.Context, log vlog.Printer, instructions []clusterOp) error {
 	// Create a VClusterOpEngine, and add certs to the engine
 	certs := httpsCerts{key: opt.Key, cert: opt.Cert, caCert: opt.CaCert}
 	clusterOpEngine := makeClusterOpEngine(instructions, &certs)
 
 	// Give the instructions to the VClusterOpEngine to run
-	return clusterOpEngine.run(log)
+	return clusterOpEngine.run(ctx, log)
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 const (
@@ -32,6 +33,13 @@ const (
 	DebugLog   = "[DEBUG] "
 )
 
+// The formats of the log entries
+const (
+	LogFormatText = "text"
+	// one JSON object per entry, for log collectors like ELK or Loki
+	LogFormatJSON = "json"
+)
+
 // Printer is a wrapper for the logger API that handles dual logging to the log
 // and stdout. It reimplements all of the APIs from logr but adds two additional
 // members: one is for printing messages to stdout, and the other one is for identifying
@@ -41,6 +49,13 @@ type Printer struct {
 	LogToFileOnly bool
 	// ForCli can indicate if vclusterops is called from vcluster cli or other clients
 	ForCli bool
+	// LogFormat is the format of the log entries set up by SetupOrDie, text by default
+	LogFormat string
+	// LogRotation limits the size of the log file set up by SetupOrDie, no limit by default
+	LogRotation LogRotation
+	// SystemLog is syslog or journald to also send the log entries to the
+	// system log of the host, empty by default
+	SystemLog string
 }
 
 // WithName will construct a new printer with the logger set with an additional
@@ -50,9 +65,22 @@ func (p *Printer) WithName(logName string) Printer {
 		Log:           p.Log.WithName(logName),
 		LogToFileOnly: p.LogToFileOnly,
 		ForCli:        p.ForCli,
+		LogFormat:     p.LogFormat,
+		LogRotation:   p.LogRotation,
+		SystemLog:     p.SystemLog,
 	}
 }
 
+// WithOp will construct a new printer for an op. In the JSON format, each entry
+// of the new printer has an op field, so that the entries of an op can be queried.
+func (p *Printer) WithOp(opName string) Printer {
+	printer := p.WithName(opName)
+	if p.LogFormat == LogFormatJSON {
+		printer.Log = printer.Log.WithValues("op", opName)
+	}
+	return printer
+}
+
 // Reimplement the logr APIs that we use. These are simple pass through functions to the logr object.
 
 // V sets the logging level. Can be daisy-chained to produce a log message for
@@ -96,7 +124,7 @@ func (p *Printer) PrintError(msg string, v ...any) {
 func (p *Printer) PrintWarning(msg string, v ...any) {
 	fmsg := fmt.Sprintf(msg, v...)
 	escapedFmsg := escapeSpecialCharacters(fmsg)
-	p.Log.Info(escapedFmsg)
+	p.Log.Info(escapedFmsg, severityKey, severityWarning)
 	p.printlnCond(WarningLog, fmsg)
 }
 
@@ -136,6 +164,7 @@ func logMaskedArgParseHelper(inputArgv []string) (maskedPairs []string) {
 		"awsauth":                 true,
 		"awssessiontoken":         true,
 		"gcsauth":                 true,
+		"gcsserviceaccountkey":    true,
 		"azurestoragecredentials": true,
 	}
 	const (
@@ -203,20 +232,54 @@ func (p *Printer) SetupOrDie(logFile string) {
 		OutputPaths:      []string{"stderr"},
 		ErrorOutputPaths: []string{"stderr"},
 	}
+	if p.LogFormat == LogFormatJSON {
+		cfg.Encoding = "json"
+		cfg.EncoderConfig = makeJSONEncoderConfig()
+	}
 	// If no log file is given, we just log to standard output
 	if logFile != "" {
 		p.LogToFileOnly = true
 		cfg.OutputPaths = []string{logFile}
+		if p.LogRotation.MaxSizeMB > 0 {
+			sinkURL, err := getRotatingSinkURL(logFile, p.LogRotation)
+			if err != nil {
+				fmt.Printf("Failed to setup the log rotation: %s", err.Error())
+				os.Exit(1)
+			}
+			cfg.OutputPaths = []string{sinkURL}
+		}
 	}
 	zapLg, err := cfg.Build()
 	if err != nil {
 		fmt.Printf("Failed to setup the logger: %s", err.Error())
 		os.Exit(1)
 	}
+	if p.SystemLog != "" {
+		systemLogCore, err := newSystemLogCore(p.SystemLog, cfg.Level, p.LogFormat)
+		if err != nil {
+			fmt.Printf("Failed to setup the system log: %s", err.Error())
+			os.Exit(1)
+		}
+		zapLg = zapLg.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, systemLogCore)
+		}))
+	}
 	p.Log = zapr.NewLogger(zapLg)
 	p.Log.Info("Successfully started logger", "logFile", logFile)
 }
 
+// makeJSONEncoderConfig returns the config of the JSON entries. The keys are
+// stable, and the durations are in seconds, so that they can be aggregated.
+func makeJSONEncoderConfig() zapcore.EncoderConfig {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "time"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.EncodeDuration = zapcore.SecondsDurationEncoder
+	encoderConfig.CallerKey = zapcore.OmitKey
+	encoderConfig.StacktraceKey = zapcore.OmitKey
+	return encoderConfig
+}
+
 func isVerboseOutputEnabled() bool {
 	return os.Getenv("VERBOSE_OUTPUT") == "yes"
 }
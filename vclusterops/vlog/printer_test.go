@@ -16,11 +16,19 @@
 package vlog
 
 import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
 	"io"
+	"log/syslog"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-logr/zapr"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 )
 
 // CaptureStdout returns the stdout of the function f as a string
@@ -52,3 +60,105 @@ func TestPasswordRedaction(t *testing.T) {
 	assert.Len(t, unmaskedArgs, 2)
 	assert.Equal(t, pw, unmaskedArgs[1])
 }
+
+func TestJSONLogFormat(t *testing.T) {
+	logFile := t.TempDir() + "/vcluster.log"
+	p := Printer{LogFormat: LogFormatJSON, LogRotation: DefaultLogRotation}
+	p.SetupOrDie(logFile)
+	opLogger := p.WithOp("NMAHealthOp")
+	opLogger.Info("Request finished", "host", "192.168.1.101", "duration", 1500*time.Millisecond)
+	opLogger.Error(errors.New("connection refused"), "Instruction failed")
+
+	data, err := os.ReadFile(logFile)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 3)
+
+	// each line is an event with the fields of the op
+	var event map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, "Request finished", event["msg"])
+	assert.Equal(t, "NMAHealthOp", event["op"])
+	assert.Equal(t, "192.168.1.101", event["host"])
+	assert.Equal(t, 1.5, event["duration"])
+	assert.Contains(t, event, "time")
+
+	event = nil
+	assert.NoError(t, json.Unmarshal([]byte(lines[2]), &event))
+	assert.Equal(t, "error", event["level"])
+	assert.Equal(t, "connection refused", event["error"])
+}
+
+func TestLogRotation(t *testing.T) {
+	logFile := t.TempDir() + "/vcluster.log"
+	f, err := openRotatingFile(logFile, LogRotation{MaxFiles: 2, Compress: true})
+	assert.NoError(t, err)
+	defer f.Close()
+	f.maxBytes = 10
+
+	// each entry goes over the max size, so it is in its own file
+	for _, entry := range []string{"entry 1\n", "entry 2\n", "entry 3\n", "entry 4\n"} {
+		_, err = f.Write([]byte(entry))
+		assert.NoError(t, err)
+	}
+	readGzip := func(path string) string {
+		gzFile, e := os.Open(path)
+		assert.NoError(t, e)
+		defer gzFile.Close()
+		reader, e := gzip.NewReader(gzFile)
+		assert.NoError(t, e)
+		data, e := io.ReadAll(reader)
+		assert.NoError(t, e)
+		return string(data)
+	}
+	data, err := os.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "entry 4\n", string(data))
+	assert.Equal(t, "entry 3\n", readGzip(logFile+".1.gz"))
+	assert.Equal(t, "entry 2\n", readGzip(logFile+".2.gz"))
+	// the oldest file is removed
+	assert.NoFileExists(t, logFile+".3.gz")
+	assert.NoFileExists(t, logFile+".1")
+
+	// the file is opened again when another process rotates it
+	assert.NoError(t, os.Rename(logFile, logFile+".moved"))
+	_, err = f.Write([]byte("entry 5\n"))
+	assert.NoError(t, err)
+	data, err = os.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "entry 5\n", string(data))
+}
+
+type testSystemLogWriter struct {
+	priorities []syslog.Priority
+	messages   []string
+}
+
+func (w *testSystemLogWriter) write(priority syslog.Priority, msg string) error {
+	w.priorities = append(w.priorities, priority)
+	w.messages = append(w.messages, msg)
+	return nil
+}
+
+func TestSystemLog(t *testing.T) {
+	writer := &testSystemLogWriter{}
+	core := makeSystemLogCore(writer, zap.NewAtomicLevelAt(zap.InfoLevel), LogFormatText)
+	p := Printer{Log: zapr.NewLogger(zap.New(core))}
+	p = p.WithName("stop_db")
+	p.PrintInfo("Stopping the database")
+	p.PrintWarning("Node %s is down", "v_test_db_node0002")
+	p.Error(errors.New("connection refused"), "fail to stop the database")
+	p.V(1).Info("not logged at the info level")
+
+	// the entries have the priority of their level, and no time or level
+	assert.Equal(t, []syslog.Priority{syslog.LOG_INFO, syslog.LOG_WARNING, syslog.LOG_ERR}, writer.priorities)
+	assert.Equal(t, "stop_db\tStopping the database", writer.messages[0])
+	assert.Contains(t, writer.messages[1], "Node v_test_db_node0002 is down")
+	assert.Contains(t, writer.messages[2], "connection refused")
+
+	// a message with newlines is written with its size in the journal
+	entry := makeJournalEntry(syslog.LOG_ERR, "a\nb")
+	assert.True(t, strings.HasPrefix(string(entry), "PRIORITY=3\nSYSLOG_IDENTIFIER=vcluster\nMESSAGE\n"))
+	assert.True(t, strings.HasSuffix(string(entry), "\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n"))
+	assert.Equal(t, "PRIORITY=6\nSYSLOG_IDENTIFIER=vcluster\nMESSAGE=ab\n", string(makeJournalEntry(syslog.LOG_INFO, "ab")))
+}
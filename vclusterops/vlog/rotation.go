@@ -0,0 +1,234 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vlog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// the zap sink scheme of the log files that are rotated
+	rotatingSinkScheme = "vlog-rotate"
+	logFilePerm        = 0666
+	bytesPerMB         = 1024 * 1024
+	compressedSuffix   = ".gz"
+)
+
+// LogRotation limits the size of the log file. When the log file reaches the
+// max size, it is renamed to <log>.1, the older files are renamed to <log>.2
+// and so on, and the files beyond the max number of files are removed.
+type LogRotation struct {
+	// the size in MB that the log file is rotated at, 0 disables the rotation
+	MaxSizeMB int
+	// the number of rotated files that are kept
+	MaxFiles int
+	// whether the rotated files are compressed with gzip
+	Compress bool
+}
+
+// DefaultLogRotation is the rotation of the vcluster log file, which keeps at
+// most about 100 MB of uncompressed logs and 5 compressed files
+var DefaultLogRotation = LogRotation{MaxSizeMB: 100, MaxFiles: 5, Compress: true}
+
+var (
+	registerSinkOnce sync.Once
+	registerSinkErr  error
+	// the rotation of each log file, looked up by the sink factory
+	rotations      = make(map[string]LogRotation)
+	rotationsMutex sync.Mutex
+)
+
+// getRotatingSinkURL registers the rotation of a log file, and returns the URL
+// of its zap sink
+func getRotatingSinkURL(logFile string, rotation LogRotation) (string, error) {
+	registerSinkOnce.Do(func() {
+		registerSinkErr = zap.RegisterSink(rotatingSinkScheme, func(u *url.URL) (zap.Sink, error) {
+			rotationsMutex.Lock()
+			r := rotations[u.Path]
+			rotationsMutex.Unlock()
+			return openRotatingFile(u.Path, r)
+		})
+	})
+	if registerSinkErr != nil {
+		return "", registerSinkErr
+	}
+	rotationsMutex.Lock()
+	rotations[logFile] = rotation
+	rotationsMutex.Unlock()
+	return (&url.URL{Scheme: rotatingSinkScheme, Path: logFile}).String(), nil
+}
+
+// rotatingFile is a log file that is rotated when it reaches its max size
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	maxFiles int
+	compress bool
+	file     *os.File
+	size     int64
+}
+
+func openRotatingFile(path string, rotation LogRotation) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:     path,
+		maxBytes: int64(rotation.MaxSizeMB) * bytesPerMB,
+		maxFiles: rotation.MaxFiles,
+		compress: rotation.Compress,
+	}
+	return f, f.open()
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, logFilePerm)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// reopenIfRotated opens the log file again if another vcluster process has
+// rotated it, so that the entries are not written to a rotated file
+func (f *rotatingFile) reopenIfRotated() error {
+	pathInfo, err := os.Stat(f.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	fileInfo, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+	if pathInfo != nil && os.SameFile(pathInfo, fileInfo) {
+		return nil
+	}
+	f.file.Close()
+	return f.open()
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.reopenIfRotated(); err != nil {
+		return 0, err
+	}
+	if f.maxBytes > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxBytes {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Sync()
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// rotatedName returns the name of the ith rotated file
+func (f *rotatingFile) rotatedName(i int, compressed bool) string {
+	name := fmt.Sprintf("%s.%d", f.path, i)
+	if compressed {
+		name += compressedSuffix
+	}
+	return name
+}
+
+// rotate renames the log file to <log>.1, shifts the older rotated files,
+// and opens a new log file
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	// the files of both kinds are handled, in case the compression is changed
+	for _, compressed := range []bool{false, true} {
+		_ = os.Remove(f.rotatedName(f.maxFiles, compressed))
+		for i := f.maxFiles - 1; i >= 1; i-- {
+			_ = os.Rename(f.rotatedName(i, compressed), f.rotatedName(i+1, compressed))
+		}
+	}
+
+	var err error
+	if f.maxFiles == 0 {
+		err = os.Remove(f.path)
+	} else {
+		rotated := f.rotatedName(1, false)
+		err = os.Rename(f.path, rotated)
+		// if the compression fails, the rotated file is kept as is
+		if err == nil && f.compress {
+			_ = compressFile(rotated, f.rotatedName(1, true))
+		}
+	}
+	// the log file is opened even if it cannot be rotated, so that the
+	// next entries are not lost
+	if e := f.open(); e != nil {
+		return e
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func compressFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, logFilePerm)
+	if err != nil {
+		return err
+	}
+	gzipWriter := gzip.NewWriter(out)
+	if _, err = io.Copy(gzipWriter, in); err == nil {
+		err = gzipWriter.Close()
+	}
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		_ = os.Remove(dest)
+		return fmt.Errorf("fail to compress the rotated log file %s: %w", src, err)
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,185 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log/syslog"
+	"net"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// The system logs that the log entries can also be sent to
+const (
+	SystemLogSyslog  = "syslog"
+	SystemLogJournal = "journald"
+)
+
+const (
+	// the identifier of the entries in the system log
+	systemLogTag = "vcluster"
+	// the socket of the native protocol of the systemd journal
+	journalSocket = "/run/systemd/journal/socket"
+	// the field of an entry that raises its priority, since logr has no warning level
+	severityKey     = "severity"
+	severityWarning = "warning"
+)
+
+// systemLogWriter sends a log entry to the system log with its priority
+type systemLogWriter interface {
+	write(priority syslog.Priority, msg string) error
+}
+
+// systemLogCore is a zap core that sends the log entries to syslog or to the
+// systemd journal. The time and the level are not encoded in the entry,
+// since the system log records the time and the priority of each entry.
+type systemLogCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	writer  systemLogWriter
+}
+
+// newSystemLogCore connects to a system log, which is syslog or journald
+func newSystemLogCore(systemLog string, level zapcore.LevelEnabler, format string) (*systemLogCore, error) {
+	var writer systemLogWriter
+	switch systemLog {
+	case SystemLogSyslog:
+		w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, systemLogTag)
+		if err != nil {
+			return nil, fmt.Errorf("fail to connect to syslog: %w", err)
+		}
+		writer = &syslogWriter{w: w}
+	case SystemLogJournal:
+		conn, err := net.Dial("unixgram", journalSocket)
+		if err != nil {
+			return nil, fmt.Errorf("fail to connect to the systemd journal: %w", err)
+		}
+		writer = &journalWriter{conn: conn}
+	default:
+		return nil, fmt.Errorf("unknown system log %q, the system log must be %s or %s",
+			systemLog, SystemLogSyslog, SystemLogJournal)
+	}
+	return makeSystemLogCore(writer, level, format), nil
+}
+
+func makeSystemLogCore(writer systemLogWriter, level zapcore.LevelEnabler, format string) *systemLogCore {
+	encoderConfig := makeJSONEncoderConfig()
+	encoderConfig.TimeKey = zapcore.OmitKey
+	encoderConfig.LevelKey = zapcore.OmitKey
+	encoder := zapcore.NewConsoleEncoder(encoderConfig)
+	if format == LogFormatJSON {
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	}
+	return &systemLogCore{LevelEnabler: level, encoder: encoder, writer: writer}
+}
+
+func (c *systemLogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &systemLogCore{LevelEnabler: c.LevelEnabler, encoder: c.encoder.Clone(), writer: c.writer}
+	for i := range fields {
+		fields[i].AddTo(clone.encoder)
+	}
+	return clone
+}
+
+func (c *systemLogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *systemLogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	msg := strings.TrimSuffix(buf.String(), "\n")
+	buf.Free()
+	return c.writer.write(getSystemLogPriority(entry.Level, fields), msg)
+}
+
+func (c *systemLogCore) Sync() error {
+	return nil
+}
+
+// getSystemLogPriority maps the level of a log entry to a syslog priority.
+// PrintWarning logs at the info level with a warning severity.
+func getSystemLogPriority(level zapcore.Level, fields []zapcore.Field) syslog.Priority {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return syslog.LOG_DEBUG
+	case level == zapcore.InfoLevel:
+		for _, field := range fields {
+			if field.Key == severityKey && field.String == severityWarning {
+				return syslog.LOG_WARNING
+			}
+		}
+		return syslog.LOG_INFO
+	case level == zapcore.WarnLevel:
+		return syslog.LOG_WARNING
+	case level == zapcore.ErrorLevel:
+		return syslog.LOG_ERR
+	}
+	return syslog.LOG_CRIT
+}
+
+type syslogWriter struct {
+	w *syslog.Writer
+}
+
+func (s *syslogWriter) write(priority syslog.Priority, msg string) error {
+	switch priority {
+	case syslog.LOG_DEBUG:
+		return s.w.Debug(msg)
+	case syslog.LOG_WARNING:
+		return s.w.Warning(msg)
+	case syslog.LOG_ERR:
+		return s.w.Err(msg)
+	case syslog.LOG_CRIT:
+		return s.w.Crit(msg)
+	}
+	return s.w.Info(msg)
+}
+
+// journalWriter sends the entries to the systemd journal with its native
+// protocol, which keeps the priority and the identifier as fields
+type journalWriter struct {
+	conn net.Conn
+}
+
+func (j *journalWriter) write(priority syslog.Priority, msg string) error {
+	_, err := j.conn.Write(makeJournalEntry(priority, msg))
+	return err
+}
+
+func makeJournalEntry(priority syslog.Priority, msg string) []byte {
+	var entry bytes.Buffer
+	fmt.Fprintf(&entry, "PRIORITY=%d\nSYSLOG_IDENTIFIER=%s\n", priority, systemLogTag)
+	if !strings.Contains(msg, "\n") {
+		fmt.Fprintf(&entry, "MESSAGE=%s\n", msg)
+		return entry.Bytes()
+	}
+	// a value with newlines is written with its size instead of a separator
+	entry.WriteString("MESSAGE\n")
+	_ = binary.Write(&entry, binary.LittleEndian, uint64(len(msg)))
+	entry.WriteString(msg)
+	entry.WriteString("\n")
+	return entry.Bytes()
+}
@@ -0,0 +1,64 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+	"time"
+)
+
+// waitOp waits between two instructions, such as between the waves of nodes
+// that are started one after another. It sends no request.
+type waitOp struct {
+	opBase
+	duration time.Duration
+}
+
+func makeWaitOp(duration time.Duration) waitOp {
+	op := waitOp{}
+	op.name = "WaitOp"
+	op.description = fmt.Sprintf("Wait %s", duration)
+	op.duration = duration
+	return op
+}
+
+func (op *waitOp) prepare(_ *opEngineExecContext) error {
+	return nil
+}
+
+// loadCertsIfNeeded does nothing, as the op has no request
+func (op *waitOp) loadCertsIfNeeded(_ *httpsCerts, _ bool) error {
+	return nil
+}
+
+func (op *waitOp) execute(execContext *opEngineExecContext) error {
+	timer := time.NewTimer(op.duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-execContext.dispatcher.ctx.Done():
+		return execContext.dispatcher.ctx.Err()
+	}
+}
+
+func (op *waitOp) processResult(_ *opEngineExecContext) error {
+	return nil
+}
+
+func (op *waitOp) finalize(_ *opEngineExecContext) error {
+	return nil
+}
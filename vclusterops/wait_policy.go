@@ -0,0 +1,100 @@
+/*
+ (c) Copyright [2023] Open Text.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ You may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vclusterops
+
+import (
+	"fmt"
+
+	"github.com/vertica/vcluster/vclusterops/vlog"
+)
+
+// WaitPolicy is the condition that start_db and restart_node wait for before
+// the nodes are considered started. An empty policy is WaitForUp.
+type WaitPolicy string
+
+const (
+	// the vertica process of the nodes is started, the nodes are not polled
+	WaitForProcess WaitPolicy = "process"
+	// the nodes are UP, which is the default
+	WaitForUp WaitPolicy = "up"
+	// the nodes are UP and their shard subscriptions are ACTIVE, in Eon mode
+	WaitForSubscriptions WaitPolicy = "subscriptions"
+	// the nodes are UP and accept client connections
+	WaitForConnections WaitPolicy = "connections"
+)
+
+// WaitPolicies are the valid values of WaitPolicy
+var WaitPolicies = []WaitPolicy{WaitForProcess, WaitForUp, WaitForSubscriptions, WaitForConnections}
+
+// validate checks the policy, which can be empty for WaitForUp
+func (policy WaitPolicy) validate() error {
+	if policy == "" {
+		return nil
+	}
+	for _, validPolicy := range WaitPolicies {
+		if policy == validPolicy {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid wait policy %q, it must be one of %v", policy, WaitPolicies)
+}
+
+// waitsForUp returns whether the nodes are UP once the policy is met, which
+// the ops after the start need, such as the catalog sync
+func (policy WaitPolicy) waitsForUp() bool {
+	return policy != WaitForProcess
+}
+
+// makeWaitPolicyOps returns the ops that wait for the started hosts to meet
+// the policy. The node names are the names of the hosts, which are needed to
+// poll the subscriptions.
+func makeWaitPolicyOps(logger vlog.Printer, policy WaitPolicy, hosts, nodeNames []string, isEon bool,
+	options *DatabaseOptions, timeout int, cmdType CmdType) ([]clusterOp, error) {
+	if !policy.waitsForUp() {
+		return nil, nil
+	}
+
+	httpsPollNodeStateOp, err := makeHTTPSPollNodeStateOpWithTimeoutAndCommand(hosts,
+		options.usePassword, options.UserName, options.Password, timeout, cmdType)
+	if err != nil {
+		return nil, err
+	}
+	instructions := []clusterOp{&httpsPollNodeStateOp}
+
+	switch policy {
+	case WaitForSubscriptions:
+		if !isEon {
+			logger.PrintInfo("Skipping the poll of the shard subscriptions, which are only available in Eon mode")
+			break
+		}
+		if len(nodeNames) == 0 {
+			logger.PrintWarning("Skipping the poll of the shard subscriptions, as the names of the nodes are unknown")
+			break
+		}
+		// the op keeps the pointer, so the node names are copied
+		nodesToPoll := append([]string{}, nodeNames...)
+		httpsPollSubscriptionStateOp, e := makeHTTPSPollSubscriptionStateOpWithTimeout(hosts,
+			options.usePassword, options.UserName, options.Password, &nodesToPoll, timeout)
+		if e != nil {
+			return nil, e
+		}
+		instructions = append(instructions, &httpsPollSubscriptionStateOp)
+	case WaitForConnections:
+		pollClientConnectionsOp := makePollClientConnectionsOp(hosts, timeout)
+		instructions = append(instructions, &pollClientConnectionsOp)
+	}
+	return instructions, nil
+}